@@ -21,12 +21,347 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type PullStage int32
+
+const (
+	PullStage_PULL_STAGE_UNSPECIFIED PullStage = 0
+	// The dependency, missing from the cache, is being fetched.
+	PullStage_PULL_STAGE_STARTED PullStage = 1
+	// The fetch has transferred bytes so far; reported as they grow.
+	PullStage_PULL_STAGE_TRANSFERRING PullStage = 2
+	// The snapshot, fetched or cached, was hashed and checked against
+	// holon.sum.
+	PullStage_PULL_STAGE_HASHED PullStage = 3
+	// The dependency is recorded in holon.sum.
+	PullStage_PULL_STAGE_DONE PullStage = 4
+)
+
+// Enum value maps for PullStage.
+var (
+	PullStage_name = map[int32]string{
+		0: "PULL_STAGE_UNSPECIFIED",
+		1: "PULL_STAGE_STARTED",
+		2: "PULL_STAGE_TRANSFERRING",
+		3: "PULL_STAGE_HASHED",
+		4: "PULL_STAGE_DONE",
+	}
+	PullStage_value = map[string]int32{
+		"PULL_STAGE_UNSPECIFIED":  0,
+		"PULL_STAGE_STARTED":      1,
+		"PULL_STAGE_TRANSFERRING": 2,
+		"PULL_STAGE_HASHED":       3,
+		"PULL_STAGE_DONE":         4,
+	}
+)
+
+func (x PullStage) Enum() *PullStage {
+	p := new(PullStage)
+	*p = x
+	return p
+}
+
+func (x PullStage) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PullStage) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes[0].Descriptor()
+}
+
+func (PullStage) Type() protoreflect.EnumType {
+	return &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes[0]
+}
+
+func (x PullStage) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PullStage.Descriptor instead.
+func (PullStage) EnumDescriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{0}
+}
+
+type UpdateBranching int32
+
+const (
+	// holon.mod is updated in place.
+	UpdateBranching_UPDATE_BRANCHING_UNSPECIFIED UpdateBranching = 0
+	// One branch per updated dependency, atlas/update/<path>@<version>.
+	UpdateBranching_UPDATE_BRANCHING_PER_DEPENDENCY UpdateBranching = 1
+	// One branch, atlas/updates, with every update.
+	UpdateBranching_UPDATE_BRANCHING_BATCH UpdateBranching = 2
+)
+
+// Enum value maps for UpdateBranching.
+var (
+	UpdateBranching_name = map[int32]string{
+		0: "UPDATE_BRANCHING_UNSPECIFIED",
+		1: "UPDATE_BRANCHING_PER_DEPENDENCY",
+		2: "UPDATE_BRANCHING_BATCH",
+	}
+	UpdateBranching_value = map[string]int32{
+		"UPDATE_BRANCHING_UNSPECIFIED":    0,
+		"UPDATE_BRANCHING_PER_DEPENDENCY": 1,
+		"UPDATE_BRANCHING_BATCH":          2,
+	}
+)
+
+func (x UpdateBranching) Enum() *UpdateBranching {
+	p := new(UpdateBranching)
+	*p = x
+	return p
+}
+
+func (x UpdateBranching) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UpdateBranching) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes[1].Descriptor()
+}
+
+func (UpdateBranching) Type() protoreflect.EnumType {
+	return &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes[1]
+}
+
+func (x UpdateBranching) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UpdateBranching.Descriptor instead.
+func (UpdateBranching) EnumDescriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{1}
+}
+
+type ReleaseBump int32
+
+const (
+	ReleaseBump_RELEASE_BUMP_PATCH ReleaseBump = 0
+	ReleaseBump_RELEASE_BUMP_MINOR ReleaseBump = 1
+	ReleaseBump_RELEASE_BUMP_MAJOR ReleaseBump = 2
+)
+
+// Enum value maps for ReleaseBump.
+var (
+	ReleaseBump_name = map[int32]string{
+		0: "RELEASE_BUMP_PATCH",
+		1: "RELEASE_BUMP_MINOR",
+		2: "RELEASE_BUMP_MAJOR",
+	}
+	ReleaseBump_value = map[string]int32{
+		"RELEASE_BUMP_PATCH": 0,
+		"RELEASE_BUMP_MINOR": 1,
+		"RELEASE_BUMP_MAJOR": 2,
+	}
+)
+
+func (x ReleaseBump) Enum() *ReleaseBump {
+	p := new(ReleaseBump)
+	*p = x
+	return p
+}
+
+func (x ReleaseBump) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReleaseBump) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes[2].Descriptor()
+}
+
+func (ReleaseBump) Type() protoreflect.EnumType {
+	return &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes[2]
+}
+
+func (x ReleaseBump) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReleaseBump.Descriptor instead.
+func (ReleaseBump) EnumDescriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{2}
+}
+
+type EventKind int32
+
+const (
+	EventKind_EVENT_KIND_UNSPECIFIED EventKind = 0
+	// A dependency missing from the cache is being fetched.
+	EventKind_EVENT_KIND_FETCH_STARTED EventKind = 1
+	// The fetch ended; errors says why if it failed.
+	EventKind_EVENT_KIND_FETCH_FINISHED EventKind = 2
+	// Verify found problems, listed in errors.
+	EventKind_EVENT_KIND_VERIFY_FAILED EventKind = 3
+	// A dependency of a holon can be updated to new_version. Each update is
+	// announced once per server.
+	EventKind_EVENT_KIND_UPDATE_AVAILABLE EventKind = 4
+	// Entries were removed from the cache, by CleanCache, CacheOrphans or
+	// the startup cache check.
+	EventKind_EVENT_KIND_CACHE_CLEANED EventKind = 5
+)
+
+// Enum value maps for EventKind.
+var (
+	EventKind_name = map[int32]string{
+		0: "EVENT_KIND_UNSPECIFIED",
+		1: "EVENT_KIND_FETCH_STARTED",
+		2: "EVENT_KIND_FETCH_FINISHED",
+		3: "EVENT_KIND_VERIFY_FAILED",
+		4: "EVENT_KIND_UPDATE_AVAILABLE",
+		5: "EVENT_KIND_CACHE_CLEANED",
+	}
+	EventKind_value = map[string]int32{
+		"EVENT_KIND_UNSPECIFIED":      0,
+		"EVENT_KIND_FETCH_STARTED":    1,
+		"EVENT_KIND_FETCH_FINISHED":   2,
+		"EVENT_KIND_VERIFY_FAILED":    3,
+		"EVENT_KIND_UPDATE_AVAILABLE": 4,
+		"EVENT_KIND_CACHE_CLEANED":    5,
+	}
+)
+
+func (x EventKind) Enum() *EventKind {
+	p := new(EventKind)
+	*p = x
+	return p
+}
+
+func (x EventKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes[3].Descriptor()
+}
+
+func (EventKind) Type() protoreflect.EnumType {
+	return &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes[3]
+}
+
+func (x EventKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventKind.Descriptor instead.
+func (EventKind) EnumDescriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{3}
+}
+
+type DiagnosticSeverity int32
+
+const (
+	DiagnosticSeverity_DIAGNOSTIC_SEVERITY_ERROR   DiagnosticSeverity = 0
+	DiagnosticSeverity_DIAGNOSTIC_SEVERITY_WARNING DiagnosticSeverity = 1
+	DiagnosticSeverity_DIAGNOSTIC_SEVERITY_INFO    DiagnosticSeverity = 2
+)
+
+// Enum value maps for DiagnosticSeverity.
+var (
+	DiagnosticSeverity_name = map[int32]string{
+		0: "DIAGNOSTIC_SEVERITY_ERROR",
+		1: "DIAGNOSTIC_SEVERITY_WARNING",
+		2: "DIAGNOSTIC_SEVERITY_INFO",
+	}
+	DiagnosticSeverity_value = map[string]int32{
+		"DIAGNOSTIC_SEVERITY_ERROR":   0,
+		"DIAGNOSTIC_SEVERITY_WARNING": 1,
+		"DIAGNOSTIC_SEVERITY_INFO":    2,
+	}
+)
+
+func (x DiagnosticSeverity) Enum() *DiagnosticSeverity {
+	p := new(DiagnosticSeverity)
+	*p = x
+	return p
+}
+
+func (x DiagnosticSeverity) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DiagnosticSeverity) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes[4].Descriptor()
+}
+
+func (DiagnosticSeverity) Type() protoreflect.EnumType {
+	return &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes[4]
+}
+
+func (x DiagnosticSeverity) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DiagnosticSeverity.Descriptor instead.
+func (DiagnosticSeverity) EnumDescriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{4}
+}
+
+type FileChange int32
+
+const (
+	FileChange_FILE_CHANGE_UNSPECIFIED FileChange = 0
+	// Only in the second tree.
+	FileChange_FILE_CHANGE_ADDED FileChange = 1
+	// Only in the first tree.
+	FileChange_FILE_CHANGE_DELETED FileChange = 2
+	// In both, with different content.
+	FileChange_FILE_CHANGE_MODIFIED FileChange = 3
+)
+
+// Enum value maps for FileChange.
+var (
+	FileChange_name = map[int32]string{
+		0: "FILE_CHANGE_UNSPECIFIED",
+		1: "FILE_CHANGE_ADDED",
+		2: "FILE_CHANGE_DELETED",
+		3: "FILE_CHANGE_MODIFIED",
+	}
+	FileChange_value = map[string]int32{
+		"FILE_CHANGE_UNSPECIFIED": 0,
+		"FILE_CHANGE_ADDED":       1,
+		"FILE_CHANGE_DELETED":     2,
+		"FILE_CHANGE_MODIFIED":    3,
+	}
+)
+
+func (x FileChange) Enum() *FileChange {
+	p := new(FileChange)
+	*p = x
+	return p
+}
+
+func (x FileChange) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FileChange) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes[5].Descriptor()
+}
+
+func (FileChange) Type() protoreflect.EnumType {
+	return &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes[5]
+}
+
+func (x FileChange) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FileChange.Descriptor instead.
+func (FileChange) EnumDescriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{5}
+}
+
 type InitRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Directory where holon.mod will be created.
 	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
 	// The holon path (e.g. "github.com/org/myholon").
-	HolonPath     string `protobuf:"bytes,2,opt,name=holon_path,json=holonPath,proto3" json:"holon_path,omitempty"`
+	HolonPath string `protobuf:"bytes,2,opt,name=holon_path,json=holonPath,proto3" json:"holon_path,omitempty"`
+	// Require the dependencies vendored in the .holon/ directory of a
+	// holon predating holon.mod: each with the path its own holon.mod
+	// declares, at the version a holon.sum in directory, or else the cache,
+	// records for the same content, or else its HOLON.md declares.
+	FromExisting  bool `protobuf:"varint,3,opt,name=from_existing,json=fromExisting,proto3" json:"from_existing,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -75,10 +410,23 @@ func (x *InitRequest) GetHolonPath() string {
 	return ""
 }
 
+func (x *InitRequest) GetFromExisting() bool {
+	if x != nil {
+		return x.FromExisting
+	}
+	return false
+}
+
 type InitResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Path to the created holon.mod file.
-	ModFile       string `protobuf:"bytes,1,opt,name=mod_file,json=modFile,proto3" json:"mod_file,omitempty"`
+	ModFile string `protobuf:"bytes,1,opt,name=mod_file,json=modFile,proto3" json:"mod_file,omitempty"`
+	// Dependencies required from their vendored copy, set with
+	// from_existing; cache_path is the copy.
+	Inferred []*Dependency `protobuf:"bytes,2,rep,name=inferred,proto3" json:"inferred,omitempty"`
+	// Vendored copies whose path or version could not be inferred, each
+	// with what to do about it.
+	Unresolved    []string `protobuf:"bytes,3,rep,name=unresolved,proto3" json:"unresolved,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -120,32 +468,55 @@ func (x *InitResponse) GetModFile() string {
 	return ""
 }
 
-type AddRequest struct {
+func (x *InitResponse) GetInferred() []*Dependency {
+	if x != nil {
+		return x.Inferred
+	}
+	return nil
+}
+
+func (x *InitResponse) GetUnresolved() []string {
+	if x != nil {
+		return x.Unresolved
+	}
+	return nil
+}
+
+type NewRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Directory containing holon.mod.
-	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
-	// Dependency path (e.g. "github.com/org/dep").
-	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
-	// Semantic version (e.g. "v1.2.0").
-	Version       string `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	// Template holon, "<path>[@<version>]": its latest release unless a
+	// version is given. Its HOLON.md must declare "template: true".
+	Template string `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"`
+	// Directory to create the holon in, the last element of holon_path by
+	// default; it must not exist or be empty.
+	Directory string `protobuf:"bytes,2,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Holon path of the new holon, substituted for {{holon_path}} and
+	// written to its holon.mod.
+	HolonPath string `protobuf:"bytes,3,opt,name=holon_path,json=holonPath,proto3" json:"holon_path,omitempty"`
+	// Substituted for {{name}}; the last element of holon_path by default.
+	Name string `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	// Substituted for {{author}}; git's user.name by default.
+	Author string `protobuf:"bytes,5,opt,name=author,proto3" json:"author,omitempty"`
+	// More variables: each value is substituted for {{<key>}}.
+	Vars          map[string]string `protobuf:"bytes,6,rep,name=vars,proto3" json:"vars,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AddRequest) Reset() {
-	*x = AddRequest{}
+func (x *NewRequest) Reset() {
+	*x = NewRequest{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AddRequest) String() string {
+func (x *NewRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddRequest) ProtoMessage() {}
+func (*NewRequest) ProtoMessage() {}
 
-func (x *AddRequest) ProtoReflect() protoreflect.Message {
+func (x *NewRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -157,54 +528,78 @@ func (x *AddRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddRequest.ProtoReflect.Descriptor instead.
-func (*AddRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use NewRequest.ProtoReflect.Descriptor instead.
+func (*NewRequest) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *AddRequest) GetDirectory() string {
+func (x *NewRequest) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *NewRequest) GetDirectory() string {
 	if x != nil {
 		return x.Directory
 	}
 	return ""
 }
 
-func (x *AddRequest) GetPath() string {
+func (x *NewRequest) GetHolonPath() string {
 	if x != nil {
-		return x.Path
+		return x.HolonPath
 	}
 	return ""
 }
 
-func (x *AddRequest) GetVersion() string {
+func (x *NewRequest) GetName() string {
 	if x != nil {
-		return x.Version
+		return x.Name
 	}
 	return ""
 }
 
-type AddResponse struct {
+func (x *NewRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *NewRequest) GetVars() map[string]string {
+	if x != nil {
+		return x.Vars
+	}
+	return nil
+}
+
+type NewResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// The dependency as recorded.
-	Dependency    *Dependency `protobuf:"bytes,1,opt,name=dependency,proto3" json:"dependency,omitempty"`
+	// Template path and the version instantiated.
+	Template string `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"`
+	Version  string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Files created, relative to the directory.
+	Files         []string `protobuf:"bytes,3,rep,name=files,proto3" json:"files,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AddResponse) Reset() {
-	*x = AddResponse{}
+func (x *NewResponse) Reset() {
+	*x = NewResponse{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AddResponse) String() string {
+func (x *NewResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddResponse) ProtoMessage() {}
+func (*NewResponse) ProtoMessage() {}
 
-func (x *AddResponse) ProtoReflect() protoreflect.Message {
+func (x *NewResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -216,42 +611,88 @@ func (x *AddResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddResponse.ProtoReflect.Descriptor instead.
-func (*AddResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use NewResponse.ProtoReflect.Descriptor instead.
+func (*NewResponse) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *AddResponse) GetDependency() *Dependency {
+func (x *NewResponse) GetTemplate() string {
 	if x != nil {
-		return x.Dependency
+		return x.Template
+	}
+	return ""
+}
+
+func (x *NewResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *NewResponse) GetFiles() []string {
+	if x != nil {
+		return x.Files
 	}
 	return nil
 }
 
-type RemoveRequest struct {
+type AddRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Directory containing holon.mod.
+	// Directory containing holon.mod, or one of its subdirectories: the
+	// nearest parent holding a holon.mod is used, as the go command does.
 	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
-	// Dependency path to remove.
-	Path          string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// Dependency path (e.g. "github.com/org/dep").
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// Semantic version (e.g. "v1.2.0"), "@<channel>" (e.g. "@beta") for
+	// the latest prerelease of that channel, a commit hash (at least 7 hex
+	// digits) for the pseudo-version pinning that commit
+	// (v0.0.0-yyyymmddhhmmss-abcdef123456), or "sha256:<hex>" to pin the
+	// content by digest, resolved through ATLAS_PROXY.
+	Version string `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	// Optional short name for the dependency (e.g. "ln").
+	Alias string `protobuf:"bytes,4,opt,name=alias,proto3" json:"alias,omitempty"`
+	// Record the dependency in holon.mod without fetching it; holon.sum is
+	// left untouched. By default a failed fetch aborts the whole Add.
+	RecordOnly bool `protobuf:"varint,5,opt,name=record_only,json=recordOnly,proto3" json:"record_only,omitempty"`
+	// Report what would change in plan without writing or fetching anything.
+	DryRun bool `protobuf:"varint,6,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// Optional client-chosen key identifying this mutation. A retry with the
+	// same key returns the response of the first successful call instead of
+	// applying the mutation again. Keys are remembered in memory by the
+	// server, for its 1000 most recent keyed calls.
+	IdempotencyKey string `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Accept a snapshot that does not hash to what holon.sum records for
+	// this version, replacing the entry. By default Add fails with
+	// HASH_MISMATCH and a freshly fetched snapshot is not kept.
+	Force bool `protobuf:"varint,8,opt,name=force,proto3" json:"force,omitempty"`
+	// Confirm the trust of a dependency added for the first time, under the
+	// tofu directive of holon.mod. Without it, such an Add fails with
+	// TRUST_REQUIRED, its metadata holding the "hash", "commit" and
+	// "source" of the snapshot to trust.
+	Trust bool `protobuf:"varint,9,opt,name=trust,proto3" json:"trust,omitempty"`
+	// With trust, the hash that was confirmed, as TRUST_REQUIRED reported
+	// it: a snapshot fetched that hashes otherwise fails with HASH_MISMATCH.
+	// Empty trusts whatever is fetched.
+	TrustHash     string `protobuf:"bytes,10,opt,name=trust_hash,json=trustHash,proto3" json:"trust_hash,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RemoveRequest) Reset() {
-	*x = RemoveRequest{}
+func (x *AddRequest) Reset() {
+	*x = AddRequest{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RemoveRequest) String() string {
+func (x *AddRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RemoveRequest) ProtoMessage() {}
+func (*AddRequest) ProtoMessage() {}
 
-func (x *RemoveRequest) ProtoReflect() protoreflect.Message {
+func (x *AddRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -263,46 +704,112 @@ func (x *RemoveRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RemoveRequest.ProtoReflect.Descriptor instead.
-func (*RemoveRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use AddRequest.ProtoReflect.Descriptor instead.
+func (*AddRequest) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *RemoveRequest) GetDirectory() string {
+func (x *AddRequest) GetDirectory() string {
 	if x != nil {
 		return x.Directory
 	}
 	return ""
 }
 
-func (x *RemoveRequest) GetPath() string {
+func (x *AddRequest) GetPath() string {
 	if x != nil {
 		return x.Path
 	}
 	return ""
 }
 
-type RemoveResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *AddRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
 }
 
-func (x *RemoveResponse) Reset() {
-	*x = RemoveResponse{}
-	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[5]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *AddRequest) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
 }
 
-func (x *RemoveResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *AddRequest) GetRecordOnly() bool {
+	if x != nil {
+		return x.RecordOnly
+	}
+	return false
 }
 
-func (*RemoveResponse) ProtoMessage() {}
+func (x *AddRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
 
-func (x *RemoveResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[5]
+func (x *AddRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *AddRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *AddRequest) GetTrust() bool {
+	if x != nil {
+		return x.Trust
+	}
+	return false
+}
+
+func (x *AddRequest) GetTrustHash() string {
+	if x != nil {
+		return x.TrustHash
+	}
+	return ""
+}
+
+type AddResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The dependency as recorded.
+	Dependency *Dependency `protobuf:"bytes,1,opt,name=dependency,proto3" json:"dependency,omitempty"`
+	// Set when dry_run was requested.
+	Plan *Plan `protobuf:"bytes,2,opt,name=plan,proto3" json:"plan,omitempty"`
+	// Directory of the holon.mod acted on, absolute when it was found in a
+	// parent of the requested directory.
+	Root string `protobuf:"bytes,3,opt,name=root,proto3" json:"root,omitempty"`
+	// Set when the dependency was trusted for the first time, under the
+	// tofu directive: what later fetches of it must match.
+	Trusted       *TrustRecord `protobuf:"bytes,4,opt,name=trusted,proto3" json:"trusted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddResponse) Reset() {
+	*x = AddResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddResponse) ProtoMessage() {}
+
+func (x *AddResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -313,33 +820,67 @@ func (x *RemoveResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RemoveResponse.ProtoReflect.Descriptor instead.
-func (*RemoveResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use AddResponse.ProtoReflect.Descriptor instead.
+func (*AddResponse) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{5}
 }
 
-type PullRequest struct {
+func (x *AddResponse) GetDependency() *Dependency {
+	if x != nil {
+		return x.Dependency
+	}
+	return nil
+}
+
+func (x *AddResponse) GetPlan() *Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+func (x *AddResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *AddResponse) GetTrusted() *TrustRecord {
+	if x != nil {
+		return x.Trusted
+	}
+	return nil
+}
+
+// TrustRecord is what was trusted of a snapshot on first use, in the
+// known holons file of the machine.
+type TrustRecord struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Directory containing holon.mod.
-	Directory     string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// holon.sum hash of the snapshot.
+	Hash string `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	// Commit it was fetched from, if known.
+	Commit string `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`
+	// Where it was fetched from.
+	Source        string `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PullRequest) Reset() {
-	*x = PullRequest{}
+func (x *TrustRecord) Reset() {
+	*x = TrustRecord{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PullRequest) String() string {
+func (x *TrustRecord) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PullRequest) ProtoMessage() {}
+func (*TrustRecord) ProtoMessage() {}
 
-func (x *PullRequest) ProtoReflect() protoreflect.Message {
+func (x *TrustRecord) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -351,40 +892,61 @@ func (x *PullRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PullRequest.ProtoReflect.Descriptor instead.
-func (*PullRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use TrustRecord.ProtoReflect.Descriptor instead.
+func (*TrustRecord) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *PullRequest) GetDirectory() string {
+func (x *TrustRecord) GetHash() string {
 	if x != nil {
-		return x.Directory
+		return x.Hash
 	}
 	return ""
 }
 
-type PullResponse struct {
+func (x *TrustRecord) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *TrustRecord) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type ResolveRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Dependencies that were fetched or verified.
-	Fetched       []*Dependency `protobuf:"bytes,1,rep,name=fetched,proto3" json:"fetched,omitempty"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest). When there is a holon.mod, its replace, registry and
+	// proxy directives apply.
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Dependency path (e.g. "github.com/org/dep").
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// Version query, as for AddRequest.version; empty for the latest
+	// release.
+	Version       string `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PullResponse) Reset() {
-	*x = PullResponse{}
+func (x *ResolveRequest) Reset() {
+	*x = ResolveRequest{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PullResponse) String() string {
+func (x *ResolveRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PullResponse) ProtoMessage() {}
+func (*ResolveRequest) ProtoMessage() {}
 
-func (x *PullResponse) ProtoReflect() protoreflect.Message {
+func (x *ResolveRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -396,40 +958,65 @@ func (x *PullResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PullResponse.ProtoReflect.Descriptor instead.
-func (*PullResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ResolveRequest.ProtoReflect.Descriptor instead.
+func (*ResolveRequest) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *PullResponse) GetFetched() []*Dependency {
+func (x *ResolveRequest) GetDirectory() string {
 	if x != nil {
-		return x.Fetched
+		return x.Directory
 	}
-	return nil
+	return ""
 }
 
-type VerifyRequest struct {
+func (x *ResolveRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ResolveRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type ResolveResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Directory containing holon.mod and holon.sum.
-	Directory     string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The concrete version the query resolves to.
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Path fetched in place of path, when holon.mod replaces it.
+	SourcePath string `protobuf:"bytes,3,opt,name=source_path,json=sourcePath,proto3" json:"source_path,omitempty"`
+	// Where the version is, or would be, fetched from.
+	Source string `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`
+	// Commit the version points to, when its fetcher tells.
+	Commit string `protobuf:"bytes,5,opt,name=commit,proto3" json:"commit,omitempty"`
+	// Whether the version is in the local cache.
+	Cached bool `protobuf:"varint,6,opt,name=cached,proto3" json:"cached,omitempty"`
+	// Directory of the cached snapshot, when cached.
+	CachePath     string `protobuf:"bytes,7,opt,name=cache_path,json=cachePath,proto3" json:"cache_path,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *VerifyRequest) Reset() {
-	*x = VerifyRequest{}
+func (x *ResolveResponse) Reset() {
+	*x = ResolveResponse{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *VerifyRequest) String() string {
+func (x *ResolveResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VerifyRequest) ProtoMessage() {}
+func (*ResolveResponse) ProtoMessage() {}
 
-func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
+func (x *ResolveResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -441,41 +1028,89 @@ func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
-func (*VerifyRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ResolveResponse.ProtoReflect.Descriptor instead.
+func (*ResolveResponse) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *VerifyRequest) GetDirectory() string {
+func (x *ResolveResponse) GetPath() string {
 	if x != nil {
-		return x.Directory
+		return x.Path
 	}
 	return ""
 }
 
-type VerifyResponse struct {
+func (x *ResolveResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ResolveResponse) GetSourcePath() string {
+	if x != nil {
+		return x.SourcePath
+	}
+	return ""
+}
+
+func (x *ResolveResponse) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ResolveResponse) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *ResolveResponse) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+func (x *ResolveResponse) GetCachePath() string {
+	if x != nil {
+		return x.CachePath
+	}
+	return ""
+}
+
+type RemoveRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	Ok    bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
-	// Non-empty if verification failed.
-	Errors        []string `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Dependency path or alias to remove.
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// Report what would change in plan without writing anything.
+	DryRun bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// See AddRequest.idempotency_key.
+	IdempotencyKey string `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *VerifyResponse) Reset() {
-	*x = VerifyResponse{}
+func (x *RemoveRequest) Reset() {
+	*x = RemoveRequest{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *VerifyResponse) String() string {
+func (x *RemoveRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VerifyResponse) ProtoMessage() {}
+func (*RemoveRequest) ProtoMessage() {}
 
-func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
+func (x *RemoveRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -487,47 +1122,63 @@ func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
-func (*VerifyResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use RemoveRequest.ProtoReflect.Descriptor instead.
+func (*RemoveRequest) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *VerifyResponse) GetOk() bool {
+func (x *RemoveRequest) GetDirectory() string {
 	if x != nil {
-		return x.Ok
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *RemoveRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *RemoveRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
 	}
 	return false
 }
 
-func (x *VerifyResponse) GetErrors() []string {
+func (x *RemoveRequest) GetIdempotencyKey() string {
 	if x != nil {
-		return x.Errors
+		return x.IdempotencyKey
 	}
-	return nil
+	return ""
 }
 
-type GraphRequest struct {
+type RemoveResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Directory containing holon.mod.
-	Directory     string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Set when dry_run was requested.
+	Plan *Plan `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
+	// See AddResponse.root.
+	Root          string `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GraphRequest) Reset() {
-	*x = GraphRequest{}
+func (x *RemoveResponse) Reset() {
+	*x = RemoveResponse{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GraphRequest) String() string {
+func (x *RemoveResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GraphRequest) ProtoMessage() {}
+func (*RemoveResponse) ProtoMessage() {}
 
-func (x *GraphRequest) ProtoReflect() protoreflect.Message {
+func (x *RemoveResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -539,42 +1190,76 @@ func (x *GraphRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GraphRequest.ProtoReflect.Descriptor instead.
-func (*GraphRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use RemoveResponse.ProtoReflect.Descriptor instead.
+func (*RemoveResponse) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *GraphRequest) GetDirectory() string {
+func (x *RemoveResponse) GetPlan() *Plan {
 	if x != nil {
-		return x.Directory
+		return x.Plan
+	}
+	return nil
+}
+
+func (x *RemoveResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
 	}
 	return ""
 }
 
-type GraphResponse struct {
+type PullRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// The root holon path.
-	Root string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
-	// All edges in the dependency graph.
-	Edges         []*Edge `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// How to resolve dependencies the graph requires at several versions:
+	// "mvs" selects the highest version required, "highest" the highest
+	// version released with its major version. Defaults to the resolve
+	// directive of holon.mod, else "mvs".
+	Resolve string `protobuf:"bytes,2,opt,name=resolve,proto3" json:"resolve,omitempty"`
+	// Fetch only the dependencies of this require group (e.g. "runtime")
+	// and theirs; holon.lock is then left as it was. Empty for all groups.
+	Group string `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
+	// Optional dependencies, of holon.mod or of a dependency, to fetch too,
+	// by path, alias or capability provided; the others are skipped.
+	With []string `protobuf:"bytes,4,rep,name=with,proto3" json:"with,omitempty"`
+	// Delete and fetch again the cached snapshots that no longer hash to
+	// holon.sum, instead of failing with HASH_MISMATCH. Pull still fails if
+	// the snapshot fetched again does not match either: upstream changed.
+	Repair bool `protobuf:"varint,5,opt,name=repair,proto3" json:"repair,omitempty"`
+	// Accept snapshots fetched from upstream that do not hash to what
+	// holon.sum records, replacing their entries, or under the tofu
+	// directive to what was trusted of them on first use. By default Pull
+	// fails with HASH_MISMATCH and does not keep them in the cache.
+	Force bool `protobuf:"varint,6,opt,name=force,proto3" json:"force,omitempty"`
+	// Fail with BUDGET_EXCEEDED, before holon.sum is updated, when a size
+	// budget of holon.mod is exceeded, instead of only reporting it.
+	EnforceBudget bool `protobuf:"varint,7,opt,name=enforce_budget,json=enforceBudget,proto3" json:"enforce_budget,omitempty"`
+	// Fail with LOCK_STALE, before fetching anything, unless holon.lock
+	// matches holon.mod, the strategy and the optional dependencies asked
+	// for. By default Pull keeps to a holon.lock that matches and resolves
+	// the graph again, rewriting it, when it does not.
+	Locked        bool `protobuf:"varint,8,opt,name=locked,proto3" json:"locked,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GraphResponse) Reset() {
-	*x = GraphResponse{}
+func (x *PullRequest) Reset() {
+	*x = PullRequest{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GraphResponse) String() string {
+func (x *PullRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GraphResponse) ProtoMessage() {}
+func (*PullRequest) ProtoMessage() {}
 
-func (x *GraphResponse) ProtoReflect() protoreflect.Message {
+func (x *PullRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -586,48 +1271,111 @@ func (x *GraphResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GraphResponse.ProtoReflect.Descriptor instead.
-func (*GraphResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use PullRequest.ProtoReflect.Descriptor instead.
+func (*PullRequest) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *GraphResponse) GetRoot() string {
+func (x *PullRequest) GetDirectory() string {
 	if x != nil {
-		return x.Root
+		return x.Directory
 	}
 	return ""
 }
 
-func (x *GraphResponse) GetEdges() []*Edge {
+func (x *PullRequest) GetResolve() string {
 	if x != nil {
-		return x.Edges
+		return x.Resolve
+	}
+	return ""
+}
+
+func (x *PullRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *PullRequest) GetWith() []string {
+	if x != nil {
+		return x.With
 	}
 	return nil
 }
 
-type Edge struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
-	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
-	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+func (x *PullRequest) GetRepair() bool {
+	if x != nil {
+		return x.Repair
+	}
+	return false
+}
+
+func (x *PullRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *PullRequest) GetEnforceBudget() bool {
+	if x != nil {
+		return x.EnforceBudget
+	}
+	return false
+}
+
+func (x *PullRequest) GetLocked() bool {
+	if x != nil {
+		return x.Locked
+	}
+	return false
+}
+
+type PullResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Dependencies that were fetched or verified: those holon.mod requires,
+	// and the version selected for each dependency of the graph.
+	Fetched []*Dependency `protobuf:"bytes,1,rep,name=fetched,proto3" json:"fetched,omitempty"`
+	// The resolution strategy applied.
+	Strategy string `protobuf:"bytes,2,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	// The version selected for each dependency of the graph, as recorded in
+	// holon.lock.
+	Resolved []*Resolution `protobuf:"bytes,3,rep,name=resolved,proto3" json:"resolved,omitempty"`
+	// Optional dependencies of holon.mod that were not asked for, with the
+	// alias they can be asked for by, if any.
+	Skipped []*Dependency `protobuf:"bytes,4,rep,name=skipped,proto3" json:"skipped,omitempty"`
+	// See AddResponse.root.
+	Root string `protobuf:"bytes,5,opt,name=root,proto3" json:"root,omitempty"`
+	// Cached snapshots fetched again by repair.
+	Repaired []*Dependency `protobuf:"bytes,6,rep,name=repaired,proto3" json:"repaired,omitempty"`
+	// Size budgets of holon.mod the snapshots fetched exceed.
+	OverBudget []*BudgetExceeded `protobuf:"bytes,7,rep,name=over_budget,json=overBudget,proto3" json:"over_budget,omitempty"`
+	// Direct dependencies fetched whose version, or whole holon, their
+	// authors deprecated, as the latest release of each declares. Those
+	// already cached are not checked: see Freshness.
+	Deprecated []*Deprecation `protobuf:"bytes,8,rep,name=deprecated,proto3" json:"deprecated,omitempty"`
+	// Dependencies fetched that were trusted for the first time, under the
+	// tofu directive of holon.mod.
+	Trusted       []*Dependency `protobuf:"bytes,9,rep,name=trusted,proto3" json:"trusted,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Edge) Reset() {
-	*x = Edge{}
+func (x *PullResponse) Reset() {
+	*x = PullResponse{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Edge) String() string {
+func (x *PullResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Edge) ProtoMessage() {}
+func (*PullResponse) ProtoMessage() {}
 
-func (x *Edge) ProtoReflect() protoreflect.Message {
+func (x *PullResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -639,54 +1387,102 @@ func (x *Edge) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Edge.ProtoReflect.Descriptor instead.
-func (*Edge) Descriptor() ([]byte, []int) {
+// Deprecated: Use PullResponse.ProtoReflect.Descriptor instead.
+func (*PullResponse) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *Edge) GetFrom() string {
+func (x *PullResponse) GetFetched() []*Dependency {
 	if x != nil {
-		return x.From
+		return x.Fetched
 	}
-	return ""
+	return nil
 }
 
-func (x *Edge) GetTo() string {
+func (x *PullResponse) GetStrategy() string {
 	if x != nil {
-		return x.To
+		return x.Strategy
 	}
 	return ""
 }
 
-func (x *Edge) GetVersion() string {
+func (x *PullResponse) GetResolved() []*Resolution {
 	if x != nil {
-		return x.Version
+		return x.Resolved
+	}
+	return nil
+}
+
+func (x *PullResponse) GetSkipped() []*Dependency {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+func (x *PullResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
 	}
 	return ""
 }
 
-type UpdateRequest struct {
+func (x *PullResponse) GetRepaired() []*Dependency {
+	if x != nil {
+		return x.Repaired
+	}
+	return nil
+}
+
+func (x *PullResponse) GetOverBudget() []*BudgetExceeded {
+	if x != nil {
+		return x.OverBudget
+	}
+	return nil
+}
+
+func (x *PullResponse) GetDeprecated() []*Deprecation {
+	if x != nil {
+		return x.Deprecated
+	}
+	return nil
+}
+
+func (x *PullResponse) GetTrusted() []*Dependency {
+	if x != nil {
+		return x.Trusted
+	}
+	return nil
+}
+
+type LockRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Directory containing holon.mod.
-	Directory     string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// See PullRequest.directory.
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// See PullRequest.resolve.
+	Resolve string `protobuf:"bytes,2,opt,name=resolve,proto3" json:"resolve,omitempty"`
+	// Optional dependencies to resolve too, as PullRequest.with.
+	With []string `protobuf:"bytes,3,rep,name=with,proto3" json:"with,omitempty"`
+	// Resolve and report, without writing holon.lock.
+	DryRun        bool `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateRequest) Reset() {
-	*x = UpdateRequest{}
+func (x *LockRequest) Reset() {
+	*x = LockRequest{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateRequest) String() string {
+func (x *LockRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateRequest) ProtoMessage() {}
+func (*LockRequest) ProtoMessage() {}
 
-func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
+func (x *LockRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -698,40 +1494,67 @@ func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
-func (*UpdateRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use LockRequest.ProtoReflect.Descriptor instead.
+func (*LockRequest) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *UpdateRequest) GetDirectory() string {
+func (x *LockRequest) GetDirectory() string {
 	if x != nil {
 		return x.Directory
 	}
 	return ""
 }
 
-type UpdateResponse struct {
+func (x *LockRequest) GetResolve() string {
+	if x != nil {
+		return x.Resolve
+	}
+	return ""
+}
+
+func (x *LockRequest) GetWith() []string {
+	if x != nil {
+		return x.With
+	}
+	return nil
+}
+
+func (x *LockRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type LockResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Dependencies that were updated.
-	Updated       []*UpdatedDependency `protobuf:"bytes,1,rep,name=updated,proto3" json:"updated,omitempty"`
+	// See AddResponse.root.
+	Root string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// The resolution strategy applied.
+	Strategy string `protobuf:"bytes,2,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	// The version selected for each dependency of the graph.
+	Resolved []*Resolution `protobuf:"bytes,3,rep,name=resolved,proto3" json:"resolved,omitempty"`
+	// holon.lock was, or with dry_run would be, changed.
+	Changed       bool `protobuf:"varint,4,opt,name=changed,proto3" json:"changed,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateResponse) Reset() {
-	*x = UpdateResponse{}
+func (x *LockResponse) Reset() {
+	*x = LockResponse{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateResponse) String() string {
+func (x *LockResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateResponse) ProtoMessage() {}
+func (*LockResponse) ProtoMessage() {}
 
-func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
+func (x *LockResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -743,41 +1566,66 @@ func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateResponse.ProtoReflect.Descriptor instead.
-func (*UpdateResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use LockResponse.ProtoReflect.Descriptor instead.
+func (*LockResponse) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *UpdateResponse) GetUpdated() []*UpdatedDependency {
+func (x *LockResponse) GetRoot() string {
 	if x != nil {
-		return x.Updated
+		return x.Root
+	}
+	return ""
+}
+
+func (x *LockResponse) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+func (x *LockResponse) GetResolved() []*Resolution {
+	if x != nil {
+		return x.Resolved
 	}
 	return nil
 }
 
-type UpdatedDependency struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	OldVersion    string                 `protobuf:"bytes,2,opt,name=old_version,json=oldVersion,proto3" json:"old_version,omitempty"`
-	NewVersion    string                 `protobuf:"bytes,3,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
+func (x *LockResponse) GetChanged() bool {
+	if x != nil {
+		return x.Changed
+	}
+	return false
+}
+
+type PullProgress struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Stage PullStage              `protobuf:"varint,1,opt,name=stage,proto3,enum=rhizome_atlas.v1.PullStage" json:"stage,omitempty"`
+	// The dependency concerned. Unset on the last message.
+	Dependency *Dependency `protobuf:"bytes,2,opt,name=dependency,proto3" json:"dependency,omitempty"`
+	// Bytes transferred so far, for PULL_STAGE_TRANSFERRING.
+	Bytes int64 `protobuf:"varint,3,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	// Set on the last message of the stream only: what Pull returns.
+	Result        *PullResponse `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdatedDependency) Reset() {
-	*x = UpdatedDependency{}
+func (x *PullProgress) Reset() {
+	*x = PullProgress{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdatedDependency) String() string {
+func (x *PullProgress) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdatedDependency) ProtoMessage() {}
+func (*PullProgress) ProtoMessage() {}
 
-func (x *UpdatedDependency) ProtoReflect() protoreflect.Message {
+func (x *PullProgress) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -789,54 +1637,74 @@ func (x *UpdatedDependency) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdatedDependency.ProtoReflect.Descriptor instead.
-func (*UpdatedDependency) Descriptor() ([]byte, []int) {
+// Deprecated: Use PullProgress.ProtoReflect.Descriptor instead.
+func (*PullProgress) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *UpdatedDependency) GetPath() string {
+func (x *PullProgress) GetStage() PullStage {
 	if x != nil {
-		return x.Path
+		return x.Stage
 	}
-	return ""
+	return PullStage_PULL_STAGE_UNSPECIFIED
 }
 
-func (x *UpdatedDependency) GetOldVersion() string {
+func (x *PullProgress) GetDependency() *Dependency {
 	if x != nil {
-		return x.OldVersion
+		return x.Dependency
 	}
-	return ""
+	return nil
 }
 
-func (x *UpdatedDependency) GetNewVersion() string {
+func (x *PullProgress) GetBytes() int64 {
 	if x != nil {
-		return x.NewVersion
+		return x.Bytes
 	}
-	return ""
+	return 0
 }
 
-type VendorRequest struct {
+func (x *PullProgress) GetResult() *PullResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// Deprecation is a deprecation notice, declared by the latest release of a
+// dependency with a "deprecated" directive in its holon.mod, or with the
+// "deprecated" and "successor" keys of its HOLON.md front matter.
+type Deprecation struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Directory containing holon.mod.
-	Directory     string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The required version.
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// The whole holon is deprecated, not only version.
+	Holon bool `protobuf:"varint,3,opt,name=holon,proto3" json:"holon,omitempty"`
+	// Why, in the authors' words; may be empty.
+	Message string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	// What to use instead, if the authors suggest anything: a version, or a
+	// holon path with an optional @version.
+	Successor string `protobuf:"bytes,5,opt,name=successor,proto3" json:"successor,omitempty"`
+	// The release declaring the deprecation.
+	DeclaredBy    string `protobuf:"bytes,6,opt,name=declared_by,json=declaredBy,proto3" json:"declared_by,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *VendorRequest) Reset() {
-	*x = VendorRequest{}
+func (x *Deprecation) Reset() {
+	*x = Deprecation{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *VendorRequest) String() string {
+func (x *Deprecation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VendorRequest) ProtoMessage() {}
+func (*Deprecation) ProtoMessage() {}
 
-func (x *VendorRequest) ProtoReflect() protoreflect.Message {
+func (x *Deprecation) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -848,40 +1716,82 @@ func (x *VendorRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VendorRequest.ProtoReflect.Descriptor instead.
-func (*VendorRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Deprecation.ProtoReflect.Descriptor instead.
+func (*Deprecation) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *VendorRequest) GetDirectory() string {
+func (x *Deprecation) GetPath() string {
 	if x != nil {
-		return x.Directory
+		return x.Path
 	}
 	return ""
 }
 
-type VendorResponse struct {
+func (x *Deprecation) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Deprecation) GetHolon() bool {
+	if x != nil {
+		return x.Holon
+	}
+	return false
+}
+
+func (x *Deprecation) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Deprecation) GetSuccessor() string {
+	if x != nil {
+		return x.Successor
+	}
+	return ""
+}
+
+func (x *Deprecation) GetDeclaredBy() string {
+	if x != nil {
+		return x.DeclaredBy
+	}
+	return ""
+}
+
+// BudgetExceeded is a size budget, set by the budget directives of
+// holon.mod or the budget annotation of a require, that is exceeded.
+type BudgetExceeded struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Dependencies copied to .holon/.
-	Vendored      []*Dependency `protobuf:"bytes,1,rep,name=vendored,proto3" json:"vendored,omitempty"`
+	// The dependency, "<path>@<version>", over its budget; empty for the
+	// total of the closure.
+	Dependency string `protobuf:"bytes,1,opt,name=dependency,proto3" json:"dependency,omitempty"`
+	// Size, in bytes.
+	Size int64 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// Budget, in bytes.
+	Budget        int64 `protobuf:"varint,3,opt,name=budget,proto3" json:"budget,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *VendorResponse) Reset() {
-	*x = VendorResponse{}
+func (x *BudgetExceeded) Reset() {
+	*x = BudgetExceeded{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *VendorResponse) String() string {
+func (x *BudgetExceeded) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VendorResponse) ProtoMessage() {}
+func (*BudgetExceeded) ProtoMessage() {}
 
-func (x *VendorResponse) ProtoReflect() protoreflect.Message {
+func (x *BudgetExceeded) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -893,38 +1803,57 @@ func (x *VendorResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VendorResponse.ProtoReflect.Descriptor instead.
-func (*VendorResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use BudgetExceeded.ProtoReflect.Descriptor instead.
+func (*BudgetExceeded) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *VendorResponse) GetVendored() []*Dependency {
+func (x *BudgetExceeded) GetDependency() string {
 	if x != nil {
-		return x.Vendored
+		return x.Dependency
 	}
-	return nil
+	return ""
 }
 
-type CleanCacheRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+func (x *BudgetExceeded) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *BudgetExceeded) GetBudget() int64 {
+	if x != nil {
+		return x.Budget
+	}
+	return 0
+}
+
+type Resolution struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The selected version.
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// The holons of the graph requiring path.
+	RequiredBy    []*Requirement `protobuf:"bytes,3,rep,name=required_by,json=requiredBy,proto3" json:"required_by,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CleanCacheRequest) Reset() {
-	*x = CleanCacheRequest{}
+func (x *Resolution) Reset() {
+	*x = Resolution{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CleanCacheRequest) String() string {
+func (x *Resolution) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CleanCacheRequest) ProtoMessage() {}
+func (*Resolution) ProtoMessage() {}
 
-func (x *CleanCacheRequest) ProtoReflect() protoreflect.Message {
+func (x *Resolution) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -936,34 +1865,7710 @@ func (x *CleanCacheRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CleanCacheRequest.ProtoReflect.Descriptor instead.
-func (*CleanCacheRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Resolution.ProtoReflect.Descriptor instead.
+func (*Resolution) Descriptor() ([]byte, []int) {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{18}
 }
 
-type CleanCacheResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Path that was purged.
-	CachePath     string `protobuf:"bytes,1,opt,name=cache_path,json=cachePath,proto3" json:"cache_path,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Resolution) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
 }
 
-func (x *CleanCacheResponse) Reset() {
-	*x = CleanCacheResponse{}
+func (x *Resolution) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Resolution) GetRequiredBy() []*Requirement {
+	if x != nil {
+		return x.RequiredBy
+	}
+	return nil
+}
+
+type Requirement struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Holon string                 `protobuf:"bytes,1,opt,name=holon,proto3" json:"holon,omitempty"`
+	// The version holon requires.
+	Version       string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Requirement) Reset() {
+	*x = Requirement{}
 	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CleanCacheResponse) String() string {
+func (x *Requirement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Requirement) ProtoMessage() {}
+
+func (x *Requirement) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Requirement.ProtoReflect.Descriptor instead.
+func (*Requirement) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Requirement) GetHolon() string {
+	if x != nil {
+		return x.Holon
+	}
+	return ""
+}
+
+func (x *Requirement) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type VerifyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod and holon.sum.
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Also re-fetch every version in holon.sum from upstream into a
+	// temporary directory, and compare its hash to holon.sum and to the
+	// cache: this detects tags moved upstream.
+	Remote bool `protobuf:"varint,2,opt,name=remote,proto3" json:"remote,omitempty"`
+	// Also check holon.sum against the checksum log of the atlas server
+	// ATLAS_CACHE_REMOTE names: the records of the versions it served must
+	// match, and its log must have only grown since the last check.
+	Log bool `protobuf:"varint,3,opt,name=log,proto3" json:"log,omitempty"`
+	// Delete and fetch again the cached snapshots that no longer hash to
+	// holon.sum. Only those still mismatching once fetched again, because
+	// upstream changed, are then reported as errors.
+	Repair bool `protobuf:"varint,4,opt,name=repair,proto3" json:"repair,omitempty"`
+	// Escalate warnings to errors, failing verification.
+	Strict        bool `protobuf:"varint,5,opt,name=strict,proto3" json:"strict,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyRequest) Reset() {
+	*x = VerifyRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyRequest) ProtoMessage() {}
+
+func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *VerifyRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *VerifyRequest) GetRemote() bool {
+	if x != nil {
+		return x.Remote
+	}
+	return false
+}
+
+func (x *VerifyRequest) GetLog() bool {
+	if x != nil {
+		return x.Log
+	}
+	return false
+}
+
+func (x *VerifyRequest) GetRepair() bool {
+	if x != nil {
+		return x.Repair
+	}
+	return false
+}
+
+func (x *VerifyRequest) GetStrict() bool {
+	if x != nil {
+		return x.Strict
+	}
+	return false
+}
+
+type VerifyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// No error was found; warnings do not fail verification unless strict.
+	Ok bool `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	// The messages of the error findings; non-empty if verification failed.
+	Errors []string `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	// See AddResponse.root.
+	Root string `protobuf:"bytes,3,opt,name=root,proto3" json:"root,omitempty"`
+	// Cached snapshots fetched again by repair.
+	Repaired []*Dependency `protobuf:"bytes,4,rep,name=repaired,proto3" json:"repaired,omitempty"`
+	// Everything verification found, errors and warnings, in the order
+	// found.
+	Findings []*VerifyFinding `protobuf:"bytes,5,rep,name=findings,proto3" json:"findings,omitempty"`
+	// The messages of the warning findings.
+	Warnings      []string `protobuf:"bytes,6,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyResponse) Reset() {
+	*x = VerifyResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyResponse) ProtoMessage() {}
+
+func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *VerifyResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *VerifyResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *VerifyResponse) GetRepaired() []*Dependency {
+	if x != nil {
+		return x.Repaired
+	}
+	return nil
+}
+
+func (x *VerifyResponse) GetFindings() []*VerifyFinding {
+	if x != nil {
+		return x.Findings
+	}
+	return nil
+}
+
+func (x *VerifyResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+// A problem Verify found.
+type VerifyFinding struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ERROR for what breaks integrity: a hash mismatch, a snapshot missing
+	// from the cache, an upstream or log disagreement. WARNING for what is
+	// worth attention without breaking it: an active replace, a prerelease
+	// in use, a pin past its until date. Strict reports those as ERROR.
+	Severity DiagnosticSeverity `protobuf:"varint,1,opt,name=severity,proto3,enum=rhizome_atlas.v1.DiagnosticSeverity" json:"severity,omitempty"`
+	// What kind of problem it is, e.g. "hash-mismatch" or "active-replace".
+	Code          string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyFinding) Reset() {
+	*x = VerifyFinding{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyFinding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyFinding) ProtoMessage() {}
+
+func (x *VerifyFinding) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyFinding.ProtoReflect.Descriptor instead.
+func (*VerifyFinding) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *VerifyFinding) GetSeverity() DiagnosticSeverity {
+	if x != nil {
+		return x.Severity
+	}
+	return DiagnosticSeverity_DIAGNOSTIC_SEVERITY_ERROR
+}
+
+func (x *VerifyFinding) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *VerifyFinding) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type VerifyAllRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directories containing holon.mod and holon.sum.
+	Directories []string `protobuf:"bytes,1,rep,name=directories,proto3" json:"directories,omitempty"`
+	// If set, every holon.mod found under this directory is verified too.
+	// Hidden directories (such as .holon/ and .git/) are not searched.
+	Root string `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
+	// Verify against upstream as well, as VerifyRequest.remote.
+	Remote bool `protobuf:"varint,3,opt,name=remote,proto3" json:"remote,omitempty"`
+	// Verify against the checksum log as well, as VerifyRequest.log.
+	Log bool `protobuf:"varint,4,opt,name=log,proto3" json:"log,omitempty"`
+	// Fetch corrupted snapshots again, as VerifyRequest.repair.
+	Repair bool `protobuf:"varint,5,opt,name=repair,proto3" json:"repair,omitempty"`
+	// Escalate warnings to errors, as VerifyRequest.strict.
+	Strict        bool `protobuf:"varint,6,opt,name=strict,proto3" json:"strict,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyAllRequest) Reset() {
+	*x = VerifyAllRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyAllRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyAllRequest) ProtoMessage() {}
+
+func (x *VerifyAllRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyAllRequest.ProtoReflect.Descriptor instead.
+func (*VerifyAllRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *VerifyAllRequest) GetDirectories() []string {
+	if x != nil {
+		return x.Directories
+	}
+	return nil
+}
+
+func (x *VerifyAllRequest) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *VerifyAllRequest) GetRemote() bool {
+	if x != nil {
+		return x.Remote
+	}
+	return false
+}
+
+func (x *VerifyAllRequest) GetLog() bool {
+	if x != nil {
+		return x.Log
+	}
+	return false
+}
+
+func (x *VerifyAllRequest) GetRepair() bool {
+	if x != nil {
+		return x.Repair
+	}
+	return false
+}
+
+func (x *VerifyAllRequest) GetStrict() bool {
+	if x != nil {
+		return x.Strict
+	}
+	return false
+}
+
+type VerifyAllResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// True if every holon verified.
+	Ok bool `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	// One result per holon, in request order then discovery order.
+	Results       []*HolonVerification `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyAllResponse) Reset() {
+	*x = VerifyAllResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyAllResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyAllResponse) ProtoMessage() {}
+
+func (x *VerifyAllResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyAllResponse.ProtoReflect.Descriptor instead.
+func (*VerifyAllResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *VerifyAllResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *VerifyAllResponse) GetResults() []*HolonVerification {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type HolonVerification struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Directory string                 `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	Ok        bool                   `protobuf:"varint,2,opt,name=ok,proto3" json:"ok,omitempty"`
+	// Non-empty if verification failed.
+	Errors []string `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	// See VerifyResponse.warnings.
+	Warnings      []string `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HolonVerification) Reset() {
+	*x = HolonVerification{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HolonVerification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HolonVerification) ProtoMessage() {}
+
+func (x *HolonVerification) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HolonVerification.ProtoReflect.Descriptor instead.
+func (*HolonVerification) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *HolonVerification) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *HolonVerification) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *HolonVerification) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *HolonVerification) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type GraphRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Keep only the requires annotated with all of these key=value pairs,
+	// and the edges below them.
+	Filter        map[string]string `protobuf:"bytes,2,rep,name=filter,proto3" json:"filter,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GraphRequest) Reset() {
+	*x = GraphRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GraphRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GraphRequest) ProtoMessage() {}
+
+func (x *GraphRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GraphRequest.ProtoReflect.Descriptor instead.
+func (*GraphRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GraphRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *GraphRequest) GetFilter() map[string]string {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type GraphResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The root holon path.
+	Root string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// All edges in the dependency graph.
+	Edges []*Edge `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"`
+	// Every holon of the graph, the root first, then in the order edges
+	// first reach them.
+	Nodes         []*Node `protobuf:"bytes,3,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GraphResponse) Reset() {
+	*x = GraphResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GraphResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GraphResponse) ProtoMessage() {}
+
+func (x *GraphResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GraphResponse.ProtoReflect.Descriptor instead.
+func (*GraphResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GraphResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *GraphResponse) GetEdges() []*Edge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+func (x *GraphResponse) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+// Node is a holon of the dependency graph, or a major version of it when
+// majors coexist.
+type Node struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The version minimum version selection picks: the highest of its major
+	// the edges to path require. Empty for the root.
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Where its sources are: "replace", "cache", "vendor", or "missing"
+	// when nowhere. Empty for the root.
+	Location string `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
+	// The local directory or path the root holon.mod replaces it with.
+	Replace string `protobuf:"bytes,4,opt,name=replace,proto3" json:"replace,omitempty"`
+	// The name in its HOLON.md front-matter, when found.
+	Title         string `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *Node) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Node) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Node) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *Node) GetReplace() string {
+	if x != nil {
+		return x.Replace
+	}
+	return ""
+}
+
+func (x *Node) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+type Edge struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	From    string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To      string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Version string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	// Alias given to "to" by the holon "from", if any.
+	Alias string `protobuf:"bytes,4,opt,name=alias,proto3" json:"alias,omitempty"`
+	// Annotations of the require line (e.g. scope=test).
+	Metadata map[string]string `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The version of "from" whose holon.mod lists the require, telling
+	// apart the nodes of coexisting majors. Empty for the root.
+	FromVersion   string `protobuf:"bytes,6,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Edge) Reset() {
+	*x = Edge{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Edge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Edge) ProtoMessage() {}
+
+func (x *Edge) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Edge.ProtoReflect.Descriptor instead.
+func (*Edge) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Edge) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Edge) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *Edge) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Edge) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *Edge) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Edge) GetFromVersion() string {
+	if x != nil {
+		return x.FromVersion
+	}
+	return ""
+}
+
+type StreamGraphRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Maximum number of edges per chunk (default 500).
+	ChunkSize int32 `protobuf:"varint,2,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	// Same as GraphRequest.filter.
+	Filter        map[string]string `protobuf:"bytes,3,rep,name=filter,proto3" json:"filter,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamGraphRequest) Reset() {
+	*x = StreamGraphRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamGraphRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamGraphRequest) ProtoMessage() {}
+
+func (x *StreamGraphRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamGraphRequest.ProtoReflect.Descriptor instead.
+func (*StreamGraphRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *StreamGraphRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *StreamGraphRequest) GetChunkSize() int32 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+func (x *StreamGraphRequest) GetFilter() map[string]string {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type GraphChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The root holon path, repeated on every chunk.
+	Root string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// Next edges of the graph.
+	Edges []*Edge `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"`
+	// Set on the last message of the stream only.
+	Summary *GraphSummary `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+	// Every holon of the graph, as GraphResponse.nodes. Set on the last
+	// message of the stream only.
+	Nodes         []*Node `protobuf:"bytes,4,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GraphChunk) Reset() {
+	*x = GraphChunk{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GraphChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GraphChunk) ProtoMessage() {}
+
+func (x *GraphChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GraphChunk.ProtoReflect.Descriptor instead.
+func (*GraphChunk) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GraphChunk) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *GraphChunk) GetEdges() []*Edge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+func (x *GraphChunk) GetSummary() *GraphSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *GraphChunk) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type GraphSummary struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Total number of edges streamed.
+	EdgeCount     int32 `protobuf:"varint,1,opt,name=edge_count,json=edgeCount,proto3" json:"edge_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GraphSummary) Reset() {
+	*x = GraphSummary{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GraphSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GraphSummary) ProtoMessage() {}
+
+func (x *GraphSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GraphSummary.ProtoReflect.Descriptor instead.
+func (*GraphSummary) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GraphSummary) GetEdgeCount() int32 {
+	if x != nil {
+		return x.EdgeCount
+	}
+	return 0
+}
+
+type UpdateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Apply updates that remove capabilities declared in HOLON.md.
+	AllowBreaking bool `protobuf:"varint,2,opt,name=allow_breaking,json=allowBreaking,proto3" json:"allow_breaking,omitempty"`
+	// Also consider prerelease tags of this channel (e.g. "beta", "rc").
+	// By default each dependency stays in the channel of its version.
+	Channel string `protobuf:"bytes,3,opt,name=channel,proto3" json:"channel,omitempty"`
+	// Report what would change in plan without writing, fetching or
+	// evicting anything. The contract check needs the new versions and is
+	// skipped, so no update is held.
+	DryRun bool `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// See AddRequest.idempotency_key.
+	IdempotencyKey string `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Collect the release notes of the updated dependencies into
+	// UpdateResponse.changelog.
+	Changelog bool `protobuf:"varint,6,opt,name=changelog,proto3" json:"changelog,omitempty"`
+	// Commit the updates to git branches instead of leaving holon.mod
+	// changed: the work tree of directory must have no uncommitted change,
+	// and is left as it was. Ignored with dry_run.
+	Branching UpdateBranching `protobuf:"varint,7,opt,name=branching,proto3,enum=rhizome_atlas.v1.UpdateBranching" json:"branching,omitempty"`
+	// Push the branches to the "origin" remote, replacing those of an
+	// earlier run. Needs branching.
+	Push          bool `protobuf:"varint,8,opt,name=push,proto3" json:"push,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRequest) Reset() {
+	*x = UpdateRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRequest) ProtoMessage() {}
+
+func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *UpdateRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetAllowBreaking() bool {
+	if x != nil {
+		return x.AllowBreaking
+	}
+	return false
+}
+
+func (x *UpdateRequest) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *UpdateRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetChangelog() bool {
+	if x != nil {
+		return x.Changelog
+	}
+	return false
+}
+
+func (x *UpdateRequest) GetBranching() UpdateBranching {
+	if x != nil {
+		return x.Branching
+	}
+	return UpdateBranching_UPDATE_BRANCHING_UNSPECIFIED
+}
+
+func (x *UpdateRequest) GetPush() bool {
+	if x != nil {
+		return x.Push
+	}
+	return false
+}
+
+type UpdateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Dependencies that were updated.
+	Updated []*UpdatedDependency `protobuf:"bytes,1,rep,name=updated,proto3" json:"updated,omitempty"`
+	// Breaking updates, and those whose contract could not be checked, left
+	// unapplied because allow_breaking was not set.
+	Held []*UpdatedDependency `protobuf:"bytes,2,rep,name=held,proto3" json:"held,omitempty"`
+	// Set when dry_run was requested.
+	Plan *Plan `protobuf:"bytes,3,opt,name=plan,proto3" json:"plan,omitempty"`
+	// Markdown summary of the updates and their release notes, e.g. for a
+	// pull request description. Set when changelog was requested.
+	Changelog string `protobuf:"bytes,4,opt,name=changelog,proto3" json:"changelog,omitempty"`
+	// See AddResponse.root.
+	Root string `protobuf:"bytes,5,opt,name=root,proto3" json:"root,omitempty"`
+	// Required versions whose upstream tag no longer points to the commit
+	// holon.sum pins: the tag was force-moved. They are left as they are.
+	Moved []*MovedTag `protobuf:"bytes,6,rep,name=moved,proto3" json:"moved,omitempty"`
+	// The branches committed, when branching was requested.
+	Branches      []*UpdateBranch `protobuf:"bytes,7,rep,name=branches,proto3" json:"branches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateResponse) Reset() {
+	*x = UpdateResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateResponse) ProtoMessage() {}
+
+func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateResponse.ProtoReflect.Descriptor instead.
+func (*UpdateResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *UpdateResponse) GetUpdated() []*UpdatedDependency {
+	if x != nil {
+		return x.Updated
+	}
+	return nil
+}
+
+func (x *UpdateResponse) GetHeld() []*UpdatedDependency {
+	if x != nil {
+		return x.Held
+	}
+	return nil
+}
+
+func (x *UpdateResponse) GetPlan() *Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+func (x *UpdateResponse) GetChangelog() string {
+	if x != nil {
+		return x.Changelog
+	}
+	return ""
+}
+
+func (x *UpdateResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *UpdateResponse) GetMoved() []*MovedTag {
+	if x != nil {
+		return x.Moved
+	}
+	return nil
+}
+
+func (x *UpdateResponse) GetBranches() []*UpdateBranch {
+	if x != nil {
+		return x.Branches
+	}
+	return nil
+}
+
+// UpdateBranch is a git branch forked from HEAD, with one commit updating
+// holon.mod and, pulled, holon.sum and holon.lock.
+type UpdateBranch struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Name   string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Commit string                 `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`
+	// The updates it commits.
+	Updated []*UpdatedDependency `protobuf:"bytes,3,rep,name=updated,proto3" json:"updated,omitempty"`
+	// It was pushed to "origin".
+	Pushed        bool `protobuf:"varint,4,opt,name=pushed,proto3" json:"pushed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBranch) Reset() {
+	*x = UpdateBranch{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBranch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBranch) ProtoMessage() {}
+
+func (x *UpdateBranch) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBranch.ProtoReflect.Descriptor instead.
+func (*UpdateBranch) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *UpdateBranch) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateBranch) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *UpdateBranch) GetUpdated() []*UpdatedDependency {
+	if x != nil {
+		return x.Updated
+	}
+	return nil
+}
+
+func (x *UpdateBranch) GetPushed() bool {
+	if x != nil {
+		return x.Pushed
+	}
+	return false
+}
+
+type MovedTag struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Path    string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Commit holon.sum pins the version to.
+	PinnedCommit string `protobuf:"bytes,3,opt,name=pinned_commit,json=pinnedCommit,proto3" json:"pinned_commit,omitempty"`
+	// Commit the upstream tag points to now.
+	UpstreamCommit string `protobuf:"bytes,4,opt,name=upstream_commit,json=upstreamCommit,proto3" json:"upstream_commit,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *MovedTag) Reset() {
+	*x = MovedTag{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MovedTag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MovedTag) ProtoMessage() {}
+
+func (x *MovedTag) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MovedTag.ProtoReflect.Descriptor instead.
+func (*MovedTag) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *MovedTag) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *MovedTag) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *MovedTag) GetPinnedCommit() string {
+	if x != nil {
+		return x.PinnedCommit
+	}
+	return ""
+}
+
+func (x *MovedTag) GetUpstreamCommit() string {
+	if x != nil {
+		return x.UpstreamCommit
+	}
+	return ""
+}
+
+type UpdatedDependency struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Path       string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	OldVersion string                 `protobuf:"bytes,2,opt,name=old_version,json=oldVersion,proto3" json:"old_version,omitempty"`
+	NewVersion string                 `protobuf:"bytes,3,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
+	// Capabilities declared by old_version but not by new_version.
+	RemovedCapabilities []string `protobuf:"bytes,4,rep,name=removed_capabilities,json=removedCapabilities,proto3" json:"removed_capabilities,omitempty"`
+	// Markdown release notes of the versions after old_version up to
+	// new_version: their releases on the forge hosting the dependency, or
+	// the new sections of CHANGELOG.md (read from the cache only on a dry
+	// run). Set when changelog was requested and notes were found.
+	Notes string `protobuf:"bytes,5,opt,name=notes,proto3" json:"notes,omitempty"`
+	// Why the contract check comparing the HOLON.md of both versions could
+	// not run, e.g. a version that could not be fetched. Such an update is
+	// held like a breaking one, unless allow_breaking.
+	ContractError string `protobuf:"bytes,6,opt,name=contract_error,json=contractError,proto3" json:"contract_error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatedDependency) Reset() {
+	*x = UpdatedDependency{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatedDependency) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatedDependency) ProtoMessage() {}
+
+func (x *UpdatedDependency) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatedDependency.ProtoReflect.Descriptor instead.
+func (*UpdatedDependency) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *UpdatedDependency) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *UpdatedDependency) GetOldVersion() string {
+	if x != nil {
+		return x.OldVersion
+	}
+	return ""
+}
+
+func (x *UpdatedDependency) GetNewVersion() string {
+	if x != nil {
+		return x.NewVersion
+	}
+	return ""
+}
+
+func (x *UpdatedDependency) GetRemovedCapabilities() []string {
+	if x != nil {
+		return x.RemovedCapabilities
+	}
+	return nil
+}
+
+func (x *UpdatedDependency) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *UpdatedDependency) GetContractError() string {
+	if x != nil {
+		return x.ContractError
+	}
+	return ""
+}
+
+type VendorRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Report what would be copied and deleted in plan without doing it.
+	DryRun bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// See AddRequest.idempotency_key.
+	IdempotencyKey string `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Vendor only the dependencies of this require group. Empty for all
+	// groups.
+	Group string `protobuf:"bytes,4,opt,name=group,proto3" json:"group,omitempty"`
+	// Fail with BUDGET_EXCEEDED, before anything is copied, when the
+	// vendored copies would exceed a size budget of holon.mod.
+	EnforceBudget bool `protobuf:"varint,5,opt,name=enforce_budget,json=enforceBudget,proto3" json:"enforce_budget,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VendorRequest) Reset() {
+	*x = VendorRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VendorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VendorRequest) ProtoMessage() {}
+
+func (x *VendorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VendorRequest.ProtoReflect.Descriptor instead.
+func (*VendorRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *VendorRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *VendorRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *VendorRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *VendorRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *VendorRequest) GetEnforceBudget() bool {
+	if x != nil {
+		return x.EnforceBudget
+	}
+	return false
+}
+
+type VendorResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Dependencies copied to .holon/.
+	Vendored []*Dependency `protobuf:"bytes,1,rep,name=vendored,proto3" json:"vendored,omitempty"`
+	// Set when dry_run was requested.
+	Plan *Plan `protobuf:"bytes,2,opt,name=plan,proto3" json:"plan,omitempty"`
+	// See AddResponse.root.
+	Root string `protobuf:"bytes,3,opt,name=root,proto3" json:"root,omitempty"`
+	// Size budgets of holon.mod the vendored copies exceed.
+	OverBudget    []*BudgetExceeded `protobuf:"bytes,4,rep,name=over_budget,json=overBudget,proto3" json:"over_budget,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VendorResponse) Reset() {
+	*x = VendorResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VendorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VendorResponse) ProtoMessage() {}
+
+func (x *VendorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VendorResponse.ProtoReflect.Descriptor instead.
+func (*VendorResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *VendorResponse) GetVendored() []*Dependency {
+	if x != nil {
+		return x.Vendored
+	}
+	return nil
+}
+
+func (x *VendorResponse) GetPlan() *Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+func (x *VendorResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *VendorResponse) GetOverBudget() []*BudgetExceeded {
+	if x != nil {
+		return x.OverBudget
+	}
+	return nil
+}
+
+type StreamVendorRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Same as VendorRequest.group.
+	Group string `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	// Report the dependencies missing from the cache or failing to copy,
+	// and vendor the others, instead of ending the stream at the first.
+	KeepGoing bool `protobuf:"varint,3,opt,name=keep_going,json=keepGoing,proto3" json:"keep_going,omitempty"`
+	// Same as VendorRequest.enforce_budget.
+	EnforceBudget bool `protobuf:"varint,4,opt,name=enforce_budget,json=enforceBudget,proto3" json:"enforce_budget,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamVendorRequest) Reset() {
+	*x = StreamVendorRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamVendorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamVendorRequest) ProtoMessage() {}
+
+func (x *StreamVendorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamVendorRequest.ProtoReflect.Descriptor instead.
+func (*StreamVendorRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *StreamVendorRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *StreamVendorRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *StreamVendorRequest) GetKeepGoing() bool {
+	if x != nil {
+		return x.KeepGoing
+	}
+	return false
+}
+
+func (x *StreamVendorRequest) GetEnforceBudget() bool {
+	if x != nil {
+		return x.EnforceBudget
+	}
+	return false
+}
+
+type VendorProgress struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The dependency just handled, with the directory it was copied to.
+	// Unset on the last message.
+	Dependency *Dependency `protobuf:"bytes,1,opt,name=dependency,proto3" json:"dependency,omitempty"`
+	// Why the dependency could not be vendored; empty if it was.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Position of the dependency among those to vendor, from 1, and their
+	// number.
+	Index int32 `protobuf:"varint,3,opt,name=index,proto3" json:"index,omitempty"`
+	Total int32 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	// Bytes copied for the dependency.
+	Bytes int64 `protobuf:"varint,5,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	// Set on the last message of the stream only.
+	Summary       *VendorSummary `protobuf:"bytes,6,opt,name=summary,proto3" json:"summary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VendorProgress) Reset() {
+	*x = VendorProgress{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VendorProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VendorProgress) ProtoMessage() {}
+
+func (x *VendorProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VendorProgress.ProtoReflect.Descriptor instead.
+func (*VendorProgress) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *VendorProgress) GetDependency() *Dependency {
+	if x != nil {
+		return x.Dependency
+	}
+	return nil
+}
+
+func (x *VendorProgress) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *VendorProgress) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *VendorProgress) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *VendorProgress) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *VendorProgress) GetSummary() *VendorSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+type VendorSummary struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of dependencies vendored, and failed with keep_going.
+	Vendored int32 `protobuf:"varint,1,opt,name=vendored,proto3" json:"vendored,omitempty"`
+	Failed   int32 `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+	// Total bytes copied.
+	Bytes int64 `protobuf:"varint,3,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	// See AddResponse.root.
+	Root string `protobuf:"bytes,4,opt,name=root,proto3" json:"root,omitempty"`
+	// Size budgets of holon.mod the vendored copies exceed.
+	OverBudget    []*BudgetExceeded `protobuf:"bytes,5,rep,name=over_budget,json=overBudget,proto3" json:"over_budget,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VendorSummary) Reset() {
+	*x = VendorSummary{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VendorSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VendorSummary) ProtoMessage() {}
+
+func (x *VendorSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VendorSummary.ProtoReflect.Descriptor instead.
+func (*VendorSummary) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *VendorSummary) GetVendored() int32 {
+	if x != nil {
+		return x.Vendored
+	}
+	return 0
+}
+
+func (x *VendorSummary) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *VendorSummary) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *VendorSummary) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *VendorSummary) GetOverBudget() []*BudgetExceeded {
+	if x != nil {
+		return x.OverBudget
+	}
+	return nil
+}
+
+type CleanCacheRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Report what would be deleted in plan without doing it.
+	DryRun        bool `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CleanCacheRequest) Reset() {
+	*x = CleanCacheRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CleanCacheRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CleanCacheRequest) ProtoMessage() {}
+
+func (x *CleanCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CleanCacheRequest.ProtoReflect.Descriptor instead.
+func (*CleanCacheRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *CleanCacheRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type CleanCacheResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path that was purged.
+	CachePath string `protobuf:"bytes,1,opt,name=cache_path,json=cachePath,proto3" json:"cache_path,omitempty"`
+	// Set when dry_run was requested.
+	Plan          *Plan `protobuf:"bytes,2,opt,name=plan,proto3" json:"plan,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CleanCacheResponse) Reset() {
+	*x = CleanCacheResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CleanCacheResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CleanCacheResponse) ProtoMessage() {}
+
+func (x *CleanCacheResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CleanCacheResponse.ProtoReflect.Descriptor instead.
+func (*CleanCacheResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *CleanCacheResponse) GetCachePath() string {
+	if x != nil {
+		return x.CachePath
+	}
+	return ""
+}
+
+func (x *CleanCacheResponse) GetPlan() *Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+type CacheListRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Maximum number of entries to return (default 100, at most 1000).
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token of the previous response; empty for the first page.
+	PageToken     string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheListRequest) Reset() {
+	*x = CacheListRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheListRequest) ProtoMessage() {}
+
+func (x *CacheListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheListRequest.ProtoReflect.Descriptor instead.
+func (*CacheListRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *CacheListRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *CacheListRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type CacheListResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Entries of this page, sorted by path then version.
+	Entries []*CacheEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	// Token for the next page; empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheListResponse) Reset() {
+	*x = CacheListResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheListResponse) ProtoMessage() {}
+
+func (x *CacheListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheListResponse.ProtoReflect.Descriptor instead.
+func (*CacheListResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *CacheListResponse) GetEntries() []*CacheEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *CacheListResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type CacheEntry struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Path    string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Directory of the cached snapshot.
+	CachePath string `protobuf:"bytes,3,opt,name=cache_path,json=cachePath,proto3" json:"cache_path,omitempty"`
+	// Total size of the snapshot, in bytes.
+	Size int64 `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	// How the snapshot was fetched, when recorded.
+	Fetch         *FetchInfo `protobuf:"bytes,5,opt,name=fetch,proto3" json:"fetch,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheEntry) Reset() {
+	*x = CacheEntry{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheEntry) ProtoMessage() {}
+
+func (x *CacheEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheEntry.ProtoReflect.Descriptor instead.
+func (*CacheEntry) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *CacheEntry) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *CacheEntry) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *CacheEntry) GetCachePath() string {
+	if x != nil {
+		return x.CachePath
+	}
+	return ""
+}
+
+func (x *CacheEntry) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CacheEntry) GetFetch() *FetchInfo {
+	if x != nil {
+		return x.Fetch
+	}
+	return nil
+}
+
+// FetchInfo is the metadata recorded with a cached snapshot when it is
+// fetched.
+type FetchInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// What served the content: a git URL for the git fetcher, the source
+	// of a registered fetcher, or "file://<bundle>" for a bundle installed.
+	Source string `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// "git" for the git fetcher, "bundle" for a bundle installed, else the
+	// Go type of the registered fetcher.
+	Fetcher string `protobuf:"bytes,2,opt,name=fetcher,proto3" json:"fetcher,omitempty"`
+	// When the content was fetched, in RFC 3339.
+	Time string `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	// Commit the version resolved to, when the fetcher tells.
+	Commit string `protobuf:"bytes,4,opt,name=commit,proto3" json:"commit,omitempty"`
+	// Hash of the annotated tag object of the version, when it is one.
+	TagObject     string `protobuf:"bytes,5,opt,name=tag_object,json=tagObject,proto3" json:"tag_object,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchInfo) Reset() {
+	*x = FetchInfo{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchInfo) ProtoMessage() {}
+
+func (x *FetchInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchInfo.ProtoReflect.Descriptor instead.
+func (*FetchInfo) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *FetchInfo) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *FetchInfo) GetFetcher() string {
+	if x != nil {
+		return x.Fetcher
+	}
+	return ""
+}
+
+func (x *FetchInfo) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *FetchInfo) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *FetchInfo) GetTagObject() string {
+	if x != nil {
+		return x.TagObject
+	}
+	return ""
+}
+
+type CacheOrphansRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directories whose holon.sum references cache entries.
+	Directories []string `protobuf:"bytes,1,rep,name=directories,proto3" json:"directories,omitempty"`
+	// If set, the holon.sum of every holon.mod found under this directory
+	// references cache entries too. Hidden directories are not searched.
+	Root string `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
+	// Delete the orphaned entries rather than only list them.
+	Delete        bool `protobuf:"varint,3,opt,name=delete,proto3" json:"delete,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheOrphansRequest) Reset() {
+	*x = CacheOrphansRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheOrphansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheOrphansRequest) ProtoMessage() {}
+
+func (x *CacheOrphansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheOrphansRequest.ProtoReflect.Descriptor instead.
+func (*CacheOrphansRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *CacheOrphansRequest) GetDirectories() []string {
+	if x != nil {
+		return x.Directories
+	}
+	return nil
+}
+
+func (x *CacheOrphansRequest) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *CacheOrphansRequest) GetDelete() bool {
+	if x != nil {
+		return x.Delete
+	}
+	return false
+}
+
+type CacheOrphansResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Entries no holon.sum references, sorted by path then version.
+	Orphans []*CacheEntry `protobuf:"bytes,1,rep,name=orphans,proto3" json:"orphans,omitempty"`
+	// Total size of the orphans, in bytes.
+	Bytes int64 `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	// The holon.sum files cross-referenced, missing ones included.
+	SumFiles      []string `protobuf:"bytes,3,rep,name=sum_files,json=sumFiles,proto3" json:"sum_files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheOrphansResponse) Reset() {
+	*x = CacheOrphansResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheOrphansResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheOrphansResponse) ProtoMessage() {}
+
+func (x *CacheOrphansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheOrphansResponse.ProtoReflect.Descriptor instead.
+func (*CacheOrphansResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *CacheOrphansResponse) GetOrphans() []*CacheEntry {
+	if x != nil {
+		return x.Orphans
+	}
+	return nil
+}
+
+func (x *CacheOrphansResponse) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *CacheOrphansResponse) GetSumFiles() []string {
+	if x != nil {
+		return x.SumFiles
+	}
+	return nil
+}
+
+type CacheSeedRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, holon.sum and the vendor directory.
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Report what would be seeded without writing to the cache.
+	DryRun        bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheSeedRequest) Reset() {
+	*x = CacheSeedRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheSeedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheSeedRequest) ProtoMessage() {}
+
+func (x *CacheSeedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheSeedRequest.ProtoReflect.Descriptor instead.
+func (*CacheSeedRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *CacheSeedRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *CacheSeedRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type CacheSeedResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing the holon.mod used.
+	Root string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// Dependencies installed in the cache from their vendored copy.
+	Seeded []*Dependency `protobuf:"bytes,2,rep,name=seeded,proto3" json:"seeded,omitempty"`
+	// Dependencies vendored that the cache already held.
+	Present []*Dependency `protobuf:"bytes,3,rep,name=present,proto3" json:"present,omitempty"`
+	// What a dry run would do.
+	Plan          *Plan `protobuf:"bytes,4,opt,name=plan,proto3" json:"plan,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheSeedResponse) Reset() {
+	*x = CacheSeedResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheSeedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheSeedResponse) ProtoMessage() {}
+
+func (x *CacheSeedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheSeedResponse.ProtoReflect.Descriptor instead.
+func (*CacheSeedResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *CacheSeedResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *CacheSeedResponse) GetSeeded() []*Dependency {
+	if x != nil {
+		return x.Seeded
+	}
+	return nil
+}
+
+func (x *CacheSeedResponse) GetPresent() []*Dependency {
+	if x != nil {
+		return x.Present
+	}
+	return nil
+}
+
+func (x *CacheSeedResponse) GetPlan() *Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+type CachePushRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Holon path of the cache entry.
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Version of the cache entry.
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Where to push: "oci://<registry>/<repository>[:<tag>]". The tag
+	// defaults to the version, with "+" replaced by "_".
+	Target        string `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CachePushRequest) Reset() {
+	*x = CachePushRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CachePushRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CachePushRequest) ProtoMessage() {}
+
+func (x *CachePushRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CachePushRequest.ProtoReflect.Descriptor instead.
+func (*CachePushRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *CachePushRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *CachePushRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *CachePushRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+type CachePushResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Reference pushed, "<registry>/<repository>:<tag>".
+	Reference string `protobuf:"bytes,1,opt,name=reference,proto3" json:"reference,omitempty"`
+	// Digest of the artifact manifest, "sha256:<hex>".
+	Digest string `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	// Size of the snapshot layer, in bytes.
+	Size int64 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	// Annotations of the manifest.
+	Annotations   map[string]string `protobuf:"bytes,4,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CachePushResponse) Reset() {
+	*x = CachePushResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CachePushResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CachePushResponse) ProtoMessage() {}
+
+func (x *CachePushResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CachePushResponse.ProtoReflect.Descriptor instead.
+func (*CachePushResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *CachePushResponse) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *CachePushResponse) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *CachePushResponse) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CachePushResponse) GetAnnotations() map[string]string {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+type HasEntryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HasEntryRequest) Reset() {
+	*x = HasEntryRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HasEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HasEntryRequest) ProtoMessage() {}
+
+func (x *HasEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HasEntryRequest.ProtoReflect.Descriptor instead.
+func (*HasEntryRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *HasEntryRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *HasEntryRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type HasEntryResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Present bool                   `protobuf:"varint,1,opt,name=present,proto3" json:"present,omitempty"`
+	// Total size of the snapshot files, in bytes, when present.
+	Size          int64 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HasEntryResponse) Reset() {
+	*x = HasEntryResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HasEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HasEntryResponse) ProtoMessage() {}
+
+func (x *HasEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HasEntryResponse.ProtoReflect.Descriptor instead.
+func (*HasEntryResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *HasEntryResponse) GetPresent() bool {
+	if x != nil {
+		return x.Present
+	}
+	return false
+}
+
+func (x *HasEntryResponse) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type FetchEntryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchEntryRequest) Reset() {
+	*x = FetchEntryRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchEntryRequest) ProtoMessage() {}
+
+func (x *FetchEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchEntryRequest.ProtoReflect.Descriptor instead.
+func (*FetchEntryRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *FetchEntryRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FetchEntryRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type FetchEntryChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Next bytes of the zip archive of the snapshot.
+	Data          []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchEntryChunk) Reset() {
+	*x = FetchEntryChunk{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchEntryChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchEntryChunk) ProtoMessage() {}
+
+func (x *FetchEntryChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchEntryChunk.ProtoReflect.Descriptor instead.
+func (*FetchEntryChunk) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *FetchEntryChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type GetLogHeadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLogHeadRequest) Reset() {
+	*x = GetLogHeadRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLogHeadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLogHeadRequest) ProtoMessage() {}
+
+func (x *GetLogHeadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLogHeadRequest.ProtoReflect.Descriptor instead.
+func (*GetLogHeadRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{59}
+}
+
+type LogHead struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of records in the log.
+	Size int64 `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	// Root hash of the tree of these records.
+	RootHash []byte `protobuf:"bytes,2,opt,name=root_hash,json=rootHash,proto3" json:"root_hash,omitempty"`
+	// When a server with a signing key (ATLAS_LOG_SIGNING_KEY) signed the
+	// head, and until when it may be trusted, in Unix seconds. A client
+	// pinning the key (ATLAS_LOG_KEY) refuses an expired head, so that a
+	// server cannot keep showing an old log.
+	Timestamp int64 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Expires   int64 `protobuf:"varint,4,opt,name=expires,proto3" json:"expires,omitempty"`
+	// Ed25519 signature of the fields above.
+	Signature []byte `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+	// Public key of the signature, for operators to pin; clients only
+	// trust the key of ATLAS_LOG_KEY.
+	PublicKey     []byte `protobuf:"bytes,6,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogHead) Reset() {
+	*x = LogHead{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogHead) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogHead) ProtoMessage() {}
+
+func (x *LogHead) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogHead.ProtoReflect.Descriptor instead.
+func (*LogHead) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *LogHead) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *LogHead) GetRootHash() []byte {
+	if x != nil {
+		return x.RootHash
+	}
+	return nil
+}
+
+func (x *LogHead) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *LogHead) GetExpires() int64 {
+	if x != nil {
+		return x.Expires
+	}
+	return 0
+}
+
+func (x *LogHead) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *LogHead) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+type ProveLogInclusionRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Path    string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Size of the tree to prove inclusion in, e.g. that of a head the client
+	// checked; 0 means the current size. Records past it are left out.
+	TreeSize      int64 `protobuf:"varint,3,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProveLogInclusionRequest) Reset() {
+	*x = ProveLogInclusionRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProveLogInclusionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProveLogInclusionRequest) ProtoMessage() {}
+
+func (x *ProveLogInclusionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProveLogInclusionRequest.ProtoReflect.Descriptor instead.
+func (*ProveLogInclusionRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *ProveLogInclusionRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ProveLogInclusionRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ProveLogInclusionRequest) GetTreeSize() int64 {
+	if x != nil {
+		return x.TreeSize
+	}
+	return 0
+}
+
+type ProveLogInclusionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The records of path@version, oldest first. More than one means the
+	// server served different content for the same version.
+	Records       []*LogRecord `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProveLogInclusionResponse) Reset() {
+	*x = ProveLogInclusionResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProveLogInclusionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProveLogInclusionResponse) ProtoMessage() {}
+
+func (x *ProveLogInclusionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProveLogInclusionResponse.ProtoReflect.Descriptor instead.
+func (*ProveLogInclusionResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *ProveLogInclusionResponse) GetRecords() []*LogRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type LogRecord struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Position of the record in the log.
+	Index int64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// The holon.sum hash of the snapshot, with the default algorithm.
+	Hash string `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	// The hashes proving the record is in the tree, leaf to root.
+	Proof         [][]byte `protobuf:"bytes,3,rep,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogRecord) Reset() {
+	*x = LogRecord{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogRecord) ProtoMessage() {}
+
+func (x *LogRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogRecord.ProtoReflect.Descriptor instead.
+func (*LogRecord) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *LogRecord) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *LogRecord) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *LogRecord) GetProof() [][]byte {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+type ProveLogConsistencyRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	OldSize int64                  `protobuf:"varint,1,opt,name=old_size,json=oldSize,proto3" json:"old_size,omitempty"`
+	// 0 means the current size.
+	NewSize       int64 `protobuf:"varint,2,opt,name=new_size,json=newSize,proto3" json:"new_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProveLogConsistencyRequest) Reset() {
+	*x = ProveLogConsistencyRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProveLogConsistencyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProveLogConsistencyRequest) ProtoMessage() {}
+
+func (x *ProveLogConsistencyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProveLogConsistencyRequest.ProtoReflect.Descriptor instead.
+func (*ProveLogConsistencyRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *ProveLogConsistencyRequest) GetOldSize() int64 {
+	if x != nil {
+		return x.OldSize
+	}
+	return 0
+}
+
+func (x *ProveLogConsistencyRequest) GetNewSize() int64 {
+	if x != nil {
+		return x.NewSize
+	}
+	return 0
+}
+
+type ProveLogConsistencyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The head of new_size.
+	Head          *LogHead `protobuf:"bytes,1,opt,name=head,proto3" json:"head,omitempty"`
+	Proof         [][]byte `protobuf:"bytes,2,rep,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProveLogConsistencyResponse) Reset() {
+	*x = ProveLogConsistencyResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProveLogConsistencyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProveLogConsistencyResponse) ProtoMessage() {}
+
+func (x *ProveLogConsistencyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProveLogConsistencyResponse.ProtoReflect.Descriptor instead.
+func (*ProveLogConsistencyResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *ProveLogConsistencyResponse) GetHead() *LogHead {
+	if x != nil {
+		return x.Head
+	}
+	return nil
+}
+
+func (x *ProveLogConsistencyResponse) GetProof() [][]byte {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+type DescribeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Dependency path or alias; empty describes the holon in directory.
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// Dependency version; defaults to the version required in holon.mod.
+	// Any cached version may be described.
+	Version       string `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeRequest) Reset() {
+	*x = DescribeRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeRequest) ProtoMessage() {}
+
+func (x *DescribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeRequest.ProtoReflect.Descriptor instead.
+func (*DescribeRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *DescribeRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *DescribeRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DescribeRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type DescribeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Holon         *HolonDescription      `protobuf:"bytes,1,opt,name=holon,proto3" json:"holon,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeResponse) Reset() {
+	*x = DescribeResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeResponse) ProtoMessage() {}
+
+func (x *DescribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeResponse.ProtoReflect.Descriptor instead.
+func (*DescribeResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *DescribeResponse) GetHolon() *HolonDescription {
+	if x != nil {
+		return x.Holon
+	}
+	return nil
+}
+
+type HolonDescription struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Empty for the holon in the request directory.
+	Version      string   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Name         string   `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Summary      string   `protobuf:"bytes,4,opt,name=summary,proto3" json:"summary,omitempty"`
+	Capabilities []string `protobuf:"bytes,5,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	Maintainers  []string `protobuf:"bytes,6,rep,name=maintainers,proto3" json:"maintainers,omitempty"`
+	// Provenance attested by the dependency's release, when it has one.
+	Provenance *Provenance `protobuf:"bytes,7,opt,name=provenance,proto3" json:"provenance,omitempty"`
+	// How the dependency's cached snapshot was fetched, when recorded.
+	Fetch *FetchInfo `protobuf:"bytes,8,opt,name=fetch,proto3" json:"fetch,omitempty"`
+	// Team or organization answering for the holon, and how to reach it.
+	Owner         string `protobuf:"bytes,9,opt,name=owner,proto3" json:"owner,omitempty"`
+	Contact       string `protobuf:"bytes,10,opt,name=contact,proto3" json:"contact,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HolonDescription) Reset() {
+	*x = HolonDescription{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HolonDescription) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HolonDescription) ProtoMessage() {}
+
+func (x *HolonDescription) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HolonDescription.ProtoReflect.Descriptor instead.
+func (*HolonDescription) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *HolonDescription) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *HolonDescription) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HolonDescription) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HolonDescription) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *HolonDescription) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *HolonDescription) GetMaintainers() []string {
+	if x != nil {
+		return x.Maintainers
+	}
+	return nil
+}
+
+func (x *HolonDescription) GetProvenance() *Provenance {
+	if x != nil {
+		return x.Provenance
+	}
+	return nil
+}
+
+func (x *HolonDescription) GetFetch() *FetchInfo {
+	if x != nil {
+		return x.Fetch
+	}
+	return nil
+}
+
+func (x *HolonDescription) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *HolonDescription) GetContact() string {
+	if x != nil {
+		return x.Contact
+	}
+	return ""
+}
+
+// Provenance of a release, from its SLSA provenance attestation.
+type Provenance struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the builder that made the release.
+	Builder string `protobuf:"bytes,1,opt,name=builder,proto3" json:"builder,omitempty"`
+	// URI of the source repository.
+	Source string `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	// Source commit the release was made from.
+	Commit string `protobuf:"bytes,3,opt,name=commit,proto3" json:"commit,omitempty"`
+	// Workflow that ran the release; may be empty.
+	Workflow      string `protobuf:"bytes,4,opt,name=workflow,proto3" json:"workflow,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Provenance) Reset() {
+	*x = Provenance{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Provenance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Provenance) ProtoMessage() {}
+
+func (x *Provenance) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Provenance.ProtoReflect.Descriptor instead.
+func (*Provenance) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *Provenance) GetBuilder() string {
+	if x != nil {
+		return x.Builder
+	}
+	return ""
+}
+
+func (x *Provenance) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Provenance) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *Provenance) GetWorkflow() string {
+	if x != nil {
+		return x.Workflow
+	}
+	return ""
+}
+
+type FindCapabilityRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Capability to look for (e.g. "storage.kv").
+	Capability    string `protobuf:"bytes,2,opt,name=capability,proto3" json:"capability,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindCapabilityRequest) Reset() {
+	*x = FindCapabilityRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindCapabilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindCapabilityRequest) ProtoMessage() {}
+
+func (x *FindCapabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindCapabilityRequest.ProtoReflect.Descriptor instead.
+func (*FindCapabilityRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *FindCapabilityRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *FindCapabilityRequest) GetCapability() string {
+	if x != nil {
+		return x.Capability
+	}
+	return ""
+}
+
+type FindCapabilityResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Providers in graph order; cache_path is where HOLON.md was read.
+	Providers     []*Dependency `protobuf:"bytes,1,rep,name=providers,proto3" json:"providers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindCapabilityResponse) Reset() {
+	*x = FindCapabilityResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindCapabilityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindCapabilityResponse) ProtoMessage() {}
+
+func (x *FindCapabilityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindCapabilityResponse.ProtoReflect.Descriptor instead.
+func (*FindCapabilityResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *FindCapabilityResponse) GetProviders() []*Dependency {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+type OwnersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Dependency path or alias; empty lists every holon of the graph.
+	Path          string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OwnersRequest) Reset() {
+	*x = OwnersRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OwnersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OwnersRequest) ProtoMessage() {}
+
+func (x *OwnersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OwnersRequest.ProtoReflect.Descriptor instead.
+func (*OwnersRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *OwnersRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *OwnersRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type OwnersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// In graph order.
+	Holons        []*HolonOwners `protobuf:"bytes,1,rep,name=holons,proto3" json:"holons,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OwnersResponse) Reset() {
+	*x = OwnersResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OwnersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OwnersResponse) ProtoMessage() {}
+
+func (x *OwnersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OwnersResponse.ProtoReflect.Descriptor instead.
+func (*OwnersResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *OwnersResponse) GetHolons() []*HolonOwners {
+	if x != nil {
+		return x.Holons
+	}
+	return nil
+}
+
+type HolonOwners struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Path    string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// From the HOLON.md front-matter: "owner", "maintainers" (or the
+	// identity "composer") and "contact". All empty when the HOLON.md is
+	// unavailable or declares none.
+	Owner       string   `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	Maintainers []string `protobuf:"bytes,4,rep,name=maintainers,proto3" json:"maintainers,omitempty"`
+	Contact     string   `protobuf:"bytes,5,opt,name=contact,proto3" json:"contact,omitempty"`
+	// The "owner" annotation of the require in holon.mod, e.g.
+	// "// owner=platform": who answers for the dependency in this project.
+	// Direct dependencies only.
+	LocalOwner    string `protobuf:"bytes,6,opt,name=local_owner,json=localOwner,proto3" json:"local_owner,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HolonOwners) Reset() {
+	*x = HolonOwners{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HolonOwners) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HolonOwners) ProtoMessage() {}
+
+func (x *HolonOwners) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HolonOwners.ProtoReflect.Descriptor instead.
+func (*HolonOwners) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *HolonOwners) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *HolonOwners) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HolonOwners) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *HolonOwners) GetMaintainers() []string {
+	if x != nil {
+		return x.Maintainers
+	}
+	return nil
+}
+
+func (x *HolonOwners) GetContact() string {
+	if x != nil {
+		return x.Contact
+	}
+	return ""
+}
+
+func (x *HolonOwners) GetLocalOwner() string {
+	if x != nil {
+		return x.LocalOwner
+	}
+	return ""
+}
+
+type ReleaseRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory of the holon; must be a clean git work tree.
+	Directory string      `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	Bump      ReleaseBump `protobuf:"varint,2,opt,name=bump,proto3,enum=rhizome_atlas.v1.ReleaseBump" json:"bump,omitempty"`
+	// Push the new tag to the "origin" remote.
+	Push bool `protobuf:"varint,3,opt,name=push,proto3" json:"push,omitempty"`
+	// Attach a provenance attestation to the tag, naming this builder and
+	// workflow.
+	Builder  string `protobuf:"bytes,4,opt,name=builder,proto3" json:"builder,omitempty"`
+	Workflow string `protobuf:"bytes,5,opt,name=workflow,proto3" json:"workflow,omitempty"`
+	// Attach this in-toto statement (SLSA provenance v1, as JSON) instead,
+	// e.g. one produced by CI. It must attest to the released commit.
+	Provenance []byte `protobuf:"bytes,6,opt,name=provenance,proto3" json:"provenance,omitempty"`
+	// Push the new tag to <registry>/<holon-path>, the registry being
+	// ATLAS_REGISTRY or else the registry directive of holon.mod. A file://
+	// registry gets the repository created if missing.
+	Publish       bool `protobuf:"varint,7,opt,name=publish,proto3" json:"publish,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseRequest) Reset() {
+	*x = ReleaseRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseRequest) ProtoMessage() {}
+
+func (x *ReleaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *ReleaseRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *ReleaseRequest) GetBump() ReleaseBump {
+	if x != nil {
+		return x.Bump
+	}
+	return ReleaseBump_RELEASE_BUMP_PATCH
+}
+
+func (x *ReleaseRequest) GetPush() bool {
+	if x != nil {
+		return x.Push
+	}
+	return false
+}
+
+func (x *ReleaseRequest) GetBuilder() string {
+	if x != nil {
+		return x.Builder
+	}
+	return ""
+}
+
+func (x *ReleaseRequest) GetWorkflow() string {
+	if x != nil {
+		return x.Workflow
+	}
+	return ""
+}
+
+func (x *ReleaseRequest) GetProvenance() []byte {
+	if x != nil {
+		return x.Provenance
+	}
+	return nil
+}
+
+func (x *ReleaseRequest) GetPublish() bool {
+	if x != nil {
+		return x.Publish
+	}
+	return false
+}
+
+type ReleaseResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Highest existing semver tag; empty for a first release.
+	PreviousVersion string `protobuf:"bytes,1,opt,name=previous_version,json=previousVersion,proto3" json:"previous_version,omitempty"`
+	// The annotated tag that was created.
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Pushed  bool   `protobuf:"varint,3,opt,name=pushed,proto3" json:"pushed,omitempty"`
+	// The tag carries a provenance attestation.
+	Attested bool `protobuf:"varint,4,opt,name=attested,proto3" json:"attested,omitempty"`
+	// Registry repository the tag was published to.
+	Published     string `protobuf:"bytes,5,opt,name=published,proto3" json:"published,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseResponse) Reset() {
+	*x = ReleaseResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseResponse) ProtoMessage() {}
+
+func (x *ReleaseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *ReleaseResponse) GetPreviousVersion() string {
+	if x != nil {
+		return x.PreviousVersion
+	}
+	return ""
+}
+
+func (x *ReleaseResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ReleaseResponse) GetPushed() bool {
+	if x != nil {
+		return x.Pushed
+	}
+	return false
+}
+
+func (x *ReleaseResponse) GetAttested() bool {
+	if x != nil {
+		return x.Attested
+	}
+	return false
+}
+
+func (x *ReleaseResponse) GetPublished() string {
+	if x != nil {
+		return x.Published
+	}
+	return ""
+}
+
+type BundleCreateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Path of the bundle file to write.
+	Output string `protobuf:"bytes,2,opt,name=output,proto3" json:"output,omitempty"`
+	// Bundle only the dependencies of this require group and theirs, e.g.
+	// "runtime" to leave out dev and test holons. Empty for all groups.
+	Group         string `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BundleCreateRequest) Reset() {
+	*x = BundleCreateRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BundleCreateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BundleCreateRequest) ProtoMessage() {}
+
+func (x *BundleCreateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BundleCreateRequest.ProtoReflect.Descriptor instead.
+func (*BundleCreateRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *BundleCreateRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *BundleCreateRequest) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *BundleCreateRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+type BundleCreateResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Output string                 `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	// Every dependency of the closure packaged in the bundle.
+	Dependencies  []*Dependency `protobuf:"bytes,2,rep,name=dependencies,proto3" json:"dependencies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BundleCreateResponse) Reset() {
+	*x = BundleCreateResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BundleCreateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BundleCreateResponse) ProtoMessage() {}
+
+func (x *BundleCreateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BundleCreateResponse.ProtoReflect.Descriptor instead.
+func (*BundleCreateResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *BundleCreateResponse) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *BundleCreateResponse) GetDependencies() []*Dependency {
+	if x != nil {
+		return x.Dependencies
+	}
+	return nil
+}
+
+type BundleInstallRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path of the bundle file to read.
+	Input string `protobuf:"bytes,1,opt,name=input,proto3" json:"input,omitempty"`
+	// Optional directory receiving the bundled holon.mod and holon.sum
+	// when it has no holon.mod yet.
+	Directory     string `protobuf:"bytes,2,opt,name=directory,proto3" json:"directory,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BundleInstallRequest) Reset() {
+	*x = BundleInstallRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BundleInstallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BundleInstallRequest) ProtoMessage() {}
+
+func (x *BundleInstallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BundleInstallRequest.ProtoReflect.Descriptor instead.
+func (*BundleInstallRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{79}
+}
+
+func (x *BundleInstallRequest) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+func (x *BundleInstallRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+type BundleInstallResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Every dependency of the bundle, now verified in the cache.
+	Installed []*Dependency `protobuf:"bytes,1,rep,name=installed,proto3" json:"installed,omitempty"`
+	// Path of the holon.mod written, if any.
+	ModFile       string `protobuf:"bytes,2,opt,name=mod_file,json=modFile,proto3" json:"mod_file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BundleInstallResponse) Reset() {
+	*x = BundleInstallResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BundleInstallResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BundleInstallResponse) ProtoMessage() {}
+
+func (x *BundleInstallResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BundleInstallResponse.ProtoReflect.Descriptor instead.
+func (*BundleInstallResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *BundleInstallResponse) GetInstalled() []*Dependency {
+	if x != nil {
+		return x.Installed
+	}
+	return nil
+}
+
+func (x *BundleInstallResponse) GetModFile() string {
+	if x != nil {
+		return x.ModFile
+	}
+	return ""
+}
+
+type SumPruneRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod and holon.sum.
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Report the entries that would be removed without writing holon.sum.
+	DryRun        bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SumPruneRequest) Reset() {
+	*x = SumPruneRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SumPruneRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SumPruneRequest) ProtoMessage() {}
+
+func (x *SumPruneRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SumPruneRequest.ProtoReflect.Descriptor instead.
+func (*SumPruneRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{81}
+}
+
+func (x *SumPruneRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *SumPruneRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type SumPruneResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Removed       []*SumEntry            `protobuf:"bytes,1,rep,name=removed,proto3" json:"removed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SumPruneResponse) Reset() {
+	*x = SumPruneResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SumPruneResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SumPruneResponse) ProtoMessage() {}
+
+func (x *SumPruneResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SumPruneResponse.ProtoReflect.Descriptor instead.
+func (*SumPruneResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *SumPruneResponse) GetRemoved() []*SumEntry {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+type SumMigrateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.sum.
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Algorithm to migrate to, e.g. "h2" (a trailing colon is accepted).
+	To string `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// Also drop the entries of other algorithms once recorded with to: only
+	// when every tool reading this holon.sum knows to.
+	DropOld bool `protobuf:"varint,3,opt,name=drop_old,json=dropOld,proto3" json:"drop_old,omitempty"`
+	// Report the changes without writing holon.sum.
+	DryRun        bool `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SumMigrateRequest) Reset() {
+	*x = SumMigrateRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SumMigrateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SumMigrateRequest) ProtoMessage() {}
+
+func (x *SumMigrateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SumMigrateRequest.ProtoReflect.Descriptor instead.
+func (*SumMigrateRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *SumMigrateRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *SumMigrateRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *SumMigrateRequest) GetDropOld() bool {
+	if x != nil {
+		return x.DropOld
+	}
+	return false
+}
+
+func (x *SumMigrateRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type SumMigrateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Added         []*SumEntry            `protobuf:"bytes,1,rep,name=added,proto3" json:"added,omitempty"`
+	Removed       []*SumEntry            `protobuf:"bytes,2,rep,name=removed,proto3" json:"removed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SumMigrateResponse) Reset() {
+	*x = SumMigrateResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SumMigrateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SumMigrateResponse) ProtoMessage() {}
+
+func (x *SumMigrateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SumMigrateResponse.ProtoReflect.Descriptor instead.
+func (*SumMigrateResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{84}
+}
+
+func (x *SumMigrateResponse) GetAdded() []*SumEntry {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *SumMigrateResponse) GetRemoved() []*SumEntry {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+type SumMergeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path of our holon.sum.
+	Ours string `protobuf:"bytes,1,opt,name=ours,proto3" json:"ours,omitempty"`
+	// Path of their holon.sum.
+	Theirs string `protobuf:"bytes,2,opt,name=theirs,proto3" json:"theirs,omitempty"`
+	// Path of the merged holon.sum; defaults to ours.
+	Output        string `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SumMergeRequest) Reset() {
+	*x = SumMergeRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SumMergeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SumMergeRequest) ProtoMessage() {}
+
+func (x *SumMergeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[85]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SumMergeRequest.ProtoReflect.Descriptor instead.
+func (*SumMergeRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{85}
+}
+
+func (x *SumMergeRequest) GetOurs() string {
+	if x != nil {
+		return x.Ours
+	}
+	return ""
+}
+
+func (x *SumMergeRequest) GetTheirs() string {
+	if x != nil {
+		return x.Theirs
+	}
+	return ""
+}
+
+func (x *SumMergeRequest) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+type SumMergeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path written; empty when there are conflicts.
+	Output string `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	// Number of entries in the merged file.
+	Entries int32 `protobuf:"varint,2,opt,name=entries,proto3" json:"entries,omitempty"`
+	// Entries recorded with different hashes on each side.
+	Conflicts     []*SumConflict `protobuf:"bytes,3,rep,name=conflicts,proto3" json:"conflicts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SumMergeResponse) Reset() {
+	*x = SumMergeResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SumMergeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SumMergeResponse) ProtoMessage() {}
+
+func (x *SumMergeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[86]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SumMergeResponse.ProtoReflect.Descriptor instead.
+func (*SumMergeResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{86}
+}
+
+func (x *SumMergeResponse) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *SumMergeResponse) GetEntries() int32 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *SumMergeResponse) GetConflicts() []*SumConflict {
+	if x != nil {
+		return x.Conflicts
+	}
+	return nil
+}
+
+type SumConflict struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Ours          string                 `protobuf:"bytes,3,opt,name=ours,proto3" json:"ours,omitempty"`
+	Theirs        string                 `protobuf:"bytes,4,opt,name=theirs,proto3" json:"theirs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SumConflict) Reset() {
+	*x = SumConflict{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SumConflict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SumConflict) ProtoMessage() {}
+
+func (x *SumConflict) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[87]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SumConflict.ProtoReflect.Descriptor instead.
+func (*SumConflict) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{87}
+}
+
+func (x *SumConflict) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SumConflict) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *SumConflict) GetOurs() string {
+	if x != nil {
+		return x.Ours
+	}
+	return ""
+}
+
+func (x *SumConflict) GetTheirs() string {
+	if x != nil {
+		return x.Theirs
+	}
+	return ""
+}
+
+type ModMergeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path of our holon.mod.
+	Ours string `protobuf:"bytes,1,opt,name=ours,proto3" json:"ours,omitempty"`
+	// Path of their holon.mod.
+	Theirs string `protobuf:"bytes,2,opt,name=theirs,proto3" json:"theirs,omitempty"`
+	// Optional path of the common ancestor, used to tell removals from
+	// additions.
+	Base string `protobuf:"bytes,3,opt,name=base,proto3" json:"base,omitempty"`
+	// Path of the merged holon.mod; defaults to ours.
+	Output        string `protobuf:"bytes,4,opt,name=output,proto3" json:"output,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModMergeRequest) Reset() {
+	*x = ModMergeRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[88]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModMergeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModMergeRequest) ProtoMessage() {}
+
+func (x *ModMergeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[88]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModMergeRequest.ProtoReflect.Descriptor instead.
+func (*ModMergeRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{88}
+}
+
+func (x *ModMergeRequest) GetOurs() string {
+	if x != nil {
+		return x.Ours
+	}
+	return ""
+}
+
+func (x *ModMergeRequest) GetTheirs() string {
+	if x != nil {
+		return x.Theirs
+	}
+	return ""
+}
+
+func (x *ModMergeRequest) GetBase() string {
+	if x != nil {
+		return x.Base
+	}
+	return ""
+}
+
+func (x *ModMergeRequest) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+type ModMergeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path written; empty on conflict.
+	Output string `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	// Why the files could not be merged, if they could not.
+	Conflict      string `protobuf:"bytes,2,opt,name=conflict,proto3" json:"conflict,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModMergeResponse) Reset() {
+	*x = ModMergeResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[89]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModMergeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModMergeResponse) ProtoMessage() {}
+
+func (x *ModMergeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[89]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModMergeResponse.ProtoReflect.Descriptor instead.
+func (*ModMergeResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{89}
+}
+
+func (x *ModMergeResponse) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *ModMergeResponse) GetConflict() string {
+	if x != nil {
+		return x.Conflict
+	}
+	return ""
+}
+
+type UndoRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Fetch again the required dependencies missing from the cache, such as
+	// versions evicted by Update.
+	RestoreCache  bool `protobuf:"varint,2,opt,name=restore_cache,json=restoreCache,proto3" json:"restore_cache,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndoRequest) Reset() {
+	*x = UndoRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[90]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndoRequest) ProtoMessage() {}
+
+func (x *UndoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[90]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndoRequest.ProtoReflect.Descriptor instead.
+func (*UndoRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{90}
+}
+
+func (x *UndoRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *UndoRequest) GetRestoreCache() bool {
+	if x != nil {
+		return x.RestoreCache
+	}
+	return false
+}
+
+type UndoResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The operation that was undone (e.g. "Update").
+	Method string `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	// When it ran, in RFC 3339 format.
+	Time string `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	// Dependencies fetched back into the cache.
+	Restored      []*Dependency `protobuf:"bytes,3,rep,name=restored,proto3" json:"restored,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndoResponse) Reset() {
+	*x = UndoResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[91]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndoResponse) ProtoMessage() {}
+
+func (x *UndoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[91]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndoResponse.ProtoReflect.Descriptor instead.
+func (*UndoResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{91}
+}
+
+func (x *UndoResponse) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *UndoResponse) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *UndoResponse) GetRestored() []*Dependency {
+	if x != nil {
+		return x.Restored
+	}
+	return nil
+}
+
+type HistoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Maximum number of entries to return; 0 returns them all.
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryRequest) Reset() {
+	*x = HistoryRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[92]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryRequest) ProtoMessage() {}
+
+func (x *HistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[92]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryRequest.ProtoReflect.Descriptor instead.
+func (*HistoryRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{92}
+}
+
+func (x *HistoryRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *HistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type HistoryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Most recent first.
+	Entries       []*HistoryEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryResponse) Reset() {
+	*x = HistoryResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[93]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryResponse) ProtoMessage() {}
+
+func (x *HistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[93]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryResponse.ProtoReflect.Descriptor instead.
+func (*HistoryResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{93}
+}
+
+func (x *HistoryResponse) GetEntries() []*HistoryEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type HistoryEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// When the operation ran, in RFC 3339 format.
+	Time string `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// The RPC that made the change (e.g. "Update", "Undo Add").
+	Method string `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	// One line per change, e.g. "github.com/org/dep v1.0.0 → v1.1.0".
+	Changes       []string `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryEntry) Reset() {
+	*x = HistoryEntry{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[94]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryEntry) ProtoMessage() {}
+
+func (x *HistoryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[94]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryEntry.ProtoReflect.Descriptor instead.
+func (*HistoryEntry) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{94}
+}
+
+func (x *HistoryEntry) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *HistoryEntry) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *HistoryEntry) GetChanges() []string {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type Dependency struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Path    string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Where this dependency was resolved to.
+	CachePath string `protobuf:"bytes,3,opt,name=cache_path,json=cachePath,proto3" json:"cache_path,omitempty"`
+	// Short name declared in holon.mod, if any.
+	Alias string `protobuf:"bytes,4,opt,name=alias,proto3" json:"alias,omitempty"`
+	// Git URL that served the cached content: the upstream repository or
+	// an ATLAS_PROXY mirror. Empty when unknown.
+	Source string `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	// Require group declaring the dependency in holon.mod, e.g. "runtime"
+	// or "dev". Empty for dependencies it does not declare.
+	Group         string `protobuf:"bytes,6,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Dependency) Reset() {
+	*x = Dependency{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[95]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Dependency) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dependency) ProtoMessage() {}
+
+func (x *Dependency) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[95]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dependency.ProtoReflect.Descriptor instead.
+func (*Dependency) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{95}
+}
+
+func (x *Dependency) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Dependency) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Dependency) GetCachePath() string {
+	if x != nil {
+		return x.CachePath
+	}
+	return ""
+}
+
+func (x *Dependency) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *Dependency) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Dependency) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+type SumEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Version, or "<version>/HOLON.md" for the hash of the HOLON.md alone.
+	Version       string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Hash          string `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SumEntry) Reset() {
+	*x = SumEntry{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[96]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SumEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SumEntry) ProtoMessage() {}
+
+func (x *SumEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[96]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SumEntry.ProtoReflect.Descriptor instead.
+func (*SumEntry) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{96}
+}
+
+func (x *SumEntry) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SumEntry) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *SumEntry) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+// Plan is what a mutating RPC would do, as reported by a dry run.
+type Plan struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Changes to holon.mod, in the format of HistoryEntry.changes.
+	Changes []string `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	// Files and directories that would be written.
+	Write []string `protobuf:"bytes,2,rep,name=write,proto3" json:"write,omitempty"`
+	// Files and directories that would be deleted.
+	Delete []string `protobuf:"bytes,3,rep,name=delete,proto3" json:"delete,omitempty"`
+	// Dependencies that would be fetched to the cache.
+	Fetch []*Dependency `protobuf:"bytes,4,rep,name=fetch,proto3" json:"fetch,omitempty"`
+	// Bytes that would be copied or freed on disk. The size of fetched
+	// dependencies is only known once fetched and is not included.
+	Bytes         int64 `protobuf:"varint,5,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Plan) Reset() {
+	*x = Plan{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[97]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Plan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Plan) ProtoMessage() {}
+
+func (x *Plan) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[97]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Plan.ProtoReflect.Descriptor instead.
+func (*Plan) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{97}
+}
+
+func (x *Plan) GetChanges() []string {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *Plan) GetWrite() []string {
+	if x != nil {
+		return x.Write
+	}
+	return nil
+}
+
+func (x *Plan) GetDelete() []string {
+	if x != nil {
+		return x.Delete
+	}
+	return nil
+}
+
+func (x *Plan) GetFetch() []*Dependency {
+	if x != nil {
+		return x.Fetch
+	}
+	return nil
+}
+
+func (x *Plan) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+type PrefetchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Dependencies to fetch, as "<path>@<version>".
+	Dependencies []string `protobuf:"bytes,1,rep,name=dependencies,proto3" json:"dependencies,omitempty"`
+	// Content of a holon.mod whose requires are fetched as well, except the
+	// replaced ones.
+	HolonMod []byte `protobuf:"bytes,2,opt,name=holon_mod,json=holonMod,proto3" json:"holon_mod,omitempty"`
+	// Wait for the fetches to complete instead of returning once started.
+	Wait          bool `protobuf:"varint,3,opt,name=wait,proto3" json:"wait,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrefetchRequest) Reset() {
+	*x = PrefetchRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[98]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrefetchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrefetchRequest) ProtoMessage() {}
+
+func (x *PrefetchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[98]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrefetchRequest.ProtoReflect.Descriptor instead.
+func (*PrefetchRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{98}
+}
+
+func (x *PrefetchRequest) GetDependencies() []string {
+	if x != nil {
+		return x.Dependencies
+	}
+	return nil
+}
+
+func (x *PrefetchRequest) GetHolonMod() []byte {
+	if x != nil {
+		return x.HolonMod
+	}
+	return nil
+}
+
+func (x *PrefetchRequest) GetWait() bool {
+	if x != nil {
+		return x.Wait
+	}
+	return false
+}
+
+type PrefetchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Dependencies missing from the cache, fetched in the background. With
+	// wait, cache_path is set on those fetched.
+	Queued []*Dependency `protobuf:"bytes,1,rep,name=queued,proto3" json:"queued,omitempty"`
+	// With wait, the fetches that failed, as "<path>@<version>: <error>".
+	Errors        []string `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrefetchResponse) Reset() {
+	*x = PrefetchResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[99]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrefetchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrefetchResponse) ProtoMessage() {}
+
+func (x *PrefetchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[99]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrefetchResponse.ProtoReflect.Descriptor instead.
+func (*PrefetchResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{99}
+}
+
+func (x *PrefetchResponse) GetQueued() []*Dependency {
+	if x != nil {
+		return x.Queued
+	}
+	return nil
+}
+
+func (x *PrefetchResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+// Operation is a Pull or Update running in the background. Finished
+// operations are kept for a while, so that a client reconnecting after it
+// lost its connection can still get the result.
+type Operation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Identifies the operation in GetOperation and WatchOperation.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// "Pull" or "Update".
+	Method    string `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Directory string `protobuf:"bytes,3,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Set once the operation finished, successfully or not.
+	Done bool `protobuf:"varint,4,opt,name=done,proto3" json:"done,omitempty"`
+	// On failure, the gRPC status code and message of the call.
+	ErrorCode    int32  `protobuf:"varint,5,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	ErrorMessage string `protobuf:"bytes,6,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	// On success, the response of the call.
+	Pull          *PullResponse   `protobuf:"bytes,7,opt,name=pull,proto3" json:"pull,omitempty"`
+	Update        *UpdateResponse `protobuf:"bytes,8,opt,name=update,proto3" json:"update,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Operation) Reset() {
+	*x = Operation{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[100]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Operation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operation) ProtoMessage() {}
+
+func (x *Operation) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[100]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operation.ProtoReflect.Descriptor instead.
+func (*Operation) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{100}
+}
+
+func (x *Operation) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Operation) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *Operation) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *Operation) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *Operation) GetErrorCode() int32 {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return 0
+}
+
+func (x *Operation) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *Operation) GetPull() *PullResponse {
+	if x != nil {
+		return x.Pull
+	}
+	return nil
+}
+
+func (x *Operation) GetUpdate() *UpdateResponse {
+	if x != nil {
+		return x.Update
+	}
+	return nil
+}
+
+type GetOperationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOperationRequest) Reset() {
+	*x = GetOperationRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[101]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOperationRequest) ProtoMessage() {}
+
+func (x *GetOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[101]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOperationRequest.ProtoReflect.Descriptor instead.
+func (*GetOperationRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{101}
+}
+
+func (x *GetOperationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CancelOperationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOperationRequest) Reset() {
+	*x = CancelOperationRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[102]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOperationRequest) ProtoMessage() {}
+
+func (x *CancelOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[102]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOperationRequest.ProtoReflect.Descriptor instead.
+func (*CancelOperationRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{102}
+}
+
+func (x *CancelOperationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type MirrorSyncRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Where to copy from: a proxy URL prefix or a directory, serving
+	// <from>/<dep-path> as git repositories.
+	From string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	// Globs of the dependency paths to mirror, as in path.Match: "*" does
+	// not match "/". Holons are found in from when it is a directory, and
+	// in the mirror; elsewhere, paths without glob characters are mirrored
+	// as given.
+	Paths []string `protobuf:"bytes,2,rep,name=paths,proto3" json:"paths,omitempty"`
+	// The mirror directory, holding <to>/<dep-path> as bare git
+	// repositories.
+	To string `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	// Number of versions to mirror per holon, the latest stable ones; 0
+	// means 3. Versions mirrored before are kept.
+	Latest        int32 `protobuf:"varint,4,opt,name=latest,proto3" json:"latest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MirrorSyncRequest) Reset() {
+	*x = MirrorSyncRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[103]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MirrorSyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MirrorSyncRequest) ProtoMessage() {}
+
+func (x *MirrorSyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[103]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MirrorSyncRequest.ProtoReflect.Descriptor instead.
+func (*MirrorSyncRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{103}
+}
+
+func (x *MirrorSyncRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *MirrorSyncRequest) GetPaths() []string {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+func (x *MirrorSyncRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *MirrorSyncRequest) GetLatest() int32 {
+	if x != nil {
+		return x.Latest
+	}
+	return 0
+}
+
+type MirrorSyncResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Holons []*MirroredHolon       `protobuf:"bytes,1,rep,name=holons,proto3" json:"holons,omitempty"`
+	// The holons that could not be synced, as "<path>: <error>".
+	Errors        []string `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MirrorSyncResponse) Reset() {
+	*x = MirrorSyncResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[104]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MirrorSyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MirrorSyncResponse) ProtoMessage() {}
+
+func (x *MirrorSyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[104]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MirrorSyncResponse.ProtoReflect.Descriptor instead.
+func (*MirrorSyncResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{104}
+}
+
+func (x *MirrorSyncResponse) GetHolons() []*MirroredHolon {
+	if x != nil {
+		return x.Holons
+	}
+	return nil
+}
+
+func (x *MirrorSyncResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type MirroredHolon struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The latest versions, newest first.
+	Versions []string `protobuf:"bytes,2,rep,name=versions,proto3" json:"versions,omitempty"`
+	// Those of versions copied by this sync.
+	Added         []string `protobuf:"bytes,3,rep,name=added,proto3" json:"added,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MirroredHolon) Reset() {
+	*x = MirroredHolon{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[105]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MirroredHolon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MirroredHolon) ProtoMessage() {}
+
+func (x *MirroredHolon) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[105]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MirroredHolon.ProtoReflect.Descriptor instead.
+func (*MirroredHolon) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{105}
+}
+
+func (x *MirroredHolon) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *MirroredHolon) GetVersions() []string {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+func (x *MirroredHolon) GetAdded() []string {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+type ReproduceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod and holon.sum.
+	Directory     string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReproduceRequest) Reset() {
+	*x = ReproduceRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[106]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReproduceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReproduceRequest) ProtoMessage() {}
+
+func (x *ReproduceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[106]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReproduceRequest.ProtoReflect.Descriptor instead.
+func (*ReproduceRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{106}
+}
+
+func (x *ReproduceRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+type ReproduceResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// True if every dependency reproduced.
+	Reproducible bool `protobuf:"varint,1,opt,name=reproducible,proto3" json:"reproducible,omitempty"`
+	// When the check ran, in RFC 3339 format.
+	Time string `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	// One per required dependency, in holon.mod order. Replaced dependencies
+	// are skipped.
+	Results       []*Reproduction `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReproduceResponse) Reset() {
+	*x = ReproduceResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[107]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReproduceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReproduceResponse) ProtoMessage() {}
+
+func (x *ReproduceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[107]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReproduceResponse.ProtoReflect.Descriptor instead.
+func (*ReproduceResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{107}
+}
+
+func (x *ReproduceResponse) GetReproducible() bool {
+	if x != nil {
+		return x.Reproducible
+	}
+	return false
+}
+
+func (x *ReproduceResponse) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *ReproduceResponse) GetResults() []*Reproduction {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type Reproduction struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Path    string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// The upstream source that served the fresh copy; empty if none could.
+	Source string `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	// Hashes of the content, with the algorithm holon.sum records it with
+	// (its default one for a dependency holon.sum lacks). Empty when
+	// holon.sum has no entry, the cache no snapshot, or the fetch failed.
+	SumHash      string `protobuf:"bytes,4,opt,name=sum_hash,json=sumHash,proto3" json:"sum_hash,omitempty"`
+	CacheHash    string `protobuf:"bytes,5,opt,name=cache_hash,json=cacheHash,proto3" json:"cache_hash,omitempty"`
+	UpstreamHash string `protobuf:"bytes,6,opt,name=upstream_hash,json=upstreamHash,proto3" json:"upstream_hash,omitempty"`
+	// True if the upstream content hashes to what holon.sum records, and to
+	// the cached snapshot when there is one.
+	Reproduced bool `protobuf:"varint,7,opt,name=reproduced,proto3" json:"reproduced,omitempty"`
+	// Why not, when not reproduced.
+	Problems      []string `protobuf:"bytes,8,rep,name=problems,proto3" json:"problems,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reproduction) Reset() {
+	*x = Reproduction{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[108]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reproduction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reproduction) ProtoMessage() {}
+
+func (x *Reproduction) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[108]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reproduction.ProtoReflect.Descriptor instead.
+func (*Reproduction) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{108}
+}
+
+func (x *Reproduction) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Reproduction) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Reproduction) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Reproduction) GetSumHash() string {
+	if x != nil {
+		return x.SumHash
+	}
+	return ""
+}
+
+func (x *Reproduction) GetCacheHash() string {
+	if x != nil {
+		return x.CacheHash
+	}
+	return ""
+}
+
+func (x *Reproduction) GetUpstreamHash() string {
+	if x != nil {
+		return x.UpstreamHash
+	}
+	return ""
+}
+
+func (x *Reproduction) GetReproduced() bool {
+	if x != nil {
+		return x.Reproduced
+	}
+	return false
+}
+
+func (x *Reproduction) GetProblems() []string {
+	if x != nil {
+		return x.Problems
+	}
+	return nil
+}
+
+type ImpactRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Dependency path, or alias, to simulate the update of. A path not
+	// required yet simulates its addition.
+	Path          string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Version       string `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImpactRequest) Reset() {
+	*x = ImpactRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[109]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImpactRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImpactRequest) ProtoMessage() {}
+
+func (x *ImpactRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[109]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImpactRequest.ProtoReflect.Descriptor instead.
+func (*ImpactRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{109}
+}
+
+func (x *ImpactRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *ImpactRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ImpactRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type ImpactResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Empty when the dependency is not required yet.
+	OldVersion string `protobuf:"bytes,2,opt,name=old_version,json=oldVersion,proto3" json:"old_version,omitempty"`
+	NewVersion string `protobuf:"bytes,3,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
+	// Requirements of the holons of the build list that the update adds,
+	// removes or changes, transitive ones included.
+	Changes []*RequirementChange `protobuf:"bytes,4,rep,name=changes,proto3" json:"changes,omitempty"`
+	// Dependencies whose selected version changes. Minimal version selection
+	// picks, for each major of a dependency, the highest version any holon
+	// of the build list requires.
+	Selections []*Selection `protobuf:"bytes,5,rep,name=selections,proto3" json:"selections,omitempty"`
+	// Dependencies the updated graph requires at several major versions.
+	Conflicts []*Conflict `protobuf:"bytes,6,rep,name=conflicts,proto3" json:"conflicts,omitempty"`
+	// Versions of either build list whose holon.mod could not be read, as
+	// "<path>@<version>: <error>"; their requirements are left out.
+	Unresolved    []string `protobuf:"bytes,7,rep,name=unresolved,proto3" json:"unresolved,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImpactResponse) Reset() {
+	*x = ImpactResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[110]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImpactResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImpactResponse) ProtoMessage() {}
+
+func (x *ImpactResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[110]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImpactResponse.ProtoReflect.Descriptor instead.
+func (*ImpactResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{110}
+}
+
+func (x *ImpactResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ImpactResponse) GetOldVersion() string {
+	if x != nil {
+		return x.OldVersion
+	}
+	return ""
+}
+
+func (x *ImpactResponse) GetNewVersion() string {
+	if x != nil {
+		return x.NewVersion
+	}
+	return ""
+}
+
+func (x *ImpactResponse) GetChanges() []*RequirementChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *ImpactResponse) GetSelections() []*Selection {
+	if x != nil {
+		return x.Selections
+	}
+	return nil
+}
+
+func (x *ImpactResponse) GetConflicts() []*Conflict {
+	if x != nil {
+		return x.Conflicts
+	}
+	return nil
+}
+
+func (x *ImpactResponse) GetUnresolved() []string {
+	if x != nil {
+		return x.Unresolved
+	}
+	return nil
+}
+
+type RequirementChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The holon whose holon.mod declares the requirement.
+	Holon string `protobuf:"bytes,1,opt,name=holon,proto3" json:"holon,omitempty"`
+	Path  string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// Empty for an added requirement.
+	OldVersion string `protobuf:"bytes,3,opt,name=old_version,json=oldVersion,proto3" json:"old_version,omitempty"`
+	// Empty for a removed requirement.
+	NewVersion    string `protobuf:"bytes,4,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequirementChange) Reset() {
+	*x = RequirementChange{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[111]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequirementChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequirementChange) ProtoMessage() {}
+
+func (x *RequirementChange) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[111]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequirementChange.ProtoReflect.Descriptor instead.
+func (*RequirementChange) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{111}
+}
+
+func (x *RequirementChange) GetHolon() string {
+	if x != nil {
+		return x.Holon
+	}
+	return ""
+}
+
+func (x *RequirementChange) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *RequirementChange) GetOldVersion() string {
+	if x != nil {
+		return x.OldVersion
+	}
+	return ""
+}
+
+func (x *RequirementChange) GetNewVersion() string {
+	if x != nil {
+		return x.NewVersion
+	}
+	return ""
+}
+
+type Selection struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Empty when the dependency enters the graph.
+	OldVersion string `protobuf:"bytes,2,opt,name=old_version,json=oldVersion,proto3" json:"old_version,omitempty"`
+	// Empty when it leaves it.
+	NewVersion string `protobuf:"bytes,3,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
+	// Holons of the graph requiring a lower version, which the selection
+	// overrides.
+	Raised        []string `protobuf:"bytes,4,rep,name=raised,proto3" json:"raised,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Selection) Reset() {
+	*x = Selection{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[112]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Selection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Selection) ProtoMessage() {}
+
+func (x *Selection) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[112]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Selection.ProtoReflect.Descriptor instead.
+func (*Selection) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{112}
+}
+
+func (x *Selection) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Selection) GetOldVersion() string {
+	if x != nil {
+		return x.OldVersion
+	}
+	return ""
+}
+
+func (x *Selection) GetNewVersion() string {
+	if x != nil {
+		return x.NewVersion
+	}
+	return ""
+}
+
+func (x *Selection) GetRaised() []string {
+	if x != nil {
+		return x.Raised
+	}
+	return nil
+}
+
+type Conflict struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The requirements of path, one per requiring holon.
+	RequiredBy    []*Edge `protobuf:"bytes,2,rep,name=required_by,json=requiredBy,proto3" json:"required_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Conflict) Reset() {
+	*x = Conflict{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[113]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Conflict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Conflict) ProtoMessage() {}
+
+func (x *Conflict) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[113]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Conflict.ProtoReflect.Descriptor instead.
+func (*Conflict) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{113}
+}
+
+func (x *Conflict) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Conflict) GetRequiredBy() []*Edge {
+	if x != nil {
+		return x.RequiredBy
+	}
+	return nil
+}
+
+type FreshnessRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Days behind its latest release past which a dependency is stale; 90
+	// when not set.
+	StaleDays     int32 `protobuf:"varint,2,opt,name=stale_days,json=staleDays,proto3" json:"stale_days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FreshnessRequest) Reset() {
+	*x = FreshnessRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[114]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FreshnessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FreshnessRequest) ProtoMessage() {}
+
+func (x *FreshnessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[114]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FreshnessRequest.ProtoReflect.Descriptor instead.
+func (*FreshnessRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{114}
+}
+
+func (x *FreshnessRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *FreshnessRequest) GetStaleDays() int32 {
+	if x != nil {
+		return x.StaleDays
+	}
+	return 0
+}
+
+type FreshnessResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One per required dependency, in holon.mod order. Replaced dependencies
+	// are skipped.
+	Dependencies []*DependencyFreshness `protobuf:"bytes,1,rep,name=dependencies,proto3" json:"dependencies,omitempty"`
+	// The threshold applied.
+	StaleDays int32 `protobuf:"varint,2,opt,name=stale_days,json=staleDays,proto3" json:"stale_days,omitempty"`
+	// Requires and replaces of holon.mod whose until annotation has passed,
+	// one sentence each.
+	Expired       []string `protobuf:"bytes,3,rep,name=expired,proto3" json:"expired,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FreshnessResponse) Reset() {
+	*x = FreshnessResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[115]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FreshnessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FreshnessResponse) ProtoMessage() {}
+
+func (x *FreshnessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[115]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FreshnessResponse.ProtoReflect.Descriptor instead.
+func (*FreshnessResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{115}
+}
+
+func (x *FreshnessResponse) GetDependencies() []*DependencyFreshness {
+	if x != nil {
+		return x.Dependencies
+	}
+	return nil
+}
+
+func (x *FreshnessResponse) GetStaleDays() int32 {
+	if x != nil {
+		return x.StaleDays
+	}
+	return 0
+}
+
+func (x *FreshnessResponse) GetExpired() []string {
+	if x != nil {
+		return x.Expired
+	}
+	return nil
+}
+
+type DependencyFreshness struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The required version.
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// The highest release tag, across major versions; empty if unknown.
+	Latest string `protobuf:"bytes,3,opt,name=latest,proto3" json:"latest,omitempty"`
+	// Release tags higher than version.
+	VersionsBehind int32 `protobuf:"varint,4,opt,name=versions_behind,json=versionsBehind,proto3" json:"versions_behind,omitempty"`
+	// When version and latest were tagged, in RFC 3339 format; empty when
+	// unknown.
+	VersionTime string `protobuf:"bytes,5,opt,name=version_time,json=versionTime,proto3" json:"version_time,omitempty"`
+	LatestTime  string `protobuf:"bytes,6,opt,name=latest_time,json=latestTime,proto3" json:"latest_time,omitempty"`
+	// Days from version_time to latest_time.
+	DaysBehind int32 `protobuf:"varint,7,opt,name=days_behind,json=daysBehind,proto3" json:"days_behind,omitempty"`
+	// days_behind exceeds the threshold.
+	Stale bool `protobuf:"varint,8,opt,name=stale,proto3" json:"stale,omitempty"`
+	// Why the versions or dates are unknown, if they are.
+	Error string `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	// Set when the latest release deprecates version or the whole holon.
+	Deprecation   *Deprecation `protobuf:"bytes,10,opt,name=deprecation,proto3" json:"deprecation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DependencyFreshness) Reset() {
+	*x = DependencyFreshness{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[116]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DependencyFreshness) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DependencyFreshness) ProtoMessage() {}
+
+func (x *DependencyFreshness) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[116]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DependencyFreshness.ProtoReflect.Descriptor instead.
+func (*DependencyFreshness) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{116}
+}
+
+func (x *DependencyFreshness) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DependencyFreshness) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *DependencyFreshness) GetLatest() string {
+	if x != nil {
+		return x.Latest
+	}
+	return ""
+}
+
+func (x *DependencyFreshness) GetVersionsBehind() int32 {
+	if x != nil {
+		return x.VersionsBehind
+	}
+	return 0
+}
+
+func (x *DependencyFreshness) GetVersionTime() string {
+	if x != nil {
+		return x.VersionTime
+	}
+	return ""
+}
+
+func (x *DependencyFreshness) GetLatestTime() string {
+	if x != nil {
+		return x.LatestTime
+	}
+	return ""
+}
+
+func (x *DependencyFreshness) GetDaysBehind() int32 {
+	if x != nil {
+		return x.DaysBehind
+	}
+	return 0
+}
+
+func (x *DependencyFreshness) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *DependencyFreshness) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DependencyFreshness) GetDeprecation() *Deprecation {
+	if x != nil {
+		return x.Deprecation
+	}
+	return nil
+}
+
+type SubscribeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Kinds of events to receive; all of them when empty.
+	Kinds         []EventKind `protobuf:"varint,1,rep,packed,name=kinds,proto3,enum=rhizome_atlas.v1.EventKind" json:"kinds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[117]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[117]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{117}
+}
+
+func (x *SubscribeRequest) GetKinds() []EventKind {
+	if x != nil {
+		return x.Kinds
+	}
+	return nil
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Increases by one with each event of the server: a gap shows events
+	// dropped for a subscriber too slow to receive them.
+	Sequence uint64    `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Kind     EventKind `protobuf:"varint,2,opt,name=kind,proto3,enum=rhizome_atlas.v1.EventKind" json:"kind,omitempty"`
+	// When the event happened, in RFC 3339 format.
+	Time string `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	// The holon directory concerned, for verify and update events.
+	Directory string `protobuf:"bytes,4,opt,name=directory,proto3" json:"directory,omitempty"`
+	// The dependency concerned: fetched, with its cache path and source
+	// once fetched, or required at the version an update is available for.
+	Dependency *Dependency `protobuf:"bytes,5,opt,name=dependency,proto3" json:"dependency,omitempty"`
+	// The version a dependency can be updated to.
+	NewVersion string `protobuf:"bytes,6,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
+	// Why a fetch or a verification failed.
+	Errors []string `protobuf:"bytes,7,rep,name=errors,proto3" json:"errors,omitempty"`
+	// The cache entries removed, and the bytes they freed.
+	Entries       int32 `protobuf:"varint,8,opt,name=entries,proto3" json:"entries,omitempty"`
+	Bytes         int64 `protobuf:"varint,9,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[118]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[118]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{118}
+}
+
+func (x *Event) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *Event) GetKind() EventKind {
+	if x != nil {
+		return x.Kind
+	}
+	return EventKind_EVENT_KIND_UNSPECIFIED
+}
+
+func (x *Event) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *Event) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *Event) GetDependency() *Dependency {
+	if x != nil {
+		return x.Dependency
+	}
+	return nil
+}
+
+func (x *Event) GetNewVersion() string {
+	if x != nil {
+		return x.NewVersion
+	}
+	return ""
+}
+
+func (x *Event) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *Event) GetEntries() int32 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *Event) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+type DiagnoseRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, whose holon.sum and vendored copies
+	// are checked against.
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Content of holon.mod to check instead of the file, e.g. an unsaved
+	// editor buffer. The file is read when empty.
+	Content string `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	// Also list the upstream tags of each dependency, to report unknown
+	// versions and available updates. Slower, and needs the network.
+	Remote        bool `protobuf:"varint,3,opt,name=remote,proto3" json:"remote,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiagnoseRequest) Reset() {
+	*x = DiagnoseRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[119]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiagnoseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiagnoseRequest) ProtoMessage() {}
+
+func (x *DiagnoseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[119]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiagnoseRequest.ProtoReflect.Descriptor instead.
+func (*DiagnoseRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{119}
+}
+
+func (x *DiagnoseRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *DiagnoseRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *DiagnoseRequest) GetRemote() bool {
+	if x != nil {
+		return x.Remote
+	}
+	return false
+}
+
+type DiagnoseResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// In the order of the lines they concern.
+	Diagnostics   []*Diagnostic `protobuf:"bytes,1,rep,name=diagnostics,proto3" json:"diagnostics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiagnoseResponse) Reset() {
+	*x = DiagnoseResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[120]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiagnoseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiagnoseResponse) ProtoMessage() {}
+
+func (x *DiagnoseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[120]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiagnoseResponse.ProtoReflect.Descriptor instead.
+func (*DiagnoseResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{120}
+}
+
+func (x *DiagnoseResponse) GetDiagnostics() []*Diagnostic {
+	if x != nil {
+		return x.Diagnostics
+	}
+	return nil
+}
+
+type Diagnostic struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The text concerned: its line, and its columns, end excluded. Lines
+	// and columns count from 1; columns count bytes.
+	Line      int32              `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	Column    int32              `protobuf:"varint,2,opt,name=column,proto3" json:"column,omitempty"`
+	EndColumn int32              `protobuf:"varint,3,opt,name=end_column,json=endColumn,proto3" json:"end_column,omitempty"`
+	Severity  DiagnosticSeverity `protobuf:"varint,4,opt,name=severity,proto3,enum=rhizome_atlas.v1.DiagnosticSeverity" json:"severity,omitempty"`
+	// What kind of problem it is, e.g. "not-cached" or "update-available".
+	Code    string `protobuf:"bytes,5,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	// Ways to fix it, if any.
+	Fixes         []*Fix `protobuf:"bytes,7,rep,name=fixes,proto3" json:"fixes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Diagnostic) Reset() {
+	*x = Diagnostic{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[121]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Diagnostic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Diagnostic) ProtoMessage() {}
+
+func (x *Diagnostic) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[121]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Diagnostic.ProtoReflect.Descriptor instead.
+func (*Diagnostic) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{121}
+}
+
+func (x *Diagnostic) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *Diagnostic) GetColumn() int32 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+func (x *Diagnostic) GetEndColumn() int32 {
+	if x != nil {
+		return x.EndColumn
+	}
+	return 0
+}
+
+func (x *Diagnostic) GetSeverity() DiagnosticSeverity {
+	if x != nil {
+		return x.Severity
+	}
+	return DiagnosticSeverity_DIAGNOSTIC_SEVERITY_ERROR
+}
+
+func (x *Diagnostic) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Diagnostic) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Diagnostic) GetFixes() []*Fix {
+	if x != nil {
+		return x.Fixes
+	}
+	return nil
+}
+
+type Fix struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// e.g. "Update to v1.2.0".
+	Title         string      `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Edits         []*TextEdit `protobuf:"bytes,2,rep,name=edits,proto3" json:"edits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Fix) Reset() {
+	*x = Fix{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[122]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Fix) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fix) ProtoMessage() {}
+
+func (x *Fix) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[122]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fix.ProtoReflect.Descriptor instead.
+func (*Fix) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{122}
+}
+
+func (x *Fix) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Fix) GetEdits() []*TextEdit {
+	if x != nil {
+		return x.Edits
+	}
+	return nil
+}
+
+type TextEdit struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Replaces the text from line:column up to end_line:end_column, end
+	// excluded, with new_text; counted as in Diagnostic.
+	Line          int32  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	Column        int32  `protobuf:"varint,2,opt,name=column,proto3" json:"column,omitempty"`
+	EndLine       int32  `protobuf:"varint,3,opt,name=end_line,json=endLine,proto3" json:"end_line,omitempty"`
+	EndColumn     int32  `protobuf:"varint,4,opt,name=end_column,json=endColumn,proto3" json:"end_column,omitempty"`
+	NewText       string `protobuf:"bytes,5,opt,name=new_text,json=newText,proto3" json:"new_text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TextEdit) Reset() {
+	*x = TextEdit{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[123]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TextEdit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TextEdit) ProtoMessage() {}
+
+func (x *TextEdit) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[123]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TextEdit.ProtoReflect.Descriptor instead.
+func (*TextEdit) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{123}
+}
+
+func (x *TextEdit) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *TextEdit) GetColumn() int32 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+func (x *TextEdit) GetEndLine() int32 {
+	if x != nil {
+		return x.EndLine
+	}
+	return 0
+}
+
+func (x *TextEdit) GetEndColumn() int32 {
+	if x != nil {
+		return x.EndColumn
+	}
+	return 0
+}
+
+func (x *TextEdit) GetNewText() string {
+	if x != nil {
+		return x.NewText
+	}
+	return ""
+}
+
+type EnvRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod, or one of its subdirectories (see
+	// AddRequest.directory).
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Only the dependencies of this require group. Empty for all groups.
+	Group         string `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnvRequest) Reset() {
+	*x = EnvRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[124]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnvRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnvRequest) ProtoMessage() {}
+
+func (x *EnvRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[124]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnvRequest.ProtoReflect.Descriptor instead.
+func (*EnvRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{124}
+}
+
+func (x *EnvRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *EnvRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+type EnvResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// See AddResponse.root.
+	Root string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// One per required dependency, in holon.mod order. Optional ones that
+	// were not pulled are left out.
+	Dependencies  []*DependencyEnv `protobuf:"bytes,2,rep,name=dependencies,proto3" json:"dependencies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnvResponse) Reset() {
+	*x = EnvResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[125]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnvResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnvResponse) ProtoMessage() {}
+
+func (x *EnvResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[125]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnvResponse.ProtoReflect.Descriptor instead.
+func (*EnvResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{125}
+}
+
+func (x *EnvResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *EnvResponse) GetDependencies() []*DependencyEnv {
+	if x != nil {
+		return x.Dependencies
+	}
+	return nil
+}
+
+type DependencyEnv struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Path    string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// HOLON_DEP_ followed by the alias of the dependency, or else the last
+	// element of its path, in upper case with the characters other than
+	// letters and digits replaced by _, e.g. HOLON_DEP_GO_HOLONS.
+	Variable string `protobuf:"bytes,3,opt,name=variable,proto3" json:"variable,omitempty"`
+	// Where the dependency is, as an absolute path.
+	Dir string `protobuf:"bytes,4,opt,name=dir,proto3" json:"dir,omitempty"`
+	// "replace", "cache" or "vendor".
+	Location      string `protobuf:"bytes,5,opt,name=location,proto3" json:"location,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DependencyEnv) Reset() {
+	*x = DependencyEnv{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[126]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DependencyEnv) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DependencyEnv) ProtoMessage() {}
+
+func (x *DependencyEnv) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[126]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DependencyEnv.ProtoReflect.Descriptor instead.
+func (*DependencyEnv) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{126}
+}
+
+func (x *DependencyEnv) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DependencyEnv) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *DependencyEnv) GetVariable() string {
+	if x != nil {
+		return x.Variable
+	}
+	return ""
+}
+
+func (x *DependencyEnv) GetDir() string {
+	if x != nil {
+		return x.Dir
+	}
+	return ""
+}
+
+func (x *DependencyEnv) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+type ReplaceDiffRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// See AddRequest.directory.
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// The replaced dependency, by path or alias.
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// List the files changed and their line counts, without the diffs.
+	Stat          bool `protobuf:"varint,3,opt,name=stat,proto3" json:"stat,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplaceDiffRequest) Reset() {
+	*x = ReplaceDiffRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[127]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplaceDiffRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplaceDiffRequest) ProtoMessage() {}
+
+func (x *ReplaceDiffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[127]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplaceDiffRequest.ProtoReflect.Descriptor instead.
+func (*ReplaceDiffRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{127}
+}
+
+func (x *ReplaceDiffRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *ReplaceDiffRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ReplaceDiffRequest) GetStat() bool {
+	if x != nil {
+		return x.Stat
+	}
+	return false
+}
+
+type ReplaceDiffResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// See AddResponse.root.
+	Root string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// The dependency, and the version compared with.
+	Path    string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Version string `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	// The cached snapshot of that version, and the replace directory.
+	CachePath  string `protobuf:"bytes,4,opt,name=cache_path,json=cachePath,proto3" json:"cache_path,omitempty"`
+	ReplaceDir string `protobuf:"bytes,5,opt,name=replace_dir,json=replaceDir,proto3" json:"replace_dir,omitempty"`
+	// The files that differ, by path. Those under .git are left out.
+	Files         []*FileDiff `protobuf:"bytes,6,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplaceDiffResponse) Reset() {
+	*x = ReplaceDiffResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[128]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplaceDiffResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplaceDiffResponse) ProtoMessage() {}
+
+func (x *ReplaceDiffResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[128]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplaceDiffResponse.ProtoReflect.Descriptor instead.
+func (*ReplaceDiffResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{128}
+}
+
+func (x *ReplaceDiffResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *ReplaceDiffResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ReplaceDiffResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ReplaceDiffResponse) GetCachePath() string {
+	if x != nil {
+		return x.CachePath
+	}
+	return ""
+}
+
+func (x *ReplaceDiffResponse) GetReplaceDir() string {
+	if x != nil {
+		return x.ReplaceDir
+	}
+	return ""
+}
+
+func (x *ReplaceDiffResponse) GetFiles() []*FileDiff {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+// FileDiff is a file differing between two trees.
+type FileDiff struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Slash-separated, relative to the trees.
+	Path   string     `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Change FileChange `protobuf:"varint,2,opt,name=change,proto3,enum=rhizome_atlas.v1.FileChange" json:"change,omitempty"`
+	// Set for files that are not text; they have no diff nor line counts.
+	Binary bool `protobuf:"varint,3,opt,name=binary,proto3" json:"binary,omitempty"`
+	// Lines added and deleted.
+	Added   int32 `protobuf:"varint,4,opt,name=added,proto3" json:"added,omitempty"`
+	Deleted int32 `protobuf:"varint,5,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	// The unified diff, from "a/<path>" to "b/<path>" with 3 lines of
+	// context. Empty for binary files, and with ReplaceDiffRequest.stat.
+	Diff          string `protobuf:"bytes,6,opt,name=diff,proto3" json:"diff,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileDiff) Reset() {
+	*x = FileDiff{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[129]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileDiff) ProtoMessage() {}
+
+func (x *FileDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[129]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileDiff.ProtoReflect.Descriptor instead.
+func (*FileDiff) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{129}
+}
+
+func (x *FileDiff) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileDiff) GetChange() FileChange {
+	if x != nil {
+		return x.Change
+	}
+	return FileChange_FILE_CHANGE_UNSPECIFIED
+}
+
+func (x *FileDiff) GetBinary() bool {
+	if x != nil {
+		return x.Binary
+	}
+	return false
+}
+
+func (x *FileDiff) GetAdded() int32 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *FileDiff) GetDeleted() int32 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+func (x *FileDiff) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+type ReplacePromoteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod.
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Path or alias of the dependency replaced by a local directory.
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// Path of the published fork, e.g. "github.com/myorg/fork".
+	To string `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	// Version of the fork to require.
+	Version string `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	// Keep requiring the dependency by its path, at version, and replace it
+	// with the fork remotely, rather than requiring the fork itself.
+	KeepPath bool `protobuf:"varint,5,opt,name=keep_path,json=keepPath,proto3" json:"keep_path,omitempty"`
+	// Under the tofu directive, trust the fork as fetched if it was never
+	// trusted on this machine.
+	Trust bool `protobuf:"varint,6,opt,name=trust,proto3" json:"trust,omitempty"`
+	// Accept a snapshot of the fork that does not hash to what holon.sum or
+	// the trust store records.
+	Force bool `protobuf:"varint,7,opt,name=force,proto3" json:"force,omitempty"`
+	// Report the changes to holon.mod without fetching or writing anything.
+	DryRun        bool `protobuf:"varint,8,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplacePromoteRequest) Reset() {
+	*x = ReplacePromoteRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[130]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplacePromoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplacePromoteRequest) ProtoMessage() {}
+
+func (x *ReplacePromoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[130]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplacePromoteRequest.ProtoReflect.Descriptor instead.
+func (*ReplacePromoteRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{130}
+}
+
+func (x *ReplacePromoteRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *ReplacePromoteRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ReplacePromoteRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ReplacePromoteRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ReplacePromoteRequest) GetKeepPath() bool {
+	if x != nil {
+		return x.KeepPath
+	}
+	return false
+}
+
+func (x *ReplacePromoteRequest) GetTrust() bool {
+	if x != nil {
+		return x.Trust
+	}
+	return false
+}
+
+func (x *ReplacePromoteRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *ReplacePromoteRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type ReplacePromoteResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing the holon.mod changed.
+	Root string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// The require after the promotion, its cache path and source those of
+	// the fork.
+	Dependency *Dependency `protobuf:"bytes,2,opt,name=dependency,proto3" json:"dependency,omitempty"`
+	// The remote replace pointing at the fork, as "<path> => <fork>", with
+	// keep_path; empty otherwise.
+	Replace string `protobuf:"bytes,3,opt,name=replace,proto3" json:"replace,omitempty"`
+	// The local directory the replace pointed at.
+	ReplaceDir string `protobuf:"bytes,4,opt,name=replace_dir,json=replaceDir,proto3" json:"replace_dir,omitempty"`
+	// Files differing between the fork and replace_dir, without their
+	// diffs: local modifications the fork does not carry, or changes it
+	// carries beyond them. Empty after a dry run.
+	Differences []*FileDiff `protobuf:"bytes,5,rep,name=differences,proto3" json:"differences,omitempty"`
+	// What a dry run would do.
+	Plan          *Plan `protobuf:"bytes,6,opt,name=plan,proto3" json:"plan,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplacePromoteResponse) Reset() {
+	*x = ReplacePromoteResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[131]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplacePromoteResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CleanCacheResponse) ProtoMessage() {}
+func (*ReplacePromoteResponse) ProtoMessage() {}
 
-func (x *CleanCacheResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[19]
+func (x *ReplacePromoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[131]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -974,43 +9579,80 @@ func (x *CleanCacheResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CleanCacheResponse.ProtoReflect.Descriptor instead.
-func (*CleanCacheResponse) Descriptor() ([]byte, []int) {
-	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use ReplacePromoteResponse.ProtoReflect.Descriptor instead.
+func (*ReplacePromoteResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{131}
 }
 
-func (x *CleanCacheResponse) GetCachePath() string {
+func (x *ReplacePromoteResponse) GetRoot() string {
 	if x != nil {
-		return x.CachePath
+		return x.Root
 	}
 	return ""
 }
 
-type Dependency struct {
-	state   protoimpl.MessageState `protogen:"open.v1"`
-	Path    string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	// Where this dependency was resolved to.
-	CachePath     string `protobuf:"bytes,3,opt,name=cache_path,json=cachePath,proto3" json:"cache_path,omitempty"`
+func (x *ReplacePromoteResponse) GetDependency() *Dependency {
+	if x != nil {
+		return x.Dependency
+	}
+	return nil
+}
+
+func (x *ReplacePromoteResponse) GetReplace() string {
+	if x != nil {
+		return x.Replace
+	}
+	return ""
+}
+
+func (x *ReplacePromoteResponse) GetReplaceDir() string {
+	if x != nil {
+		return x.ReplaceDir
+	}
+	return ""
+}
+
+func (x *ReplacePromoteResponse) GetDifferences() []*FileDiff {
+	if x != nil {
+		return x.Differences
+	}
+	return nil
+}
+
+func (x *ReplacePromoteResponse) GetPlan() *Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+type TidyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing holon.mod and holon.sum.
+	Directory string `protobuf:"bytes,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	// Report the changes without writing holon.mod or holon.sum.
+	DryRun bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// Keep every require, only tidying holon.sum.
+	KeepRequires  bool `protobuf:"varint,3,opt,name=keep_requires,json=keepRequires,proto3" json:"keep_requires,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Dependency) Reset() {
-	*x = Dependency{}
-	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[20]
+func (x *TidyRequest) Reset() {
+	*x = TidyRequest{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[132]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Dependency) String() string {
+func (x *TidyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Dependency) ProtoMessage() {}
+func (*TidyRequest) ProtoMessage() {}
 
-func (x *Dependency) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[20]
+func (x *TidyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[132]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1021,109 +9663,932 @@ func (x *Dependency) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Dependency.ProtoReflect.Descriptor instead.
-func (*Dependency) Descriptor() ([]byte, []int) {
-	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use TidyRequest.ProtoReflect.Descriptor instead.
+func (*TidyRequest) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{132}
 }
 
-func (x *Dependency) GetPath() string {
+func (x *TidyRequest) GetDirectory() string {
 	if x != nil {
-		return x.Path
+		return x.Directory
 	}
 	return ""
 }
 
-func (x *Dependency) GetVersion() string {
+func (x *TidyRequest) GetDryRun() bool {
 	if x != nil {
-		return x.Version
+		return x.DryRun
 	}
-	return ""
+	return false
 }
 
-func (x *Dependency) GetCachePath() string {
+func (x *TidyRequest) GetKeepRequires() bool {
 	if x != nil {
-		return x.CachePath
+		return x.KeepRequires
+	}
+	return false
+}
+
+type TidyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Directory containing the holon.mod tidied.
+	Root string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// Requires dropped from holon.mod: no file of the holon names their
+	// path or environment variable, no dependency requires them, and they
+	// are not annotated tidy=keep.
+	Dropped []*Dependency `protobuf:"bytes,2,rep,name=dropped,proto3" json:"dropped,omitempty"`
+	// Entries removed from holon.sum.
+	Removed []*SumEntry `protobuf:"bytes,3,rep,name=removed,proto3" json:"removed,omitempty"`
+	// Entries added to holon.sum.
+	Added         []*SumEntry `protobuf:"bytes,4,rep,name=added,proto3" json:"added,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TidyResponse) Reset() {
+	*x = TidyResponse{}
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[133]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TidyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TidyResponse) ProtoMessage() {}
+
+func (x *TidyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes[133]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TidyResponse.ProtoReflect.Descriptor instead.
+func (*TidyResponse) Descriptor() ([]byte, []int) {
+	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP(), []int{133}
+}
+
+func (x *TidyResponse) GetRoot() string {
+	if x != nil {
+		return x.Root
 	}
 	return ""
 }
 
+func (x *TidyResponse) GetDropped() []*Dependency {
+	if x != nil {
+		return x.Dropped
+	}
+	return nil
+}
+
+func (x *TidyResponse) GetRemoved() []*SumEntry {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *TidyResponse) GetAdded() []*SumEntry {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
 var File_protos_rhizome_atlas_v1_rhizome_atlas_proto protoreflect.FileDescriptor
 
 const file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDesc = "" +
 	"\n" +
-	"+protos/rhizome_atlas/v1/rhizome_atlas.proto\x12\x10rhizome_atlas.v1\"J\n" +
+	"+protos/rhizome_atlas/v1/rhizome_atlas.proto\x12\x10rhizome_atlas.v1\"o\n" +
 	"\vInitRequest\x12\x1c\n" +
 	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x1d\n" +
 	"\n" +
-	"holon_path\x18\x02 \x01(\tR\tholonPath\")\n" +
+	"holon_path\x18\x02 \x01(\tR\tholonPath\x12#\n" +
+	"\rfrom_existing\x18\x03 \x01(\bR\ffromExisting\"\x83\x01\n" +
 	"\fInitResponse\x12\x19\n" +
-	"\bmod_file\x18\x01 \x01(\tR\amodFile\"X\n" +
+	"\bmod_file\x18\x01 \x01(\tR\amodFile\x128\n" +
+	"\binferred\x18\x02 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\binferred\x12\x1e\n" +
+	"\n" +
+	"unresolved\x18\x03 \x03(\tR\n" +
+	"unresolved\"\x86\x02\n" +
+	"\n" +
+	"NewRequest\x12\x1a\n" +
+	"\btemplate\x18\x01 \x01(\tR\btemplate\x12\x1c\n" +
+	"\tdirectory\x18\x02 \x01(\tR\tdirectory\x12\x1d\n" +
+	"\n" +
+	"holon_path\x18\x03 \x01(\tR\tholonPath\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x16\n" +
+	"\x06author\x18\x05 \x01(\tR\x06author\x12:\n" +
+	"\x04vars\x18\x06 \x03(\v2&.rhizome_atlas.v1.NewRequest.VarsEntryR\x04vars\x1a7\n" +
+	"\tVarsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"Y\n" +
+	"\vNewResponse\x12\x1a\n" +
+	"\btemplate\x18\x01 \x01(\tR\btemplate\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x14\n" +
+	"\x05files\x18\x03 \x03(\tR\x05files\"\x9c\x02\n" +
 	"\n" +
 	"AddRequest\x12\x1c\n" +
 	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x12\n" +
 	"\x04path\x18\x02 \x01(\tR\x04path\x12\x18\n" +
-	"\aversion\x18\x03 \x01(\tR\aversion\"K\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12\x14\n" +
+	"\x05alias\x18\x04 \x01(\tR\x05alias\x12\x1f\n" +
+	"\vrecord_only\x18\x05 \x01(\bR\n" +
+	"recordOnly\x12\x17\n" +
+	"\adry_run\x18\x06 \x01(\bR\x06dryRun\x12'\n" +
+	"\x0fidempotency_key\x18\a \x01(\tR\x0eidempotencyKey\x12\x14\n" +
+	"\x05force\x18\b \x01(\bR\x05force\x12\x14\n" +
+	"\x05trust\x18\t \x01(\bR\x05trust\x12\x1d\n" +
+	"\n" +
+	"trust_hash\x18\n" +
+	" \x01(\tR\ttrustHash\"\xc4\x01\n" +
 	"\vAddResponse\x12<\n" +
 	"\n" +
 	"dependency\x18\x01 \x01(\v2\x1c.rhizome_atlas.v1.DependencyR\n" +
-	"dependency\"A\n" +
+	"dependency\x12*\n" +
+	"\x04plan\x18\x02 \x01(\v2\x16.rhizome_atlas.v1.PlanR\x04plan\x12\x12\n" +
+	"\x04root\x18\x03 \x01(\tR\x04root\x127\n" +
+	"\atrusted\x18\x04 \x01(\v2\x1d.rhizome_atlas.v1.TrustRecordR\atrusted\"Q\n" +
+	"\vTrustRecord\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x16\n" +
+	"\x06commit\x18\x02 \x01(\tR\x06commit\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\"\\\n" +
+	"\x0eResolveRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"\xc7\x01\n" +
+	"\x0fResolveResponse\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1f\n" +
+	"\vsource_path\x18\x03 \x01(\tR\n" +
+	"sourcePath\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12\x16\n" +
+	"\x06commit\x18\x05 \x01(\tR\x06commit\x12\x16\n" +
+	"\x06cached\x18\x06 \x01(\bR\x06cached\x12\x1d\n" +
+	"\n" +
+	"cache_path\x18\a \x01(\tR\tcachePath\"\x83\x01\n" +
 	"\rRemoveRequest\x12\x1c\n" +
 	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x12\n" +
-	"\x04path\x18\x02 \x01(\tR\x04path\"\x10\n" +
-	"\x0eRemoveResponse\"+\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\"P\n" +
+	"\x0eRemoveResponse\x12*\n" +
+	"\x04plan\x18\x01 \x01(\v2\x16.rhizome_atlas.v1.PlanR\x04plan\x12\x12\n" +
+	"\x04root\x18\x02 \x01(\tR\x04root\"\xdc\x01\n" +
 	"\vPullRequest\x12\x1c\n" +
-	"\tdirectory\x18\x01 \x01(\tR\tdirectory\"F\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x18\n" +
+	"\aresolve\x18\x02 \x01(\tR\aresolve\x12\x14\n" +
+	"\x05group\x18\x03 \x01(\tR\x05group\x12\x12\n" +
+	"\x04with\x18\x04 \x03(\tR\x04with\x12\x16\n" +
+	"\x06repair\x18\x05 \x01(\bR\x06repair\x12\x14\n" +
+	"\x05force\x18\x06 \x01(\bR\x05force\x12%\n" +
+	"\x0eenforce_budget\x18\a \x01(\bR\renforceBudget\x12\x16\n" +
+	"\x06locked\x18\b \x01(\bR\x06locked\"\xdc\x03\n" +
 	"\fPullResponse\x126\n" +
-	"\afetched\x18\x01 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\afetched\"-\n" +
+	"\afetched\x18\x01 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\afetched\x12\x1a\n" +
+	"\bstrategy\x18\x02 \x01(\tR\bstrategy\x128\n" +
+	"\bresolved\x18\x03 \x03(\v2\x1c.rhizome_atlas.v1.ResolutionR\bresolved\x126\n" +
+	"\askipped\x18\x04 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\askipped\x12\x12\n" +
+	"\x04root\x18\x05 \x01(\tR\x04root\x128\n" +
+	"\brepaired\x18\x06 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\brepaired\x12A\n" +
+	"\vover_budget\x18\a \x03(\v2 .rhizome_atlas.v1.BudgetExceededR\n" +
+	"overBudget\x12=\n" +
+	"\n" +
+	"deprecated\x18\b \x03(\v2\x1d.rhizome_atlas.v1.DeprecationR\n" +
+	"deprecated\x126\n" +
+	"\atrusted\x18\t \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\atrusted\"r\n" +
+	"\vLockRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x18\n" +
+	"\aresolve\x18\x02 \x01(\tR\aresolve\x12\x12\n" +
+	"\x04with\x18\x03 \x03(\tR\x04with\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"\x92\x01\n" +
+	"\fLockResponse\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x12\x1a\n" +
+	"\bstrategy\x18\x02 \x01(\tR\bstrategy\x128\n" +
+	"\bresolved\x18\x03 \x03(\v2\x1c.rhizome_atlas.v1.ResolutionR\bresolved\x12\x18\n" +
+	"\achanged\x18\x04 \x01(\bR\achanged\"\xcd\x01\n" +
+	"\fPullProgress\x121\n" +
+	"\x05stage\x18\x01 \x01(\x0e2\x1b.rhizome_atlas.v1.PullStageR\x05stage\x12<\n" +
+	"\n" +
+	"dependency\x18\x02 \x01(\v2\x1c.rhizome_atlas.v1.DependencyR\n" +
+	"dependency\x12\x14\n" +
+	"\x05bytes\x18\x03 \x01(\x03R\x05bytes\x126\n" +
+	"\x06result\x18\x04 \x01(\v2\x1e.rhizome_atlas.v1.PullResponseR\x06result\"\xaa\x01\n" +
+	"\vDeprecation\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x14\n" +
+	"\x05holon\x18\x03 \x01(\bR\x05holon\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x12\x1c\n" +
+	"\tsuccessor\x18\x05 \x01(\tR\tsuccessor\x12\x1f\n" +
+	"\vdeclared_by\x18\x06 \x01(\tR\n" +
+	"declaredBy\"\\\n" +
+	"\x0eBudgetExceeded\x12\x1e\n" +
+	"\n" +
+	"dependency\x18\x01 \x01(\tR\n" +
+	"dependency\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06budget\x18\x03 \x01(\x03R\x06budget\"z\n" +
+	"\n" +
+	"Resolution\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12>\n" +
+	"\vrequired_by\x18\x03 \x03(\v2\x1d.rhizome_atlas.v1.RequirementR\n" +
+	"requiredBy\"=\n" +
+	"\vRequirement\x12\x14\n" +
+	"\x05holon\x18\x01 \x01(\tR\x05holon\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\"\x87\x01\n" +
 	"\rVerifyRequest\x12\x1c\n" +
-	"\tdirectory\x18\x01 \x01(\tR\tdirectory\"8\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x16\n" +
+	"\x06remote\x18\x02 \x01(\bR\x06remote\x12\x10\n" +
+	"\x03log\x18\x03 \x01(\bR\x03log\x12\x16\n" +
+	"\x06repair\x18\x04 \x01(\bR\x06repair\x12\x16\n" +
+	"\x06strict\x18\x05 \x01(\bR\x06strict\"\xdf\x01\n" +
 	"\x0eVerifyResponse\x12\x0e\n" +
 	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x16\n" +
-	"\x06errors\x18\x02 \x03(\tR\x06errors\",\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\x12\x12\n" +
+	"\x04root\x18\x03 \x01(\tR\x04root\x128\n" +
+	"\brepaired\x18\x04 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\brepaired\x12;\n" +
+	"\bfindings\x18\x05 \x03(\v2\x1f.rhizome_atlas.v1.VerifyFindingR\bfindings\x12\x1a\n" +
+	"\bwarnings\x18\x06 \x03(\tR\bwarnings\"\x7f\n" +
+	"\rVerifyFinding\x12@\n" +
+	"\bseverity\x18\x01 \x01(\x0e2$.rhizome_atlas.v1.DiagnosticSeverityR\bseverity\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\xa2\x01\n" +
+	"\x10VerifyAllRequest\x12 \n" +
+	"\vdirectories\x18\x01 \x03(\tR\vdirectories\x12\x12\n" +
+	"\x04root\x18\x02 \x01(\tR\x04root\x12\x16\n" +
+	"\x06remote\x18\x03 \x01(\bR\x06remote\x12\x10\n" +
+	"\x03log\x18\x04 \x01(\bR\x03log\x12\x16\n" +
+	"\x06repair\x18\x05 \x01(\bR\x06repair\x12\x16\n" +
+	"\x06strict\x18\x06 \x01(\bR\x06strict\"b\n" +
+	"\x11VerifyAllResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12=\n" +
+	"\aresults\x18\x02 \x03(\v2#.rhizome_atlas.v1.HolonVerificationR\aresults\"u\n" +
+	"\x11HolonVerification\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x0e\n" +
+	"\x02ok\x18\x02 \x01(\bR\x02ok\x12\x16\n" +
+	"\x06errors\x18\x03 \x03(\tR\x06errors\x12\x1a\n" +
+	"\bwarnings\x18\x04 \x03(\tR\bwarnings\"\xab\x01\n" +
 	"\fGraphRequest\x12\x1c\n" +
-	"\tdirectory\x18\x01 \x01(\tR\tdirectory\"Q\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12B\n" +
+	"\x06filter\x18\x02 \x03(\v2*.rhizome_atlas.v1.GraphRequest.FilterEntryR\x06filter\x1a9\n" +
+	"\vFilterEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x7f\n" +
 	"\rGraphResponse\x12\x12\n" +
 	"\x04root\x18\x01 \x01(\tR\x04root\x12,\n" +
-	"\x05edges\x18\x02 \x03(\v2\x16.rhizome_atlas.v1.EdgeR\x05edges\"D\n" +
+	"\x05edges\x18\x02 \x03(\v2\x16.rhizome_atlas.v1.EdgeR\x05edges\x12,\n" +
+	"\x05nodes\x18\x03 \x03(\v2\x16.rhizome_atlas.v1.NodeR\x05nodes\"\x80\x01\n" +
+	"\x04Node\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1a\n" +
+	"\blocation\x18\x03 \x01(\tR\blocation\x12\x18\n" +
+	"\areplace\x18\x04 \x01(\tR\areplace\x12\x14\n" +
+	"\x05title\x18\x05 \x01(\tR\x05title\"\xfc\x01\n" +
 	"\x04Edge\x12\x12\n" +
 	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
 	"\x02to\x18\x02 \x01(\tR\x02to\x12\x18\n" +
-	"\aversion\x18\x03 \x01(\tR\aversion\"-\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12\x14\n" +
+	"\x05alias\x18\x04 \x01(\tR\x05alias\x12@\n" +
+	"\bmetadata\x18\x05 \x03(\v2$.rhizome_atlas.v1.Edge.MetadataEntryR\bmetadata\x12!\n" +
+	"\ffrom_version\x18\x06 \x01(\tR\vfromVersion\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd6\x01\n" +
+	"\x12StreamGraphRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x02 \x01(\x05R\tchunkSize\x12H\n" +
+	"\x06filter\x18\x03 \x03(\v20.rhizome_atlas.v1.StreamGraphRequest.FilterEntryR\x06filter\x1a9\n" +
+	"\vFilterEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb6\x01\n" +
+	"\n" +
+	"GraphChunk\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x12,\n" +
+	"\x05edges\x18\x02 \x03(\v2\x16.rhizome_atlas.v1.EdgeR\x05edges\x128\n" +
+	"\asummary\x18\x03 \x01(\v2\x1e.rhizome_atlas.v1.GraphSummaryR\asummary\x12,\n" +
+	"\x05nodes\x18\x04 \x03(\v2\x16.rhizome_atlas.v1.NodeR\x05nodes\"-\n" +
+	"\fGraphSummary\x12\x1d\n" +
+	"\n" +
+	"edge_count\x18\x01 \x01(\x05R\tedgeCount\"\xa3\x02\n" +
 	"\rUpdateRequest\x12\x1c\n" +
-	"\tdirectory\x18\x01 \x01(\tR\tdirectory\"O\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12%\n" +
+	"\x0eallow_breaking\x18\x02 \x01(\bR\rallowBreaking\x12\x18\n" +
+	"\achannel\x18\x03 \x01(\tR\achannel\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\x12'\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\x12\x1c\n" +
+	"\tchangelog\x18\x06 \x01(\bR\tchangelog\x12?\n" +
+	"\tbranching\x18\a \x01(\x0e2!.rhizome_atlas.v1.UpdateBranchingR\tbranching\x12\x12\n" +
+	"\x04push\x18\b \x01(\bR\x04push\"\xd4\x02\n" +
 	"\x0eUpdateResponse\x12=\n" +
-	"\aupdated\x18\x01 \x03(\v2#.rhizome_atlas.v1.UpdatedDependencyR\aupdated\"i\n" +
+	"\aupdated\x18\x01 \x03(\v2#.rhizome_atlas.v1.UpdatedDependencyR\aupdated\x127\n" +
+	"\x04held\x18\x02 \x03(\v2#.rhizome_atlas.v1.UpdatedDependencyR\x04held\x12*\n" +
+	"\x04plan\x18\x03 \x01(\v2\x16.rhizome_atlas.v1.PlanR\x04plan\x12\x1c\n" +
+	"\tchangelog\x18\x04 \x01(\tR\tchangelog\x12\x12\n" +
+	"\x04root\x18\x05 \x01(\tR\x04root\x120\n" +
+	"\x05moved\x18\x06 \x03(\v2\x1a.rhizome_atlas.v1.MovedTagR\x05moved\x12:\n" +
+	"\bbranches\x18\a \x03(\v2\x1e.rhizome_atlas.v1.UpdateBranchR\bbranches\"\x91\x01\n" +
+	"\fUpdateBranch\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06commit\x18\x02 \x01(\tR\x06commit\x12=\n" +
+	"\aupdated\x18\x03 \x03(\v2#.rhizome_atlas.v1.UpdatedDependencyR\aupdated\x12\x16\n" +
+	"\x06pushed\x18\x04 \x01(\bR\x06pushed\"\x86\x01\n" +
+	"\bMovedTag\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12#\n" +
+	"\rpinned_commit\x18\x03 \x01(\tR\fpinnedCommit\x12'\n" +
+	"\x0fupstream_commit\x18\x04 \x01(\tR\x0eupstreamCommit\"\xd9\x01\n" +
 	"\x11UpdatedDependency\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1f\n" +
 	"\vold_version\x18\x02 \x01(\tR\n" +
 	"oldVersion\x12\x1f\n" +
 	"\vnew_version\x18\x03 \x01(\tR\n" +
-	"newVersion\"-\n" +
+	"newVersion\x121\n" +
+	"\x14removed_capabilities\x18\x04 \x03(\tR\x13removedCapabilities\x12\x14\n" +
+	"\x05notes\x18\x05 \x01(\tR\x05notes\x12%\n" +
+	"\x0econtract_error\x18\x06 \x01(\tR\rcontractError\"\xac\x01\n" +
 	"\rVendorRequest\x12\x1c\n" +
-	"\tdirectory\x18\x01 \x01(\tR\tdirectory\"J\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\x12\x14\n" +
+	"\x05group\x18\x04 \x01(\tR\x05group\x12%\n" +
+	"\x0eenforce_budget\x18\x05 \x01(\bR\renforceBudget\"\xcd\x01\n" +
 	"\x0eVendorResponse\x128\n" +
-	"\bvendored\x18\x01 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\bvendored\"\x13\n" +
-	"\x11CleanCacheRequest\"3\n" +
+	"\bvendored\x18\x01 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\bvendored\x12*\n" +
+	"\x04plan\x18\x02 \x01(\v2\x16.rhizome_atlas.v1.PlanR\x04plan\x12\x12\n" +
+	"\x04root\x18\x03 \x01(\tR\x04root\x12A\n" +
+	"\vover_budget\x18\x04 \x03(\v2 .rhizome_atlas.v1.BudgetExceededR\n" +
+	"overBudget\"\x8f\x01\n" +
+	"\x13StreamVendorRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\x12\x1d\n" +
+	"\n" +
+	"keep_going\x18\x03 \x01(\bR\tkeepGoing\x12%\n" +
+	"\x0eenforce_budget\x18\x04 \x01(\bR\renforceBudget\"\xe1\x01\n" +
+	"\x0eVendorProgress\x12<\n" +
+	"\n" +
+	"dependency\x18\x01 \x01(\v2\x1c.rhizome_atlas.v1.DependencyR\n" +
+	"dependency\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x14\n" +
+	"\x05index\x18\x03 \x01(\x05R\x05index\x12\x14\n" +
+	"\x05total\x18\x04 \x01(\x05R\x05total\x12\x14\n" +
+	"\x05bytes\x18\x05 \x01(\x03R\x05bytes\x129\n" +
+	"\asummary\x18\x06 \x01(\v2\x1f.rhizome_atlas.v1.VendorSummaryR\asummary\"\xb0\x01\n" +
+	"\rVendorSummary\x12\x1a\n" +
+	"\bvendored\x18\x01 \x01(\x05R\bvendored\x12\x16\n" +
+	"\x06failed\x18\x02 \x01(\x05R\x06failed\x12\x14\n" +
+	"\x05bytes\x18\x03 \x01(\x03R\x05bytes\x12\x12\n" +
+	"\x04root\x18\x04 \x01(\tR\x04root\x12A\n" +
+	"\vover_budget\x18\x05 \x03(\v2 .rhizome_atlas.v1.BudgetExceededR\n" +
+	"overBudget\",\n" +
+	"\x11CleanCacheRequest\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\"_\n" +
 	"\x12CleanCacheResponse\x12\x1d\n" +
 	"\n" +
-	"cache_path\x18\x01 \x01(\tR\tcachePath\"Y\n" +
+	"cache_path\x18\x01 \x01(\tR\tcachePath\x12*\n" +
+	"\x04plan\x18\x02 \x01(\v2\x16.rhizome_atlas.v1.PlanR\x04plan\"N\n" +
+	"\x10CacheListRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"s\n" +
+	"\x11CacheListResponse\x126\n" +
+	"\aentries\x18\x01 \x03(\v2\x1c.rhizome_atlas.v1.CacheEntryR\aentries\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xa0\x01\n" +
+	"\n" +
+	"CacheEntry\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1d\n" +
+	"\n" +
+	"cache_path\x18\x03 \x01(\tR\tcachePath\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x121\n" +
+	"\x05fetch\x18\x05 \x01(\v2\x1b.rhizome_atlas.v1.FetchInfoR\x05fetch\"\x88\x01\n" +
+	"\tFetchInfo\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x18\n" +
+	"\afetcher\x18\x02 \x01(\tR\afetcher\x12\x12\n" +
+	"\x04time\x18\x03 \x01(\tR\x04time\x12\x16\n" +
+	"\x06commit\x18\x04 \x01(\tR\x06commit\x12\x1d\n" +
+	"\n" +
+	"tag_object\x18\x05 \x01(\tR\ttagObject\"c\n" +
+	"\x13CacheOrphansRequest\x12 \n" +
+	"\vdirectories\x18\x01 \x03(\tR\vdirectories\x12\x12\n" +
+	"\x04root\x18\x02 \x01(\tR\x04root\x12\x16\n" +
+	"\x06delete\x18\x03 \x01(\bR\x06delete\"\x81\x01\n" +
+	"\x14CacheOrphansResponse\x126\n" +
+	"\aorphans\x18\x01 \x03(\v2\x1c.rhizome_atlas.v1.CacheEntryR\aorphans\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x03R\x05bytes\x12\x1b\n" +
+	"\tsum_files\x18\x03 \x03(\tR\bsumFiles\"I\n" +
+	"\x10CacheSeedRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"\xc1\x01\n" +
+	"\x11CacheSeedResponse\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x124\n" +
+	"\x06seeded\x18\x02 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\x06seeded\x126\n" +
+	"\apresent\x18\x03 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\apresent\x12*\n" +
+	"\x04plan\x18\x04 \x01(\v2\x16.rhizome_atlas.v1.PlanR\x04plan\"X\n" +
+	"\x10CachePushRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
+	"\x06target\x18\x03 \x01(\tR\x06target\"\xf5\x01\n" +
+	"\x11CachePushResponse\x12\x1c\n" +
+	"\treference\x18\x01 \x01(\tR\treference\x12\x16\n" +
+	"\x06digest\x18\x02 \x01(\tR\x06digest\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12V\n" +
+	"\vannotations\x18\x04 \x03(\v24.rhizome_atlas.v1.CachePushResponse.AnnotationsEntryR\vannotations\x1a>\n" +
+	"\x10AnnotationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"?\n" +
+	"\x0fHasEntryRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\"@\n" +
+	"\x10HasEntryResponse\x12\x18\n" +
+	"\apresent\x18\x01 \x01(\bR\apresent\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\"A\n" +
+	"\x11FetchEntryRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\"%\n" +
+	"\x0fFetchEntryChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\x13\n" +
+	"\x11GetLogHeadRequest\"\xaf\x01\n" +
+	"\aLogHead\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\x12\x1b\n" +
+	"\troot_hash\x18\x02 \x01(\fR\brootHash\x12\x1c\n" +
+	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\x12\x18\n" +
+	"\aexpires\x18\x04 \x01(\x03R\aexpires\x12\x1c\n" +
+	"\tsignature\x18\x05 \x01(\fR\tsignature\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x06 \x01(\fR\tpublicKey\"e\n" +
+	"\x18ProveLogInclusionRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1b\n" +
+	"\ttree_size\x18\x03 \x01(\x03R\btreeSize\"R\n" +
+	"\x19ProveLogInclusionResponse\x125\n" +
+	"\arecords\x18\x01 \x03(\v2\x1b.rhizome_atlas.v1.LogRecordR\arecords\"K\n" +
+	"\tLogRecord\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\tR\x04hash\x12\x14\n" +
+	"\x05proof\x18\x03 \x03(\fR\x05proof\"R\n" +
+	"\x1aProveLogConsistencyRequest\x12\x19\n" +
+	"\bold_size\x18\x01 \x01(\x03R\aoldSize\x12\x19\n" +
+	"\bnew_size\x18\x02 \x01(\x03R\anewSize\"b\n" +
+	"\x1bProveLogConsistencyResponse\x12-\n" +
+	"\x04head\x18\x01 \x01(\v2\x19.rhizome_atlas.v1.LogHeadR\x04head\x12\x14\n" +
+	"\x05proof\x18\x02 \x03(\fR\x05proof\"]\n" +
+	"\x0fDescribeRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"L\n" +
+	"\x10DescribeResponse\x128\n" +
+	"\x05holon\x18\x01 \x01(\v2\".rhizome_atlas.v1.HolonDescriptionR\x05holon\"\xd5\x02\n" +
+	"\x10HolonDescription\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\asummary\x18\x04 \x01(\tR\asummary\x12\"\n" +
+	"\fcapabilities\x18\x05 \x03(\tR\fcapabilities\x12 \n" +
+	"\vmaintainers\x18\x06 \x03(\tR\vmaintainers\x12<\n" +
+	"\n" +
+	"provenance\x18\a \x01(\v2\x1c.rhizome_atlas.v1.ProvenanceR\n" +
+	"provenance\x121\n" +
+	"\x05fetch\x18\b \x01(\v2\x1b.rhizome_atlas.v1.FetchInfoR\x05fetch\x12\x14\n" +
+	"\x05owner\x18\t \x01(\tR\x05owner\x12\x18\n" +
+	"\acontact\x18\n" +
+	" \x01(\tR\acontact\"r\n" +
+	"\n" +
+	"Provenance\x12\x18\n" +
+	"\abuilder\x18\x01 \x01(\tR\abuilder\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x16\n" +
+	"\x06commit\x18\x03 \x01(\tR\x06commit\x12\x1a\n" +
+	"\bworkflow\x18\x04 \x01(\tR\bworkflow\"U\n" +
+	"\x15FindCapabilityRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x1e\n" +
+	"\n" +
+	"capability\x18\x02 \x01(\tR\n" +
+	"capability\"T\n" +
+	"\x16FindCapabilityResponse\x12:\n" +
+	"\tproviders\x18\x01 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\tproviders\"A\n" +
+	"\rOwnersRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"G\n" +
+	"\x0eOwnersResponse\x125\n" +
+	"\x06holons\x18\x01 \x03(\v2\x1d.rhizome_atlas.v1.HolonOwnersR\x06holons\"\xae\x01\n" +
+	"\vHolonOwners\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x14\n" +
+	"\x05owner\x18\x03 \x01(\tR\x05owner\x12 \n" +
+	"\vmaintainers\x18\x04 \x03(\tR\vmaintainers\x12\x18\n" +
+	"\acontact\x18\x05 \x01(\tR\acontact\x12\x1f\n" +
+	"\vlocal_owner\x18\x06 \x01(\tR\n" +
+	"localOwner\"\xe5\x01\n" +
+	"\x0eReleaseRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x121\n" +
+	"\x04bump\x18\x02 \x01(\x0e2\x1d.rhizome_atlas.v1.ReleaseBumpR\x04bump\x12\x12\n" +
+	"\x04push\x18\x03 \x01(\bR\x04push\x12\x18\n" +
+	"\abuilder\x18\x04 \x01(\tR\abuilder\x12\x1a\n" +
+	"\bworkflow\x18\x05 \x01(\tR\bworkflow\x12\x1e\n" +
+	"\n" +
+	"provenance\x18\x06 \x01(\fR\n" +
+	"provenance\x12\x18\n" +
+	"\apublish\x18\a \x01(\bR\apublish\"\xa8\x01\n" +
+	"\x0fReleaseResponse\x12)\n" +
+	"\x10previous_version\x18\x01 \x01(\tR\x0fpreviousVersion\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
+	"\x06pushed\x18\x03 \x01(\bR\x06pushed\x12\x1a\n" +
+	"\battested\x18\x04 \x01(\bR\battested\x12\x1c\n" +
+	"\tpublished\x18\x05 \x01(\tR\tpublished\"a\n" +
+	"\x13BundleCreateRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x16\n" +
+	"\x06output\x18\x02 \x01(\tR\x06output\x12\x14\n" +
+	"\x05group\x18\x03 \x01(\tR\x05group\"p\n" +
+	"\x14BundleCreateResponse\x12\x16\n" +
+	"\x06output\x18\x01 \x01(\tR\x06output\x12@\n" +
+	"\fdependencies\x18\x02 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\fdependencies\"J\n" +
+	"\x14BundleInstallRequest\x12\x14\n" +
+	"\x05input\x18\x01 \x01(\tR\x05input\x12\x1c\n" +
+	"\tdirectory\x18\x02 \x01(\tR\tdirectory\"n\n" +
+	"\x15BundleInstallResponse\x12:\n" +
+	"\tinstalled\x18\x01 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\tinstalled\x12\x19\n" +
+	"\bmod_file\x18\x02 \x01(\tR\amodFile\"H\n" +
+	"\x0fSumPruneRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"H\n" +
+	"\x10SumPruneResponse\x124\n" +
+	"\aremoved\x18\x01 \x03(\v2\x1a.rhizome_atlas.v1.SumEntryR\aremoved\"u\n" +
+	"\x11SumMigrateRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x19\n" +
+	"\bdrop_old\x18\x03 \x01(\bR\adropOld\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"|\n" +
+	"\x12SumMigrateResponse\x120\n" +
+	"\x05added\x18\x01 \x03(\v2\x1a.rhizome_atlas.v1.SumEntryR\x05added\x124\n" +
+	"\aremoved\x18\x02 \x03(\v2\x1a.rhizome_atlas.v1.SumEntryR\aremoved\"U\n" +
+	"\x0fSumMergeRequest\x12\x12\n" +
+	"\x04ours\x18\x01 \x01(\tR\x04ours\x12\x16\n" +
+	"\x06theirs\x18\x02 \x01(\tR\x06theirs\x12\x16\n" +
+	"\x06output\x18\x03 \x01(\tR\x06output\"\x81\x01\n" +
+	"\x10SumMergeResponse\x12\x16\n" +
+	"\x06output\x18\x01 \x01(\tR\x06output\x12\x18\n" +
+	"\aentries\x18\x02 \x01(\x05R\aentries\x12;\n" +
+	"\tconflicts\x18\x03 \x03(\v2\x1d.rhizome_atlas.v1.SumConflictR\tconflicts\"g\n" +
+	"\vSumConflict\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x12\n" +
+	"\x04ours\x18\x03 \x01(\tR\x04ours\x12\x16\n" +
+	"\x06theirs\x18\x04 \x01(\tR\x06theirs\"i\n" +
+	"\x0fModMergeRequest\x12\x12\n" +
+	"\x04ours\x18\x01 \x01(\tR\x04ours\x12\x16\n" +
+	"\x06theirs\x18\x02 \x01(\tR\x06theirs\x12\x12\n" +
+	"\x04base\x18\x03 \x01(\tR\x04base\x12\x16\n" +
+	"\x06output\x18\x04 \x01(\tR\x06output\"F\n" +
+	"\x10ModMergeResponse\x12\x16\n" +
+	"\x06output\x18\x01 \x01(\tR\x06output\x12\x1a\n" +
+	"\bconflict\x18\x02 \x01(\tR\bconflict\"P\n" +
+	"\vUndoRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12#\n" +
+	"\rrestore_cache\x18\x02 \x01(\bR\frestoreCache\"t\n" +
+	"\fUndoResponse\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\tR\x04time\x128\n" +
+	"\brestored\x18\x03 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\brestored\"D\n" +
+	"\x0eHistoryRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"K\n" +
+	"\x0fHistoryResponse\x128\n" +
+	"\aentries\x18\x01 \x03(\v2\x1e.rhizome_atlas.v1.HistoryEntryR\aentries\"T\n" +
+	"\fHistoryEntry\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\tR\x04time\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12\x18\n" +
+	"\achanges\x18\x03 \x03(\tR\achanges\"\x9d\x01\n" +
 	"\n" +
 	"Dependency\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1d\n" +
 	"\n" +
-	"cache_path\x18\x03 \x01(\tR\tcachePath2\xbe\x05\n" +
+	"cache_path\x18\x03 \x01(\tR\tcachePath\x12\x14\n" +
+	"\x05alias\x18\x04 \x01(\tR\x05alias\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\x12\x14\n" +
+	"\x05group\x18\x06 \x01(\tR\x05group\"L\n" +
+	"\bSumEntry\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x12\n" +
+	"\x04hash\x18\x03 \x01(\tR\x04hash\"\x98\x01\n" +
+	"\x04Plan\x12\x18\n" +
+	"\achanges\x18\x01 \x03(\tR\achanges\x12\x14\n" +
+	"\x05write\x18\x02 \x03(\tR\x05write\x12\x16\n" +
+	"\x06delete\x18\x03 \x03(\tR\x06delete\x122\n" +
+	"\x05fetch\x18\x04 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\x05fetch\x12\x14\n" +
+	"\x05bytes\x18\x05 \x01(\x03R\x05bytes\"f\n" +
+	"\x0fPrefetchRequest\x12\"\n" +
+	"\fdependencies\x18\x01 \x03(\tR\fdependencies\x12\x1b\n" +
+	"\tholon_mod\x18\x02 \x01(\fR\bholonMod\x12\x12\n" +
+	"\x04wait\x18\x03 \x01(\bR\x04wait\"`\n" +
+	"\x10PrefetchResponse\x124\n" +
+	"\x06queued\x18\x01 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\x06queued\x12\x16\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\"\x97\x02\n" +
+	"\tOperation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12\x1c\n" +
+	"\tdirectory\x18\x03 \x01(\tR\tdirectory\x12\x12\n" +
+	"\x04done\x18\x04 \x01(\bR\x04done\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x05 \x01(\x05R\terrorCode\x12#\n" +
+	"\rerror_message\x18\x06 \x01(\tR\ferrorMessage\x122\n" +
+	"\x04pull\x18\a \x01(\v2\x1e.rhizome_atlas.v1.PullResponseR\x04pull\x128\n" +
+	"\x06update\x18\b \x01(\v2 .rhizome_atlas.v1.UpdateResponseR\x06update\"%\n" +
+	"\x13GetOperationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"(\n" +
+	"\x16CancelOperationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"e\n" +
+	"\x11MirrorSyncRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x14\n" +
+	"\x05paths\x18\x02 \x03(\tR\x05paths\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\x12\x16\n" +
+	"\x06latest\x18\x04 \x01(\x05R\x06latest\"e\n" +
+	"\x12MirrorSyncResponse\x127\n" +
+	"\x06holons\x18\x01 \x03(\v2\x1f.rhizome_atlas.v1.MirroredHolonR\x06holons\x12\x16\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\"U\n" +
+	"\rMirroredHolon\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1a\n" +
+	"\bversions\x18\x02 \x03(\tR\bversions\x12\x14\n" +
+	"\x05added\x18\x03 \x03(\tR\x05added\"0\n" +
+	"\x10ReproduceRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\"\x85\x01\n" +
+	"\x11ReproduceResponse\x12\"\n" +
+	"\freproducible\x18\x01 \x01(\bR\freproducible\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\tR\x04time\x128\n" +
+	"\aresults\x18\x03 \x03(\v2\x1e.rhizome_atlas.v1.ReproductionR\aresults\"\xef\x01\n" +
+	"\fReproduction\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x19\n" +
+	"\bsum_hash\x18\x04 \x01(\tR\asumHash\x12\x1d\n" +
+	"\n" +
+	"cache_hash\x18\x05 \x01(\tR\tcacheHash\x12#\n" +
+	"\rupstream_hash\x18\x06 \x01(\tR\fupstreamHash\x12\x1e\n" +
+	"\n" +
+	"reproduced\x18\a \x01(\bR\n" +
+	"reproduced\x12\x1a\n" +
+	"\bproblems\x18\b \x03(\tR\bproblems\"[\n" +
+	"\rImpactRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"\xbc\x02\n" +
+	"\x0eImpactResponse\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1f\n" +
+	"\vold_version\x18\x02 \x01(\tR\n" +
+	"oldVersion\x12\x1f\n" +
+	"\vnew_version\x18\x03 \x01(\tR\n" +
+	"newVersion\x12=\n" +
+	"\achanges\x18\x04 \x03(\v2#.rhizome_atlas.v1.RequirementChangeR\achanges\x12;\n" +
+	"\n" +
+	"selections\x18\x05 \x03(\v2\x1b.rhizome_atlas.v1.SelectionR\n" +
+	"selections\x128\n" +
+	"\tconflicts\x18\x06 \x03(\v2\x1a.rhizome_atlas.v1.ConflictR\tconflicts\x12\x1e\n" +
+	"\n" +
+	"unresolved\x18\a \x03(\tR\n" +
+	"unresolved\"\x7f\n" +
+	"\x11RequirementChange\x12\x14\n" +
+	"\x05holon\x18\x01 \x01(\tR\x05holon\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x1f\n" +
+	"\vold_version\x18\x03 \x01(\tR\n" +
+	"oldVersion\x12\x1f\n" +
+	"\vnew_version\x18\x04 \x01(\tR\n" +
+	"newVersion\"y\n" +
+	"\tSelection\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1f\n" +
+	"\vold_version\x18\x02 \x01(\tR\n" +
+	"oldVersion\x12\x1f\n" +
+	"\vnew_version\x18\x03 \x01(\tR\n" +
+	"newVersion\x12\x16\n" +
+	"\x06raised\x18\x04 \x03(\tR\x06raised\"W\n" +
+	"\bConflict\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x127\n" +
+	"\vrequired_by\x18\x02 \x03(\v2\x16.rhizome_atlas.v1.EdgeR\n" +
+	"requiredBy\"O\n" +
+	"\x10FreshnessRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x1d\n" +
+	"\n" +
+	"stale_days\x18\x02 \x01(\x05R\tstaleDays\"\x97\x01\n" +
+	"\x11FreshnessResponse\x12I\n" +
+	"\fdependencies\x18\x01 \x03(\v2%.rhizome_atlas.v1.DependencyFreshnessR\fdependencies\x12\x1d\n" +
+	"\n" +
+	"stale_days\x18\x02 \x01(\x05R\tstaleDays\x12\x18\n" +
+	"\aexpired\x18\x03 \x03(\tR\aexpired\"\xd6\x02\n" +
+	"\x13DependencyFreshness\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
+	"\x06latest\x18\x03 \x01(\tR\x06latest\x12'\n" +
+	"\x0fversions_behind\x18\x04 \x01(\x05R\x0eversionsBehind\x12!\n" +
+	"\fversion_time\x18\x05 \x01(\tR\vversionTime\x12\x1f\n" +
+	"\vlatest_time\x18\x06 \x01(\tR\n" +
+	"latestTime\x12\x1f\n" +
+	"\vdays_behind\x18\a \x01(\x05R\n" +
+	"daysBehind\x12\x14\n" +
+	"\x05stale\x18\b \x01(\bR\x05stale\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x12?\n" +
+	"\vdeprecation\x18\n" +
+	" \x01(\v2\x1d.rhizome_atlas.v1.DeprecationR\vdeprecation\"E\n" +
+	"\x10SubscribeRequest\x121\n" +
+	"\x05kinds\x18\x01 \x03(\x0e2\x1b.rhizome_atlas.v1.EventKindR\x05kinds\"\xad\x02\n" +
+	"\x05Event\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x04R\bsequence\x12/\n" +
+	"\x04kind\x18\x02 \x01(\x0e2\x1b.rhizome_atlas.v1.EventKindR\x04kind\x12\x12\n" +
+	"\x04time\x18\x03 \x01(\tR\x04time\x12\x1c\n" +
+	"\tdirectory\x18\x04 \x01(\tR\tdirectory\x12<\n" +
+	"\n" +
+	"dependency\x18\x05 \x01(\v2\x1c.rhizome_atlas.v1.DependencyR\n" +
+	"dependency\x12\x1f\n" +
+	"\vnew_version\x18\x06 \x01(\tR\n" +
+	"newVersion\x12\x16\n" +
+	"\x06errors\x18\a \x03(\tR\x06errors\x12\x18\n" +
+	"\aentries\x18\b \x01(\x05R\aentries\x12\x14\n" +
+	"\x05bytes\x18\t \x01(\x03R\x05bytes\"a\n" +
+	"\x0fDiagnoseRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x16\n" +
+	"\x06remote\x18\x03 \x01(\bR\x06remote\"R\n" +
+	"\x10DiagnoseResponse\x12>\n" +
+	"\vdiagnostics\x18\x01 \x03(\v2\x1c.rhizome_atlas.v1.DiagnosticR\vdiagnostics\"\xf4\x01\n" +
+	"\n" +
+	"Diagnostic\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\x05R\x04line\x12\x16\n" +
+	"\x06column\x18\x02 \x01(\x05R\x06column\x12\x1d\n" +
+	"\n" +
+	"end_column\x18\x03 \x01(\x05R\tendColumn\x12@\n" +
+	"\bseverity\x18\x04 \x01(\x0e2$.rhizome_atlas.v1.DiagnosticSeverityR\bseverity\x12\x12\n" +
+	"\x04code\x18\x05 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x06 \x01(\tR\amessage\x12+\n" +
+	"\x05fixes\x18\a \x03(\v2\x15.rhizome_atlas.v1.FixR\x05fixes\"M\n" +
+	"\x03Fix\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x120\n" +
+	"\x05edits\x18\x02 \x03(\v2\x1a.rhizome_atlas.v1.TextEditR\x05edits\"\x8b\x01\n" +
+	"\bTextEdit\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\x05R\x04line\x12\x16\n" +
+	"\x06column\x18\x02 \x01(\x05R\x06column\x12\x19\n" +
+	"\bend_line\x18\x03 \x01(\x05R\aendLine\x12\x1d\n" +
+	"\n" +
+	"end_column\x18\x04 \x01(\x05R\tendColumn\x12\x19\n" +
+	"\bnew_text\x18\x05 \x01(\tR\anewText\"@\n" +
+	"\n" +
+	"EnvRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\"f\n" +
+	"\vEnvResponse\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x12C\n" +
+	"\fdependencies\x18\x02 \x03(\v2\x1f.rhizome_atlas.v1.DependencyEnvR\fdependencies\"\x87\x01\n" +
+	"\rDependencyEnv\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1a\n" +
+	"\bvariable\x18\x03 \x01(\tR\bvariable\x12\x10\n" +
+	"\x03dir\x18\x04 \x01(\tR\x03dir\x12\x1a\n" +
+	"\blocation\x18\x05 \x01(\tR\blocation\"Z\n" +
+	"\x12ReplaceDiffRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
+	"\x04stat\x18\x03 \x01(\bR\x04stat\"\xc9\x01\n" +
+	"\x13ReplaceDiffResponse\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12\x1d\n" +
+	"\n" +
+	"cache_path\x18\x04 \x01(\tR\tcachePath\x12\x1f\n" +
+	"\vreplace_dir\x18\x05 \x01(\tR\n" +
+	"replaceDir\x120\n" +
+	"\x05files\x18\x06 \x03(\v2\x1a.rhizome_atlas.v1.FileDiffR\x05files\"\xb0\x01\n" +
+	"\bFileDiff\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x124\n" +
+	"\x06change\x18\x02 \x01(\x0e2\x1c.rhizome_atlas.v1.FileChangeR\x06change\x12\x16\n" +
+	"\x06binary\x18\x03 \x01(\bR\x06binary\x12\x14\n" +
+	"\x05added\x18\x04 \x01(\x05R\x05added\x12\x18\n" +
+	"\adeleted\x18\x05 \x01(\x05R\adeleted\x12\x12\n" +
+	"\x04diff\x18\x06 \x01(\tR\x04diff\"\xd5\x01\n" +
+	"\x15ReplacePromoteRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x12\x1b\n" +
+	"\tkeep_path\x18\x05 \x01(\bR\bkeepPath\x12\x14\n" +
+	"\x05trust\x18\x06 \x01(\bR\x05trust\x12\x14\n" +
+	"\x05force\x18\a \x01(\bR\x05force\x12\x17\n" +
+	"\adry_run\x18\b \x01(\bR\x06dryRun\"\x8f\x02\n" +
+	"\x16ReplacePromoteResponse\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x12<\n" +
+	"\n" +
+	"dependency\x18\x02 \x01(\v2\x1c.rhizome_atlas.v1.DependencyR\n" +
+	"dependency\x12\x18\n" +
+	"\areplace\x18\x03 \x01(\tR\areplace\x12\x1f\n" +
+	"\vreplace_dir\x18\x04 \x01(\tR\n" +
+	"replaceDir\x12<\n" +
+	"\vdifferences\x18\x05 \x03(\v2\x1a.rhizome_atlas.v1.FileDiffR\vdifferences\x12*\n" +
+	"\x04plan\x18\x06 \x01(\v2\x16.rhizome_atlas.v1.PlanR\x04plan\"i\n" +
+	"\vTidyRequest\x12\x1c\n" +
+	"\tdirectory\x18\x01 \x01(\tR\tdirectory\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\x12#\n" +
+	"\rkeep_requires\x18\x03 \x01(\bR\fkeepRequires\"\xc2\x01\n" +
+	"\fTidyResponse\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x126\n" +
+	"\adropped\x18\x02 \x03(\v2\x1c.rhizome_atlas.v1.DependencyR\adropped\x124\n" +
+	"\aremoved\x18\x03 \x03(\v2\x1a.rhizome_atlas.v1.SumEntryR\aremoved\x120\n" +
+	"\x05added\x18\x04 \x03(\v2\x1a.rhizome_atlas.v1.SumEntryR\x05added*\x88\x01\n" +
+	"\tPullStage\x12\x1a\n" +
+	"\x16PULL_STAGE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12PULL_STAGE_STARTED\x10\x01\x12\x1b\n" +
+	"\x17PULL_STAGE_TRANSFERRING\x10\x02\x12\x15\n" +
+	"\x11PULL_STAGE_HASHED\x10\x03\x12\x13\n" +
+	"\x0fPULL_STAGE_DONE\x10\x04*t\n" +
+	"\x0fUpdateBranching\x12 \n" +
+	"\x1cUPDATE_BRANCHING_UNSPECIFIED\x10\x00\x12#\n" +
+	"\x1fUPDATE_BRANCHING_PER_DEPENDENCY\x10\x01\x12\x1a\n" +
+	"\x16UPDATE_BRANCHING_BATCH\x10\x02*U\n" +
+	"\vReleaseBump\x12\x16\n" +
+	"\x12RELEASE_BUMP_PATCH\x10\x00\x12\x16\n" +
+	"\x12RELEASE_BUMP_MINOR\x10\x01\x12\x16\n" +
+	"\x12RELEASE_BUMP_MAJOR\x10\x02*\xc1\x01\n" +
+	"\tEventKind\x12\x1a\n" +
+	"\x16EVENT_KIND_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18EVENT_KIND_FETCH_STARTED\x10\x01\x12\x1d\n" +
+	"\x19EVENT_KIND_FETCH_FINISHED\x10\x02\x12\x1c\n" +
+	"\x18EVENT_KIND_VERIFY_FAILED\x10\x03\x12\x1f\n" +
+	"\x1bEVENT_KIND_UPDATE_AVAILABLE\x10\x04\x12\x1c\n" +
+	"\x18EVENT_KIND_CACHE_CLEANED\x10\x05*r\n" +
+	"\x12DiagnosticSeverity\x12\x1d\n" +
+	"\x19DIAGNOSTIC_SEVERITY_ERROR\x10\x00\x12\x1f\n" +
+	"\x1bDIAGNOSTIC_SEVERITY_WARNING\x10\x01\x12\x1c\n" +
+	"\x18DIAGNOSTIC_SEVERITY_INFO\x10\x02*s\n" +
+	"\n" +
+	"FileChange\x12\x1b\n" +
+	"\x17FILE_CHANGE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11FILE_CHANGE_ADDED\x10\x01\x12\x17\n" +
+	"\x13FILE_CHANGE_DELETED\x10\x02\x12\x18\n" +
+	"\x14FILE_CHANGE_MODIFIED\x10\x032\xd5\"\n" +
 	"\x13RhizomeAtlasService\x12E\n" +
 	"\x04Init\x12\x1d.rhizome_atlas.v1.InitRequest\x1a\x1e.rhizome_atlas.v1.InitResponse\x12B\n" +
-	"\x03Add\x12\x1c.rhizome_atlas.v1.AddRequest\x1a\x1d.rhizome_atlas.v1.AddResponse\x12K\n" +
+	"\x03New\x12\x1c.rhizome_atlas.v1.NewRequest\x1a\x1d.rhizome_atlas.v1.NewResponse\x12B\n" +
+	"\x03Add\x12\x1c.rhizome_atlas.v1.AddRequest\x1a\x1d.rhizome_atlas.v1.AddResponse\x12N\n" +
+	"\aResolve\x12 .rhizome_atlas.v1.ResolveRequest\x1a!.rhizome_atlas.v1.ResolveResponse\x12K\n" +
 	"\x06Remove\x12\x1f.rhizome_atlas.v1.RemoveRequest\x1a .rhizome_atlas.v1.RemoveResponse\x12E\n" +
-	"\x04Pull\x12\x1d.rhizome_atlas.v1.PullRequest\x1a\x1e.rhizome_atlas.v1.PullResponse\x12K\n" +
-	"\x06Verify\x12\x1f.rhizome_atlas.v1.VerifyRequest\x1a .rhizome_atlas.v1.VerifyResponse\x12H\n" +
-	"\x05Graph\x12\x1e.rhizome_atlas.v1.GraphRequest\x1a\x1f.rhizome_atlas.v1.GraphResponse\x12K\n" +
+	"\x04Pull\x12\x1d.rhizome_atlas.v1.PullRequest\x1a\x1e.rhizome_atlas.v1.PullResponse\x12M\n" +
+	"\n" +
+	"StreamPull\x12\x1d.rhizome_atlas.v1.PullRequest\x1a\x1e.rhizome_atlas.v1.PullProgress0\x01\x12E\n" +
+	"\x04Lock\x12\x1d.rhizome_atlas.v1.LockRequest\x1a\x1e.rhizome_atlas.v1.LockResponse\x12K\n" +
+	"\x06Verify\x12\x1f.rhizome_atlas.v1.VerifyRequest\x1a .rhizome_atlas.v1.VerifyResponse\x12T\n" +
+	"\tVerifyAll\x12\".rhizome_atlas.v1.VerifyAllRequest\x1a#.rhizome_atlas.v1.VerifyAllResponse\x12H\n" +
+	"\x05Graph\x12\x1e.rhizome_atlas.v1.GraphRequest\x1a\x1f.rhizome_atlas.v1.GraphResponse\x12S\n" +
+	"\vStreamGraph\x12$.rhizome_atlas.v1.StreamGraphRequest\x1a\x1c.rhizome_atlas.v1.GraphChunk0\x01\x12K\n" +
 	"\x06Update\x12\x1f.rhizome_atlas.v1.UpdateRequest\x1a .rhizome_atlas.v1.UpdateResponse\x12K\n" +
-	"\x06Vendor\x12\x1f.rhizome_atlas.v1.VendorRequest\x1a .rhizome_atlas.v1.VendorResponse\x12W\n" +
+	"\x06Vendor\x12\x1f.rhizome_atlas.v1.VendorRequest\x1a .rhizome_atlas.v1.VendorResponse\x12Y\n" +
+	"\fStreamVendor\x12%.rhizome_atlas.v1.StreamVendorRequest\x1a .rhizome_atlas.v1.VendorProgress0\x01\x12W\n" +
+	"\n" +
+	"CleanCache\x12#.rhizome_atlas.v1.CleanCacheRequest\x1a$.rhizome_atlas.v1.CleanCacheResponse\x12Q\n" +
+	"\bDescribe\x12!.rhizome_atlas.v1.DescribeRequest\x1a\".rhizome_atlas.v1.DescribeResponse\x12c\n" +
+	"\x0eFindCapability\x12'.rhizome_atlas.v1.FindCapabilityRequest\x1a(.rhizome_atlas.v1.FindCapabilityResponse\x12K\n" +
+	"\x06Owners\x12\x1f.rhizome_atlas.v1.OwnersRequest\x1a .rhizome_atlas.v1.OwnersResponse\x12N\n" +
+	"\aRelease\x12 .rhizome_atlas.v1.ReleaseRequest\x1a!.rhizome_atlas.v1.ReleaseResponse\x12]\n" +
+	"\fBundleCreate\x12%.rhizome_atlas.v1.BundleCreateRequest\x1a&.rhizome_atlas.v1.BundleCreateResponse\x12`\n" +
+	"\rBundleInstall\x12&.rhizome_atlas.v1.BundleInstallRequest\x1a'.rhizome_atlas.v1.BundleInstallResponse\x12Q\n" +
+	"\bSumPrune\x12!.rhizome_atlas.v1.SumPruneRequest\x1a\".rhizome_atlas.v1.SumPruneResponse\x12Q\n" +
+	"\bSumMerge\x12!.rhizome_atlas.v1.SumMergeRequest\x1a\".rhizome_atlas.v1.SumMergeResponse\x12W\n" +
+	"\n" +
+	"SumMigrate\x12#.rhizome_atlas.v1.SumMigrateRequest\x1a$.rhizome_atlas.v1.SumMigrateResponse\x12Q\n" +
+	"\bModMerge\x12!.rhizome_atlas.v1.ModMergeRequest\x1a\".rhizome_atlas.v1.ModMergeResponse\x12E\n" +
+	"\x04Undo\x12\x1d.rhizome_atlas.v1.UndoRequest\x1a\x1e.rhizome_atlas.v1.UndoResponse\x12N\n" +
+	"\aHistory\x12 .rhizome_atlas.v1.HistoryRequest\x1a!.rhizome_atlas.v1.HistoryResponse\x12T\n" +
+	"\tCacheList\x12\".rhizome_atlas.v1.CacheListRequest\x1a#.rhizome_atlas.v1.CacheListResponse\x12]\n" +
+	"\fCacheOrphans\x12%.rhizome_atlas.v1.CacheOrphansRequest\x1a&.rhizome_atlas.v1.CacheOrphansResponse\x12T\n" +
+	"\tCacheSeed\x12\".rhizome_atlas.v1.CacheSeedRequest\x1a#.rhizome_atlas.v1.CacheSeedResponse\x12T\n" +
+	"\tCachePush\x12\".rhizome_atlas.v1.CachePushRequest\x1a#.rhizome_atlas.v1.CachePushResponse\x12Q\n" +
+	"\bHasEntry\x12!.rhizome_atlas.v1.HasEntryRequest\x1a\".rhizome_atlas.v1.HasEntryResponse\x12V\n" +
+	"\n" +
+	"FetchEntry\x12#.rhizome_atlas.v1.FetchEntryRequest\x1a!.rhizome_atlas.v1.FetchEntryChunk0\x01\x12L\n" +
+	"\n" +
+	"GetLogHead\x12#.rhizome_atlas.v1.GetLogHeadRequest\x1a\x19.rhizome_atlas.v1.LogHead\x12l\n" +
+	"\x11ProveLogInclusion\x12*.rhizome_atlas.v1.ProveLogInclusionRequest\x1a+.rhizome_atlas.v1.ProveLogInclusionResponse\x12r\n" +
+	"\x13ProveLogConsistency\x12,.rhizome_atlas.v1.ProveLogConsistencyRequest\x1a-.rhizome_atlas.v1.ProveLogConsistencyResponse\x12Q\n" +
+	"\bPrefetch\x12!.rhizome_atlas.v1.PrefetchRequest\x1a\".rhizome_atlas.v1.PrefetchResponse\x12G\n" +
+	"\tStartPull\x12\x1d.rhizome_atlas.v1.PullRequest\x1a\x1b.rhizome_atlas.v1.Operation\x12K\n" +
+	"\vStartUpdate\x12\x1f.rhizome_atlas.v1.UpdateRequest\x1a\x1b.rhizome_atlas.v1.Operation\x12R\n" +
+	"\fGetOperation\x12%.rhizome_atlas.v1.GetOperationRequest\x1a\x1b.rhizome_atlas.v1.Operation\x12V\n" +
+	"\x0eWatchOperation\x12%.rhizome_atlas.v1.GetOperationRequest\x1a\x1b.rhizome_atlas.v1.Operation0\x01\x12X\n" +
+	"\x0fCancelOperation\x12(.rhizome_atlas.v1.CancelOperationRequest\x1a\x1b.rhizome_atlas.v1.Operation\x12W\n" +
 	"\n" +
-	"CleanCache\x12#.rhizome_atlas.v1.CleanCacheRequest\x1a$.rhizome_atlas.v1.CleanCacheResponseBUZSgithub.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1;rhizomeatlasv1b\x06proto3"
+	"MirrorSync\x12#.rhizome_atlas.v1.MirrorSyncRequest\x1a$.rhizome_atlas.v1.MirrorSyncResponse\x12T\n" +
+	"\tReproduce\x12\".rhizome_atlas.v1.ReproduceRequest\x1a#.rhizome_atlas.v1.ReproduceResponse\x12K\n" +
+	"\x06Impact\x12\x1f.rhizome_atlas.v1.ImpactRequest\x1a .rhizome_atlas.v1.ImpactResponse\x12T\n" +
+	"\tFreshness\x12\".rhizome_atlas.v1.FreshnessRequest\x1a#.rhizome_atlas.v1.FreshnessResponse\x12J\n" +
+	"\tSubscribe\x12\".rhizome_atlas.v1.SubscribeRequest\x1a\x17.rhizome_atlas.v1.Event0\x01\x12Q\n" +
+	"\bDiagnose\x12!.rhizome_atlas.v1.DiagnoseRequest\x1a\".rhizome_atlas.v1.DiagnoseResponse\x12B\n" +
+	"\x03Env\x12\x1c.rhizome_atlas.v1.EnvRequest\x1a\x1d.rhizome_atlas.v1.EnvResponse\x12Z\n" +
+	"\vReplaceDiff\x12$.rhizome_atlas.v1.ReplaceDiffRequest\x1a%.rhizome_atlas.v1.ReplaceDiffResponse\x12c\n" +
+	"\x0eReplacePromote\x12'.rhizome_atlas.v1.ReplacePromoteRequest\x1a(.rhizome_atlas.v1.ReplacePromoteResponse\x12E\n" +
+	"\x04Tidy\x12\x1d.rhizome_atlas.v1.TidyRequest\x1a\x1e.rhizome_atlas.v1.TidyResponseBUZSgithub.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1;rhizomeatlasv1b\x06proto3"
 
 var (
 	file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescOnce sync.Once
@@ -1137,59 +10602,362 @@ func file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescGZIP() []byte {
 	return file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDescData
 }
 
-var file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes = make([]protoimpl.MessageInfo, 139)
 var file_protos_rhizome_atlas_v1_rhizome_atlas_proto_goTypes = []any{
-	(*InitRequest)(nil),        // 0: rhizome_atlas.v1.InitRequest
-	(*InitResponse)(nil),       // 1: rhizome_atlas.v1.InitResponse
-	(*AddRequest)(nil),         // 2: rhizome_atlas.v1.AddRequest
-	(*AddResponse)(nil),        // 3: rhizome_atlas.v1.AddResponse
-	(*RemoveRequest)(nil),      // 4: rhizome_atlas.v1.RemoveRequest
-	(*RemoveResponse)(nil),     // 5: rhizome_atlas.v1.RemoveResponse
-	(*PullRequest)(nil),        // 6: rhizome_atlas.v1.PullRequest
-	(*PullResponse)(nil),       // 7: rhizome_atlas.v1.PullResponse
-	(*VerifyRequest)(nil),      // 8: rhizome_atlas.v1.VerifyRequest
-	(*VerifyResponse)(nil),     // 9: rhizome_atlas.v1.VerifyResponse
-	(*GraphRequest)(nil),       // 10: rhizome_atlas.v1.GraphRequest
-	(*GraphResponse)(nil),      // 11: rhizome_atlas.v1.GraphResponse
-	(*Edge)(nil),               // 12: rhizome_atlas.v1.Edge
-	(*UpdateRequest)(nil),      // 13: rhizome_atlas.v1.UpdateRequest
-	(*UpdateResponse)(nil),     // 14: rhizome_atlas.v1.UpdateResponse
-	(*UpdatedDependency)(nil),  // 15: rhizome_atlas.v1.UpdatedDependency
-	(*VendorRequest)(nil),      // 16: rhizome_atlas.v1.VendorRequest
-	(*VendorResponse)(nil),     // 17: rhizome_atlas.v1.VendorResponse
-	(*CleanCacheRequest)(nil),  // 18: rhizome_atlas.v1.CleanCacheRequest
-	(*CleanCacheResponse)(nil), // 19: rhizome_atlas.v1.CleanCacheResponse
-	(*Dependency)(nil),         // 20: rhizome_atlas.v1.Dependency
+	(PullStage)(0),                      // 0: rhizome_atlas.v1.PullStage
+	(UpdateBranching)(0),                // 1: rhizome_atlas.v1.UpdateBranching
+	(ReleaseBump)(0),                    // 2: rhizome_atlas.v1.ReleaseBump
+	(EventKind)(0),                      // 3: rhizome_atlas.v1.EventKind
+	(DiagnosticSeverity)(0),             // 4: rhizome_atlas.v1.DiagnosticSeverity
+	(FileChange)(0),                     // 5: rhizome_atlas.v1.FileChange
+	(*InitRequest)(nil),                 // 6: rhizome_atlas.v1.InitRequest
+	(*InitResponse)(nil),                // 7: rhizome_atlas.v1.InitResponse
+	(*NewRequest)(nil),                  // 8: rhizome_atlas.v1.NewRequest
+	(*NewResponse)(nil),                 // 9: rhizome_atlas.v1.NewResponse
+	(*AddRequest)(nil),                  // 10: rhizome_atlas.v1.AddRequest
+	(*AddResponse)(nil),                 // 11: rhizome_atlas.v1.AddResponse
+	(*TrustRecord)(nil),                 // 12: rhizome_atlas.v1.TrustRecord
+	(*ResolveRequest)(nil),              // 13: rhizome_atlas.v1.ResolveRequest
+	(*ResolveResponse)(nil),             // 14: rhizome_atlas.v1.ResolveResponse
+	(*RemoveRequest)(nil),               // 15: rhizome_atlas.v1.RemoveRequest
+	(*RemoveResponse)(nil),              // 16: rhizome_atlas.v1.RemoveResponse
+	(*PullRequest)(nil),                 // 17: rhizome_atlas.v1.PullRequest
+	(*PullResponse)(nil),                // 18: rhizome_atlas.v1.PullResponse
+	(*LockRequest)(nil),                 // 19: rhizome_atlas.v1.LockRequest
+	(*LockResponse)(nil),                // 20: rhizome_atlas.v1.LockResponse
+	(*PullProgress)(nil),                // 21: rhizome_atlas.v1.PullProgress
+	(*Deprecation)(nil),                 // 22: rhizome_atlas.v1.Deprecation
+	(*BudgetExceeded)(nil),              // 23: rhizome_atlas.v1.BudgetExceeded
+	(*Resolution)(nil),                  // 24: rhizome_atlas.v1.Resolution
+	(*Requirement)(nil),                 // 25: rhizome_atlas.v1.Requirement
+	(*VerifyRequest)(nil),               // 26: rhizome_atlas.v1.VerifyRequest
+	(*VerifyResponse)(nil),              // 27: rhizome_atlas.v1.VerifyResponse
+	(*VerifyFinding)(nil),               // 28: rhizome_atlas.v1.VerifyFinding
+	(*VerifyAllRequest)(nil),            // 29: rhizome_atlas.v1.VerifyAllRequest
+	(*VerifyAllResponse)(nil),           // 30: rhizome_atlas.v1.VerifyAllResponse
+	(*HolonVerification)(nil),           // 31: rhizome_atlas.v1.HolonVerification
+	(*GraphRequest)(nil),                // 32: rhizome_atlas.v1.GraphRequest
+	(*GraphResponse)(nil),               // 33: rhizome_atlas.v1.GraphResponse
+	(*Node)(nil),                        // 34: rhizome_atlas.v1.Node
+	(*Edge)(nil),                        // 35: rhizome_atlas.v1.Edge
+	(*StreamGraphRequest)(nil),          // 36: rhizome_atlas.v1.StreamGraphRequest
+	(*GraphChunk)(nil),                  // 37: rhizome_atlas.v1.GraphChunk
+	(*GraphSummary)(nil),                // 38: rhizome_atlas.v1.GraphSummary
+	(*UpdateRequest)(nil),               // 39: rhizome_atlas.v1.UpdateRequest
+	(*UpdateResponse)(nil),              // 40: rhizome_atlas.v1.UpdateResponse
+	(*UpdateBranch)(nil),                // 41: rhizome_atlas.v1.UpdateBranch
+	(*MovedTag)(nil),                    // 42: rhizome_atlas.v1.MovedTag
+	(*UpdatedDependency)(nil),           // 43: rhizome_atlas.v1.UpdatedDependency
+	(*VendorRequest)(nil),               // 44: rhizome_atlas.v1.VendorRequest
+	(*VendorResponse)(nil),              // 45: rhizome_atlas.v1.VendorResponse
+	(*StreamVendorRequest)(nil),         // 46: rhizome_atlas.v1.StreamVendorRequest
+	(*VendorProgress)(nil),              // 47: rhizome_atlas.v1.VendorProgress
+	(*VendorSummary)(nil),               // 48: rhizome_atlas.v1.VendorSummary
+	(*CleanCacheRequest)(nil),           // 49: rhizome_atlas.v1.CleanCacheRequest
+	(*CleanCacheResponse)(nil),          // 50: rhizome_atlas.v1.CleanCacheResponse
+	(*CacheListRequest)(nil),            // 51: rhizome_atlas.v1.CacheListRequest
+	(*CacheListResponse)(nil),           // 52: rhizome_atlas.v1.CacheListResponse
+	(*CacheEntry)(nil),                  // 53: rhizome_atlas.v1.CacheEntry
+	(*FetchInfo)(nil),                   // 54: rhizome_atlas.v1.FetchInfo
+	(*CacheOrphansRequest)(nil),         // 55: rhizome_atlas.v1.CacheOrphansRequest
+	(*CacheOrphansResponse)(nil),        // 56: rhizome_atlas.v1.CacheOrphansResponse
+	(*CacheSeedRequest)(nil),            // 57: rhizome_atlas.v1.CacheSeedRequest
+	(*CacheSeedResponse)(nil),           // 58: rhizome_atlas.v1.CacheSeedResponse
+	(*CachePushRequest)(nil),            // 59: rhizome_atlas.v1.CachePushRequest
+	(*CachePushResponse)(nil),           // 60: rhizome_atlas.v1.CachePushResponse
+	(*HasEntryRequest)(nil),             // 61: rhizome_atlas.v1.HasEntryRequest
+	(*HasEntryResponse)(nil),            // 62: rhizome_atlas.v1.HasEntryResponse
+	(*FetchEntryRequest)(nil),           // 63: rhizome_atlas.v1.FetchEntryRequest
+	(*FetchEntryChunk)(nil),             // 64: rhizome_atlas.v1.FetchEntryChunk
+	(*GetLogHeadRequest)(nil),           // 65: rhizome_atlas.v1.GetLogHeadRequest
+	(*LogHead)(nil),                     // 66: rhizome_atlas.v1.LogHead
+	(*ProveLogInclusionRequest)(nil),    // 67: rhizome_atlas.v1.ProveLogInclusionRequest
+	(*ProveLogInclusionResponse)(nil),   // 68: rhizome_atlas.v1.ProveLogInclusionResponse
+	(*LogRecord)(nil),                   // 69: rhizome_atlas.v1.LogRecord
+	(*ProveLogConsistencyRequest)(nil),  // 70: rhizome_atlas.v1.ProveLogConsistencyRequest
+	(*ProveLogConsistencyResponse)(nil), // 71: rhizome_atlas.v1.ProveLogConsistencyResponse
+	(*DescribeRequest)(nil),             // 72: rhizome_atlas.v1.DescribeRequest
+	(*DescribeResponse)(nil),            // 73: rhizome_atlas.v1.DescribeResponse
+	(*HolonDescription)(nil),            // 74: rhizome_atlas.v1.HolonDescription
+	(*Provenance)(nil),                  // 75: rhizome_atlas.v1.Provenance
+	(*FindCapabilityRequest)(nil),       // 76: rhizome_atlas.v1.FindCapabilityRequest
+	(*FindCapabilityResponse)(nil),      // 77: rhizome_atlas.v1.FindCapabilityResponse
+	(*OwnersRequest)(nil),               // 78: rhizome_atlas.v1.OwnersRequest
+	(*OwnersResponse)(nil),              // 79: rhizome_atlas.v1.OwnersResponse
+	(*HolonOwners)(nil),                 // 80: rhizome_atlas.v1.HolonOwners
+	(*ReleaseRequest)(nil),              // 81: rhizome_atlas.v1.ReleaseRequest
+	(*ReleaseResponse)(nil),             // 82: rhizome_atlas.v1.ReleaseResponse
+	(*BundleCreateRequest)(nil),         // 83: rhizome_atlas.v1.BundleCreateRequest
+	(*BundleCreateResponse)(nil),        // 84: rhizome_atlas.v1.BundleCreateResponse
+	(*BundleInstallRequest)(nil),        // 85: rhizome_atlas.v1.BundleInstallRequest
+	(*BundleInstallResponse)(nil),       // 86: rhizome_atlas.v1.BundleInstallResponse
+	(*SumPruneRequest)(nil),             // 87: rhizome_atlas.v1.SumPruneRequest
+	(*SumPruneResponse)(nil),            // 88: rhizome_atlas.v1.SumPruneResponse
+	(*SumMigrateRequest)(nil),           // 89: rhizome_atlas.v1.SumMigrateRequest
+	(*SumMigrateResponse)(nil),          // 90: rhizome_atlas.v1.SumMigrateResponse
+	(*SumMergeRequest)(nil),             // 91: rhizome_atlas.v1.SumMergeRequest
+	(*SumMergeResponse)(nil),            // 92: rhizome_atlas.v1.SumMergeResponse
+	(*SumConflict)(nil),                 // 93: rhizome_atlas.v1.SumConflict
+	(*ModMergeRequest)(nil),             // 94: rhizome_atlas.v1.ModMergeRequest
+	(*ModMergeResponse)(nil),            // 95: rhizome_atlas.v1.ModMergeResponse
+	(*UndoRequest)(nil),                 // 96: rhizome_atlas.v1.UndoRequest
+	(*UndoResponse)(nil),                // 97: rhizome_atlas.v1.UndoResponse
+	(*HistoryRequest)(nil),              // 98: rhizome_atlas.v1.HistoryRequest
+	(*HistoryResponse)(nil),             // 99: rhizome_atlas.v1.HistoryResponse
+	(*HistoryEntry)(nil),                // 100: rhizome_atlas.v1.HistoryEntry
+	(*Dependency)(nil),                  // 101: rhizome_atlas.v1.Dependency
+	(*SumEntry)(nil),                    // 102: rhizome_atlas.v1.SumEntry
+	(*Plan)(nil),                        // 103: rhizome_atlas.v1.Plan
+	(*PrefetchRequest)(nil),             // 104: rhizome_atlas.v1.PrefetchRequest
+	(*PrefetchResponse)(nil),            // 105: rhizome_atlas.v1.PrefetchResponse
+	(*Operation)(nil),                   // 106: rhizome_atlas.v1.Operation
+	(*GetOperationRequest)(nil),         // 107: rhizome_atlas.v1.GetOperationRequest
+	(*CancelOperationRequest)(nil),      // 108: rhizome_atlas.v1.CancelOperationRequest
+	(*MirrorSyncRequest)(nil),           // 109: rhizome_atlas.v1.MirrorSyncRequest
+	(*MirrorSyncResponse)(nil),          // 110: rhizome_atlas.v1.MirrorSyncResponse
+	(*MirroredHolon)(nil),               // 111: rhizome_atlas.v1.MirroredHolon
+	(*ReproduceRequest)(nil),            // 112: rhizome_atlas.v1.ReproduceRequest
+	(*ReproduceResponse)(nil),           // 113: rhizome_atlas.v1.ReproduceResponse
+	(*Reproduction)(nil),                // 114: rhizome_atlas.v1.Reproduction
+	(*ImpactRequest)(nil),               // 115: rhizome_atlas.v1.ImpactRequest
+	(*ImpactResponse)(nil),              // 116: rhizome_atlas.v1.ImpactResponse
+	(*RequirementChange)(nil),           // 117: rhizome_atlas.v1.RequirementChange
+	(*Selection)(nil),                   // 118: rhizome_atlas.v1.Selection
+	(*Conflict)(nil),                    // 119: rhizome_atlas.v1.Conflict
+	(*FreshnessRequest)(nil),            // 120: rhizome_atlas.v1.FreshnessRequest
+	(*FreshnessResponse)(nil),           // 121: rhizome_atlas.v1.FreshnessResponse
+	(*DependencyFreshness)(nil),         // 122: rhizome_atlas.v1.DependencyFreshness
+	(*SubscribeRequest)(nil),            // 123: rhizome_atlas.v1.SubscribeRequest
+	(*Event)(nil),                       // 124: rhizome_atlas.v1.Event
+	(*DiagnoseRequest)(nil),             // 125: rhizome_atlas.v1.DiagnoseRequest
+	(*DiagnoseResponse)(nil),            // 126: rhizome_atlas.v1.DiagnoseResponse
+	(*Diagnostic)(nil),                  // 127: rhizome_atlas.v1.Diagnostic
+	(*Fix)(nil),                         // 128: rhizome_atlas.v1.Fix
+	(*TextEdit)(nil),                    // 129: rhizome_atlas.v1.TextEdit
+	(*EnvRequest)(nil),                  // 130: rhizome_atlas.v1.EnvRequest
+	(*EnvResponse)(nil),                 // 131: rhizome_atlas.v1.EnvResponse
+	(*DependencyEnv)(nil),               // 132: rhizome_atlas.v1.DependencyEnv
+	(*ReplaceDiffRequest)(nil),          // 133: rhizome_atlas.v1.ReplaceDiffRequest
+	(*ReplaceDiffResponse)(nil),         // 134: rhizome_atlas.v1.ReplaceDiffResponse
+	(*FileDiff)(nil),                    // 135: rhizome_atlas.v1.FileDiff
+	(*ReplacePromoteRequest)(nil),       // 136: rhizome_atlas.v1.ReplacePromoteRequest
+	(*ReplacePromoteResponse)(nil),      // 137: rhizome_atlas.v1.ReplacePromoteResponse
+	(*TidyRequest)(nil),                 // 138: rhizome_atlas.v1.TidyRequest
+	(*TidyResponse)(nil),                // 139: rhizome_atlas.v1.TidyResponse
+	nil,                                 // 140: rhizome_atlas.v1.NewRequest.VarsEntry
+	nil,                                 // 141: rhizome_atlas.v1.GraphRequest.FilterEntry
+	nil,                                 // 142: rhizome_atlas.v1.Edge.MetadataEntry
+	nil,                                 // 143: rhizome_atlas.v1.StreamGraphRequest.FilterEntry
+	nil,                                 // 144: rhizome_atlas.v1.CachePushResponse.AnnotationsEntry
 }
 var file_protos_rhizome_atlas_v1_rhizome_atlas_proto_depIdxs = []int32{
-	20, // 0: rhizome_atlas.v1.AddResponse.dependency:type_name -> rhizome_atlas.v1.Dependency
-	20, // 1: rhizome_atlas.v1.PullResponse.fetched:type_name -> rhizome_atlas.v1.Dependency
-	12, // 2: rhizome_atlas.v1.GraphResponse.edges:type_name -> rhizome_atlas.v1.Edge
-	15, // 3: rhizome_atlas.v1.UpdateResponse.updated:type_name -> rhizome_atlas.v1.UpdatedDependency
-	20, // 4: rhizome_atlas.v1.VendorResponse.vendored:type_name -> rhizome_atlas.v1.Dependency
-	0,  // 5: rhizome_atlas.v1.RhizomeAtlasService.Init:input_type -> rhizome_atlas.v1.InitRequest
-	2,  // 6: rhizome_atlas.v1.RhizomeAtlasService.Add:input_type -> rhizome_atlas.v1.AddRequest
-	4,  // 7: rhizome_atlas.v1.RhizomeAtlasService.Remove:input_type -> rhizome_atlas.v1.RemoveRequest
-	6,  // 8: rhizome_atlas.v1.RhizomeAtlasService.Pull:input_type -> rhizome_atlas.v1.PullRequest
-	8,  // 9: rhizome_atlas.v1.RhizomeAtlasService.Verify:input_type -> rhizome_atlas.v1.VerifyRequest
-	10, // 10: rhizome_atlas.v1.RhizomeAtlasService.Graph:input_type -> rhizome_atlas.v1.GraphRequest
-	13, // 11: rhizome_atlas.v1.RhizomeAtlasService.Update:input_type -> rhizome_atlas.v1.UpdateRequest
-	16, // 12: rhizome_atlas.v1.RhizomeAtlasService.Vendor:input_type -> rhizome_atlas.v1.VendorRequest
-	18, // 13: rhizome_atlas.v1.RhizomeAtlasService.CleanCache:input_type -> rhizome_atlas.v1.CleanCacheRequest
-	1,  // 14: rhizome_atlas.v1.RhizomeAtlasService.Init:output_type -> rhizome_atlas.v1.InitResponse
-	3,  // 15: rhizome_atlas.v1.RhizomeAtlasService.Add:output_type -> rhizome_atlas.v1.AddResponse
-	5,  // 16: rhizome_atlas.v1.RhizomeAtlasService.Remove:output_type -> rhizome_atlas.v1.RemoveResponse
-	7,  // 17: rhizome_atlas.v1.RhizomeAtlasService.Pull:output_type -> rhizome_atlas.v1.PullResponse
-	9,  // 18: rhizome_atlas.v1.RhizomeAtlasService.Verify:output_type -> rhizome_atlas.v1.VerifyResponse
-	11, // 19: rhizome_atlas.v1.RhizomeAtlasService.Graph:output_type -> rhizome_atlas.v1.GraphResponse
-	14, // 20: rhizome_atlas.v1.RhizomeAtlasService.Update:output_type -> rhizome_atlas.v1.UpdateResponse
-	17, // 21: rhizome_atlas.v1.RhizomeAtlasService.Vendor:output_type -> rhizome_atlas.v1.VendorResponse
-	19, // 22: rhizome_atlas.v1.RhizomeAtlasService.CleanCache:output_type -> rhizome_atlas.v1.CleanCacheResponse
-	14, // [14:23] is the sub-list for method output_type
-	5,  // [5:14] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	101, // 0: rhizome_atlas.v1.InitResponse.inferred:type_name -> rhizome_atlas.v1.Dependency
+	140, // 1: rhizome_atlas.v1.NewRequest.vars:type_name -> rhizome_atlas.v1.NewRequest.VarsEntry
+	101, // 2: rhizome_atlas.v1.AddResponse.dependency:type_name -> rhizome_atlas.v1.Dependency
+	103, // 3: rhizome_atlas.v1.AddResponse.plan:type_name -> rhizome_atlas.v1.Plan
+	12,  // 4: rhizome_atlas.v1.AddResponse.trusted:type_name -> rhizome_atlas.v1.TrustRecord
+	103, // 5: rhizome_atlas.v1.RemoveResponse.plan:type_name -> rhizome_atlas.v1.Plan
+	101, // 6: rhizome_atlas.v1.PullResponse.fetched:type_name -> rhizome_atlas.v1.Dependency
+	24,  // 7: rhizome_atlas.v1.PullResponse.resolved:type_name -> rhizome_atlas.v1.Resolution
+	101, // 8: rhizome_atlas.v1.PullResponse.skipped:type_name -> rhizome_atlas.v1.Dependency
+	101, // 9: rhizome_atlas.v1.PullResponse.repaired:type_name -> rhizome_atlas.v1.Dependency
+	23,  // 10: rhizome_atlas.v1.PullResponse.over_budget:type_name -> rhizome_atlas.v1.BudgetExceeded
+	22,  // 11: rhizome_atlas.v1.PullResponse.deprecated:type_name -> rhizome_atlas.v1.Deprecation
+	101, // 12: rhizome_atlas.v1.PullResponse.trusted:type_name -> rhizome_atlas.v1.Dependency
+	24,  // 13: rhizome_atlas.v1.LockResponse.resolved:type_name -> rhizome_atlas.v1.Resolution
+	0,   // 14: rhizome_atlas.v1.PullProgress.stage:type_name -> rhizome_atlas.v1.PullStage
+	101, // 15: rhizome_atlas.v1.PullProgress.dependency:type_name -> rhizome_atlas.v1.Dependency
+	18,  // 16: rhizome_atlas.v1.PullProgress.result:type_name -> rhizome_atlas.v1.PullResponse
+	25,  // 17: rhizome_atlas.v1.Resolution.required_by:type_name -> rhizome_atlas.v1.Requirement
+	101, // 18: rhizome_atlas.v1.VerifyResponse.repaired:type_name -> rhizome_atlas.v1.Dependency
+	28,  // 19: rhizome_atlas.v1.VerifyResponse.findings:type_name -> rhizome_atlas.v1.VerifyFinding
+	4,   // 20: rhizome_atlas.v1.VerifyFinding.severity:type_name -> rhizome_atlas.v1.DiagnosticSeverity
+	31,  // 21: rhizome_atlas.v1.VerifyAllResponse.results:type_name -> rhizome_atlas.v1.HolonVerification
+	141, // 22: rhizome_atlas.v1.GraphRequest.filter:type_name -> rhizome_atlas.v1.GraphRequest.FilterEntry
+	35,  // 23: rhizome_atlas.v1.GraphResponse.edges:type_name -> rhizome_atlas.v1.Edge
+	34,  // 24: rhizome_atlas.v1.GraphResponse.nodes:type_name -> rhizome_atlas.v1.Node
+	142, // 25: rhizome_atlas.v1.Edge.metadata:type_name -> rhizome_atlas.v1.Edge.MetadataEntry
+	143, // 26: rhizome_atlas.v1.StreamGraphRequest.filter:type_name -> rhizome_atlas.v1.StreamGraphRequest.FilterEntry
+	35,  // 27: rhizome_atlas.v1.GraphChunk.edges:type_name -> rhizome_atlas.v1.Edge
+	38,  // 28: rhizome_atlas.v1.GraphChunk.summary:type_name -> rhizome_atlas.v1.GraphSummary
+	34,  // 29: rhizome_atlas.v1.GraphChunk.nodes:type_name -> rhizome_atlas.v1.Node
+	1,   // 30: rhizome_atlas.v1.UpdateRequest.branching:type_name -> rhizome_atlas.v1.UpdateBranching
+	43,  // 31: rhizome_atlas.v1.UpdateResponse.updated:type_name -> rhizome_atlas.v1.UpdatedDependency
+	43,  // 32: rhizome_atlas.v1.UpdateResponse.held:type_name -> rhizome_atlas.v1.UpdatedDependency
+	103, // 33: rhizome_atlas.v1.UpdateResponse.plan:type_name -> rhizome_atlas.v1.Plan
+	42,  // 34: rhizome_atlas.v1.UpdateResponse.moved:type_name -> rhizome_atlas.v1.MovedTag
+	41,  // 35: rhizome_atlas.v1.UpdateResponse.branches:type_name -> rhizome_atlas.v1.UpdateBranch
+	43,  // 36: rhizome_atlas.v1.UpdateBranch.updated:type_name -> rhizome_atlas.v1.UpdatedDependency
+	101, // 37: rhizome_atlas.v1.VendorResponse.vendored:type_name -> rhizome_atlas.v1.Dependency
+	103, // 38: rhizome_atlas.v1.VendorResponse.plan:type_name -> rhizome_atlas.v1.Plan
+	23,  // 39: rhizome_atlas.v1.VendorResponse.over_budget:type_name -> rhizome_atlas.v1.BudgetExceeded
+	101, // 40: rhizome_atlas.v1.VendorProgress.dependency:type_name -> rhizome_atlas.v1.Dependency
+	48,  // 41: rhizome_atlas.v1.VendorProgress.summary:type_name -> rhizome_atlas.v1.VendorSummary
+	23,  // 42: rhizome_atlas.v1.VendorSummary.over_budget:type_name -> rhizome_atlas.v1.BudgetExceeded
+	103, // 43: rhizome_atlas.v1.CleanCacheResponse.plan:type_name -> rhizome_atlas.v1.Plan
+	53,  // 44: rhizome_atlas.v1.CacheListResponse.entries:type_name -> rhizome_atlas.v1.CacheEntry
+	54,  // 45: rhizome_atlas.v1.CacheEntry.fetch:type_name -> rhizome_atlas.v1.FetchInfo
+	53,  // 46: rhizome_atlas.v1.CacheOrphansResponse.orphans:type_name -> rhizome_atlas.v1.CacheEntry
+	101, // 47: rhizome_atlas.v1.CacheSeedResponse.seeded:type_name -> rhizome_atlas.v1.Dependency
+	101, // 48: rhizome_atlas.v1.CacheSeedResponse.present:type_name -> rhizome_atlas.v1.Dependency
+	103, // 49: rhizome_atlas.v1.CacheSeedResponse.plan:type_name -> rhizome_atlas.v1.Plan
+	144, // 50: rhizome_atlas.v1.CachePushResponse.annotations:type_name -> rhizome_atlas.v1.CachePushResponse.AnnotationsEntry
+	69,  // 51: rhizome_atlas.v1.ProveLogInclusionResponse.records:type_name -> rhizome_atlas.v1.LogRecord
+	66,  // 52: rhizome_atlas.v1.ProveLogConsistencyResponse.head:type_name -> rhizome_atlas.v1.LogHead
+	74,  // 53: rhizome_atlas.v1.DescribeResponse.holon:type_name -> rhizome_atlas.v1.HolonDescription
+	75,  // 54: rhizome_atlas.v1.HolonDescription.provenance:type_name -> rhizome_atlas.v1.Provenance
+	54,  // 55: rhizome_atlas.v1.HolonDescription.fetch:type_name -> rhizome_atlas.v1.FetchInfo
+	101, // 56: rhizome_atlas.v1.FindCapabilityResponse.providers:type_name -> rhizome_atlas.v1.Dependency
+	80,  // 57: rhizome_atlas.v1.OwnersResponse.holons:type_name -> rhizome_atlas.v1.HolonOwners
+	2,   // 58: rhizome_atlas.v1.ReleaseRequest.bump:type_name -> rhizome_atlas.v1.ReleaseBump
+	101, // 59: rhizome_atlas.v1.BundleCreateResponse.dependencies:type_name -> rhizome_atlas.v1.Dependency
+	101, // 60: rhizome_atlas.v1.BundleInstallResponse.installed:type_name -> rhizome_atlas.v1.Dependency
+	102, // 61: rhizome_atlas.v1.SumPruneResponse.removed:type_name -> rhizome_atlas.v1.SumEntry
+	102, // 62: rhizome_atlas.v1.SumMigrateResponse.added:type_name -> rhizome_atlas.v1.SumEntry
+	102, // 63: rhizome_atlas.v1.SumMigrateResponse.removed:type_name -> rhizome_atlas.v1.SumEntry
+	93,  // 64: rhizome_atlas.v1.SumMergeResponse.conflicts:type_name -> rhizome_atlas.v1.SumConflict
+	101, // 65: rhizome_atlas.v1.UndoResponse.restored:type_name -> rhizome_atlas.v1.Dependency
+	100, // 66: rhizome_atlas.v1.HistoryResponse.entries:type_name -> rhizome_atlas.v1.HistoryEntry
+	101, // 67: rhizome_atlas.v1.Plan.fetch:type_name -> rhizome_atlas.v1.Dependency
+	101, // 68: rhizome_atlas.v1.PrefetchResponse.queued:type_name -> rhizome_atlas.v1.Dependency
+	18,  // 69: rhizome_atlas.v1.Operation.pull:type_name -> rhizome_atlas.v1.PullResponse
+	40,  // 70: rhizome_atlas.v1.Operation.update:type_name -> rhizome_atlas.v1.UpdateResponse
+	111, // 71: rhizome_atlas.v1.MirrorSyncResponse.holons:type_name -> rhizome_atlas.v1.MirroredHolon
+	114, // 72: rhizome_atlas.v1.ReproduceResponse.results:type_name -> rhizome_atlas.v1.Reproduction
+	117, // 73: rhizome_atlas.v1.ImpactResponse.changes:type_name -> rhizome_atlas.v1.RequirementChange
+	118, // 74: rhizome_atlas.v1.ImpactResponse.selections:type_name -> rhizome_atlas.v1.Selection
+	119, // 75: rhizome_atlas.v1.ImpactResponse.conflicts:type_name -> rhizome_atlas.v1.Conflict
+	35,  // 76: rhizome_atlas.v1.Conflict.required_by:type_name -> rhizome_atlas.v1.Edge
+	122, // 77: rhizome_atlas.v1.FreshnessResponse.dependencies:type_name -> rhizome_atlas.v1.DependencyFreshness
+	22,  // 78: rhizome_atlas.v1.DependencyFreshness.deprecation:type_name -> rhizome_atlas.v1.Deprecation
+	3,   // 79: rhizome_atlas.v1.SubscribeRequest.kinds:type_name -> rhizome_atlas.v1.EventKind
+	3,   // 80: rhizome_atlas.v1.Event.kind:type_name -> rhizome_atlas.v1.EventKind
+	101, // 81: rhizome_atlas.v1.Event.dependency:type_name -> rhizome_atlas.v1.Dependency
+	127, // 82: rhizome_atlas.v1.DiagnoseResponse.diagnostics:type_name -> rhizome_atlas.v1.Diagnostic
+	4,   // 83: rhizome_atlas.v1.Diagnostic.severity:type_name -> rhizome_atlas.v1.DiagnosticSeverity
+	128, // 84: rhizome_atlas.v1.Diagnostic.fixes:type_name -> rhizome_atlas.v1.Fix
+	129, // 85: rhizome_atlas.v1.Fix.edits:type_name -> rhizome_atlas.v1.TextEdit
+	132, // 86: rhizome_atlas.v1.EnvResponse.dependencies:type_name -> rhizome_atlas.v1.DependencyEnv
+	135, // 87: rhizome_atlas.v1.ReplaceDiffResponse.files:type_name -> rhizome_atlas.v1.FileDiff
+	5,   // 88: rhizome_atlas.v1.FileDiff.change:type_name -> rhizome_atlas.v1.FileChange
+	101, // 89: rhizome_atlas.v1.ReplacePromoteResponse.dependency:type_name -> rhizome_atlas.v1.Dependency
+	135, // 90: rhizome_atlas.v1.ReplacePromoteResponse.differences:type_name -> rhizome_atlas.v1.FileDiff
+	103, // 91: rhizome_atlas.v1.ReplacePromoteResponse.plan:type_name -> rhizome_atlas.v1.Plan
+	101, // 92: rhizome_atlas.v1.TidyResponse.dropped:type_name -> rhizome_atlas.v1.Dependency
+	102, // 93: rhizome_atlas.v1.TidyResponse.removed:type_name -> rhizome_atlas.v1.SumEntry
+	102, // 94: rhizome_atlas.v1.TidyResponse.added:type_name -> rhizome_atlas.v1.SumEntry
+	6,   // 95: rhizome_atlas.v1.RhizomeAtlasService.Init:input_type -> rhizome_atlas.v1.InitRequest
+	8,   // 96: rhizome_atlas.v1.RhizomeAtlasService.New:input_type -> rhizome_atlas.v1.NewRequest
+	10,  // 97: rhizome_atlas.v1.RhizomeAtlasService.Add:input_type -> rhizome_atlas.v1.AddRequest
+	13,  // 98: rhizome_atlas.v1.RhizomeAtlasService.Resolve:input_type -> rhizome_atlas.v1.ResolveRequest
+	15,  // 99: rhizome_atlas.v1.RhizomeAtlasService.Remove:input_type -> rhizome_atlas.v1.RemoveRequest
+	17,  // 100: rhizome_atlas.v1.RhizomeAtlasService.Pull:input_type -> rhizome_atlas.v1.PullRequest
+	17,  // 101: rhizome_atlas.v1.RhizomeAtlasService.StreamPull:input_type -> rhizome_atlas.v1.PullRequest
+	19,  // 102: rhizome_atlas.v1.RhizomeAtlasService.Lock:input_type -> rhizome_atlas.v1.LockRequest
+	26,  // 103: rhizome_atlas.v1.RhizomeAtlasService.Verify:input_type -> rhizome_atlas.v1.VerifyRequest
+	29,  // 104: rhizome_atlas.v1.RhizomeAtlasService.VerifyAll:input_type -> rhizome_atlas.v1.VerifyAllRequest
+	32,  // 105: rhizome_atlas.v1.RhizomeAtlasService.Graph:input_type -> rhizome_atlas.v1.GraphRequest
+	36,  // 106: rhizome_atlas.v1.RhizomeAtlasService.StreamGraph:input_type -> rhizome_atlas.v1.StreamGraphRequest
+	39,  // 107: rhizome_atlas.v1.RhizomeAtlasService.Update:input_type -> rhizome_atlas.v1.UpdateRequest
+	44,  // 108: rhizome_atlas.v1.RhizomeAtlasService.Vendor:input_type -> rhizome_atlas.v1.VendorRequest
+	46,  // 109: rhizome_atlas.v1.RhizomeAtlasService.StreamVendor:input_type -> rhizome_atlas.v1.StreamVendorRequest
+	49,  // 110: rhizome_atlas.v1.RhizomeAtlasService.CleanCache:input_type -> rhizome_atlas.v1.CleanCacheRequest
+	72,  // 111: rhizome_atlas.v1.RhizomeAtlasService.Describe:input_type -> rhizome_atlas.v1.DescribeRequest
+	76,  // 112: rhizome_atlas.v1.RhizomeAtlasService.FindCapability:input_type -> rhizome_atlas.v1.FindCapabilityRequest
+	78,  // 113: rhizome_atlas.v1.RhizomeAtlasService.Owners:input_type -> rhizome_atlas.v1.OwnersRequest
+	81,  // 114: rhizome_atlas.v1.RhizomeAtlasService.Release:input_type -> rhizome_atlas.v1.ReleaseRequest
+	83,  // 115: rhizome_atlas.v1.RhizomeAtlasService.BundleCreate:input_type -> rhizome_atlas.v1.BundleCreateRequest
+	85,  // 116: rhizome_atlas.v1.RhizomeAtlasService.BundleInstall:input_type -> rhizome_atlas.v1.BundleInstallRequest
+	87,  // 117: rhizome_atlas.v1.RhizomeAtlasService.SumPrune:input_type -> rhizome_atlas.v1.SumPruneRequest
+	91,  // 118: rhizome_atlas.v1.RhizomeAtlasService.SumMerge:input_type -> rhizome_atlas.v1.SumMergeRequest
+	89,  // 119: rhizome_atlas.v1.RhizomeAtlasService.SumMigrate:input_type -> rhizome_atlas.v1.SumMigrateRequest
+	94,  // 120: rhizome_atlas.v1.RhizomeAtlasService.ModMerge:input_type -> rhizome_atlas.v1.ModMergeRequest
+	96,  // 121: rhizome_atlas.v1.RhizomeAtlasService.Undo:input_type -> rhizome_atlas.v1.UndoRequest
+	98,  // 122: rhizome_atlas.v1.RhizomeAtlasService.History:input_type -> rhizome_atlas.v1.HistoryRequest
+	51,  // 123: rhizome_atlas.v1.RhizomeAtlasService.CacheList:input_type -> rhizome_atlas.v1.CacheListRequest
+	55,  // 124: rhizome_atlas.v1.RhizomeAtlasService.CacheOrphans:input_type -> rhizome_atlas.v1.CacheOrphansRequest
+	57,  // 125: rhizome_atlas.v1.RhizomeAtlasService.CacheSeed:input_type -> rhizome_atlas.v1.CacheSeedRequest
+	59,  // 126: rhizome_atlas.v1.RhizomeAtlasService.CachePush:input_type -> rhizome_atlas.v1.CachePushRequest
+	61,  // 127: rhizome_atlas.v1.RhizomeAtlasService.HasEntry:input_type -> rhizome_atlas.v1.HasEntryRequest
+	63,  // 128: rhizome_atlas.v1.RhizomeAtlasService.FetchEntry:input_type -> rhizome_atlas.v1.FetchEntryRequest
+	65,  // 129: rhizome_atlas.v1.RhizomeAtlasService.GetLogHead:input_type -> rhizome_atlas.v1.GetLogHeadRequest
+	67,  // 130: rhizome_atlas.v1.RhizomeAtlasService.ProveLogInclusion:input_type -> rhizome_atlas.v1.ProveLogInclusionRequest
+	70,  // 131: rhizome_atlas.v1.RhizomeAtlasService.ProveLogConsistency:input_type -> rhizome_atlas.v1.ProveLogConsistencyRequest
+	104, // 132: rhizome_atlas.v1.RhizomeAtlasService.Prefetch:input_type -> rhizome_atlas.v1.PrefetchRequest
+	17,  // 133: rhizome_atlas.v1.RhizomeAtlasService.StartPull:input_type -> rhizome_atlas.v1.PullRequest
+	39,  // 134: rhizome_atlas.v1.RhizomeAtlasService.StartUpdate:input_type -> rhizome_atlas.v1.UpdateRequest
+	107, // 135: rhizome_atlas.v1.RhizomeAtlasService.GetOperation:input_type -> rhizome_atlas.v1.GetOperationRequest
+	107, // 136: rhizome_atlas.v1.RhizomeAtlasService.WatchOperation:input_type -> rhizome_atlas.v1.GetOperationRequest
+	108, // 137: rhizome_atlas.v1.RhizomeAtlasService.CancelOperation:input_type -> rhizome_atlas.v1.CancelOperationRequest
+	109, // 138: rhizome_atlas.v1.RhizomeAtlasService.MirrorSync:input_type -> rhizome_atlas.v1.MirrorSyncRequest
+	112, // 139: rhizome_atlas.v1.RhizomeAtlasService.Reproduce:input_type -> rhizome_atlas.v1.ReproduceRequest
+	115, // 140: rhizome_atlas.v1.RhizomeAtlasService.Impact:input_type -> rhizome_atlas.v1.ImpactRequest
+	120, // 141: rhizome_atlas.v1.RhizomeAtlasService.Freshness:input_type -> rhizome_atlas.v1.FreshnessRequest
+	123, // 142: rhizome_atlas.v1.RhizomeAtlasService.Subscribe:input_type -> rhizome_atlas.v1.SubscribeRequest
+	125, // 143: rhizome_atlas.v1.RhizomeAtlasService.Diagnose:input_type -> rhizome_atlas.v1.DiagnoseRequest
+	130, // 144: rhizome_atlas.v1.RhizomeAtlasService.Env:input_type -> rhizome_atlas.v1.EnvRequest
+	133, // 145: rhizome_atlas.v1.RhizomeAtlasService.ReplaceDiff:input_type -> rhizome_atlas.v1.ReplaceDiffRequest
+	136, // 146: rhizome_atlas.v1.RhizomeAtlasService.ReplacePromote:input_type -> rhizome_atlas.v1.ReplacePromoteRequest
+	138, // 147: rhizome_atlas.v1.RhizomeAtlasService.Tidy:input_type -> rhizome_atlas.v1.TidyRequest
+	7,   // 148: rhizome_atlas.v1.RhizomeAtlasService.Init:output_type -> rhizome_atlas.v1.InitResponse
+	9,   // 149: rhizome_atlas.v1.RhizomeAtlasService.New:output_type -> rhizome_atlas.v1.NewResponse
+	11,  // 150: rhizome_atlas.v1.RhizomeAtlasService.Add:output_type -> rhizome_atlas.v1.AddResponse
+	14,  // 151: rhizome_atlas.v1.RhizomeAtlasService.Resolve:output_type -> rhizome_atlas.v1.ResolveResponse
+	16,  // 152: rhizome_atlas.v1.RhizomeAtlasService.Remove:output_type -> rhizome_atlas.v1.RemoveResponse
+	18,  // 153: rhizome_atlas.v1.RhizomeAtlasService.Pull:output_type -> rhizome_atlas.v1.PullResponse
+	21,  // 154: rhizome_atlas.v1.RhizomeAtlasService.StreamPull:output_type -> rhizome_atlas.v1.PullProgress
+	20,  // 155: rhizome_atlas.v1.RhizomeAtlasService.Lock:output_type -> rhizome_atlas.v1.LockResponse
+	27,  // 156: rhizome_atlas.v1.RhizomeAtlasService.Verify:output_type -> rhizome_atlas.v1.VerifyResponse
+	30,  // 157: rhizome_atlas.v1.RhizomeAtlasService.VerifyAll:output_type -> rhizome_atlas.v1.VerifyAllResponse
+	33,  // 158: rhizome_atlas.v1.RhizomeAtlasService.Graph:output_type -> rhizome_atlas.v1.GraphResponse
+	37,  // 159: rhizome_atlas.v1.RhizomeAtlasService.StreamGraph:output_type -> rhizome_atlas.v1.GraphChunk
+	40,  // 160: rhizome_atlas.v1.RhizomeAtlasService.Update:output_type -> rhizome_atlas.v1.UpdateResponse
+	45,  // 161: rhizome_atlas.v1.RhizomeAtlasService.Vendor:output_type -> rhizome_atlas.v1.VendorResponse
+	47,  // 162: rhizome_atlas.v1.RhizomeAtlasService.StreamVendor:output_type -> rhizome_atlas.v1.VendorProgress
+	50,  // 163: rhizome_atlas.v1.RhizomeAtlasService.CleanCache:output_type -> rhizome_atlas.v1.CleanCacheResponse
+	73,  // 164: rhizome_atlas.v1.RhizomeAtlasService.Describe:output_type -> rhizome_atlas.v1.DescribeResponse
+	77,  // 165: rhizome_atlas.v1.RhizomeAtlasService.FindCapability:output_type -> rhizome_atlas.v1.FindCapabilityResponse
+	79,  // 166: rhizome_atlas.v1.RhizomeAtlasService.Owners:output_type -> rhizome_atlas.v1.OwnersResponse
+	82,  // 167: rhizome_atlas.v1.RhizomeAtlasService.Release:output_type -> rhizome_atlas.v1.ReleaseResponse
+	84,  // 168: rhizome_atlas.v1.RhizomeAtlasService.BundleCreate:output_type -> rhizome_atlas.v1.BundleCreateResponse
+	86,  // 169: rhizome_atlas.v1.RhizomeAtlasService.BundleInstall:output_type -> rhizome_atlas.v1.BundleInstallResponse
+	88,  // 170: rhizome_atlas.v1.RhizomeAtlasService.SumPrune:output_type -> rhizome_atlas.v1.SumPruneResponse
+	92,  // 171: rhizome_atlas.v1.RhizomeAtlasService.SumMerge:output_type -> rhizome_atlas.v1.SumMergeResponse
+	90,  // 172: rhizome_atlas.v1.RhizomeAtlasService.SumMigrate:output_type -> rhizome_atlas.v1.SumMigrateResponse
+	95,  // 173: rhizome_atlas.v1.RhizomeAtlasService.ModMerge:output_type -> rhizome_atlas.v1.ModMergeResponse
+	97,  // 174: rhizome_atlas.v1.RhizomeAtlasService.Undo:output_type -> rhizome_atlas.v1.UndoResponse
+	99,  // 175: rhizome_atlas.v1.RhizomeAtlasService.History:output_type -> rhizome_atlas.v1.HistoryResponse
+	52,  // 176: rhizome_atlas.v1.RhizomeAtlasService.CacheList:output_type -> rhizome_atlas.v1.CacheListResponse
+	56,  // 177: rhizome_atlas.v1.RhizomeAtlasService.CacheOrphans:output_type -> rhizome_atlas.v1.CacheOrphansResponse
+	58,  // 178: rhizome_atlas.v1.RhizomeAtlasService.CacheSeed:output_type -> rhizome_atlas.v1.CacheSeedResponse
+	60,  // 179: rhizome_atlas.v1.RhizomeAtlasService.CachePush:output_type -> rhizome_atlas.v1.CachePushResponse
+	62,  // 180: rhizome_atlas.v1.RhizomeAtlasService.HasEntry:output_type -> rhizome_atlas.v1.HasEntryResponse
+	64,  // 181: rhizome_atlas.v1.RhizomeAtlasService.FetchEntry:output_type -> rhizome_atlas.v1.FetchEntryChunk
+	66,  // 182: rhizome_atlas.v1.RhizomeAtlasService.GetLogHead:output_type -> rhizome_atlas.v1.LogHead
+	68,  // 183: rhizome_atlas.v1.RhizomeAtlasService.ProveLogInclusion:output_type -> rhizome_atlas.v1.ProveLogInclusionResponse
+	71,  // 184: rhizome_atlas.v1.RhizomeAtlasService.ProveLogConsistency:output_type -> rhizome_atlas.v1.ProveLogConsistencyResponse
+	105, // 185: rhizome_atlas.v1.RhizomeAtlasService.Prefetch:output_type -> rhizome_atlas.v1.PrefetchResponse
+	106, // 186: rhizome_atlas.v1.RhizomeAtlasService.StartPull:output_type -> rhizome_atlas.v1.Operation
+	106, // 187: rhizome_atlas.v1.RhizomeAtlasService.StartUpdate:output_type -> rhizome_atlas.v1.Operation
+	106, // 188: rhizome_atlas.v1.RhizomeAtlasService.GetOperation:output_type -> rhizome_atlas.v1.Operation
+	106, // 189: rhizome_atlas.v1.RhizomeAtlasService.WatchOperation:output_type -> rhizome_atlas.v1.Operation
+	106, // 190: rhizome_atlas.v1.RhizomeAtlasService.CancelOperation:output_type -> rhizome_atlas.v1.Operation
+	110, // 191: rhizome_atlas.v1.RhizomeAtlasService.MirrorSync:output_type -> rhizome_atlas.v1.MirrorSyncResponse
+	113, // 192: rhizome_atlas.v1.RhizomeAtlasService.Reproduce:output_type -> rhizome_atlas.v1.ReproduceResponse
+	116, // 193: rhizome_atlas.v1.RhizomeAtlasService.Impact:output_type -> rhizome_atlas.v1.ImpactResponse
+	121, // 194: rhizome_atlas.v1.RhizomeAtlasService.Freshness:output_type -> rhizome_atlas.v1.FreshnessResponse
+	124, // 195: rhizome_atlas.v1.RhizomeAtlasService.Subscribe:output_type -> rhizome_atlas.v1.Event
+	126, // 196: rhizome_atlas.v1.RhizomeAtlasService.Diagnose:output_type -> rhizome_atlas.v1.DiagnoseResponse
+	131, // 197: rhizome_atlas.v1.RhizomeAtlasService.Env:output_type -> rhizome_atlas.v1.EnvResponse
+	134, // 198: rhizome_atlas.v1.RhizomeAtlasService.ReplaceDiff:output_type -> rhizome_atlas.v1.ReplaceDiffResponse
+	137, // 199: rhizome_atlas.v1.RhizomeAtlasService.ReplacePromote:output_type -> rhizome_atlas.v1.ReplacePromoteResponse
+	139, // 200: rhizome_atlas.v1.RhizomeAtlasService.Tidy:output_type -> rhizome_atlas.v1.TidyResponse
+	148, // [148:201] is the sub-list for method output_type
+	95,  // [95:148] is the sub-list for method input_type
+	95,  // [95:95] is the sub-list for extension type_name
+	95,  // [95:95] is the sub-list for extension extendee
+	0,   // [0:95] is the sub-list for field type_name
 }
 
 func init() { file_protos_rhizome_atlas_v1_rhizome_atlas_proto_init() }
@@ -1202,13 +10970,14 @@ func file_protos_rhizome_atlas_v1_rhizome_atlas_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDesc), len(file_protos_rhizome_atlas_v1_rhizome_atlas_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   21,
+			NumEnums:      6,
+			NumMessages:   139,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_protos_rhizome_atlas_v1_rhizome_atlas_proto_goTypes,
 		DependencyIndexes: file_protos_rhizome_atlas_v1_rhizome_atlas_proto_depIdxs,
+		EnumInfos:         file_protos_rhizome_atlas_v1_rhizome_atlas_proto_enumTypes,
 		MessageInfos:      file_protos_rhizome_atlas_v1_rhizome_atlas_proto_msgTypes,
 	}.Build()
 	File_protos_rhizome_atlas_v1_rhizome_atlas_proto = out.File
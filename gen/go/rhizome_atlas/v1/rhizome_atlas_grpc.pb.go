@@ -19,15 +19,59 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	RhizomeAtlasService_Init_FullMethodName       = "/rhizome_atlas.v1.RhizomeAtlasService/Init"
-	RhizomeAtlasService_Add_FullMethodName        = "/rhizome_atlas.v1.RhizomeAtlasService/Add"
-	RhizomeAtlasService_Remove_FullMethodName     = "/rhizome_atlas.v1.RhizomeAtlasService/Remove"
-	RhizomeAtlasService_Pull_FullMethodName       = "/rhizome_atlas.v1.RhizomeAtlasService/Pull"
-	RhizomeAtlasService_Verify_FullMethodName     = "/rhizome_atlas.v1.RhizomeAtlasService/Verify"
-	RhizomeAtlasService_Graph_FullMethodName      = "/rhizome_atlas.v1.RhizomeAtlasService/Graph"
-	RhizomeAtlasService_Update_FullMethodName     = "/rhizome_atlas.v1.RhizomeAtlasService/Update"
-	RhizomeAtlasService_Vendor_FullMethodName     = "/rhizome_atlas.v1.RhizomeAtlasService/Vendor"
-	RhizomeAtlasService_CleanCache_FullMethodName = "/rhizome_atlas.v1.RhizomeAtlasService/CleanCache"
+	RhizomeAtlasService_Init_FullMethodName                = "/rhizome_atlas.v1.RhizomeAtlasService/Init"
+	RhizomeAtlasService_New_FullMethodName                 = "/rhizome_atlas.v1.RhizomeAtlasService/New"
+	RhizomeAtlasService_Add_FullMethodName                 = "/rhizome_atlas.v1.RhizomeAtlasService/Add"
+	RhizomeAtlasService_Resolve_FullMethodName             = "/rhizome_atlas.v1.RhizomeAtlasService/Resolve"
+	RhizomeAtlasService_Remove_FullMethodName              = "/rhizome_atlas.v1.RhizomeAtlasService/Remove"
+	RhizomeAtlasService_Pull_FullMethodName                = "/rhizome_atlas.v1.RhizomeAtlasService/Pull"
+	RhizomeAtlasService_StreamPull_FullMethodName          = "/rhizome_atlas.v1.RhizomeAtlasService/StreamPull"
+	RhizomeAtlasService_Lock_FullMethodName                = "/rhizome_atlas.v1.RhizomeAtlasService/Lock"
+	RhizomeAtlasService_Verify_FullMethodName              = "/rhizome_atlas.v1.RhizomeAtlasService/Verify"
+	RhizomeAtlasService_VerifyAll_FullMethodName           = "/rhizome_atlas.v1.RhizomeAtlasService/VerifyAll"
+	RhizomeAtlasService_Graph_FullMethodName               = "/rhizome_atlas.v1.RhizomeAtlasService/Graph"
+	RhizomeAtlasService_StreamGraph_FullMethodName         = "/rhizome_atlas.v1.RhizomeAtlasService/StreamGraph"
+	RhizomeAtlasService_Update_FullMethodName              = "/rhizome_atlas.v1.RhizomeAtlasService/Update"
+	RhizomeAtlasService_Vendor_FullMethodName              = "/rhizome_atlas.v1.RhizomeAtlasService/Vendor"
+	RhizomeAtlasService_StreamVendor_FullMethodName        = "/rhizome_atlas.v1.RhizomeAtlasService/StreamVendor"
+	RhizomeAtlasService_CleanCache_FullMethodName          = "/rhizome_atlas.v1.RhizomeAtlasService/CleanCache"
+	RhizomeAtlasService_Describe_FullMethodName            = "/rhizome_atlas.v1.RhizomeAtlasService/Describe"
+	RhizomeAtlasService_FindCapability_FullMethodName      = "/rhizome_atlas.v1.RhizomeAtlasService/FindCapability"
+	RhizomeAtlasService_Owners_FullMethodName              = "/rhizome_atlas.v1.RhizomeAtlasService/Owners"
+	RhizomeAtlasService_Release_FullMethodName             = "/rhizome_atlas.v1.RhizomeAtlasService/Release"
+	RhizomeAtlasService_BundleCreate_FullMethodName        = "/rhizome_atlas.v1.RhizomeAtlasService/BundleCreate"
+	RhizomeAtlasService_BundleInstall_FullMethodName       = "/rhizome_atlas.v1.RhizomeAtlasService/BundleInstall"
+	RhizomeAtlasService_SumPrune_FullMethodName            = "/rhizome_atlas.v1.RhizomeAtlasService/SumPrune"
+	RhizomeAtlasService_SumMerge_FullMethodName            = "/rhizome_atlas.v1.RhizomeAtlasService/SumMerge"
+	RhizomeAtlasService_SumMigrate_FullMethodName          = "/rhizome_atlas.v1.RhizomeAtlasService/SumMigrate"
+	RhizomeAtlasService_ModMerge_FullMethodName            = "/rhizome_atlas.v1.RhizomeAtlasService/ModMerge"
+	RhizomeAtlasService_Undo_FullMethodName                = "/rhizome_atlas.v1.RhizomeAtlasService/Undo"
+	RhizomeAtlasService_History_FullMethodName             = "/rhizome_atlas.v1.RhizomeAtlasService/History"
+	RhizomeAtlasService_CacheList_FullMethodName           = "/rhizome_atlas.v1.RhizomeAtlasService/CacheList"
+	RhizomeAtlasService_CacheOrphans_FullMethodName        = "/rhizome_atlas.v1.RhizomeAtlasService/CacheOrphans"
+	RhizomeAtlasService_CacheSeed_FullMethodName           = "/rhizome_atlas.v1.RhizomeAtlasService/CacheSeed"
+	RhizomeAtlasService_CachePush_FullMethodName           = "/rhizome_atlas.v1.RhizomeAtlasService/CachePush"
+	RhizomeAtlasService_HasEntry_FullMethodName            = "/rhizome_atlas.v1.RhizomeAtlasService/HasEntry"
+	RhizomeAtlasService_FetchEntry_FullMethodName          = "/rhizome_atlas.v1.RhizomeAtlasService/FetchEntry"
+	RhizomeAtlasService_GetLogHead_FullMethodName          = "/rhizome_atlas.v1.RhizomeAtlasService/GetLogHead"
+	RhizomeAtlasService_ProveLogInclusion_FullMethodName   = "/rhizome_atlas.v1.RhizomeAtlasService/ProveLogInclusion"
+	RhizomeAtlasService_ProveLogConsistency_FullMethodName = "/rhizome_atlas.v1.RhizomeAtlasService/ProveLogConsistency"
+	RhizomeAtlasService_Prefetch_FullMethodName            = "/rhizome_atlas.v1.RhizomeAtlasService/Prefetch"
+	RhizomeAtlasService_StartPull_FullMethodName           = "/rhizome_atlas.v1.RhizomeAtlasService/StartPull"
+	RhizomeAtlasService_StartUpdate_FullMethodName         = "/rhizome_atlas.v1.RhizomeAtlasService/StartUpdate"
+	RhizomeAtlasService_GetOperation_FullMethodName        = "/rhizome_atlas.v1.RhizomeAtlasService/GetOperation"
+	RhizomeAtlasService_WatchOperation_FullMethodName      = "/rhizome_atlas.v1.RhizomeAtlasService/WatchOperation"
+	RhizomeAtlasService_CancelOperation_FullMethodName     = "/rhizome_atlas.v1.RhizomeAtlasService/CancelOperation"
+	RhizomeAtlasService_MirrorSync_FullMethodName          = "/rhizome_atlas.v1.RhizomeAtlasService/MirrorSync"
+	RhizomeAtlasService_Reproduce_FullMethodName           = "/rhizome_atlas.v1.RhizomeAtlasService/Reproduce"
+	RhizomeAtlasService_Impact_FullMethodName              = "/rhizome_atlas.v1.RhizomeAtlasService/Impact"
+	RhizomeAtlasService_Freshness_FullMethodName           = "/rhizome_atlas.v1.RhizomeAtlasService/Freshness"
+	RhizomeAtlasService_Subscribe_FullMethodName           = "/rhizome_atlas.v1.RhizomeAtlasService/Subscribe"
+	RhizomeAtlasService_Diagnose_FullMethodName            = "/rhizome_atlas.v1.RhizomeAtlasService/Diagnose"
+	RhizomeAtlasService_Env_FullMethodName                 = "/rhizome_atlas.v1.RhizomeAtlasService/Env"
+	RhizomeAtlasService_ReplaceDiff_FullMethodName         = "/rhizome_atlas.v1.RhizomeAtlasService/ReplaceDiff"
+	RhizomeAtlasService_ReplacePromote_FullMethodName      = "/rhizome_atlas.v1.RhizomeAtlasService/ReplacePromote"
+	RhizomeAtlasService_Tidy_FullMethodName                = "/rhizome_atlas.v1.RhizomeAtlasService/Tidy"
 )
 
 // RhizomeAtlasServiceClient is the client API for RhizomeAtlasService service.
@@ -37,25 +81,173 @@ const (
 // RhizomeAtlasService manages holon dependencies.
 // It resolves, fetches, caches, and verifies dependencies
 // declared in holon.mod and holon.sum.
+//
+// Errors carry a google.rpc.ErrorInfo in the domain
+// "rhizome-atlas.organic-programming" when their cause is known. Its reason
+// (e.g. NOT_CACHED) is stable; its metadata may name the "dependency", the
+// "file" at fault and the command to "run" next. Missing dependencies are
+// also listed as google.rpc.PreconditionFailure violations.
 type RhizomeAtlasServiceClient interface {
 	// Init creates a holon.mod file in the given directory.
 	Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*InitResponse, error)
-	// Add adds a dependency to holon.mod and fetches it.
+	// New creates a holon from a published template, substituting its
+	// variables.
+	New(ctx context.Context, in *NewRequest, opts ...grpc.CallOption) (*NewResponse, error)
+	// Add fetches a dependency and records it in holon.mod and holon.sum,
+	// all or nothing.
 	Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*AddResponse, error)
+	// Resolve reports what an Add of a version query would record: the
+	// concrete version, where it would be fetched from and whether it is
+	// cached. It modifies nothing.
+	Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error)
 	// Remove removes a dependency from holon.mod.
 	Remove(ctx context.Context, in *RemoveRequest, opts ...grpc.CallOption) (*RemoveResponse, error)
 	// Pull fetches all dependencies declared in holon.mod to the cache.
 	Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*PullResponse, error)
+	// StreamPull pulls as Pull does, reporting the progress of each
+	// dependency as it is fetched, hashed and recorded, then the outcome.
+	StreamPull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PullProgress], error)
+	// Lock resolves the dependency graph again and records the version
+	// selected for every dependency, direct or not, in holon.lock, which
+	// Pull and Vendor then keep to.
+	Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error)
 	// Verify checks holon.sum integrity against cached content.
 	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+	// VerifyAll verifies several holons in one call.
+	VerifyAll(ctx context.Context, in *VerifyAllRequest, opts ...grpc.CallOption) (*VerifyAllResponse, error)
 	// Graph returns the dependency tree.
 	Graph(ctx context.Context, in *GraphRequest, opts ...grpc.CallOption) (*GraphResponse, error)
+	// StreamGraph returns the dependency tree as a stream of edge chunks
+	// followed by a summary. Prefer it over Graph for very large graphs.
+	StreamGraph(ctx context.Context, in *StreamGraphRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GraphChunk], error)
 	// Update updates dependencies to their latest compatible versions.
+	// Updates that remove declared capabilities are held back unless
+	// allow_breaking is set.
 	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
 	// Vendor copies cached dependencies to a local .holon/ directory.
 	Vendor(ctx context.Context, in *VendorRequest, opts ...grpc.CallOption) (*VendorResponse, error)
+	// StreamVendor vendors as Vendor does, reporting each dependency as it
+	// is copied, then a summary. Prefer it over Vendor for large closures.
+	StreamVendor(ctx context.Context, in *StreamVendorRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[VendorProgress], error)
 	// CleanCache purges the global holon cache (~/.holon/cache/).
 	CleanCache(ctx context.Context, in *CleanCacheRequest, opts ...grpc.CallOption) (*CleanCacheResponse, error)
+	// Describe returns the HOLON.md front-matter of a holon or of one of
+	// its dependencies.
+	Describe(ctx context.Context, in *DescribeRequest, opts ...grpc.CallOption) (*DescribeResponse, error)
+	// FindCapability returns the holons of the dependency graph whose
+	// HOLON.md declares a capability.
+	FindCapability(ctx context.Context, in *FindCapabilityRequest, opts ...grpc.CallOption) (*FindCapabilityResponse, error)
+	// Owners returns who owns and maintains each holon of the dependency
+	// graph, from their HOLON.md.
+	Owners(ctx context.Context, in *OwnersRequest, opts ...grpc.CallOption) (*OwnersResponse, error)
+	// Release tags the next semver version of the holon in a directory.
+	Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error)
+	// BundleCreate packages a holon's manifests and its whole cached
+	// dependency closure into one file for air-gapped machines.
+	BundleCreate(ctx context.Context, in *BundleCreateRequest, opts ...grpc.CallOption) (*BundleCreateResponse, error)
+	// BundleInstall verifies a bundle and installs it into the cache.
+	BundleInstall(ctx context.Context, in *BundleInstallRequest, opts ...grpc.CallOption) (*BundleInstallResponse, error)
+	// SumPrune drops holon.sum entries no longer reachable from holon.mod.
+	SumPrune(ctx context.Context, in *SumPruneRequest, opts ...grpc.CallOption) (*SumPruneResponse, error)
+	// SumMerge unions two holon.sum files, failing only on hash conflicts.
+	SumMerge(ctx context.Context, in *SumMergeRequest, opts ...grpc.CallOption) (*SumMergeResponse, error)
+	// SumMigrate records every holon.sum entry with another hash algorithm
+	// as well.
+	SumMigrate(ctx context.Context, in *SumMigrateRequest, opts ...grpc.CallOption) (*SumMigrateResponse, error)
+	// ModMerge merges two holon.mod files structurally.
+	ModMerge(ctx context.Context, in *ModMergeRequest, opts ...grpc.CallOption) (*ModMergeResponse, error)
+	// Undo restores holon.mod and holon.sum as they were before the last
+	// mutating operation.
+	Undo(ctx context.Context, in *UndoRequest, opts ...grpc.CallOption) (*UndoResponse, error)
+	// History lists the changes made to holon.mod and holon.sum.
+	History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error)
+	// CacheList lists the entries of the global holon cache, one page at a time.
+	CacheList(ctx context.Context, in *CacheListRequest, opts ...grpc.CallOption) (*CacheListResponse, error)
+	// CacheOrphans lists, and optionally deletes, the cache entries no
+	// holon.sum of the given holons records.
+	CacheOrphans(ctx context.Context, in *CacheOrphansRequest, opts ...grpc.CallOption) (*CacheOrphansResponse, error)
+	// CacheSeed populates the cache from the vendor directory of a holon,
+	// each copy checked against holon.sum first, without network access.
+	CacheSeed(ctx context.Context, in *CacheSeedRequest, opts ...grpc.CallOption) (*CacheSeedResponse, error)
+	// CachePush exports a cache entry as an OCI artifact to a container
+	// registry, for registry replication tooling to distribute.
+	CachePush(ctx context.Context, in *CachePushRequest, opts ...grpc.CallOption) (*CachePushResponse, error)
+	// HasEntry reports whether a snapshot is in the local cache of this
+	// server, for other servers using it as a shared cache.
+	HasEntry(ctx context.Context, in *HasEntryRequest, opts ...grpc.CallOption) (*HasEntryResponse, error)
+	// FetchEntry streams a snapshot of the local cache of this server as a
+	// zip archive.
+	FetchEntry(ctx context.Context, in *FetchEntryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FetchEntryChunk], error)
+	// GetLogHead returns the head of the checksum log of this server: the
+	// append-only Merkle tree, hashed as in RFC 6962, of one
+	// "<path> <version> <hash>\n" record per snapshot FetchEntry served.
+	GetLogHead(ctx context.Context, in *GetLogHeadRequest, opts ...grpc.CallOption) (*LogHead, error)
+	// ProveLogInclusion returns the records of a snapshot in the checksum
+	// log, each with the proof that it is in the tree of the given size.
+	ProveLogInclusion(ctx context.Context, in *ProveLogInclusionRequest, opts ...grpc.CallOption) (*ProveLogInclusionResponse, error)
+	// ProveLogConsistency proves that the checksum log of some size is a
+	// prefix of the log of a larger size: that it was only appended to.
+	ProveLogConsistency(ctx context.Context, in *ProveLogConsistencyRequest, opts ...grpc.CallOption) (*ProveLogConsistencyResponse, error)
+	// Prefetch fetches dependencies to the cache in the background, to warm
+	// it before builds start.
+	Prefetch(ctx context.Context, in *PrefetchRequest, opts ...grpc.CallOption) (*PrefetchResponse, error)
+	// StartPull runs Pull in the background and returns its operation at
+	// once, for clients that may disconnect before a large pull completes.
+	StartPull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*Operation, error)
+	// StartUpdate runs Update in the background, as StartPull does Pull.
+	StartUpdate(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*Operation, error)
+	// GetOperation returns an operation started by StartPull or StartUpdate,
+	// with its result once done.
+	GetOperation(ctx context.Context, in *GetOperationRequest, opts ...grpc.CallOption) (*Operation, error)
+	// WatchOperation streams an operation each time it changes, until done.
+	WatchOperation(ctx context.Context, in *GetOperationRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Operation], error)
+	// CancelOperation aborts a running operation: the fetch in progress is
+	// stopped and its partial snapshot removed. The operation then ends with
+	// the CANCELLED code. Canceling a finished operation does nothing.
+	CancelOperation(ctx context.Context, in *CancelOperationRequest, opts ...grpc.CallOption) (*Operation, error)
+	// MirrorSync copies the latest versions of the holons matching some
+	// path globs from a proxy into a local mirror, which ATLAS_PROXY can then
+	// point at.
+	MirrorSync(ctx context.Context, in *MirrorSyncRequest, opts ...grpc.CallOption) (*MirrorSyncResponse, error)
+	// Reproduce fetches every dependency afresh from upstream into a scratch
+	// directory, hashes it, and compares the hash with holon.sum and the
+	// cache: a reproducibility report for audits.
+	Reproduce(ctx context.Context, in *ReproduceRequest, opts ...grpc.CallOption) (*ReproduceResponse, error)
+	// Impact simulates requiring a dependency at another version and
+	// reports how the resolved graph would change, without modifying any
+	// file.
+	Impact(ctx context.Context, in *ImpactRequest, opts ...grpc.CallOption) (*ImpactResponse, error)
+	// Freshness reports how far each dependency is behind its latest
+	// release, in versions and in days between their tags, and whether that
+	// release deprecates it.
+	Freshness(ctx context.Context, in *FreshnessRequest, opts ...grpc.CallOption) (*FreshnessResponse, error)
+	// Subscribe streams the events of this server as they happen, until the
+	// call is canceled: fetches, failed verifications, available updates
+	// and cache cleanups. Response headers are sent once subscribed.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	// Diagnose checks a holon.mod, as saved or as being edited, and returns
+	// its problems with their position and the edits fixing them, for
+	// editors: "atlas lsp" serves it over the Language Server Protocol.
+	Diagnose(ctx context.Context, in *DiagnoseRequest, opts ...grpc.CallOption) (*DiagnoseResponse, error)
+	// Env returns the directory holding each dependency, local replace,
+	// cache or vendored copy, and the environment variable naming it:
+	// "atlas exec" runs commands with them set.
+	Env(ctx context.Context, in *EnvRequest, opts ...grpc.CallOption) (*EnvResponse, error)
+	// ReplaceDiff compares the local directory a dependency is replaced by
+	// with the version holon.mod requires, as cached: the local
+	// modifications carried by the replace. It modifies nothing but the
+	// cache, where that version is fetched to if missing.
+	ReplaceDiff(ctx context.Context, in *ReplaceDiffRequest, opts ...grpc.CallOption) (*ReplaceDiffResponse, error)
+	// ReplacePromote turns the local replace of a dependency into a
+	// published fork: the replace is removed, the require points at the
+	// fork instead (or a remote replace does), and the fork is fetched and
+	// recorded in holon.sum.
+	ReplacePromote(ctx context.Context, in *ReplacePromoteRequest, opts ...grpc.CallOption) (*ReplacePromoteResponse, error)
+	// Tidy drops the requires of holon.mod that nothing references, then
+	// brings holon.sum to the dependencies still reachable: entries of
+	// those no longer are removed, missing ones of cached dependencies
+	// added.
+	Tidy(ctx context.Context, in *TidyRequest, opts ...grpc.CallOption) (*TidyResponse, error)
 }
 
 type rhizomeAtlasServiceClient struct {
@@ -76,6 +268,16 @@ func (c *rhizomeAtlasServiceClient) Init(ctx context.Context, in *InitRequest, o
 	return out, nil
 }
 
+func (c *rhizomeAtlasServiceClient) New(ctx context.Context, in *NewRequest, opts ...grpc.CallOption) (*NewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NewResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_New_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *rhizomeAtlasServiceClient) Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*AddResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AddResponse)
@@ -86,6 +288,16 @@ func (c *rhizomeAtlasServiceClient) Add(ctx context.Context, in *AddRequest, opt
 	return out, nil
 }
 
+func (c *rhizomeAtlasServiceClient) Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolveResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Resolve_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *rhizomeAtlasServiceClient) Remove(ctx context.Context, in *RemoveRequest, opts ...grpc.CallOption) (*RemoveResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(RemoveResponse)
@@ -106,6 +318,35 @@ func (c *rhizomeAtlasServiceClient) Pull(ctx context.Context, in *PullRequest, o
 	return out, nil
 }
 
+func (c *rhizomeAtlasServiceClient) StreamPull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PullProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RhizomeAtlasService_ServiceDesc.Streams[0], RhizomeAtlasService_StreamPull_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PullRequest, PullProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RhizomeAtlasService_StreamPullClient = grpc.ServerStreamingClient[PullProgress]
+
+func (c *rhizomeAtlasServiceClient) Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LockResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Lock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *rhizomeAtlasServiceClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(VerifyResponse)
@@ -116,6 +357,16 @@ func (c *rhizomeAtlasServiceClient) Verify(ctx context.Context, in *VerifyReques
 	return out, nil
 }
 
+func (c *rhizomeAtlasServiceClient) VerifyAll(ctx context.Context, in *VerifyAllRequest, opts ...grpc.CallOption) (*VerifyAllResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyAllResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_VerifyAll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *rhizomeAtlasServiceClient) Graph(ctx context.Context, in *GraphRequest, opts ...grpc.CallOption) (*GraphResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GraphResponse)
@@ -126,6 +377,25 @@ func (c *rhizomeAtlasServiceClient) Graph(ctx context.Context, in *GraphRequest,
 	return out, nil
 }
 
+func (c *rhizomeAtlasServiceClient) StreamGraph(ctx context.Context, in *StreamGraphRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GraphChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RhizomeAtlasService_ServiceDesc.Streams[1], RhizomeAtlasService_StreamGraph_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamGraphRequest, GraphChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RhizomeAtlasService_StreamGraphClient = grpc.ServerStreamingClient[GraphChunk]
+
 func (c *rhizomeAtlasServiceClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(UpdateResponse)
@@ -143,261 +413,1707 @@ func (c *rhizomeAtlasServiceClient) Vendor(ctx context.Context, in *VendorReques
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) StreamVendor(ctx context.Context, in *StreamVendorRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[VendorProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RhizomeAtlasService_ServiceDesc.Streams[2], RhizomeAtlasService_StreamVendor_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamVendorRequest, VendorProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RhizomeAtlasService_StreamVendorClient = grpc.ServerStreamingClient[VendorProgress]
+
+func (c *rhizomeAtlasServiceClient) CleanCache(ctx context.Context, in *CleanCacheRequest, opts ...grpc.CallOption) (*CleanCacheResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CleanCacheResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_CleanCache_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) Describe(ctx context.Context, in *DescribeRequest, opts ...grpc.CallOption) (*DescribeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DescribeResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Describe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) FindCapability(ctx context.Context, in *FindCapabilityRequest, opts ...grpc.CallOption) (*FindCapabilityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindCapabilityResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_FindCapability_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) Owners(ctx context.Context, in *OwnersRequest, opts ...grpc.CallOption) (*OwnersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OwnersResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Owners_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReleaseResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Release_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) BundleCreate(ctx context.Context, in *BundleCreateRequest, opts ...grpc.CallOption) (*BundleCreateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BundleCreateResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_BundleCreate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) BundleInstall(ctx context.Context, in *BundleInstallRequest, opts ...grpc.CallOption) (*BundleInstallResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BundleInstallResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_BundleInstall_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) SumPrune(ctx context.Context, in *SumPruneRequest, opts ...grpc.CallOption) (*SumPruneResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SumPruneResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_SumPrune_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) SumMerge(ctx context.Context, in *SumMergeRequest, opts ...grpc.CallOption) (*SumMergeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SumMergeResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_SumMerge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) SumMigrate(ctx context.Context, in *SumMigrateRequest, opts ...grpc.CallOption) (*SumMigrateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SumMigrateResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_SumMigrate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) ModMerge(ctx context.Context, in *ModMergeRequest, opts ...grpc.CallOption) (*ModMergeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ModMergeResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_ModMerge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) Undo(ctx context.Context, in *UndoRequest, opts ...grpc.CallOption) (*UndoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UndoResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Undo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HistoryResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_History_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) CacheList(ctx context.Context, in *CacheListRequest, opts ...grpc.CallOption) (*CacheListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CacheListResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_CacheList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) CacheOrphans(ctx context.Context, in *CacheOrphansRequest, opts ...grpc.CallOption) (*CacheOrphansResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CacheOrphansResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_CacheOrphans_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) CacheSeed(ctx context.Context, in *CacheSeedRequest, opts ...grpc.CallOption) (*CacheSeedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CacheSeedResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_CacheSeed_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) CachePush(ctx context.Context, in *CachePushRequest, opts ...grpc.CallOption) (*CachePushResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CachePushResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_CachePush_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) HasEntry(ctx context.Context, in *HasEntryRequest, opts ...grpc.CallOption) (*HasEntryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HasEntryResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_HasEntry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) FetchEntry(ctx context.Context, in *FetchEntryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FetchEntryChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RhizomeAtlasService_ServiceDesc.Streams[3], RhizomeAtlasService_FetchEntry_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FetchEntryRequest, FetchEntryChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RhizomeAtlasService_FetchEntryClient = grpc.ServerStreamingClient[FetchEntryChunk]
+
+func (c *rhizomeAtlasServiceClient) GetLogHead(ctx context.Context, in *GetLogHeadRequest, opts ...grpc.CallOption) (*LogHead, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LogHead)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_GetLogHead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) ProveLogInclusion(ctx context.Context, in *ProveLogInclusionRequest, opts ...grpc.CallOption) (*ProveLogInclusionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProveLogInclusionResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_ProveLogInclusion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) ProveLogConsistency(ctx context.Context, in *ProveLogConsistencyRequest, opts ...grpc.CallOption) (*ProveLogConsistencyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProveLogConsistencyResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_ProveLogConsistency_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) Prefetch(ctx context.Context, in *PrefetchRequest, opts ...grpc.CallOption) (*PrefetchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PrefetchResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Prefetch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) StartPull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (*Operation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Operation)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_StartPull_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) StartUpdate(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*Operation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Operation)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_StartUpdate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) GetOperation(ctx context.Context, in *GetOperationRequest, opts ...grpc.CallOption) (*Operation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Operation)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_GetOperation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) WatchOperation(ctx context.Context, in *GetOperationRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Operation], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RhizomeAtlasService_ServiceDesc.Streams[4], RhizomeAtlasService_WatchOperation_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetOperationRequest, Operation]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RhizomeAtlasService_WatchOperationClient = grpc.ServerStreamingClient[Operation]
+
+func (c *rhizomeAtlasServiceClient) CancelOperation(ctx context.Context, in *CancelOperationRequest, opts ...grpc.CallOption) (*Operation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Operation)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_CancelOperation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) MirrorSync(ctx context.Context, in *MirrorSyncRequest, opts ...grpc.CallOption) (*MirrorSyncResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MirrorSyncResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_MirrorSync_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) Reproduce(ctx context.Context, in *ReproduceRequest, opts ...grpc.CallOption) (*ReproduceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReproduceResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Reproduce_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) Impact(ctx context.Context, in *ImpactRequest, opts ...grpc.CallOption) (*ImpactResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImpactResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Impact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) Freshness(ctx context.Context, in *FreshnessRequest, opts ...grpc.CallOption) (*FreshnessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FreshnessResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Freshness_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RhizomeAtlasService_ServiceDesc.Streams[5], RhizomeAtlasService_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RhizomeAtlasService_SubscribeClient = grpc.ServerStreamingClient[Event]
+
+func (c *rhizomeAtlasServiceClient) Diagnose(ctx context.Context, in *DiagnoseRequest, opts ...grpc.CallOption) (*DiagnoseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiagnoseResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Diagnose_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) Env(ctx context.Context, in *EnvRequest, opts ...grpc.CallOption) (*EnvResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnvResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Env_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) ReplaceDiff(ctx context.Context, in *ReplaceDiffRequest, opts ...grpc.CallOption) (*ReplaceDiffResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplaceDiffResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_ReplaceDiff_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) ReplacePromote(ctx context.Context, in *ReplacePromoteRequest, opts ...grpc.CallOption) (*ReplacePromoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplacePromoteResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_ReplacePromote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rhizomeAtlasServiceClient) Tidy(ctx context.Context, in *TidyRequest, opts ...grpc.CallOption) (*TidyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TidyResponse)
+	err := c.cc.Invoke(ctx, RhizomeAtlasService_Tidy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RhizomeAtlasServiceServer is the server API for RhizomeAtlasService service.
+// All implementations must embed UnimplementedRhizomeAtlasServiceServer
+// for forward compatibility.
+//
+// RhizomeAtlasService manages holon dependencies.
+// It resolves, fetches, caches, and verifies dependencies
+// declared in holon.mod and holon.sum.
+//
+// Errors carry a google.rpc.ErrorInfo in the domain
+// "rhizome-atlas.organic-programming" when their cause is known. Its reason
+// (e.g. NOT_CACHED) is stable; its metadata may name the "dependency", the
+// "file" at fault and the command to "run" next. Missing dependencies are
+// also listed as google.rpc.PreconditionFailure violations.
+type RhizomeAtlasServiceServer interface {
+	// Init creates a holon.mod file in the given directory.
+	Init(context.Context, *InitRequest) (*InitResponse, error)
+	// New creates a holon from a published template, substituting its
+	// variables.
+	New(context.Context, *NewRequest) (*NewResponse, error)
+	// Add fetches a dependency and records it in holon.mod and holon.sum,
+	// all or nothing.
+	Add(context.Context, *AddRequest) (*AddResponse, error)
+	// Resolve reports what an Add of a version query would record: the
+	// concrete version, where it would be fetched from and whether it is
+	// cached. It modifies nothing.
+	Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error)
+	// Remove removes a dependency from holon.mod.
+	Remove(context.Context, *RemoveRequest) (*RemoveResponse, error)
+	// Pull fetches all dependencies declared in holon.mod to the cache.
+	Pull(context.Context, *PullRequest) (*PullResponse, error)
+	// StreamPull pulls as Pull does, reporting the progress of each
+	// dependency as it is fetched, hashed and recorded, then the outcome.
+	StreamPull(*PullRequest, grpc.ServerStreamingServer[PullProgress]) error
+	// Lock resolves the dependency graph again and records the version
+	// selected for every dependency, direct or not, in holon.lock, which
+	// Pull and Vendor then keep to.
+	Lock(context.Context, *LockRequest) (*LockResponse, error)
+	// Verify checks holon.sum integrity against cached content.
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	// VerifyAll verifies several holons in one call.
+	VerifyAll(context.Context, *VerifyAllRequest) (*VerifyAllResponse, error)
+	// Graph returns the dependency tree.
+	Graph(context.Context, *GraphRequest) (*GraphResponse, error)
+	// StreamGraph returns the dependency tree as a stream of edge chunks
+	// followed by a summary. Prefer it over Graph for very large graphs.
+	StreamGraph(*StreamGraphRequest, grpc.ServerStreamingServer[GraphChunk]) error
+	// Update updates dependencies to their latest compatible versions.
+	// Updates that remove declared capabilities are held back unless
+	// allow_breaking is set.
+	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
+	// Vendor copies cached dependencies to a local .holon/ directory.
+	Vendor(context.Context, *VendorRequest) (*VendorResponse, error)
+	// StreamVendor vendors as Vendor does, reporting each dependency as it
+	// is copied, then a summary. Prefer it over Vendor for large closures.
+	StreamVendor(*StreamVendorRequest, grpc.ServerStreamingServer[VendorProgress]) error
+	// CleanCache purges the global holon cache (~/.holon/cache/).
+	CleanCache(context.Context, *CleanCacheRequest) (*CleanCacheResponse, error)
+	// Describe returns the HOLON.md front-matter of a holon or of one of
+	// its dependencies.
+	Describe(context.Context, *DescribeRequest) (*DescribeResponse, error)
+	// FindCapability returns the holons of the dependency graph whose
+	// HOLON.md declares a capability.
+	FindCapability(context.Context, *FindCapabilityRequest) (*FindCapabilityResponse, error)
+	// Owners returns who owns and maintains each holon of the dependency
+	// graph, from their HOLON.md.
+	Owners(context.Context, *OwnersRequest) (*OwnersResponse, error)
+	// Release tags the next semver version of the holon in a directory.
+	Release(context.Context, *ReleaseRequest) (*ReleaseResponse, error)
+	// BundleCreate packages a holon's manifests and its whole cached
+	// dependency closure into one file for air-gapped machines.
+	BundleCreate(context.Context, *BundleCreateRequest) (*BundleCreateResponse, error)
+	// BundleInstall verifies a bundle and installs it into the cache.
+	BundleInstall(context.Context, *BundleInstallRequest) (*BundleInstallResponse, error)
+	// SumPrune drops holon.sum entries no longer reachable from holon.mod.
+	SumPrune(context.Context, *SumPruneRequest) (*SumPruneResponse, error)
+	// SumMerge unions two holon.sum files, failing only on hash conflicts.
+	SumMerge(context.Context, *SumMergeRequest) (*SumMergeResponse, error)
+	// SumMigrate records every holon.sum entry with another hash algorithm
+	// as well.
+	SumMigrate(context.Context, *SumMigrateRequest) (*SumMigrateResponse, error)
+	// ModMerge merges two holon.mod files structurally.
+	ModMerge(context.Context, *ModMergeRequest) (*ModMergeResponse, error)
+	// Undo restores holon.mod and holon.sum as they were before the last
+	// mutating operation.
+	Undo(context.Context, *UndoRequest) (*UndoResponse, error)
+	// History lists the changes made to holon.mod and holon.sum.
+	History(context.Context, *HistoryRequest) (*HistoryResponse, error)
+	// CacheList lists the entries of the global holon cache, one page at a time.
+	CacheList(context.Context, *CacheListRequest) (*CacheListResponse, error)
+	// CacheOrphans lists, and optionally deletes, the cache entries no
+	// holon.sum of the given holons records.
+	CacheOrphans(context.Context, *CacheOrphansRequest) (*CacheOrphansResponse, error)
+	// CacheSeed populates the cache from the vendor directory of a holon,
+	// each copy checked against holon.sum first, without network access.
+	CacheSeed(context.Context, *CacheSeedRequest) (*CacheSeedResponse, error)
+	// CachePush exports a cache entry as an OCI artifact to a container
+	// registry, for registry replication tooling to distribute.
+	CachePush(context.Context, *CachePushRequest) (*CachePushResponse, error)
+	// HasEntry reports whether a snapshot is in the local cache of this
+	// server, for other servers using it as a shared cache.
+	HasEntry(context.Context, *HasEntryRequest) (*HasEntryResponse, error)
+	// FetchEntry streams a snapshot of the local cache of this server as a
+	// zip archive.
+	FetchEntry(*FetchEntryRequest, grpc.ServerStreamingServer[FetchEntryChunk]) error
+	// GetLogHead returns the head of the checksum log of this server: the
+	// append-only Merkle tree, hashed as in RFC 6962, of one
+	// "<path> <version> <hash>\n" record per snapshot FetchEntry served.
+	GetLogHead(context.Context, *GetLogHeadRequest) (*LogHead, error)
+	// ProveLogInclusion returns the records of a snapshot in the checksum
+	// log, each with the proof that it is in the tree of the given size.
+	ProveLogInclusion(context.Context, *ProveLogInclusionRequest) (*ProveLogInclusionResponse, error)
+	// ProveLogConsistency proves that the checksum log of some size is a
+	// prefix of the log of a larger size: that it was only appended to.
+	ProveLogConsistency(context.Context, *ProveLogConsistencyRequest) (*ProveLogConsistencyResponse, error)
+	// Prefetch fetches dependencies to the cache in the background, to warm
+	// it before builds start.
+	Prefetch(context.Context, *PrefetchRequest) (*PrefetchResponse, error)
+	// StartPull runs Pull in the background and returns its operation at
+	// once, for clients that may disconnect before a large pull completes.
+	StartPull(context.Context, *PullRequest) (*Operation, error)
+	// StartUpdate runs Update in the background, as StartPull does Pull.
+	StartUpdate(context.Context, *UpdateRequest) (*Operation, error)
+	// GetOperation returns an operation started by StartPull or StartUpdate,
+	// with its result once done.
+	GetOperation(context.Context, *GetOperationRequest) (*Operation, error)
+	// WatchOperation streams an operation each time it changes, until done.
+	WatchOperation(*GetOperationRequest, grpc.ServerStreamingServer[Operation]) error
+	// CancelOperation aborts a running operation: the fetch in progress is
+	// stopped and its partial snapshot removed. The operation then ends with
+	// the CANCELLED code. Canceling a finished operation does nothing.
+	CancelOperation(context.Context, *CancelOperationRequest) (*Operation, error)
+	// MirrorSync copies the latest versions of the holons matching some
+	// path globs from a proxy into a local mirror, which ATLAS_PROXY can then
+	// point at.
+	MirrorSync(context.Context, *MirrorSyncRequest) (*MirrorSyncResponse, error)
+	// Reproduce fetches every dependency afresh from upstream into a scratch
+	// directory, hashes it, and compares the hash with holon.sum and the
+	// cache: a reproducibility report for audits.
+	Reproduce(context.Context, *ReproduceRequest) (*ReproduceResponse, error)
+	// Impact simulates requiring a dependency at another version and
+	// reports how the resolved graph would change, without modifying any
+	// file.
+	Impact(context.Context, *ImpactRequest) (*ImpactResponse, error)
+	// Freshness reports how far each dependency is behind its latest
+	// release, in versions and in days between their tags, and whether that
+	// release deprecates it.
+	Freshness(context.Context, *FreshnessRequest) (*FreshnessResponse, error)
+	// Subscribe streams the events of this server as they happen, until the
+	// call is canceled: fetches, failed verifications, available updates
+	// and cache cleanups. Response headers are sent once subscribed.
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error
+	// Diagnose checks a holon.mod, as saved or as being edited, and returns
+	// its problems with their position and the edits fixing them, for
+	// editors: "atlas lsp" serves it over the Language Server Protocol.
+	Diagnose(context.Context, *DiagnoseRequest) (*DiagnoseResponse, error)
+	// Env returns the directory holding each dependency, local replace,
+	// cache or vendored copy, and the environment variable naming it:
+	// "atlas exec" runs commands with them set.
+	Env(context.Context, *EnvRequest) (*EnvResponse, error)
+	// ReplaceDiff compares the local directory a dependency is replaced by
+	// with the version holon.mod requires, as cached: the local
+	// modifications carried by the replace. It modifies nothing but the
+	// cache, where that version is fetched to if missing.
+	ReplaceDiff(context.Context, *ReplaceDiffRequest) (*ReplaceDiffResponse, error)
+	// ReplacePromote turns the local replace of a dependency into a
+	// published fork: the replace is removed, the require points at the
+	// fork instead (or a remote replace does), and the fork is fetched and
+	// recorded in holon.sum.
+	ReplacePromote(context.Context, *ReplacePromoteRequest) (*ReplacePromoteResponse, error)
+	// Tidy drops the requires of holon.mod that nothing references, then
+	// brings holon.sum to the dependencies still reachable: entries of
+	// those no longer are removed, missing ones of cached dependencies
+	// added.
+	Tidy(context.Context, *TidyRequest) (*TidyResponse, error)
+	mustEmbedUnimplementedRhizomeAtlasServiceServer()
+}
+
+// UnimplementedRhizomeAtlasServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRhizomeAtlasServiceServer struct{}
+
+func (UnimplementedRhizomeAtlasServiceServer) Init(context.Context, *InitRequest) (*InitResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Init not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) New(context.Context, *NewRequest) (*NewResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method New not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Add(context.Context, *AddRequest) (*AddResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Add not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Resolve not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Remove(context.Context, *RemoveRequest) (*RemoveResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Remove not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Pull(context.Context, *PullRequest) (*PullResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Pull not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) StreamPull(*PullRequest, grpc.ServerStreamingServer[PullProgress]) error {
+	return status.Error(codes.Unimplemented, "method StreamPull not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Lock(context.Context, *LockRequest) (*LockResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Lock not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) VerifyAll(context.Context, *VerifyAllRequest) (*VerifyAllResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method VerifyAll not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Graph(context.Context, *GraphRequest) (*GraphResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Graph not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) StreamGraph(*StreamGraphRequest, grpc.ServerStreamingServer[GraphChunk]) error {
+	return status.Error(codes.Unimplemented, "method StreamGraph not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Update(context.Context, *UpdateRequest) (*UpdateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Vendor(context.Context, *VendorRequest) (*VendorResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Vendor not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) StreamVendor(*StreamVendorRequest, grpc.ServerStreamingServer[VendorProgress]) error {
+	return status.Error(codes.Unimplemented, "method StreamVendor not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) CleanCache(context.Context, *CleanCacheRequest) (*CleanCacheResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CleanCache not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Describe(context.Context, *DescribeRequest) (*DescribeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Describe not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) FindCapability(context.Context, *FindCapabilityRequest) (*FindCapabilityResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FindCapability not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Owners(context.Context, *OwnersRequest) (*OwnersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Owners not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Release(context.Context, *ReleaseRequest) (*ReleaseResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Release not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) BundleCreate(context.Context, *BundleCreateRequest) (*BundleCreateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BundleCreate not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) BundleInstall(context.Context, *BundleInstallRequest) (*BundleInstallResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BundleInstall not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) SumPrune(context.Context, *SumPruneRequest) (*SumPruneResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SumPrune not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) SumMerge(context.Context, *SumMergeRequest) (*SumMergeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SumMerge not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) SumMigrate(context.Context, *SumMigrateRequest) (*SumMigrateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SumMigrate not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) ModMerge(context.Context, *ModMergeRequest) (*ModMergeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ModMerge not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Undo(context.Context, *UndoRequest) (*UndoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Undo not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) History(context.Context, *HistoryRequest) (*HistoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method History not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) CacheList(context.Context, *CacheListRequest) (*CacheListResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CacheList not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) CacheOrphans(context.Context, *CacheOrphansRequest) (*CacheOrphansResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CacheOrphans not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) CacheSeed(context.Context, *CacheSeedRequest) (*CacheSeedResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CacheSeed not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) CachePush(context.Context, *CachePushRequest) (*CachePushResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CachePush not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) HasEntry(context.Context, *HasEntryRequest) (*HasEntryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method HasEntry not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) FetchEntry(*FetchEntryRequest, grpc.ServerStreamingServer[FetchEntryChunk]) error {
+	return status.Error(codes.Unimplemented, "method FetchEntry not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) GetLogHead(context.Context, *GetLogHeadRequest) (*LogHead, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetLogHead not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) ProveLogInclusion(context.Context, *ProveLogInclusionRequest) (*ProveLogInclusionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ProveLogInclusion not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) ProveLogConsistency(context.Context, *ProveLogConsistencyRequest) (*ProveLogConsistencyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ProveLogConsistency not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Prefetch(context.Context, *PrefetchRequest) (*PrefetchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Prefetch not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) StartPull(context.Context, *PullRequest) (*Operation, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartPull not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) StartUpdate(context.Context, *UpdateRequest) (*Operation, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartUpdate not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) GetOperation(context.Context, *GetOperationRequest) (*Operation, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetOperation not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) WatchOperation(*GetOperationRequest, grpc.ServerStreamingServer[Operation]) error {
+	return status.Error(codes.Unimplemented, "method WatchOperation not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) CancelOperation(context.Context, *CancelOperationRequest) (*Operation, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelOperation not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) MirrorSync(context.Context, *MirrorSyncRequest) (*MirrorSyncResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method MirrorSync not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Reproduce(context.Context, *ReproduceRequest) (*ReproduceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Reproduce not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Impact(context.Context, *ImpactRequest) (*ImpactResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Impact not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Freshness(context.Context, *FreshnessRequest) (*FreshnessResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Freshness not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Error(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Diagnose(context.Context, *DiagnoseRequest) (*DiagnoseResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Diagnose not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Env(context.Context, *EnvRequest) (*EnvResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Env not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) ReplaceDiff(context.Context, *ReplaceDiffRequest) (*ReplaceDiffResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReplaceDiff not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) ReplacePromote(context.Context, *ReplacePromoteRequest) (*ReplacePromoteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReplacePromote not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) Tidy(context.Context, *TidyRequest) (*TidyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Tidy not implemented")
+}
+func (UnimplementedRhizomeAtlasServiceServer) mustEmbedUnimplementedRhizomeAtlasServiceServer() {}
+func (UnimplementedRhizomeAtlasServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeRhizomeAtlasServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RhizomeAtlasServiceServer will
+// result in compilation errors.
+type UnsafeRhizomeAtlasServiceServer interface {
+	mustEmbedUnimplementedRhizomeAtlasServiceServer()
+}
+
+func RegisterRhizomeAtlasServiceServer(s grpc.ServiceRegistrar, srv RhizomeAtlasServiceServer) {
+	// If the following call panics, it indicates UnimplementedRhizomeAtlasServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RhizomeAtlasService_ServiceDesc, srv)
+}
+
+func _RhizomeAtlasService_Init_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Init(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Init_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Init(ctx, req.(*InitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_New_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).New(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_New_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).New(ctx, req.(*NewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Add(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Add_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Add(ctx, req.(*AddRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_Resolve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Resolve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Resolve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Resolve(ctx, req.(*ResolveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Remove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Remove_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Remove(ctx, req.(*RemoveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_Pull_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PullRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Pull(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Pull_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Pull(ctx, req.(*PullRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_StreamPull_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PullRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RhizomeAtlasServiceServer).StreamPull(m, &grpc.GenericServerStream[PullRequest, PullProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RhizomeAtlasService_StreamPullServer = grpc.ServerStreamingServer[PullProgress]
+
+func _RhizomeAtlasService_Lock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Lock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Lock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Lock(ctx, req.(*LockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Verify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_VerifyAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyAllRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).VerifyAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_VerifyAll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).VerifyAll(ctx, req.(*VerifyAllRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_Graph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GraphRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Graph(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Graph_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Graph(ctx, req.(*GraphRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_StreamGraph_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamGraphRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RhizomeAtlasServiceServer).StreamGraph(m, &grpc.GenericServerStream[StreamGraphRequest, GraphChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RhizomeAtlasService_StreamGraphServer = grpc.ServerStreamingServer[GraphChunk]
+
+func _RhizomeAtlasService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_Vendor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VendorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Vendor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Vendor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Vendor(ctx, req.(*VendorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_StreamVendor_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamVendorRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RhizomeAtlasServiceServer).StreamVendor(m, &grpc.GenericServerStream[StreamVendorRequest, VendorProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RhizomeAtlasService_StreamVendorServer = grpc.ServerStreamingServer[VendorProgress]
+
+func _RhizomeAtlasService_CleanCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CleanCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).CleanCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_CleanCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).CleanCache(ctx, req.(*CleanCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_Describe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Describe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Describe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Describe(ctx, req.(*DescribeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_FindCapability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindCapabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).FindCapability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_FindCapability_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).FindCapability(ctx, req.(*FindCapabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_Owners_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OwnersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Owners(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Owners_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Owners(ctx, req.(*OwnersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_Release_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Release(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Release_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Release(ctx, req.(*ReleaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_BundleCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BundleCreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).BundleCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_BundleCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).BundleCreate(ctx, req.(*BundleCreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_BundleInstall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BundleInstallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).BundleInstall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_BundleInstall_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).BundleInstall(ctx, req.(*BundleInstallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_SumPrune_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SumPruneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).SumPrune(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_SumPrune_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).SumPrune(ctx, req.(*SumPruneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_SumMerge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SumMergeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).SumMerge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_SumMerge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).SumMerge(ctx, req.(*SumMergeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_SumMigrate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SumMigrateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).SumMigrate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_SumMigrate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).SumMigrate(ctx, req.(*SumMigrateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_ModMerge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModMergeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).ModMerge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_ModMerge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).ModMerge(ctx, req.(*ModMergeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_Undo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Undo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Undo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Undo(ctx, req.(*UndoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_History_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).History(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_History_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).History(ctx, req.(*HistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_CacheList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CacheListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).CacheList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_CacheList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).CacheList(ctx, req.(*CacheListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_CacheOrphans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CacheOrphansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).CacheOrphans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_CacheOrphans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).CacheOrphans(ctx, req.(*CacheOrphansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_CacheSeed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CacheSeedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).CacheSeed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_CacheSeed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).CacheSeed(ctx, req.(*CacheSeedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_CachePush_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CachePushRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).CachePush(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_CachePush_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).CachePush(ctx, req.(*CachePushRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_HasEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HasEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).HasEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_HasEntry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).HasEntry(ctx, req.(*HasEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_FetchEntry_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FetchEntryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RhizomeAtlasServiceServer).FetchEntry(m, &grpc.GenericServerStream[FetchEntryRequest, FetchEntryChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RhizomeAtlasService_FetchEntryServer = grpc.ServerStreamingServer[FetchEntryChunk]
+
+func _RhizomeAtlasService_GetLogHead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogHeadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).GetLogHead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_GetLogHead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).GetLogHead(ctx, req.(*GetLogHeadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_ProveLogInclusion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProveLogInclusionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).ProveLogInclusion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_ProveLogInclusion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).ProveLogInclusion(ctx, req.(*ProveLogInclusionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_ProveLogConsistency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProveLogConsistencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).ProveLogConsistency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_ProveLogConsistency_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).ProveLogConsistency(ctx, req.(*ProveLogConsistencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_Prefetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrefetchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).Prefetch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_Prefetch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).Prefetch(ctx, req.(*PrefetchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RhizomeAtlasService_StartPull_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PullRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).StartPull(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_StartPull_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).StartPull(ctx, req.(*PullRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *rhizomeAtlasServiceClient) CleanCache(ctx context.Context, in *CleanCacheRequest, opts ...grpc.CallOption) (*CleanCacheResponse, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(CleanCacheResponse)
-	err := c.cc.Invoke(ctx, RhizomeAtlasService_CleanCache_FullMethodName, in, out, cOpts...)
-	if err != nil {
+func _RhizomeAtlasService_StartUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).StartUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_StartUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).StartUpdate(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// RhizomeAtlasServiceServer is the server API for RhizomeAtlasService service.
-// All implementations must embed UnimplementedRhizomeAtlasServiceServer
-// for forward compatibility.
-//
-// RhizomeAtlasService manages holon dependencies.
-// It resolves, fetches, caches, and verifies dependencies
-// declared in holon.mod and holon.sum.
-type RhizomeAtlasServiceServer interface {
-	// Init creates a holon.mod file in the given directory.
-	Init(context.Context, *InitRequest) (*InitResponse, error)
-	// Add adds a dependency to holon.mod and fetches it.
-	Add(context.Context, *AddRequest) (*AddResponse, error)
-	// Remove removes a dependency from holon.mod.
-	Remove(context.Context, *RemoveRequest) (*RemoveResponse, error)
-	// Pull fetches all dependencies declared in holon.mod to the cache.
-	Pull(context.Context, *PullRequest) (*PullResponse, error)
-	// Verify checks holon.sum integrity against cached content.
-	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
-	// Graph returns the dependency tree.
-	Graph(context.Context, *GraphRequest) (*GraphResponse, error)
-	// Update updates dependencies to their latest compatible versions.
-	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
-	// Vendor copies cached dependencies to a local .holon/ directory.
-	Vendor(context.Context, *VendorRequest) (*VendorResponse, error)
-	// CleanCache purges the global holon cache (~/.holon/cache/).
-	CleanCache(context.Context, *CleanCacheRequest) (*CleanCacheResponse, error)
-	mustEmbedUnimplementedRhizomeAtlasServiceServer()
+func _RhizomeAtlasService_GetOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).GetOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_GetOperation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).GetOperation(ctx, req.(*GetOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// UnimplementedRhizomeAtlasServiceServer must be embedded to have
-// forward compatible implementations.
-//
-// NOTE: this should be embedded by value instead of pointer to avoid a nil
-// pointer dereference when methods are called.
-type UnimplementedRhizomeAtlasServiceServer struct{}
-
-func (UnimplementedRhizomeAtlasServiceServer) Init(context.Context, *InitRequest) (*InitResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Init not implemented")
-}
-func (UnimplementedRhizomeAtlasServiceServer) Add(context.Context, *AddRequest) (*AddResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Add not implemented")
-}
-func (UnimplementedRhizomeAtlasServiceServer) Remove(context.Context, *RemoveRequest) (*RemoveResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Remove not implemented")
-}
-func (UnimplementedRhizomeAtlasServiceServer) Pull(context.Context, *PullRequest) (*PullResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Pull not implemented")
-}
-func (UnimplementedRhizomeAtlasServiceServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Verify not implemented")
-}
-func (UnimplementedRhizomeAtlasServiceServer) Graph(context.Context, *GraphRequest) (*GraphResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Graph not implemented")
-}
-func (UnimplementedRhizomeAtlasServiceServer) Update(context.Context, *UpdateRequest) (*UpdateResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Update not implemented")
-}
-func (UnimplementedRhizomeAtlasServiceServer) Vendor(context.Context, *VendorRequest) (*VendorResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Vendor not implemented")
-}
-func (UnimplementedRhizomeAtlasServiceServer) CleanCache(context.Context, *CleanCacheRequest) (*CleanCacheResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method CleanCache not implemented")
+func _RhizomeAtlasService_WatchOperation_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetOperationRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RhizomeAtlasServiceServer).WatchOperation(m, &grpc.GenericServerStream[GetOperationRequest, Operation]{ServerStream: stream})
 }
-func (UnimplementedRhizomeAtlasServiceServer) mustEmbedUnimplementedRhizomeAtlasServiceServer() {}
-func (UnimplementedRhizomeAtlasServiceServer) testEmbeddedByValue()                             {}
 
-// UnsafeRhizomeAtlasServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to RhizomeAtlasServiceServer will
-// result in compilation errors.
-type UnsafeRhizomeAtlasServiceServer interface {
-	mustEmbedUnimplementedRhizomeAtlasServiceServer()
-}
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RhizomeAtlasService_WatchOperationServer = grpc.ServerStreamingServer[Operation]
 
-func RegisterRhizomeAtlasServiceServer(s grpc.ServiceRegistrar, srv RhizomeAtlasServiceServer) {
-	// If the following call panics, it indicates UnimplementedRhizomeAtlasServiceServer was
-	// embedded by pointer and is nil.  This will cause panics if an
-	// unimplemented method is ever invoked, so we test this at initialization
-	// time to prevent it from happening at runtime later due to I/O.
-	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
-		t.testEmbeddedByValue()
+func _RhizomeAtlasService_CancelOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	s.RegisterService(&RhizomeAtlasService_ServiceDesc, srv)
+	if interceptor == nil {
+		return srv.(RhizomeAtlasServiceServer).CancelOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RhizomeAtlasService_CancelOperation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RhizomeAtlasServiceServer).CancelOperation(ctx, req.(*CancelOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _RhizomeAtlasService_Init_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(InitRequest)
+func _RhizomeAtlasService_MirrorSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MirrorSyncRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(RhizomeAtlasServiceServer).Init(ctx, in)
+		return srv.(RhizomeAtlasServiceServer).MirrorSync(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: RhizomeAtlasService_Init_FullMethodName,
+		FullMethod: RhizomeAtlasService_MirrorSync_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RhizomeAtlasServiceServer).Init(ctx, req.(*InitRequest))
+		return srv.(RhizomeAtlasServiceServer).MirrorSync(ctx, req.(*MirrorSyncRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _RhizomeAtlasService_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AddRequest)
+func _RhizomeAtlasService_Reproduce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReproduceRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(RhizomeAtlasServiceServer).Add(ctx, in)
+		return srv.(RhizomeAtlasServiceServer).Reproduce(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: RhizomeAtlasService_Add_FullMethodName,
+		FullMethod: RhizomeAtlasService_Reproduce_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RhizomeAtlasServiceServer).Add(ctx, req.(*AddRequest))
+		return srv.(RhizomeAtlasServiceServer).Reproduce(ctx, req.(*ReproduceRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _RhizomeAtlasService_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RemoveRequest)
+func _RhizomeAtlasService_Impact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImpactRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(RhizomeAtlasServiceServer).Remove(ctx, in)
+		return srv.(RhizomeAtlasServiceServer).Impact(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: RhizomeAtlasService_Remove_FullMethodName,
+		FullMethod: RhizomeAtlasService_Impact_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RhizomeAtlasServiceServer).Remove(ctx, req.(*RemoveRequest))
+		return srv.(RhizomeAtlasServiceServer).Impact(ctx, req.(*ImpactRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _RhizomeAtlasService_Pull_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PullRequest)
+func _RhizomeAtlasService_Freshness_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FreshnessRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(RhizomeAtlasServiceServer).Pull(ctx, in)
+		return srv.(RhizomeAtlasServiceServer).Freshness(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: RhizomeAtlasService_Pull_FullMethodName,
+		FullMethod: RhizomeAtlasService_Freshness_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RhizomeAtlasServiceServer).Pull(ctx, req.(*PullRequest))
+		return srv.(RhizomeAtlasServiceServer).Freshness(ctx, req.(*FreshnessRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _RhizomeAtlasService_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(VerifyRequest)
+func _RhizomeAtlasService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RhizomeAtlasServiceServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RhizomeAtlasService_SubscribeServer = grpc.ServerStreamingServer[Event]
+
+func _RhizomeAtlasService_Diagnose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiagnoseRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(RhizomeAtlasServiceServer).Verify(ctx, in)
+		return srv.(RhizomeAtlasServiceServer).Diagnose(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: RhizomeAtlasService_Verify_FullMethodName,
+		FullMethod: RhizomeAtlasService_Diagnose_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RhizomeAtlasServiceServer).Verify(ctx, req.(*VerifyRequest))
+		return srv.(RhizomeAtlasServiceServer).Diagnose(ctx, req.(*DiagnoseRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _RhizomeAtlasService_Graph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GraphRequest)
+func _RhizomeAtlasService_Env_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnvRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(RhizomeAtlasServiceServer).Graph(ctx, in)
+		return srv.(RhizomeAtlasServiceServer).Env(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: RhizomeAtlasService_Graph_FullMethodName,
+		FullMethod: RhizomeAtlasService_Env_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RhizomeAtlasServiceServer).Graph(ctx, req.(*GraphRequest))
+		return srv.(RhizomeAtlasServiceServer).Env(ctx, req.(*EnvRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _RhizomeAtlasService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateRequest)
+func _RhizomeAtlasService_ReplaceDiff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplaceDiffRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(RhizomeAtlasServiceServer).Update(ctx, in)
+		return srv.(RhizomeAtlasServiceServer).ReplaceDiff(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: RhizomeAtlasService_Update_FullMethodName,
+		FullMethod: RhizomeAtlasService_ReplaceDiff_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RhizomeAtlasServiceServer).Update(ctx, req.(*UpdateRequest))
+		return srv.(RhizomeAtlasServiceServer).ReplaceDiff(ctx, req.(*ReplaceDiffRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _RhizomeAtlasService_Vendor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(VendorRequest)
+func _RhizomeAtlasService_ReplacePromote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplacePromoteRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(RhizomeAtlasServiceServer).Vendor(ctx, in)
+		return srv.(RhizomeAtlasServiceServer).ReplacePromote(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: RhizomeAtlasService_Vendor_FullMethodName,
+		FullMethod: RhizomeAtlasService_ReplacePromote_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RhizomeAtlasServiceServer).Vendor(ctx, req.(*VendorRequest))
+		return srv.(RhizomeAtlasServiceServer).ReplacePromote(ctx, req.(*ReplacePromoteRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _RhizomeAtlasService_CleanCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CleanCacheRequest)
+func _RhizomeAtlasService_Tidy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TidyRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(RhizomeAtlasServiceServer).CleanCache(ctx, in)
+		return srv.(RhizomeAtlasServiceServer).Tidy(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: RhizomeAtlasService_CleanCache_FullMethodName,
+		FullMethod: RhizomeAtlasService_Tidy_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RhizomeAtlasServiceServer).CleanCache(ctx, req.(*CleanCacheRequest))
+		return srv.(RhizomeAtlasServiceServer).Tidy(ctx, req.(*TidyRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -413,10 +2129,18 @@ var RhizomeAtlasService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Init",
 			Handler:    _RhizomeAtlasService_Init_Handler,
 		},
+		{
+			MethodName: "New",
+			Handler:    _RhizomeAtlasService_New_Handler,
+		},
 		{
 			MethodName: "Add",
 			Handler:    _RhizomeAtlasService_Add_Handler,
 		},
+		{
+			MethodName: "Resolve",
+			Handler:    _RhizomeAtlasService_Resolve_Handler,
+		},
 		{
 			MethodName: "Remove",
 			Handler:    _RhizomeAtlasService_Remove_Handler,
@@ -425,10 +2149,18 @@ var RhizomeAtlasService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Pull",
 			Handler:    _RhizomeAtlasService_Pull_Handler,
 		},
+		{
+			MethodName: "Lock",
+			Handler:    _RhizomeAtlasService_Lock_Handler,
+		},
 		{
 			MethodName: "Verify",
 			Handler:    _RhizomeAtlasService_Verify_Handler,
 		},
+		{
+			MethodName: "VerifyAll",
+			Handler:    _RhizomeAtlasService_VerifyAll_Handler,
+		},
 		{
 			MethodName: "Graph",
 			Handler:    _RhizomeAtlasService_Graph_Handler,
@@ -445,7 +2177,174 @@ var RhizomeAtlasService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CleanCache",
 			Handler:    _RhizomeAtlasService_CleanCache_Handler,
 		},
+		{
+			MethodName: "Describe",
+			Handler:    _RhizomeAtlasService_Describe_Handler,
+		},
+		{
+			MethodName: "FindCapability",
+			Handler:    _RhizomeAtlasService_FindCapability_Handler,
+		},
+		{
+			MethodName: "Owners",
+			Handler:    _RhizomeAtlasService_Owners_Handler,
+		},
+		{
+			MethodName: "Release",
+			Handler:    _RhizomeAtlasService_Release_Handler,
+		},
+		{
+			MethodName: "BundleCreate",
+			Handler:    _RhizomeAtlasService_BundleCreate_Handler,
+		},
+		{
+			MethodName: "BundleInstall",
+			Handler:    _RhizomeAtlasService_BundleInstall_Handler,
+		},
+		{
+			MethodName: "SumPrune",
+			Handler:    _RhizomeAtlasService_SumPrune_Handler,
+		},
+		{
+			MethodName: "SumMerge",
+			Handler:    _RhizomeAtlasService_SumMerge_Handler,
+		},
+		{
+			MethodName: "SumMigrate",
+			Handler:    _RhizomeAtlasService_SumMigrate_Handler,
+		},
+		{
+			MethodName: "ModMerge",
+			Handler:    _RhizomeAtlasService_ModMerge_Handler,
+		},
+		{
+			MethodName: "Undo",
+			Handler:    _RhizomeAtlasService_Undo_Handler,
+		},
+		{
+			MethodName: "History",
+			Handler:    _RhizomeAtlasService_History_Handler,
+		},
+		{
+			MethodName: "CacheList",
+			Handler:    _RhizomeAtlasService_CacheList_Handler,
+		},
+		{
+			MethodName: "CacheOrphans",
+			Handler:    _RhizomeAtlasService_CacheOrphans_Handler,
+		},
+		{
+			MethodName: "CacheSeed",
+			Handler:    _RhizomeAtlasService_CacheSeed_Handler,
+		},
+		{
+			MethodName: "CachePush",
+			Handler:    _RhizomeAtlasService_CachePush_Handler,
+		},
+		{
+			MethodName: "HasEntry",
+			Handler:    _RhizomeAtlasService_HasEntry_Handler,
+		},
+		{
+			MethodName: "GetLogHead",
+			Handler:    _RhizomeAtlasService_GetLogHead_Handler,
+		},
+		{
+			MethodName: "ProveLogInclusion",
+			Handler:    _RhizomeAtlasService_ProveLogInclusion_Handler,
+		},
+		{
+			MethodName: "ProveLogConsistency",
+			Handler:    _RhizomeAtlasService_ProveLogConsistency_Handler,
+		},
+		{
+			MethodName: "Prefetch",
+			Handler:    _RhizomeAtlasService_Prefetch_Handler,
+		},
+		{
+			MethodName: "StartPull",
+			Handler:    _RhizomeAtlasService_StartPull_Handler,
+		},
+		{
+			MethodName: "StartUpdate",
+			Handler:    _RhizomeAtlasService_StartUpdate_Handler,
+		},
+		{
+			MethodName: "GetOperation",
+			Handler:    _RhizomeAtlasService_GetOperation_Handler,
+		},
+		{
+			MethodName: "CancelOperation",
+			Handler:    _RhizomeAtlasService_CancelOperation_Handler,
+		},
+		{
+			MethodName: "MirrorSync",
+			Handler:    _RhizomeAtlasService_MirrorSync_Handler,
+		},
+		{
+			MethodName: "Reproduce",
+			Handler:    _RhizomeAtlasService_Reproduce_Handler,
+		},
+		{
+			MethodName: "Impact",
+			Handler:    _RhizomeAtlasService_Impact_Handler,
+		},
+		{
+			MethodName: "Freshness",
+			Handler:    _RhizomeAtlasService_Freshness_Handler,
+		},
+		{
+			MethodName: "Diagnose",
+			Handler:    _RhizomeAtlasService_Diagnose_Handler,
+		},
+		{
+			MethodName: "Env",
+			Handler:    _RhizomeAtlasService_Env_Handler,
+		},
+		{
+			MethodName: "ReplaceDiff",
+			Handler:    _RhizomeAtlasService_ReplaceDiff_Handler,
+		},
+		{
+			MethodName: "ReplacePromote",
+			Handler:    _RhizomeAtlasService_ReplacePromote_Handler,
+		},
+		{
+			MethodName: "Tidy",
+			Handler:    _RhizomeAtlasService_Tidy_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamPull",
+			Handler:       _RhizomeAtlasService_StreamPull_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamGraph",
+			Handler:       _RhizomeAtlasService_StreamGraph_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamVendor",
+			Handler:       _RhizomeAtlasService_StreamVendor_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "FetchEntry",
+			Handler:       _RhizomeAtlasService_FetchEntry_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchOperation",
+			Handler:       _RhizomeAtlasService_WatchOperation_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _RhizomeAtlasService_Subscribe_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "protos/rhizome_atlas/v1/rhizome_atlas.proto",
 }
@@ -4,12 +4,29 @@
 package cli
 
 import (
+	"bufio"
+	"cmp"
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
 	"github.com/organic-programming/rhizome-atlas/internal/server"
+	"github.com/organic-programming/rhizome-atlas/internal/web"
+	"github.com/organic-programming/rhizome-atlas/pkg/client"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
 )
 
 // Run executes the CLI with the given arguments.
@@ -25,26 +42,118 @@ func Run(args []string) int {
 	switch args[0] {
 	case "init":
 		return cmdInit(ctx, srv, args[1:])
+	case "new":
+		return cmdNew(ctx, srv, args[1:])
 	case "add":
 		return cmdAdd(ctx, srv, args[1:])
+	case "resolve":
+		return cmdResolve(ctx, srv, args[1:])
 	case "remove":
 		return cmdRemove(ctx, srv, args[1:])
 	case "pull":
 		return cmdPull(ctx, srv, args[1:])
+	case "lock":
+		return cmdLock(ctx, srv, args[1:])
 	case "verify":
 		return cmdVerify(ctx, srv, args[1:])
+	case "freshness":
+		return cmdFreshness(ctx, srv, args[1:])
+	case "impact":
+		return cmdImpact(ctx, srv, args[1:])
+	case "reproduce":
+		return cmdReproduce(ctx, srv, args[1:])
 	case "graph":
 		return cmdGraph(ctx, srv, args[1:])
+	case "describe":
+		return cmdDescribe(ctx, srv, args[1:])
+	case "capability":
+		return cmdCapability(ctx, srv, args[1:])
+	case "owners":
+		return cmdOwners(ctx, srv, args[1:])
+	case "release":
+		return cmdRelease(ctx, srv, args[1:])
 	case "update":
 		return cmdUpdate(ctx, srv, args[1:])
+	case "history":
+		return cmdHistory(ctx, srv, args[1:])
+	case "undo":
+		return cmdUndo(ctx, srv, args[1:])
 	case "vendor":
 		return cmdVendor(ctx, srv, args[1:])
+	case "tidy":
+		return cmdTidy(ctx, srv, args[1:])
 	case "cache":
 		if len(args) > 1 && args[1] == "clean" {
-			return cmdCacheClean(ctx, srv)
+			return cmdCacheClean(ctx, srv, args[2:])
+		}
+		if len(args) > 1 && args[1] == "list" {
+			return cmdCacheList(ctx, srv)
+		}
+		if len(args) > 1 && args[1] == "orphans" {
+			return cmdCacheOrphans(ctx, srv, args[2:])
+		}
+		if len(args) > 1 && args[1] == "push" {
+			return cmdCachePush(ctx, srv, args[2:])
+		}
+		if len(args) > 1 && args[1] == "seed" {
+			return cmdCacheSeed(ctx, srv, args[2:])
+		}
+		fmt.Fprintln(os.Stderr, "usage: atlas cache clean|list|orphans|push|seed")
+		return 1
+	case "sum":
+		if len(args) > 1 && args[1] == "prune" {
+			return cmdSumPrune(ctx, srv, args[2:])
+		}
+		if len(args) > 1 && args[1] == "merge" {
+			return cmdSumMerge(ctx, srv, args[2:])
+		}
+		if len(args) > 1 && args[1] == "migrate" {
+			return cmdSumMigrate(ctx, srv, args[2:])
+		}
+		fmt.Fprintln(os.Stderr, "usage: atlas sum prune [--dry-run] | merge <ours> <theirs> | migrate --to <alg>")
+		return 1
+	case "mod":
+		if len(args) > 1 && args[1] == "merge" {
+			return cmdModMerge(ctx, srv, args[2:])
+		}
+		fmt.Fprintln(os.Stderr, "usage: atlas mod merge <ours> <theirs> [<base>]")
+		return 1
+	case "replace":
+		if len(args) > 1 && args[1] == "diff" {
+			return cmdReplaceDiff(ctx, srv, args[2:])
+		}
+		if len(args) > 1 && args[1] == "promote" {
+			return cmdReplacePromote(ctx, srv, args[2:])
+		}
+		fmt.Fprintln(os.Stderr, replaceDiffUsage)
+		fmt.Fprintln(os.Stderr, replacePromoteUsage)
+		return 1
+	case "merge-driver":
+		return cmdMergeDriver(args[1:])
+	case "bundle":
+		if len(args) == 3 && args[1] == "create" {
+			return cmdBundleCreate(ctx, srv, args[2], "")
 		}
-		fmt.Fprintln(os.Stderr, "usage: atlas cache clean")
+		if len(args) == 5 && args[1] == "create" && args[3] == "--group" {
+			return cmdBundleCreate(ctx, srv, args[2], args[4])
+		}
+		if len(args) == 3 && args[1] == "install" {
+			return cmdBundleInstall(ctx, srv, args[2])
+		}
+		fmt.Fprintln(os.Stderr, "usage: atlas bundle create <out.bundle> [--group <name>] | install <in.bundle>")
+		return 1
+	case "mirror":
+		if len(args) > 1 && args[1] == "sync" {
+			return cmdMirrorSync(ctx, srv, args[2:])
+		}
+		fmt.Fprintln(os.Stderr, mirrorSyncUsage)
 		return 1
+	case "exec":
+		return cmdExec(ctx, srv, args[1:])
+	case "lsp":
+		return cmdLSP(ctx, srv, args[1:])
+	case "serve":
+		return cmdServe(srv, args[1:])
 	case "help", "--help", "-h":
 		printUsage()
 		return 0
@@ -56,86 +165,420 @@ func Run(args []string) int {
 }
 
 func cmdInit(ctx context.Context, srv *server.Server, args []string) int {
-	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "usage: atlas init <holon-path>")
+	req := &pb.InitRequest{Directory: "."}
+	if len(args) > 0 && args[0] == "--from-existing" {
+		req.FromExisting = true
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: atlas init [--from-existing] <holon-path>")
 		return 1
 	}
+	req.HolonPath = args[0]
 
-	resp, err := srv.Init(ctx, &pb.InitRequest{
-		Directory: ".",
-		HolonPath: args[0],
-	})
+	resp, err := srv.Init(ctx, req)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "atlas init: %v\n", err)
 		return 1
 	}
 	fmt.Printf("created %s\n", resp.ModFile)
+	for _, dep := range resp.Inferred {
+		fmt.Printf("  require %s %s (from %s)\n", dep.Path, dep.Version, dep.CachePath)
+	}
+	for _, u := range resp.Unresolved {
+		fmt.Fprintf(os.Stderr, "  %s\n", u)
+	}
+	return 0
+}
+
+func cmdNew(ctx context.Context, srv *server.Server, args []string) int {
+	usage := func() int {
+		fmt.Fprintln(os.Stderr, "usage: atlas new [--dir <dir>] [--name <name>] [--author <author>] [--var <key>=<value>]... <template>[@<version>] <holon-path>")
+		return 1
+	}
+	req := &pb.NewRequest{Vars: map[string]string{}}
+	var pos []string
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; a {
+		case "--dir", "--name", "--author", "--var":
+			if i+1 >= len(args) {
+				return usage()
+			}
+			i++
+			switch a {
+			case "--dir":
+				req.Directory = args[i]
+			case "--name":
+				req.Name = args[i]
+			case "--author":
+				req.Author = args[i]
+			default:
+				k, v, ok := strings.Cut(args[i], "=")
+				if !ok {
+					return usage()
+				}
+				req.Vars[k] = v
+			}
+		default:
+			pos = append(pos, a)
+		}
+	}
+	if len(pos) != 2 {
+		return usage()
+	}
+	req.Template, req.HolonPath = pos[0], pos[1]
+
+	resp, err := srv.New(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas new: %v\n", err)
+		return 1
+	}
+	fmt.Printf("created %s from %s@%s\n", req.HolonPath, resp.Template, resp.Version)
+	for _, f := range resp.Files {
+		fmt.Printf("  %s\n", f)
+	}
 	return 0
 }
 
 func cmdAdd(ctx context.Context, srv *server.Server, args []string) int {
-	if len(args) < 2 {
-		fmt.Fprintln(os.Stderr, "usage: atlas add <path> <version>")
+	req := &pb.AddRequest{Directory: "."}
+	var pos []string
+	for _, a := range args {
+		switch a {
+		case "--record-only":
+			req.RecordOnly = true
+		case "--dry-run":
+			req.DryRun = true
+		case "--force":
+			req.Force = true
+		case "--yes":
+			req.Trust = true
+		default:
+			pos = append(pos, a)
+		}
+	}
+	if len(pos) != 2 && (len(pos) != 4 || pos[2] != "as") {
+		fmt.Fprintln(os.Stderr, "usage: atlas add [--record-only] [--dry-run] [--force] [--yes] <path> <version|@channel|commit> [as <alias>]")
 		return 1
 	}
+	req.Path, req.Version = pos[0], pos[1]
+	if len(pos) == 4 {
+		req.Alias = pos[3]
+	}
 
-	resp, err := srv.Add(ctx, &pb.AddRequest{
-		Directory: ".",
-		Path:      args[0],
-		Version:   args[1],
-	})
+	resp, err := srv.Add(ctx, req)
+	if info := errorInfo(err); info != nil && info.Reason == client.ReasonTrustRequired {
+		// Show what would be trusted, then ask, unless nobody can answer.
+		md := info.Metadata
+		fmt.Fprintf(os.Stderr, "%s is used for the first time on this machine:\n", md["dependency"])
+		fmt.Fprintf(os.Stderr, "  hash:   %s\n  commit: %s\n  source: %s\n", md["hash"], orNone(md["commit"]), orNone(md["source"]))
+		if !confirm("Trust it?") {
+			fmt.Fprintln(os.Stderr, "atlas add: not trusted (use --yes to trust it)")
+			return 1
+		}
+		req.Trust, req.TrustHash = true, md["hash"]
+		resp, err = srv.Add(ctx, req)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "atlas add: %v\n", err)
 		return 1
 	}
+	printRoot(resp.Root)
 	dep := resp.Dependency
+	if resp.Plan != nil {
+		printPlan(resp.Plan)
+		return 0
+	}
 	if dep.CachePath != "" {
 		fmt.Printf("added %s@%s → %s\n", dep.Path, dep.Version, dep.CachePath)
 	} else {
 		fmt.Printf("added %s@%s (fetch deferred)\n", dep.Path, dep.Version)
 	}
+	if t := resp.Trusted; t != nil {
+		fmt.Printf("  trusted on first use: %s (commit %s, source %s)\n", t.Hash, orNone(t.Commit), orNone(t.Source))
+	}
+	return 0
+}
+
+// errorInfo returns the ErrorInfo details of err, if any.
+func errorInfo(err error) *errdetails.ErrorInfo {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Domain == client.ErrorDomain {
+			return info
+		}
+	}
+	return nil
+}
+
+// confirm asks question on stderr and reports whether it was answered
+// yes. Without a terminal to answer on, it is not.
+func confirm(question string) bool {
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func cmdResolve(ctx context.Context, srv *server.Server, args []string) int {
+	if len(args) < 1 || len(args) > 2 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintln(os.Stderr, "usage: atlas resolve <path> [<version|@channel|commit>]")
+		return 1
+	}
+	req := &pb.ResolveRequest{Directory: ".", Path: args[0]}
+	if len(args) == 2 {
+		req.Version = args[1]
+	}
+
+	resp, err := srv.Resolve(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas resolve: %v\n", err)
+		return 1
+	}
+	fmt.Printf("%s@%s\n", resp.Path, resp.Version)
+	if resp.SourcePath != "" {
+		fmt.Printf("  replaced by: %s\n", resp.SourcePath)
+	}
+	fmt.Printf("  source:      %s\n", orNone(resp.Source))
+	fmt.Printf("  commit:      %s\n", orNone(resp.Commit))
+	if resp.Cached {
+		fmt.Printf("  cached:      %s\n", resp.CachePath)
+	} else {
+		fmt.Println("  cached:      no")
+	}
 	return 0
 }
 
 func cmdRemove(ctx context.Context, srv *server.Server, args []string) int {
-	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "usage: atlas remove <path>")
+	req := &pb.RemoveRequest{Directory: "."}
+	var pos []string
+	for _, a := range args {
+		if a == "--dry-run" {
+			req.DryRun = true
+			continue
+		}
+		pos = append(pos, a)
+	}
+	if len(pos) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: atlas remove [--dry-run] <path|alias>")
 		return 1
 	}
+	req.Path = pos[0]
 
-	_, err := srv.Remove(ctx, &pb.RemoveRequest{
-		Directory: ".",
-		Path:      args[0],
-	})
+	resp, err := srv.Remove(ctx, req)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "atlas remove: %v\n", err)
 		return 1
 	}
-	fmt.Printf("removed %s\n", args[0])
+	printRoot(resp.Root)
+	if resp.Plan != nil {
+		printPlan(resp.Plan)
+		return 0
+	}
+	fmt.Printf("removed %s\n", req.Path)
 	return 0
 }
 
-func cmdPull(ctx context.Context, srv *server.Server, _ []string) int {
-	resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: "."})
+const pullUsage = "usage: atlas pull [--resolve mvs|highest] [--group <name>] [--locked] [--with <name|capability>]... [--repair] [--force] [--enforce-budget]"
+
+func cmdPull(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.PullRequest{Directory: "."}
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--repair" || args[i] == "--force" || args[i] == "--enforce-budget" || args[i] == "--locked" {
+			req.Repair = req.Repair || args[i] == "--repair"
+			req.Force = req.Force || args[i] == "--force"
+			req.EnforceBudget = req.EnforceBudget || args[i] == "--enforce-budget"
+			req.Locked = req.Locked || args[i] == "--locked"
+			continue
+		}
+		if i+1 >= len(args) {
+			fmt.Fprintln(os.Stderr, pullUsage)
+			return 1
+		}
+		switch args[i] {
+		case "--resolve":
+			req.Resolve = args[i+1]
+		case "--group":
+			req.Group = args[i+1]
+		case "--with":
+			req.With = append(req.With, args[i+1])
+		default:
+			fmt.Fprintln(os.Stderr, pullUsage)
+			return 1
+		}
+		i++
+	}
+
+	// Pull through the stream, to report each fetch as it goes.
+	var resp *pb.PullResponse
+	progress := pullProgress()
+	stream := newLocalStream(ctx, func(p *pb.PullProgress) error {
+		if p.Result != nil {
+			resp = p.Result
+			return nil
+		}
+		progress(p)
+		return nil
+	})
+	err := srv.StreamPull(req, stream)
+	progress(nil)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "atlas pull: %v\n", err)
 		return 1
 	}
+	printRoot(resp.Root)
 	for _, dep := range resp.Fetched {
+		if dep.Source != "" && !strings.HasPrefix(dep.Source, "https://"+dep.Path) {
+			fmt.Printf("  %s@%s → %s (via %s)\n", dep.Path, dep.Version, dep.CachePath, dep.Source)
+			continue
+		}
 		fmt.Printf("  %s@%s → %s\n", dep.Path, dep.Version, dep.CachePath)
 	}
 	if len(resp.Fetched) == 0 {
 		fmt.Println("all dependencies up to date")
 	}
+	printRepaired(resp.Repaired)
+	printOverBudget(resp.OverBudget)
+	for _, d := range resp.Deprecated {
+		printDeprecation(d)
+	}
+	for _, dep := range resp.Trusted {
+		fmt.Printf("  trusted on first use: %s@%s (source %s)\n", dep.Path, dep.Version, orNone(dep.Source))
+	}
+	for _, dep := range resp.Skipped {
+		fmt.Printf("  skipped optional %s@%s (--with %s)\n", dep.Path, dep.Version, cmp.Or(dep.Alias, dep.Path))
+	}
+
+	// Report the dependencies whose requirements disagreed.
+	for _, r := range resp.Resolved {
+		var reqs []string
+		disagree := false
+		for _, by := range r.RequiredBy {
+			reqs = append(reqs, by.Holon+" requires "+by.Version)
+			disagree = disagree || by.Version != r.Version
+		}
+		if disagree {
+			fmt.Printf("  %s: %s selected %s (%s)\n", r.Path, resp.Strategy, r.Version, strings.Join(reqs, ", "))
+		}
+	}
+	return 0
+}
+
+const lockUsage = "usage: atlas lock [--resolve mvs|highest] [--with <name|capability>]... [--check]"
+
+func cmdLock(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.LockRequest{Directory: "."}
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--check" {
+			req.DryRun = true
+			continue
+		}
+		if i+1 >= len(args) {
+			fmt.Fprintln(os.Stderr, lockUsage)
+			return 1
+		}
+		switch args[i] {
+		case "--resolve":
+			req.Resolve = args[i+1]
+		case "--with":
+			req.With = append(req.With, args[i+1])
+		default:
+			fmt.Fprintln(os.Stderr, lockUsage)
+			return 1
+		}
+		i++
+	}
+
+	resp, err := srv.Lock(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas lock: %v\n", err)
+		return 1
+	}
+	printRoot(resp.Root)
+	switch {
+	case req.DryRun && resp.Changed:
+		fmt.Fprintln(os.Stderr, "atlas lock: holon.lock is out of date — run 'atlas lock'")
+		return 1
+	case !resp.Changed:
+		fmt.Println("holon.lock up to date")
+		return 0
+	}
+	for _, r := range resp.Resolved {
+		fmt.Printf("  %s@%s\n", r.Path, r.Version)
+	}
+	fmt.Printf("holon.lock written (%s, %d dependencies)\n", resp.Strategy, len(resp.Resolved))
 	return 0
 }
 
-func cmdVerify(ctx context.Context, srv *server.Server, _ []string) int {
-	resp, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: "."})
+// pullProgress returns the function printing the progress of a pull to
+// stderr, called with nil once it is over. On a terminal, one status line
+// is redrawn as the fetches go; otherwise each fetch started is printed.
+func pullProgress() func(*pb.PullProgress) {
+	if fi, err := os.Stderr.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return func(p *pb.PullProgress) {
+			if p != nil && p.Stage == pb.PullStage_PULL_STAGE_STARTED {
+				fmt.Fprintf(os.Stderr, "  fetching %s@%s\n", p.Dependency.Path, p.Dependency.Version)
+			}
+		}
+	}
+	drawn := false
+	return func(p *pb.PullProgress) {
+		if p == nil {
+			if drawn {
+				fmt.Fprint(os.Stderr, "\r\033[K")
+			}
+			return
+		}
+		dep := p.Dependency.Path + "@" + p.Dependency.Version
+		switch p.Stage {
+		case pb.PullStage_PULL_STAGE_STARTED:
+			fmt.Fprintf(os.Stderr, "\r\033[K  fetching %s", dep)
+		case pb.PullStage_PULL_STAGE_TRANSFERRING:
+			fmt.Fprintf(os.Stderr, "\r\033[K  fetching %s (%d bytes)", dep, p.Bytes)
+		case pb.PullStage_PULL_STAGE_HASHED:
+			fmt.Fprintf(os.Stderr, "\r\033[K  hashed %s", dep)
+		case pb.PullStage_PULL_STAGE_DONE:
+			fmt.Fprintf(os.Stderr, "\r\033[K  recorded %s", dep)
+		default:
+			return
+		}
+		drawn = true
+	}
+}
+
+func cmdVerify(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.VerifyRequest{Directory: "."}
+	var rest []string
+	for _, a := range args {
+		switch a {
+		case "--remote":
+			req.Remote = true
+		case "--log":
+			req.Log = true
+		case "--repair":
+			req.Repair = true
+		case "--strict":
+			req.Strict = true
+		default:
+			rest = append(rest, a)
+		}
+	}
+	if len(rest) > 0 {
+		return cmdVerifyAll(ctx, srv, rest, req)
+	}
+
+	resp, err := srv.Verify(ctx, req)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "atlas verify: %v\n", err)
 		return 1
 	}
+	printRoot(resp.Root)
+	printRepaired(resp.Repaired)
+	for _, w := range resp.Warnings {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", w)
+	}
 	if resp.Ok {
 		fmt.Println("all verified")
 		return 0
@@ -146,61 +589,1375 @@ func cmdVerify(ctx context.Context, srv *server.Server, _ []string) int {
 	return 1
 }
 
-func cmdGraph(ctx context.Context, srv *server.Server, _ []string) int {
-	resp, err := srv.Graph(ctx, &pb.GraphRequest{Directory: "."})
+func cmdVerifyAll(ctx context.Context, srv *server.Server, args []string, one *pb.VerifyRequest) int {
+	req := &pb.VerifyAllRequest{Remote: one.Remote, Log: one.Log, Repair: one.Repair, Strict: one.Strict}
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--root" {
+			if i+1 >= len(args) {
+				fmt.Fprintln(os.Stderr, "usage: atlas verify [--remote] [--log] [--repair] [--strict] [--root <dir>] [<dir>...]")
+				return 1
+			}
+			req.Root = args[i+1]
+			i++
+			continue
+		}
+		req.Directories = append(req.Directories, args[i])
+	}
+
+	resp, err := srv.VerifyAll(ctx, req)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "atlas graph: %v\n", err)
+		fmt.Fprintf(os.Stderr, "atlas verify: %v\n", err)
+		return 1
+	}
+	for _, r := range resp.Results {
+		if r.Ok {
+			fmt.Printf("ok    %s\n", r.Directory)
+		} else {
+			fmt.Printf("FAIL  %s\n", r.Directory)
+		}
+		for _, w := range r.Warnings {
+			fmt.Fprintf(os.Stderr, "  WARNING: %s\n", w)
+		}
+		for _, e := range r.Errors {
+			fmt.Fprintf(os.Stderr, "  %s\n", e)
+		}
+	}
+	if !resp.Ok {
+		return 1
+	}
+	return 0
+}
+
+func cmdFreshness(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.FreshnessRequest{Directory: "."}
+	switch {
+	case len(args) == 0:
+	case len(args) == 2 && args[0] == "--stale-days":
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			fmt.Fprintf(os.Stderr, "atlas freshness: invalid --stale-days %q\n", args[1])
+			return 1
+		}
+		req.StaleDays = int32(n)
+	default:
+		fmt.Fprintln(os.Stderr, "usage: atlas freshness [--stale-days <n>]")
 		return 1
 	}
 
-	fmt.Println(resp.Root)
-	for _, edge := range resp.Edges {
-		fmt.Printf("  %s → %s@%s\n", edge.From, edge.To, edge.Version)
+	resp, err := srv.Freshness(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas freshness: %v\n", err)
+		return 1
+	}
+	stale := 0
+	for _, d := range resp.Dependencies {
+		mark := "ok   "
+		switch {
+		case d.Stale:
+			mark = "STALE"
+			stale++
+		case d.Error != "":
+			mark = "?    "
+		}
+		line := fmt.Sprintf("%s %s %s", mark, d.Path, d.Version)
+		switch {
+		case d.VersionsBehind == 0 && d.Error == "":
+			line += " (latest)"
+		case d.VersionsBehind > 0:
+			line += fmt.Sprintf(" → %s: %d behind", d.Latest, d.VersionsBehind)
+			if d.LatestTime != "" {
+				line += fmt.Sprintf(", %d days", d.DaysBehind)
+			}
+		}
+		fmt.Println(line)
+		if d.Error != "" {
+			fmt.Fprintf(os.Stderr, "  %s\n", d.Error)
+		}
+		if d.Deprecation != nil {
+			printDeprecation(d.Deprecation)
+		}
+	}
+	for _, e := range resp.Expired {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", e)
+	}
+	if stale > 0 {
+		fmt.Printf("%d of %d dependencies more than %d days behind\n", stale, len(resp.Dependencies), resp.StaleDays)
+		return 1
 	}
 	return 0
 }
 
-func cmdUpdate(ctx context.Context, srv *server.Server, _ []string) int {
-	resp, err := srv.Update(ctx, &pb.UpdateRequest{Directory: "."})
+func cmdImpact(ctx context.Context, srv *server.Server, args []string) int {
+	var path, version string
+	if len(args) == 1 {
+		if i := strings.LastIndex(args[0], "@"); i > 0 {
+			path, version = args[0][:i], args[0][i+1:]
+		}
+	}
+	if path == "" || version == "" {
+		fmt.Fprintln(os.Stderr, "usage: atlas impact <path|alias>@<version>")
+		return 1
+	}
+	resp, err := srv.Impact(ctx, &pb.ImpactRequest{Directory: ".", Path: path, Version: version})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "atlas update: %v\n", err)
+		fmt.Fprintf(os.Stderr, "atlas impact: %v\n", err)
 		return 1
 	}
-	if len(resp.Updated) == 0 {
-		fmt.Println("all dependencies at latest compatible version")
+
+	fmt.Printf("impact of %s %s → %s\n", resp.Path, orNone(resp.OldVersion), resp.NewVersion)
+	if len(resp.Changes) > 0 {
+		fmt.Println("requirements:")
+		for _, c := range resp.Changes {
+			switch {
+			case c.OldVersion == "":
+				fmt.Printf("  %s now requires %s %s\n", c.Holon, c.Path, c.NewVersion)
+			case c.NewVersion == "":
+				fmt.Printf("  %s no longer requires %s %s\n", c.Holon, c.Path, c.OldVersion)
+			default:
+				fmt.Printf("  %s requires %s %s → %s\n", c.Holon, c.Path, c.OldVersion, c.NewVersion)
+			}
+		}
+	}
+	if len(resp.Selections) > 0 {
+		fmt.Println("selected versions:")
+		for _, sel := range resp.Selections {
+			line := fmt.Sprintf("  %s %s → %s", sel.Path, orNone(sel.OldVersion), orNone(sel.NewVersion))
+			if len(sel.Raised) > 0 {
+				line += " (raises the requirement of " + strings.Join(sel.Raised, ", ") + ")"
+			}
+			fmt.Println(line)
+		}
+	}
+	if len(resp.Unresolved) > 0 {
+		fmt.Println("requirements unknown, holon.mod not read:")
+		for _, u := range resp.Unresolved {
+			fmt.Printf("  %s\n", u)
+		}
+	}
+	if len(resp.Conflicts) > 0 {
+		fmt.Println("conflicts:")
+		for _, c := range resp.Conflicts {
+			var reqs []string
+			for _, e := range c.RequiredBy {
+				reqs = append(reqs, e.From+" requires "+e.Version)
+			}
+			fmt.Printf("  %s: %s\n", c.Path, strings.Join(reqs, ", "))
+		}
+		return 1
+	}
+	return 0
+}
+
+func cmdReproduce(ctx context.Context, srv *server.Server, args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "usage: atlas reproduce")
+		return 1
+	}
+	resp, err := srv.Reproduce(ctx, &pb.ReproduceRequest{Directory: "."})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas reproduce: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("reproducibility report, %s\n", resp.Time)
+	reproduced := 0
+	for _, r := range resp.Results {
+		if r.Reproduced {
+			reproduced++
+			fmt.Printf("ok    %s@%s\n", r.Path, r.Version)
+		} else {
+			fmt.Printf("FAIL  %s@%s\n", r.Path, r.Version)
+		}
+		fmt.Printf("        holon.sum: %s\n", orNone(r.SumHash))
+		fmt.Printf("        cache:     %s\n", orNone(r.CacheHash))
+		fmt.Printf("        upstream:  %s\n", orNone(r.UpstreamHash))
+		if r.Source != "" {
+			fmt.Printf("        source:    %s\n", r.Source)
+		}
+		for _, p := range r.Problems {
+			fmt.Printf("        %s\n", p)
+		}
+	}
+	fmt.Printf("%d of %d dependencies reproduced\n", reproduced, len(resp.Results))
+	if !resp.Reproducible {
+		return 1
+	}
+	return 0
+}
+
+// orNone returns s, or "(none)" if it is empty.
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func cmdGraph(ctx context.Context, srv *server.Server, args []string) int {
+	usage := func() int {
+		fmt.Fprintln(os.Stderr, "usage: atlas graph [--where <key>=<value>]... [--serve <addr>]")
+		return 1
+	}
+	var serveAddr string
+	filter := map[string]string{}
+	for i := 0; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return usage()
+		}
+		switch args[i] {
+		case "--serve":
+			serveAddr = args[i+1]
+		case "--where":
+			k, v, ok := strings.Cut(args[i+1], "=")
+			if !ok {
+				return usage()
+			}
+			filter[k] = v
+		default:
+			return usage()
+		}
+	}
+
+	if serveAddr != "" {
+		fmt.Printf("serving dependency graph on %s\n", serveAddr)
+		if err := http.ListenAndServe(serveAddr, web.GraphHandler(srv, ".")); err != nil {
+			fmt.Fprintf(os.Stderr, "atlas graph: %v\n", err)
+			return 1
+		}
 		return 0
 	}
-	for _, u := range resp.Updated {
-		fmt.Printf("  %s: %s → %s\n", u.Path, u.OldVersion, u.NewVersion)
+
+	printed := false
+	stream := newLocalStream(ctx, func(chunk *pb.GraphChunk) error {
+		if !printed {
+			fmt.Println(chunk.Root)
+			printed = true
+		}
+		for _, edge := range chunk.Edges {
+			if edge.Alias != "" {
+				fmt.Printf("  %s → %s@%s (%s)\n", edge.From, edge.To, edge.Version, edge.Alias)
+			} else {
+				fmt.Printf("  %s → %s@%s\n", edge.From, edge.To, edge.Version)
+			}
+		}
+		return nil
+	})
+	if err := srv.StreamGraph(&pb.StreamGraphRequest{Directory: ".", Filter: filter}, stream); err != nil {
+		fmt.Fprintf(os.Stderr, "atlas graph: %v\n", err)
+		return 1
 	}
 	return 0
 }
 
-func cmdVendor(ctx context.Context, srv *server.Server, _ []string) int {
-	resp, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: "."})
+func cmdDescribe(ctx context.Context, srv *server.Server, args []string) int {
+	if len(args) > 2 {
+		fmt.Fprintln(os.Stderr, "usage: atlas describe [<path|alias> [<version>]]")
+		return 1
+	}
+	req := &pb.DescribeRequest{Directory: "."}
+	if len(args) > 0 {
+		req.Path = args[0]
+	}
+	if len(args) > 1 {
+		req.Version = args[1]
+	}
+
+	resp, err := srv.Describe(ctx, req)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "atlas vendor: %v\n", err)
+		fmt.Fprintf(os.Stderr, "atlas describe: %v\n", err)
 		return 1
 	}
-	for _, dep := range resp.Vendored {
-		fmt.Printf("  %s@%s → %s\n", dep.Path, dep.Version, dep.CachePath)
+	h := resp.Holon
+	if h.Version != "" {
+		fmt.Printf("%s@%s\n", h.Path, h.Version)
+	} else {
+		fmt.Println(h.Path)
 	}
-	if len(resp.Vendored) == 0 {
-		fmt.Println("nothing to vendor")
+	fmt.Printf("  name:         %s\n", h.Name)
+	fmt.Printf("  summary:      %s\n", h.Summary)
+	fmt.Printf("  maintainers:  %s\n", strings.Join(h.Maintainers, ", "))
+	if h.Owner != "" || h.Contact != "" {
+		fmt.Printf("  owner:        %s\n", ownerLine(h.Owner, h.Contact))
+	}
+	fmt.Printf("  capabilities: %s\n", strings.Join(h.Capabilities, ", "))
+	if p := h.Provenance; p != nil {
+		fmt.Printf("  builder:      %s\n", p.Builder)
+		fmt.Printf("  source:       %s @ %s\n", p.Source, p.Commit)
+		if p.Workflow != "" {
+			fmt.Printf("  workflow:     %s\n", p.Workflow)
+		}
+	}
+	if f := h.Fetch; f != nil {
+		fmt.Printf("  fetched:      %s from %s (%s)\n", f.Time, f.Source, f.Fetcher)
+		if f.TagObject != "" {
+			fmt.Printf("  commit:       %s (tag object %s)\n", f.Commit, f.TagObject)
+		} else if f.Commit != "" {
+			fmt.Printf("  commit:       %s\n", f.Commit)
+		}
 	}
 	return 0
 }
 
-func cmdCacheClean(ctx context.Context, srv *server.Server) int {
-	resp, err := srv.CleanCache(ctx, &pb.CleanCacheRequest{})
+func cmdOwners(ctx context.Context, srv *server.Server, args []string) int {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "usage: atlas owners [<path|alias>]")
+		return 1
+	}
+	req := &pb.OwnersRequest{Directory: "."}
+	if len(args) == 1 {
+		req.Path = args[0]
+	}
+
+	resp, err := srv.Owners(ctx, req)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "atlas cache clean: %v\n", err)
+		fmt.Fprintf(os.Stderr, "atlas owners: %v\n", err)
 		return 1
 	}
-	fmt.Printf("purged %s\n", resp.CachePath)
+	for _, h := range resp.Holons {
+		fmt.Printf("%s@%s\n", h.Path, h.Version)
+		if h.Owner == "" && h.Contact == "" && len(h.Maintainers) == 0 {
+			fmt.Println("  (no owner declared)")
+		}
+		if h.Owner != "" || h.Contact != "" {
+			fmt.Printf("  owner:        %s\n", ownerLine(h.Owner, h.Contact))
+		}
+		if len(h.Maintainers) > 0 {
+			fmt.Printf("  maintainers:  %s\n", strings.Join(h.Maintainers, ", "))
+		}
+		if h.LocalOwner != "" {
+			fmt.Printf("  local owner:  %s\n", h.LocalOwner)
+		}
+	}
+	return 0
+}
+
+// ownerLine renders an owner and how to reach them.
+func ownerLine(owner, contact string) string {
+	switch {
+	case contact == "":
+		return owner
+	case owner == "":
+		return "<" + contact + ">"
+	}
+	return owner + " <" + contact + ">"
+}
+
+func cmdCapability(ctx context.Context, srv *server.Server, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: atlas capability <name>")
+		return 1
+	}
+
+	resp, err := srv.FindCapability(ctx, &pb.FindCapabilityRequest{Directory: ".", Capability: args[0]})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas capability: %v\n", err)
+		return 1
+	}
+	if len(resp.Providers) == 0 {
+		fmt.Printf("no dependency provides %s\n", args[0])
+		return 1
+	}
+	for _, p := range resp.Providers {
+		fmt.Printf("  %s@%s\n", p.Path, p.Version)
+	}
 	return 0
 }
 
+const updateUsage = "usage: atlas update [--allow-breaking] [--dry-run] [--channel <name>] [--changelog <file>]\n" +
+	"                    [--branch [--batch] [--push]]"
+
+func cmdUpdate(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.UpdateRequest{Directory: "."}
+	var changelog string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--allow-breaking":
+			req.AllowBreaking = true
+		case "--dry-run":
+			req.DryRun = true
+		case "--branch":
+			if req.Branching == pb.UpdateBranching_UPDATE_BRANCHING_UNSPECIFIED {
+				req.Branching = pb.UpdateBranching_UPDATE_BRANCHING_PER_DEPENDENCY
+			}
+		case "--batch":
+			req.Branching = pb.UpdateBranching_UPDATE_BRANCHING_BATCH
+		case "--push":
+			req.Push = true
+		case "--channel", "--changelog":
+			if i+1 >= len(args) {
+				fmt.Fprintln(os.Stderr, updateUsage)
+				return 1
+			}
+			if args[i] == "--channel" {
+				req.Channel = args[i+1]
+			} else {
+				changelog, req.Changelog = args[i+1], true
+			}
+			i++
+		default:
+			fmt.Fprintln(os.Stderr, updateUsage)
+			return 1
+		}
+	}
+
+	resp, err := srv.Update(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas update: %v\n", err)
+		return 1
+	}
+	printRoot(resp.Root)
+	for _, m := range resp.Moved {
+		fmt.Fprintf(os.Stderr, "WARNING: tag %s of %s moved upstream from commit %s to %s\n",
+			m.Version, m.Path, m.PinnedCommit, m.UpstreamCommit)
+	}
+	if changelog == "-" {
+		defer fmt.Print("\n" + resp.Changelog)
+	} else if changelog != "" {
+		if err := os.WriteFile(changelog, []byte(resp.Changelog), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "atlas update: %v\n", err)
+			return 1
+		}
+	}
+	if resp.Plan != nil {
+		printPlan(resp.Plan)
+		return 0
+	}
+	if len(resp.Updated) == 0 && len(resp.Held) == 0 {
+		fmt.Println("all dependencies at latest compatible version")
+		return 0
+	}
+	for _, u := range resp.Updated {
+		fmt.Printf("  %s: %s → %s\n", u.Path, u.OldVersion, u.NewVersion)
+		if len(u.RemovedCapabilities) > 0 {
+			fmt.Printf("    removed capabilities: %s\n", strings.Join(u.RemovedCapabilities, ", "))
+		}
+	}
+	for _, b := range resp.Branches {
+		pushed := ""
+		if b.Pushed {
+			pushed = ", pushed"
+		}
+		fmt.Printf("  branch %s at %.12s%s\n", b.Name, b.Commit, pushed)
+	}
+	if len(resp.Held) == 0 {
+		return 0
+	}
+	fmt.Fprintln(os.Stderr, "breaking updates held back:")
+	for _, u := range resp.Held {
+		if u.ContractError != "" {
+			fmt.Fprintf(os.Stderr, "  %s: %s → %s could not be checked: %s\n",
+				u.Path, u.OldVersion, u.NewVersion, u.ContractError)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "  %s: %s → %s removes %s\n",
+			u.Path, u.OldVersion, u.NewVersion, strings.Join(u.RemovedCapabilities, ", "))
+	}
+	fmt.Fprintln(os.Stderr, "rerun with --allow-breaking to apply them")
+	return 1
+}
+
+const releaseUsage = "usage: atlas release [--patch|--minor|--major] [--push] [--publish]\n" +
+	"                     [--builder <id> [--workflow <ref>] | --provenance <file>]"
+
+func cmdRelease(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.ReleaseRequest{Directory: "."}
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; a {
+		case "--patch":
+			req.Bump = pb.ReleaseBump_RELEASE_BUMP_PATCH
+		case "--minor":
+			req.Bump = pb.ReleaseBump_RELEASE_BUMP_MINOR
+		case "--major":
+			req.Bump = pb.ReleaseBump_RELEASE_BUMP_MAJOR
+		case "--push":
+			req.Push = true
+		case "--publish":
+			req.Publish = true
+		case "--builder", "--workflow", "--provenance":
+			if i+1 >= len(args) {
+				fmt.Fprintln(os.Stderr, releaseUsage)
+				return 1
+			}
+			i++
+			switch a {
+			case "--builder":
+				req.Builder = args[i]
+			case "--workflow":
+				req.Workflow = args[i]
+			default:
+				data, err := os.ReadFile(args[i])
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "atlas release: %v\n", err)
+					return 1
+				}
+				req.Provenance = data
+			}
+		default:
+			fmt.Fprintln(os.Stderr, releaseUsage)
+			return 1
+		}
+	}
+
+	resp, err := srv.Release(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas release: %v\n", err)
+		return 1
+	}
+	if resp.PreviousVersion != "" {
+		fmt.Printf("tagged %s (was %s)\n", resp.Version, resp.PreviousVersion)
+	} else {
+		fmt.Printf("tagged %s\n", resp.Version)
+	}
+	if resp.Attested {
+		fmt.Printf("attached provenance to %s\n", resp.Version)
+	}
+	if resp.Pushed {
+		fmt.Printf("pushed %s to origin\n", resp.Version)
+	}
+	if resp.Published != "" {
+		fmt.Printf("published %s to %s\n", resp.Version, resp.Published)
+	}
+	return 0
+}
+
+func cmdUndo(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.UndoRequest{Directory: "."}
+	for _, a := range args {
+		if a != "--restore-cache" {
+			fmt.Fprintln(os.Stderr, "usage: atlas undo [--restore-cache]")
+			return 1
+		}
+		req.RestoreCache = true
+	}
+
+	resp, err := srv.Undo(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas undo: %v\n", err)
+		return 1
+	}
+	fmt.Printf("undid %s from %s\n", resp.Method, resp.Time)
+	for _, dep := range resp.Restored {
+		fmt.Printf("  restored %s@%s → %s\n", dep.Path, dep.Version, dep.CachePath)
+	}
+	return 0
+}
+
+func cmdHistory(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.HistoryRequest{Directory: "."}
+	if len(args) == 2 && args[0] == "-n" {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			fmt.Fprintln(os.Stderr, "usage: atlas history [-n <count>]")
+			return 1
+		}
+		req.Limit = int32(n)
+	} else if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, "usage: atlas history [-n <count>]")
+		return 1
+	}
+
+	resp, err := srv.History(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas history: %v\n", err)
+		return 1
+	}
+	if len(resp.Entries) == 0 {
+		fmt.Println("no recorded history")
+		return 0
+	}
+	for _, e := range resp.Entries {
+		fmt.Printf("%s  %s\n", e.Time, e.Method)
+		for _, c := range e.Changes {
+			fmt.Printf("    %s\n", c)
+		}
+	}
+	return 0
+}
+
+func cmdVendor(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.VendorRequest{Directory: "."}
+	keepGoing := false
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--dry-run":
+			req.DryRun = true
+		case args[i] == "--keep-going":
+			keepGoing = true
+		case args[i] == "--enforce-budget":
+			req.EnforceBudget = true
+		case args[i] == "--group" && i+1 < len(args):
+			i++
+			req.Group = args[i]
+		default:
+			fmt.Fprintln(os.Stderr, "usage: atlas vendor [--dry-run] [--keep-going] [--enforce-budget] [--group <name>]")
+			return 1
+		}
+	}
+
+	if req.DryRun {
+		resp, err := srv.Vendor(ctx, req)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "atlas vendor: %v\n", err)
+			return 1
+		}
+		printRoot(resp.Root)
+		printPlan(resp.Plan)
+		printOverBudget(resp.OverBudget)
+		return 0
+	}
+
+	// Copy through the stream, to report each dependency as it is done.
+	var summary *pb.VendorSummary
+	stream := newLocalStream(ctx, func(p *pb.VendorProgress) error {
+		if p.Summary != nil {
+			summary = p.Summary
+			return nil
+		}
+		dep := p.Dependency
+		if p.Error != "" {
+			fmt.Fprintf(os.Stderr, "  [%d/%d] %s@%s: %s\n", p.Index, p.Total, dep.Path, dep.Version, p.Error)
+			return nil
+		}
+		fmt.Printf("  [%d/%d] %s@%s → %s (%s)\n", p.Index, p.Total, dep.Path, dep.Version, dep.CachePath, dep.Group)
+		return nil
+	})
+	err := srv.StreamVendor(&pb.StreamVendorRequest{Directory: req.Directory, Group: req.Group, KeepGoing: keepGoing, EnforceBudget: req.EnforceBudget}, stream)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas vendor: %v\n", err)
+		return 1
+	}
+	printRoot(summary.Root)
+	printOverBudget(summary.OverBudget)
+	switch {
+	case summary.Failed > 0:
+		fmt.Fprintf(os.Stderr, "vendored %d dependencies (%d bytes), %d failed\n", summary.Vendored, summary.Bytes, summary.Failed)
+		return 1
+	case summary.Vendored == 0:
+		fmt.Println("nothing to vendor")
+	default:
+		fmt.Printf("vendored %d dependencies (%d bytes)\n", summary.Vendored, summary.Bytes)
+	}
+	return 0
+}
+
+const replaceDiffUsage = "usage: atlas replace diff [--stat] <path|alias>"
+
+// cmdReplaceDiff prints, as a unified diff, the local modifications a
+// replace carries: from the version required, cached, to the replace
+// directory.
+func cmdReplaceDiff(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.ReplaceDiffRequest{Directory: "."}
+	for _, arg := range args {
+		switch {
+		case arg == "--stat":
+			req.Stat = true
+		case req.Path == "" && !strings.HasPrefix(arg, "-"):
+			req.Path = arg
+		default:
+			fmt.Fprintln(os.Stderr, replaceDiffUsage)
+			return 1
+		}
+	}
+	if req.Path == "" {
+		fmt.Fprintln(os.Stderr, replaceDiffUsage)
+		return 1
+	}
+
+	resp, err := srv.ReplaceDiff(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas replace diff: %v\n", err)
+		return 1
+	}
+	printRoot(resp.Root)
+	fmt.Fprintf(os.Stderr, "comparing %s@%s (%s) with %s\n", resp.Path, resp.Version, resp.CachePath, resp.ReplaceDir)
+	var added, deleted int32
+	for _, f := range resp.Files {
+		added, deleted = added+f.Added, deleted+f.Deleted
+		switch {
+		case req.Stat && f.Binary:
+			fmt.Printf("  %s %s (binary)\n", fileChangeLetter[f.Change], f.Path)
+		case req.Stat:
+			fmt.Printf("  %s %s +%d -%d\n", fileChangeLetter[f.Change], f.Path, f.Added, f.Deleted)
+		case f.Binary:
+			fmt.Printf("Binary files a/%s and b/%s differ\n", f.Path, f.Path)
+		default:
+			fmt.Print(f.Diff)
+		}
+	}
+	if len(resp.Files) == 0 {
+		fmt.Fprintln(os.Stderr, "no local modifications")
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "%d files changed, +%d -%d lines\n", len(resp.Files), added, deleted)
+	return 0
+}
+
+const replacePromoteUsage = "usage: atlas replace promote [--keep-path] [--dry-run] [--force] [--yes] <path|alias> --to <fork>@<version>"
+
+// cmdReplacePromote moves a dependency from its local replace to the fork
+// its patches were published to, then lists the files the fork and the
+// local directory still differ in.
+func cmdReplacePromote(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.ReplacePromoteRequest{Directory: "."}
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--keep-path":
+			req.KeepPath = true
+		case arg == "--dry-run":
+			req.DryRun = true
+		case arg == "--force":
+			req.Force = true
+		case arg == "--yes":
+			req.Trust = true
+		case arg == "--to" && i+1 < len(args):
+			i++
+			at := strings.LastIndex(args[i], "@")
+			if at <= 0 {
+				fmt.Fprintln(os.Stderr, replacePromoteUsage)
+				return 1
+			}
+			req.To, req.Version = args[i][:at], args[i][at+1:]
+		case req.Path == "" && !strings.HasPrefix(arg, "-"):
+			req.Path = arg
+		default:
+			fmt.Fprintln(os.Stderr, replacePromoteUsage)
+			return 1
+		}
+	}
+	if req.Path == "" || req.To == "" {
+		fmt.Fprintln(os.Stderr, replacePromoteUsage)
+		return 1
+	}
+
+	resp, err := srv.ReplacePromote(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas replace promote: %v\n", err)
+		return 1
+	}
+	printRoot(resp.Root)
+	if resp.Plan != nil {
+		printPlan(resp.Plan)
+		return 0
+	}
+	dep := resp.Dependency
+	fmt.Printf("require %s %s → %s\n", dep.Path, dep.Version, dep.CachePath)
+	if resp.Replace != "" {
+		fmt.Printf("replace %s\n", resp.Replace)
+	}
+	if len(resp.Differences) == 0 {
+		fmt.Printf("the fork matches %s\n", resp.ReplaceDir)
+		return 0
+	}
+	fmt.Printf("the fork differs from %s in:\n", resp.ReplaceDir)
+	for _, f := range resp.Differences {
+		fmt.Printf("  %s %s\n", fileChangeLetter[f.Change], f.Path)
+	}
+	return 0
+}
+
+// fileChangeLetter abbreviates a FileChange as git's --name-status does.
+var fileChangeLetter = map[pb.FileChange]string{
+	pb.FileChange_FILE_CHANGE_ADDED:    "A",
+	pb.FileChange_FILE_CHANGE_DELETED:  "D",
+	pb.FileChange_FILE_CHANGE_MODIFIED: "M",
+}
+
+const execUsage = "usage: atlas exec [--group <name>] -- <command> [<arg>...]"
+
+// cmdExec runs a command with HOLON_ROOT naming the holon's directory and
+// a HOLON_DEP_<NAME> variable naming the directory of each dependency, and
+// exits with its status.
+func cmdExec(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.EnvRequest{Directory: "."}
+	for len(args) > 0 && args[0] != "--" {
+		if args[0] != "--group" || len(args) < 2 {
+			fmt.Fprintln(os.Stderr, execUsage)
+			return 1
+		}
+		req.Group = args[1]
+		args = args[2:]
+	}
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, execUsage)
+		return 1
+	}
+
+	resp, err := srv.Env(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas exec: %v\n", err)
+		return 1
+	}
+	root, err := filepath.Abs(resp.Root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas exec: %v\n", err)
+		return 1
+	}
+	cmd := exec.CommandContext(ctx, args[1], args[2:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), "HOLON_ROOT="+root)
+	for _, dep := range resp.Dependencies {
+		cmd.Env = append(cmd.Env, dep.Variable+"="+dep.Dir)
+	}
+
+	// The command gets the interrupts of the terminal itself.
+	signal.Ignore(os.Interrupt)
+	err = cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas exec: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func cmdCacheClean(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.CleanCacheRequest{}
+	for _, a := range args {
+		if a != "--dry-run" {
+			fmt.Fprintln(os.Stderr, "usage: atlas cache clean [--dry-run]")
+			return 1
+		}
+		req.DryRun = true
+	}
+
+	resp, err := srv.CleanCache(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas cache clean: %v\n", err)
+		return 1
+	}
+	if resp.Plan != nil {
+		printPlan(resp.Plan)
+		return 0
+	}
+	fmt.Printf("purged %s\n", resp.CachePath)
+	return 0
+}
+
+func cmdCacheList(ctx context.Context, srv *server.Server) int {
+	req := &pb.CacheListRequest{}
+	count := 0
+	for {
+		resp, err := srv.CacheList(ctx, req)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "atlas cache list: %v\n", err)
+			return 1
+		}
+		for _, e := range resp.Entries {
+			if e.Fetch != nil && e.Fetch.Commit != "" {
+				fmt.Printf("  %s@%s  %d bytes  commit %s\n", e.Path, e.Version, e.Size, e.Fetch.Commit)
+				continue
+			}
+			fmt.Printf("  %s@%s  %d bytes\n", e.Path, e.Version, e.Size)
+		}
+		count += len(resp.Entries)
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+	if count == 0 {
+		fmt.Println("cache is empty")
+	}
+	return 0
+}
+
+func cmdCacheOrphans(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.CacheOrphansRequest{}
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--delete":
+			req.Delete = true
+		case args[i] == "--root" && i+1 < len(args):
+			req.Root = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "-"):
+			fmt.Fprintln(os.Stderr, "usage: atlas cache orphans [--delete] [--root <dir>] [<dir>...]")
+			return 1
+		default:
+			req.Directories = append(req.Directories, args[i])
+		}
+	}
+	if req.Root == "" && len(req.Directories) == 0 {
+		req.Root = "."
+	}
+
+	resp, err := srv.CacheOrphans(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas cache orphans: %v\n", err)
+		return 1
+	}
+	for _, e := range resp.Orphans {
+		fmt.Printf("  %s@%s  %d bytes\n", e.Path, e.Version, e.Size)
+	}
+	switch {
+	case len(resp.Orphans) == 0:
+		fmt.Printf("no orphans among the entries referenced by %d holon.sum files\n", len(resp.SumFiles))
+	case req.Delete:
+		fmt.Printf("deleted %d orphans, %d bytes\n", len(resp.Orphans), resp.Bytes)
+	default:
+		fmt.Printf("%d orphans, %d bytes; --delete removes them\n", len(resp.Orphans), resp.Bytes)
+	}
+	return 0
+}
+
+func cmdCachePush(ctx context.Context, srv *server.Server, args []string) int {
+	if len(args) != 2 || !strings.Contains(args[0], "@") {
+		fmt.Fprintln(os.Stderr, "usage: atlas cache push <path@version> oci://<registry>/<repository>[:<tag>]")
+		return 1
+	}
+	path, version, _ := strings.Cut(args[0], "@")
+	resp, err := srv.CachePush(ctx, &pb.CachePushRequest{Path: path, Version: version, Target: args[1]})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas cache push: %v\n", err)
+		return 1
+	}
+	fmt.Printf("pushed %s to %s\n", args[0], resp.Reference)
+	fmt.Printf("  digest %s, %d bytes\n", resp.Digest, resp.Size)
+	keys := make([]string, 0, len(resp.Annotations))
+	for k := range resp.Annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("  %s=%s\n", k, resp.Annotations[k])
+	}
+	return 0
+}
+
+func cmdCacheSeed(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.CacheSeedRequest{Directory: "."}
+	for _, a := range args {
+		if a != "--dry-run" {
+			fmt.Fprintln(os.Stderr, "usage: atlas cache seed [--dry-run]")
+			return 1
+		}
+		req.DryRun = true
+	}
+
+	resp, err := srv.CacheSeed(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas cache seed: %v\n", err)
+		return 1
+	}
+	printRoot(resp.Root)
+	if resp.Plan != nil {
+		printPlan(resp.Plan)
+		return 0
+	}
+	for _, dep := range resp.Seeded {
+		fmt.Printf("  + %s@%s\n", dep.Path, dep.Version)
+	}
+	fmt.Printf("seeded %d vendored copies verified against holon.sum, %d already cached\n", len(resp.Seeded), len(resp.Present))
+	return 0
+}
+
+// printOverBudget warns of the size budgets exceeded.
+func printOverBudget(over []*pb.BudgetExceeded) {
+	for _, b := range over {
+		if b.Dependency == "" {
+			fmt.Fprintf(os.Stderr, "WARNING: dependencies total %d bytes, over the total budget of %d\n", b.Size, b.Budget)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "WARNING: %s is %d bytes, over its budget of %d\n", b.Dependency, b.Size, b.Budget)
+	}
+}
+
+// printDeprecation warns of a deprecated dependency, with its successor.
+func printDeprecation(d *pb.Deprecation) {
+	what := d.Path + "@" + d.Version
+	if d.Holon {
+		what = d.Path
+	}
+	line := fmt.Sprintf("WARNING: %s is deprecated (as of %s)", what, d.DeclaredBy)
+	if d.Message != "" {
+		line += ": " + d.Message
+	}
+	if d.Successor != "" {
+		line += "; use " + d.Successor + " instead"
+	}
+	fmt.Fprintln(os.Stderr, line)
+}
+
+// printPlan prints what a dry run would have done.
+func printPlan(plan *pb.Plan) {
+	for _, c := range plan.Changes {
+		fmt.Printf("  %s\n", c)
+	}
+	for _, dep := range plan.Fetch {
+		fmt.Printf("  fetch %s@%s\n", dep.Path, dep.Version)
+	}
+	for _, f := range plan.Write {
+		fmt.Printf("  write %s\n", f)
+	}
+	for _, f := range plan.Delete {
+		fmt.Printf("  delete %s\n", f)
+	}
+	if plan.Bytes > 0 {
+		fmt.Printf("  %d bytes\n", plan.Bytes)
+	}
+	fmt.Println("dry run, nothing changed")
+}
+
+// defaultListenURI is where "atlas serve" listens without --listen.
+const defaultListenURI = "tcp://:9090"
+
+func cmdSumPrune(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.SumPruneRequest{Directory: "."}
+	for _, a := range args {
+		if a != "--dry-run" {
+			fmt.Fprintln(os.Stderr, "usage: atlas sum prune [--dry-run]")
+			return 1
+		}
+		req.DryRun = true
+	}
+
+	resp, err := srv.SumPrune(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas sum prune: %v\n", err)
+		return 1
+	}
+	if len(resp.Removed) == 0 {
+		fmt.Println("holon.sum has no stale entries")
+		return 0
+	}
+	for _, e := range resp.Removed {
+		fmt.Printf("  - %s %s\n", e.Path, e.Version)
+	}
+	if req.DryRun {
+		fmt.Printf("%d stale entries (dry run, holon.sum unchanged)\n", len(resp.Removed))
+	} else {
+		fmt.Printf("pruned %d stale entries\n", len(resp.Removed))
+	}
+	return 0
+}
+
+func cmdTidy(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.TidyRequest{Directory: "."}
+	for _, a := range args {
+		switch a {
+		case "--dry-run":
+			req.DryRun = true
+		case "--sum-only":
+			req.KeepRequires = true
+		default:
+			fmt.Fprintln(os.Stderr, "usage: atlas tidy [--dry-run] [--sum-only]")
+			return 1
+		}
+	}
+
+	resp, err := srv.Tidy(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas tidy: %v\n", err)
+		return 1
+	}
+	printRoot(resp.Root)
+	if len(resp.Dropped)+len(resp.Removed)+len(resp.Added) == 0 {
+		fmt.Println("holon.mod and holon.sum are tidy")
+		return 0
+	}
+	for _, dep := range resp.Dropped {
+		fmt.Printf("  - require %s %s\n", dep.Path, dep.Version)
+	}
+	for _, e := range resp.Removed {
+		fmt.Printf("  - %s %s\n", e.Path, e.Version)
+	}
+	for _, e := range resp.Added {
+		fmt.Printf("  + %s %s\n", e.Path, e.Version)
+	}
+	if req.DryRun {
+		fmt.Printf("%d requires unused, %d holon.sum entries stale, %d missing (dry run, nothing written)\n",
+			len(resp.Dropped), len(resp.Removed), len(resp.Added))
+	} else {
+		fmt.Printf("dropped %d unused requires, removed %d stale holon.sum entries, added %d\n",
+			len(resp.Dropped), len(resp.Removed), len(resp.Added))
+	}
+	return 0
+}
+
+func cmdSumMigrate(ctx context.Context, srv *server.Server, args []string) int {
+	usage := func() int {
+		fmt.Fprintln(os.Stderr, "usage: atlas sum migrate --to <alg> [--drop-old] [--dry-run]")
+		return 1
+	}
+	req := &pb.SumMigrateRequest{Directory: "."}
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--to" && i+1 < len(args):
+			req.To = args[i+1]
+			i++
+		case args[i] == "--drop-old":
+			req.DropOld = true
+		case args[i] == "--dry-run":
+			req.DryRun = true
+		default:
+			return usage()
+		}
+	}
+	if req.To == "" {
+		return usage()
+	}
+
+	resp, err := srv.SumMigrate(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas sum migrate: %v\n", err)
+		return 1
+	}
+	for _, e := range resp.Added {
+		fmt.Printf("  + %s %s %s\n", e.Path, e.Version, e.Hash)
+	}
+	for _, e := range resp.Removed {
+		fmt.Printf("  - %s %s %s\n", e.Path, e.Version, e.Hash)
+	}
+	verb := "migrated"
+	if req.DryRun {
+		verb = "would migrate"
+	}
+	fmt.Printf("%s holon.sum: %d entries added, %d removed\n", verb, len(resp.Added), len(resp.Removed))
+	return 0
+}
+
+func cmdBundleCreate(ctx context.Context, srv *server.Server, out, group string) int {
+	resp, err := srv.BundleCreate(ctx, &pb.BundleCreateRequest{Directory: ".", Output: out, Group: group})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas bundle create: %v\n", err)
+		return 1
+	}
+	for _, dep := range resp.Dependencies {
+		fmt.Printf("  %s@%s\n", dep.Path, dep.Version)
+	}
+	fmt.Printf("wrote %s (%d dependencies)\n", resp.Output, len(resp.Dependencies))
+	return 0
+}
+
+func cmdBundleInstall(ctx context.Context, srv *server.Server, in string) int {
+	resp, err := srv.BundleInstall(ctx, &pb.BundleInstallRequest{Input: in, Directory: "."})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas bundle install: %v\n", err)
+		return 1
+	}
+	for _, dep := range resp.Installed {
+		fmt.Printf("  %s@%s → %s\n", dep.Path, dep.Version, dep.CachePath)
+	}
+	if resp.ModFile != "" {
+		fmt.Printf("wrote %s\n", resp.ModFile)
+	}
+	fmt.Printf("installed %d verified dependencies\n", len(resp.Installed))
+	return 0
+}
+
+const mirrorSyncUsage = "usage: atlas mirror sync --from <proxy|dir> --to <dir> --paths <glob>[,<glob>...] [--latest <n>] [--every <duration>]"
+
+func cmdMirrorSync(ctx context.Context, srv *server.Server, args []string) int {
+	req := &pb.MirrorSyncRequest{}
+	var every time.Duration
+	for i := 0; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			fmt.Fprintln(os.Stderr, mirrorSyncUsage)
+			return 1
+		}
+		v := args[i+1]
+		switch args[i] {
+		case "--from":
+			req.From = v
+		case "--to":
+			req.To = v
+		case "--paths":
+			for _, glob := range strings.Split(v, ",") {
+				if glob = strings.TrimSpace(glob); glob != "" {
+					req.Paths = append(req.Paths, glob)
+				}
+			}
+		case "--latest":
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				fmt.Fprintln(os.Stderr, mirrorSyncUsage)
+				return 1
+			}
+			req.Latest = int32(n)
+		case "--every":
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				fmt.Fprintln(os.Stderr, mirrorSyncUsage)
+				return 1
+			}
+			every = d
+		default:
+			fmt.Fprintln(os.Stderr, mirrorSyncUsage)
+			return 1
+		}
+	}
+	if req.From == "" || req.To == "" || len(req.Paths) == 0 {
+		fmt.Fprintln(os.Stderr, mirrorSyncUsage)
+		return 1
+	}
+
+	// With --every, sync again on schedule until interrupted; a sync that
+	// fails is reported and retried at the next tick.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	for {
+		code := mirrorSyncOnce(ctx, srv, req)
+		if every == 0 {
+			return code
+		}
+		select {
+		case <-time.After(every):
+		case <-ctx.Done():
+			return 0
+		}
+	}
+}
+
+func mirrorSyncOnce(ctx context.Context, srv *server.Server, req *pb.MirrorSyncRequest) int {
+	resp, err := srv.MirrorSync(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas mirror sync: %v\n", err)
+		return 1
+	}
+	for _, h := range resp.Holons {
+		if len(h.Added) > 0 {
+			fmt.Printf("  %s: added %s\n", h.Path, strings.Join(h.Added, ", "))
+		} else {
+			fmt.Printf("  %s: up to date (%s)\n", h.Path, strings.Join(h.Versions, ", "))
+		}
+	}
+	for _, e := range resp.Errors {
+		fmt.Fprintf(os.Stderr, "  %s\n", e)
+	}
+	if len(resp.Holons) == 0 && len(resp.Errors) == 0 {
+		fmt.Println("no matching holons")
+	}
+	if len(resp.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func cmdServe(srv *server.Server, args []string) int {
+	listenURI := defaultListenURI
+	var webAddr string
+	var dirs []string
+	var tlsOpts server.TLSOptions
+	var checkCache, quarantine bool
+	opts := server.ServeOptions{Reflection: true}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--check-cache":
+			checkCache = true
+		case "--quarantine":
+			checkCache, quarantine = true, true
+		case "--read-only":
+			opts.ReadOnly = true
+		case "--listen", "--max-msg-size", "--auth", "--web", "--tls-cert", "--tls-key", "--tls-min-version", "--tls-ciphers", "--tls-client-ca", "--tls-client-auth":
+			if i+1 >= len(args) {
+				fmt.Fprintln(os.Stderr, "usage: atlas serve [--listen <URI>] [--max-msg-size <bytes>] [--read-only] [--auth <file>] [--check-cache [--quarantine]] [--web <addr> [<dir>...]] [--tls-cert <file> --tls-key <file> [--tls-min-version 1.2|1.3] [--tls-ciphers <name>,...] [--tls-client-ca <file>] [--tls-client-auth <mode>]]")
+				return 1
+			}
+			v := args[i+1]
+			switch args[i] {
+			case "--listen":
+				listenURI = v
+			case "--max-msg-size":
+				size, err := server.ParseMessageSize(v)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "atlas serve: %v\n", err)
+					return 1
+				}
+				opts.MaxRecvMsgSize, opts.MaxSendMsgSize = size, size
+			case "--auth":
+				auth, err := server.LoadAuthConfig(v)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "atlas serve: %v\n", err)
+					return 1
+				}
+				opts.Auth = auth
+			case "--web":
+				webAddr = v
+			case "--tls-cert":
+				tlsOpts.CertFile = v
+			case "--tls-key":
+				tlsOpts.KeyFile = v
+			case "--tls-min-version":
+				tlsOpts.MinVersion = v
+			case "--tls-ciphers":
+				tlsOpts.CipherSuites = server.ParseCipherSuites(v)
+			case "--tls-client-ca":
+				tlsOpts.ClientCAFile = v
+			case "--tls-client-auth":
+				tlsOpts.ClientAuth = v
+			}
+			i++
+		default:
+			dirs = append(dirs, args[i])
+		}
+	}
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+
+	var tlsCfg *tls.Config
+	if tlsOpts.Enabled() {
+		var err error
+		if tlsCfg, err = tlsOpts.Config(); err != nil {
+			fmt.Fprintf(os.Stderr, "atlas serve: %v\n", err)
+			return 1
+		}
+	}
+
+	if checkCache {
+		repairs, err := srv.CheckCache(quarantine)
+		for _, r := range repairs {
+			fmt.Fprintf(os.Stderr, "atlas: cache: %s %s (%s, %d bytes)\n", r.Action, r.Path, r.Problem, r.Size)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "atlas serve: check cache: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "atlas: cache checked, %d repaired\n", len(repairs))
+	}
+
+	if webAddr != "" {
+		go func() {
+			fmt.Fprintf(os.Stderr, "atlas: dashboard on %s\n", webAddr)
+			hs := &http.Server{Addr: webAddr, Handler: web.DashboardHandler(srv, dirs), TLSConfig: tlsCfg}
+			var err error
+			if tlsCfg != nil {
+				err = hs.ListenAndServeTLS("", "")
+			} else {
+				err = hs.ListenAndServe()
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "atlas serve: dashboard: %v\n", err)
+			}
+		}()
+	}
+
+	opts.TLS = tlsCfg
+	if err := srv.Serve(listenURI, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "atlas serve: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// printRepaired lists the cached snapshots that were fetched again
+// because they no longer matched holon.sum.
+func printRepaired(deps []*pb.Dependency) {
+	for _, dep := range deps {
+		fmt.Printf("  repaired %s@%s: fetched again, it matches holon.sum\n", dep.Path, dep.Version)
+	}
+}
+
+// printRoot tells, on stderr, which holon.mod a command acted on when it
+// was found in a parent of the current directory.
+func printRoot(root string) {
+	if root != "" && root != "." {
+		fmt.Fprintf(os.Stderr, "using %s\n", filepath.Join(root, "holon.mod"))
+	}
+}
+
 func printUsage() {
 	fmt.Fprintf(os.Stderr, `Rhizome Atlas — holon dependency manager
 
@@ -208,16 +1965,133 @@ Usage:
   atlas <command> [arguments]
 
 Commands:
-  init <holon-path>            create holon.mod in current directory
-  add <path> <version>         add a dependency
-  remove <path>                remove a dependency
-  pull                         fetch all dependencies to cache
-  update                       update deps to latest compatible version
-  verify                       check holon.sum integrity
-  graph                        display dependency tree
-  vendor                       copy cached deps to local .holon/
-  cache clean                  purge the global cache
-  serve [--listen <URI>]       start gRPC server
+  init [--from-existing] <holon-path>
+                               create holon.mod in current directory,
+                               requiring the deps vendored in .holon/
+  new [--dir <dir>] [--name <name>] [--author <author>] [--var <key>=<value>]...
+    <template>[@<version>] <holon-path>
+                               create a holon from a template, substituting
+                               {{holon_path}}, {{name}}, {{author}}, {{<key>}}
+  add [--record-only] [--dry-run] [--force] <path> <version|@channel|commit>
+    [as <alias>]               fetch and add a dependency (or only record it),
+                               --force: even if it does not match holon.sum;
+                               a commit is pinned by pseudo-version
+  resolve <path> [<version|@channel|commit>]
+                               report the version, source, commit and cache
+                               state an add would record, changing nothing
+  remove [--dry-run] <path|alias>
+                               remove a dependency
+  pull [--resolve mvs|highest] [--group <name>] [--with <name|capability>]...
+    [--locked] [--repair] [--force] [--enforce-budget]
+                               fetch all dependencies (of a require group)
+                               to cache, optional ones only when asked for;
+                               --locked: fail unless holon.lock is current;
+                               --repair: fetch again those whose cached
+                               snapshot no longer matches holon.sum;
+                               --force: keep those fetched not matching it;
+                               --enforce-budget: fail over a size budget
+  lock [--resolve mvs|highest] [--with <name|capability>]... [--check]
+                               resolve the graph again into holon.lock;
+                               --check: fail if it would change
+  update [--allow-breaking] [--dry-run] [--channel <name>] [--changelog <file>]
+         [--branch [--batch] [--push]]
+                               update deps to latest compatible version (and
+                               write their release notes as markdown to file,
+                               - for stdout); --branch commits each update to
+                               a branch of its own (--batch: all to one) and
+                               --push pushes them to origin
+  verify [--remote] [--log] [--repair] [--strict] [--root <dir>] [<dir>...]
+                               check holon.sum integrity (of several holons),
+                               --remote: against a fresh upstream fetch too,
+                               --log: against the checksum log of the
+                               ATLAS_CACHE_REMOTE server too,
+                               --repair: fetching mismatching snapshots again,
+                               --strict: failing on warnings too
+  freshness [--stale-days <n>]
+                               show how far each dependency is behind its
+                               latest release; fails if one is more than n
+                               days (default 90) behind
+  impact <path|alias>@<version>
+                               report how requiring that version would change
+                               the resolved graph, without modifying anything
+  reproduce                    re-fetch every dependency from upstream and
+                               compare its hash with holon.sum and the cache
+  graph [--where <key>=<value>]... [--serve <addr>]
+                               display dependency tree (or serve it as a web page)
+  describe [<path|alias> [<version>]]
+                               show HOLON.md metadata of this holon or a dep,
+                               the provenance of the dep's release, and the
+                               commit and source it was fetched from
+  capability <name>            list dependencies providing a capability
+  owners [<path|alias>]        show who owns and maintains each dependency
+  release [--patch|--minor|--major] [--push] [--publish]
+    [--builder <id> [--workflow <ref>] | --provenance <file>]
+                               tag the next version of this holon, with a
+                               provenance attestation of its build;
+                               --publish: push it to the registry
+  history [-n <count>]         show the changes made to holon.mod/holon.sum
+  undo [--restore-cache]       revert holon.mod/holon.sum to before the last change
+  vendor [--dry-run] [--keep-going] [--enforce-budget] [--group <name>]
+                               copy cached deps (of a require group) to
+                               local .holon/ (past those failing)
+  tidy [--dry-run] [--sum-only]
+                               drop the requires nothing references, then
+                               bring holon.sum to the deps still required
+  sum prune [--dry-run]        drop holon.sum entries no longer required
+  sum migrate --to <alg> [--drop-old] [--dry-run]
+                               record holon.sum entries with another hash
+                               algorithm too (and drop the old ones)
+  sum merge <ours> <theirs>    union two holon.sum files into ours
+  mod merge <ours> <theirs> [<base>]
+                               merge two holon.mod files into ours
+  replace diff [--stat] <path|alias>
+                               show the local modifications a replace
+                               carries, against the version required
+  replace promote [--keep-path] [--dry-run] [--force] [--yes] <path|alias>
+    --to <fork>@<version>      require the published fork instead of the
+                               local replace (or replace with it remotely)
+  merge-driver install         register the holon.mod and holon.sum git merge drivers
+  bundle create <out.bundle> [--group <name>]
+                               package holon.mod, holon.sum and the cached
+                               deps (of a require group)
+  bundle install <in.bundle>   verify a bundle and install it into the cache
+  cache clean [--dry-run]      purge the global cache
+  cache list                   list the global cache
+  cache orphans [--delete] [--root <dir>] [<dir>...]
+                               list (or delete) the cache entries no holon.sum
+                               of the holons under root (.) or in the
+                               directories references
+  cache push <path@version> oci://<registry>/<repository>[:<tag>]
+                               export a cache entry as an OCI artifact
+  cache seed [--dry-run]       install the vendored copies matching holon.sum
+                               into the cache, offline
+  mirror sync --from <proxy|dir> --to <dir> --paths <glob>[,<glob>...]
+    [--latest <n>] [--every <duration>]
+                               copy the latest n (3) versions of matching
+                               holons into a local mirror (on a schedule)
+  exec [--group <name>] -- <command> [<arg>...]
+                               run a command with HOLON_DEP_<NAME> set to the
+                               directory of each dependency (cache, vendored
+                               copy or local replace) and HOLON_ROOT
+  lsp [--remote]               serve holon.mod diagnostics and quick fixes to
+                               editors over LSP on stdin/stdout (--remote:
+                               checking upstream versions on open and save)
+  serve [--listen <URI>] [--web <addr> [<dir>...]]
+                               start gRPC server (and web dashboard)
+    [--max-msg-size <bytes>]   … accepting messages up to that size (4M),
+                               e.g. 64M for the graphs of big workspaces
+    [--read-only]              … refusing the RPCs that write anything
+    [--auth <file>]            … allowing each token or client certificate
+                               the methods the file grants it
+    [--tls-cert <file> --tls-key <file>]
+                               … over TLS (1.2 or later)
+    [--tls-min-version 1.2|1.3] [--tls-ciphers <name>,...]
+    [--tls-client-ca <file>] [--tls-client-auth <mode>]
+                               … with these TLS settings; a client CA
+                               requires verified client certificates
+    [--check-cache [--quarantine]]
+                               … after removing (or quarantining) what
+                               interrupted fetches left in the cache
 
 `)
 }
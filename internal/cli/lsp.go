@@ -0,0 +1,344 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/internal/server"
+)
+
+// cmdLSP serves the Language Server Protocol on stdin and stdout for
+// holon.mod files: each open one is diagnosed by Diagnose as it changes,
+// and the fixes of its diagnostics are offered as quick fixes. With
+// --remote, upstream tags are checked too when a file is opened or saved.
+func cmdLSP(ctx context.Context, srv *server.Server, args []string) int {
+	remote := false
+	for _, a := range args {
+		if a != "--remote" {
+			fmt.Fprintln(os.Stderr, "usage: atlas lsp [--remote]")
+			return 1
+		}
+		remote = true
+	}
+	l := &lspServer{srv: srv, remote: remote, out: os.Stdout, docs: map[string]*lspDoc{}}
+	if err := l.serve(ctx, bufio.NewReader(os.Stdin)); err != nil {
+		fmt.Fprintf(os.Stderr, "atlas lsp: %v\n", err)
+		return 1
+	}
+	return l.exitCode
+}
+
+// lspServer is the state of an LSP session.
+type lspServer struct {
+	srv      *server.Server
+	remote   bool
+	out      io.Writer
+	docs     map[string]*lspDoc // open holon.mod files by URI
+	shutdown bool
+	exitCode int
+}
+
+// lspDoc is an open holon.mod and its last diagnostics.
+type lspDoc struct {
+	text  string
+	diags []*pb.Diagnostic
+}
+
+type lspMessage struct {
+	ID     *json.RawMessage `json:"id,omitempty"`
+	Method string           `json:"method,omitempty"`
+	Params json.RawMessage  `json:"params,omitempty"`
+}
+
+type lspResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  any              `json:"result"`
+	Error   *lspError        `json:"error,omitempty"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspCodeAction struct {
+	Title       string          `json:"title"`
+	Kind        string          `json:"kind"`
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+	Edit        struct {
+		Changes map[string][]lspTextEdit `json:"changes"`
+	} `json:"edit"`
+}
+
+type lspDocumentParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+	Text  *string  `json:"text"` // of didSave, when includeText
+	Range lspRange `json:"range"`
+}
+
+// lspSeverities maps diagnostic severities to those of LSP.
+var lspSeverities = map[pb.DiagnosticSeverity]int{
+	pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_ERROR:   1,
+	pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_WARNING: 2,
+	pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_INFO:    3,
+}
+
+// serve handles the messages read from in until the client exits or in
+// ends.
+func (l *lspServer) serve(ctx context.Context, in *bufio.Reader) error {
+	for {
+		data, err := readLSPMessage(in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var msg lspMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf("invalid message: %v", err)
+		}
+		if msg.Method == "exit" {
+			if !l.shutdown {
+				l.exitCode = 1
+			}
+			return nil
+		}
+		result, rerr := l.handle(ctx, msg)
+		if msg.ID == nil {
+			continue
+		}
+		if err := l.write(lspResponse{JSONRPC: "2.0", ID: msg.ID, Result: result, Error: rerr}); err != nil {
+			return err
+		}
+	}
+}
+
+// handle runs the request or notification msg and returns its result.
+func (l *lspServer) handle(ctx context.Context, msg lspMessage) (any, *lspError) {
+	var params lspDocumentParams
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &lspError{Code: -32602, Message: err.Error()}
+		}
+	}
+	uri := params.TextDocument.URI
+
+	switch msg.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   map[string]any{"openClose": true, "change": 1, "save": map[string]bool{"includeText": true}},
+				"codeActionProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "atlas"},
+		}, nil
+	case "shutdown":
+		l.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		l.diagnose(ctx, uri, params.TextDocument.Text, l.remote)
+	case "textDocument/didChange":
+		if n := len(params.ContentChanges); n > 0 {
+			l.diagnose(ctx, uri, params.ContentChanges[n-1].Text, false)
+		}
+	case "textDocument/didSave":
+		if doc, ok := l.docs[uri]; ok {
+			text := doc.text
+			if params.Text != nil {
+				text = *params.Text
+			}
+			l.diagnose(ctx, uri, text, l.remote)
+		}
+	case "textDocument/didClose":
+		if _, ok := l.docs[uri]; ok {
+			delete(l.docs, uri)
+			l.publish(uri, []lspDiagnostic{})
+		}
+	case "textDocument/codeAction":
+		return l.codeActions(uri, params.Range), nil
+	default:
+		if msg.ID != nil {
+			return nil, &lspError{Code: -32601, Message: "method not found: " + msg.Method}
+		}
+	}
+	return nil, nil
+}
+
+// diagnose runs Diagnose on text, the content of the holon.mod at uri,
+// and publishes its diagnostics. Other files are ignored.
+func (l *lspServer) diagnose(ctx context.Context, uri, text string, remote bool) {
+	path, ok := lspPath(uri)
+	if !ok || filepath.Base(path) != "holon.mod" {
+		return
+	}
+	doc := &lspDoc{text: text}
+	l.docs[uri] = doc
+
+	resp, err := l.srv.Diagnose(ctx, &pb.DiagnoseRequest{Directory: filepath.Dir(path), Content: text, Remote: remote})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas lsp: %s: %v\n", path, err)
+		return
+	}
+	doc.diags = resp.Diagnostics
+	diags := []lspDiagnostic{}
+	for _, d := range doc.diags {
+		diags = append(diags, doc.diagnostic(d))
+	}
+	l.publish(uri, diags)
+}
+
+// codeActions returns the fixes of the diagnostics of uri on the lines of r.
+func (l *lspServer) codeActions(uri string, r lspRange) []lspCodeAction {
+	actions := []lspCodeAction{}
+	doc, ok := l.docs[uri]
+	if !ok {
+		return actions
+	}
+	for _, d := range doc.diags {
+		if line := int(d.Line) - 1; line < r.Start.Line || line > r.End.Line {
+			continue
+		}
+		for _, fix := range d.Fixes {
+			a := lspCodeAction{Title: fix.Title, Kind: "quickfix", Diagnostics: []lspDiagnostic{doc.diagnostic(d)}}
+			a.Edit.Changes = map[string][]lspTextEdit{}
+			for _, e := range fix.Edits {
+				a.Edit.Changes[uri] = append(a.Edit.Changes[uri], lspTextEdit{
+					Range:   lspRange{doc.position(e.Line, e.Column), doc.position(e.EndLine, e.EndColumn)},
+					NewText: e.NewText,
+				})
+			}
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+func (l *lspServer) publish(uri string, diags []lspDiagnostic) {
+	l.write(lspNotification{ //nolint:errcheck
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  map[string]any{"uri": uri, "diagnostics": diags},
+	})
+}
+
+// write sends msg with its Content-Length header.
+func (l *lspServer) write(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(l.out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	return err
+}
+
+// diagnostic converts d to LSP.
+func (doc *lspDoc) diagnostic(d *pb.Diagnostic) lspDiagnostic {
+	return lspDiagnostic{
+		Range:    lspRange{doc.position(d.Line, d.Column), doc.position(d.Line, d.EndColumn)},
+		Severity: lspSeverities[d.Severity],
+		Code:     d.Code,
+		Source:   "atlas",
+		Message:  d.Message,
+	}
+}
+
+// position converts a line and byte column, counted from 1, to an LSP
+// position: counted from 0, in UTF-16 code units.
+func (doc *lspDoc) position(line, column int32) lspPosition {
+	lines := strings.Split(doc.text, "\n")
+	pos := lspPosition{Line: int(line) - 1}
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return pos
+	}
+	text := lines[pos.Line]
+	n := min(max(int(column)-1, 0), len(text))
+	pos.Character = len(utf16.Encode([]rune(text[:n])))
+	return pos
+}
+
+// lspPath returns the file path of a file:// URI.
+func lspPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	return filepath.FromSlash(u.Path), true
+}
+
+// readLSPMessage reads the content of the next message from in.
+func readLSPMessage(in *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := in.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" && length < 0 {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("read header: %v", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if v, ok := strings.CutPrefix(line, "Content-Length: "); ok {
+			if length, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("invalid header %q", line)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("message without Content-Length")
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(in, data); err != nil {
+		return nil, fmt.Errorf("read message: %v", err)
+	}
+	return data, nil
+}
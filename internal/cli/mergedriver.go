@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/internal/server"
+)
+
+// mergeDriver is a git merge driver provided by atlas for one file.
+type mergeDriver struct {
+	file    string // file name matched in .gitattributes
+	name    string // driver name in git config
+	command string // driver command; git substitutes %A, %B and %O
+}
+
+var mergeDrivers = []mergeDriver{
+	{file: "holon.sum", name: "atlas-sum", command: "atlas sum merge %A %B"},
+	{file: "holon.mod", name: "atlas-mod", command: "atlas mod merge %A %B %O"},
+}
+
+func cmdSumMerge(ctx context.Context, srv *server.Server, args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: atlas sum merge <ours> <theirs>")
+		return 1
+	}
+
+	resp, err := srv.SumMerge(ctx, &pb.SumMergeRequest{Ours: args[0], Theirs: args[1]})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas sum merge: %v\n", err)
+		return 1
+	}
+	if len(resp.Conflicts) > 0 {
+		fmt.Fprintln(os.Stderr, "atlas sum merge: conflicting hashes:")
+		for _, c := range resp.Conflicts {
+			fmt.Fprintf(os.Stderr, "  %s %s\n    ours:   %s\n    theirs: %s\n", c.Path, c.Version, c.Ours, c.Theirs)
+		}
+		return 1
+	}
+	return 0
+}
+
+func cmdModMerge(ctx context.Context, srv *server.Server, args []string) int {
+	if len(args) != 2 && len(args) != 3 {
+		fmt.Fprintln(os.Stderr, "usage: atlas mod merge <ours> <theirs> [<base>]")
+		return 1
+	}
+	req := &pb.ModMergeRequest{Ours: args[0], Theirs: args[1]}
+	if len(args) == 3 {
+		req.Base = args[2]
+	}
+
+	resp, err := srv.ModMerge(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas mod merge: %v\n", err)
+		return 1
+	}
+	if resp.Conflict != "" {
+		fmt.Fprintf(os.Stderr, "atlas mod merge: conflict: %s\n", resp.Conflict)
+		return 1
+	}
+	return 0
+}
+
+// cmdMergeDriver registers the atlas merge drivers in the git repository
+// of the current directory: git config for the commands, .gitattributes
+// for the files.
+func cmdMergeDriver(args []string) int {
+	if len(args) != 1 || args[0] != "install" {
+		fmt.Fprintln(os.Stderr, "usage: atlas merge-driver install")
+		return 1
+	}
+
+	top, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "atlas merge-driver: not in a git repository: %v\n", err)
+		return 1
+	}
+	attrPath := filepath.Join(strings.TrimSpace(string(top)), ".gitattributes")
+	attrs, err := os.ReadFile(attrPath)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "atlas merge-driver: %v\n", err)
+		return 1
+	}
+
+	for _, d := range mergeDrivers {
+		for _, kv := range [][2]string{
+			{"merge." + d.name + ".name", "atlas " + d.file + " merge"},
+			{"merge." + d.name + ".driver", d.command},
+		} {
+			key, value := kv[0], kv[1]
+			if out, err := exec.Command("git", "config", key, value).CombinedOutput(); err != nil {
+				fmt.Fprintf(os.Stderr, "atlas merge-driver: git config %s: %v\n%s", key, err, out)
+				return 1
+			}
+		}
+
+		line := d.file + " merge=" + d.name
+		if !strings.Contains("\n"+string(attrs), "\n"+line+"\n") {
+			if len(attrs) > 0 && attrs[len(attrs)-1] != '\n' {
+				attrs = append(attrs, '\n')
+			}
+			attrs = append(attrs, line+"\n"...)
+		}
+		fmt.Printf("installed %s merge driver for %s\n", d.name, d.file)
+	}
+
+	if err := os.WriteFile(attrPath, attrs, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "atlas merge-driver: %v\n", err)
+		return 1
+	}
+	return 0
+}
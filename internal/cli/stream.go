@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// localStream lets the CLI call a server-streaming RPC in-process: each
+// message the server sends is handed to send. Only Send and Context are
+// implemented; the embedded grpc.ServerStream is nil.
+type localStream[T any] struct {
+	grpc.ServerStream
+	ctx  context.Context
+	send func(*T) error
+}
+
+func newLocalStream[T any](ctx context.Context, send func(*T) error) *localStream[T] {
+	return &localStream[T]{ctx: ctx, send: send}
+}
+
+func (s *localStream[T]) Send(m *T) error {
+	return s.send(m)
+}
+
+func (s *localStream[T]) Context() context.Context {
+	return s.ctx
+}
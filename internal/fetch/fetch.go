@@ -0,0 +1,350 @@
+// Package fetch reads git repositories in process, with go-git: the tags
+// of a repository, the commits they name, and the tree of a tag, branch or
+// commit. atlas uses it instead of the git command where git is not
+// installed, or when ATLAS_GO_GIT=1 asks for it.
+//
+// Local repositories are read where they are; remote ones are fetched
+// into memory, shallowly unless a commit is looked for in their history.
+// Submodules and SSH remotes need the git command.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// ErrSubmodules is returned for a tree with submodules, which only the git
+// command fetches.
+var ErrSubmodules = errors.New("the tree has submodules, which are fetched with git only")
+
+// Remote is a git repository: a URL over HTTP(S) or git://, a file:// URL
+// or a local path.
+type Remote struct {
+	URL string
+	// User and Password, if set, are sent to an HTTP(S) URL.
+	User, Password string
+}
+
+// Revision is what Checkout checked out.
+type Revision struct {
+	// Commit is the hash of the commit, made at Time.
+	Commit string
+	Time   time.Time
+	// TagObject is the hash of the annotated tag naming the commit, if
+	// any, and Tag its content, as git cat-file prints it.
+	TagObject string
+	Tag       []byte
+}
+
+// Tags returns the commit each tag of r points to.
+func (r Remote) Tags(ctx context.Context) (map[string]string, error) {
+	if local, ok, err := r.local(); ok || err != nil {
+		if err != nil {
+			return nil, err
+		}
+		iter, err := local.IterReferences()
+		if err != nil {
+			return nil, err
+		}
+		tags := map[string]string{}
+		err = iter.ForEach(func(ref *plumbing.Reference) error {
+			if name, ok := strings.CutPrefix(ref.Name().String(), "refs/tags/"); ok {
+				if commit, err := peel(local, ref.Hash()); err == nil {
+					tags[name] = commit.Hash.String()
+				}
+			}
+			return nil
+		})
+		return tags, err
+	}
+
+	auth, err := r.auth()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := git.NewRemote(memory.NewStorage(), r.config()).ListContext(ctx, &git.ListOptions{Auth: auth, PeelingOption: git.AppendPeeled})
+	if err != nil {
+		return nil, err
+	}
+	// An annotated tag is listed twice: as its tag object, then peeled to
+	// its commit.
+	tags := map[string]string{}
+	for _, ref := range refs {
+		name, ok := strings.CutPrefix(ref.Name().String(), "refs/tags/")
+		if !ok {
+			continue
+		}
+		if name, peeled := strings.CutSuffix(name, "^{}"); peeled || tags[name] == "" {
+			tags[name] = ref.Hash().String()
+		}
+	}
+	return tags, nil
+}
+
+// TagTimes returns when each of tags of r was made: the tagger date of an
+// annotated tag, the commit date of a lightweight one.
+func (r Remote) TagTimes(ctx context.Context, tags []string) (map[string]time.Time, error) {
+	var specs []config.RefSpec
+	for _, tag := range tags {
+		specs = append(specs, config.RefSpec("+refs/tags/"+tag+":refs/tags/"+tag))
+	}
+	s, err := r.open(ctx, specs, 1)
+	if err != nil {
+		return nil, err
+	}
+	times := map[string]time.Time{}
+	for _, tag := range tags {
+		ref, err := s.Reference(plumbing.NewTagReferenceName(tag))
+		if err != nil {
+			continue
+		}
+		if t, err := object.GetTag(s, ref.Hash()); err == nil {
+			times[tag] = t.Tagger.When.UTC()
+		} else if c, err := object.GetCommit(s, ref.Hash()); err == nil {
+			times[tag] = c.Committer.When.UTC()
+		}
+	}
+	return times, nil
+}
+
+// Commit returns the full hash of the commit rev, a hash or a prefix of
+// one, in the history of the branches and tags of r, and when it was made.
+func (r Remote) Commit(ctx context.Context, rev string) (string, time.Time, error) {
+	s, err := r.open(ctx, historySpecs, 0)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	c, err := findCommit(s, rev)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return c.Hash.String(), c.Committer.When.UTC(), nil
+}
+
+// historySpecs fetch the branches and tags of a repository.
+var historySpecs = []config.RefSpec{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"}
+
+// Checkout writes the tree of rev in r into dst, a directory that does
+// not exist yet: rev is a tag, a commit hash or a prefix of one, found in
+// the history of the branches and tags, or "" for the default branch.
+func (r Remote) Checkout(ctx context.Context, rev, dst string) (Revision, error) {
+	var s storer.Storer
+	var hash plumbing.Hash
+	var err error
+	switch {
+	case rev == "":
+		if s, err = r.open(ctx, []config.RefSpec{"+HEAD:refs/remotes/origin/HEAD"}, 1); err != nil {
+			return Revision{}, err
+		}
+		head := plumbing.ReferenceName("refs/remotes/origin/HEAD")
+		if _, local, _ := r.local(); local {
+			head = plumbing.HEAD
+		}
+		ref, err := storer.ResolveReference(s, head)
+		if err != nil {
+			return Revision{}, fmt.Errorf("default branch: %w", err)
+		}
+		hash = ref.Hash()
+	case isHash(rev):
+		if s, err = r.open(ctx, historySpecs, 0); err != nil {
+			return Revision{}, err
+		}
+		c, err := findCommit(s, rev)
+		if err != nil {
+			return Revision{}, err
+		}
+		hash = c.Hash
+	default:
+		if s, err = r.open(ctx, []config.RefSpec{config.RefSpec("+refs/tags/" + rev + ":refs/tags/" + rev)}, 1); err != nil {
+			return Revision{}, err
+		}
+		ref, err := s.Reference(plumbing.NewTagReferenceName(rev))
+		if err != nil {
+			return Revision{}, fmt.Errorf("tag %s: %w", rev, err)
+		}
+		hash = ref.Hash()
+	}
+
+	var out Revision
+	if obj, err := s.EncodedObject(plumbing.TagObject, hash); err == nil {
+		rd, err := obj.Reader()
+		if err != nil {
+			return Revision{}, err
+		}
+		out.Tag, err = io.ReadAll(rd)
+		rd.Close() //nolint:errcheck
+		if err != nil {
+			return Revision{}, err
+		}
+		out.TagObject = hash.String()
+	}
+	commit, err := peel(s, hash)
+	if err != nil {
+		return Revision{}, err
+	}
+	out.Commit, out.Time = commit.Hash.String(), commit.Committer.When.UTC()
+	if err := writeTree(ctx, commit, dst); err != nil {
+		return Revision{}, err
+	}
+	return out, nil
+}
+
+// writeTree writes the files of commit into dst.
+func writeTree(ctx context.Context, commit *object.Commit, dst string) error {
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	if _, err := tree.File(".gitmodules"); err == nil {
+		return ErrSubmodules
+	}
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	return tree.Files().ForEach(func(f *object.File) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !filepath.IsLocal(f.Name) {
+			return fmt.Errorf("%q escapes the tree", f.Name)
+		}
+		path := filepath.Join(dst, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		content, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		switch f.Mode {
+		case filemode.Symlink:
+			return os.Symlink(content, path)
+		case filemode.Executable:
+			return os.WriteFile(path, []byte(content), 0o755)
+		default:
+			return os.WriteFile(path, []byte(content), 0o644)
+		}
+	})
+}
+
+// open returns the objects of r: a local repository as it is, a remote one
+// with what specs fetch, at depth unless 0. Local repositories hold every
+// object already.
+func (r Remote) open(ctx context.Context, specs []config.RefSpec, depth int) (storer.Storer, error) {
+	if local, ok, err := r.local(); ok || err != nil {
+		return local, err
+	}
+	auth, err := r.auth()
+	if err != nil {
+		return nil, err
+	}
+	s := memory.NewStorage()
+	err = git.NewRemote(s, r.config()).FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: specs,
+		Depth:    depth,
+		Auth:     auth,
+		Tags:     git.NoTags,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, err
+	}
+	return s, nil
+}
+
+// local returns the storage of r if a local repository.
+func (r Remote) local() (storer.Storer, bool, error) {
+	ep, err := transport.NewEndpoint(r.URL)
+	if err != nil || ep.Protocol != "file" {
+		return nil, false, err
+	}
+	repo, err := git.PlainOpen(ep.Path)
+	if err != nil {
+		return nil, true, fmt.Errorf("open %s: %w", ep.Path, err)
+	}
+	return repo.Storer, true, nil
+}
+
+func (r Remote) config() *config.RemoteConfig {
+	return &config.RemoteConfig{Name: "origin", URLs: []string{r.URL}}
+}
+
+// auth returns the credentials of r for its protocol. SSH is refused:
+// go-git would offer it the keys of the user, which the git command is
+// kept from unless ATLAS_GIT_AUTH says so.
+func (r Remote) auth() (transport.AuthMethod, error) {
+	ep, err := transport.NewEndpoint(r.URL)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ep.Protocol == "ssh":
+		return nil, fmt.Errorf("%s: SSH remotes are fetched with git only", r.URL)
+	case r.User == "" && r.Password == "" || ep.Protocol != "http" && ep.Protocol != "https":
+		return nil, nil
+	}
+	return &githttp.BasicAuth{Username: r.User, Password: r.Password}, nil
+}
+
+// peel returns the commit hash names, through annotated tags.
+func peel(s storer.EncodedObjectStorer, hash plumbing.Hash) (*object.Commit, error) {
+	for range 10 {
+		t, err := object.GetTag(s, hash)
+		if err != nil {
+			break
+		}
+		hash = t.Target
+	}
+	return object.GetCommit(s, hash)
+}
+
+// findCommit returns the commit rev, a hash or a prefix of one, in s.
+func findCommit(s storer.EncodedObjectStorer, rev string) (*object.Commit, error) {
+	if len(rev) == 40 {
+		if c, err := object.GetCommit(s, plumbing.NewHash(rev)); err == nil {
+			return c, nil
+		}
+		return nil, fmt.Errorf("no branch or tag has commit %s", rev)
+	}
+	iter, err := s.IterEncodedObjects(plumbing.CommitObject)
+	if err != nil {
+		return nil, err
+	}
+	var found []plumbing.Hash
+	err = iter.ForEach(func(obj plumbing.EncodedObject) error {
+		if h := obj.Hash(); strings.HasPrefix(h.String(), rev) {
+			found = append(found, h)
+		}
+		return nil
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case len(found) == 0:
+		return nil, fmt.Errorf("no branch or tag has commit %s", rev)
+	case len(found) > 1:
+		return nil, fmt.Errorf("commit prefix %s is ambiguous", rev)
+	}
+	return object.GetCommit(s, found[0])
+}
+
+// isHash reports whether rev is a commit hash, or a prefix of one of at
+// least 7 hex digits, rather than a tag.
+func isHash(rev string) bool {
+	return len(rev) >= 7 && len(rev) <= 40 && strings.Trim(rev, "0123456789abcdef") == ""
+}
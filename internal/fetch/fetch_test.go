@@ -0,0 +1,187 @@
+package fetch_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/organic-programming/rhizome-atlas/internal/fetch"
+)
+
+// testRepo is a repository with v1.0.0, a lightweight tag, and v1.1.0, an
+// annotated one on the next commit, which HEAD points to.
+type testRepo struct {
+	dir        string
+	repo       *git.Repository
+	c1, c2     plumbing.Hash
+	t1, tagged time.Time
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &testRepo{dir: dir, repo: repo, t1: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), tagged: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	r.write(t, "HOLON.md", "---\nname: dep\n---\n")
+	r.c1 = r.commit(t, "v1", r.t1)
+	if _, err := repo.CreateTag("v1.0.0", r.c1, nil); err != nil {
+		t.Fatal(err)
+	}
+	r.write(t, "bin/run", "#!/bin/sh\n")
+	if err := os.Chmod(filepath.Join(dir, "bin", "run"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	r.c2 = r.commit(t, "v2", r.t1.Add(time.Hour))
+	if _, err := repo.CreateTag("v1.1.0", r.c2, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "t", Email: "t@example.com", When: r.tagged},
+		Message: "Release v1.1.0",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func (r *testRepo) write(t *testing.T, name, content string) {
+	t.Helper()
+	path := filepath.Join(r.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (r *testRepo) commit(t *testing.T, msg string, at time.Time) plumbing.Hash {
+	t.Helper()
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.AddGlob("."); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "t", Email: "t@example.com", When: at}
+	hash, err := wt.Commit(msg, &git.CommitOptions{Author: sig, Committer: sig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+// check checks r through remote: its tags, their times, and checkouts.
+func (r *testRepo) check(t *testing.T, remote fetch.Remote) {
+	t.Helper()
+	ctx := context.Background()
+
+	tags, err := remote.Tags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || tags["v1.0.0"] != r.c1.String() || tags["v1.1.0"] != r.c2.String() {
+		t.Errorf("tags = %v, want v1.0.0 at %s and v1.1.0 at %s", tags, r.c1, r.c2)
+	}
+	times, err := remote.TagTimes(ctx, []string{"v1.0.0", "v1.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !times["v1.0.0"].Equal(r.t1) || !times["v1.1.0"].Equal(r.tagged) {
+		t.Errorf("tag times = %v, want the commit date of v1.0.0 and the tagger date of v1.1.0", times)
+	}
+
+	dst := filepath.Join(t.TempDir(), "v1.1.0")
+	rev, err := remote.Checkout(ctx, "v1.1.0", dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Commit != r.c2.String() || rev.TagObject == "" || !strings.Contains(string(rev.Tag), "tag v1.1.0\n") {
+		t.Errorf("revision of v1.1.0 = %s, tag %s:\n%s", rev.Commit, rev.TagObject, rev.Tag)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "bin", "run")); err != nil || info.Mode().Perm() != 0o755 {
+		t.Errorf("bin/run: %v, %v, want an executable", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, ".git")); !os.IsNotExist(err) {
+		t.Errorf(".git checked out: %v", err)
+	}
+
+	for rev, want := range map[string]plumbing.Hash{"": r.c2, r.c1.String()[:10]: r.c1} {
+		got, err := remote.Checkout(ctx, rev, filepath.Join(t.TempDir(), "tree"))
+		if err != nil || got.Commit != want.String() || got.TagObject != "" {
+			t.Errorf("checkout %q = %+v, %v, want commit %s", rev, got, err, want)
+		}
+	}
+	if hash, at, err := remote.Commit(ctx, r.c1.String()[:8]); err != nil || hash != r.c1.String() || !at.Equal(r.t1) {
+		t.Errorf("commit %s = %s at %v, %v", r.c1.String()[:8], hash, at, err)
+	}
+	if _, _, err := remote.Commit(ctx, "0000000"); err == nil {
+		t.Error("unknown commit found")
+	}
+}
+
+func TestLocal(t *testing.T) {
+	r := newTestRepo(t)
+	r.check(t, fetch.Remote{URL: r.dir})
+	r.check(t, fetch.Remote{URL: "file://" + r.dir})
+}
+
+// TestRemote reads the repository through git daemon, shallowly.
+func TestRemote(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git daemon needs git")
+	}
+	r := newTestRepo(t)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close() //nolint:errcheck
+	daemon := exec.Command("git", "daemon", "--export-all", "--reuseaddr", "--listen=127.0.0.1",
+		fmt.Sprintf("--port=%d", port), "--base-path="+filepath.Dir(r.dir), filepath.Dir(r.dir))
+	if err := daemon.Start(); err != nil {
+		t.Skipf("git daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		daemon.Process.Kill() //nolint:errcheck
+		daemon.Wait()         //nolint:errcheck
+	})
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close() //nolint:errcheck
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Skip("git daemon did not start")
+		}
+	}
+
+	r.check(t, fetch.Remote{URL: "git://" + addr + "/" + filepath.Base(r.dir)})
+}
+
+func TestCheckoutRefuses(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	r.write(t, ".gitmodules", "[submodule \"sub\"]\n\tpath = sub\n\turl = https://example.com/sub\n")
+	r.commit(t, "submodule", r.t1.Add(2*time.Hour))
+	if _, err := (fetch.Remote{URL: r.dir}).Checkout(ctx, "", t.TempDir()+"/tree"); !errors.Is(err, fetch.ErrSubmodules) {
+		t.Errorf("checkout with submodules = %v, want ErrSubmodules", err)
+	}
+	if _, err := (fetch.Remote{URL: "ssh://git@example.com/dep"}).Tags(ctx); err == nil || !strings.Contains(err.Error(), "SSH") {
+		t.Errorf("SSH remote = %v, want it refused", err)
+	}
+}
@@ -0,0 +1,33 @@
+// Package npipe implements the npipe:// transport on Windows named pipes,
+// so that a local daemon can be reached without opening a TCP port, with
+// access controlled by the pipe's ACL.
+package npipe
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Path returns the pipe name of a URI of the form
+// "npipe:////./pipe/<name>": \\.\pipe\<name>.
+func Path(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("parse %q: %w", uri, err)
+	}
+	if u.Scheme != "npipe" {
+		return "", fmt.Errorf("%s: not an npipe:// URI", uri)
+	}
+	name := strings.ReplaceAll(u.Host+u.Path, "/", `\`)
+	if !strings.HasPrefix(name, `\\.\pipe\`) || len(name) == len(`\\.\pipe\`) {
+		return "", fmt.Errorf("%s: want npipe:////./pipe/<name>", uri)
+	}
+	return name, nil
+}
+
+// addr is the net.Addr of both ends of a pipe: its name.
+type addr string
+
+func (addr) Network() string  { return "npipe" }
+func (a addr) String() string { return string(a) }
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package npipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+var errUnsupported = fmt.Errorf("named pipes are only available on Windows: %w", errors.ErrUnsupported)
+
+// Listen is only implemented on Windows.
+func Listen(name string) (net.Listener, error) {
+	return nil, fmt.Errorf("%s: %w", name, errUnsupported)
+}
+
+// Dial is only implemented on Windows.
+func Dial(_ context.Context, name string) (net.Conn, error) {
+	return nil, fmt.Errorf("%s: %w", name, errUnsupported)
+}
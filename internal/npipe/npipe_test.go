@@ -0,0 +1,24 @@
+package npipe
+
+import "testing"
+
+func TestPath(t *testing.T) {
+	for uri, want := range map[string]string{
+		"npipe:////./pipe/atlas":     `\\.\pipe\atlas`,
+		"npipe:////./pipe/org/atlas": `\\.\pipe\org\atlas`,
+		"npipe:////./pipe/":          "",
+		"npipe://atlas":              "",
+		"unix:///tmp/atlas.sock":     "",
+	} {
+		got, err := Path(uri)
+		if want == "" {
+			if err == nil {
+				t.Errorf("Path(%q) = %q, want an error", uri, got)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("Path(%q) = %q, %v, want %q", uri, got, err, want)
+		}
+	}
+}
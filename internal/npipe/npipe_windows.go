@@ -0,0 +1,228 @@
+//go:build windows
+
+package npipe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// pipeSDDL is the ACL of the pipes served: only the user running the
+// server, SYSTEM and administrators may connect.
+const pipeSDDL = "D:P(A;;GA;;;OW)(A;;GA;;;SY)(A;;GA;;;BA)"
+
+const bufferSize = 64 << 10
+
+// busyRetry is how often Dial retries while every instance of the pipe is
+// busy.
+const busyRetry = 10 * time.Millisecond
+
+type listener struct {
+	name *uint16
+	addr addr
+	sa   *windows.SecurityAttributes
+
+	mu        sync.Mutex
+	pending   windows.Handle // the instance waiting for a client, if any
+	accepting bool
+	closed    bool
+}
+
+// Listen serves the pipe named name (\\.\pipe\<name>), which must not be
+// served already. Only local clients are accepted.
+func Listen(name string) (net.Listener, error) {
+	p, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	sd, err := windows.SecurityDescriptorFromString(pipeSDDL)
+	if err != nil {
+		return nil, err
+	}
+	l := &listener{
+		name: p,
+		addr: addr(name),
+		sa: &windows.SecurityAttributes{
+			Length:             uint32(unsafe.Sizeof(windows.SecurityAttributes{})),
+			SecurityDescriptor: sd,
+		},
+	}
+	// The first instance claims the name, so that a second server fails
+	// here rather than sharing it.
+	if l.pending, err = l.create(true); err != nil {
+		return nil, fmt.Errorf("listen %s: %w", name, err)
+	}
+	return l, nil
+}
+
+func (l *listener) create(first bool) (windows.Handle, error) {
+	flags := uint32(windows.PIPE_ACCESS_DUPLEX | windows.FILE_FLAG_OVERLAPPED)
+	if first {
+		flags |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+	mode := uint32(windows.PIPE_TYPE_BYTE | windows.PIPE_READMODE_BYTE | windows.PIPE_WAIT | windows.PIPE_REJECT_REMOTE_CLIENTS)
+	return windows.CreateNamedPipe(l.name, flags, mode, windows.PIPE_UNLIMITED_INSTANCES, bufferSize, bufferSize, 0, l.sa)
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil, net.ErrClosed
+	}
+	if l.pending == 0 {
+		h, err := l.create(false)
+		if err != nil {
+			l.mu.Unlock()
+			return nil, err
+		}
+		l.pending = h
+	}
+	h := l.pending
+	l.accepting = true
+	l.mu.Unlock()
+
+	_, err := wait(h, func(o *windows.Overlapped) error { return windows.ConnectNamedPipe(h, o) })
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending, l.accepting = 0, false
+	if l.closed {
+		windows.CloseHandle(h) //nolint:errcheck
+		return nil, net.ErrClosed
+	}
+	if err != nil && err != windows.ERROR_PIPE_CONNECTED {
+		windows.CloseHandle(h) //nolint:errcheck
+		return nil, err
+	}
+	return &conn{h: h, addr: l.addr}, nil
+}
+
+func (l *listener) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	if l.pending != 0 {
+		if l.accepting {
+			// Accept closes it once the wait is cancelled.
+			return windows.CancelIoEx(l.pending, nil)
+		}
+		windows.CloseHandle(l.pending) //nolint:errcheck
+		l.pending = 0
+	}
+	return nil
+}
+
+func (l *listener) Addr() net.Addr { return l.addr }
+
+// Dial connects to the pipe named name, waiting while all its instances
+// are busy. The server may identify the client but not impersonate it.
+func Dial(ctx context.Context, name string) (net.Conn, error) {
+	p, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		h, err := windows.CreateFile(p, windows.GENERIC_READ|windows.GENERIC_WRITE, 0, nil, windows.OPEN_EXISTING,
+			windows.FILE_FLAG_OVERLAPPED|windows.SECURITY_SQOS_PRESENT|windows.SECURITY_IDENTIFICATION, 0)
+		if err == nil {
+			return &conn{h: h, addr: addr(name)}, nil
+		}
+		if err != windows.ERROR_PIPE_BUSY {
+			return nil, fmt.Errorf("dial %s: %w", name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(busyRetry):
+		}
+	}
+}
+
+// conn is one end of a connected pipe instance. Reads and writes are
+// overlapped, so that they can run concurrently.
+type conn struct {
+	h     windows.Handle
+	addr  addr
+	close sync.Once
+}
+
+func (c *conn) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n, err := wait(c.h, func(o *windows.Overlapped) error { return windows.ReadFile(c.h, p, nil, o) })
+	switch err {
+	case nil:
+		if n == 0 {
+			return 0, io.EOF
+		}
+		return int(n), nil
+	case windows.ERROR_BROKEN_PIPE, windows.ERROR_PIPE_NOT_CONNECTED:
+		return int(n), io.EOF
+	case windows.ERROR_OPERATION_ABORTED, windows.ERROR_INVALID_HANDLE:
+		return int(n), net.ErrClosed
+	}
+	return int(n), err
+}
+
+func (c *conn) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		n, err := wait(c.h, func(o *windows.Overlapped) error { return windows.WriteFile(c.h, p[written:], nil, o) })
+		written += int(n)
+		switch err {
+		case nil:
+		case windows.ERROR_OPERATION_ABORTED, windows.ERROR_INVALID_HANDLE:
+			return written, net.ErrClosed
+		default:
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (c *conn) Close() error {
+	err := net.ErrClosed
+	c.close.Do(func() {
+		windows.CancelIoEx(c.h, nil) //nolint:errcheck // nothing pending
+		err = windows.CloseHandle(c.h)
+	})
+	return err
+}
+
+func (c *conn) LocalAddr() net.Addr  { return c.addr }
+func (c *conn) RemoteAddr() net.Addr { return c.addr }
+
+// Deadlines are accepted but not enforced: pipes have none, and the gRPC
+// transport on top bounds its calls with contexts.
+func (c *conn) SetDeadline(time.Time) error      { return nil }
+func (c *conn) SetReadDeadline(time.Time) error  { return nil }
+func (c *conn) SetWriteDeadline(time.Time) error { return nil }
+
+// wait starts an overlapped operation on h with op and waits for it to
+// complete, returning the number of bytes transferred.
+func wait(h windows.Handle, op func(*windows.Overlapped) error) (uint32, error) {
+	ev, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.CloseHandle(ev) //nolint:errcheck
+	o := &windows.Overlapped{HEvent: ev}
+	if err := op(o); err != nil && err != windows.ERROR_IO_PENDING {
+		return 0, err
+	}
+	var n uint32
+	err = windows.GetOverlappedResult(h, o, &n, true)
+	return n, err
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/holonmd"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+)
+
+// inferRequires reconstructs the requires of the holon in dir from the
+// copies vendored in its .holon/ directory by a tool predating holon.mod.
+// A copy's path is the one its own holon.mod declares; its version is the
+// one a holon.sum in dir, or else the cache, records for content hashing
+// the same, or else the "version" of its HOLON.md. Without its own
+// holon.mod, a copy is identified by the cache alone: the entries named
+// like it holding the same content. Copies left unidentified are
+// reported in unresolved, one sentence each.
+func inferRequires(dir string, sum *modfile.SumFile, cache []CacheEntry) (inferred []*pb.Dependency, unresolved []string, err error) {
+	vendorDir := filepath.Join(dir, modfile.DefaultVendorDir)
+	des, err := os.ReadDir(vendorDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, de := range des {
+		if !de.IsDir() || strings.HasPrefix(de.Name(), ".") {
+			continue
+		}
+		copyDir := filepath.Join(vendorDir, de.Name())
+		rel := filepath.ToSlash(filepath.Join(modfile.DefaultVendorDir, de.Name()))
+
+		depPath := ""
+		if own, err := modfile.Parse(filepath.Join(copyDir, "holon.mod")); err == nil {
+			depPath = own.HolonPath
+		}
+		// Entries of the copy's path, or named like it when it is unknown.
+		matches := func(p string) bool {
+			return p == depPath || depPath == "" && path.Base(p) == de.Name()
+		}
+		hashes := map[string]string{}
+		sameContent := func(recorded string) bool {
+			alg := hashAlgorithm(recorded)
+			if _, ok := hashes[alg]; !ok {
+				hashes[alg], _ = sumHashDir(alg, copyDir)
+			}
+			return hashes[alg] == recorded
+		}
+
+		found, version := "", ""
+		pick := func(p, v string) {
+			if found == "" || semver.Compare(v, version) > 0 {
+				found, version = p, v
+			}
+		}
+		if sum != nil {
+			for _, e := range sum.Entries {
+				if _, ok := hashers[hashAlgorithm(e.Hash)]; ok && !strings.Contains(e.Version, "/") && matches(e.Path) && sameContent(e.Hash) {
+					pick(e.Path, e.Version)
+				}
+			}
+		}
+		if found == "" {
+			for _, e := range cache {
+				if !matches(e.Path) {
+					continue
+				}
+				if hash, err := sumHashDir(defaultHashAlgorithm, e.Dir); err == nil && sameContent(hash) {
+					pick(e.Path, e.Version)
+				}
+			}
+		}
+		if found == "" && depPath != "" {
+			if fm, err := holonmd.Parse(filepath.Join(copyDir, "HOLON.md")); err == nil {
+				if v, _ := fm.Fields["version"].(string); v != "" {
+					found, version = depPath, v
+				}
+			}
+		}
+
+		switch {
+		case found != "":
+			inferred = append(inferred, &pb.Dependency{Path: found, Version: version, CachePath: copyDir})
+		case depPath != "":
+			unresolved = append(unresolved, rel+": version of "+depPath+" unknown — add it with 'atlas add "+depPath+" <version>'")
+		default:
+			unresolved = append(unresolved, rel+": no holon.mod, and no cache entry holds the same content — add it with 'atlas add'")
+		}
+	}
+	return inferred, unresolved, nil
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionalphapb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+// Scopes an AuthConfig grants. ScopeRead allows the atlas methods a
+// read-only server serves, and reflection; ScopePublish adds
+// publishMethods; ScopeAdmin allows every method.
+const (
+	ScopeRead    = "read"
+	ScopePublish = "publish"
+	ScopeAdmin   = "admin"
+)
+
+// publishMethods are the RPCs that publish a holon to a registry, a
+// mirror or the shared cache, allowed by ScopePublish.
+var publishMethods = map[string]bool{
+	pb.RhizomeAtlasService_Release_FullMethodName:      true,
+	pb.RhizomeAtlasService_BundleCreate_FullMethodName: true,
+	pb.RhizomeAtlasService_CachePush_FullMethodName:    true,
+	pb.RhizomeAtlasService_MirrorSync_FullMethodName:   true,
+}
+
+// reflectionServices are the services of gRPC reflection, which only
+// describe what the server serves, allowed by ScopeRead.
+var reflectionServices = []string{
+	reflectionpb.ServerReflection_ServiceDesc.ServiceName,
+	reflectionalphapb.ServerReflection_ServiceDesc.ServiceName,
+}
+
+// reflectionMethod reports whether method, in full, is one of
+// reflectionServices.
+func reflectionMethod(method string) bool {
+	service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return slices.Contains(reflectionServices, service)
+}
+
+// AuthConfig authorizes each call of a server, per method, after the
+// bearer token of its "authorization" metadata or the verified TLS
+// certificate of its client, so that one server can serve mixed
+// audiences. It is read by LoadAuthConfig from a file of lines
+//
+//	token <secret> <grant>...
+//	client <certificate common name> <grant>...
+//	anonymous <grant>...
+//
+// a grant being a scope (read, publish or admin) or a method, named after
+// the atlas service ("Pull") or in full ("/pkg.Service/Method"). Calls
+// carrying neither a known token nor a known certificate get the grants
+// of anonymous, none by default; "#" starts a comment.
+type AuthConfig struct {
+	tokens    map[string]grants
+	clients   map[string]grants
+	anonymous grants
+}
+
+// grants are the scopes and full method names granted to a caller.
+type grants map[string]bool
+
+// allows reports whether g allows a call of method with req, nil for a
+// stream.
+func (g grants) allows(method string, req any) bool {
+	switch {
+	case g[ScopeAdmin] || g[method]:
+		return true
+	case g[ScopePublish] && publishMethods[method]:
+		return true
+	}
+	return (g[ScopeRead] || g[ScopePublish]) &&
+		(reflectionMethod(method) || atlasMethod(method) && checkReadOnly(method, req) == nil)
+}
+
+// LoadAuthConfig reads the AuthConfig of path.
+func LoadAuthConfig(path string) (*AuthConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cfg := &AuthConfig{tokens: map[string]grants{}, clients: map[string]grants{}}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var names []string
+		switch {
+		case fields[0] == "anonymous":
+			names = fields[1:]
+		case (fields[0] == "token" || fields[0] == "client") && len(fields) >= 3:
+			names = fields[2:]
+		default:
+			return nil, fmt.Errorf("%s:%d: want token <secret>, client <name> or anonymous, then grants", path, n)
+		}
+		g := grants{}
+		for _, name := range names {
+			full, err := grantName(name)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, n, err)
+			}
+			g[full] = true
+		}
+		switch fields[0] {
+		case "anonymous":
+			cfg.anonymous = g
+		case "token":
+			cfg.tokens[fields[1]] = g
+		case "client":
+			cfg.clients[fields[1]] = g
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// grantName returns the scope or full method name that name grants.
+func grantName(name string) (string, error) {
+	switch {
+	case name == ScopeRead || name == ScopePublish || name == ScopeAdmin:
+		return name, nil
+	case strings.HasPrefix(name, "/"):
+		return name, nil
+	}
+	desc := pb.RhizomeAtlasService_ServiceDesc
+	for _, m := range desc.Methods {
+		if m.MethodName == name {
+			return "/" + desc.ServiceName + "/" + name, nil
+		}
+	}
+	for _, s := range desc.Streams {
+		if s.StreamName == name {
+			return "/" + desc.ServiceName + "/" + name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown scope or method %q", name)
+}
+
+// check refuses a call of method with req that c does not authorize:
+// with Unauthenticated for an unknown token, or when anonymous calls are
+// granted nothing, and PermissionDenied otherwise.
+func (c *AuthConfig) check(ctx context.Context, method string, req any) error {
+	g, who, err := c.caller(ctx)
+	if err != nil {
+		return err
+	}
+	if !g.allows(method, req) {
+		return status.Errorf(codes.PermissionDenied, "%s: not granted to %s", method, who)
+	}
+	return nil
+}
+
+// caller returns the grants of the caller of ctx, and how to name it.
+func (c *AuthConfig) caller(ctx context.Context) (grants, string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if auth := md.Get("authorization"); len(auth) > 0 {
+		token, ok := strings.CutPrefix(auth[0], "Bearer ")
+		if !ok {
+			return nil, "", status.Error(codes.Unauthenticated, "authorization is not a bearer token")
+		}
+		// Compare with every token, in constant time, not to tell how
+		// much of one matched.
+		var found grants
+		for known, g := range c.tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(known)) == 1 {
+				found = g
+			}
+		}
+		if found == nil {
+			return nil, "", status.Error(codes.Unauthenticated, "unknown token")
+		}
+		return found, "the token", nil
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+			name := info.State.VerifiedChains[0][0].Subject.CommonName
+			if g, ok := c.clients[name]; ok {
+				return g, "client " + name, nil
+			}
+		}
+	}
+	if len(c.anonymous) == 0 {
+		return nil, "", status.Error(codes.Unauthenticated, "a token is required")
+	}
+	return c.anonymous, "anonymous callers", nil
+}
+
+// unary is the unary interceptor of a server authorizing with c.
+func (c *AuthConfig) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := c.check(ctx, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// stream is the stream interceptor of a server authorizing with c.
+func (c *AuthConfig) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := c.check(ss.Context(), info.FullMethod, nil); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
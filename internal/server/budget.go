@@ -0,0 +1,89 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/client"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+// sizedDep is the size, in bytes, of a dependency of the closure.
+type sizedDep struct {
+	path, version string
+	size          int64
+}
+
+// overBudget returns the size budgets of mod that deps exceed: the budget
+// of each dependency, then the total of the closure.
+func overBudget(mod *modfile.ModFile, deps []sizedDep) []*pb.BudgetExceeded {
+	var over []*pb.BudgetExceeded
+	var total int64
+	for _, d := range deps {
+		total += d.size
+		if budget := mod.BudgetOf(d.path); budget > 0 && d.size > budget {
+			over = append(over, &pb.BudgetExceeded{Dependency: d.path + "@" + d.version, Size: d.size, Budget: budget})
+		}
+	}
+	if mod.TotalBudget > 0 && total > mod.TotalBudget {
+		over = append(over, &pb.BudgetExceeded{Size: total, Budget: mod.TotalBudget})
+	}
+	return over
+}
+
+// budgetMessage describes a budget exceeded.
+func budgetMessage(b *pb.BudgetExceeded) string {
+	if b.Dependency == "" {
+		return fmt.Sprintf("dependencies total %d bytes, over the total budget of %s", b.Size, modfile.FormatSize(b.Budget))
+	}
+	return fmt.Sprintf("%s is %d bytes, over its budget of %s", b.Dependency, b.Size, modfile.FormatSize(b.Budget))
+}
+
+// budgetError reports the budgets of modPath that are exceeded.
+func budgetError(modPath string, over []*pb.BudgetExceeded) error {
+	var violations []*errdetails.PreconditionFailure_Violation
+	var msgs []string
+	for _, b := range over {
+		violations = append(violations, &errdetails.PreconditionFailure_Violation{
+			Type:        client.ReasonBudgetExceeded,
+			Subject:     cmp.Or(b.Dependency, "total"),
+			Description: budgetMessage(b),
+		})
+		msgs = append(msgs, budgetMessage(b))
+	}
+	return detailed(codes.FailedPrecondition, client.ReasonBudgetExceeded,
+		map[string]string{"file": modPath}, violations,
+		"size budget exceeded: %s", strings.Join(msgs, "; "))
+}
+
+// snapshotBudgets returns the budgets of mod the cached snapshots of deps
+// exceed.
+func snapshotBudgets(mod *modfile.ModFile, deps []*pb.Dependency) ([]*pb.BudgetExceeded, error) {
+	var sized []sizedDep
+	for _, d := range deps {
+		size, err := dirSize(d.CachePath)
+		if err != nil {
+			return nil, fmt.Errorf("size %s@%s: %w", d.Path, d.Version, err)
+		}
+		sized = append(sized, sizedDep{d.Path, d.Version, size})
+	}
+	return overBudget(mod, sized), nil
+}
+
+// vendorBudgets returns the budgets of mod the vendored copies of deps
+// would exceed, measured as vendorCopy counts them, .holonvendorignore
+// applied. Dependencies missing from the cache are left out.
+func vendorBudgets(mod *modfile.ModFile, deps []vendorItem, ignore vendorIgnore) []*pb.BudgetExceeded {
+	var sized []sizedDep
+	for _, v := range deps {
+		if size, err := vendorCopy(mod, v, ignore, true); err == nil {
+			sized = append(sized, sizedDep{v.dep.Path, v.dep.Version, size})
+		}
+	}
+	return overBudget(mod, sized)
+}
@@ -0,0 +1,373 @@
+package server
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// A bundle is a gzipped tar holding:
+//
+//	holon.mod, holon.sum   the manifests of the bundled holon
+//	bundle.sum             the hash of every snapshot in the bundle
+//	cache/<path>@<version>/...
+//	                       the resolved dependency closure
+const bundleSum = "bundle.sum"
+
+// BundleCreate packages holon.mod, holon.sum and the cached snapshots of
+// the whole dependency closure into a single file, for installation on a
+// machine without network access. With req.Group, the closure is that of
+// the dependencies of that require group only.
+func (s *Server) BundleCreate(_ context.Context, req *pb.BundleCreateRequest) (_ *pb.BundleCreateResponse, err error) {
+	defer s.record("BundleCreate", req.Directory, &err)
+
+	dir := holonDir(req.Directory)
+	if req.Output == "" {
+		return nil, status.Error(codes.InvalidArgument, "output is required")
+	}
+
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	if mod, err = groupMod(mod, req.Group); err != nil {
+		return nil, err
+	}
+	sumPath := filepath.Join(dir, "holon.sum")
+	sum, _ := s.parseSum(sumPath)
+
+	deps, err := s.closure(mod)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+
+	// Hash every snapshot; those listed in holon.sum must still match it.
+	bsum := &modfile.SumFile{}
+	var missing []string
+	for _, dep := range deps {
+		cachePath, err := cacheStore().Get(dep.Path, dep.Version)
+		if err != nil {
+			missing = append(missing, dep.Path+"@"+dep.Version)
+			continue
+		}
+		hash, err := sumHashDir(defaultHashAlgorithm, cachePath)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "hash %s@%s: %v", dep.Path, dep.Version, err)
+		}
+		if want := sum.Lookup(dep.Path, dep.Version); want != "" && !hashMatches(want, cachePath) {
+			return nil, hashMismatchError(dep.Path+"@"+dep.Version, sumPath, "cache does not match holon.sum")
+		}
+		bsum.Set(dep.Path, dep.Version, hash)
+	}
+	if len(missing) > 0 {
+		return nil, notCachedError(missing)
+	}
+
+	if err := writeBundle(req.Output, dir, bsum, deps); err != nil {
+		os.Remove(req.Output) //nolint:errcheck
+		return nil, status.Errorf(codes.Internal, "write bundle: %v", err)
+	}
+
+	resp := &pb.BundleCreateResponse{Output: req.Output}
+	for _, dep := range deps {
+		cachePath, _ := cacheStore().Get(dep.Path, dep.Version)
+		resp.Dependencies = append(resp.Dependencies, &pb.Dependency{
+			Path:      dep.Path,
+			Version:   dep.Version,
+			CachePath: cachePath,
+		})
+	}
+	return resp, nil
+}
+
+// BundleInstall unpacks a bundle into the global cache. Every snapshot is
+// verified against the bundle's hashes, and against its holon.sum where
+// listed, before it enters the cache; snapshots already cached must match
+// too. When req.Directory has no holon.mod, the bundled holon.mod and
+// holon.sum are written there.
+func (s *Server) BundleInstall(_ context.Context, req *pb.BundleInstallRequest) (_ *pb.BundleInstallResponse, err error) {
+	defer s.record("BundleInstall", req.Directory, &err)
+
+	if req.Input == "" {
+		return nil, status.Error(codes.InvalidArgument, "input is required")
+	}
+
+	// Stage next to the cache so that snapshots can be renamed into it.
+	if err := os.MkdirAll(CacheDir(), 0o755); err != nil {
+		return nil, status.Errorf(codes.Internal, "create cache dir: %v", err)
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(CacheDir()), "bundle-")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "create staging dir: %v", err)
+	}
+	defer os.RemoveAll(staging) //nolint:errcheck
+
+	if err := extractBundle(req.Input, staging); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "read bundle: %v", err)
+	}
+	if !exists(filepath.Join(staging, bundleSum)) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s is not an atlas bundle", req.Input)
+	}
+	bsum, err := modfile.ParseSum(filepath.Join(staging, bundleSum))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "parse %s: %v", bundleSum, err)
+	}
+	sum, err := modfile.ParseSum(filepath.Join(staging, "holon.sum"))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "parse bundled holon.sum: %v", err)
+	}
+
+	// Verify everything before touching the cache.
+	for _, e := range bsum.Entries {
+		if !validBundleEntry(e.Path, e.Version) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s: invalid entry %s@%s", bundleSum, e.Path, e.Version)
+		}
+		staged := filepath.Join(staging, "cache", e.Path+"@"+e.Version)
+		alg := hashAlgorithm(e.Hash)
+		if _, ok := hashers[alg]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "%s@%s: unknown hash algorithm %q", e.Path, e.Version, alg)
+		}
+		hash, err := sumHashDir(alg, staged)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%s@%s: missing from bundle", e.Path, e.Version)
+		}
+		if hash != e.Hash {
+			return nil, hashMismatchError(e.Path+"@"+e.Version, req.Input, "hash mismatch (want %s, got %s)", e.Hash, hash)
+		}
+		if want := sum.Lookup(e.Path, e.Version); want != "" && !hashMatches(want, staged) {
+			return nil, hashMismatchError(e.Path+"@"+e.Version, req.Input, "bundle does not match holon.sum")
+		}
+		if cached, err := cacheStore().Get(e.Path, e.Version); err == nil && !hashMatches(e.Hash, cached) {
+			return nil, hashMismatchError(e.Path+"@"+e.Version, req.Input, "cached copy does not match the bundle")
+		}
+	}
+
+	source := req.Input
+	if abs, err := filepath.Abs(req.Input); err == nil {
+		source = abs
+	}
+	resp := &pb.BundleInstallResponse{}
+	for _, e := range bsum.Entries {
+		if !inCache(e.Path, e.Version) {
+			if err := cacheStore().Put(e.Path, e.Version, filepath.Join(staging, "cache", e.Path+"@"+e.Version)); err != nil {
+				return nil, status.Errorf(codes.Internal, "install %s@%s: %v", e.Path, e.Version, err)
+			}
+			info := fetchInfo{Source: "file://" + filepath.ToSlash(source), Fetcher: "bundle", Time: time.Now().UTC()}
+			if err := writeFetchInfo(e.Path, e.Version, info); err != nil {
+				return nil, status.Errorf(codes.Internal, "record fetch info: %v", err)
+			}
+		}
+		target, err := cacheStore().Get(e.Path, e.Version)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "install %s@%s: %v", e.Path, e.Version, err)
+		}
+		resp.Installed = append(resp.Installed, &pb.Dependency{
+			Path:      e.Path,
+			Version:   e.Version,
+			CachePath: target,
+			Source:    fetchSource(e.Path, e.Version),
+		})
+	}
+
+	if req.Directory != "" && !exists(filepath.Join(req.Directory, "holon.mod")) {
+		defer s.journal(req.Directory, "BundleInstall", &err)()
+		for _, name := range []string{"holon.mod", "holon.sum"} {
+			if err := copyFile(filepath.Join(staging, name), filepath.Join(req.Directory, name)); err != nil {
+				return nil, status.Errorf(codes.Internal, "write %s: %v", name, err)
+			}
+		}
+		resp.ModFile = filepath.Join(req.Directory, "holon.mod")
+	}
+	return resp, nil
+}
+
+// validBundleEntry reports whether path@version, as listed in bundle.sum,
+// names a snapshot of its own in the cache: a clean, relative path that
+// neither leaves the cache nor, e.g. as "a/../b", lands on another entry.
+func validBundleEntry(depPath, version string) bool {
+	name := depPath + "@" + version
+	return depPath != "" && version != "" && !strings.Contains(version, "/") &&
+		path.Clean(name) == name && filepath.IsLocal(filepath.FromSlash(name)) &&
+		validSnapshot(depPath, version)
+}
+
+// closure returns every dependency reachable from mod through the cached
+// holon.mod files, each path@version once. Dependencies replaced by local
+// directories are left out; those replaced by another path are listed as
+// that path. Optional dependencies are left out unless cached.
+func (s *Server) closure(mod *modfile.ModFile) ([]modfile.Require, error) {
+	var deps []modfile.Require
+	seen := map[string]bool{}
+	queue := append([]modfile.Require(nil), mod.Require...)
+	for len(queue) > 0 {
+		dep := queue[0]
+		queue = queue[1:]
+		key := dep.Path + "@" + dep.Version
+		if seen[key] || mod.ResolvedPath(dep.Path) != "" {
+			continue
+		}
+		seen[key] = true
+		dep.Path = mod.SourcePath(dep.Path)
+		if dep.Optional && !inCache(dep.Path, dep.Version) {
+			continue // not pulled
+		}
+
+		cachePath, err := cacheStore().Get(dep.Path, dep.Version)
+		if err != nil {
+			return nil, fmt.Errorf("%s not in cache — run 'atlas pull' first", key)
+		}
+		deps = append(deps, dep)
+		if sub, err := s.parseMod(filepath.Join(cachePath, "holon.mod")); err == nil {
+			queue = append(queue, sub.Require...)
+		}
+	}
+	return deps, nil
+}
+
+// writeBundle writes the bundle of the holon in dir to out.
+func writeBundle(out, dir string, bsum *modfile.SumFile, deps []modfile.Require) error {
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range []string{"holon.mod", "holon.sum"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			data = nil // a holon without dependencies may have no holon.sum
+		} else if err != nil {
+			return err
+		}
+		if err := addTarFile(tw, name, data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	var sumData strings.Builder
+	for _, e := range bsum.Entries {
+		fmt.Fprintf(&sumData, "%s %s %s\n", e.Path, e.Version, e.Hash)
+	}
+	if err := addTarFile(tw, bundleSum, []byte(sumData.String()), 0o644); err != nil {
+		return err
+	}
+
+	for _, dep := range deps {
+		src, err := cacheStore().Get(dep.Path, dep.Version)
+		if err != nil {
+			return err
+		}
+		prefix := path.Join("cache", dep.Path+"@"+dep.Version)
+		err = filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(src, p)
+			return addTarFile(tw, path.Join(prefix, filepath.ToSlash(rel)), data, info.Mode().Perm())
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func addTarFile(tw *tar.Writer, name string, data []byte, mode fs.FileMode) error {
+	hdr := &tar.Header{Name: name, Mode: int64(mode), Size: int64(len(data))}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// extractBundle unpacks the regular files of the bundle at in under dir,
+// rejecting entries that would land outside of it.
+func extractBundle(in, dir string) error {
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := filepath.FromSlash(hdr.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid entry %q", hdr.Name)
+		}
+
+		target := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fs.FileMode(hdr.Mode).Perm())
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, tr)
+		out.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o644)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
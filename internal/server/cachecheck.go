@@ -0,0 +1,152 @@
+package server
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+)
+
+// staleAfter is how old a staging directory or lock file must be before
+// CheckCache takes it for the remnant of a fetch that died, rather than
+// one another process is running.
+const staleAfter = time.Hour
+
+// CacheRepair is one remnant of an interrupted fetch that CheckCache
+// removed or quarantined.
+type CacheRepair struct {
+	Path    string // absolute path of the file or directory
+	Problem string // e.g. "partial clone"
+	Action  string // "removed", or "quarantined to <path>"
+	Size    int64  // total size of its files, in bytes
+}
+
+// cacheCheck keeps the repairs of the last CheckCache.
+type cacheCheck struct {
+	mu      sync.Mutex
+	repairs []CacheRepair
+}
+
+// CheckCache scans the local cache for what fetches that died midway
+// leave behind: snapshots holding a .git directory or no file at all,
+// staging directories, and lock files left in the partial repositories
+// of resumable git fetches. Those older than staleAfter are removed, or
+// with quarantine moved under <holon>/quarantine/<time>/ for inspection.
+// It is meant to run as the daemon starts, before any fetch.
+func (s *Server) CheckCache(quarantine bool) (_ []CacheRepair, err error) {
+	defer s.record("CheckCache", "", &err)
+
+	root := filepath.Dir(CacheDir())
+	var found []CacheRepair
+	add := func(path, problem string) {
+		size, _ := dirSize(path)
+		found = append(found, CacheRepair{Path: path, Problem: problem, Size: size})
+	}
+	stale := func(path string) bool {
+		info, err := os.Stat(path)
+		return err == nil && time.Since(info.ModTime()) > staleAfter
+	}
+
+	staging, _ := filepath.Glob(filepath.Join(root, "fetch-*"))
+	for _, dir := range staging {
+		if stale(dir) {
+			add(dir, "interrupted fetch")
+		}
+	}
+	err = filepath.WalkDir(filepath.Join(root, "partial"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".lock") && stale(path) {
+			add(path, "lock remnant")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan partial repositories: %w", err)
+	}
+	entries, err := listCacheDir(CacheDir())
+	if err != nil {
+		return nil, fmt.Errorf("scan cache: %w", err)
+	}
+	for _, e := range entries {
+		if _, err := os.Stat(filepath.Join(e.Dir, ".git")); err == nil {
+			add(e.Dir, "partial clone")
+		} else if empty, err := emptyTree(e.Dir); err == nil && empty {
+			add(e.Dir, "empty snapshot")
+		}
+	}
+
+	dest := filepath.Join(root, "quarantine", time.Now().UTC().Format("20060102T150405Z"))
+	for i := range found {
+		r := &found[i]
+		paths := []string{r.Path}
+		if r.Problem == "partial clone" || r.Problem == "empty snapshot" {
+			paths = append(paths, r.Path+".info", r.Path+".provenance.json")
+		}
+		for _, p := range paths {
+			if _, err := os.Lstat(p); err != nil {
+				continue
+			}
+			if !quarantine {
+				err = os.RemoveAll(p)
+			} else {
+				rel, _ := filepath.Rel(root, p)
+				if err = os.MkdirAll(filepath.Dir(filepath.Join(dest, rel)), 0o755); err == nil {
+					err = os.Rename(p, filepath.Join(dest, rel))
+				}
+			}
+			if err != nil {
+				return found[:i], fmt.Errorf("repair %s: %w", p, err)
+			}
+		}
+		r.Action = "removed"
+		if quarantine {
+			rel, _ := filepath.Rel(root, r.Path)
+			r.Action = "quarantined to " + filepath.Join(dest, rel)
+		}
+	}
+
+	s.check.mu.Lock()
+	s.check.repairs = found
+	s.check.mu.Unlock()
+	if len(found) > 0 {
+		ev := &pb.Event{Kind: pb.EventKind_EVENT_KIND_CACHE_CLEANED, Entries: int32(len(found))}
+		for _, r := range found {
+			ev.Bytes += r.Size
+		}
+		s.events.publish(ev)
+	}
+	return found, nil
+}
+
+// CacheRepairs returns the repairs of the last CheckCache.
+func (s *Server) CacheRepairs() []CacheRepair {
+	s.check.mu.Lock()
+	defer s.check.mu.Unlock()
+	return append([]CacheRepair(nil), s.check.repairs...)
+}
+
+// emptyTree reports whether no regular file lives under dir.
+func emptyTree(dir string) (bool, error) {
+	empty := true
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			empty = false
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return empty, err
+}
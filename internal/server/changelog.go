@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+)
+
+// forgesEnv names the environment variable listing GitHub-compatible
+// forges besides github.com, whose releases hold the release notes of the
+// dependencies they host: comma-separated host=api-url pairs, e.g.
+// "github.corp.example=https://github.corp.example/api/v3". A
+// GITHUB_TOKEN is sent when set.
+const forgesEnv = "ATLAS_FORGES"
+
+// forgeAPI returns the API of the forge hosting depPath, if it is one.
+func forgeAPI(depPath string) (string, bool) {
+	host, _, _ := strings.Cut(depPath, "/")
+	for _, forge := range strings.Split(os.Getenv(forgesEnv), ",") {
+		h, api, ok := strings.Cut(strings.TrimSpace(forge), "=")
+		if ok && h == host {
+			return strings.TrimRight(api, "/"), true
+		}
+	}
+	if host == "github.com" {
+		return "https://api.github.com", true
+	}
+	return "", false
+}
+
+// releaseNotes returns the notes of the versions of depPath after
+// oldVersion up to newVersion, as markdown: their releases on the forge
+// hosting it, else the sections of the new CHANGELOG.md that the old one
+// lacks. With fetch, versions not cached are fetched into a scratch
+// directory to read their CHANGELOG.md. It returns "" if there are no
+// notes.
+func releaseNotes(ctx context.Context, depPath, oldVersion, newVersion string, fetch bool) string {
+	if api, ok := forgeAPI(depPath); ok {
+		notes, err := forgeReleaseNotes(ctx, api, depPath, oldVersion, newVersion)
+		if err == nil && notes != "" {
+			return notes
+		}
+	}
+	return changelogNotes(ctx, depPath, oldVersion, newVersion, fetch)
+}
+
+// githubRelease is the part of a GitHub release the notes use.
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Body    string `json:"body"`
+}
+
+// forgeReleaseNotes returns the bodies of the releases of the repository
+// of depPath tagged after oldVersion up to newVersion, listed by the
+// GitHub-compatible api, newest first, each under its tag.
+func forgeReleaseNotes(ctx context.Context, api, depPath, oldVersion, newVersion string) (string, error) {
+	parts := strings.SplitN(depPath, "/", 4)
+	if len(parts) < 3 {
+		return "", fmt.Errorf("%s: not an owner/repository path", depPath)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		api+"/repos/"+parts[1]+"/"+parts[2]+"/releases?per_page=100", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("list releases of %s: %s", depPath, resp.Status)
+	}
+	var releases []githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return "", fmt.Errorf("list releases of %s: %w", depPath, err)
+	}
+
+	var kept []githubRelease
+	for _, r := range releases {
+		if semver.Compare(r.TagName, oldVersion) > 0 && semver.Compare(r.TagName, newVersion) <= 0 {
+			kept = append(kept, r)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return semver.Compare(kept[i].TagName, kept[j].TagName) > 0 })
+	var b strings.Builder
+	for _, r := range kept {
+		fmt.Fprintf(&b, "#### %s\n\n", r.TagName)
+		if body := strings.TrimSpace(r.Body); body != "" {
+			fmt.Fprintf(&b, "%s\n\n", demoteHeadings(body))
+		}
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// changelogNotes returns the sections of the CHANGELOG.md of
+// depPath@newVersion whose heading the one of depPath@oldVersion lacks,
+// reading both from the cache, or from a scratch fetch with fetch.
+func changelogNotes(ctx context.Context, depPath, oldVersion, newVersion string, fetch bool) string {
+	read := func(version string) (string, bool) {
+		dir, err := cacheStore().Get(depPath, version)
+		if err != nil {
+			if !fetch {
+				return "", false
+			}
+			scratch, err := os.MkdirTemp("", "atlas-changelog-")
+			if err != nil {
+				return "", false
+			}
+			defer os.RemoveAll(scratch) //nolint:errcheck
+			dir = filepath.Join(scratch, "snapshot")
+			if _, err := fetchUpstream(ctx, depPath, version, dir); err != nil {
+				return "", false
+			}
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "CHANGELOG.md"))
+		if err != nil && !os.IsNotExist(err) {
+			return "", false
+		}
+		return string(data), true
+	}
+	oldLog, ok := read(oldVersion)
+	if !ok {
+		return ""
+	}
+	newLog, ok := read(newVersion)
+	if !ok {
+		return ""
+	}
+
+	known := map[string]bool{}
+	for _, sec := range changelogSections(oldLog) {
+		known[sec.heading] = true
+	}
+	var b strings.Builder
+	for _, sec := range changelogSections(newLog) {
+		if !known[sec.heading] {
+			fmt.Fprintf(&b, "%s\n\n", demoteHeadings(sec.text))
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// changelogSection is a second-level section of a CHANGELOG.md, usually
+// one version.
+type changelogSection struct {
+	heading string // its "## " line
+	text    string // the heading and its content
+}
+
+// changelogSections splits a CHANGELOG.md into its "## " sections; what
+// precedes the first one is dropped.
+func changelogSections(log string) []changelogSection {
+	var secs []changelogSection
+	for _, line := range strings.Split(log, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			secs = append(secs, changelogSection{heading: strings.TrimSpace(line)})
+		}
+		if len(secs) > 0 {
+			secs[len(secs)-1].text += line + "\n"
+		}
+	}
+	for i := range secs {
+		secs[i].text = strings.TrimSpace(secs[i].text)
+	}
+	return secs
+}
+
+// demoteHeadings lowers the markdown headings of notes by two levels, to
+// nest them under the dependency's heading.
+func demoteHeadings(notes string) string {
+	lines := strings.Split(notes, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			lines[i] = "##" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// aggregateChangelog returns the markdown summary of updated, with the
+// notes of each.
+func aggregateChangelog(updated []*pb.UpdatedDependency) string {
+	var b strings.Builder
+	b.WriteString("## Dependency updates\n")
+	for _, u := range updated {
+		fmt.Fprintf(&b, "\n### %s %s → %s\n\n", u.Path, u.OldVersion, u.NewVersion)
+		if len(u.RemovedCapabilities) > 0 {
+			fmt.Fprintf(&b, "**Removes capabilities:** %s\n\n", strings.Join(u.RemovedCapabilities, ", "))
+		}
+		if u.Notes != "" {
+			fmt.Fprintf(&b, "%s\n", u.Notes)
+		} else {
+			b.WriteString("_No release notes found._\n")
+		}
+	}
+	return b.String()
+}
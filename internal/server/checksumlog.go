@@ -0,0 +1,378 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+	"github.com/organic-programming/rhizome-atlas/pkg/tlog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// checksumLogPath returns the file holding the checksum log of this
+// machine, one record per line.
+func checksumLogPath() string {
+	return filepath.Join(filepath.Dir(CacheDir()), "log", "records")
+}
+
+// checksumLog is the append-only log of the snapshots served by
+// FetchEntry, kept in memory as read from checksumLogPath. The file is
+// only ever appended to, so what another Server or process appended is
+// read on the next use.
+type checksumLog struct {
+	mu      sync.Mutex
+	path    string
+	read    int64 // bytes of path loaded
+	hashes  []string
+	leaves  []tlog.Hash
+	byEntry map[string][]int     // path@version → indexes
+	checked map[string]time.Time // path@version → mtime of the snapshot hashed
+}
+
+// logRecord returns the record the log keeps for a snapshot.
+func logRecord(path, version, hash string) []byte {
+	return []byte(path + " " + version + " " + hash + "\n")
+}
+
+// load reads the records appended to the file since the last call.
+// l.mu must be held.
+func (l *checksumLog) load() error {
+	path := checksumLogPath()
+	if path != l.path {
+		l.path, l.read, l.hashes, l.leaves, l.byEntry, l.checked = path, 0, nil, nil, nil, nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil {
+		return err
+	} else if info.Size() < l.read {
+		// Not appended to but rewritten: what was read is no more.
+		l.read, l.hashes, l.leaves, l.byEntry, l.checked = 0, nil, nil, nil, nil
+	}
+	if _, err := f.Seek(l.read, io.SeekStart); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	// A line still being appended is read next time.
+	data = data[:bytes.LastIndexByte(data, '\n')+1]
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return fmt.Errorf("%s: malformed record %q", path, line)
+		}
+		l.add(fields[0], fields[1], fields[2])
+	}
+	l.read += int64(len(data))
+	return nil
+}
+
+// add appends a record to the in-memory log. l.mu must be held.
+func (l *checksumLog) add(path, version, hash string) {
+	if l.byEntry == nil {
+		l.byEntry = map[string][]int{}
+	}
+	key := path + "@" + version
+	l.byEntry[key] = append(l.byEntry[key], len(l.leaves))
+	l.hashes = append(l.hashes, hash)
+	l.leaves = append(l.leaves, tlog.RecordHash(logRecord(path, version, hash)))
+}
+
+// ensure logs the snapshot of path@version in dir, unless its last record
+// has the same hash. A snapshot is only hashed again when its directory
+// changed, e.g. once the cache was cleaned and the version fetched anew.
+func (l *checksumLog) ensure(path, version, dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.load(); err != nil {
+		return err
+	}
+	key := path + "@" + version
+	if t, ok := l.checked[key]; ok && t.Equal(info.ModTime()) {
+		return nil
+	}
+	hash, err := sumHashDir(defaultHashAlgorithm, dir)
+	if err != nil {
+		return err
+	}
+	if l.checked == nil {
+		l.checked = map[string]time.Time{}
+	}
+	if records := l.byEntry[key]; len(records) > 0 && l.hashes[records[len(records)-1]] == hash {
+		l.checked[key] = info.ModTime()
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	record := logRecord(path, version, hash)
+	if _, err := f.Write(record); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// Read it back with anything appended before it.
+	if err := l.load(); err != nil {
+		return err
+	}
+	l.checked[key] = info.ModTime()
+	return nil
+}
+
+// treeSize returns the current size if size is 0, and checks size
+// otherwise. l.mu must be held.
+func (l *checksumLog) treeSize(size int64) (int64, error) {
+	if size == 0 {
+		return int64(len(l.leaves)), nil
+	}
+	if size < 0 || size > int64(len(l.leaves)) {
+		return 0, status.Errorf(codes.OutOfRange, "tree size %d: the log has %d records", size, len(l.leaves))
+	}
+	return size, nil
+}
+
+func (l *checksumLog) head(size int64) *pb.LogHead {
+	root := tlog.TreeHash(l.leaves[:size])
+	return &pb.LogHead{Size: size, RootHash: root[:]}
+}
+
+// GetLogHead returns the head of the checksum log, signed when the server
+// has a signing key (see logSigningKeyEnv).
+func (s *Server) GetLogHead(context.Context, *pb.GetLogHeadRequest) (*pb.LogHead, error) {
+	s.clog.mu.Lock()
+	defer s.clog.mu.Unlock()
+	if err := s.clog.load(); err != nil {
+		return nil, status.Errorf(codes.Internal, "read checksum log: %v", err)
+	}
+	head := s.clog.head(int64(len(s.clog.leaves)))
+	if err := signLogHead(head, time.Now()); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "sign checksum log head: %v", err)
+	}
+	return head, nil
+}
+
+// ProveLogInclusion returns the records of path@version in the tree of
+// req.TreeSize, with their inclusion proofs.
+func (s *Server) ProveLogInclusion(_ context.Context, req *pb.ProveLogInclusionRequest) (*pb.ProveLogInclusionResponse, error) {
+	if req.Path == "" || req.Version == "" {
+		return nil, status.Error(codes.InvalidArgument, "path and version are required")
+	}
+	s.clog.mu.Lock()
+	defer s.clog.mu.Unlock()
+	if err := s.clog.load(); err != nil {
+		return nil, status.Errorf(codes.Internal, "read checksum log: %v", err)
+	}
+	size, err := s.clog.treeSize(req.TreeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ProveLogInclusionResponse{}
+	for _, i := range s.clog.byEntry[req.Path+"@"+req.Version] {
+		if int64(i) >= size {
+			break
+		}
+		proof, err := tlog.ProveRecord(s.clog.leaves[:size], i)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+		record := &pb.LogRecord{Index: int64(i), Hash: s.clog.hashes[i]}
+		for _, h := range proof {
+			record.Proof = append(record.Proof, h[:])
+		}
+		resp.Records = append(resp.Records, record)
+	}
+	if len(resp.Records) == 0 {
+		return nil, status.Errorf(codes.NotFound, "%s@%s is not in the checksum log", req.Path, req.Version)
+	}
+	return resp, nil
+}
+
+// ProveLogConsistency proves that the tree of req.OldSize is a prefix of
+// that of req.NewSize.
+func (s *Server) ProveLogConsistency(_ context.Context, req *pb.ProveLogConsistencyRequest) (*pb.ProveLogConsistencyResponse, error) {
+	s.clog.mu.Lock()
+	defer s.clog.mu.Unlock()
+	if err := s.clog.load(); err != nil {
+		return nil, status.Errorf(codes.Internal, "read checksum log: %v", err)
+	}
+	size, err := s.clog.treeSize(req.NewSize)
+	if err != nil {
+		return nil, err
+	}
+	if req.OldSize < 0 || req.OldSize > size {
+		return nil, status.Errorf(codes.OutOfRange, "old size %d is not within 0..%d", req.OldSize, size)
+	}
+	proof, err := tlog.ProveTree(s.clog.leaves[:size], int(req.OldSize))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	resp := &pb.ProveLogConsistencyResponse{Head: s.clog.head(size)}
+	for _, h := range proof {
+		resp.Proof = append(resp.Proof, h[:])
+	}
+	return resp, nil
+}
+
+// verifyLog checks the entries of sum against the checksum log of the atlas
+// server ATLAS_CACHE_REMOTE names: the versions it logged must have been
+// served with the content sum records. The log must also have only grown
+// since the head last seen from that server, which is then remembered,
+// and be signed and fresh when logKeyEnv pins the key of the server.
+// Only the entries of the default algorithm can be compared.
+func verifyLog(ctx context.Context, sum *modfile.SumFile) []string {
+	uri := os.Getenv(remoteCacheEnv)
+	remote, err := newRemoteStore(uri)
+	if err != nil {
+		return []string{fmt.Sprintf("checksum log: %v", err)}
+	}
+	peer, ok := remote.(peerStore)
+	if !ok {
+		return []string{fmt.Sprintf("checksum log: %s=%q names no atlas server", remoteCacheEnv, uri)}
+	}
+	rpc := peer.c.Service()
+	head, err := rpc.GetLogHead(ctx, &pb.GetLogHeadRequest{})
+	if err != nil {
+		return []string{fmt.Sprintf("checksum log of %s: %v", uri, err)}
+	}
+	if err := checkLogHead(head, time.Now()); err != nil {
+		return []string{fmt.Sprintf("checksum log of %s: %v", uri, err)}
+	}
+	root, err := tlog.ParseHash(head.RootHash)
+	if err != nil {
+		return []string{fmt.Sprintf("checksum log of %s: head: %v", uri, err)}
+	}
+	if err := checkLogGrowth(ctx, rpc, uri, head.Size, root); err != nil {
+		return []string{fmt.Sprintf("checksum log of %s: %v", uri, err)}
+	}
+	if head.Size == 0 {
+		return nil
+	}
+
+	var problems []string
+	for _, entry := range sum.Entries {
+		if strings.HasSuffix(entry.Version, "/HOLON.md") || hashAlgorithm(entry.Hash) != defaultHashAlgorithm {
+			continue
+		}
+		resp, err := rpc.ProveLogInclusion(ctx, &pb.ProveLogInclusionRequest{Path: entry.Path, Version: entry.Version, TreeSize: head.Size})
+		if status.Code(err) == codes.NotFound {
+			continue // never served by this server
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s %s: checksum log: %v", entry.Path, entry.Version, err))
+			continue
+		}
+		for _, r := range resp.Records {
+			if err := checkLogRecord(r, entry.Path, entry.Version, head.Size, root); err != nil {
+				problems = append(problems, fmt.Sprintf("%s %s: checksum log record %d: %v", entry.Path, entry.Version, r.Index, err))
+			} else if r.Hash != entry.Hash {
+				problems = append(problems, fmt.Sprintf("%s %s: %s served %s (holon.sum has %s)", entry.Path, entry.Version, uri, r.Hash, entry.Hash))
+			}
+		}
+	}
+	return problems
+}
+
+// checkLogRecord checks the inclusion proof of r, a record of
+// path@version, in the tree of size and root.
+func checkLogRecord(r *pb.LogRecord, path, version string, size int64, root tlog.Hash) error {
+	var proof []tlog.Hash
+	for _, b := range r.Proof {
+		h, err := tlog.ParseHash(b)
+		if err != nil {
+			return err
+		}
+		proof = append(proof, h)
+	}
+	return tlog.CheckRecord(proof, size, r.Index, root, tlog.RecordHash(logRecord(path, version, r.Hash)))
+}
+
+// logHeadPath returns where the last head seen from the checksum log of
+// the server at uri is remembered.
+func logHeadPath(uri string) string {
+	return filepath.Join(filepath.Dir(CacheDir()), "log", "heads", url.PathEscape(uri))
+}
+
+// checkLogGrowth checks that the head of size and root of the checksum log
+// at uri extends the head last seen from it, and remembers it.
+func checkLogGrowth(ctx context.Context, rpc pb.RhizomeAtlasServiceClient, uri string, size int64, root tlog.Hash) error {
+	path := logHeadPath(uri)
+	if data, err := os.ReadFile(path); err == nil {
+		var oldSize int64
+		var oldHex string
+		if _, err := fmt.Sscanf(string(data), "%d %s", &oldSize, &oldHex); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		b, err := hex.DecodeString(oldHex)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		oldRoot, err := tlog.ParseHash(b)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if oldSize > size {
+			return fmt.Errorf("log shrank from %d to %d records", oldSize, size)
+		}
+		resp, err := rpc.ProveLogConsistency(ctx, &pb.ProveLogConsistencyRequest{OldSize: oldSize, NewSize: size})
+		if err != nil {
+			return err
+		}
+		var proof []tlog.Hash
+		for _, b := range resp.Proof {
+			h, err := tlog.ParseHash(b)
+			if err != nil {
+				return err
+			}
+			proof = append(proof, h)
+		}
+		if err := tlog.CheckTree(proof, size, root, oldSize, oldRoot); err != nil {
+			return fmt.Errorf("log of %d records is not an extension of the one of %d seen before: %w", size, oldSize, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, fmt.Appendf(nil, "%d %x\n", size, root), 0o644)
+}
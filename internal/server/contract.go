@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/holonmd"
+)
+
+// removedCapabilities fetches depPath at both versions and returns the
+// capabilities declared by the old HOLON.md that the new one no longer
+// declares. A version without HOLON.md declares no contract, so nothing
+// can be reported as removed.
+func removedCapabilities(ctx context.Context, depPath, oldVersion, newVersion string) ([]string, error) {
+	oldCaps, err := capabilitiesAt(ctx, depPath, oldVersion)
+	if err != nil || oldCaps == nil {
+		return nil, err
+	}
+	newCaps, err := capabilitiesAt(ctx, depPath, newVersion)
+	if err != nil {
+		return nil, err
+	}
+	return diffCapabilities(oldCaps, newCaps), nil
+}
+
+// capabilitiesAt returns the capabilities declared by depPath@version,
+// or nil if that version has no HOLON.md.
+func capabilitiesAt(ctx context.Context, depPath, version string) ([]string, error) {
+	dir, err := fetchToCache(ctx, depPath, version)
+	if err != nil {
+		return nil, err
+	}
+	fm, err := holonmd.Parse(filepath.Join(dir, "HOLON.md"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fm.Capabilities == nil {
+		return []string{}, nil
+	}
+	return fm.Capabilities, nil
+}
+
+// diffCapabilities returns the entries of old missing from new, in order.
+func diffCapabilities(old, new []string) []string {
+	kept := make(map[string]bool, len(new))
+	for _, c := range new {
+		kept[c] = true
+	}
+	var removed []string
+	for _, c := range old {
+		if !kept[c] {
+			removed = append(removed, c)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/holonmd"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+)
+
+// latestDeprecation returns the deprecation of path@version, fetched from
+// src, that the latest release of src declares; nil for none, or when src
+// has no release.
+func latestDeprecation(ctx context.Context, path, src, version string) (*pb.Deprecation, error) {
+	tags, err := remoteTags(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	latest := latestTag(tags)
+	if latest == "" {
+		return nil, nil
+	}
+	return deprecationAt(ctx, path, src, version, latest)
+}
+
+// deprecationAt returns the deprecation of path@version declared by the
+// release latest of src, read from the cache, or from a scratch fetch when
+// it is not cached: a deprecated directive of its holon.mod for version,
+// else for the whole holon, else the "deprecated" key of its HOLON.md
+// front matter, whose value is the message ("true" for none) with a
+// "successor" key alongside. Nil for none.
+func deprecationAt(ctx context.Context, path, src, version, latest string) (*pb.Deprecation, error) {
+	dir, err := cacheStore().Get(src, latest)
+	if err != nil {
+		scratch, err := os.MkdirTemp("", "atlas-deprecation-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(scratch) //nolint:errcheck
+		dir = filepath.Join(scratch, "snapshot")
+		if _, err := fetchUpstream(ctx, src, latest, dir); err != nil {
+			return nil, err
+		}
+	}
+	mod, err := modfile.Parse(filepath.Join(dir, "holon.mod"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if mod != nil {
+		if d, ok := mod.DeprecationOf(version); ok {
+			return &pb.Deprecation{
+				Path:       path,
+				Version:    version,
+				Holon:      d.Version == "",
+				Message:    d.Message,
+				Successor:  d.Successor,
+				DeclaredBy: latest,
+			}, nil
+		}
+	}
+
+	fm, err := holonmd.Parse(filepath.Join(dir, "HOLON.md"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	message := fm.String("deprecated")
+	switch message {
+	case "", "false":
+		return nil, nil
+	case "true":
+		message = ""
+	}
+	return &pb.Deprecation{
+		Path:       path,
+		Version:    version,
+		Holon:      true,
+		Message:    message,
+		Successor:  fm.String("successor"),
+		DeclaredBy: latest,
+	}, nil
+}
+
+// requireDeprecations returns the deprecations of reqs, required by mod,
+// in order. Deprecations are advisory: a dependency whose latest release
+// cannot be read is left out rather than failing the caller.
+func requireDeprecations(ctx context.Context, mod *modfile.ModFile, reqs []modfile.Require) []*pb.Deprecation {
+	found := make([]*pb.Deprecation, len(reqs))
+	var wg sync.WaitGroup
+	for i, r := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found[i], _ = latestDeprecation(ctx, r.Path, mod.SourcePath(r.Path), r.Version)
+		}()
+	}
+	wg.Wait()
+	var deprecated []*pb.Deprecation
+	for _, d := range found {
+		if d != nil {
+			deprecated = append(deprecated, d)
+		}
+	}
+	return deprecated
+}
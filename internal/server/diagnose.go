@@ -0,0 +1,258 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+)
+
+// Diagnose checks the holon.mod in req.Directory, or req.Content in its
+// place, and reports its problems: invalid lines and versions,
+// prereleases the stable directive forbids, dependencies missing from the
+// cache or holon.sum, snapshots no longer matching holon.sum or lacking
+// the provenance the provenance directive requires, replace directives
+// that are unused or point to a missing directory, and requires and
+// replaces past their until date. With req.Remote, versions upstream does
+// not tag and available updates are reported too. Problems are reported
+// rather than failing the call; it fails only when holon.mod or holon.sum
+// cannot be read.
+func (s *Server) Diagnose(ctx context.Context, req *pb.DiagnoseRequest) (*pb.DiagnoseResponse, error) {
+	dir := holonDir(req.Directory)
+	modPath := filepath.Join(dir, "holon.mod")
+	data := []byte(req.Content)
+	if req.Content == "" {
+		var err error
+		if data, err = os.ReadFile(modPath); err != nil {
+			return nil, modError(modPath, err)
+		}
+	}
+	d := &diagnoser{lines: strings.Split(string(data), "\n")}
+
+	mod, err := modfile.ParseBytes(data)
+	var perr *modfile.ParseError
+	if errors.As(err, &perr) {
+		d.report(d.wholeLine(perr.Line), pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_ERROR, "invalid-line", perr.Err.Error())
+		return &pb.DiagnoseResponse{Diagnostics: d.diags}, nil
+	}
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+
+	sumPath := filepath.Join(dir, "holon.sum")
+	sum, err := s.parseSum(sumPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		sum, err = &modfile.SumFile{}, nil
+	}
+	if err != nil {
+		return nil, sumError(sumPath, err)
+	}
+
+	ctx = withFetchSources(ctx, mod)
+	for _, dep := range mod.Require {
+		d.require(ctx, dir, mod, sum, dep, req.Remote)
+	}
+	for _, r := range mod.Replace {
+		if !slices.ContainsFunc(mod.Require, func(dep modfile.Require) bool { _, ok := r.Match(dep.Path); return ok }) {
+			d.report(d.wholeLine(r.Line), pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_WARNING, "unused-replace",
+				fmt.Sprintf("no dependency matches %s", r.Old), d.removeLine("Remove the replace directive", r.Line))
+		}
+	}
+
+	now := time.Now()
+	for _, dep := range mod.Require {
+		if until, ok := dep.Until(); ok && modfile.Expired(until, now) {
+			d.expired(dep.Line, fmt.Sprintf("%s %s was to stay until %s", dep.Path, dep.Version, until.Format(time.DateOnly)))
+		}
+	}
+	for _, r := range mod.Replace {
+		if until, ok := r.Until(); ok && modfile.Expired(until, now) {
+			d.expired(r.Line, fmt.Sprintf("replace %s => %s was to stay until %s", r.Old, r.New, until.Format(time.DateOnly)))
+		}
+	}
+
+	sort.SliceStable(d.diags, func(i, j int) bool { return d.diags[i].Line < d.diags[j].Line })
+	return &pb.DiagnoseResponse{Diagnostics: d.diags}, nil
+}
+
+// diagnoser collects the diagnostics of the lines of a holon.mod.
+type diagnoser struct {
+	lines []string
+	diags []*pb.Diagnostic
+}
+
+// span is a range of columns of a line, as in pb.Diagnostic.
+type span struct {
+	line, column, endColumn int
+}
+
+// report adds a diagnostic of the text at sp.
+func (d *diagnoser) report(sp span, severity pb.DiagnosticSeverity, code, message string, fixes ...*pb.Fix) {
+	d.diags = append(d.diags, &pb.Diagnostic{
+		Line:      int32(sp.line),
+		Column:    int32(sp.column),
+		EndColumn: int32(sp.endColumn),
+		Severity:  severity,
+		Code:      code,
+		Message:   message,
+		Fixes:     fixes,
+	})
+}
+
+// text returns the given line, counted from 1, or "" past the end.
+func (d *diagnoser) text(line int) string {
+	if line < 1 || line > len(d.lines) {
+		return ""
+	}
+	return strings.TrimSuffix(d.lines[line-1], "\r")
+}
+
+// wholeLine spans the text of line, without its indentation and trailing
+// spaces.
+func (d *diagnoser) wholeLine(line int) span {
+	text := strings.TrimRight(d.text(line), " \t")
+	start := len(text) - len(strings.TrimLeft(text, " \t"))
+	return span{line, start + 1, len(text) + 1}
+}
+
+// word spans the first occurrence of word in line after the column after.
+func (d *diagnoser) word(line int, word string, after int) span {
+	text := d.text(line)
+	if after > len(text) {
+		after = len(text)
+	}
+	i := strings.Index(text[after:], word)
+	if i < 0 {
+		return d.wholeLine(line)
+	}
+	return span{line, after + i + 1, after + i + len(word) + 1}
+}
+
+// expired reports the until annotation of line as passed.
+func (d *diagnoser) expired(line int, message string) {
+	d.report(d.word(line, modfile.UntilKey+"=", 0), pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_WARNING, "expired", message)
+}
+
+// removeLine is a fix deleting line.
+func (d *diagnoser) removeLine(title string, line int) *pb.Fix {
+	return &pb.Fix{Title: title, Edits: []*pb.TextEdit{{Line: int32(line), Column: 1, EndLine: int32(line + 1), EndColumn: 1}}}
+}
+
+// replace is a fix replacing the text at sp with text.
+func (d *diagnoser) replace(title string, sp span, text string) *pb.Fix {
+	return &pb.Fix{Title: title, Edits: []*pb.TextEdit{{
+		Line: int32(sp.line), Column: int32(sp.column), EndLine: int32(sp.line), EndColumn: int32(sp.endColumn), NewText: text,
+	}}}
+}
+
+// require reports the problems of dep, required by the holon.mod of dir.
+func (d *diagnoser) require(ctx context.Context, dir string, mod *modfile.ModFile, sum *modfile.SumFile, dep modfile.Require, remote bool) {
+	const (
+		errorSev   = pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_ERROR
+		warningSev = pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_WARNING
+		infoSev    = pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_INFO
+	)
+	pathSpan := d.word(dep.Line, dep.Path, 0)
+	versionSpan := d.word(dep.Line, dep.Version, pathSpan.endColumn-1)
+	remove := d.removeLine("Remove "+dep.Path, dep.Line)
+	update := func(version string) *pb.Fix {
+		return d.replace("Update to "+version, versionSpan, version)
+	}
+
+	// A local replace stands for the dependency: only its directory matters.
+	if local := mod.ResolvedPath(dep.Path); local != "" {
+		if _, err := os.Stat(localPath(dir, local)); err != nil {
+			r, _, _ := mod.Replacement(dep.Path)
+			d.report(d.wholeLine(r.Line), errorSev, "replace-missing",
+				fmt.Sprintf("%s, replacing %s, does not exist", local, dep.Path))
+		}
+		return
+	}
+
+	src := mod.SourcePath(dep.Path)
+	_, digest := parseDigest(dep.Version)
+	if _, _, _, ok := semver.Parse(dep.Version); !ok && !digest {
+		d.report(versionSpan, errorSev, "invalid-version",
+			fmt.Sprintf("%s is neither a semver tag nor a %s digest", dep.Version, strings.TrimSuffix(digestPrefix, ":")), remove)
+		return
+	}
+
+	var tags []string
+	if remote && !digest {
+		var err error
+		if tags, err = remoteTags(ctx, src); err != nil {
+			d.report(pathSpan, infoSev, "tags-unavailable", fmt.Sprintf("cannot list the versions of %s: %v", src, err))
+		}
+	}
+	stableUpdate := func() []*pb.Fix {
+		latest := compatibleTag(tags, dep.Version, "")
+		if latest == dep.Version || semver.Prerelease(latest) != "" {
+			return nil
+		}
+		return []*pb.Fix{update(latest)}
+	}
+
+	forbidden := mod.Stable && semver.Prerelease(dep.Version) != ""
+	if forbidden {
+		d.report(versionSpan, errorSev, "prerelease-forbidden",
+			fmt.Sprintf("prerelease %s is forbidden by the stable directive", dep.Version), stableUpdate()...)
+	}
+
+	switch {
+	case tags == nil:
+	case !slices.Contains(tags, dep.Version):
+		fixes := stableUpdate()
+		if latest := latestTag(tags); len(fixes) == 0 && latest != "" {
+			fixes = append(fixes, update(latest))
+		}
+		d.report(versionSpan, errorSev, "unknown-version",
+			fmt.Sprintf("%s has no version %s", src, dep.Version), append(fixes, remove)...)
+	default:
+		channel := semver.Channel(dep.Version)
+		if mod.Stable {
+			channel = ""
+		}
+		if latest := compatibleTag(tags, dep.Version, channel); latest != dep.Version && !forbidden {
+			d.report(versionSpan, infoSev, "update-available",
+				fmt.Sprintf("%s %s is available", src, latest), update(latest))
+		}
+	}
+
+	cached, _ := cacheStore().Get(src, dep.Version)
+	vendored := ""
+	if cached == "" {
+		vendored = vendoredCopy(dir, mod, src, dep.Version)
+	}
+	switch {
+	case cached == "" && vendored == "":
+		// Optional dependencies are only fetched when asked for.
+		if !dep.Optional {
+			d.report(pathSpan, warningSev, "not-cached",
+				fmt.Sprintf("%s@%s not in cache — run 'atlas pull'", src, dep.Version))
+		}
+		return
+	case sum.Lookup(src, dep.Version) == "":
+		d.report(pathSpan, warningSev, "not-in-sum",
+			fmt.Sprintf("%s@%s has no holon.sum entry — run 'atlas pull'", src, dep.Version))
+	case cached != "" && cacheMismatch(sum, src, dep.Version, cached) != "":
+		d.report(pathSpan, errorSev, "hash-mismatch",
+			fmt.Sprintf("cached %s@%s no longer matches holon.sum — run 'atlas verify --repair'", src, dep.Version))
+	case vendored != "" && cacheMismatch(sum, src, dep.Version, vendored) != "":
+		d.report(pathSpan, errorSev, "hash-mismatch",
+			fmt.Sprintf("vendored %s@%s no longer matches holon.sum — run 'atlas vendor'", src, dep.Version))
+	}
+	if mod.Provenance && cached != "" && readProvenance(src, dep.Version) == nil {
+		d.report(pathSpan, errorSev, "no-provenance",
+			fmt.Sprintf("%s@%s has no provenance attestation, which the provenance directive requires", src, dep.Version), remove)
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"path/filepath"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// envPrefix starts the name of the environment variable of each
+// dependency.
+const envPrefix = "HOLON_DEP_"
+
+// Env locates every dependency required by the holon in req.Directory
+// (of req.Group only, when set): the directory of its local replace, else
+// its cache entry, else its vendored copy. Optional dependencies not
+// pulled are left out; others missing fail the call with NotCached, and
+// two dependencies whose variables would have the same name fail it with
+// FailedPrecondition.
+func (s *Server) Env(_ context.Context, req *pb.EnvRequest) (*pb.EnvResponse, error) {
+	dir := holonDir(req.Directory)
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	if mod, err = groupMod(mod, req.Group); err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s: %v", dir, err)
+	}
+
+	resp := &pb.EnvResponse{Root: dir}
+	var missing []string
+	byVariable := map[string]string{}
+	for _, dep := range mod.Require {
+		env := &pb.DependencyEnv{Path: dep.Path, Version: dep.Version, Variable: envVariable(cmp.Or(dep.Alias, dep.Path))}
+		src := mod.SourcePath(dep.Path)
+		if local := mod.ResolvedPath(dep.Path); local != "" {
+			env.Dir, env.Location = localPath(abs, local), "replace"
+		} else if cached, err := cacheStore().Get(src, dep.Version); err == nil {
+			env.Dir, env.Location = cached, "cache"
+		} else if vendored := vendoredCopy(abs, mod, src, dep.Version); vendored != "" {
+			env.Dir, env.Location = vendored, "vendor"
+		}
+		if env.Dir == "" {
+			if !dep.Optional {
+				missing = append(missing, src+"@"+dep.Version)
+			}
+			continue
+		}
+
+		if other, dup := byVariable[env.Variable]; dup {
+			return nil, status.Errorf(codes.FailedPrecondition,
+				"%s and %s would both be %s — give one an alias in holon.mod", other, dep.Path, env.Variable)
+		}
+		byVariable[env.Variable] = dep.Path
+		resp.Dependencies = append(resp.Dependencies, env)
+	}
+	if len(missing) > 0 {
+		return nil, notCachedError(missing)
+	}
+	return resp, nil
+}
+
+// envVariable returns the environment variable naming the dependency
+// called name, an alias or a path.
+func envVariable(name string) string {
+	name = strings.ToUpper(name[strings.LastIndex(name, "/")+1:])
+	return envPrefix + strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
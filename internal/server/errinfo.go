@@ -0,0 +1,143 @@
+package server
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/client"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// detailed is status.Errorf with an ErrorInfo of reason and md attached,
+// and the violations, if any, as a PreconditionFailure, so that clients
+// can act on the error without parsing its message. The reasons and
+// metadata keys are documented in pkg/client.
+func detailed(c codes.Code, reason string, md map[string]string, violations []*errdetails.PreconditionFailure_Violation, format string, args ...any) error {
+	st := status.Newf(c, format, args...)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: reason, Domain: client.ErrorDomain, Metadata: md}}
+	if len(violations) > 0 {
+		details = append(details, &errdetails.PreconditionFailure{Violations: violations})
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// StatusError is the status error of an RPC in plain Go types, for
+// callers in the same process that do not deal in gRPC statuses, such as
+// pkg/atlas.
+type StatusError struct {
+	Code    string // name of the gRPC code, e.g. "NotFound"
+	Message string
+	// Reason and Metadata come from the ErrorInfo of the status, if any.
+	Reason   string
+	Metadata map[string]string
+}
+
+// AsStatusError returns the StatusError of err, or false if err carries
+// no gRPC status, e.g. a canceled context.
+func AsStatusError(err error) (StatusError, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return StatusError{}, false
+	}
+	e := StatusError{Code: st.Code().String(), Message: st.Message()}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Domain == client.ErrorDomain {
+			e.Reason, e.Metadata = info.Reason, info.Metadata
+		}
+	}
+	return e, true
+}
+
+// modError reports that holon.mod at modPath could not be parsed.
+func modError(modPath string, err error) error {
+	md := map[string]string{"file": modPath}
+	if errors.Is(err, fs.ErrNotExist) {
+		md["run"] = "atlas init <holon-path>"
+	}
+	return detailed(codes.NotFound, client.ReasonModUnreadable, md, nil, "parse holon.mod: %v", err)
+}
+
+// sumError reports that holon.sum at sumPath could not be parsed.
+func sumError(sumPath string, err error) error {
+	return detailed(codes.NotFound, client.ReasonSumUnreadable, map[string]string{"file": sumPath}, nil, "parse holon.sum: %v", err)
+}
+
+// unknownDependencyError reports that name is not required in modPath.
+func unknownDependencyError(modPath, name string) error {
+	return detailed(codes.NotFound, client.ReasonDependencyUnknown,
+		map[string]string{"dependency": name, "file": modPath}, nil,
+		"dependency %q not found in holon.mod", name)
+}
+
+// notCachedError reports the dependencies, as path@version, missing from
+// the cache.
+func notCachedError(missing []string) error {
+	var violations []*errdetails.PreconditionFailure_Violation
+	for _, dep := range missing {
+		violations = append(violations, &errdetails.PreconditionFailure_Violation{
+			Type:        client.ReasonNotCached,
+			Subject:     dep,
+			Description: "not in cache",
+		})
+	}
+	return detailed(codes.FailedPrecondition, client.ReasonNotCached,
+		map[string]string{"dependency": missing[0], "run": "atlas pull"}, violations,
+		"%s not in cache — run 'atlas pull' first", strings.Join(missing, ", "))
+}
+
+// hashMismatchError reports that the content of dep (path@version) does
+// not match what file records.
+func hashMismatchError(dep, file, format string, args ...any) error {
+	return detailed(codes.DataLoss, client.ReasonHashMismatch,
+		map[string]string{"dependency": dep, "file": file}, nil,
+		"%s: %s", dep, fmt.Sprintf(format, args...))
+}
+
+// prereleaseError reports a prerelease forbidden by the stable directive
+// of modPath.
+func prereleaseError(modPath, what string) error {
+	return detailed(codes.FailedPrecondition, client.ReasonPrereleaseForbidden,
+		map[string]string{"file": modPath}, nil,
+		"%s: prerelease versions are forbidden by the stable directive", what)
+}
+
+// trustRequiredError reports that dep (path@version), never trusted, must
+// be confirmed as rec before the tofu directive of modPath lets it in.
+func trustRequiredError(modPath, dep string, rec *pb.TrustRecord) error {
+	return detailed(codes.FailedPrecondition, client.ReasonTrustRequired,
+		map[string]string{
+			"file":       modPath,
+			"dependency": dep,
+			"hash":       rec.Hash,
+			"commit":     rec.Commit,
+			"source":     rec.Source,
+		}, nil,
+		"%s: first use must be confirmed, as the tofu directive requires: hash %s, commit %s, source %s", dep, rec.Hash, cmp.Or(rec.Commit, "unknown"), cmp.Or(rec.Source, "unknown"))
+}
+
+// lockStaleError reports that the holon.lock at lockPath does not match
+// the holon.mod at modPath, as a locked pull requires.
+func lockStaleError(lockPath, modPath string) error {
+	return detailed(codes.FailedPrecondition, client.ReasonLockStale,
+		map[string]string{"file": lockPath, "run": "atlas lock"}, nil,
+		"%s is missing or does not match %s — run 'atlas lock'", lockPath, modPath)
+}
+
+// provenanceError reports a dependency without the provenance attestation
+// that the provenance directive of modPath requires.
+func provenanceError(modPath, depPath, version string) error {
+	return detailed(codes.FailedPrecondition, client.ReasonProvenanceMissing,
+		map[string]string{"file": modPath, "dependency": depPath + "@" + version}, nil,
+		"%s@%s: release has no provenance attestation, required by the provenance directive", depPath, version)
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+)
+
+// eventBuffer is how many events a subscriber may lag behind before the
+// next ones are dropped for it.
+const eventBuffer = 256
+
+// eventBus fans the events of a Server out to its subscribers.
+type eventBus struct {
+	mu        sync.Mutex
+	seq       uint64
+	subs      map[*subscriber]struct{}
+	announced map[string]bool // updates published, by directory, path and version
+}
+
+type subscriber struct {
+	kinds []pb.EventKind // all when empty
+	ch    chan *pb.Event
+}
+
+// Subscribe streams the events of s until the call is canceled. Headers
+// are sent once the subscriber is registered, so that a client waiting
+// for them misses none of the events that follow.
+func (s *Server) Subscribe(req *pb.SubscribeRequest, stream pb.RhizomeAtlasService_SubscribeServer) error {
+	sub := s.events.subscribe(req.Kinds)
+	defer s.events.unsubscribe(sub)
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+	for {
+		select {
+		case ev := <-sub.ch:
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (b *eventBus) subscribe(kinds []pb.EventKind) *subscriber {
+	sub := &subscriber{kinds: kinds, ch: make(chan *pb.Event, eventBuffer)}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs == nil {
+		b.subs = map[*subscriber]struct{}{}
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+func (b *eventBus) unsubscribe(sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs, sub)
+}
+
+// publish numbers and timestamps ev, then hands it to the subscribers
+// asking for its kind. A subscriber whose buffer is full misses it.
+func (b *eventBus) publish(ev *pb.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	ev.Sequence = b.seq
+	ev.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	for sub := range b.subs {
+		if len(sub.kinds) > 0 && !slices.Contains(sub.kinds, ev.Kind) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+		}
+	}
+}
+
+// announceUpdates publishes the updates of the holon in dir not published
+// yet.
+func (b *eventBus) announceUpdates(dir string, updates []*pb.UpdatedDependency) {
+	for _, u := range updates {
+		key := dir + "\x00" + u.Path + "@" + u.NewVersion
+		b.mu.Lock()
+		seen := b.announced[key]
+		if b.announced == nil {
+			b.announced = map[string]bool{}
+		}
+		b.announced[key] = true
+		b.mu.Unlock()
+		if !seen {
+			b.publish(&pb.Event{
+				Kind:       pb.EventKind_EVENT_KIND_UPDATE_AVAILABLE,
+				Directory:  dir,
+				Dependency: &pb.Dependency{Path: u.Path, Version: u.OldVersion},
+				NewVersion: u.NewVersion,
+			})
+		}
+	}
+}
+
+type eventsKey struct{}
+
+// withEvents returns ctx carrying the event bus of s, on which the
+// fetches made under it are published.
+func (s *Server) withEvents(ctx context.Context) context.Context {
+	return context.WithValue(ctx, eventsKey{}, &s.events)
+}
+
+// publish publishes ev on the event bus ctx carries, if any.
+func publish(ctx context.Context, ev *pb.Event) {
+	if b, ok := ctx.Value(eventsKey{}).(*eventBus); ok {
+		b.publish(ev)
+	}
+}
@@ -0,0 +1,909 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/fetch"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+	"github.com/organic-programming/rhizome-atlas/pkg/provenance"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+	"github.com/organic-programming/rhizome-atlas/pkg/tuf"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// proxyEnv names the environment variable listing mirrors to fall back to
+// when a dependency cannot be reached directly: comma-separated URL
+// prefixes, each serving <prefix>/<dep-path> as a git repository.
+const proxyEnv = "ATLAS_PROXY"
+
+// fetchInfo is the metadata recorded next to each cache snapshot, in
+// <cache>/<dep-path>@<version>.info.
+type fetchInfo struct {
+	Source    string    `json:"source"`  // git URL that served the content
+	Fetcher   string    `json:"fetcher"` // see pb.FetchInfo.fetcher
+	Time      time.Time `json:"time"`
+	Commit    string    `json:"commit,omitempty"`
+	TagObject string    `json:"tag_object,omitempty"`
+}
+
+// registryEnv names the environment variable giving the URL prefix
+// tried first for every dependency, serving <prefix>/<dep-path> as a git
+// repository.
+const registryEnv = "ATLAS_REGISTRY"
+
+// fetchSources are the registry and proxies fetches go through.
+type fetchSources struct {
+	registry string
+	proxies  []string
+}
+
+type fetchSourcesKey struct{}
+
+// withFetchSources returns ctx carrying the registry and proxy directives
+// of mod, for the fetches made on behalf of its holon.
+func withFetchSources(ctx context.Context, mod *modfile.ModFile) context.Context {
+	if mod == nil || mod.Registry == "" && len(mod.Proxy) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, fetchSourcesKey{}, fetchSources{registry: mod.Registry, proxies: mod.Proxy})
+}
+
+// sourcesFrom returns the registry and proxies of the fetches made with
+// ctx. ATLAS_REGISTRY and ATLAS_PROXY, the configuration of the user or
+// their organization, override those a holon.mod declares once set, even
+// to nothing: an empty ATLAS_PROXY forbids every mirror.
+func sourcesFrom(ctx context.Context) fetchSources {
+	src, _ := ctx.Value(fetchSourcesKey{}).(fetchSources)
+	if registry, ok := os.LookupEnv(registryEnv); ok {
+		src.registry = strings.TrimRight(strings.TrimSpace(registry), "/")
+	}
+	if proxies, ok := os.LookupEnv(proxyEnv); ok {
+		src.proxies = strings.Split(proxies, ",")
+	}
+	return src
+}
+
+// urls returns the git URLs to try for depPath, in order: the registry,
+// the direct repository, with and without the .git suffix, over SSH if
+// ATLAS_GIT_AUTH says so and HTTPS otherwise, then each proxy.
+func (src fetchSources) urls(depPath string) []string {
+	var urls []string
+	if registry := strings.TrimRight(src.registry, "/"); registry != "" {
+		urls = append(urls, registry+"/"+depPath)
+	}
+	if ssh := sshURLs(depPath); ssh != nil {
+		urls = append(urls, ssh...)
+	} else {
+		urls = append(urls, "https://"+depPath+".git", "https://"+depPath)
+	}
+	for _, proxy := range src.proxies {
+		proxy = strings.TrimRight(strings.TrimSpace(proxy), "/")
+		if proxy != "" {
+			urls = append(urls, proxy+"/"+depPath)
+		}
+	}
+	return urls
+}
+
+// sources returns the git URLs to try for depPath with the registry and
+// proxies of ctx (see fetchSources.urls).
+func sources(ctx context.Context, depPath string) []string {
+	return sourcesFrom(ctx).urls(depPath)
+}
+
+// digestPrefix marks a digest-addressed version: "sha256:<hex>", the
+// hash of the snapshot's content as recorded in holon.sum.
+const digestPrefix = "sha256:"
+
+// parseDigest returns the hex digest of a digest-addressed version.
+func parseDigest(version string) (string, bool) {
+	digest, ok := strings.CutPrefix(version, digestPrefix)
+	if !ok {
+		return "", false
+	}
+	if _, err := hex.DecodeString(digest); err != nil || len(digest) != 2*sha256.Size {
+		return "", false
+	}
+	return digest, true
+}
+
+// digestURLs returns the URLs serving depPath by content digest. Only
+// the registry and proxies can: each serves
+// <prefix>/<dep-path>/@sha256/<hex> as a git repository whose default
+// branch holds the content.
+func (src fetchSources) digestURLs(depPath, digest string) []string {
+	var urls []string
+	for _, prefix := range append([]string{src.registry}, src.proxies...) {
+		prefix = strings.TrimRight(strings.TrimSpace(prefix), "/")
+		if prefix != "" {
+			urls = append(urls, prefix+"/"+depPath+"/@sha256/"+digest)
+		}
+	}
+	return urls
+}
+
+// fetcherFor returns the Fetcher registered for the host of depPath, or
+// the git fetcher, going through the registry and proxies of ctx.
+func fetcherFor(ctx context.Context, depPath string) fetch.Fetcher {
+	if f, ok := fetch.Lookup(depPath); ok {
+		return f
+	}
+	return gitFetcher{sources: sourcesFrom(ctx)}
+}
+
+// fetcherName names the Fetcher of depPath in its fetch metadata.
+func fetcherName(depPath string) string {
+	if f, ok := fetch.Lookup(depPath); ok {
+		return fmt.Sprintf("%T", f)
+	}
+	return "git"
+}
+
+// fetchToCache fetches depPath at version into the cache, unless it is
+// already there, through the fetch scheduler: callers asking for the same
+// version at once share one fetch.
+func fetchToCache(ctx context.Context, depPath, version string) (string, error) {
+	if dir, err := (fsStore{}).Get(depPath, version); err == nil {
+		return dir, nil
+	}
+	return fetches.do(ctx, depPath+"@"+version, func(ctx context.Context) (string, error) {
+		return fetchSnapshot(ctx, depPath, version)
+	})
+}
+
+// fetchSnapshot fetches depPath at version into the cache with
+// fetchUpstream, unless it is already there. The content is staged outside
+// the cache and only stored once complete, so that a fetch failing or
+// canceled through ctx leaves nothing behind. The fetch is published on
+// the event bus ctx carries, if any, and its progress reported to the
+// function it carries (see withProgress).
+func fetchSnapshot(ctx context.Context, depPath, version string) (cached string, err error) {
+	// Already cached?
+	store := cacheStore()
+	if dir, err := store.Get(depPath, version); err == nil {
+		return dir, nil
+	}
+
+	// Of a shared cache, only one machine fetches; the others wait and
+	// find the snapshot stored.
+	if l, ok := store.(entryLocker); ok {
+		unlock, err := l.lockEntry(ctx, depPath, version)
+		if err != nil {
+			return "", fmt.Errorf("lock %s@%s: %w", depPath, version, err)
+		}
+		defer unlock()
+		if dir, err := store.Get(depPath, version); err == nil {
+			return dir, nil
+		}
+	}
+
+	publish(ctx, &pb.Event{Kind: pb.EventKind_EVENT_KIND_FETCH_STARTED, Dependency: &pb.Dependency{Path: depPath, Version: version}})
+	defer func() {
+		ev := &pb.Event{Kind: pb.EventKind_EVENT_KIND_FETCH_FINISHED, Dependency: &pb.Dependency{Path: depPath, Version: version}}
+		if err != nil {
+			ev.Errors = []string{err.Error()}
+		} else {
+			ev.Dependency.CachePath, ev.Dependency.Source = cached, fetchSource(depPath, version)
+		}
+		publish(ctx, ev)
+	}()
+
+	// Stage next to the cache so that the local store can rename.
+	if err := os.MkdirAll(filepath.Dir(CacheDir()), 0o755); err != nil {
+		return "", fmt.Errorf("create cache dir: %w", err)
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(CacheDir()), "fetch-")
+	if err != nil {
+		return "", fmt.Errorf("create staging dir: %w", err)
+	}
+	defer os.RemoveAll(staging) //nolint:errcheck
+	staged := filepath.Join(staging, "snapshot")
+
+	reportProgress(ctx, pb.PullStage_PULL_STAGE_STARTED, depPath, version, 0)
+	watchCtx, stop := watchTransfer(ctx, depPath, version, staging)
+	src, err := fetchUpstream(watchCtx, depPath, version, staged)
+	stop()
+	if err != nil {
+		return "", err
+	}
+	if err := store.Put(depPath, version, staged); err != nil {
+		return "", fmt.Errorf("store %s@%s: %w", depPath, version, err)
+	}
+	info := fetchInfo{Source: src.String(), Fetcher: fetcherName(depPath), Time: time.Now().UTC()}
+	rev, err := readRevision(staged)
+	if err != nil {
+		return "", fmt.Errorf("revision of %s@%s: %w", depPath, version, err)
+	}
+	info.Commit, info.TagObject = rev.Commit, rev.TagObject
+	if err := writeFetchInfo(depPath, version, info); err != nil {
+		return "", fmt.Errorf("record fetch info: %w", err)
+	}
+	if err := keepProvenance(depPath, version, staged); err != nil {
+		return "", fmt.Errorf("record provenance: %w", err)
+	}
+	return store.Get(depPath, version)
+}
+
+// fetchUpstream fetches depPath at version into dst, a directory that does
+// not exist yet, trying each source its Fetcher resolves in turn, and
+// returns the source that served it. The content of a digest-addressed
+// version must hash to the digest.
+func fetchUpstream(ctx context.Context, depPath, version, dst string) (fetch.Source, error) {
+	srcs, err := fetcherFor(ctx, depPath).Resolve(depPath, version)
+	if err != nil {
+		return nil, err
+	}
+	digest, pinned := parseDigest(version)
+
+	var errs []error
+	for _, src := range srcs {
+		os.Remove(fetch.ProvenanceFile(dst)) //nolint:errcheck
+		os.Remove(fetch.RevisionFile(dst))   //nolint:errcheck
+		if err := src.Fetch(ctx, dst); err != nil {
+			os.RemoveAll(dst) //nolint:errcheck
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", src, err))
+			continue
+		}
+
+		if pinned {
+			if hash, _ := hashDir(dst); hash != digest {
+				errs = append(errs, fmt.Errorf("%s: content hashes to %s%s", src, digestPrefix, hash))
+				os.RemoveAll(dst) //nolint:errcheck
+				continue
+			}
+		}
+		return src, nil
+	}
+	return nil, fmt.Errorf("fetch %s@%s: %w", depPath, version, errors.Join(errs...))
+}
+
+// remoteTags lists the tags of depPath through its Fetcher.
+func remoteTags(ctx context.Context, depPath string) ([]string, error) {
+	tagger, ok := fetcherFor(ctx, depPath).(fetch.Tagger)
+	if !ok {
+		return nil, fmt.Errorf("%s: its fetcher cannot list versions", depPath)
+	}
+	return tagger.Tags(ctx, depPath)
+}
+
+// isCommitHash reports whether version is a commit hash, or a prefix of
+// one of at least 7 hex digits, rather than a tag.
+func isCommitHash(version string) bool {
+	return len(version) >= 7 && len(version) <= 40 && strings.Trim(version, "0123456789abcdef") == ""
+}
+
+// commitVersion returns the pseudo-version pinning the commit rev of
+// depPath, and its full hash, found through the Fetcher of depPath.
+func commitVersion(ctx context.Context, depPath, rev string) (version, hash string, err error) {
+	committer, ok := fetcherFor(ctx, depPath).(fetch.Committer)
+	if !ok {
+		return "", "", fmt.Errorf("%s: its fetcher cannot find commits", depPath)
+	}
+	hash, at, err := committer.Commit(ctx, depPath, rev)
+	if err != nil {
+		return "", "", err
+	}
+	return semver.PseudoVersion(at, hash), hash, nil
+}
+
+// gitFetcher clones dependencies from the registry, their upstream
+// repository, or else the proxies. It serves every host without a
+// registered Fetcher.
+type gitFetcher struct {
+	sources fetchSources
+}
+
+func (f gitFetcher) Resolve(depPath, version string) ([]fetch.Source, error) {
+	urls, branch := f.sources.urls(depPath), version
+	if digest, pinned := parseDigest(version); pinned {
+		urls, branch = f.sources.digestURLs(depPath, digest), ""
+		if len(urls) == 0 {
+			return nil, fmt.Errorf("%s@%s: digest-addressed versions are resolved through %s or a registry, neither set", depPath, version, proxyEnv)
+		}
+	}
+	var srcs []fetch.Source
+	for _, u := range urls {
+		srcs = append(srcs, gitSource{url: u, branch: branch, path: depPath, prefix: f.sources.prefixOf(u, depPath)})
+	}
+	return srcs, nil
+}
+
+// Tags lists the tags of depPath's repository in the registry or
+// upstream, falling back to the proxies when neither can be reached. Of a
+// registry or proxy, only the tags its TUF metadata signs are listed, if
+// ATLAS_TUF_ROOT is set.
+func (f gitFetcher) Tags(ctx context.Context, depPath string) ([]string, error) {
+	var errs []error
+	var served string
+	var listed []string
+	for _, gitURL := range f.sources.urls(depPath) {
+		var err error
+		if useGoGit() {
+			var commits map[string]string
+			commits, err = goGitTagCommits(ctx, gitURL)
+			listed = slices.Sorted(maps.Keys(commits))
+		} else {
+			var out []byte
+			out, err = lsRemote(ctx, gitURL)
+			listed = nil
+			for _, line := range strings.Split(string(out), "\n") {
+				if parts := strings.Fields(line); len(parts) >= 2 {
+					listed = append(listed, strings.TrimPrefix(parts[1], "refs/tags/"))
+				}
+			}
+		}
+		if err == nil {
+			errs, served = nil, gitURL
+			break
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", gitURL, err))
+	}
+	if errs != nil {
+		return nil, fmt.Errorf("ls-remote %s: %w", depPath, errors.Join(errs...))
+	}
+	var targets *tuf.Targets
+	if prefix := f.sources.prefixOf(served, depPath); prefix != "" {
+		var err error
+		if targets, err = signedTargets(ctx, prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	var tags []string
+	for _, tag := range listed {
+		if targets != nil {
+			if _, signed := targets.Targets[tuf.TargetName(depPath, tag)]; !signed {
+				continue
+			}
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// remoteTagCommits returns the commit each tag of depPath's repository in
+// the registry or upstream, or else of a proxy, points to. Dependencies
+// of a registered Fetcher have none.
+func remoteTagCommits(ctx context.Context, depPath string) (map[string]string, error) {
+	if _, ok := fetch.Lookup(depPath); ok {
+		return nil, nil
+	}
+	var errs []error
+	for _, gitURL := range sources(ctx, depPath) {
+		if useGoGit() {
+			commits, err := goGitTagCommits(ctx, gitURL)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", gitURL, err))
+				continue
+			}
+			return commits, nil
+		}
+		cmd, done, err := gitCommand(ctx, gitURL, "ls-remote", "--tags", gitURL)
+		if err != nil {
+			return nil, err
+		}
+		out, err := cmd.Output()
+		done()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", gitURL, err))
+			continue
+		}
+
+		// An annotated tag is listed twice: as its tag object, then peeled
+		// to its commit.
+		commits := map[string]string{}
+		for _, line := range strings.Split(string(out), "\n") {
+			sha, ref, ok := strings.Cut(strings.TrimSpace(line), "\t")
+			tag, isTag := strings.CutPrefix(ref, "refs/tags/")
+			if !ok || !isTag {
+				continue
+			}
+			if tag, peeled := strings.CutSuffix(tag, "^{}"); peeled || commits[tag] == "" {
+				commits[tag] = sha
+			}
+		}
+		return commits, nil
+	}
+	return nil, fmt.Errorf("ls-remote %s: %w", depPath, errors.Join(errs...))
+}
+
+// TagTimes shallow-fetches tags of depPath into a scratch repository, from
+// the registry, its upstream repository or else the proxies, and returns
+// when each was made: the tagger date of an annotated tag, the commit
+// date of a lightweight one.
+func (f gitFetcher) TagTimes(ctx context.Context, depPath string, tags []string) (map[string]time.Time, error) {
+	if useGoGit() {
+		var errs []error
+		for _, gitURL := range f.sources.urls(depPath) {
+			remote, err := goGitRemote(gitURL)
+			if err != nil {
+				return nil, err
+			}
+			times, err := remote.TagTimes(ctx, tags)
+			if err == nil {
+				return times, nil
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", gitURL, err))
+		}
+		return nil, fmt.Errorf("fetch tags of %s: %w", depPath, errors.Join(errs...))
+	}
+	repo, err := os.MkdirTemp("", "atlas-tags-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(repo) //nolint:errcheck
+	if err := runGit(ctx, "", "init", "--quiet", "--bare", repo); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, gitURL := range f.sources.urls(depPath) {
+		if err := fetchTags(ctx, repo, gitURL, tags); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", gitURL, err))
+			continue
+		}
+		out, err := gitOutput(ctx, repo, "for-each-ref", "--format=%(refname:strip=2) %(creatordate:unix)", "refs/tags")
+		if err != nil {
+			return nil, err
+		}
+		times := map[string]time.Time{}
+		for _, line := range strings.Split(out, "\n") {
+			tag, unix, ok := strings.Cut(line, " ")
+			if sec, err := strconv.ParseInt(unix, 10, 64); ok && err == nil {
+				times[tag] = time.Unix(sec, 0).UTC()
+			}
+		}
+		return times, nil
+	}
+	return nil, fmt.Errorf("fetch tags of %s: %w", depPath, errors.Join(errs...))
+}
+
+// fetchTags shallow-fetches tags from gitURL into repo.
+func fetchTags(ctx context.Context, repo, gitURL string, tags []string) error {
+	limit, done, err := gitLimitConfig(ctx, gitURL)
+	if err != nil {
+		return err
+	}
+	defer done()
+	args := append(limit, "fetch", "--quiet", "--depth=1", "--no-tags", gitURL)
+	for _, tag := range tags {
+		args = append(args, "refs/tags/"+tag+":refs/tags/"+tag)
+	}
+	return runRemoteGit(ctx, repo, gitURL, args...)
+}
+
+// Commit fetches the branches and tags of depPath into a scratch
+// repository, from the registry, its upstream repository or else the
+// proxies, and finds rev in their history.
+func (f gitFetcher) Commit(ctx context.Context, depPath, rev string) (string, time.Time, error) {
+	if useGoGit() {
+		var errs []error
+		for _, gitURL := range f.sources.urls(depPath) {
+			remote, err := goGitRemote(gitURL)
+			if err != nil {
+				return "", time.Time{}, err
+			}
+			hash, at, err := remote.Commit(ctx, rev)
+			if err == nil {
+				return hash, at, nil
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", gitURL, err))
+		}
+		return "", time.Time{}, fmt.Errorf("find commit %s of %s: %w", rev, depPath, errors.Join(errs...))
+	}
+	repo, err := os.MkdirTemp("", "atlas-commit-")
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer os.RemoveAll(repo) //nolint:errcheck
+	if err := runGit(ctx, "", "init", "--quiet", "--bare", repo); err != nil {
+		return "", time.Time{}, err
+	}
+
+	var errs []error
+	for _, gitURL := range f.sources.urls(depPath) {
+		limit, done, err := gitLimitConfig(ctx, gitURL)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		err = runRemoteGit(ctx, repo, gitURL, append(limit, historyFetch(gitURL)...)...)
+		done()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", gitURL, err))
+			continue
+		}
+		hash, at, err := findCommit(ctx, repo, rev)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", gitURL, err))
+			continue
+		}
+		return hash, at, nil
+	}
+	return "", time.Time{}, fmt.Errorf("find commit %s of %s: %w", rev, depPath, errors.Join(errs...))
+}
+
+// historyFetch returns the git arguments fetching the history of the
+// branches and tags of gitURL.
+func historyFetch(gitURL string) []string {
+	return []string{"fetch", "--quiet", gitURL, "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"}
+}
+
+// findCommit returns the full hash of the commit rev, a hash or a prefix
+// of one, in repo, and the time it was made.
+func findCommit(ctx context.Context, repo, rev string) (string, time.Time, error) {
+	hash, err := gitOutput(ctx, repo, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil || hash == "" {
+		return "", time.Time{}, fmt.Errorf("no branch or tag has commit %s", rev)
+	}
+	unix, err := gitOutput(ctx, repo, "show", "-s", "--format=%ct", hash)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	sec, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("time of commit %s: %w", hash, err)
+	}
+	return hash, time.Unix(sec, 0).UTC(), nil
+}
+
+// gitSource is a repository to shallow-fetch, at branch (a tag) unless
+// empty, in which case its default branch. A pseudo-version for branch
+// names a commit, found in the history of the branches and tags. A tag of
+// path fetched through prefix, a registry or proxy, must be a target of
+// its TUF metadata (see checkTarget).
+type gitSource struct {
+	url, branch  string
+	path, prefix string
+}
+
+func (g gitSource) String() string { return g.url }
+
+// gitAttempts bounds how often a git fetch failing midway is retried.
+const gitAttempts = 3
+
+// partialLocks serializes the fetches sharing a partial repository.
+var partialLocks pathLocks
+
+// partialRepo returns the bare repository that g fetches into, kept next
+// to the cache until its content is checked out.
+func (g gitSource) partialRepo() string {
+	sum := sha256.Sum256([]byte(g.url + "\x00" + g.branch))
+	return filepath.Join(filepath.Dir(CacheDir()), "partial", hex.EncodeToString(sum[:8]))
+}
+
+// Fetch shallow-fetches g into a bare repository, then checks its tree out
+// into dst, with its submodules (see fetchSubmodules). The repository
+// outlives a failed attempt, here or in a later run, so a retry only asks
+// for the objects it does not hold yet. Git cannot resume a pack cut
+// midway, though: what a dropped connection was transferring is
+// transferred again.
+func (g gitSource) Fetch(ctx context.Context, dst string) error {
+	if useGoGit() {
+		return g.fetchInProcess(ctx, dst)
+	}
+	repo := g.partialRepo()
+	defer partialLocks.lock(repo)()
+	if _, err := os.Stat(filepath.Join(repo, "HEAD")); err != nil {
+		if err := runGit(ctx, "", "init", "--quiet", "--bare", repo); err != nil {
+			return err
+		}
+	}
+
+	ref := g.branch
+	if ref == "" {
+		ref = "HEAD"
+	}
+	limit, done, err := gitLimitConfig(ctx, g.url)
+	if err != nil {
+		return err
+	}
+	defer done()
+	at, commit, pinned := semver.Pseudo(g.branch)
+	args := append(limit, "fetch", "--quiet", "--depth=1", g.url, ref)
+	if pinned {
+		args = append(limit, historyFetch(g.url)...)
+	}
+	untrack := transferDir(ctx, repo)
+	for attempt := 1; ; attempt++ {
+		err = runRemoteGit(ctx, repo, g.url, args...)
+		if err == nil || attempt == gitAttempts || ctx.Err() != nil {
+			break
+		}
+	}
+	untrack()
+	if err != nil {
+		return err
+	}
+	head := "FETCH_HEAD"
+	if pinned {
+		hash, made, err := findCommit(ctx, repo, commit)
+		if err != nil {
+			return err
+		}
+		if !made.Equal(at) {
+			return fmt.Errorf("commit %s was made at %s, not at the time %s names", hash, made.Format(time.RFC3339), g.branch)
+		}
+		head = hash
+	}
+
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	if err := runGit(ctx, repo, "--work-tree="+dst, "checkout", "--quiet", "--force", head, "--", "."); err != nil {
+		return err
+	}
+	if err := g.checkProvenance(ctx, repo, head, dst); err != nil {
+		return err
+	}
+	if err := writeRevision(ctx, repo, head, dst); err != nil {
+		return err
+	}
+	rev, err := readRevision(dst)
+	if err != nil {
+		return err
+	}
+	if g.prefix != "" && g.branch != "" && !pinned {
+		if err := checkTarget(ctx, g.prefix, g.path, g.branch, rev.Commit, dst); err != nil {
+			return err
+		}
+	}
+	if err := fetchSubmodules(ctx, repo, rev.Commit, g.url, dst, 0); err != nil {
+		return err
+	}
+	return os.RemoveAll(repo)
+}
+
+// readRevision returns the Revision the Source that fetched dst recorded,
+// empty if none.
+func readRevision(dst string) (fetch.Revision, error) {
+	var rev fetch.Revision
+	data, err := os.ReadFile(fetch.RevisionFile(dst))
+	if errors.Is(err, os.ErrNotExist) {
+		return rev, nil
+	}
+	if err != nil {
+		return rev, err
+	}
+	return rev, json.Unmarshal(data, &rev)
+}
+
+// writeRevision records the commit of head, fetched into repo, and the
+// annotated tag naming it if any, as the Revision of dst.
+func writeRevision(ctx context.Context, repo, head, dst string) error {
+	var rev fetch.Revision
+	var err error
+	if rev.Commit, err = gitOutput(ctx, repo, "rev-parse", head+"^{commit}"); err != nil {
+		return err
+	}
+	if kind, _ := gitOutput(ctx, repo, "cat-file", "-t", head); kind == "tag" {
+		if rev.TagObject, err = gitOutput(ctx, repo, "rev-parse", head); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fetch.RevisionFile(dst), data, 0o644)
+}
+
+// checkProvenance verifies the provenance attestation carried by head, if
+// an annotated tag fetched into repo, against the commit it tags, and
+// writes it next to dst.
+func (g gitSource) checkProvenance(ctx context.Context, repo, head, dst string) error {
+	if kind, err := gitOutput(ctx, repo, "cat-file", "-t", head); err != nil || kind != "tag" {
+		return err
+	}
+	tag, err := gitOutput(ctx, repo, "cat-file", "tag", head)
+	if err != nil {
+		return err
+	}
+	commit, err := gitOutput(ctx, repo, "rev-parse", head+"^{commit}")
+	if err != nil {
+		return err
+	}
+	return g.verifyProvenance(tag, commit, dst)
+}
+
+// verifyProvenance verifies the provenance attestation tag, the content of
+// an annotated tag, carries if any, against commit, the commit it tags,
+// and writes it next to dst.
+func (g gitSource) verifyProvenance(tag, commit, dst string) error {
+	stmt, err := provenance.Extract(tag)
+	if err != nil {
+		return fmt.Errorf("provenance of %s: %w", g.branch, err)
+	}
+	if stmt == nil {
+		return nil
+	}
+	if err := stmt.Verify(commit); err != nil {
+		return fmt.Errorf("provenance of %s: %w", g.branch, err)
+	}
+	data, err := json.MarshalIndent(stmt, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fetch.ProvenanceFile(dst), append(data, '\n'), 0o644)
+}
+
+// runGit runs git, sandboxed, on the repository gitDir, unless empty.
+func runGit(ctx context.Context, gitDir string, args ...string) error {
+	return runRemoteGit(ctx, gitDir, "", args...)
+}
+
+// gitOutput runs git, sandboxed, on the repository gitDir and returns its
+// trimmed output.
+func gitOutput(ctx context.Context, gitDir string, args ...string) (string, error) {
+	cmd, done, err := gitCommand(ctx, "", withGitDir(gitDir, args)...)
+	if err != nil {
+		return "", err
+	}
+	defer done()
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// fetchInfoPath returns where the fetch metadata of depPath@version lives.
+func fetchInfoPath(depPath, version string) string {
+	return CachePath(depPath, version) + ".info"
+}
+
+func writeFetchInfo(depPath, version string, info fetchInfo) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fetchInfoPath(depPath, version)), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(fetchInfoPath(depPath, version), append(data, '\n'), 0o644)
+}
+
+// readFetchInfo returns the fetch metadata of depPath@version, or nil if
+// none is recorded.
+func readFetchInfo(depPath, version string) *fetchInfo {
+	data, err := os.ReadFile(fetchInfoPath(depPath, version))
+	if err != nil {
+		return nil
+	}
+	var info fetchInfo
+	if json.Unmarshal(data, &info) != nil {
+		return nil
+	}
+	return &info
+}
+
+// fetchSource returns the git URL that served depPath@version into the
+// cache, or "" if it is unknown.
+func fetchSource(depPath, version string) string {
+	if info := readFetchInfo(depPath, version); info != nil {
+		return info.Source
+	}
+	return ""
+}
+
+// fetchInfoProto returns the fetch metadata of depPath@version, or nil if
+// none is recorded.
+func fetchInfoProto(depPath, version string) *pb.FetchInfo {
+	info := readFetchInfo(depPath, version)
+	if info == nil {
+		return nil
+	}
+	return &pb.FetchInfo{
+		Source:    info.Source,
+		Fetcher:   info.Fetcher,
+		Time:      info.Time.Format(time.RFC3339),
+		Commit:    info.Commit,
+		TagObject: info.TagObject,
+	}
+}
+
+// provenancePath returns where the provenance attestation of
+// depPath@version lives, when its release has one.
+func provenancePath(depPath, version string) string {
+	return CachePath(depPath, version) + ".provenance.json"
+}
+
+// keepProvenance moves the attestation a source wrote next to the staged
+// snapshot into the cache.
+func keepProvenance(depPath, version, staged string) error {
+	err := os.Rename(fetch.ProvenanceFile(staged), provenancePath(depPath, version))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// readProvenance returns the provenance attestation of depPath@version,
+// or nil if the cache holds none.
+func readProvenance(depPath, version string) *provenance.Statement {
+	data, err := os.ReadFile(provenancePath(depPath, version))
+	if err != nil {
+		return nil
+	}
+	stmt, err := provenance.Parse(data)
+	if err != nil {
+		return nil
+	}
+	return stmt
+}
+
+// removeFromCache deletes the snapshot of depPath@version and its metadata.
+func removeFromCache(depPath, version string) {
+	cacheStore().Delete(depPath, version)       //nolint:errcheck
+	os.Remove(fetchInfoPath(depPath, version))  //nolint:errcheck
+	os.Remove(provenancePath(depPath, version)) //nolint:errcheck
+}
+
+// cacheMismatch returns the hash sum records for path@version if the
+// snapshot in dir no longer matches it, and "" if it does or sum records
+// nothing it can check.
+func cacheMismatch(sum *modfile.SumFile, path, version, dir string) string {
+	if sum == nil {
+		return ""
+	}
+	recorded := sum.Lookup(path, version)
+	if _, ok := hashers[hashAlgorithm(recorded)]; !ok || hashMatches(recorded, dir) {
+		return ""
+	}
+	return recorded
+}
+
+// refetch deletes the cached snapshot of depPath@version, which no longer
+// hashes to recorded, and fetches it again. It fails with a hash mismatch
+// naming sumPath, evicting it, if the snapshot fetched again does not
+// match either: then upstream changed, not the cache. With force it is
+// kept.
+func refetch(ctx context.Context, depPath, version, recorded, sumPath string, force bool) (string, error) {
+	removeFromCache(depPath, version)
+	dir, err := fetchToCache(ctx, depPath, version)
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "fetch %s@%s: %v", depPath, version, err)
+	}
+	if !force && !hashMatches(recorded, dir) {
+		got, _ := sumHashDir(hashAlgorithm(recorded), dir)
+		removeFromCache(depPath, version)
+		return "", hashMismatchError(depPath+"@"+version, sumPath, "upstream content changed: fetched again, it still does not match holon.sum (want %s, got %s)", recorded, got)
+	}
+	return dir, nil
+}
+
+// checkFetched fails with a hash mismatch naming sumPath if the snapshot
+// of depPath@version just fetched into dir does not hash to what sum
+// records, and evicts it from the cache, so that content upstream changed
+// is never installed; so it is if it was fetched from another commit than
+// the one sum pins. With force it is kept.
+func checkFetched(sum *modfile.SumFile, depPath, version, dir, sumPath string, force bool) error {
+	if force {
+		return nil
+	}
+	if pinned, got := pinnedCommit(sum, depPath, version), fetchedCommit(depPath, version); pinned != "" && got != "" && got != pinned {
+		removeFromCache(depPath, version)
+		return hashMismatchError(depPath+"@"+version, sumPath, "tag moved upstream: it resolves to commit %s, holon.sum pins %s; use --force to accept it", got, pinned)
+	}
+	want := cacheMismatch(sum, depPath, version, dir)
+	if want == "" {
+		return nil
+	}
+	got, _ := sumHashDir(hashAlgorithm(want), dir)
+	removeFromCache(depPath, version)
+	return hashMismatchError(depPath+"@"+version, sumPath, "upstream content changed: fetched, it does not match holon.sum (want %s, got %s); use --force to accept it", want, got)
+}
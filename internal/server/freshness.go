@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/fetch"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+)
+
+// defaultStaleDays is the number of days behind its latest release past
+// which Freshness flags a dependency when the request does not say.
+const defaultStaleDays = 90
+
+// Freshness reports, for each dependency required by the holon in
+// req.Directory, the release tags higher than the required version, and
+// the days between the tags of the required version and the latest one.
+// A dependency whose tags or tag dates cannot be listed is reported with
+// its error rather than failing the call. A dependency the latest release
+// of which deprecates its version, or the whole holon, carries the notice.
+// Requires and replaces past their until date are reported in Expired.
+func (s *Server) Freshness(ctx context.Context, req *pb.FreshnessRequest) (*pb.FreshnessResponse, error) {
+	dir := holonDir(req.Directory)
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	ctx = withFetchSources(ctx, mod)
+	staleDays := req.StaleDays
+	if staleDays <= 0 {
+		staleDays = defaultStaleDays
+	}
+
+	resp := &pb.FreshnessResponse{StaleDays: staleDays}
+	for _, dep := range mod.Require {
+		// Skip replaced dependencies
+		if mod.ResolvedPath(dep.Path) != "" {
+			continue
+		}
+		f := &pb.DependencyFreshness{Path: dep.Path, Version: dep.Version}
+		src := mod.SourcePath(dep.Path)
+		if err := freshness(ctx, src, f); err != nil {
+			f.Error = err.Error()
+		}
+		if f.Latest != "" {
+			d, err := deprecationAt(ctx, dep.Path, src, dep.Version, f.Latest)
+			if err != nil && f.Error == "" {
+				f.Error = err.Error()
+			}
+			f.Deprecation = d
+		}
+		f.Stale = f.DaysBehind > staleDays
+		resp.Dependencies = append(resp.Dependencies, f)
+	}
+	resp.Expired = expiredPins(mod, modPath, time.Now())
+	return resp, nil
+}
+
+// expiredPins describes the requires and replaces of mod, read from
+// modPath, whose until annotation has passed at now, so that temporary
+// pins and replaces do not outlive their purpose unnoticed.
+func expiredPins(mod *modfile.ModFile, modPath string, now time.Time) []string {
+	var expired []string
+	for _, r := range mod.Require {
+		if until, ok := r.Until(); ok && modfile.Expired(until, now) {
+			expired = append(expired, fmt.Sprintf("%s:%d: %s %s was to stay until %s — update it or push back its %s date",
+				modPath, r.Line, r.Path, r.Version, until.Format(time.DateOnly), modfile.UntilKey))
+		}
+	}
+	for _, r := range mod.Replace {
+		if until, ok := r.Until(); ok && modfile.Expired(until, now) {
+			expired = append(expired, fmt.Sprintf("%s:%d: replace %s => %s was to stay until %s — remove it or push back its %s date",
+				modPath, r.Line, r.Old, r.New, until.Format(time.DateOnly), modfile.UntilKey))
+		}
+	}
+	return expired
+}
+
+// freshness fills in how far f.Version is behind the latest release of
+// src, the path f.Path is fetched from.
+func freshness(ctx context.Context, src string, f *pb.DependencyFreshness) error {
+	if _, _, _, ok := semver.Parse(f.Version); !ok {
+		return fmt.Errorf("%s is not a semver tag", f.Version)
+	}
+	tags, err := remoteTags(ctx, src)
+	if err != nil {
+		return err
+	}
+	f.Latest = latestTag(tags)
+	for _, tag := range tags {
+		if _, _, _, ok := semver.Parse(tag); ok && semver.Prerelease(tag) == "" && semver.Compare(tag, f.Version) > 0 {
+			f.VersionsBehind++
+		}
+	}
+	if f.VersionsBehind == 0 {
+		return nil
+	}
+
+	dater, ok := fetcherFor(ctx, src).(fetch.Dater)
+	if !ok {
+		return fmt.Errorf("%s: its fetcher cannot date versions", src)
+	}
+	times, err := dater.TagTimes(ctx, src, []string{f.Version, f.Latest})
+	if err != nil {
+		return err
+	}
+	versionTime, ok1 := times[f.Version]
+	latestTime, ok2 := times[f.Latest]
+	if !ok1 || !ok2 {
+		return fmt.Errorf("%s: tag dates of %s and %s unknown", src, f.Version, f.Latest)
+	}
+	f.VersionTime = versionTime.Format(time.RFC3339)
+	f.LatestTime = latestTime.Format(time.RFC3339)
+	f.DaysBehind = int32(latestTime.Sub(versionTime) / (24 * time.Hour))
+	return nil
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// gitAuthEnv names the environment variable giving how to authenticate to
+// private repositories: comma-separated <prefix>=<method> pairs, e.g.
+// "github.com/myorg=ssh,git.corp.example=netrc". A prefix is matched,
+// whole path segments at a time, against the dependency path, or against
+// the host and path of a registry or proxy URL; the longest wins. The
+// methods are:
+//
+//   - ssh: the repository of a dependency is cloned from
+//     ssh://git@<host>/<path>, with the SSH keys, agent and known hosts of
+//     the user;
+//   - netrc: the login and password of the host's machine entry in $NETRC,
+//     else ~/.netrc, are sent;
+//   - token, or token:<variable>: the token in ATLAS_GIT_TOKEN, or in that
+//     variable, is sent as the password of x-access-token, as forges
+//     accept their access tokens.
+//
+// Credentials only go to the URLs of the prefix; git commands otherwise
+// stay sandboxed (see gitCommand).
+const gitAuthEnv = "ATLAS_GIT_AUTH"
+
+// gitTokenEnv names the environment variable holding the token of the
+// "token" method of ATLAS_GIT_AUTH.
+const gitTokenEnv = "ATLAS_GIT_TOKEN"
+
+// gitAuth is the ATLAS_GIT_AUTH method of a prefix: "ssh", "netrc" or
+// "token", the latter with the variable holding the token.
+type gitAuth struct {
+	prefix, method, variable string
+}
+
+// gitAuthFor returns the ATLAS_GIT_AUTH method of name, a dependency path
+// or "<host>/<path>", if a prefix matches it.
+func gitAuthFor(name string) (gitAuth, bool, error) {
+	var best gitAuth
+	found := false
+	for _, entry := range strings.Split(os.Getenv(gitAuthEnv), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, method, ok := strings.Cut(entry, "=")
+		prefix = strings.Trim(prefix, "/")
+		method, variable, _ := strings.Cut(method, ":")
+		switch {
+		case !ok || prefix == "":
+			return gitAuth{}, false, fmt.Errorf("%s: invalid entry %q: want <prefix>=<method>", gitAuthEnv, entry)
+		case method == "token":
+			variable = cmp.Or(variable, gitTokenEnv)
+		case method != "ssh" && method != "netrc" || variable != "":
+			return gitAuth{}, false, fmt.Errorf("%s: unknown method %q for %s: want ssh, netrc or token[:<variable>]", gitAuthEnv, method, prefix)
+		}
+		if (name == prefix || strings.HasPrefix(name, prefix+"/")) && (!found || len(prefix) > len(best.prefix)) {
+			best, found = gitAuth{prefix: prefix, method: method, variable: variable}, true
+		}
+	}
+	return best, found, nil
+}
+
+// sshURLs returns the URLs of the repository of depPath over SSH, with and
+// without the .git suffix, if ATLAS_GIT_AUTH has it cloned so.
+func sshURLs(depPath string) []string {
+	auth, ok, _ := gitAuthFor(depPath)
+	host, rest, hasPath := strings.Cut(depPath, "/")
+	if !ok || auth.method != "ssh" || !hasPath {
+		return nil
+	}
+	base := "ssh://git@" + host + "/" + rest
+	return []string{base + ".git", base}
+}
+
+// urlName returns the host and path of a remote git URL, including
+// scp-like ones (user@host:path), without a .git suffix, to be matched
+// against the prefixes of ATLAS_GIT_AUTH; "" for local ones.
+func urlName(gitURL string) string {
+	host := urlHost(gitURL)
+	if host == "" {
+		return ""
+	}
+	var p string
+	if u, err := url.Parse(gitURL); err == nil && u.Scheme != "" {
+		p = u.Path
+	} else {
+		_, p, _ = strings.Cut(gitURL, ":")
+	}
+	return host + "/" + strings.TrimSuffix(strings.Trim(p, "/"), ".git")
+}
+
+// gitAuthForURL returns the ATLAS_GIT_AUTH method of gitURL, if a prefix
+// matches its host and path.
+func gitAuthForURL(gitURL string) (gitAuth, bool, error) {
+	name := urlName(gitURL)
+	if name == "" {
+		return gitAuth{}, false, nil
+	}
+	return gitAuthFor(name)
+}
+
+// apply passes cmd the credentials of a netrc or token method, as an
+// Authorization header that git only sends to gitURL. The ssh method is
+// gitCommand's to apply.
+func (a gitAuth) apply(cmd *exec.Cmd, gitURL string) error {
+	user, password, err := a.login(gitURL)
+	if err != nil || password == "" {
+		return err
+	}
+	header := "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+	addGitConfig(cmd, "http."+gitURL+".extraHeader", header)
+	return nil
+}
+
+// login returns the user and password a netrc or token method sends to
+// gitURL; none for the ssh method.
+func (a gitAuth) login(gitURL string) (user, password string, err error) {
+	switch a.method {
+	case "netrc":
+		if user, password, err = netrcLogin(urlHost(gitURL)); err != nil {
+			return "", "", fmt.Errorf("%s: %w", gitAuthEnv, err)
+		}
+	case "token":
+		if password = os.Getenv(a.variable); password == "" {
+			return "", "", fmt.Errorf("%s: %s is not set, for %s", gitAuthEnv, a.variable, a.prefix)
+		}
+		user = "x-access-token"
+	}
+	return user, password, nil
+}
+
+// addGitConfig sets the git configuration key to value for cmd through
+// the GIT_CONFIG_COUNT variables, after those it already has. Unlike -c,
+// they do not show in the command line of the process.
+func addGitConfig(cmd *exec.Cmd, key, value string) {
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	n := 0
+	for i, kv := range cmd.Env {
+		if count, ok := strings.CutPrefix(kv, "GIT_CONFIG_COUNT="); ok {
+			n, _ = strconv.Atoi(count)
+			cmd.Env = append(cmd.Env[:i:i], cmd.Env[i+1:]...)
+			break
+		}
+	}
+	cmd.Env = append(cmd.Env,
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", n, key),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", n, value),
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", n+1),
+	)
+}
+
+// netrcLogin returns the login and password of host in $NETRC, else
+// ~/.netrc: its machine entry, else the default one.
+func netrcLogin(host string) (user, password string, err error) {
+	path := os.Getenv("NETRC")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", err
+		}
+		path = filepath.Join(home, ".netrc")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	// entries holds the login and password of each machine, "" being the
+	// default one.
+	type login struct{ user, password string }
+	entries := map[string]*login{}
+	var current *login
+	scanner := bufio.NewScanner(f)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		switch word := scanner.Text(); word {
+		case "machine", "default":
+			name := ""
+			if word == "machine" && scanner.Scan() {
+				name = scanner.Text()
+			}
+			current = &login{}
+			if _, seen := entries[name]; !seen {
+				entries[name] = current
+			}
+		case "login", "password":
+			if current != nil && scanner.Scan() {
+				if word == "login" {
+					current.user = scanner.Text()
+				} else {
+					current.password = scanner.Text()
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", err
+	}
+	for _, name := range []string{host, ""} {
+		if l := entries[name]; l != nil && l.password != "" {
+			return l.user, l.password, nil
+		}
+	}
+	return "", "", fmt.Errorf("%s has no entry for %s", path, host)
+}
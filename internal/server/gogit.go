@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	gitfetch "github.com/organic-programming/rhizome-atlas/internal/fetch"
+	"github.com/organic-programming/rhizome-atlas/pkg/fetch"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+)
+
+// goGitEnv, set to 1, has the dependencies of hosts without a registered
+// fetcher fetched in process, with go-git (see internal/fetch), rather
+// than by the git command. Where git is not installed they always are.
+// Fetched so, they get the credentials of ATLAS_GIT_AUTH over HTTP(S)
+// only, never those of the user, and escape ATLAS_RATE_LIMIT and
+// ATLAS_FETCH_RATE_LIMIT. Trees with submodules and SSH remotes still
+// need git, as do mirroring, releasing and signing TUF metadata.
+const goGitEnv = "ATLAS_GO_GIT"
+
+// useGoGit reports whether dependencies are fetched with go-git.
+func useGoGit() bool {
+	if os.Getenv(goGitEnv) == "1" {
+		return true
+	}
+	_, err := exec.LookPath("git")
+	return err != nil
+}
+
+// goGitRemote returns the repository at gitURL for go-git, with the
+// credentials ATLAS_GIT_AUTH gives it.
+func goGitRemote(gitURL string) (gitfetch.Remote, error) {
+	remote := gitfetch.Remote{URL: gitURL}
+	auth, authed, err := gitAuthForURL(gitURL)
+	if err != nil || !authed {
+		return remote, err
+	}
+	remote.User, remote.Password, err = auth.login(gitURL)
+	return remote, err
+}
+
+// goGitTagCommits returns the commit each tag of the repository at gitURL
+// points to, listed with go-git.
+func goGitTagCommits(ctx context.Context, gitURL string) (map[string]string, error) {
+	remote, err := goGitRemote(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	return remote.Tags(ctx)
+}
+
+// fetchInProcess is Fetch with go-git: it checks the tree of g out into
+// dst, with the provenance and Revision the git command records, and
+// checks it against the TUF targets of g.prefix.
+func (g gitSource) fetchInProcess(ctx context.Context, dst string) error {
+	remote, err := goGitRemote(g.url)
+	if err != nil {
+		return err
+	}
+	at, commit, pinned := semver.Pseudo(g.branch)
+	rev := g.branch
+	if pinned {
+		rev = commit
+	}
+	got, err := remote.Checkout(ctx, rev, dst)
+	if err != nil {
+		return err
+	}
+	if pinned && !got.Time.Equal(at) {
+		return fmt.Errorf("commit %s was made at %s, not at the time %s names", got.Commit, got.Time.Format(time.RFC3339), g.branch)
+	}
+	if got.TagObject != "" {
+		if err := g.verifyProvenance(strings.TrimSpace(string(got.Tag)), got.Commit, dst); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(fetch.Revision{Commit: got.Commit, TagObject: got.TagObject})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(fetch.RevisionFile(dst), data, 0o644); err != nil {
+		return err
+	}
+	if g.prefix != "" && g.branch != "" && !pinned {
+		return checkTarget(ctx, g.prefix, g.path, g.branch, got.Commit, dst)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+)
+
+// hasher computes the digests of one holon.sum hash algorithm.
+type hasher interface {
+	// HashDir hashes a snapshot: every file, with its relative path.
+	HashDir(dir string) (string, error)
+	// HashFile hashes the content of a single file.
+	HashFile(path string) (string, error)
+}
+
+// hashers maps the algorithm prefix of holon.sum hashes ("h1" in
+// "h1:<digest>") to its implementation. Entries are always checked with
+// the algorithm they name, so a new default leaves older entries valid.
+var hashers = map[string]hasher{
+	"h1": h1{},
+	"h2": h2{},
+}
+
+// defaultHashAlgorithm is the algorithm new holon.sum entries use.
+const defaultHashAlgorithm = "h1"
+
+// commitSuffix ends the version of the holon.sum entries pinning the
+// commit a version was fetched from, when its fetcher tells:
+// "<path> <version>/commit git:<sha>". A tag force-moved upstream no
+// longer resolves to it.
+const commitSuffix = "/commit"
+
+// commitPrefix starts the hash of those entries.
+const commitPrefix = "git:"
+
+// sumHashDir returns the holon.sum hash of the snapshot in dir, as
+// "<alg>:<digest>".
+func sumHashDir(alg, dir string) (string, error) {
+	h, ok := hashers[alg]
+	if !ok {
+		return "", fmt.Errorf("unknown hash algorithm %q", alg)
+	}
+	digest, err := h.HashDir(dir)
+	if err != nil {
+		return "", err
+	}
+	return alg + ":" + digest, nil
+}
+
+// sumHashFile returns the holon.sum hash of a single file, as
+// "<alg>:<digest>".
+func sumHashFile(alg, path string) (string, error) {
+	h, ok := hashers[alg]
+	if !ok {
+		return "", fmt.Errorf("unknown hash algorithm %q", alg)
+	}
+	digest, err := h.HashFile(path)
+	if err != nil {
+		return "", err
+	}
+	return alg + ":" + digest, nil
+}
+
+// hashAlgorithm returns the algorithm a recorded holon.sum hash names.
+func hashAlgorithm(recorded string) string {
+	alg, _, _ := modfile.SplitHash(recorded)
+	return alg
+}
+
+// sumAlgorithms returns the known algorithms sum records hashes with,
+// sorted, or the default one for a holon.sum without entries. New entries
+// are recorded with each, so that a holon.sum migrating to another
+// algorithm stays readable by tools knowing either.
+func sumAlgorithms(sum *modfile.SumFile) []string {
+	seen := map[string]bool{}
+	var algs []string
+	for _, e := range sum.Entries {
+		alg := hashAlgorithm(e.Hash)
+		if _, known := hashers[alg]; known && !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+	if len(algs) == 0 {
+		return []string{defaultHashAlgorithm}
+	}
+	sort.Strings(algs)
+	return algs
+}
+
+// setSnapshotHashes records in sum the hashes of the snapshot of
+// path@version in dir, and of its HOLON.md if it has one, with each of
+// algs, and the commit it was fetched from if known.
+func setSnapshotHashes(sum *modfile.SumFile, algs []string, path, version, dir string) error {
+	if commit := fetchedCommit(path, version); commit != "" {
+		sum.Set(path, version+commitSuffix, commitPrefix+commit)
+	}
+	for _, alg := range algs {
+		hash, err := sumHashDir(alg, dir)
+		if err != nil {
+			return fmt.Errorf("hash %s@%s: %w", path, version, err)
+		}
+		sum.Set(path, version, hash)
+		if hash, err := sumHashFile(alg, filepath.Join(dir, "HOLON.md")); err == nil {
+			sum.Set(path, version+"/HOLON.md", hash)
+		}
+	}
+	return nil
+}
+
+// pinnedCommit returns the commit sum pins path@version to, or "".
+func pinnedCommit(sum *modfile.SumFile, path, version string) string {
+	if sum == nil {
+		return ""
+	}
+	commit, _ := strings.CutPrefix(sum.Lookup(path, version+commitSuffix), commitPrefix)
+	return commit
+}
+
+// fetchedCommit returns the commit the cached snapshot of path@version was
+// fetched from, or "" if unknown.
+func fetchedCommit(path, version string) string {
+	if info := readFetchInfo(path, version); info != nil {
+		return info.Commit
+	}
+	return ""
+}
+
+// hashMatches reports whether dir still hashes to recorded, computed with
+// the algorithm recorded names.
+func hashMatches(recorded, dir string) bool {
+	got, err := sumHashDir(hashAlgorithm(recorded), dir)
+	return err == nil && got == recorded
+}
+
+// h1 is SHA-256 over the files of a snapshot. Its directory digest also
+// addresses "sha256:<hex>" versions.
+type h1 struct{}
+
+func (h1) HashDir(dir string) (string, error)   { return hashDir(dir) }
+func (h1) HashFile(path string) (string, error) { return hashFile(path) }
+
+// h2 is SHA-256 over a manifest of the files of a snapshot: one
+// "<sha256 of the file>  <slash-separated path>\n" line per file, sorted
+// by path. Unlike h1, a path boundary cannot be mistaken for file
+// content, and a snapshot hashes the same on every OS.
+type h2 struct{}
+
+func (h2) HashDir(dir string) (string, error) {
+	type file struct{ path, sum string }
+	var files []file
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		if strings.Contains(rel, "\n") {
+			return fmt.Errorf("file name %q contains a newline", rel)
+		}
+		sum, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, file{rel, sum})
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+
+	h := sha256.New()
+	for _, f := range files {
+		fmt.Fprintf(h, "%s  %s\n", f.sum, f.path)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (h2) HashFile(path string) (string, error) { return hashFile(path) }
+
+// hashDir computes SHA-256 of all files in a directory.
+func hashDir(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		// Write relative path for reproducibility
+		rel, _ := filepath.Rel(dir, path)
+		h.Write([]byte(rel))
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		h.Write(data)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFile computes SHA-256 of a single file.
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:]), nil
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// historyEntry is one line of .holon/history.log, in JSON.
+type historyEntry struct {
+	Time    time.Time `json:"time"`
+	Method  string    `json:"method"`
+	Changes []string  `json:"changes"`
+}
+
+// historyPath returns the history log of the holon in dir. Vendor leaves
+// it alone.
+func historyPath(dir string) string {
+	return filepath.Join(dir, ".holon", "history.log")
+}
+
+// appendHistory records an operation that changed the journaled files of
+// dir from before to after.
+func appendHistory(dir, method string, before, after map[string][]byte) error {
+	entry := historyEntry{
+		Time:    time.Now().UTC(),
+		Method:  method,
+		Changes: describeChanges(before, after),
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(historyPath(dir)), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(historyPath(dir), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// describeChanges summarizes how the journaled files changed, one line
+// per require or replace added, removed or moved to another version.
+func describeChanges(before, after map[string][]byte) []string {
+	var changes []string
+	switch {
+	case before["holon.mod"] == nil && after["holon.mod"] != nil:
+		changes = append(changes, "created holon.mod")
+	case before["holon.mod"] != nil && after["holon.mod"] == nil:
+		changes = append(changes, "removed holon.mod")
+	}
+
+	oldMod, _ := modfile.ParseBytes(before["holon.mod"])
+	newMod, _ := modfile.ParseBytes(after["holon.mod"])
+	changes = append(changes, diffMods(oldMod, newMod)...)
+
+	if len(changes) == 0 && !bytes.Equal(before["holon.sum"], after["holon.sum"]) {
+		changes = append(changes, "updated holon.sum")
+	}
+	if len(changes) == 0 && !bytes.Equal(before["holon.lock"], after["holon.lock"]) {
+		changes = append(changes, "updated holon.lock")
+	}
+	return changes
+}
+
+// diffMods describes the requires and replaces added, removed or moved to
+// another version from oldMod to newMod. Either may be nil.
+func diffMods(oldMod, newMod *modfile.ModFile) []string {
+	if oldMod == nil {
+		oldMod = &modfile.ModFile{}
+	}
+	if newMod == nil {
+		newMod = &modfile.ModFile{}
+	}
+
+	var changes []string
+	for _, r := range newMod.Require {
+		old, ok := findRequire(oldMod, r.Path)
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("+ %s %s", r.Path, r.Version))
+		case old.Version != r.Version:
+			changes = append(changes, fmt.Sprintf("%s %s → %s", r.Path, old.Version, r.Version))
+		}
+	}
+	for _, r := range oldMod.Require {
+		if _, ok := findRequire(newMod, r.Path); !ok {
+			changes = append(changes, fmt.Sprintf("- %s %s", r.Path, r.Version))
+		}
+	}
+	for _, r := range newMod.Replace {
+		if old, ok := findReplace(oldMod, r.Old); !ok || old.New != r.New {
+			changes = append(changes, fmt.Sprintf("+ replace %s => %s", r.Old, r.New))
+		}
+	}
+	for _, r := range oldMod.Replace {
+		if cur, ok := findReplace(newMod, r.Old); !ok || cur.New != r.New {
+			changes = append(changes, fmt.Sprintf("- replace %s => %s", r.Old, r.New))
+		}
+	}
+	return changes
+}
+
+func findRequire(mod *modfile.ModFile, path string) (modfile.Require, bool) {
+	for _, r := range mod.Require {
+		if r.Path == path {
+			return r, true
+		}
+	}
+	return modfile.Require{}, false
+}
+
+func findReplace(mod *modfile.ModFile, old string) (modfile.Replace, bool) {
+	for _, r := range mod.Replace {
+		if r.Old == old {
+			return r, true
+		}
+	}
+	return modfile.Replace{}, false
+}
+
+// History returns the operations recorded in the history log of
+// req.Directory, most recent first.
+func (s *Server) History(_ context.Context, req *pb.HistoryRequest) (*pb.HistoryResponse, error) {
+	dir := holonDir(req.Directory)
+
+	f, err := os.Open(historyPath(dir))
+	if os.IsNotExist(err) {
+		return &pb.HistoryResponse{}, nil
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "read history: %v", err)
+	}
+	defer f.Close()
+
+	var entries []*pb.HistoryEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // tolerate a torn last line
+		}
+		entries = append(entries, &pb.HistoryEntry{
+			Time:    e.Time.Format(time.RFC3339),
+			Method:  e.Method,
+			Changes: e.Changes,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, status.Errorf(codes.Internal, "read history: %v", err)
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if req.Limit > 0 && int(req.Limit) < len(entries) {
+		entries = entries[:req.Limit]
+	}
+	return &pb.HistoryResponse{Entries: entries}, nil
+}
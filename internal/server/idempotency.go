@@ -0,0 +1,85 @@
+package server
+
+import (
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxIdempotencyKeys bounds the number of idempotency keys remembered.
+const maxIdempotencyKeys = 1000
+
+// idemCache remembers the outcome of recent RPCs by idempotency key, so
+// that a client retrying over a flaky transport gets the original response
+// instead of applying the same mutation twice.
+type idemCache struct {
+	mu      sync.Mutex
+	entries map[string]*idemEntry
+	order   []string // keys, oldest first
+}
+
+type idemEntry struct {
+	req  proto.Message
+	resp proto.Message
+	done chan struct{} // closed once resp is set or the call failed
+}
+
+// idempotent runs call unless an earlier call of method with the same key
+// succeeded, in which case its response is returned. A retry arriving
+// while the first call is still running waits for it. Failed calls are
+// forgotten so that they can be retried; reusing a key for a different
+// request is an error. An empty key disables all of this.
+func idempotent[Req, Resp proto.Message](c *idemCache, method, key string, req Req, call func() (Resp, error)) (Resp, error) {
+	var zero Resp
+	if key == "" {
+		return call()
+	}
+	key = method + "\x00" + key
+
+	for {
+		c.mu.Lock()
+		e, ok := c.entries[key]
+		if !ok {
+			break // still locked
+		}
+		c.mu.Unlock()
+
+		<-e.done
+		if e.resp == nil {
+			continue // the call failed and was forgotten
+		}
+		if !proto.Equal(e.req, req) {
+			return zero, status.Errorf(codes.InvalidArgument,
+				"idempotency key %q was used for a different %s request", key[len(method)+1:], method)
+		}
+		return proto.Clone(e.resp).(Resp), nil
+	}
+
+	e := &idemEntry{req: proto.Clone(req), done: make(chan struct{})}
+	if c.entries == nil {
+		c.entries = map[string]*idemEntry{}
+	}
+	c.entries[key] = e
+	c.order = append(c.order, key)
+	for len(c.order) > maxIdempotencyKeys {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.mu.Unlock()
+
+	resp, err := call()
+
+	c.mu.Lock()
+	if err != nil {
+		if c.entries[key] == e {
+			delete(c.entries, key)
+		}
+	} else {
+		e.resp = proto.Clone(resp)
+	}
+	c.mu.Unlock()
+	close(e.done)
+	return resp, err
+}
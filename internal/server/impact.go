@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Impact compares the build list of the holon in req.Directory with the
+// one it would have if it required req.Path at req.Version, as minimal
+// version selection picks both. The requirements of each version selected
+// are read from the cache or a vendored copy, or from a scratch fetch when
+// found in neither; a version none of them yields is reported in
+// Unresolved, its requirements left out. Neither the cache nor the
+// holon's files are modified.
+func (s *Server) Impact(ctx context.Context, req *pb.ImpactRequest) (*pb.ImpactResponse, error) {
+	if req.Path == "" || req.Version == "" {
+		return nil, status.Error(codes.InvalidArgument, "path and version are required")
+	}
+	dir := holonDir(req.Directory)
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	ctx = withFetchSources(ctx, mod)
+
+	path, oldVersion := req.Path, ""
+	if dep, ok := mod.RequireByName(req.Path); ok {
+		path, oldVersion = dep.Path, dep.Version
+	}
+	if mod.ResolvedPath(path) != "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s is replaced by a local path", path)
+	}
+	if mod.Stable && semver.Prerelease(req.Version) != "" {
+		return nil, prereleaseError(modPath, path+"@"+req.Version)
+	}
+	changed := mod.Clone()
+	changed.AddRequire(path, req.Version)
+
+	// Both build lists read the holon.mod of each version once.
+	mods := map[string]*modfile.ModFile{}
+	unresolved := map[string]string{}
+	load := func(sel map[selKey]string) (map[selKey]*modfile.ModFile, error) {
+		loaded := map[selKey]*modfile.ModFile{}
+		for key, version := range sel {
+			id := key.path + "@" + version
+			sub, ok := mods[id]
+			if !ok {
+				var err error
+				if sub, err = modAt(ctx, dir, mod, key.path, version); err != nil {
+					unresolved[id] = err.Error()
+				}
+				mods[id] = sub
+			}
+			if sub != nil {
+				loaded[key] = sub
+			}
+		}
+		return loaded, nil
+	}
+	all := func(modfile.Require) bool { return true }
+	before, err := selectGraph(ctx, mod, modfile.StrategyMVS, all, load)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	after, err := selectGraph(ctx, changed, modfile.StrategyMVS, all, load)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	resp := &pb.ImpactResponse{
+		Path:       path,
+		OldVersion: oldVersion,
+		NewVersion: req.Version,
+		Changes:    requirementChanges(lockEdges(before), lockEdges(after)),
+		Selections: selectionChanges(before, after),
+		Conflicts:  majorConflicts(lockEdges(after)),
+	}
+	for id, why := range unresolved {
+		resp.Unresolved = append(resp.Unresolved, id+": "+why)
+	}
+	sort.Strings(resp.Unresolved)
+	return resp, nil
+}
+
+// modAt returns the holon.mod of path@version, a dependency of mod, the
+// holon.mod of the holon in dir: read from the cache or its vendored copy,
+// or else from a scratch fetch. It is nil for a version without one.
+func modAt(ctx context.Context, dir string, mod *modfile.ModFile, path, version string) (*modfile.ModFile, error) {
+	depDir := dependencyDir(dir, mod, path, version)
+	if _, err := os.Stat(depDir); err != nil {
+		scratch, err := os.MkdirTemp("", "atlas-impact-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(scratch) //nolint:errcheck
+		depDir = filepath.Join(scratch, "snapshot")
+		if _, err := fetchUpstream(ctx, mod.SourcePath(path), version, depDir); err != nil {
+			return nil, err
+		}
+	}
+	sub, err := modfile.Parse(filepath.Join(depDir, "holon.mod"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return sub, err
+}
+
+// lockEdges returns the requirements of the holons of lock, as edges from
+// each holon to the dependency it requires.
+func lockEdges(lock *modfile.LockFile) []*pb.Edge {
+	var edges []*pb.Edge
+	for _, sel := range lock.Selected {
+		for _, r := range sel.RequiredBy {
+			edges = append(edges, &pb.Edge{From: r.Holon, To: sel.Path, Version: r.Version})
+		}
+	}
+	return edges
+}
+
+// requirementChanges returns the requirements added, removed or changed
+// from before to after, by holon then path.
+func requirementChanges(before, after []*pb.Edge) []*pb.RequirementChange {
+	type key struct{ holon, path string }
+	versions := func(edges []*pb.Edge) map[key]string {
+		m := map[key]string{}
+		for _, e := range edges {
+			m[key{e.From, e.To}] = e.Version
+		}
+		return m
+	}
+	old, cur := versions(before), versions(after)
+
+	var changes []*pb.RequirementChange
+	for k, v := range cur {
+		if old[k] != v {
+			changes = append(changes, &pb.RequirementChange{Holon: k.holon, Path: k.path, OldVersion: old[k], NewVersion: v})
+		}
+	}
+	for k, v := range old {
+		if _, ok := cur[k]; !ok {
+			changes = append(changes, &pb.RequirementChange{Holon: k.holon, Path: k.path, OldVersion: v})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Holon != changes[j].Holon {
+			return changes[i].Holon < changes[j].Holon
+		}
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// selectionChanges returns the dependencies whose selected version
+// differs between the build lists before and after, by path then major,
+// with the holons whose lower requirement the new selection overrides.
+// Coexisting majors of a dependency are compared each on its own.
+func selectionChanges(before, after *modfile.LockFile) []*pb.Selection {
+	selected := func(lock *modfile.LockFile) map[selKey]modfile.Selection {
+		m := map[selKey]modfile.Selection{}
+		for _, sel := range lock.Selected {
+			m[majorKey(sel.Path, sel.Version)] = sel
+		}
+		return m
+	}
+	old, cur := selected(before), selected(after)
+
+	var sels []*pb.Selection
+	for key, s := range cur {
+		if old[key].Version == s.Version {
+			continue
+		}
+		sel := &pb.Selection{Path: s.Path, OldVersion: old[key].Version, NewVersion: s.Version}
+		for _, r := range s.RequiredBy {
+			if r.Version != s.Version {
+				sel.Raised = append(sel.Raised, r.Holon)
+			}
+		}
+		sort.Strings(sel.Raised)
+		sels = append(sels, sel)
+	}
+	for key, s := range old {
+		if _, ok := cur[key]; !ok {
+			sels = append(sels, &pb.Selection{Path: s.Path, OldVersion: s.Version})
+		}
+	}
+	version := func(s *pb.Selection) string {
+		if s.NewVersion != "" {
+			return s.NewVersion
+		}
+		return s.OldVersion
+	}
+	sort.Slice(sels, func(i, j int) bool {
+		a, b := sels[i], sels[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return semver.Compare(version(a), version(b)) < 0
+	})
+	return sels
+}
+
+// majorConflicts returns the dependencies that edges require at several
+// major versions, by path. Versions that are not semver are ignored.
+func majorConflicts(edges []*pb.Edge) []*pb.Conflict {
+	byPath := map[string][]*pb.Edge{}
+	majors := map[string]map[int]bool{}
+	for _, e := range edges {
+		major, _, _, ok := semver.Parse(e.Version)
+		if !ok {
+			continue
+		}
+		if majors[e.To] == nil {
+			majors[e.To] = map[int]bool{}
+		}
+		majors[e.To][major] = true
+		byPath[e.To] = append(byPath[e.To], &pb.Edge{From: e.From, To: e.To, Version: e.Version})
+	}
+
+	var conflicts []*pb.Conflict
+	for path, m := range majors {
+		if len(m) > 1 {
+			conflicts = append(conflicts, &pb.Conflict{Path: path, RequiredBy: byPath[path]})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Path < conflicts[j].Path })
+	return conflicts
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/client"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxJournal bounds the number of operations that can be undone.
+const maxJournal = 20
+
+// journaledFiles are the files saved before each journaled operation.
+var journaledFiles = []string{"holon.mod", "holon.sum", "holon.lock"}
+
+// journalEntry describes one undoable operation. The previous contents of
+// the journaled files are stored next to it; a missing file did not exist.
+type journalEntry struct {
+	Method string    `json:"method"`
+	Time   time.Time `json:"time"`
+	// Files are the journaled files when the entry was written. Entries
+	// without it saved holon.mod and holon.sum only, and leave holon.lock
+	// alone when undone.
+	Files []string `json:"files,omitempty"`
+}
+
+// files returns the files e restores.
+func (e journalEntry) files() []string {
+	if e.Files == nil {
+		return []string{"holon.mod", "holon.sum"}
+	}
+	return e.Files
+}
+
+// journalDir returns where the undo journal of the holon in dir lives.
+// Vendor leaves it alone.
+func journalDir(dir string) string {
+	return filepath.Join(dir, ".holon", ".journal")
+}
+
+// journal saves the journaled files of dir and returns a function that,
+// once the operation succeeded and changed any of them, records the saved
+// contents as an undoable entry and the change in the history log. It is
+// meant to be deferred:
+//
+//	defer s.journal(dir, "Update", &err)()
+func (s *Server) journal(dir, method string, err *error) func() {
+	before := readJournaled(dir)
+	return func() {
+		if *err != nil {
+			return
+		}
+		after := readJournaled(dir)
+		changed := false
+		for _, name := range journaledFiles {
+			if !bytes.Equal(before[name], after[name]) || (before[name] == nil) != (after[name] == nil) {
+				changed = true
+			}
+		}
+		if !changed {
+			return
+		}
+		if jerr := writeJournal(dir, journalEntry{Method: method, Time: time.Now().UTC(), Files: journaledFiles}, before); jerr != nil {
+			*err = status.Errorf(codes.Internal, "write undo journal: %v", jerr)
+			return
+		}
+		if herr := appendHistory(dir, method, before, after); herr != nil {
+			log.Printf("atlas: %s: history: %v", dir, herr)
+		}
+	}
+}
+
+// readJournaled reads the journaled files of dir; missing files map to nil.
+func readJournaled(dir string) map[string][]byte {
+	files := map[string][]byte{}
+	for _, name := range journaledFiles {
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			files[name] = data
+		}
+	}
+	return files
+}
+
+// writeJournal appends an entry to the journal of dir and drops the
+// oldest ones beyond maxJournal.
+func writeJournal(dir string, entry journalEntry, files map[string][]byte) error {
+	entries, err := journalEntries(dir)
+	if err != nil {
+		return err
+	}
+	seq := 1
+	if len(entries) > 0 {
+		fmt.Sscan(entries[len(entries)-1], &seq) //nolint:errcheck
+		seq++
+	}
+
+	edir := filepath.Join(journalDir(dir), fmt.Sprintf("%06d", seq))
+	if err := os.MkdirAll(edir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(edir, "entry.json"), append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(edir, name), content, 0o644); err != nil {
+			return err
+		}
+	}
+
+	for len(entries)+1 > maxJournal {
+		os.RemoveAll(filepath.Join(journalDir(dir), entries[0])) //nolint:errcheck
+		entries = entries[1:]
+	}
+	return nil
+}
+
+// journalEntries lists the entry directories of the journal of dir,
+// oldest first.
+func journalEntries(dir string) ([]string, error) {
+	des, err := os.ReadDir(journalDir(dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, de := range des {
+		if de.IsDir() {
+			names = append(names, de.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Undo restores holon.mod, holon.sum and holon.lock as they were before
+// the last journaled operation in req.Directory and drops that journal
+// entry. With req.RestoreCache, required dependencies missing from the
+// cache (e.g. evicted by Update) are fetched again.
+func (s *Server) Undo(ctx context.Context, req *pb.UndoRequest) (_ *pb.UndoResponse, err error) {
+	defer s.record("Undo", req.Directory, &err)
+
+	dir := holonDir(req.Directory)
+
+	entries, err := journalEntries(dir)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "read undo journal: %v", err)
+	}
+	if len(entries) == 0 {
+		return nil, detailed(codes.FailedPrecondition, client.ReasonNothingToUndo, nil, nil, "nothing to undo")
+	}
+	edir := filepath.Join(journalDir(dir), entries[len(entries)-1])
+	before := readJournaled(dir)
+
+	data, err := os.ReadFile(filepath.Join(edir, "entry.json"))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "read undo journal: %v", err)
+	}
+	var entry journalEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, status.Errorf(codes.Internal, "read undo journal: %v", err)
+	}
+
+	for _, name := range entry.files() {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(filepath.Join(edir, name))
+		switch {
+		case os.IsNotExist(err):
+			err = os.Remove(path)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		case err == nil:
+			err = os.WriteFile(path, content, 0o644)
+		}
+		s.mods.invalidate(path)
+		s.sums.invalidate(path)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "restore %s: %v", name, err)
+		}
+	}
+	if err := os.RemoveAll(edir); err != nil {
+		return nil, status.Errorf(codes.Internal, "drop undo entry: %v", err)
+	}
+	if err := appendHistory(dir, "Undo "+entry.Method, before, readJournaled(dir)); err != nil {
+		log.Printf("atlas: %s: history: %v", dir, err)
+	}
+
+	resp := &pb.UndoResponse{Method: entry.Method, Time: entry.Time.Format(time.RFC3339)}
+	if req.RestoreCache {
+		mod, err := s.parseMod(filepath.Join(dir, "holon.mod"))
+		if err != nil {
+			return resp, nil // undone to a state without holon.mod
+		}
+		ctx := withFetchSources(s.withEvents(ctx), mod)
+		for _, dep := range mod.Require {
+			src := mod.SourcePath(dep.Path)
+			if mod.ResolvedPath(dep.Path) != "" || inCache(src, dep.Version) {
+				continue
+			}
+			cachePath, err := fetchToCache(ctx, src, dep.Version)
+			if err != nil {
+				return nil, status.Errorf(codes.Unavailable, "restore %s@%s to cache: %v", src, dep.Version, err)
+			}
+			resp.Restored = append(resp.Restored, &pb.Dependency{
+				Path:      src,
+				Version:   dep.Version,
+				CachePath: cachePath,
+				Source:    fetchSource(src, dep.Version),
+			})
+		}
+	}
+	return resp, nil
+}
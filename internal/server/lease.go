@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Leases on snapshots of a shared cache. The holder renews its lease
+// every leaseTTL/3 while fetching; a lease not renewed for leaseTTL, e.g.
+// because its holder died, is taken over.
+const (
+	leaseTTL  = 2 * time.Minute
+	leasePoll = 500 * time.Millisecond
+)
+
+// entryLocker is implemented by CacheStores shared between machines, so
+// that only one of them fetches a snapshot from upstream while the others
+// wait for it to be stored.
+type entryLocker interface {
+	// lockEntry waits until this process holds the lease of path@version,
+	// or ctx is done, and returns the function releasing it.
+	lockEntry(ctx context.Context, path, version string) (func(), error)
+}
+
+func (t tieredStore) lockEntry(ctx context.Context, path, version string) (func(), error) {
+	if l, ok := t.remote.(entryLocker); ok {
+		return l.lockEntry(ctx, path, version)
+	}
+	return func() {}, nil
+}
+
+// lease is the content of a lock object.
+type lease struct {
+	Owner   string    `json:"owner"`
+	Expires time.Time `json:"expires"`
+}
+
+// leaseOwner identifies this process in the leases it holds.
+var leaseOwner = func() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s/%d", host, os.Getpid())
+}()
+
+// errLeaseLost is returned when renewing a lease taken over by another
+// process.
+var errLeaseLost = errors.New("lease taken over")
+
+// lockEntry holds the lock object <path>@<version>.lock, created only if
+// absent with a conditional PUT, for as long as the fetch takes. The lease
+// is only renewed, and deleted, as last written: once taken over, e.g.
+// after this process stalled past leaseTTL, it belongs to the new holder.
+func (o *objectStore) lockEntry(ctx context.Context, path, version string) (func(), error) {
+	key := path + "@" + version + ".lock"
+	var cond http.Header
+	for {
+		var acquired bool
+		var err error
+		cond, acquired, err = o.createLease(key)
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			break
+		}
+
+		held, cond, err := o.readLease(key)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // released meanwhile
+		}
+		if err != nil {
+			return nil, err
+		}
+		if time.Now().After(held.Expires) {
+			// Stale: only delete the lease read, not one taken since.
+			if err := o.delete(key, cond); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(leasePoll):
+		}
+	}
+
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(leaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				next, err := o.writeLease(key, cond)
+				if errors.Is(err, errLeaseLost) {
+					return
+				}
+				if err == nil { // else retried on the next tick
+					cond = next
+				}
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+		o.delete(key, cond) //nolint:errcheck // expires anyway
+	}, nil
+}
+
+// createLease creates the lock object at key unless it exists, and
+// reports whether it did, with the header making a request conditional on
+// the lease being the one written.
+func (o *objectStore) createLease(key string) (http.Header, bool, error) {
+	header := http.Header{"If-None-Match": {"*"}}
+	if o.scheme == "gs" {
+		header = http.Header{"X-Goog-If-Generation-Match": {"0"}}
+	}
+	resp, err := o.putLease(key, header)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return o.leaseCond(resp), true, nil
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		return nil, false, nil
+	}
+	return nil, false, o.failed("lock", key, resp)
+}
+
+// writeLease extends the lease at key, unless it changed since the write
+// cond was returned for, and returns the condition of the new write.
+func (o *objectStore) writeLease(key string, cond http.Header) (http.Header, error) {
+	resp, err := o.putLease(key, cond)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return o.leaseCond(resp), nil
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		return nil, errLeaseLost
+	}
+	return nil, o.failed("lock", key, resp)
+}
+
+// leaseCond returns the header making a request conditional on the lease
+// being the version resp answered with, or nil if resp did not say.
+func (o *objectStore) leaseCond(resp *http.Response) http.Header {
+	if o.scheme == "gs" {
+		if gen := resp.Header.Get("X-Goog-Generation"); gen != "" {
+			return http.Header{"X-Goog-If-Generation-Match": {gen}}
+		}
+		return nil
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		return http.Header{"If-Match": {etag}}
+	}
+	return nil
+}
+
+func (o *objectStore) putLease(key string, header http.Header) (*http.Response, error) {
+	data, _ := json.Marshal(lease{Owner: leaseOwner, Expires: time.Now().Add(leaseTTL)})
+	return o.do(http.MethodPut, key, data, header)
+}
+
+// readLease returns the lease at key, and the header making a delete
+// conditional on it being unchanged. An unreadable lease is returned
+// expired.
+func (o *objectStore) readLease(key string) (lease, http.Header, error) {
+	resp, err := o.do(http.MethodGet, key, nil, nil)
+	if err != nil {
+		return lease{}, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return lease{}, nil, &fs.PathError{Op: "lock", Path: o.uri + "/" + key, Err: fs.ErrNotExist}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return lease{}, nil, o.failed("lock", key, resp)
+	}
+	cond := o.leaseCond(resp)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return lease{}, nil, err
+	}
+	var l lease
+	json.Unmarshal(data, &l) //nolint:errcheck
+	return l, cond, nil
+}
+
+// delete removes the object at key. A missing object, or a condition in
+// header not met, is not an error.
+func (o *objectStore) delete(key string, header http.Header) error {
+	resp, err := o.do(http.MethodDelete, key, nil, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound, http.StatusPreconditionFailed:
+		return nil
+	}
+	return o.failed("delete", key, resp)
+}
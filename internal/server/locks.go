@@ -0,0 +1,54 @@
+package server
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+)
+
+// pathLocks hands out one mutex per absolute file path.
+type pathLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock locks path and returns the function that unlocks it.
+func (l *pathLocks) lock(path string) func() {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		key = path
+	}
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*sync.Mutex{}
+	}
+	m, ok := l.locks[key]
+	if !ok {
+		m = &sync.Mutex{}
+		l.locks[key] = m
+	}
+	l.mu.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
+
+// updateSum re-reads the holon.sum at path under its lock, applies edit
+// and writes the result if edit reports a change. Operations that record
+// hashes concurrently, such as an Add and a Pull of the same holon, each
+// apply their entries to the latest file instead of overwriting each
+// other's. The lock is held by this Server only; other processes writing
+// the same file are not excluded.
+func (s *Server) updateSum(path string, edit func(*modfile.SumFile) bool) error {
+	defer s.locks.lock(path)()
+
+	sum, err := modfile.ParseSum(path)
+	if err != nil {
+		return err
+	}
+	if !edit(sum) {
+		return nil
+	}
+	return s.writeSum(sum, path)
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+)
+
+// logSigningKeyEnv names a file holding the key a server signs the heads
+// of its checksum log with: an Ed25519 seed of 32 bytes in hex, e.g. from
+// `openssl rand -hex 32`. Unset, heads are not signed.
+const logSigningKeyEnv = "ATLAS_LOG_SIGNING_KEY"
+
+// logKeyEnv names the Ed25519 public key, in hex, that the heads of the
+// checksum log read by verify --log must be signed with. Unset, heads are
+// taken as they come.
+const logKeyEnv = "ATLAS_LOG_KEY"
+
+// logHeadTTLEnv names the environment variable bounding how long a signed
+// head may be trusted; unset means defaultLogHeadTTL.
+const logHeadTTLEnv = "ATLAS_LOG_HEAD_TTL"
+
+const defaultLogHeadTTL = 24 * time.Hour
+
+// logSigningKey returns the key logSigningKeyEnv names, or nil if unset.
+func logSigningKey() (ed25519.PrivateKey, error) {
+	path := os.Getenv(logSigningKeyEnv)
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", logSigningKeyEnv, err)
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%s: %s holds no Ed25519 seed of %d bytes in hex", logSigningKeyEnv, path, ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// logHeadTTL returns the lifetime logHeadTTLEnv sets.
+func logHeadTTL() (time.Duration, error) {
+	env := strings.TrimSpace(os.Getenv(logHeadTTLEnv))
+	if env == "" {
+		return defaultLogHeadTTL, nil
+	}
+	d, err := time.ParseDuration(env)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s: invalid value %q (want a positive duration)", logHeadTTLEnv, env)
+	}
+	return d, nil
+}
+
+// logHeadMessage returns what the signature of head covers.
+func logHeadMessage(head *pb.LogHead) []byte {
+	return fmt.Appendf(nil, "atlas checksum log head\n%d\n%x\n%d\n%d\n", head.Size, head.RootHash, head.Timestamp, head.Expires)
+}
+
+// signLogHead signs head at now with the key of logSigningKeyEnv, if any.
+func signLogHead(head *pb.LogHead, now time.Time) error {
+	key, err := logSigningKey()
+	if err != nil || key == nil {
+		return err
+	}
+	ttl, err := logHeadTTL()
+	if err != nil {
+		return err
+	}
+	head.Timestamp, head.Expires = now.Unix(), now.Add(ttl).Unix()
+	head.Signature = ed25519.Sign(key, logHeadMessage(head))
+	head.PublicKey = key.Public().(ed25519.PublicKey)
+	return nil
+}
+
+// checkLogHead checks that head is signed with the key of logKeyEnv, if
+// set, and has not expired at now.
+func checkLogHead(head *pb.LogHead, now time.Time) error {
+	env := strings.TrimSpace(os.Getenv(logKeyEnv))
+	if env == "" {
+		return nil
+	}
+	key, err := hex.DecodeString(env)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("%s: invalid value %q (want an Ed25519 public key in hex)", logKeyEnv, env)
+	}
+	switch {
+	case len(head.Signature) == 0:
+		return fmt.Errorf("head is not signed")
+	case !ed25519.Verify(key, logHeadMessage(head), head.Signature):
+		return fmt.Errorf("head is not signed with the key of %s", logKeyEnv)
+	case !now.Before(time.Unix(head.Expires, 0)):
+		return fmt.Errorf("head signed at %s expired at %s", time.Unix(head.Timestamp, 0).UTC().Format(time.RFC3339), time.Unix(head.Expires, 0).UTC().Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxDoneOperations bounds the finished long-running operations kept for
+// GetOperation; running ones are always kept.
+const maxDoneOperations = 100
+
+// longOps holds the operations started by StartPull and StartUpdate.
+type longOps struct {
+	mu   sync.Mutex
+	ops  map[string]*longOp
+	done []string // IDs of the finished operations, oldest first
+}
+
+type longOp struct {
+	op      *pb.Operation // replaced, never modified, once published
+	changed chan struct{} // closed when op is replaced
+	cancel  context.CancelFunc
+}
+
+// StartPull runs Pull in the background and returns its operation.
+func (s *Server) StartPull(_ context.Context, req *pb.PullRequest) (*pb.Operation, error) {
+	req = proto.CloneOf(req)
+	return s.lro.start("Pull", req.Directory, func(ctx context.Context, op *pb.Operation) error {
+		resp, err := s.Pull(ctx, req)
+		op.Pull = resp
+		return err
+	}), nil
+}
+
+// StartUpdate runs Update in the background and returns its operation.
+func (s *Server) StartUpdate(_ context.Context, req *pb.UpdateRequest) (*pb.Operation, error) {
+	req = proto.CloneOf(req)
+	return s.lro.start("Update", req.Directory, func(ctx context.Context, op *pb.Operation) error {
+		resp, err := s.Update(ctx, req)
+		op.Update = resp
+		return err
+	}), nil
+}
+
+// GetOperation returns the current state of an operation.
+func (s *Server) GetOperation(_ context.Context, req *pb.GetOperationRequest) (*pb.Operation, error) {
+	op, _, err := s.lro.get(req.Id)
+	return op, err
+}
+
+// CancelOperation cancels the context of a running operation, and returns
+// it as it is: it is only done once the call returned.
+func (s *Server) CancelOperation(_ context.Context, req *pb.CancelOperationRequest) (*pb.Operation, error) {
+	s.lro.mu.Lock()
+	e, ok := s.lro.ops[req.Id]
+	s.lro.mu.Unlock()
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "operation %q not found", req.Id)
+	}
+	e.cancel()
+	op, _, err := s.lro.get(req.Id)
+	return op, err
+}
+
+// WatchOperation sends the state of an operation, then again each time it
+// changes, until it is done.
+func (s *Server) WatchOperation(req *pb.GetOperationRequest, stream pb.RhizomeAtlasService_WatchOperationServer) error {
+	for {
+		op, changed, err := s.lro.get(req.Id)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(op); err != nil {
+			return err
+		}
+		if op.Done {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// start registers a new operation and runs it in the background, with a
+// context canceled by CancelOperation. run sets the response on the
+// operation it is given.
+func (l *longOps) start(method, dir string, run func(context.Context, *pb.Operation) error) *pb.Operation {
+	id := make([]byte, 16)
+	rand.Read(id) //nolint:errcheck // never fails
+	op := &pb.Operation{Id: hex.EncodeToString(id), Method: method, Directory: dir}
+
+	l.mu.Lock()
+	if l.ops == nil {
+		l.ops = map[string]*longOp{}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	l.ops[op.Id] = &longOp{op: op, changed: make(chan struct{}), cancel: cancel}
+	l.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		result := proto.CloneOf(op)
+		if err := run(ctx, result); err != nil {
+			st := status.Convert(err)
+			result.ErrorCode, result.ErrorMessage = int32(st.Code()), st.Message()
+		}
+		result.Done = true
+		l.finish(result)
+	}()
+	return proto.CloneOf(op)
+}
+
+// finish publishes the final state of an operation and forgets the
+// oldest finished ones beyond maxDoneOperations.
+func (l *longOps) finish(op *pb.Operation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := l.ops[op.Id]
+	close(e.changed)
+	l.ops[op.Id] = &longOp{op: op, changed: make(chan struct{}), cancel: e.cancel}
+
+	l.done = append(l.done, op.Id)
+	for len(l.done) > maxDoneOperations {
+		delete(l.ops, l.done[0])
+		l.done = l.done[1:]
+	}
+}
+
+// get returns a copy of the operation id, and a channel closed when it
+// changes.
+func (l *longOps) get(id string) (*pb.Operation, <-chan struct{}, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.ops[id]
+	if !ok {
+		return nil, nil, status.Errorf(codes.NotFound, "operation %q not found", id)
+	}
+	return proto.CloneOf(e.op), e.changed, nil
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultMirrorLatest is the number of versions MirrorSync keeps current
+// per holon when the request does not say.
+const defaultMirrorLatest = 3
+
+// MirrorSync copies the latest versions of the holons matching req.Paths
+// from req.From into the mirror req.To, then signs its TUF metadata if
+// ATLAS_TUF_KEYS is set. A holon failing does not stop the others: its
+// error is reported in the response.
+func (s *Server) MirrorSync(ctx context.Context, req *pb.MirrorSyncRequest) (_ *pb.MirrorSyncResponse, err error) {
+	defer s.record("MirrorSync", req.To, &err)
+	if req.From == "" || req.To == "" || len(req.Paths) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "from, to and paths are required")
+	}
+	for _, glob := range req.Paths {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%q: %v", glob, err)
+		}
+	}
+	latest := int(req.Latest)
+	if latest <= 0 {
+		latest = defaultMirrorLatest
+	}
+
+	candidates := map[string]bool{}
+	for _, glob := range req.Paths {
+		if !strings.ContainsAny(glob, `*?[\`) {
+			candidates[glob] = true
+		}
+	}
+	for _, root := range []string{localDir(req.From), req.To} {
+		if root == "" {
+			continue
+		}
+		found, err := findRepos(root)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "list %s: %v", root, err)
+		}
+		for _, p := range found {
+			if matchAny(req.Paths, p) {
+				candidates[p] = true
+			}
+		}
+	}
+	paths := make([]string, 0, len(candidates))
+	for p := range candidates {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	resp := &pb.MirrorSyncResponse{}
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		holon, err := mirrorHolon(ctx, strings.TrimRight(req.From, "/")+"/"+p, filepath.Join(req.To, p), latest)
+		if err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %v", p, err))
+			continue
+		}
+		holon.Path = p
+		resp.Holons = append(resp.Holons, holon)
+	}
+	if err := signRepository(ctx, req.To); err != nil {
+		return nil, status.Errorf(codes.Internal, "sign %s: %v", req.To, err)
+	}
+	return resp, nil
+}
+
+// mirrorHolon fetches the latest versions of the repository at src missing
+// from the bare repository repo, creating it if needed.
+func mirrorHolon(ctx context.Context, src, repo string, latest int) (*pb.MirroredHolon, error) {
+	out, err := lsRemote(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("ls-remote %s: %w", src, err)
+	}
+	var tags []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			tag := strings.TrimPrefix(fields[1], "refs/tags/")
+			if _, _, _, ok := semver.Parse(tag); ok && semver.Prerelease(tag) == "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("%s: no stable versions", src)
+	}
+	sort.Slice(tags, func(i, j int) bool { return semver.Compare(tags[i], tags[j]) > 0 })
+	tags = tags[:min(latest, len(tags))]
+
+	if _, err := os.Stat(filepath.Join(repo, "HEAD")); err != nil {
+		if err := os.MkdirAll(filepath.Dir(repo), 0o755); err != nil {
+			return nil, err
+		}
+		if err := runGit(ctx, "", "init", "--quiet", "--bare", repo); err != nil {
+			return nil, fmt.Errorf("init %s: %w", repo, err)
+		}
+	}
+	have, err := gitOutput(ctx, repo, "tag", "--list")
+	if err != nil {
+		return nil, fmt.Errorf("list tags of %s: %w", repo, err)
+	}
+	mirrored := map[string]bool{}
+	for _, tag := range strings.Fields(have) {
+		mirrored[tag] = true
+	}
+
+	holon := &pb.MirroredHolon{Versions: tags}
+	var refspecs []string
+	for _, tag := range tags {
+		if !mirrored[tag] {
+			holon.Added = append(holon.Added, tag)
+			refspecs = append(refspecs, "refs/tags/"+tag+":refs/tags/"+tag)
+		}
+	}
+	if len(refspecs) == 0 {
+		return holon, nil
+	}
+	limit, done, err := gitLimitConfig(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	args := append(limit, "fetch", "--quiet", "--depth=1", src)
+	if err := runRemoteGit(ctx, repo, src, append(args, refspecs...)...); err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", strings.Join(holon.Added, ", "), err)
+	}
+	return holon, nil
+}
+
+// findRepos returns the paths, relative to root, of the git repositories
+// under it, bare or not.
+func findRepos(root string) ([]string, error) {
+	var repos []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if isRepo(p) {
+			if rel, err := filepath.Rel(root, p); err == nil && rel != "." {
+				repos = append(repos, filepath.ToSlash(rel))
+			}
+			return fs.SkipDir
+		}
+		return nil
+	})
+	return repos, err
+}
+
+// isRepo reports whether dir is a git repository.
+func isRepo(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true
+	}
+	_, errHead := os.Stat(filepath.Join(dir, "HEAD"))
+	_, errObjects := os.Stat(filepath.Join(dir, "objects"))
+	return errHead == nil && errObjects == nil
+}
+
+// matchAny reports whether p matches one of globs.
+func matchAny(globs []string, p string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// localDir returns the directory a proxy prefix names, a path or a file://
+// URL, or "" for a remote one.
+func localDir(prefix string) string {
+	if rest, ok := strings.CutPrefix(prefix, "file://"); ok {
+		return rest
+	}
+	if strings.Contains(prefix, "://") {
+		return ""
+	}
+	return prefix
+}
@@ -0,0 +1,446 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// remoteCacheEnv names a cache shared by several machines, e.g. a build
+// farm: an object store, "s3://<bucket>[/<prefix>]" or
+// "gs://<bucket>[/<prefix>]", or another atlas server, by the URI it
+// listens on.
+//
+// Object store requests are signed with AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN when set (for GCS, an HMAC
+// key of its XML API), in AWS_REGION. AWS_ENDPOINT_URL points s3:// at an
+// S3-compatible service instead of AWS.
+const remoteCacheEnv = "ATLAS_CACHE_REMOTE"
+
+// remoteStore is the shared tier of a tieredStore.
+type remoteStore interface {
+	fmt.Stringer
+	// fetch writes the snapshot of path@version into dst, a directory that
+	// does not exist yet. It fails with an error matching fs.ErrNotExist
+	// when the store does not have it.
+	fetch(path, version, dst string) error
+	// store shares the snapshot in src, which it leaves in place.
+	store(path, version, src string) error
+}
+
+// newRemoteStore configures the shared cache named by uri.
+func newRemoteStore(uri string) (remoteStore, error) {
+	scheme, _, _ := strings.Cut(uri, "://")
+	switch scheme {
+	case "s3", "gs":
+		return newObjectStore(uri)
+	default:
+		return newPeerStore(uri)
+	}
+}
+
+// tieredStore keeps snapshots in the local cache directory, in front of
+// a remote store: a snapshot missing locally is fetched from it once, and
+// every snapshot stored is shared through it. Sharing is best effort: a
+// snapshot the remote store refuses is still kept locally.
+//
+// List, Delete and Clear only act on the local copies. Snapshots are
+// immutable, and one machine cleaning its cache must not empty the
+// store the others share.
+type tieredStore struct {
+	local  fsStore
+	remote remoteStore
+}
+
+func (t tieredStore) Get(path, version string) (string, error) {
+	dir, err := t.local.Get(path, version)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return dir, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(CacheDir()), 0o755); err != nil {
+		return "", fmt.Errorf("create cache dir: %w", err)
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(CacheDir()), "remote-")
+	if err != nil {
+		return "", fmt.Errorf("create staging dir: %w", err)
+	}
+	defer os.RemoveAll(staging) //nolint:errcheck
+	staged := filepath.Join(staging, "snapshot")
+	if err := t.remote.fetch(path, version, staged); err != nil {
+		return "", err
+	}
+	if err := checkSignedArchive(path, version, staged); err != nil {
+		return "", err
+	}
+	if err := t.local.Put(path, version, staged); err != nil {
+		// Lost a race with a concurrent Get of the same snapshot?
+		if dir, err := t.local.Get(path, version); err == nil {
+			return dir, nil
+		}
+		return "", err
+	}
+	return t.local.Get(path, version)
+}
+
+func (t tieredStore) Put(path, version, src string) error {
+	if !validSnapshot(path, version) {
+		return &fs.PathError{Op: "put", Path: path + "@" + version, Err: fs.ErrInvalid}
+	}
+	if err := t.local.Put(path, version, src); err != nil {
+		return err
+	}
+	if err := t.remote.store(path, version, CachePath(path, version)); err != nil {
+		log.Printf("atlas: share %s@%s through %s: %v", path, version, t.remote, err)
+	}
+	return nil
+}
+
+func (t tieredStore) Stat(path, version string) (CacheEntry, error) {
+	if _, err := t.Get(path, version); err != nil {
+		return CacheEntry{}, err
+	}
+	return t.local.Stat(path, version)
+}
+
+func (t tieredStore) List() ([]CacheEntry, error)       { return t.local.List() }
+func (t tieredStore) Delete(path, version string) error { return t.local.Delete(path, version) }
+func (t tieredStore) Clear() error                      { return t.local.Clear() }
+
+// snapshotKey is the object name of path@version, below the store prefix.
+func snapshotKey(path, version string) string {
+	return path + "@" + version + ".tar.gz"
+}
+
+// tarSnapshot packs the regular files of dir into a gzipped tar.
+func tarSnapshot(dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		return addTarFile(tw, filepath.ToSlash(rel), data, info.Mode().Perm())
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// untarSnapshot unpacks a gzipped tar made by tarSnapshot into dir.
+func untarSnapshot(data []byte, dir string) error {
+	f, err := os.CreateTemp(filepath.Dir(dir), "snapshot-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return extractBundle(f.Name(), dir)
+}
+
+// objectStoreClient sends the requests of object stores. Its timeout
+// bounds each request, so that a stalled store does not hold up a fetch
+// forever; a download cut short by it is resumed (see get).
+var objectStoreClient = &http.Client{Timeout: 5 * time.Minute}
+
+// objectStore reads and writes objects of a bucket through the S3 REST
+// API, which GCS also serves (its "XML API").
+type objectStore struct {
+	uri    string // as configured, for messages
+	scheme string // "s3" or "gs"
+	base   *url.URL
+	bucket string
+	prefix string
+	region string
+	access string
+	secret string
+	token  string
+	client *http.Client
+	// virtualHost is set when base names the bucket in its host name,
+	// rather than the bucket being the first element of the path.
+	virtualHost bool
+}
+
+// newObjectStore configures the object store named by uri from the
+// environment, as described for remoteCacheEnv.
+func newObjectStore(uri string) (*objectStore, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%s: want s3://<bucket>[/<prefix>] or gs://<bucket>[/<prefix>]", remoteCacheEnv)
+	}
+	o := &objectStore{
+		uri:    uri,
+		scheme: u.Scheme,
+		bucket: u.Host,
+		prefix: strings.Trim(u.Path, "/"),
+		region: os.Getenv("AWS_REGION"),
+		access: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secret: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:  os.Getenv("AWS_SESSION_TOKEN"),
+		client: objectStoreClient,
+	}
+	endpoint := os.Getenv("AWS_ENDPOINT_URL")
+	switch u.Scheme {
+	case "s3":
+		if o.region == "" {
+			o.region = "us-east-1"
+		}
+		if endpoint == "" {
+			endpoint = "https://" + o.bucket + ".s3." + o.region + ".amazonaws.com"
+			o.virtualHost = true
+		}
+	case "gs":
+		if o.region == "" {
+			o.region = "auto"
+		}
+		endpoint = "https://storage.googleapis.com"
+	default:
+		return nil, fmt.Errorf("%s: unsupported scheme %q, want s3 or gs", remoteCacheEnv, u.Scheme)
+	}
+	if o.base, err = url.Parse(endpoint); err != nil {
+		return nil, fmt.Errorf("endpoint %q: %w", endpoint, err)
+	}
+	return o, nil
+}
+
+func (o *objectStore) String() string { return o.uri }
+
+func (o *objectStore) fetch(path, version, dst string) error {
+	data, err := o.get(snapshotKey(path, version))
+	if err != nil {
+		return err
+	}
+	if err := untarSnapshot(data, dst); err != nil {
+		return fmt.Errorf("unpack %s@%s from %s: %w", path, version, o, err)
+	}
+	return nil
+}
+
+func (o *objectStore) store(path, version, src string) error {
+	data, err := tarSnapshot(src)
+	if err != nil {
+		return err
+	}
+	return o.put(snapshotKey(path, version), data)
+}
+
+// getAttempts bounds the requests get makes for one object: a download cut
+// short is resumed where it stopped rather than started over.
+const getAttempts = 5
+
+// get downloads the object at key, failing with an error matching
+// fs.ErrNotExist when there is none. When the connection drops midway,
+// the rest is requested with a Range conditional on the object's ETag, so
+// large snapshots are not transferred again; a store that ignores the
+// range, or an object replaced meanwhile, restarts the download.
+func (o *objectStore) get(key string) ([]byte, error) {
+	limits, err := downloadLimits()
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	var etag string
+	var lastErr error
+	for attempt := 0; attempt < getAttempts; attempt++ {
+		var header http.Header
+		if len(data) > 0 && etag != "" {
+			header = http.Header{
+				"Range":    {fmt.Sprintf("bytes=%d-", len(data))},
+				"If-Range": {etag},
+			}
+		}
+		resp, err := o.do(http.MethodGet, key, nil, header)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			resp.Body.Close()
+			return nil, &fs.PathError{Op: "get", Path: o.uri + "/" + key, Err: fs.ErrNotExist}
+		case resp.StatusCode == http.StatusOK:
+			data, etag = nil, resp.Header.Get("ETag")
+		case resp.StatusCode == http.StatusPartialContent && header != nil && rangeStart(resp) == int64(len(data)):
+		default:
+			err := o.failed("get", key, resp)
+			resp.Body.Close()
+			return nil, err
+		}
+		rest, err := io.ReadAll(limits.reader(context.Background(), resp.Body))
+		resp.Body.Close()
+		data = append(data, rest...)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = fmt.Errorf("get %s/%s: %w", o.uri, key, err)
+	}
+	return nil, lastErr
+}
+
+// rangeStart returns the first byte of a 206 response, from its
+// Content-Range, or -1.
+func rangeStart(resp *http.Response) int64 {
+	var start, end, size int64
+	if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &size); err != nil {
+		return -1
+	}
+	return start
+}
+
+// put uploads data as the object at key.
+func (o *objectStore) put(key string, data []byte) error {
+	resp, err := o.do(http.MethodPut, key, data, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return o.failed("put", key, resp)
+	}
+	return nil
+}
+
+func (o *objectStore) failed(op, key string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s %s/%s: %s: %s", op, o.uri, key, resp.Status, bytes.TrimSpace(msg))
+}
+
+// do sends a request for the object at key, with the extra header,
+// signed with AWS Signature Version 4 when credentials are configured.
+func (o *objectStore) do(method, key string, body []byte, header http.Header) (*http.Response, error) {
+	name := path.Join(o.prefix, key)
+	if !o.virtualHost {
+		name = path.Join(o.bucket, name)
+	}
+	u := *o.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + name
+	u.RawPath = awsEscape(u.Path)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if o.access != "" {
+		o.sign(req, body, time.Now().UTC())
+	}
+	return o.client.Do(req)
+}
+
+// sign adds the SigV4 headers for req, whose payload is body.
+func (o *objectStore) sign(req *http.Request, body []byte, now time.Time) {
+	payload := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(payload[:])
+	stamp := now.Format("20060102T150405Z")
+	day := stamp[:8]
+
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if o.token != "" {
+		req.Header.Set("X-Amz-Security-Token", o.token)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonHeaders, "%s:%s\n", name, headers[name])
+	}
+	signed := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"", // no query
+		canonHeaders.String(),
+		signed,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + o.region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+o.secret), day)
+	for _, part := range []string{o.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		o.access, scope, signed, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsEscape percent-encodes p as SigV4 requires: every byte but the
+// unreserved characters and "/".
+func awsEscape(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '.' || c == '_' || c == '~' || c == '/' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,376 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ociUserEnv and ociPasswordEnv hold the credentials CachePush presents
+// to a registry that asks for them, as basic auth or to obtain a bearer
+// token from its token service.
+const (
+	ociUserEnv     = "ATLAS_OCI_USERNAME"
+	ociPasswordEnv = "ATLAS_OCI_PASSWORD"
+)
+
+// Media types of the artifacts CachePush writes: an OCI image manifest
+// whose single layer is the snapshot, packed as for a remote cache, and
+// whose config is the empty descriptor.
+const (
+	ociManifestType = "application/vnd.oci.image.manifest.v1+json"
+	ociArtifactType = "application/vnd.organic-programming.holon.v1"
+	ociLayerType    = "application/vnd.oci.image.layer.v1.tar+gzip"
+	ociEmptyType    = "application/vnd.oci.empty.v1+json"
+)
+
+// Annotation keys of the artifact manifest. Source and commit use the
+// pre-defined OCI keys, which registry tooling already displays.
+const (
+	annotationPath     = "org.organic-programming.holon.path"
+	annotationVersion  = "org.opencontainers.image.version"
+	annotationHash     = "org.organic-programming.holon.hash"
+	annotationSource   = "org.opencontainers.image.source"
+	annotationRevision = "org.opencontainers.image.revision"
+	annotationCreated  = "org.opencontainers.image.created"
+	annotationTitle    = "org.opencontainers.image.title"
+)
+
+// ociTag matches the tags the distribution spec allows.
+var ociTag = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]{0,127}$`)
+
+// CachePush exports the cached snapshot of req.Path@req.Version to an OCI
+// registry: the snapshot is the single layer of an artifact whose
+// manifest is annotated with its holon.sum hash and, when recorded, the
+// source and commit it was fetched from.
+func (s *Server) CachePush(ctx context.Context, req *pb.CachePushRequest) (_ *pb.CachePushResponse, err error) {
+	defer s.record("CachePush", "", &err)
+
+	if req.Path == "" || req.Version == "" || req.Target == "" {
+		return nil, status.Error(codes.InvalidArgument, "path, version and target are required")
+	}
+	ref, err := parseOCIReference(req.Target, req.Version)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	dep := req.Path + "@" + req.Version
+	dir, err := cacheStore().Get(req.Path, req.Version)
+	if err != nil {
+		return nil, notCachedError([]string{dep})
+	}
+
+	hash, err := sumHashDir(defaultHashAlgorithm, dir)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "hash %s: %v", dep, err)
+	}
+	layer, err := tarSnapshot(dir)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "pack %s: %v", dep, err)
+	}
+	annotations := map[string]string{
+		annotationPath:    req.Path,
+		annotationVersion: req.Version,
+		annotationHash:    hash,
+	}
+	if info := fetchInfoProto(req.Path, req.Version); info != nil {
+		annotations[annotationSource] = info.Source
+		annotations[annotationCreated] = info.Time
+		if info.Commit != "" {
+			annotations[annotationRevision] = info.Commit
+		}
+	}
+
+	empty := []byte("{}")
+	layerDesc := ociDescriptorOf(ociLayerType, layer)
+	layerDesc.Annotations = map[string]string{annotationTitle: snapshotKey(path.Base(req.Path), req.Version)}
+	manifest, err := json.Marshal(ociManifest{
+		SchemaVersion: 2,
+		MediaType:     ociManifestType,
+		ArtifactType:  ociArtifactType,
+		Config:        ociDescriptorOf(ociEmptyType, empty),
+		Layers:        []ociDescriptor{layerDesc},
+		Annotations:   annotations,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encode manifest: %v", err)
+	}
+
+	r := &ociRegistry{ref: ref, client: http.DefaultClient, ctx: ctx}
+	for _, blob := range [][]byte{empty, layer} {
+		if err := r.pushBlob(blob); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "push %s to %s: %v", dep, ref, err)
+		}
+	}
+	if err := r.pushManifest(manifest); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "push %s to %s: %v", dep, ref, err)
+	}
+
+	return &pb.CachePushResponse{
+		Reference:   ref.String(),
+		Digest:      ociDigest(manifest),
+		Size:        int64(len(layer)),
+		Annotations: annotations,
+	}, nil
+}
+
+type ociManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType"`
+	Config        ociDescriptor     `json:"config"`
+	Layers        []ociDescriptor   `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+func ociDescriptorOf(mediaType string, data []byte) ociDescriptor {
+	return ociDescriptor{MediaType: mediaType, Digest: ociDigest(data), Size: int64(len(data))}
+}
+
+func ociDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ociReference is a parsed "oci://<registry>/<repository>[:<tag>]".
+type ociReference struct {
+	registry   string
+	repository string
+	tag        string
+}
+
+func (r ociReference) String() string {
+	return r.registry + "/" + r.repository + ":" + r.tag
+}
+
+// parseOCIReference parses target, tagging with version when it names
+// no tag.
+func parseOCIReference(target, version string) (ociReference, error) {
+	rest, ok := strings.CutPrefix(target, "oci://")
+	registry, repository, _ := strings.Cut(rest, "/")
+	if !ok || registry == "" || repository == "" {
+		return ociReference{}, fmt.Errorf("target %q: want oci://<registry>/<repository>[:<tag>]", target)
+	}
+	ref := ociReference{registry: registry, repository: repository}
+	if i := strings.LastIndex(repository, ":"); i >= 0 {
+		ref.repository, ref.tag = repository[:i], repository[i+1:]
+	} else {
+		ref.tag = strings.ReplaceAll(version, "+", "_")
+	}
+	if !ociTag.MatchString(ref.tag) {
+		return ociReference{}, fmt.Errorf("target %q: invalid tag %q", target, ref.tag)
+	}
+	return ref, nil
+}
+
+// ociRegistry pushes to a repository through the OCI distribution API.
+type ociRegistry struct {
+	ref    ociReference
+	client *http.Client
+	ctx    context.Context
+	// auth is the Authorization header of the requests, once a challenge
+	// has been answered.
+	auth string
+}
+
+// baseURL is plain HTTP for a registry on the loopback interface, as
+// for local registries, and HTTPS otherwise.
+func (r *ociRegistry) baseURL() string {
+	host, _, err := net.SplitHostPort(r.ref.registry)
+	if err != nil {
+		host = r.ref.registry
+	}
+	if ip := net.ParseIP(host); host == "localhost" || ip != nil && ip.IsLoopback() {
+		return "http://" + r.ref.registry
+	}
+	return "https://" + r.ref.registry
+}
+
+// pushBlob uploads data, unless the repository already has it, in a
+// single monolithic upload.
+func (r *ociRegistry) pushBlob(data []byte) error {
+	digest := ociDigest(data)
+	blobs := r.baseURL() + "/v2/" + r.ref.repository + "/blobs/"
+	resp, err := r.do(http.MethodHead, blobs+digest, nil, "")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = r.do(http.MethodPost, blobs+"uploads/", nil, "")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return r.failed("start upload of "+digest, resp)
+	}
+	loc, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("upload location: %w", err)
+	}
+	q := loc.Query()
+	q.Set("digest", digest)
+	loc.RawQuery = q.Encode()
+
+	resp, err = r.do(http.MethodPut, loc.String(), data, "application/octet-stream")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return r.failed("upload "+digest, resp)
+	}
+	return nil
+}
+
+// pushManifest tags manifest in the repository.
+func (r *ociRegistry) pushManifest(manifest []byte) error {
+	u := r.baseURL() + "/v2/" + r.ref.repository + "/manifests/" + r.ref.tag
+	resp, err := r.do(http.MethodPut, u, manifest, ociManifestType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return r.failed("put manifest", resp)
+	}
+	return nil
+}
+
+func (r *ociRegistry) failed(op string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s: %s: %s", op, resp.Status, bytes.TrimSpace(msg))
+}
+
+// do sends a request, answering an authentication challenge once: with
+// the credentials of the environment for Basic, or with a token of the
+// registry's token service for Bearer.
+func (r *ociRegistry) do(method, u string, body []byte, contentType string) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(r.ctx, method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if r.auth != "" {
+			req.Header.Set("Authorization", r.auth)
+		}
+		return r.client.Do(req)
+	}
+	resp, err := send()
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || r.auth != "" {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	if r.auth, err = r.authorize(challenge); err != nil {
+		return nil, err
+	}
+	return send()
+}
+
+// authorize returns the Authorization header answering challenge.
+func (r *ociRegistry) authorize(challenge string) (string, error) {
+	user, password := os.Getenv(ociUserEnv), os.Getenv(ociPasswordEnv)
+	scheme, params, _ := strings.Cut(challenge, " ")
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if user == "" {
+			return "", fmt.Errorf("%s asks for credentials: set %s and %s", r.ref.registry, ociUserEnv, ociPasswordEnv)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(user, password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("%s: unsupported authentication challenge %q", r.ref.registry, challenge)
+	}
+
+	attrs := challengeParams(params)
+	realm, err := url.Parse(attrs["realm"])
+	if err != nil || attrs["realm"] == "" {
+		return "", fmt.Errorf("%s: bearer challenge without a realm", r.ref.registry)
+	}
+	q := realm.Query()
+	if attrs["service"] != "" {
+		q.Set("service", attrs["service"])
+	}
+	q.Set("scope", "repository:"+r.ref.repository+":pull,push")
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if user != "" {
+		req.SetBasicAuth(user, password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", r.failed("get token from "+realm.Host, resp)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("decode token from %s: %w", realm.Host, err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	return "Bearer " + token.Token, nil
+}
+
+// challengeParams parses the comma-separated key="value" parameters of
+// an authentication challenge.
+func challengeParams(s string) map[string]string {
+	attrs := map[string]string{}
+	for s != "" {
+		key, rest, ok := strings.Cut(strings.TrimLeft(s, " ,"), "=")
+		if !ok {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		attrs[strings.ToLower(strings.TrimSpace(key))] = value
+		s = rest
+	}
+	return attrs
+}
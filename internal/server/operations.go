@@ -0,0 +1,52 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// maxOperations bounds the in-memory list of recent operations.
+const maxOperations = 100
+
+// Operation is one mutating RPC handled by a Server. Read-only RPCs
+// (Verify, Graph) are not recorded.
+type Operation struct {
+	Time      time.Time
+	Method    string
+	Directory string
+	Err       string // empty on success
+}
+
+// opLog is a bounded, concurrency-safe list of recent operations.
+type opLog struct {
+	mu  sync.Mutex
+	ops []Operation
+}
+
+// record appends the outcome of an RPC to the operation log. It is meant
+// to be deferred with a pointer to the RPC's named error result.
+func (s *Server) record(method, dir string, err *error) {
+	op := Operation{Time: time.Now(), Method: method, Directory: dir}
+	if *err != nil {
+		op.Err = (*err).Error()
+	}
+
+	s.ops.mu.Lock()
+	defer s.ops.mu.Unlock()
+	s.ops.ops = append(s.ops.ops, op)
+	if len(s.ops.ops) > maxOperations {
+		s.ops.ops = s.ops.ops[len(s.ops.ops)-maxOperations:]
+	}
+}
+
+// RecentOperations returns the mutating operations handled by this Server,
+// most recent first.
+func (s *Server) RecentOperations() []Operation {
+	s.ops.mu.Lock()
+	defer s.ops.mu.Unlock()
+	out := make([]Operation, len(s.ops.ops))
+	for i, op := range s.ops.ops {
+		out[len(out)-1-i] = op
+	}
+	return out
+}
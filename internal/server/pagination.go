@@ -0,0 +1,49 @@
+package server
+
+import (
+	"encoding/base64"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Page size limits shared by list-style RPCs.
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// paginate returns the page of items following pageToken. Items must be
+// sorted by key; the token encodes the key of the last item returned, so
+// pages stay consistent when entries are added or removed between calls.
+func paginate[T any](items []T, key func(T) string, pageSize int32, pageToken string) ([]T, string, error) {
+	size := int(pageSize)
+	switch {
+	case size < 0:
+		return nil, "", status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+
+	start := 0
+	if pageToken != "" {
+		after, err := base64.RawURLEncoding.DecodeString(pageToken)
+		if err != nil {
+			return nil, "", status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		start = sort.Search(len(items), func(i int) bool {
+			return key(items[i]) > string(after)
+		})
+	}
+
+	end := min(start+size, len(items))
+	page := items[start:end]
+	var next string
+	if end < len(items) {
+		next = base64.RawURLEncoding.EncodeToString([]byte(key(items[end-1])))
+	}
+	return page, next, nil
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+)
+
+// fileCache memoizes a parsed file, keyed by absolute path. An entry is
+// reused only while the file's modification time and size are unchanged;
+// writes made through the Server invalidate it explicitly, which covers
+// edits that land within the same mtime tick.
+type fileCache[T any] struct {
+	mu      sync.Mutex
+	entries map[string]fileCacheEntry[T]
+}
+
+type fileCacheEntry[T any] struct {
+	modTime time.Time
+	size    int64
+	value   T
+}
+
+// get returns a private copy of the parsed file at path, parsing it only
+// when the cached entry is missing or stale.
+func (c *fileCache[T]) get(path string, parse func(string) (T, error), clone func(T) T) (T, error) {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return parse(path)
+	}
+	info, err := os.Stat(key)
+	if err != nil {
+		c.invalidate(path)
+		return parse(path)
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && e.modTime.Equal(info.ModTime()) && e.size == info.Size() {
+		return clone(e.value), nil
+	}
+
+	v, err := parse(path)
+	if err != nil {
+		return v, err
+	}
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]fileCacheEntry[T])
+	}
+	c.entries[key] = fileCacheEntry[T]{modTime: info.ModTime(), size: info.Size(), value: clone(v)}
+	c.mu.Unlock()
+	return v, nil
+}
+
+// invalidate drops the entry for path, if any.
+func (c *fileCache[T]) invalidate(path string) {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
+
+// parseMod parses a holon.mod through the Server's parse cache.
+func (s *Server) parseMod(path string) (*modfile.ModFile, error) {
+	return s.mods.get(path, modfile.Parse, (*modfile.ModFile).Clone)
+}
+
+// parseSum parses a holon.sum through the Server's parse cache.
+func (s *Server) parseSum(path string) (*modfile.SumFile, error) {
+	return s.sums.get(path, modfile.ParseSum, (*modfile.SumFile).Clone)
+}
+
+// writeMod writes a holon.mod and invalidates its cache entry.
+func (s *Server) writeMod(mod *modfile.ModFile, path string) error {
+	defer s.mods.invalidate(path)
+	return mod.Write(path)
+}
+
+// writeSum writes a holon.sum and invalidates its cache entry.
+func (s *Server) writeSum(sum *modfile.SumFile, path string) error {
+	defer s.sums.invalidate(path)
+	return sum.Write(path)
+}
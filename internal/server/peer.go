@@ -0,0 +1,230 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/client"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// entryChunkSize is the size of the FetchEntryChunk messages.
+const entryChunkSize = 64 << 10
+
+// HasEntry reports whether a snapshot is in the local cache. Only the
+// local copies are served to other servers, so that servers using each
+// other as shared caches cannot loop.
+func (s *Server) HasEntry(_ context.Context, req *pb.HasEntryRequest) (*pb.HasEntryResponse, error) {
+	if req.Path == "" || req.Version == "" {
+		return nil, status.Error(codes.InvalidArgument, "path and version are required")
+	}
+	entry, err := fsStore{}.Stat(req.Path, req.Version)
+	switch {
+	case err == nil:
+		return &pb.HasEntryResponse{Present: true, Size: entry.Size}, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return &pb.HasEntryResponse{}, nil
+	case errors.Is(err, fs.ErrInvalid):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	default:
+		return nil, status.Errorf(codes.Internal, "stat %s@%s: %v", req.Path, req.Version, err)
+	}
+}
+
+// FetchEntry streams a snapshot of the local cache as a zip archive.
+func (s *Server) FetchEntry(req *pb.FetchEntryRequest, stream pb.RhizomeAtlasService_FetchEntryServer) error {
+	if req.Path == "" || req.Version == "" {
+		return status.Error(codes.InvalidArgument, "path and version are required")
+	}
+	dir, err := fsStore{}.Get(req.Path, req.Version)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return status.Errorf(codes.NotFound, "%s@%s is not cached", req.Path, req.Version)
+	case errors.Is(err, fs.ErrInvalid):
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	case err != nil:
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+
+	// Everything served is logged first, so that clients can check later
+	// that it is what others were served too.
+	if err := s.clog.ensure(req.Path, req.Version, dir); err != nil {
+		return status.Errorf(codes.Internal, "log %s@%s: %v", req.Path, req.Version, err)
+	}
+	data, err := zipSnapshot(dir)
+	if err != nil {
+		return status.Errorf(codes.Internal, "zip %s@%s: %v", req.Path, req.Version, err)
+	}
+	for len(data) > 0 {
+		n := min(entryChunkSize, len(data))
+		if err := stream.Send(&pb.FetchEntryChunk{Data: data[:n]}); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// peerStore fetches snapshots from the local cache of another atlas
+// server. It is read-only: each server shares what it fetched itself.
+type peerStore struct {
+	uri string
+	c   *client.Client
+}
+
+// peers holds one client per peer URI, for the life of the process.
+var peers sync.Map // string → *client.Client
+
+func newPeerStore(uri string) (peerStore, error) {
+	if c, ok := peers.Load(uri); ok {
+		return peerStore{uri: uri, c: c.(*client.Client)}, nil
+	}
+	c, err := client.Dial(uri)
+	if err != nil {
+		return peerStore{}, fmt.Errorf("%s: %w", remoteCacheEnv, err)
+	}
+	if prev, loaded := peers.LoadOrStore(uri, c); loaded {
+		c.Close() //nolint:errcheck
+		c = prev.(*client.Client)
+	}
+	return peerStore{uri: uri, c: c}, nil
+}
+
+func (p peerStore) String() string { return p.uri }
+
+func (p peerStore) fetch(path, version, dst string) error {
+	ctx := context.Background()
+	rpc := p.c.Service()
+	has, err := rpc.HasEntry(ctx, &pb.HasEntryRequest{Path: path, Version: version})
+	if err != nil {
+		return fmt.Errorf("%s: %w", p, err)
+	}
+	if !has.Present {
+		return &fs.PathError{Op: "fetch", Path: p.uri + "/" + path + "@" + version, Err: fs.ErrNotExist}
+	}
+
+	limits, err := downloadLimits()
+	if err != nil {
+		return err
+	}
+	stream, err := rpc.FetchEntry(ctx, &pb.FetchEntryRequest{Path: path, Version: version})
+	if err != nil {
+		return fmt.Errorf("%s: %w", p, err)
+	}
+	var data bytes.Buffer
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%s: fetch %s@%s: %w", p, path, version, err)
+		}
+		if err := limits.wait(ctx, len(chunk.Data)); err != nil {
+			return err
+		}
+		data.Write(chunk.Data)
+	}
+	if err := unzipSnapshot(data.Bytes(), dst); err != nil {
+		return fmt.Errorf("unpack %s@%s from %s: %w", path, version, p, err)
+	}
+	return nil
+}
+
+func (peerStore) store(string, string, string) error { return nil }
+
+// zipSnapshot packs the regular files of dir into a zip archive.
+func zipSnapshot(dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		hdr.Name = filepath.ToSlash(rel)
+		hdr.Method = zip.Deflate
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unzipSnapshot unpacks the regular files of a zip archive made by
+// zipSnapshot into dir, rejecting entries that would land outside of it.
+func unzipSnapshot(data []byte, dir string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		name := filepath.FromSlash(f.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid entry %q", f.Name)
+		}
+		if err := unzipFile(f, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unzipFile(f *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	in, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
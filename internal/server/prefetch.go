@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Prefetch starts fetching the requested dependencies missing from the
+// cache and returns, unless req.Wait is set. The outcome of the
+// background fetches is recorded in the operation log once all are done.
+func (s *Server) Prefetch(_ context.Context, req *pb.PrefetchRequest) (*pb.PrefetchResponse, error) {
+	// The fetches outlive the request.
+	ctx := withFetchQueue(s.withEvents(context.Background()), "Prefetch")
+	var queued []*pb.Dependency
+	seen := map[string]bool{}
+	want := func(path, version string) {
+		key := path + "@" + version
+		if seen[key] || inCache(path, version) {
+			return
+		}
+		seen[key] = true
+		queued = append(queued, &pb.Dependency{Path: path, Version: version})
+	}
+
+	for _, d := range req.Dependencies {
+		at := strings.LastIndex(d, "@")
+		if at <= 0 || at == len(d)-1 {
+			return nil, status.Errorf(codes.InvalidArgument, "%q: want <path>@<version>", d)
+		}
+		want(d[:at], d[at+1:])
+	}
+	if len(req.HolonMod) > 0 {
+		mod, err := modfile.ParseBytes(req.HolonMod)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "parse holon_mod: %v", err)
+		}
+		ctx = withFetchSources(ctx, mod)
+		for _, r := range mod.Require {
+			if mod.ResolvedPath(r.Path) == "" {
+				want(mod.SourcePath(r.Path), r.Version)
+			}
+		}
+	}
+
+	// The background fetches work on copies: the response may be sent
+	// while they run.
+	work := make([]*pb.Dependency, len(queued))
+	for i, d := range queued {
+		work[i] = proto.CloneOf(d)
+	}
+	done := make(chan []string, 1)
+	go func() { done <- s.prefetch(ctx, work) }()
+	resp := &pb.PrefetchResponse{Queued: queued}
+	if req.Wait {
+		resp.Queued, resp.Errors = work, <-done
+	}
+	return resp, nil
+}
+
+// prefetch fetches deps to the cache, as many at a time as the fetch
+// scheduler runs, setting the cache path of those fetched, and returns the
+// failures.
+func (s *Server) prefetch(ctx context.Context, deps []*pb.Dependency) []string {
+	results := fetchAll(ctx, deps)
+	var errs []string
+	for _, d := range deps {
+		r := results[d.Path+"@"+d.Version]
+		if r.err != nil {
+			errs = append(errs, fmt.Sprintf("%s@%s: %v", d.Path, d.Version, r.err))
+			continue
+		}
+		d.CachePath = r.dir
+		d.Source = fetchSource(d.Path, d.Version)
+	}
+	sort.Strings(errs)
+
+	var err error
+	if len(errs) > 0 {
+		err = errors.New(strings.Join(errs, "; "))
+	}
+	s.record("Prefetch", "", &err)
+	return errs
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+)
+
+// progressInterval is how often the bytes a fetch transferred are
+// measured, for the pulls following its progress.
+const progressInterval = 200 * time.Millisecond
+
+type progressKey struct{}
+
+// withProgress returns ctx reporting the progress of the dependencies
+// pulled with it to report, which is called from the goroutines fetching
+// them and must be safe for concurrent use.
+func withProgress(ctx context.Context, report func(*pb.PullProgress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// progressOf returns the function ctx reports progress to, if any.
+func progressOf(ctx context.Context) func(*pb.PullProgress) {
+	report, _ := ctx.Value(progressKey{}).(func(*pb.PullProgress))
+	return report
+}
+
+// reportProgress reports that depPath@version reached stage, to the
+// function ctx carries, if any.
+func reportProgress(ctx context.Context, stage pb.PullStage, depPath, version string, bytes int64) {
+	if report := progressOf(ctx); report != nil {
+		report(&pb.PullProgress{Stage: stage, Dependency: &pb.Dependency{Path: depPath, Version: version}, Bytes: bytes})
+	}
+}
+
+// transfer is the set of directories a fetch writes what it transfers to.
+type transfer struct {
+	mu   sync.Mutex
+	dirs []string
+}
+
+type transferKey struct{}
+
+// watchTransfer reports, every progressInterval until stop is called, the
+// bytes the fetch of depPath@version made with the returned ctx has
+// written to dir and to the directories it adds with transferDir, as they
+// grow. Without a progress function in ctx it measures nothing.
+func watchTransfer(ctx context.Context, depPath, version, dir string) (_ context.Context, stop func()) {
+	if progressOf(ctx) == nil {
+		return ctx, func() {}
+	}
+	t := &transfer{dirs: []string{dir}}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		var reported int64
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			var size int64
+			t.mu.Lock()
+			dirs := slices.Clone(t.dirs)
+			t.mu.Unlock()
+			for _, d := range dirs {
+				// Files come and go while git writes: count what is there.
+				n, _ := dirSize(d)
+				size += n
+			}
+			if size > reported {
+				reported = size
+				reportProgress(ctx, pb.PullStage_PULL_STAGE_TRANSFERRING, depPath, version, size)
+			}
+		}
+	}()
+	return context.WithValue(ctx, transferKey{}, t), func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// transferDir adds dir to the directories measured for the transfer ctx
+// carries, if any, until the returned function is called.
+func transferDir(ctx context.Context, dir string) func() {
+	t, ok := ctx.Value(transferKey{}).(*transfer)
+	if !ok {
+		return func() {}
+	}
+	t.mu.Lock()
+	t.dirs = append(t.dirs, dir)
+	t.mu.Unlock()
+	return func() {
+		t.mu.Lock()
+		t.dirs = slices.DeleteFunc(t.dirs, func(d string) bool { return d == dir })
+		t.mu.Unlock()
+	}
+}
@@ -0,0 +1,299 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitEnv names the environment variable capping the download rate of
+// the whole process, in bytes per second with an optional k, M or G suffix
+// (powers of 1024), e.g. "2M". fetchRateLimitEnv caps each fetch alike.
+// Both apply to snapshots downloaded from a shared cache and to git
+// fetches over HTTP(S); unset or "0" means unlimited.
+const (
+	rateLimitEnv      = "ATLAS_RATE_LIMIT"
+	fetchRateLimitEnv = "ATLAS_FETCH_RATE_LIMIT"
+)
+
+// limitChunk is the most a limited download reads at once, so that a slow
+// rate is kept smoothly rather than in large steps.
+const limitChunk = 8 << 10
+
+// parseRate parses a rate as rateLimitEnv documents it.
+func parseRate(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	num, mult := s, 1.0
+	switch s[len(s)-1] {
+	case 'k', 'K':
+		mult = 1 << 10
+	case 'm', 'M':
+		mult = 1 << 20
+	case 'g', 'G':
+		mult = 1 << 30
+	}
+	if mult > 1 {
+		num = s[:len(s)-1]
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, fmt.Errorf("invalid rate %q (want bytes per second, e.g. 512k or 2M)", s)
+	}
+	return int64(f * mult), nil
+}
+
+// limiter is a token bucket letting rate bytes per second through, in
+// bursts of at most a quarter of a second's worth.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter returns a limiter of rate bytes per second, or nil for none.
+func newLimiter(rate int64) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &limiter{rate: float64(rate)}
+}
+
+// wait blocks until n more bytes may pass, or ctx is done.
+func (l *limiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.rate/4, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens -= float64(n)
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limits are the limiters a download goes through.
+type limits []*limiter
+
+// wait blocks until n more bytes may pass every limiter, or ctx is done.
+func (ls limits) wait(ctx context.Context, n int) error {
+	for _, l := range ls {
+		if err := l.wait(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reader returns r read no faster than ls allow.
+func (ls limits) reader(ctx context.Context, r io.Reader) io.Reader {
+	if len(ls) == 0 {
+		return r
+	}
+	return limitedReader{ctx, r, ls}
+}
+
+type limitedReader struct {
+	ctx    context.Context
+	r      io.Reader
+	limits limits
+}
+
+func (lr limitedReader) Read(p []byte) (int, error) {
+	if len(p) > limitChunk {
+		p = p[:limitChunk]
+	}
+	n, err := lr.r.Read(p)
+	if werr := lr.limits.wait(lr.ctx, n); werr != nil && err == nil {
+		err = werr
+	}
+	return n, err
+}
+
+// processLimit is the limiter shared by every download of the process, of
+// the rate rateLimitEnv holds when it was last read.
+var processLimit struct {
+	mu  sync.Mutex
+	env string
+	l   *limiter
+}
+
+// downloadLimits returns the limits of a new download: the process's,
+// then one of its own.
+func downloadLimits() (limits, error) {
+	env := os.Getenv(rateLimitEnv)
+	processLimit.mu.Lock()
+	if env != processLimit.env {
+		rate, err := parseRate(env)
+		if err != nil {
+			processLimit.mu.Unlock()
+			return nil, fmt.Errorf("%s: %w", rateLimitEnv, err)
+		}
+		processLimit.env, processLimit.l = env, newLimiter(rate)
+	}
+	shared := processLimit.l
+	processLimit.mu.Unlock()
+
+	rate, err := parseRate(os.Getenv(fetchRateLimitEnv))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fetchRateLimitEnv, err)
+	}
+	var ls limits
+	for _, l := range []*limiter{shared, newLimiter(rate)} {
+		if l != nil {
+			ls = append(ls, l)
+		}
+	}
+	return ls, nil
+}
+
+// throttle is the local HTTP proxy that git fetches go through when
+// downloads are limited: git cannot pace a transfer itself, but follows
+// http.proxy. Each fetch is told apart by the proxy credentials it is
+// given.
+var throttle struct {
+	once    sync.Once
+	addr    string
+	err     error
+	mu      sync.Mutex
+	fetches map[string]limits // by credentials
+}
+
+// gitLimitConfig returns the git options that route a fetch of gitURL
+// through the throttle, and the function to call once it is done. There
+// are none when downloads are not limited, and for transports other than
+// HTTP(S) or fetches that already go through a proxy.
+func gitLimitConfig(ctx context.Context, gitURL string) ([]string, func(), error) {
+	ls, err := downloadLimits()
+	if err != nil || len(ls) == 0 {
+		return nil, func() {}, err
+	}
+	u, err := url.Parse(gitURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || proxied(ctx, u) {
+		return nil, func() {}, nil
+	}
+
+	throttle.once.Do(func() {
+		var lis net.Listener
+		if lis, throttle.err = net.Listen("tcp", "127.0.0.1:0"); throttle.err == nil {
+			throttle.addr = lis.Addr().String()
+			go http.Serve(lis, throttleProxy{}) //nolint:errcheck
+		}
+	})
+	if throttle.err != nil {
+		return nil, nil, fmt.Errorf("rate limit proxy: %w", throttle.err)
+	}
+
+	id := make([]byte, 16)
+	rand.Read(id) //nolint:errcheck // never fails
+	user := url.UserPassword("fetch", hex.EncodeToString(id))
+	creds := base64.StdEncoding.EncodeToString([]byte(user.String()))
+	throttle.mu.Lock()
+	if throttle.fetches == nil {
+		throttle.fetches = map[string]limits{}
+	}
+	throttle.fetches[creds] = ls
+	throttle.mu.Unlock()
+
+	proxy := &url.URL{Scheme: "http", User: user, Host: throttle.addr}
+	config := []string{"-c", "http.proxy=" + proxy.String(), "-c", "http.proxyAuthMethod=basic"}
+	return config, func() {
+		throttle.mu.Lock()
+		delete(throttle.fetches, creds)
+		throttle.mu.Unlock()
+	}, nil
+}
+
+// proxied reports whether git fetches u through a proxy already, from its
+// configuration or the environment.
+func proxied(ctx context.Context, u *url.URL) bool {
+	if out, _ := exec.CommandContext(ctx, "git", "config", "--get-urlmatch", "http.proxy", u.String()).Output(); len(strings.TrimSpace(string(out))) > 0 {
+		return true
+	}
+	for _, name := range []string{u.Scheme + "_proxy", "all_proxy"} {
+		if os.Getenv(name) != "" || os.Getenv(strings.ToUpper(name)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// throttleProxy forwards the requests of the fetches registered in
+// throttle, tunnelled or not, pacing what they download.
+type throttleProxy struct{}
+
+// forwardTransport sends the plain HTTP requests throttleProxy forwards.
+var forwardTransport = &http.Transport{}
+
+func (throttleProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	creds, _ := strings.CutPrefix(r.Header.Get("Proxy-Authorization"), "Basic ")
+	throttle.mu.Lock()
+	ls, ok := throttle.fetches[creds]
+	throttle.mu.Unlock()
+	if !ok {
+		w.Header().Set("Proxy-Authenticate", `Basic realm="atlas"`)
+		http.Error(w, "unknown fetch", http.StatusProxyAuthRequired)
+		return
+	}
+
+	if r.Method == http.MethodConnect {
+		upstream, err := net.DialTimeout("tcp", r.Host, 30*time.Second)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, err := conn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+			return
+		}
+		go io.Copy(upstream, buf)                                //nolint:errcheck
+		io.Copy(conn, ls.reader(context.Background(), upstream)) //nolint:errcheck
+		return
+	}
+
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	out.Header.Del("Proxy-Authorization")
+	out.Header.Del("Proxy-Connection")
+	resp, err := forwardTransport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for name, values := range resp.Header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, ls.reader(r.Context(), resp.Body)) //nolint:errcheck
+}
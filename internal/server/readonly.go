@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mutatingMethods are the RPCs that write holon files, the cache, a
+// mirror or a registry, refused by a read-only server.
+var mutatingMethods = map[string]bool{
+	pb.RhizomeAtlasService_Init_FullMethodName:            true,
+	pb.RhizomeAtlasService_New_FullMethodName:             true,
+	pb.RhizomeAtlasService_Add_FullMethodName:             true,
+	pb.RhizomeAtlasService_Remove_FullMethodName:          true,
+	pb.RhizomeAtlasService_Pull_FullMethodName:            true,
+	pb.RhizomeAtlasService_StreamPull_FullMethodName:      true,
+	pb.RhizomeAtlasService_Lock_FullMethodName:            true,
+	pb.RhizomeAtlasService_Update_FullMethodName:          true,
+	pb.RhizomeAtlasService_Vendor_FullMethodName:          true,
+	pb.RhizomeAtlasService_StreamVendor_FullMethodName:    true,
+	pb.RhizomeAtlasService_CleanCache_FullMethodName:      true,
+	pb.RhizomeAtlasService_Release_FullMethodName:         true,
+	pb.RhizomeAtlasService_BundleCreate_FullMethodName:    true,
+	pb.RhizomeAtlasService_BundleInstall_FullMethodName:   true,
+	pb.RhizomeAtlasService_SumPrune_FullMethodName:        true,
+	pb.RhizomeAtlasService_SumMerge_FullMethodName:        true,
+	pb.RhizomeAtlasService_SumMigrate_FullMethodName:      true,
+	pb.RhizomeAtlasService_Tidy_FullMethodName:            true,
+	pb.RhizomeAtlasService_ReplaceDiff_FullMethodName:     true,
+	pb.RhizomeAtlasService_ReplacePromote_FullMethodName:  true,
+	pb.RhizomeAtlasService_ModMerge_FullMethodName:        true,
+	pb.RhizomeAtlasService_Undo_FullMethodName:            true,
+	pb.RhizomeAtlasService_CachePush_FullMethodName:       true,
+	pb.RhizomeAtlasService_CacheSeed_FullMethodName:       true,
+	pb.RhizomeAtlasService_Prefetch_FullMethodName:        true,
+	pb.RhizomeAtlasService_StartPull_FullMethodName:       true,
+	pb.RhizomeAtlasService_StartUpdate_FullMethodName:     true,
+	pb.RhizomeAtlasService_CancelOperation_FullMethodName: true,
+	pb.RhizomeAtlasService_MirrorSync_FullMethodName:      true,
+}
+
+// readOnlyMethods are the RPCs that write nothing, served by a read-only
+// server. Every RPC is in exactly one of mutatingMethods and
+// readOnlyMethods.
+var readOnlyMethods = map[string]bool{
+	pb.RhizomeAtlasService_Resolve_FullMethodName:             true,
+	pb.RhizomeAtlasService_Verify_FullMethodName:              true,
+	pb.RhizomeAtlasService_VerifyAll_FullMethodName:           true,
+	pb.RhizomeAtlasService_Graph_FullMethodName:               true,
+	pb.RhizomeAtlasService_StreamGraph_FullMethodName:         true,
+	pb.RhizomeAtlasService_Describe_FullMethodName:            true,
+	pb.RhizomeAtlasService_FindCapability_FullMethodName:      true,
+	pb.RhizomeAtlasService_Owners_FullMethodName:              true,
+	pb.RhizomeAtlasService_History_FullMethodName:             true,
+	pb.RhizomeAtlasService_CacheList_FullMethodName:           true,
+	pb.RhizomeAtlasService_CacheOrphans_FullMethodName:        true,
+	pb.RhizomeAtlasService_HasEntry_FullMethodName:            true,
+	pb.RhizomeAtlasService_FetchEntry_FullMethodName:          true,
+	pb.RhizomeAtlasService_GetLogHead_FullMethodName:          true,
+	pb.RhizomeAtlasService_ProveLogInclusion_FullMethodName:   true,
+	pb.RhizomeAtlasService_ProveLogConsistency_FullMethodName: true,
+	pb.RhizomeAtlasService_GetOperation_FullMethodName:        true,
+	pb.RhizomeAtlasService_WatchOperation_FullMethodName:      true,
+	pb.RhizomeAtlasService_Reproduce_FullMethodName:           true,
+	pb.RhizomeAtlasService_Impact_FullMethodName:              true,
+	pb.RhizomeAtlasService_Freshness_FullMethodName:           true,
+	pb.RhizomeAtlasService_Subscribe_FullMethodName:           true,
+	pb.RhizomeAtlasService_Diagnose_FullMethodName:            true,
+	pb.RhizomeAtlasService_Env_FullMethodName:                 true,
+}
+
+// atlasMethod reports whether method, in full, is one of the atlas
+// service, which mutatingMethods and readOnlyMethods classify.
+func atlasMethod(method string) bool {
+	return strings.HasPrefix(method, "/"+pb.RhizomeAtlasService_ServiceDesc.ServiceName+"/")
+}
+
+// checkReadOnly refuses, with PermissionDenied, a call of method with req
+// that a read-only server does not serve: one of mutatingMethods, unless
+// it is a dry run, one asking for a repair of the cache, or an atlas
+// method in neither list. The methods of other services, reflection and
+// those of ServeOptions.Register, are left to them.
+func checkReadOnly(method string, req any) error {
+	dryRun, _ := req.(interface{ GetDryRun() bool })
+	repair, _ := req.(interface{ GetRepair() bool })
+	switch {
+	case !atlasMethod(method):
+		return nil
+	case !mutatingMethods[method] && !readOnlyMethods[method]:
+		return status.Errorf(codes.PermissionDenied, "%s: the server is read-only, and does not know whether this writes", method)
+	case mutatingMethods[method] && (dryRun == nil || !dryRun.GetDryRun()):
+		return status.Errorf(codes.PermissionDenied, "%s: the server is read-only", method)
+	case repair != nil && repair.GetRepair():
+		return status.Errorf(codes.PermissionDenied, "%s: the server is read-only, it does not repair the cache", method)
+	}
+	return nil
+}
+
+// readOnlyUnary is the unary interceptor of a read-only server.
+func readOnlyUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := checkReadOnly(info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// readOnlyStream is the stream interceptor of a read-only server. The
+// streaming RPCs have no dry run, so the method alone decides.
+func readOnlyStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := checkReadOnly(info.FullMethod, nil); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"testing"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+)
+
+// TestReadOnlyMethods checks that every RPC of the service is either
+// refused or served by a read-only server, so that a new one is
+// classified when it is added.
+func TestReadOnlyMethods(t *testing.T) {
+	desc := pb.RhizomeAtlasService_ServiceDesc
+	var methods []string
+	for _, m := range desc.Methods {
+		methods = append(methods, "/"+desc.ServiceName+"/"+m.MethodName)
+	}
+	for _, m := range desc.Streams {
+		methods = append(methods, "/"+desc.ServiceName+"/"+m.StreamName)
+	}
+	known := map[string]bool{}
+	for _, method := range methods {
+		known[method] = true
+		switch {
+		case mutatingMethods[method] && readOnlyMethods[method]:
+			t.Errorf("%s is both in mutatingMethods and readOnlyMethods", method)
+		case !mutatingMethods[method] && !readOnlyMethods[method]:
+			t.Errorf("%s is in neither mutatingMethods nor readOnlyMethods", method)
+		}
+	}
+	for _, set := range []map[string]bool{mutatingMethods, readOnlyMethods} {
+		for method := range set {
+			if !known[method] {
+				t.Errorf("%s is not a method of %s", method, desc.ServiceName)
+			}
+		}
+	}
+	if err := checkReadOnly(pb.RhizomeAtlasService_ReplaceDiff_FullMethodName, &pb.ReplaceDiffRequest{}); err == nil {
+		t.Error("ReplaceDiff served by a read-only server")
+	}
+	if err := checkReadOnly("/"+desc.ServiceName+"/Unknown", nil); err == nil {
+		t.Error("an unknown method served by a read-only server")
+	}
+	if err := checkReadOnly("/example.v1.Other/Write", nil); err != nil {
+		t.Errorf("a method of another service refused by a read-only server: %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/client"
+	"github.com/organic-programming/rhizome-atlas/pkg/holonmd"
+	"github.com/organic-programming/rhizome-atlas/pkg/provenance"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Release validates the holon in req.Directory, computes the next semver
+// from its existing tags and creates an annotated tag for it. The holon
+// must have a valid holon.mod without replace directives, a parsable
+// HOLON.md if one exists, and a clean git work tree. With req.Publish the
+// tag is pushed to the holon's repository in the registry, which is how
+// templates (see New) are published, and a registry in a directory has its
+// TUF metadata signed if ATLAS_TUF_KEYS is set.
+func (s *Server) Release(ctx context.Context, req *pb.ReleaseRequest) (_ *pb.ReleaseResponse, err error) {
+	defer s.record("Release", req.Directory, &err)
+
+	dir := holonDir(req.Directory)
+
+	mod, err := s.parseMod(filepath.Join(dir, "holon.mod"))
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "parse holon.mod: %v", err)
+	}
+	if mod.HolonPath == "" {
+		return nil, status.Error(codes.FailedPrecondition, "holon.mod has no holon path")
+	}
+	if len(mod.Replace) > 0 {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"holon.mod has active replace %s => %s", mod.Replace[0].Old, mod.Replace[0].New)
+	}
+	if _, err := holonmd.Parse(filepath.Join(dir, "HOLON.md")); err != nil && !os.IsNotExist(err) {
+		return nil, status.Errorf(codes.FailedPrecondition, "parse HOLON.md: %v", err)
+	}
+	var registryRepo string
+	if req.Publish {
+		registry := sourcesFrom(withFetchSources(ctx, mod)).registry
+		if registry == "" {
+			return nil, status.Errorf(codes.FailedPrecondition, "no registry to publish to: set %s or a registry directive in holon.mod", registryEnv)
+		}
+		registryRepo = registry + "/" + mod.HolonPath
+	}
+
+	dirty, err := git(dir, "status", "--porcelain")
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "git status: %v", err)
+	}
+	if dirty != "" {
+		return nil, detailed(codes.FailedPrecondition, client.ReasonDirtyWorkTree, map[string]string{"file": dir}, nil, "work tree has uncommitted changes")
+	}
+
+	tags, err := git(dir, "tag", "--list", "v*")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "git tag: %v", err)
+	}
+	previous := latestTag(strings.Fields(tags))
+	version := nextVersion(previous, req.Bump)
+
+	message := "Release " + version
+	stmt, err := releaseProvenance(dir, mod.HolonPath, version, req)
+	if err != nil {
+		return nil, err
+	}
+	if stmt != nil {
+		block, err := provenance.Encode(stmt)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "encode provenance: %v", err)
+		}
+		message += "\n\n" + block
+	}
+
+	if _, err := git(dir, "tag", "-a", version, "-m", message); err != nil {
+		return nil, status.Errorf(codes.Internal, "git tag %s: %v", version, err)
+	}
+
+	resp := &pb.ReleaseResponse{PreviousVersion: previous, Version: version, Attested: stmt != nil}
+	if req.Push {
+		if _, err := git(dir, "push", "origin", version); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "git push %s: %v", version, err)
+		}
+		resp.Pushed = true
+	}
+	if req.Publish {
+		if local := localDir(registryRepo); local != "" && !isRepo(local) {
+			if _, err := git(".", "init", "--quiet", "--bare", local); err != nil {
+				return nil, status.Errorf(codes.Internal, "create %s: %v", registryRepo, err)
+			}
+		}
+		if _, err := git(dir, "push", registryRepo, version); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "publish %s to %s: %v", version, registryRepo, err)
+		}
+		// A registry in a directory is signed here; a remote one by its
+		// operator.
+		if registry := localDir(strings.TrimSuffix(registryRepo, "/"+mod.HolonPath)); registry != "" {
+			if err := signRepository(ctx, registry); err != nil {
+				return nil, status.Errorf(codes.Internal, "sign %s: %v", registry, err)
+			}
+		}
+		resp.Published = registryRepo
+	}
+	return resp, nil
+}
+
+// releaseProvenance returns the provenance statement to attach to the
+// release of holonPath@version, or nil if req asks for none: the statement in
+// req.Provenance, which must attest to HEAD, else one naming req.Builder.
+func releaseProvenance(dir, holonPath, version string, req *pb.ReleaseRequest) (*provenance.Statement, error) {
+	if len(req.Provenance) == 0 && req.Builder == "" {
+		if req.Workflow != "" {
+			return nil, status.Error(codes.InvalidArgument, "workflow given without builder")
+		}
+		return nil, nil
+	}
+	if len(req.Provenance) > 0 && (req.Builder != "" || req.Workflow != "") {
+		return nil, status.Error(codes.InvalidArgument, "provenance statement given with builder or workflow")
+	}
+
+	commit, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "git rev-parse HEAD: %v", err)
+	}
+	if len(req.Provenance) > 0 {
+		stmt, err := provenance.Parse(req.Provenance)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "provenance: %v", err)
+		}
+		if err := stmt.Verify(commit); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "provenance: %v", err)
+		}
+		return stmt, nil
+	}
+	source := "git+https://" + holonPath + "@refs/tags/" + version
+	return provenance.New(holonPath+"@"+version, source, commit, req.Builder, req.Workflow), nil
+}
+
+// latestTag returns the highest release tag, or "" if there is none.
+// Prerelease tags are ignored.
+func latestTag(tags []string) string {
+	var latest string
+	for _, tag := range tags {
+		if _, _, _, ok := semver.Parse(tag); !ok || semver.Prerelease(tag) != "" {
+			continue
+		}
+		if latest == "" || semver.Compare(tag, latest) > 0 {
+			latest = tag
+		}
+	}
+	return latest
+}
+
+// nextVersion bumps previous; a first release is v0.1.0 for a minor or
+// patch bump and v1.0.0 for a major one.
+func nextVersion(previous string, bump pb.ReleaseBump) string {
+	major, minor, patch, ok := semver.Parse(previous)
+	if !ok {
+		if bump == pb.ReleaseBump_RELEASE_BUMP_MAJOR {
+			return "v1.0.0"
+		}
+		return "v0.1.0"
+	}
+	switch bump {
+	case pb.ReleaseBump_RELEASE_BUMP_MAJOR:
+		major, minor, patch = major+1, 0, 0
+	case pb.ReleaseBump_RELEASE_BUMP_MINOR:
+		minor, patch = minor+1, 0
+	default:
+		patch++
+	}
+	return fmt.Sprintf("v%d.%d.%d", major, minor, patch)
+}
+
+// git runs a git command in dir and returns its trimmed standard output.
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok && len(exit.Stderr) > 0 {
+			return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exit.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,311 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// diffContext is the number of unchanged lines around each change in the
+// hunks of a unified diff.
+const diffContext = 3
+
+// ReplaceDiff compares the local directory replacing req.Path with the
+// version holon.mod requires, fetched to the cache if missing and then
+// checked against holon.sum, as Pull would fetch it without the replace.
+func (s *Server) ReplaceDiff(ctx context.Context, req *pb.ReplaceDiffRequest) (*pb.ReplaceDiffResponse, error) {
+	ctx = s.withEvents(ctx)
+	dir := holonDir(req.Directory)
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	dep, ok := mod.RequireByName(req.Path)
+	if !ok {
+		return nil, unknownDependencyError(modPath, req.Path)
+	}
+	local := mod.ResolvedPath(dep.Path)
+	if local == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s is not replaced by a local directory", dep.Path)
+	}
+	ctx = withFetchSources(ctx, mod)
+
+	sumPath := filepath.Join(dir, "holon.sum")
+	recorded, _ := s.parseSum(sumPath)
+	fresh := !inCache(dep.Path, dep.Version)
+	cachePath, err := fetchToCache(ctx, dep.Path, dep.Version)
+	if ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "fetch %s@%s: %v", dep.Path, dep.Version, err)
+	}
+	if fresh {
+		if err := checkFetched(recorded, dep.Path, dep.Version, cachePath, sumPath, false); err != nil {
+			return nil, err
+		}
+	}
+
+	replaceDir := localPath(dir, local)
+	files, err := diffTrees(cachePath, replaceDir, !req.Stat)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "diff %s with %s: %v", cachePath, replaceDir, err)
+	}
+	return &pb.ReplaceDiffResponse{
+		Root:       dir,
+		Path:       dep.Path,
+		Version:    dep.Version,
+		CachePath:  cachePath,
+		ReplaceDir: replaceDir,
+		Files:      files,
+	}, nil
+}
+
+// diffTrees returns the files differing between the trees a and b, by
+// path, with their unified diffs if withDiffs. Only regular files are
+// compared, and .git directories are skipped.
+func diffTrees(a, b string, withDiffs bool) ([]*pb.FileDiff, error) {
+	aFiles, err := treeFiles(a)
+	if err != nil {
+		return nil, err
+	}
+	bFiles, err := treeFiles(b)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for p := range aFiles {
+		paths = append(paths, p)
+	}
+	for p := range bFiles {
+		if !aFiles[p] {
+			paths = append(paths, p)
+		}
+	}
+	slices.Sort(paths)
+
+	var diffs []*pb.FileDiff
+	for _, p := range paths {
+		var before, after []byte
+		change := pb.FileChange_FILE_CHANGE_MODIFIED
+		switch {
+		case !bFiles[p]:
+			change = pb.FileChange_FILE_CHANGE_DELETED
+		case !aFiles[p]:
+			change = pb.FileChange_FILE_CHANGE_ADDED
+		}
+		if aFiles[p] {
+			if before, err = os.ReadFile(filepath.Join(a, filepath.FromSlash(p))); err != nil {
+				return nil, err
+			}
+		}
+		if bFiles[p] {
+			if after, err = os.ReadFile(filepath.Join(b, filepath.FromSlash(p))); err != nil {
+				return nil, err
+			}
+		}
+		if change == pb.FileChange_FILE_CHANGE_MODIFIED && bytes.Equal(before, after) {
+			continue
+		}
+		d := &pb.FileDiff{Path: p, Change: change}
+		if isBinary(before) || isBinary(after) {
+			d.Binary = true
+		} else {
+			var diff string
+			diff, d.Added, d.Deleted = unifiedDiff(p, change, string(before), string(after))
+			if withDiffs {
+				d.Diff = diff
+			}
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+// treeFiles returns the slash-separated paths of the regular files under
+// dir, outside .git directories.
+func treeFiles(dir string) (map[string]bool, error) {
+	files := map[string]bool{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if d.Type().IsRegular() {
+			rel, _ := filepath.Rel(dir, path)
+			files[filepath.ToSlash(rel)] = true
+		}
+		return nil
+	})
+	return files, err
+}
+
+// isBinary reports whether data is not text: it holds a NUL byte in its
+// first 8 KiB, as git decides, or is not UTF-8.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8<<10)], 0) >= 0 || !utf8.Valid(data)
+}
+
+// lineEdit is one line of an edit script: kept (' '), deleted ('-') or
+// added ('+').
+type lineEdit struct {
+	op   byte
+	line string
+}
+
+// unifiedDiff returns the unified diff of the file at path turning before
+// into after, and the lines it adds and deletes.
+func unifiedDiff(path string, change pb.FileChange, before, after string) (string, int32, int32) {
+	edits := lineEdits(splitLines(before), splitLines(after))
+
+	var b strings.Builder
+	from, to := "a/"+path, "b/"+path
+	switch change {
+	case pb.FileChange_FILE_CHANGE_ADDED:
+		from = "/dev/null"
+	case pb.FileChange_FILE_CHANGE_DELETED:
+		to = "/dev/null"
+	}
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", from, to)
+
+	// aAt and bAt count the lines of before and after preceding each edit.
+	aAt, bAt := make([]int, len(edits)+1), make([]int, len(edits)+1)
+	var added, deleted int32
+	for i, e := range edits {
+		aAt[i+1], bAt[i+1] = aAt[i], bAt[i]
+		if e.op != '+' {
+			aAt[i+1]++
+		}
+		if e.op != '-' {
+			bAt[i+1]++
+		}
+		switch e.op {
+		case '+':
+			added++
+		case '-':
+			deleted++
+		}
+	}
+
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+		// A hunk runs until the changes are more than twice the context
+		// apart.
+		end := i + 1
+		for j := end; j < len(edits); j++ {
+			if edits[j].op != ' ' {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		start, stop := max(0, i-diffContext), min(len(edits), end+diffContext)
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(aAt[start], aAt[stop]-aAt[start]), hunkRange(bAt[start], bAt[stop]-bAt[start]))
+		for _, e := range edits[start:stop] {
+			b.WriteByte(e.op)
+			b.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = stop
+	}
+	return b.String(), added, deleted
+}
+
+// hunkRange formats the range of n lines after the first skipped ones of
+// a hunk header.
+func hunkRange(skipped, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", skipped)
+	}
+	if n == 1 {
+		return fmt.Sprint(skipped + 1)
+	}
+	return fmt.Sprintf("%d,%d", skipped+1, n)
+}
+
+// splitLines splits s after each newline; the last line may lack one.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineEdits returns the shortest edit script turning a into b, with
+// Myers' algorithm.
+func lineEdits(a, b []string) []lineEdit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	// trace holds v as each round d starts, to walk the path back.
+	var trace [][]int
+	done := false
+	for d := 0; d <= n+m && !done; d++ {
+		trace = append(trace, slices.Clone(v))
+		for k := -d; k <= d; k += 2 {
+			x := v[offset+k-1] + 1
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1]
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+	}
+
+	var edits []lineEdit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		prev := k - 1
+		if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+			prev = k + 1
+		}
+		px := v[offset+prev]
+		py := px - prev
+		for x > px && y > py {
+			edits = append(edits, lineEdit{' ', a[x-1]})
+			x, y = x-1, y-1
+		}
+		if x == px {
+			edits = append(edits, lineEdit{'+', b[y-1]})
+			y--
+		} else {
+			edits = append(edits, lineEdit{'-', a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		edits = append(edits, lineEdit{' ', a[x-1]})
+		x, y = x-1, y-1
+	}
+	slices.Reverse(edits)
+	return edits
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/client"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReplacePromote moves a dependency patched in a local directory to the
+// fork the patches were published to, req.To at req.Version: the local
+// replace is removed and the require rewritten to the fork, keeping its
+// alias, group and annotations, or, with req.KeepPath, kept at its path
+// at req.Version and replaced with the fork remotely. The fork is
+// fetched and hashed into holon.sum as Add does it, all or nothing, and
+// compared with the local directory, if still there, so that patches it
+// lacks show.
+func (s *Server) ReplacePromote(ctx context.Context, req *pb.ReplacePromoteRequest) (_ *pb.ReplacePromoteResponse, err error) {
+	defer s.record("ReplacePromote", req.Directory, &err)
+
+	if req.To == "" || req.Version == "" {
+		return nil, status.Error(codes.InvalidArgument, "the fork's path and version are required")
+	}
+	dir := holonDir(req.Directory)
+	defer s.journal(dir, "ReplacePromote", &err)()
+
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	dep, ok := mod.RequireByName(req.Path)
+	if !ok {
+		return nil, unknownDependencyError(modPath, req.Path)
+	}
+	r, local, ok := mod.Replacement(dep.Path)
+	switch {
+	case !ok || !r.Local():
+		return nil, status.Errorf(codes.FailedPrecondition, "%s is not replaced by a local directory", dep.Path)
+	case r.Old != dep.Path:
+		return nil, status.Errorf(codes.FailedPrecondition, "%s is replaced by the wildcard replace of %s, which other dependencies may share", dep.Path, r.Old)
+	case !req.KeepPath && req.To != dep.Path && slices.ContainsFunc(mod.Require, func(o modfile.Require) bool { return o.Path == req.To }):
+		return nil, status.Errorf(codes.FailedPrecondition, "%s is already required", req.To)
+	}
+	if mod.Stable && semver.Prerelease(req.Version) != "" {
+		return nil, prereleaseError(modPath, req.To+"@"+req.Version)
+	}
+
+	promoted := mod.Clone()
+	promoted.Replace = slices.DeleteFunc(promoted.Replace, func(o modfile.Replace) bool { return o.Old == r.Old })
+	resp := &pb.ReplacePromoteResponse{Root: dir, ReplaceDir: localPath(dir, local)}
+	i := slices.IndexFunc(promoted.Require, func(o modfile.Require) bool { return o.Path == dep.Path })
+	promoted.Require[i].Version = req.Version
+	if req.KeepPath {
+		promoted.Replace = append(promoted.Replace, modfile.Replace{Old: dep.Path, New: req.To})
+		resp.Replace = dep.Path + " => " + req.To
+	} else {
+		promoted.Require[i].Path = req.To
+	}
+	required := promoted.Require[i]
+	resp.Dependency = &pb.Dependency{Path: required.Path, Version: required.Version, Alias: required.Alias, Group: required.Group}
+
+	sumPath := filepath.Join(dir, "holon.sum")
+	if req.DryRun {
+		resp.Plan = &pb.Plan{Changes: diffMods(mod, promoted), Write: []string{modPath, sumPath}}
+		if !inCache(req.To, req.Version) {
+			resp.Plan.Fetch = append(resp.Plan.Fetch, &pb.Dependency{Path: req.To, Version: req.Version})
+		}
+		return resp, nil
+	}
+
+	// Fetch and hash before touching any file. Whatever fails from here
+	// on restores holon.mod and holon.sum, and evicts a fresh snapshot.
+	var txn fileTxn
+	var fetched bool
+	defer func() {
+		if err != nil {
+			txn.rollback(s)
+			if fetched {
+				removeFromCache(req.To, req.Version)
+			}
+		}
+	}()
+
+	ctx = withFetchSources(withFetchQueue(s.withEvents(ctx), "ReplacePromote"), promoted)
+	fork := req.To + "@" + req.Version
+	fetched = !inCache(req.To, req.Version)
+	cachePath, err := fetchToCache(ctx, req.To, req.Version)
+	if err != nil {
+		return nil, detailed(codes.Unavailable, client.ReasonFetchFailed,
+			map[string]string{"dependency": fork}, nil, "fetch %s: %v", fork, err)
+	}
+	if mod.Provenance && readProvenance(req.To, req.Version) == nil {
+		return nil, provenanceError(modPath, req.To, req.Version)
+	}
+	var trust *pb.TrustRecord
+	if mod.TrustOnFirstUse {
+		if trust, err = checkTrust(modPath, req.To, req.Version, cachePath, req.Trust, req.Force, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	// A local directory already deleted has nothing left to compare.
+	if _, statErr := os.Stat(resp.ReplaceDir); statErr == nil {
+		if resp.Differences, err = diffTrees(cachePath, resp.ReplaceDir, false); err != nil {
+			return nil, status.Errorf(codes.Internal, "diff %s with %s: %v", cachePath, resp.ReplaceDir, err)
+		}
+	}
+
+	var mismatchErr, hashErr error
+	err = s.updateSum(sumPath, func(sum *modfile.SumFile) bool {
+		if want := cacheMismatch(sum, req.To, req.Version, cachePath); want != "" && !req.Force {
+			got, _ := sumHashDir(hashAlgorithm(want), cachePath)
+			mismatchErr = hashMismatchError(fork, sumPath,
+				"snapshot does not match holon.sum (want %s, got %s); use --force to accept it", want, got)
+			return false
+		}
+		before := sum.Clone()
+		if hashErr = setSnapshotHashes(sum, sumAlgorithms(sum), req.To, req.Version, cachePath); hashErr != nil {
+			return false
+		}
+		txn.saveSum(sumPath, before, sum)
+		return true
+	})
+	switch {
+	case mismatchErr != nil:
+		return nil, mismatchErr
+	case hashErr != nil:
+		return nil, status.Errorf(codes.Internal, "%v", hashErr)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "update holon.sum: %v", err)
+	}
+
+	if err := txn.save(modPath); err != nil {
+		return nil, status.Errorf(codes.Internal, "read holon.mod: %v", err)
+	}
+	if err := s.writeMod(promoted, modPath); err != nil {
+		return nil, status.Errorf(codes.Internal, "write holon.mod: %v", err)
+	}
+	if trust != nil {
+		if err := trustHolon(req.To, req.Version, trust); err != nil {
+			return nil, status.Errorf(codes.Internal, "write %s: %v", knownHolonsPath(), err)
+		}
+	}
+
+	resp.Dependency.CachePath = cachePath
+	resp.Dependency.Source = fetchSource(req.To, req.Version)
+	return resp, nil
+}
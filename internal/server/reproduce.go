@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Reproduce fetches every dependency required by the holon in
+// req.Directory from upstream into a scratch directory, and reports for
+// each whether the fresh copy hashes to what holon.sum records and to the
+// cached snapshot. Neither the cache nor holon.sum is modified.
+func (s *Server) Reproduce(ctx context.Context, req *pb.ReproduceRequest) (_ *pb.ReproduceResponse, err error) {
+	defer s.record("Reproduce", req.Directory, &err)
+
+	dir := holonDir(req.Directory)
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	ctx = withFetchSources(ctx, mod)
+	sumPath := filepath.Join(dir, "holon.sum")
+	sum, err := s.parseSum(sumPath)
+	if err != nil {
+		return nil, sumError(sumPath, err)
+	}
+
+	scratch, err := os.MkdirTemp("", "atlas-reproduce-")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "create scratch dir: %v", err)
+	}
+	defer os.RemoveAll(scratch) //nolint:errcheck
+
+	resp := &pb.ReproduceResponse{Reproducible: true, Time: time.Now().UTC().Format(time.RFC3339)}
+	for i, dep := range mod.Require {
+		// Skip replaced dependencies
+		if mod.ResolvedPath(dep.Path) != "" {
+			continue
+		}
+		r := reproduce(ctx, sum, mod.SourcePath(dep.Path), dep.Version, filepath.Join(scratch, fmt.Sprint(i)))
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		resp.Reproducible = resp.Reproducible && r.Reproduced
+		resp.Results = append(resp.Results, r)
+	}
+	return resp, nil
+}
+
+// reproduce fetches path@version from upstream into dst and compares its
+// hash with sum and the cache.
+func reproduce(ctx context.Context, sum *modfile.SumFile, path, version, dst string) *pb.Reproduction {
+	r := &pb.Reproduction{Path: path, Version: version}
+
+	// Hash with the first algorithm holon.sum records the entry with.
+	algs := sumAlgorithms(sum)
+	alg := algs[0]
+	for _, a := range algs {
+		if h := sum.LookupAlgorithm(path, version, a); h != "" {
+			alg, r.SumHash = a, h
+			break
+		}
+	}
+	if r.SumHash == "" {
+		r.Problems = append(r.Problems, "not in holon.sum")
+	}
+
+	if cachePath, err := cacheStore().Get(path, version); err == nil {
+		r.CacheHash, _ = sumHashDir(alg, cachePath)
+	}
+
+	src, err := fetchUpstream(ctx, path, version, dst)
+	if err != nil {
+		r.Problems = append(r.Problems, fmt.Sprintf("fetch from upstream: %v", err))
+		return r
+	}
+	r.Source = src.String()
+	if r.UpstreamHash, err = sumHashDir(alg, dst); err != nil {
+		r.Problems = append(r.Problems, fmt.Sprintf("hash upstream copy: %v", err))
+		return r
+	}
+
+	if r.SumHash != "" && r.UpstreamHash != r.SumHash {
+		r.Problems = append(r.Problems, "upstream content differs from holon.sum")
+	}
+	if r.CacheHash != "" && r.UpstreamHash != r.CacheHash {
+		r.Problems = append(r.Problems, "upstream content differs from the cache")
+	}
+	r.Reproduced = len(r.Problems) == 0
+	return r
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// resolveRounds bounds the rounds of resolution, each selecting versions
+// from the requirements of the versions the previous one selected.
+const resolveRounds = 32
+
+// selKey identifies a selection of the graph: a dependency required at
+// several major versions has one per major, the versions coexisting.
+type selKey struct {
+	path  string
+	major int
+}
+
+// majorKey returns the key version of path is selected under; versions
+// that are not semver, such as digests, share major -1.
+func majorKey(path, version string) selKey {
+	major, _, _, ok := semver.Parse(version)
+	if !ok {
+		major = -1
+	}
+	return selKey{path, major}
+}
+
+// resolveGraph selects a version of every dependency reachable from the
+// requirements of mod, with strategy where requirements of the same major
+// version disagree; requirements of different majors each get theirs, so
+// both coexist. The requirements of a dependency are those of the
+// holon.mod of its selected version, fetched to the cache from the path
+// it is replaced by, if any. Dependencies replaced by local directories
+// are left out, and optional ones unless with asks for them (see
+// modfile.Require.Wanted).
+func resolveGraph(ctx context.Context, mod *modfile.ModFile, strategy string, with []string) (*modfile.LockFile, error) {
+	want := func(r modfile.Require) bool { return r.Wanted(with) }
+	lock, err := selectGraph(ctx, mod, strategy, want, func(sel map[selKey]string) (map[selKey]*modfile.ModFile, error) {
+		var deps []*pb.Dependency
+		for key, version := range sel {
+			deps = append(deps, &pb.Dependency{Path: mod.SourcePath(key.path), Version: version})
+		}
+		fetched := fetchAll(ctx, deps)
+		mods := map[selKey]*modfile.ModFile{}
+		for key, version := range sel {
+			src := mod.SourcePath(key.path)
+			r := fetched[src+"@"+version]
+			if r.err != nil {
+				return nil, fmt.Errorf("fetch %s@%s: %w", src, version, r.err)
+			}
+			sub, err := modfile.Parse(filepath.Join(r.dir, "holon.mod"))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("parse holon.mod of %s@%s: %w", key.path, version, err)
+			}
+			mods[key] = sub
+		}
+		return mods, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	lock.With = modfile.SortedWith(with)
+	return lock, nil
+}
+
+// matchingLock returns the holon.lock of the holon in dir if it matches
+// mod, its holon.mod, resolved with strategy and with; otherwise nil.
+func matchingLock(dir string, mod *modfile.ModFile, strategy string, with []string) (*modfile.LockFile, error) {
+	lock, err := modfile.ParseLock(filepath.Join(dir, "holon.lock"))
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "parse holon.lock: %v", err)
+	}
+	if !lock.Matches(mod, strategy, with) {
+		return nil, nil
+	}
+	return lock, nil
+}
+
+// lockedGraph returns the selections of lock, matching the holon.mod that
+// mod is a group of, which the requirements of mod need: those it
+// requires, then those they require, transitively.
+func lockedGraph(lock *modfile.LockFile, mod *modfile.ModFile) *modfile.LockFile {
+	need := map[string]bool{}
+	for _, r := range mod.Require {
+		need[r.Path] = true
+	}
+	for grown := true; grown; {
+		grown = false
+		for _, sel := range lock.Selected {
+			if need[sel.Path] {
+				continue
+			}
+			for _, r := range sel.RequiredBy {
+				if r.Holon != mod.HolonPath && need[r.Holon] {
+					need[sel.Path], grown = true, true
+					break
+				}
+			}
+		}
+	}
+	locked := &modfile.LockFile{Strategy: lock.Strategy, With: lock.With}
+	for _, sel := range lock.Selected {
+		if need[sel.Path] {
+			locked.Selected = append(locked.Selected, sel)
+		}
+	}
+	return locked
+}
+
+// buildList selects, with minimal version selection and without fetching
+// anything, a version of every dependency reachable from the requirements
+// of mod, the holon.mod of the holon in dir, that want keeps. The
+// requirements of a dependency are read from the holon.mod of its
+// selected version where it is found, in the cache or vendored; one found
+// nowhere adds none. Dependencies replaced by local directories are left
+// out, as resolveGraph does.
+func (s *Server) buildList(dir string, mod *modfile.ModFile, want func(modfile.Require) bool) *modfile.LockFile {
+	lock, _ := selectGraph(context.Background(), mod, modfile.StrategyMVS, want, func(sel map[selKey]string) (map[selKey]*modfile.ModFile, error) {
+		mods := map[selKey]*modfile.ModFile{}
+		for key, version := range sel {
+			if sub, err := s.parseMod(filepath.Join(dependencyDir(dir, mod, key.path, version), "holon.mod")); err == nil {
+				mods[key] = sub
+			}
+		}
+		return mods, nil
+	})
+	return lock
+}
+
+// selectGraph is the resolution of resolveGraph and buildList: starting
+// from the requirements of mod that want keeps, each round selects a
+// version per selKey, and load returns the holon.mod of the versions
+// selected, whose requirements the next round adds, until the selection
+// settles.
+func selectGraph(ctx context.Context, mod *modfile.ModFile, strategy string, want func(modfile.Require) bool, load func(map[selKey]string) (map[selKey]*modfile.ModFile, error)) (*modfile.LockFile, error) {
+	tags := map[string][]string{} // listed once per resolution
+	sel := map[selKey]string{}
+	var reqs map[selKey][]modfile.Requirement
+	for round := 0; ; round++ {
+		reqs = map[selKey][]modfile.Requirement{}
+		add := func(holon string, rs []modfile.Require) {
+			for _, r := range rs {
+				if r.Path != mod.HolonPath && mod.ResolvedPath(r.Path) == "" && want(r) {
+					key := majorKey(r.Path, r.Version)
+					reqs[key] = append(reqs[key], modfile.Requirement{Holon: holon, Version: r.Version})
+				}
+			}
+		}
+		add(mod.HolonPath, mod.Require)
+		mods, err := load(sel)
+		if err != nil {
+			return nil, err
+		}
+		for key, sub := range mods {
+			add(key.path, sub.Require)
+		}
+
+		next := map[selKey]string{}
+		for key, rs := range reqs {
+			v, err := selectVersion(ctx, mod.SourcePath(key.path), rs, strategy, tags)
+			if err != nil {
+				return nil, err
+			}
+			next[key] = v
+		}
+		if maps.Equal(next, sel) {
+			break
+		}
+		if round == resolveRounds {
+			return nil, fmt.Errorf("selection does not settle after %d rounds", resolveRounds)
+		}
+		sel = next
+	}
+
+	lock := &modfile.LockFile{Strategy: strategy}
+	for key, version := range sel {
+		rs := reqs[key]
+		sort.Slice(rs, func(i, j int) bool { return rs[i].Holon < rs[j].Holon })
+		lock.Selected = append(lock.Selected, modfile.Selection{Path: key.path, Version: version, RequiredBy: rs})
+	}
+	sort.Slice(lock.Selected, func(i, j int) bool {
+		a, b := lock.Selected[i], lock.Selected[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return semver.Compare(a.Version, b.Version) < 0
+	})
+	return lock, nil
+}
+
+// selectVersion returns the version of path that strategy selects from
+// its requirements rs. tags caches the tags listed for StrategyHighest.
+func selectVersion(ctx context.Context, path string, rs []modfile.Requirement, strategy string, tags map[string][]string) (string, error) {
+	highest := rs[0].Version
+	for _, r := range rs[1:] {
+		if semver.Compare(r.Version, highest) > 0 {
+			highest = r.Version
+		}
+	}
+	major, _, _, ok := semver.Parse(highest)
+	if strategy != modfile.StrategyHighest || !ok {
+		return highest, nil
+	}
+
+	listed, ok := tags[path]
+	if !ok {
+		var err error
+		if listed, err = remoteTags(ctx, path); err != nil {
+			return "", fmt.Errorf("resolve %s: %w", path, err)
+		}
+		tags[path] = listed
+	}
+	for _, tag := range listed {
+		m, _, _, ok := semver.Parse(tag)
+		if ok && m == major && semver.Prerelease(tag) == "" && semver.Compare(tag, highest) > 0 {
+			highest = tag
+		}
+	}
+	return highest, nil
+}
+
+// resolutions returns the selections of lock for a response.
+func resolutions(lock *modfile.LockFile) []*pb.Resolution {
+	var res []*pb.Resolution
+	for _, s := range lock.Selected {
+		r := &pb.Resolution{Path: s.Path, Version: s.Version}
+		for _, req := range s.RequiredBy {
+			r.RequiredBy = append(r.RequiredBy, &pb.Requirement{Holon: req.Holon, Version: req.Version})
+		}
+		res = append(res, r)
+	}
+	return res
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// credentialHostsEnv lists, comma-separated, the hosts whose fetches run
+// with the environment of the user: their git configuration, credential
+// helpers, SSH keys and agent. Fetches from other hosts see none of them.
+const credentialHostsEnv = "ATLAS_FETCH_CREDENTIALS"
+
+// netnsEnv, set to 1, runs the git commands that need no network, those
+// on local repositories and fetches from file:// URLs, in a network
+// namespace of their own. Only available on Linux.
+const netnsEnv = "ATLAS_FETCH_NETNS"
+
+// sandboxEnv lists the variables sandboxed git commands inherit: enough to
+// find git, reach proxies and check certificates.
+var sandboxEnv = []string{
+	"PATH", "SYSTEMROOT", "TMPDIR", "TEMP", "TMP", "LANG", "LC_ALL", "TZ",
+	"http_proxy", "https_proxy", "no_proxy", "all_proxy",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "ALL_PROXY",
+	"SSL_CERT_FILE", "SSL_CERT_DIR", "GIT_SSL_CAINFO", "GIT_SSL_CAPATH", "GIT_EXEC_PATH",
+}
+
+// gitCommand returns the git command running args against gitURL, the
+// repository fetched from, or "" for a command using no remote, and the
+// function to call once it has run. Unless the host of gitURL is listed
+// in ATLAS_FETCH_CREDENTIALS, the command is sandboxed so that a
+// malicious dependency URL cannot get at local credentials: it only
+// inherits the variables of sandboxEnv, runs with a scratch HOME, ignores
+// the system and global git configuration, never prompts, and offers no
+// SSH key or agent. Only the credentials ATLAS_GIT_AUTH gives for gitURL
+// get through: its SSH keys and agent, or an Authorization header. A host
+// without git fails up front, naming what needs it.
+func gitCommand(ctx context.Context, gitURL string, args ...string) (*exec.Cmd, func(), error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, nil, fmt.Errorf("git is not installed: submodules, mirroring, releasing and signing TUF metadata need it (%w)", err)
+	}
+	auth, authed, err := gitAuthForURL(gitURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	done := func() {}
+	if gitURL == "" || !credentialHost(gitURL) {
+		home, err := os.MkdirTemp("", "atlas-home-")
+		if err != nil {
+			return nil, nil, err
+		}
+		done = func() { os.RemoveAll(home) } //nolint:errcheck
+		for _, name := range sandboxEnv {
+			if v, ok := os.LookupEnv(name); ok {
+				cmd.Env = append(cmd.Env, name+"="+v)
+			}
+		}
+		sshCommand := "ssh -F " + os.DevNull + " -o BatchMode=yes -o IdentityAgent=none -o IdentitiesOnly=yes" +
+			" -o IdentityFile=" + os.DevNull + " -o UserKnownHostsFile=" + filepath.Join(home, "known_hosts") +
+			" -o StrictHostKeyChecking=accept-new"
+		if authed && auth.method == "ssh" {
+			// SSH finds the keys, configuration and known hosts of the
+			// user in their home directory, whatever HOME says.
+			sshCommand = "ssh -o BatchMode=yes"
+			if sock, ok := os.LookupEnv("SSH_AUTH_SOCK"); ok {
+				cmd.Env = append(cmd.Env, "SSH_AUTH_SOCK="+sock)
+			}
+		}
+		cmd.Env = append(cmd.Env,
+			"HOME="+home,
+			"XDG_CONFIG_HOME="+home,
+			"GIT_CONFIG_NOSYSTEM=1",
+			"GIT_CONFIG_GLOBAL="+os.DevNull,
+			"GIT_TERMINAL_PROMPT=0",
+			"GIT_SSH_COMMAND="+sshCommand,
+		)
+	}
+	if authed {
+		if err := auth.apply(cmd, gitURL); err != nil {
+			done()
+			return nil, nil, err
+		}
+	}
+	if os.Getenv(netnsEnv) == "1" && urlHost(gitURL) == "" {
+		if err := isolateNetwork(cmd); err != nil {
+			done()
+			return nil, nil, fmt.Errorf("%s: %w", netnsEnv, err)
+		}
+	}
+	return cmd, done, nil
+}
+
+// credentialHost reports whether the host of gitURL is listed in
+// ATLAS_FETCH_CREDENTIALS.
+func credentialHost(gitURL string) bool {
+	host := urlHost(gitURL)
+	if host == "" {
+		return false
+	}
+	for _, h := range strings.Split(os.Getenv(credentialHostsEnv), ",") {
+		if strings.EqualFold(strings.TrimSpace(h), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// urlHost returns the host of a git URL, including scp-like ones
+// (user@host:path), or "" for local ones.
+func urlHost(gitURL string) string {
+	if u, err := url.Parse(gitURL); err == nil && u.Scheme != "" {
+		if u.Scheme == "file" {
+			return ""
+		}
+		return u.Hostname()
+	}
+	if at, _, ok := strings.Cut(gitURL, ":"); ok && !strings.Contains(at, "/") && len(at) > 1 {
+		if _, host, ok := strings.Cut(at, "@"); ok {
+			return host
+		}
+		return at
+	}
+	return ""
+}
+
+// runRemoteGit runs git, sandboxed for gitURL as by gitCommand, on the
+// repository gitDir, unless empty.
+func runRemoteGit(ctx context.Context, gitDir, gitURL string, args ...string) error {
+	cmd, done, err := gitCommand(ctx, gitURL, withGitDir(gitDir, args)...)
+	if err != nil {
+		return err
+	}
+	defer done()
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// lsRemote lists the tags of the repository at gitURL, as printed by git
+// ls-remote.
+func lsRemote(ctx context.Context, gitURL string) ([]byte, error) {
+	cmd, done, err := gitCommand(ctx, gitURL, "ls-remote", "--tags", "--refs", gitURL)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return cmd.Output()
+}
+
+func withGitDir(gitDir string, args []string) []string {
+	if gitDir == "" {
+		return args
+	}
+	return append([]string{"--git-dir=" + gitDir}, args...)
+}
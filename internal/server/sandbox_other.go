@@ -0,0 +1,14 @@
+//go:build !linux
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// isolateNetwork is only implemented on Linux.
+func isolateNetwork(*exec.Cmd) error {
+	return fmt.Errorf("network namespaces are only available on Linux: %w", errors.ErrUnsupported)
+}
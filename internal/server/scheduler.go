@@ -0,0 +1,217 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+)
+
+// fetchConcurrencyEnv names the environment variable bounding the fetches
+// the process runs at once, across every RPC; unset means
+// defaultFetchConcurrency.
+const fetchConcurrencyEnv = "ATLAS_FETCH_CONCURRENCY"
+
+const defaultFetchConcurrency = 8
+
+// fetchConcurrency returns the bound fetchConcurrencyEnv sets.
+func fetchConcurrency() (int, error) {
+	env := strings.TrimSpace(os.Getenv(fetchConcurrencyEnv))
+	if env == "" {
+		return defaultFetchConcurrency, nil
+	}
+	n, err := strconv.Atoi(env)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s: invalid value %q (want a positive number of fetches)", fetchConcurrencyEnv, env)
+	}
+	return n, nil
+}
+
+// fetches schedules the fetches of the process, whichever Server or RPC
+// asks for them, as the rate limit is shared by all of them.
+var fetches = &fetchScheduler{}
+
+// fetchQueue groups the fetches of one RPC call. The scheduler starts the
+// fetches of the queues waiting in turn, so a Pull of many dependencies
+// does not hold up an Add behind all of them.
+type fetchQueue struct{ name string }
+
+type fetchQueueKey struct{}
+
+// withFetchQueue returns ctx with a queue of its own for the fetches made
+// with it, named after the RPC for messages. Fetches made with a context
+// carrying none share a default queue.
+func withFetchQueue(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, fetchQueueKey{}, &fetchQueue{name: name})
+}
+
+var defaultFetchQueue = &fetchQueue{name: "default"}
+
+func fetchQueueOf(ctx context.Context) *fetchQueue {
+	if q, ok := ctx.Value(fetchQueueKey{}).(*fetchQueue); ok {
+		return q
+	}
+	return defaultFetchQueue
+}
+
+// fetchCall is one fetch of a path@version, shared by every caller asking
+// for it while it is queued or running.
+type fetchCall struct {
+	start chan struct{} // closed when the call may run
+	done  chan struct{} // closed once dir and err are set
+	dir   string
+	err   error
+	// waiters counts the callers still waiting for the call; when the
+	// last gives up, the fetch is canceled.
+	waiters int
+	cancel  context.CancelFunc
+	// reporters are the progress functions of the callers (see
+	// withProgress), which all get the progress of the fetch.
+	reporters []func(*pb.PullProgress)
+}
+
+// fetchScheduler deduplicates concurrent fetches of the same path@version
+// and runs at most fetchConcurrency of them at once, starting the queued
+// ones round-robin across fetch queues.
+type fetchScheduler struct {
+	mu       sync.Mutex
+	inflight map[string]*fetchCall
+	queued   map[*fetchQueue][]*fetchCall
+	turns    []*fetchQueue // queues with calls waiting, in turn order
+	running  int
+}
+
+// do runs fetch for key, or joins the call already queued or running for
+// it, and returns its result. fetch runs with the values of the first
+// caller's ctx, and is only canceled once every caller's ctx is done; its
+// progress is reported to that of every caller's ctx.
+func (s *fetchScheduler) do(ctx context.Context, key string, fetch func(context.Context) (string, error)) (string, error) {
+	limit, err := fetchConcurrency()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	c, ok := s.inflight[key]
+	if !ok {
+		if s.inflight == nil {
+			s.inflight = map[string]*fetchCall{}
+			s.queued = map[*fetchQueue][]*fetchCall{}
+		}
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &fetchCall{start: make(chan struct{}), done: make(chan struct{}), cancel: cancel}
+		fctx = withProgress(fctx, func(p *pb.PullProgress) { s.report(c, p) })
+		s.inflight[key] = c
+		q := fetchQueueOf(ctx)
+		if len(s.queued[q]) == 0 {
+			s.turns = append(s.turns, q)
+		}
+		s.queued[q] = append(s.queued[q], c)
+		go s.run(fctx, key, c, fetch)
+		s.dispatch(limit)
+	}
+	c.waiters++
+	if report := progressOf(ctx); report != nil {
+		c.reporters = append(c.reporters, report)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.dir, c.err
+	case <-ctx.Done():
+		s.mu.Lock()
+		if c.waiters--; c.waiters == 0 {
+			// Later callers start over rather than join a canceled call.
+			c.cancel()
+			if s.inflight[key] == c {
+				delete(s.inflight, key)
+			}
+		}
+		s.mu.Unlock()
+		return "", ctx.Err()
+	}
+}
+
+// run waits for c to be started, fetches and releases its slot.
+func (s *fetchScheduler) run(ctx context.Context, key string, c *fetchCall, fetch func(context.Context) (string, error)) {
+	<-c.start
+	if err := ctx.Err(); err != nil {
+		c.err = err
+	} else {
+		c.dir, c.err = fetch(ctx)
+	}
+	c.cancel()
+
+	s.mu.Lock()
+	if s.inflight[key] == c {
+		delete(s.inflight, key)
+	}
+	s.running--
+	limit, err := fetchConcurrency()
+	if err != nil {
+		limit = defaultFetchConcurrency
+	}
+	s.dispatch(limit)
+	s.mu.Unlock()
+	close(c.done)
+}
+
+// report hands the progress p of c to the progress functions of its
+// callers.
+func (s *fetchScheduler) report(c *fetchCall, p *pb.PullProgress) {
+	s.mu.Lock()
+	reporters := slices.Clone(c.reporters)
+	s.mu.Unlock()
+	for _, report := range reporters {
+		report(p)
+	}
+}
+
+// dispatch starts queued calls while fewer than limit run, taking the
+// first call of each queue in turn. s.mu must be held.
+func (s *fetchScheduler) dispatch(limit int) {
+	for s.running < limit && len(s.turns) > 0 {
+		q := s.turns[0]
+		s.turns = s.turns[1:]
+		c := s.queued[q][0]
+		if s.queued[q] = s.queued[q][1:]; len(s.queued[q]) > 0 {
+			s.turns = append(s.turns, q)
+		} else {
+			delete(s.queued, q)
+		}
+		s.running++
+		close(c.start)
+	}
+}
+
+// fetchResult is the outcome of one fetch of fetchAll.
+type fetchResult struct {
+	dir string
+	err error
+}
+
+// fetchAll fetches deps to the cache concurrently, through the scheduler,
+// and returns the outcome of each, by "<path>@<version>".
+func fetchAll(ctx context.Context, deps []*pb.Dependency) map[string]fetchResult {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]fetchResult, len(deps))
+	for _, d := range deps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dir, err := fetchToCache(ctx, d.Path, d.Version)
+			mu.Lock()
+			results[d.Path+"@"+d.Version] = fetchResult{dir, err}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// vendoredEntry is a vendored copy of the dependency fetched as path at
+// version.
+type vendoredEntry struct {
+	path, version, dir string
+}
+
+// CacheSeed installs in the cache the vendored copies of the holon in
+// req.Directory that it lacks, so that a machine with a checked-in vendor
+// directory needs no network access for the workflows reading the cache.
+// Every copy must hash to what holon.sum records for it, and so must the
+// snapshots already cached; nothing is installed otherwise. A copy
+// vendored without the paths .holonvendorignore lists cannot match.
+func (s *Server) CacheSeed(_ context.Context, req *pb.CacheSeedRequest) (_ *pb.CacheSeedResponse, err error) {
+	defer s.record("CacheSeed", req.Directory, &err)
+
+	dir := holonDir(req.Directory)
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	sumPath := filepath.Join(dir, "holon.sum")
+	sum, err := s.parseSum(sumPath)
+	if err != nil {
+		return nil, sumError(sumPath, err)
+	}
+	vendored, err := vendoredCopies(dir, mod)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "read vendor directory: %v", err)
+	}
+	if len(vendored) == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s has nothing vendored — run 'atlas vendor' first",
+			filepath.Join(dir, cmp.Or(mod.VendorDir, modfile.DefaultVendorDir)))
+	}
+
+	// Verify everything before touching the cache.
+	resp := &pb.CacheSeedResponse{Root: dir}
+	var missing []vendoredEntry
+	for _, v := range vendored {
+		dep := v.path + "@" + v.version
+		recorded := sum.Lookup(v.path, v.version)
+		if recorded == "" {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s: holon.sum records no hash to check %s against", dep, v.dir)
+		}
+		if cached, err := cacheStore().Get(v.path, v.version); err == nil {
+			if !hashMatches(recorded, cached) {
+				return nil, hashMismatchError(dep, sumPath, "cached copy %s does not match holon.sum", cached)
+			}
+			resp.Present = append(resp.Present, &pb.Dependency{Path: v.path, Version: v.version, CachePath: cached, Source: fetchSource(v.path, v.version)})
+			continue
+		}
+		if !hashMatches(recorded, v.dir) {
+			got, _ := sumHashDir(hashAlgorithm(recorded), v.dir)
+			return nil, hashMismatchError(dep, sumPath, "vendored copy %s does not match holon.sum (want %s, got %s)", v.dir, recorded, got)
+		}
+		missing = append(missing, v)
+	}
+
+	if req.DryRun {
+		resp.Plan = &pb.Plan{}
+		for _, v := range missing {
+			resp.Plan.Write = append(resp.Plan.Write, CachePath(v.path, v.version))
+			size, err := dirSize(v.dir)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "size %s: %v", v.dir, err)
+			}
+			resp.Plan.Bytes += size
+		}
+		return resp, nil
+	}
+
+	// Stage next to the cache so that copies can be renamed into it.
+	if err := os.MkdirAll(CacheDir(), 0o755); err != nil {
+		return nil, status.Errorf(codes.Internal, "create cache dir: %v", err)
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(CacheDir()), "seed-")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "create staging dir: %v", err)
+	}
+	defer os.RemoveAll(staging) //nolint:errcheck
+
+	for i, v := range missing {
+		staged := filepath.Join(staging, strconv.Itoa(i))
+		if err := copyDir(v.dir, staged, nil); err != nil {
+			return nil, status.Errorf(codes.Internal, "copy %s: %v", v.dir, err)
+		}
+		if err := cacheStore().Put(v.path, v.version, staged); err != nil {
+			return nil, status.Errorf(codes.Internal, "install %s@%s: %v", v.path, v.version, err)
+		}
+		source := v.dir
+		if abs, err := filepath.Abs(v.dir); err == nil {
+			source = abs
+		}
+		info := fetchInfo{Source: "file://" + filepath.ToSlash(source), Fetcher: "vendor", Time: time.Now().UTC()}
+		if err := writeFetchInfo(v.path, v.version, info); err != nil {
+			return nil, status.Errorf(codes.Internal, "record fetch info: %v", err)
+		}
+		cached, err := cacheStore().Get(v.path, v.version)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "install %s@%s: %v", v.path, v.version, err)
+		}
+		resp.Seeded = append(resp.Seeded, &pb.Dependency{Path: v.path, Version: v.version, CachePath: cached, Source: info.Source})
+	}
+	return resp, nil
+}
+
+// vendoredCopies returns the vendored copies of the holon in dir: those
+// the list Vendor writes names or, without one, those of its requires.
+func vendoredCopies(dir string, mod *modfile.ModFile) ([]vendoredEntry, error) {
+	vendorDir := filepath.Join(dir, cmp.Or(mod.VendorDir, modfile.DefaultVendorDir))
+	data, err := os.ReadFile(filepath.Join(vendorDir, vendoredListFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var copies []vendoredEntry
+	if err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if f := strings.Fields(line); len(f) == 3 && exists(filepath.Join(vendorDir, f[2])) {
+				copies = append(copies, vendoredEntry{f[0], f[1], filepath.Join(vendorDir, f[2])})
+			}
+		}
+		return copies, nil
+	}
+	seen := map[string]bool{}
+	for _, r := range mod.Require {
+		src := mod.SourcePath(r.Path)
+		if mod.ResolvedPath(r.Path) != "" || seen[src+"@"+r.Version] {
+			continue
+		}
+		seen[src+"@"+r.Version] = true
+		if copied := vendoredCopy(dir, mod, src, r.Version); copied != "" {
+			copies = append(copies, vendoredEntry{src, r.Version, copied})
+		}
+	}
+	return copies, nil
+}
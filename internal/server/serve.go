@@ -0,0 +1,161 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+
+	"github.com/organic-programming/go-holons/pkg/serve"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // accept gzip calls, and compress their responses
+	"google.golang.org/grpc/reflection"
+)
+
+// ServeOptions configures the grpc.Server that Serve creates. The zero
+// value serves the atlas service alone, without reflection.
+type ServeOptions struct {
+	// Reflection registers the gRPC reflection service.
+	Reflection bool
+
+	// TLS, when set, secures the connections.
+	TLS *tls.Config
+
+	// MaxRecvMsgSize and MaxSendMsgSize bound the messages received and
+	// sent, in bytes. Zero keeps the gRPC defaults: 4 MiB received,
+	// unbounded sent.
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+
+	// ReadOnly refuses, with PermissionDenied, the atlas RPCs writing
+	// holon files, the cache, a mirror or a registry, and those asking
+	// for a repair, for servers open to anyone to query. Dry runs are
+	// served, and so are reflection and the services of Register.
+	ReadOnly bool
+
+	// Auth, when set, authorizes each call per method, refusing the
+	// others with Unauthenticated or PermissionDenied.
+	Auth *AuthConfig
+
+	// UnaryInterceptors and StreamInterceptors wrap every call, the first
+	// outermost.
+	UnaryInterceptors  []grpc.UnaryServerInterceptor
+	StreamInterceptors []grpc.StreamServerInterceptor
+
+	// ServerOptions are passed to grpc.NewServer after those above.
+	ServerOptions []grpc.ServerOption
+
+	// Register is called to register other services next to atlas.
+	Register func(*grpc.Server)
+
+	// OnStart is called with the server once every service is
+	// registered, before it serves; it may keep it to stop it. OnStop is
+	// then called once it stopped serving, with the error Serve returns.
+	OnStart func(*grpc.Server)
+	OnStop  func(error)
+}
+
+// serverOptions returns the grpc.ServerOptions o describes.
+func (o ServeOptions) serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if o.TLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(o.TLS)))
+	}
+	if o.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(o.MaxRecvMsgSize))
+	}
+	if o.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(o.MaxSendMsgSize))
+	}
+	unary, stream := o.UnaryInterceptors, o.StreamInterceptors
+	if o.ReadOnly {
+		unary = append([]grpc.UnaryServerInterceptor{readOnlyUnary}, unary...)
+		stream = append([]grpc.StreamServerInterceptor{readOnlyStream}, stream...)
+	}
+	if o.Auth != nil {
+		unary = append([]grpc.UnaryServerInterceptor{o.Auth.unary}, unary...)
+		stream = append([]grpc.StreamServerInterceptor{o.Auth.stream}, stream...)
+	}
+	if len(unary) > 0 {
+		opts = append(opts, grpc.ChainUnaryInterceptor(unary...))
+	}
+	if len(stream) > 0 {
+		opts = append(opts, grpc.ChainStreamInterceptor(stream...))
+	}
+	return append(opts, o.ServerOptions...)
+}
+
+// ParseMessageSize parses a message size in bytes, with an optional k, M
+// or G suffix (powers of 1024), e.g. "64M".
+func ParseMessageSize(s string) (int, error) {
+	n, err := parseRate(s)
+	if err != nil || n > math.MaxInt32 {
+		return 0, fmt.Errorf("invalid message size %q (want bytes, e.g. 64M)", s)
+	}
+	return int(n), nil
+}
+
+// Serve serves s over gRPC on the given transport URI, on a server
+// configured by opts, until interrupted.
+func (s *Server) Serve(listenURI string, opts ServeOptions) error {
+	started := false
+	register := func(gs *grpc.Server) {
+		started = true
+		pb.RegisterRhizomeAtlasServiceServer(gs, s)
+		if opts.Register != nil {
+			opts.Register(gs)
+		}
+		if opts.OnStart != nil {
+			opts.OnStart(gs)
+		}
+	}
+
+	var err error
+	if gopts := opts.serverOptions(); len(gopts) > 0 || ownsTransport(listenURI) {
+		var lis net.Listener
+		if lis, err = listen(listenURI); err == nil {
+			err = serveOn(lis, register, opts.Reflection, gopts...)
+		}
+	} else {
+		err = serve.RunWithOptions(listenURI, register, opts.Reflection)
+	}
+	if started && opts.OnStop != nil {
+		opts.OnStop(err)
+	}
+	return err
+}
+
+// serveOn serves the services register registers on lis until
+// interrupted, then stops gracefully, which also removes a unix socket.
+func serveOn(lis net.Listener, register func(*grpc.Server), reflect bool, opts ...grpc.ServerOption) error {
+	gs := grpc.NewServer(opts...)
+	if reflect {
+		reflection.Register(gs)
+	}
+	register(gs)
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-sig:
+			gs.GracefulStop()
+		case <-done:
+		}
+	}()
+
+	if err := gs.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
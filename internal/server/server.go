@@ -2,24 +2,26 @@
 package server
 
 import (
+	"bytes"
+	"cmp"
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
 	"io"
 	"io/fs"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/organic-programming/go-holons/pkg/serve"
 	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/client"
+	"github.com/organic-programming/rhizome-atlas/pkg/holonmd"
 	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
 
-	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -30,529 +32,2105 @@ func CacheDir() string {
 	return filepath.Join(home, ".holon", "cache")
 }
 
+// CachePath returns the cache directory for a dependency.
+func CachePath(depPath, version string) string {
+	return filepath.Join(CacheDir(), depPath+"@"+version)
+}
+
 // Server implements the RhizomeAtlasService.
 type Server struct {
 	pb.UnimplementedRhizomeAtlasServiceServer
+
+	ops    opLog
+	idem   idemCache
+	lro    longOps
+	clog   checksumLog
+	locks  pathLocks
+	check  cacheCheck
+	events eventBus
+	mods   fileCache[*modfile.ModFile]
+	sums   fileCache[*modfile.SumFile]
 }
 
 // ListenAndServe starts the gRPC server on the given transport URI.
 func ListenAndServe(listenURI string, reflection bool) error {
-	return serve.RunWithOptions(listenURI, func(s *grpc.Server) {
-		pb.RegisterRhizomeAtlasServiceServer(s, &Server{})
-	}, reflection)
+	return (&Server{}).ListenAndServe(listenURI, reflection)
 }
 
-// Init creates a holon.mod file in the given directory.
-func (s *Server) Init(_ context.Context, req *pb.InitRequest) (*pb.InitResponse, error) {
+// ListenAndServe serves s over gRPC on the given transport URI. Use it
+// instead of the package-level function when the same Server must also
+// back another facet, such as the web dashboard.
+func (s *Server) ListenAndServe(listenURI string, reflection bool) error {
+	return s.Serve(listenURI, ServeOptions{Reflection: reflection})
+}
+
+// Init creates a holon.mod file in the given directory. With
+// req.FromExisting, it requires the dependencies vendored there (see
+// inferRequires).
+func (s *Server) Init(_ context.Context, req *pb.InitRequest) (_ *pb.InitResponse, err error) {
+	defer s.record("Init", req.Directory, &err)
+
 	dir := req.Directory
 	if dir == "" {
 		dir = "."
 	}
-	holonPath := req.HolonPath
-	if holonPath == "" {
-		return nil, status.Error(codes.InvalidArgument, "holon_path is required")
+	defer s.journal(dir, "Init", &err)()
+	holonPath := req.HolonPath
+	if holonPath == "" {
+		return nil, status.Error(codes.InvalidArgument, "holon_path is required")
+	}
+
+	modPath := filepath.Join(dir, "holon.mod")
+	if _, err := os.Stat(modPath); err == nil {
+		return nil, status.Errorf(codes.AlreadyExists, "holon.mod already exists in %s", dir)
+	}
+
+	mod := &modfile.ModFile{HolonPath: holonPath}
+	resp := &pb.InitResponse{ModFile: modPath}
+	if req.FromExisting {
+		sum, err := s.parseSum(filepath.Join(dir, "holon.sum"))
+		if err != nil {
+			return nil, sumError(filepath.Join(dir, "holon.sum"), err)
+		}
+		cache, err := ListCache()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "list cache: %v", err)
+		}
+		resp.Inferred, resp.Unresolved, err = inferRequires(dir, sum, cache)
+		if os.IsNotExist(err) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s has no %s directory to infer requires from", dir, modfile.DefaultVendorDir)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "infer requires: %v", err)
+		}
+		for _, dep := range resp.Inferred {
+			mod.AddRequire(dep.Path, dep.Version)
+		}
+	}
+	if err := s.writeMod(mod, modPath); err != nil {
+		return nil, status.Errorf(codes.Internal, "write holon.mod: %v", err)
+	}
+
+	return resp, nil
+}
+
+// Add fetches a dependency to the cache, then records it in holon.mod and
+// its hashes in holon.sum. It is all or nothing: if the fetch or a write
+// fails, both files are left as they were. So it is if the snapshot does
+// not hash to what holon.sum already records for that version, unless
+// req.Force. With req.RecordOnly the dependency is only recorded in
+// holon.mod, without fetching.
+//
+// Under the tofu directive of holon.mod, a snapshot never trusted on this
+// machine must be confirmed with req.Trust, then is trusted as fetched; one
+// trusted before must still hash to what was trusted, unless req.Force.
+//
+// A version of the form "@<channel>" (e.g. "@beta") resolves to the latest
+// prerelease tag of that channel; a commit hash, or a prefix of one of at
+// least 7 hex digits, to the pseudo-version pinning that commit;
+// "sha256:<hex>" pins the content by digest instead of by tag.
+//
+// With req.DryRun nothing is written or fetched; the response carries the
+// plan instead.
+func (s *Server) Add(ctx context.Context, req *pb.AddRequest) (*pb.AddResponse, error) {
+	return idempotent(&s.idem, "Add", req.IdempotencyKey, req, func() (*pb.AddResponse, error) {
+		return s.add(ctx, req)
+	})
+}
+
+func (s *Server) add(ctx context.Context, req *pb.AddRequest) (_ *pb.AddResponse, err error) {
+	defer s.record("Add", req.Directory, &err)
+
+	dir := holonDir(req.Directory)
+	defer s.journal(dir, "Add", &err)()
+
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	ctx = withFetchSources(withFetchQueue(s.withEvents(ctx), "Add"), mod)
+
+	// A dependency replaced by another path is fetched, cached and hashed
+	// as that path.
+	src := mod.SourcePath(req.Path)
+	version := req.Version
+	if channel, ok := strings.CutPrefix(version, "@"); ok {
+		version, err = latestChannelTag(ctx, src, channel)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "resolve %s@%s: %v", req.Path, channel, err)
+		}
+	}
+	if isCommitHash(version) {
+		if version, _, err = commitVersion(ctx, src, version); err != nil {
+			return nil, status.Errorf(codes.NotFound, "resolve %s@%s: %v", req.Path, req.Version, err)
+		}
+	}
+	if strings.HasPrefix(version, digestPrefix) {
+		if _, ok := parseDigest(version); !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid digest %q: want %s<64 hex digits>", version, digestPrefix)
+		}
+	}
+	if mod.Stable && semver.Prerelease(version) != "" {
+		return nil, prereleaseError(modPath, req.Path+"@"+version)
+	}
+
+	before := mod.Clone()
+	mod.AddRequire(req.Path, version)
+	if req.Alias != "" {
+		if err := mod.SetAlias(req.Path, req.Alias); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+	}
+	dep, _ := mod.RequireByName(req.Path)
+
+	if req.DryRun {
+		plan := &pb.Plan{Changes: diffMods(before, mod), Write: []string{modPath}}
+		if !req.RecordOnly {
+			plan.Write = append(plan.Write, filepath.Join(dir, "holon.sum"))
+			if !inCache(src, version) {
+				plan.Fetch = append(plan.Fetch, &pb.Dependency{Path: src, Version: version})
+			}
+		}
+		return &pb.AddResponse{
+			Dependency: &pb.Dependency{Path: req.Path, Version: version, Alias: dep.Alias},
+			Plan:       plan,
+			Root:       dir,
+		}, nil
+	}
+
+	// Fetch and hash before touching any file. Whatever fails from here
+	// on restores holon.mod and holon.sum, and evicts a fresh snapshot.
+	var txn fileTxn
+	var fetched bool
+	defer func() {
+		if err != nil {
+			txn.rollback(s)
+			if fetched {
+				removeFromCache(src, version)
+			}
+		}
+	}()
+
+	sumPath := filepath.Join(dir, "holon.sum")
+	var cachePath string
+	var trust *pb.TrustRecord
+	if !req.RecordOnly {
+		fetched = !inCache(src, version)
+		cachePath, err = fetchToCache(ctx, src, version)
+		if err != nil && ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		if err != nil {
+			return nil, detailed(codes.Unavailable, client.ReasonFetchFailed,
+				map[string]string{
+					"dependency": req.Path + "@" + version,
+					"run":        "atlas add --record-only " + req.Path + " " + version,
+				}, nil,
+				"fetch %s@%s: %v (use --record-only to add it without fetching)", req.Path, version, err)
+		}
+		if mod.Provenance && readProvenance(src, version) == nil {
+			return nil, provenanceError(modPath, req.Path, version)
+		}
+		if mod.TrustOnFirstUse {
+			if trust, err = checkTrust(modPath, src, version, cachePath, req.Trust, req.Force, req.TrustHash); err != nil {
+				return nil, err
+			}
+		}
+
+		// Content that no longer matches holon.sum is not installed.
+		var mismatchErr, hashErr error
+		err = s.updateSum(sumPath, func(sum *modfile.SumFile) bool {
+			if want := cacheMismatch(sum, src, version, cachePath); want != "" && !req.Force {
+				got, _ := sumHashDir(hashAlgorithm(want), cachePath)
+				mismatchErr = hashMismatchError(req.Path+"@"+version, sumPath,
+					"snapshot does not match holon.sum (want %s, got %s); use --force to accept it", want, got)
+				return false
+			}
+			if pinned, got := pinnedCommit(sum, src, version), fetchedCommit(src, version); pinned != "" && got != "" && got != pinned && !req.Force {
+				mismatchErr = hashMismatchError(req.Path+"@"+version, sumPath,
+					"tag moved upstream: it resolves to commit %s, holon.sum pins %s; use --force to accept it", got, pinned)
+				return false
+			}
+			before := sum.Clone()
+			if hashErr = setSnapshotHashes(sum, sumAlgorithms(sum), src, version, cachePath); hashErr != nil {
+				return false
+			}
+			txn.saveSum(sumPath, before, sum)
+			return true
+		})
+		if mismatchErr != nil {
+			return nil, mismatchErr
+		}
+		if hashErr != nil {
+			return nil, status.Errorf(codes.Internal, "%v", hashErr)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "update holon.sum: %v", err)
+		}
+	}
+
+	if err := txn.save(modPath); err != nil {
+		return nil, status.Errorf(codes.Internal, "read holon.mod: %v", err)
+	}
+	if err := s.writeMod(mod, modPath); err != nil {
+		return nil, status.Errorf(codes.Internal, "write holon.mod: %v", err)
+	}
+	if trust != nil {
+		if err := trustHolon(src, version, trust); err != nil {
+			return nil, status.Errorf(codes.Internal, "write %s: %v", knownHolonsPath(), err)
+		}
+	}
+
+	return &pb.AddResponse{
+		Dependency: &pb.Dependency{
+			Path:      req.Path,
+			Version:   version,
+			CachePath: cachePath,
+			Alias:     dep.Alias,
+			Source:    fetchSource(src, version),
+		},
+		Root:    dir,
+		Trusted: trust,
+	}, nil
+}
+
+// Resolve resolves a version query the way Add does, without fetching or
+// writing anything. A version outside the local cache is looked up
+// upstream: its source is the first one its Fetcher resolves, and its
+// commit the one its tag points to, for the git fetcher, or the one a
+// commit hash queried names.
+func (s *Server) Resolve(ctx context.Context, req *pb.ResolveRequest) (*pb.ResolveResponse, error) {
+	if req.Path == "" {
+		return nil, status.Error(codes.InvalidArgument, "path is required")
+	}
+	dir := holonDir(req.Directory)
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if os.IsNotExist(err) {
+		mod, err = &modfile.ModFile{}, nil
+	}
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	ctx = withFetchSources(ctx, mod)
+
+	src := mod.SourcePath(req.Path)
+	version := req.Version
+	var commit string
+	switch channel, isChannel := strings.CutPrefix(version, "@"); {
+	case version == "":
+		tags, err := remoteTags(ctx, src)
+		if err != nil {
+			return nil, detailed(codes.Unavailable, client.ReasonFetchFailed,
+				map[string]string{"dependency": req.Path}, nil, "list versions of %s: %v", src, err)
+		}
+		if version = latestTag(tags); version == "" {
+			return nil, status.Errorf(codes.NotFound, "%s has no release", src)
+		}
+	case isChannel:
+		if version, err = latestChannelTag(ctx, src, channel); err != nil {
+			return nil, status.Errorf(codes.NotFound, "resolve %s@%s: %v", req.Path, channel, err)
+		}
+	case isCommitHash(version):
+		if version, commit, err = commitVersion(ctx, src, version); err != nil {
+			return nil, status.Errorf(codes.NotFound, "resolve %s@%s: %v", req.Path, req.Version, err)
+		}
+	case strings.HasPrefix(version, digestPrefix):
+		if _, ok := parseDigest(version); !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid digest %q: want %s<64 hex digits>", version, digestPrefix)
+		}
+	}
+	if mod.Stable && semver.Prerelease(version) != "" {
+		return nil, prereleaseError(modPath, req.Path+"@"+version)
+	}
+
+	resp := &pb.ResolveResponse{Path: req.Path, Version: version}
+	if src != req.Path {
+		resp.SourcePath = src
+	}
+	// Only the local cache counts: a shared one would be downloaded from.
+	if cached, err := (fsStore{}).Get(src, version); err == nil {
+		resp.Cached, resp.CachePath = true, cached
+		if info := fetchInfoProto(src, version); info != nil {
+			resp.Source, resp.Commit = info.Source, info.Commit
+		}
+		return resp, nil
+	}
+
+	srcs, err := fetcherFor(ctx, src).Resolve(src, version)
+	if err != nil {
+		return nil, detailed(codes.Unavailable, client.ReasonFetchFailed,
+			map[string]string{"dependency": req.Path + "@" + version}, nil, "resolve %s@%s: %v", src, version, err)
+	}
+	if len(srcs) > 0 {
+		resp.Source = srcs[0].String()
+	}
+	resp.Commit = commit
+	if _, pinned := parseDigest(version); !pinned && !semver.IsPseudo(version) {
+		commits, err := remoteTagCommits(ctx, src)
+		if err != nil {
+			return nil, detailed(codes.Unavailable, client.ReasonFetchFailed,
+				map[string]string{"dependency": req.Path + "@" + version}, nil, "resolve %s@%s: %v", src, version, err)
+		}
+		if commits != nil && commits[version] == "" {
+			return nil, status.Errorf(codes.NotFound, "%s has no version %s", src, version)
+		}
+		resp.Commit = commits[version]
+	}
+	return resp, nil
+}
+
+// Remove removes a dependency from holon.mod. With req.DryRun nothing is
+// written; the response carries the plan instead.
+func (s *Server) Remove(ctx context.Context, req *pb.RemoveRequest) (*pb.RemoveResponse, error) {
+	return idempotent(&s.idem, "Remove", req.IdempotencyKey, req, func() (*pb.RemoveResponse, error) {
+		return s.remove(ctx, req)
+	})
+}
+
+func (s *Server) remove(ctx context.Context, req *pb.RemoveRequest) (_ *pb.RemoveResponse, err error) {
+	defer s.record("Remove", req.Directory, &err)
+
+	dir := holonDir(req.Directory)
+	defer s.journal(dir, "Remove", &err)()
+
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+
+	path := req.Path
+	if dep, ok := mod.RequireByName(req.Path); ok {
+		path = dep.Path
+	}
+	before := mod.Clone()
+	if !mod.RemoveRequire(path) {
+		return nil, unknownDependencyError(modPath, req.Path)
+	}
+
+	if req.DryRun {
+		return &pb.RemoveResponse{
+			Plan: &pb.Plan{Changes: diffMods(before, mod), Write: []string{modPath}},
+			Root: dir,
+		}, nil
+	}
+	if ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	if err := s.writeMod(mod, modPath); err != nil {
+		return nil, status.Errorf(codes.Internal, "write holon.mod: %v", err)
+	}
+
+	return &pb.RemoveResponse{Root: dir}, nil
+}
+
+// Pull fetches every dependency of the graph to the cache, records their
+// hashes in holon.sum and the versions selected in holon.lock. A
+// holon.lock that matches holon.mod is kept to rather than resolved
+// again. Canceling ctx aborts the fetch in progress and leaves holon.sum
+// as it was. See PullRequest for the options.
+func (s *Server) Pull(ctx context.Context, req *pb.PullRequest) (_ *pb.PullResponse, err error) {
+	defer s.record("Pull", req.Directory, &err)
+	defer s.journal(holonDir(req.Directory), "Pull", &err)()
+	return s.pull(ctx, req)
+}
+
+// pull is Pull, neither recorded nor journaled. The progress of each
+// dependency is reported to the function ctx carries, if any (see
+// withProgress).
+func (s *Server) pull(ctx context.Context, req *pb.PullRequest) (*pb.PullResponse, error) {
+	ctx = withFetchQueue(s.withEvents(ctx), "Pull")
+	dir := holonDir(req.Directory)
+
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	ctx = withFetchSources(ctx, mod)
+	strategy := cmp.Or(req.Resolve, mod.Resolve, modfile.StrategyMVS)
+	if err := modfile.ValidateStrategy(strategy); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	// The graph is resolved again only when holon.lock no longer matches.
+	locked, err := matchingLock(dir, mod, strategy, req.With)
+	if err != nil {
+		return nil, err
+	}
+	if locked == nil && req.Locked {
+		return nil, lockStaleError(filepath.Join(dir, "holon.lock"), modPath)
+	}
+	if mod, err = groupMod(mod, req.Group); err != nil {
+		return nil, err
+	}
+
+	// Optional dependencies are only pulled when asked for.
+	var skipped []*pb.Dependency
+	mod = mod.Clone()
+	mod.Require = slices.DeleteFunc(mod.Require, func(r modfile.Require) bool {
+		if r.Wanted(req.With) {
+			return false
+		}
+		skipped = append(skipped, &pb.Dependency{Path: r.Path, Version: r.Version, Alias: r.Alias})
+		return true
+	})
+
+	// Snapshots fetched from upstream are checked against holon.sum as
+	// soon as they are, those already cached once all are.
+	sumPath := filepath.Join(dir, "holon.sum")
+	recorded, _ := s.parseSum(sumPath)
+	force := req.Force
+	fresh := map[string]bool{}
+
+	// Under the tofu directive, a snapshot fetched must also match what was
+	// trusted of it on first use; one never trusted is trusted once the
+	// pull succeeds.
+	trusted := map[string]*pb.TrustRecord{}
+	checkFresh := func(depPath, version, dir string) error {
+		if err := checkFetched(recorded, depPath, version, dir, sumPath, force); err != nil {
+			return err
+		}
+		if mod.TrustOnFirstUse {
+			rec, err := checkTrust(modPath, depPath, version, dir, true, force, "")
+			if err != nil {
+				removeFromCache(depPath, version)
+				return err
+			}
+			if rec != nil {
+				trusted[depPath+"@"+version] = rec
+			}
+		}
+		reportProgress(ctx, pb.PullStage_PULL_STAGE_HASHED, depPath, version, 0)
+		return nil
+	}
+
+	// The direct dependencies are fetched at once, then checked in order.
+	var direct []*pb.Dependency
+	for _, req := range mod.Require {
+		// Skip replaced dependencies
+		if mod.ResolvedPath(req.Path) == "" {
+			src := mod.SourcePath(req.Path)
+			fresh[src+"@"+req.Version] = !inCache(src, req.Version)
+			direct = append(direct, &pb.Dependency{Path: src, Version: req.Version, Group: req.Group})
+		}
+	}
+	results := fetchAll(ctx, direct)
+	if ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	// Each snapshot fetched is checked before the first failure is
+	// reported, so that none not matching holon.sum stays cached.
+	var failed error
+	for _, req := range direct {
+		key := req.Path + "@" + req.Version
+		r := results[key]
+		if r.err != nil {
+			failed = cmp.Or(failed, status.Errorf(codes.Internal, "fetch %s@%s: %v", req.Path, req.Version, r.err))
+		} else if fresh[key] {
+			failed = cmp.Or(failed, checkFresh(req.Path, req.Version, r.dir))
+		}
+	}
+	if failed != nil {
+		return nil, failed
+	}
+
+	var fetched []*pb.Dependency
+	for _, req := range direct {
+		src, cachePath := req.Path, results[req.Path+"@"+req.Version].dir
+		if mod.Provenance && readProvenance(src, req.Version) == nil {
+			return nil, provenanceError(modPath, src, req.Version)
+		}
+
+		fetched = append(fetched, &pb.Dependency{
+			Path:      src,
+			Version:   req.Version,
+			CachePath: cachePath,
+			Source:    fetchSource(src, req.Version),
+			Group:     cmp.Or(req.Group, modfile.DefaultGroup),
+		})
+	}
+
+	// Then fetch the version the graph selects for each dependency, as
+	// holon.lock records it if it matches.
+	var lock *modfile.LockFile
+	if locked != nil {
+		lock = lockedGraph(locked, mod)
+	} else {
+		lock, err = resolveGraph(ctx, mod, strategy, req.With)
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "resolve dependencies: %v", err)
+		}
+	}
+	for _, sel := range lock.Selected {
+		src := mod.SourcePath(sel.Path)
+		if slices.ContainsFunc(fetched, func(d *pb.Dependency) bool { return d.Path == src && d.Version == sel.Version }) {
+			continue
+		}
+		key := src + "@" + sel.Version
+		fresh[key] = !inCache(src, sel.Version)
+		cachePath, err := fetchToCache(ctx, src, sel.Version)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "fetch %s@%s: %v", src, sel.Version, err)
+		}
+		if fresh[key] {
+			if err := checkFresh(src, sel.Version, cachePath); err != nil {
+				return nil, err
+			}
+		}
+		if mod.Provenance && readProvenance(src, sel.Version) == nil {
+			return nil, provenanceError(modPath, src, sel.Version)
+		}
+		fetched = append(fetched, &pb.Dependency{
+			Path:      src,
+			Version:   sel.Version,
+			CachePath: cachePath,
+			Source:    fetchSource(src, sel.Version),
+		})
+	}
+
+	// A cached snapshot no longer matching holon.sum was corrupted, unless
+	// fetching it again shows that upstream changed.
+	var repaired []*pb.Dependency
+	for _, dep := range fetched {
+		if fresh[dep.Path+"@"+dep.Version] {
+			continue
+		}
+		if pinned, got := pinnedCommit(recorded, dep.Path, dep.Version), fetchedCommit(dep.Path, dep.Version); pinned != "" && got != "" && got != pinned && !force {
+			return nil, hashMismatchError(dep.Path+"@"+dep.Version, sumPath,
+				"cached snapshot was fetched from commit %s, holon.sum pins %s: the tag moved upstream; use --force to accept it", got, pinned)
+		}
+		if want := cacheMismatch(recorded, dep.Path, dep.Version, dep.CachePath); want != "" {
+			if !req.Repair {
+				return nil, hashMismatchError(dep.Path+"@"+dep.Version, sumPath, "cached snapshot does not match holon.sum; pull with repair to fetch it again")
+			}
+			if dep.CachePath, err = refetch(ctx, dep.Path, dep.Version, want, sumPath, force); err != nil {
+				return nil, err
+			}
+			dep.Source = fetchSource(dep.Path, dep.Version)
+			repaired = append(repaired, dep)
+		}
+		reportProgress(ctx, pb.PullStage_PULL_STAGE_HASHED, dep.Path, dep.Version, 0)
+	}
+
+	over, err := snapshotBudgets(mod, fetched)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	if req.EnforceBudget && len(over) > 0 {
+		return nil, budgetError(modPath, over)
+	}
+
+	var hashErr error
+	err = s.updateSum(sumPath, func(sum *modfile.SumFile) bool {
+		algs := sumAlgorithms(sum)
+		for _, dep := range fetched {
+			if hashErr = setSnapshotHashes(sum, algs, dep.Path, dep.Version, dep.CachePath); hashErr != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if hashErr != nil {
+		return nil, status.Errorf(codes.Internal, "%v", hashErr)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "update holon.sum: %v", err)
+	}
+	var trustedDeps []*pb.Dependency
+	for _, dep := range fetched {
+		if rec := trusted[dep.Path+"@"+dep.Version]; rec != nil {
+			if err := trustHolon(dep.Path, dep.Version, rec); err != nil {
+				return nil, status.Errorf(codes.Internal, "write %s: %v", knownHolonsPath(), err)
+			}
+			trustedDeps = append(trustedDeps, &pb.Dependency{Path: dep.Path, Version: dep.Version, Source: rec.Source})
+		}
+		reportProgress(ctx, pb.PullStage_PULL_STAGE_DONE, dep.Path, dep.Version, 0)
+	}
+
+	// The lock records the whole graph, not that of one group.
+	if req.Group == "" && locked == nil {
+		if err := lock.Write(filepath.Join(dir, "holon.lock")); err != nil {
+			return nil, status.Errorf(codes.Internal, "write holon.lock: %v", err)
+		}
+	}
+
+	// Deprecations are checked when a direct dependency is first fetched,
+	// not on every pull: Freshness reports them all.
+	var adopted []modfile.Require
+	for _, r := range mod.Require {
+		if mod.ResolvedPath(r.Path) == "" && fresh[mod.SourcePath(r.Path)+"@"+r.Version] {
+			adopted = append(adopted, r)
+		}
+	}
+
+	return &pb.PullResponse{
+		Fetched:    fetched,
+		Strategy:   strategy,
+		Resolved:   resolutions(lock),
+		Skipped:    skipped,
+		Root:       dir,
+		Repaired:   repaired,
+		OverBudget: over,
+		Deprecated: requireDeprecations(ctx, mod, adopted),
+		Trusted:    trustedDeps,
+	}, nil
+}
+
+// StreamPull pulls as Pull does, sending the progress of each dependency
+// as it is fetched, hashed and recorded in holon.sum, then the outcome.
+// A dependency already cached is only hashed and recorded.
+func (s *Server) StreamPull(req *pb.PullRequest, stream pb.RhizomeAtlasService_StreamPullServer) (err error) {
+	defer s.record("StreamPull", req.Directory, &err)
+	defer s.journal(holonDir(req.Directory), "Pull", &err)()
+
+	// Progress comes from the goroutines fetching, and from fetches
+	// shared with other calls, which may outlive this one.
+	var mu sync.Mutex
+	var sendErr error
+	done := false
+	send := func(p *pb.PullProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !done && sendErr == nil {
+			sendErr = stream.Send(p)
+		}
+	}
+	resp, err := s.pull(withProgress(stream.Context(), send), req)
+	mu.Lock()
+	done = true
+	mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	return stream.Send(&pb.PullProgress{Result: resp})
+}
+
+// Lock resolves the dependency graph of the holon again, as Pull does
+// when holon.lock does not match holon.mod, and records it in holon.lock;
+// with req.DryRun, it only reports it. The dependencies are fetched to the
+// cache for their holon.mod, but holon.sum is left as it was: the next
+// Pull hashes and records them.
+func (s *Server) Lock(ctx context.Context, req *pb.LockRequest) (_ *pb.LockResponse, err error) {
+	defer s.record("Lock", req.Directory, &err)
+	defer s.journal(holonDir(req.Directory), "Lock", &err)()
+	ctx = withFetchQueue(s.withEvents(ctx), "Lock")
+	dir := holonDir(req.Directory)
+
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	ctx = withFetchSources(ctx, mod)
+	strategy := cmp.Or(req.Resolve, mod.Resolve, modfile.StrategyMVS)
+	if err := modfile.ValidateStrategy(strategy); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	lock, err := resolveGraph(ctx, mod, strategy, req.With)
+	if ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "resolve dependencies: %v", err)
+	}
+
+	lockPath := filepath.Join(dir, "holon.lock")
+	previous, _ := os.ReadFile(lockPath)
+	changed := !bytes.Equal(previous, lock.Format())
+	if changed && !req.DryRun {
+		if err := lock.Write(lockPath); err != nil {
+			return nil, status.Errorf(codes.Internal, "write holon.lock: %v", err)
+		}
+	}
+	return &pb.LockResponse{Root: dir, Strategy: strategy, Resolved: resolutions(lock), Changed: changed}, nil
+}
+
+// Verify checks holon.sum integrity against cached content, or against
+// the vendored copy of a dependency that is not cached. Active replaces,
+// prerelease requires, and requires and replaces past their until date,
+// are reported as warnings, which only fail verification with req.Strict.
+func (s *Server) Verify(ctx context.Context, req *pb.VerifyRequest) (*pb.VerifyResponse, error) {
+	ctx = s.withEvents(ctx)
+	dir := holonDir(req.Directory)
+
+	sumPath := filepath.Join(dir, "holon.sum")
+	sum, err := s.parseSum(sumPath)
+	if err != nil {
+		return nil, sumError(sumPath, err)
+	}
+
+	// Also check for active replaces, prereleases and expired pins
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, _ := s.parseMod(modPath)
+	ctx = withFetchSources(ctx, mod)
+
+	var f verifyFindings
+	var repaired []*pb.Dependency
+	refetched := map[string]bool{} // path@version fetched again by repair
+
+	if mod != nil {
+		for _, r := range mod.Replace {
+			f.warn("active-replace", "active replace %s => %s", r.Old, r.New)
+		}
+		for _, dep := range mod.Require {
+			switch {
+			case semver.IsPseudo(dep.Version):
+				f.warn("pseudo-version", "%s %s pins a commit, not a release", dep.Path, dep.Version)
+			case semver.Prerelease(dep.Version) != "":
+				f.warn("prerelease", "prerelease %s %s in use", dep.Path, dep.Version)
+			}
+		}
+		for _, e := range expiredPins(mod, modPath, time.Now()) {
+			f.warn("expired", "%s", e)
+		}
+	}
+
+	for _, entry := range sum.Entries {
+		// A pinned commit is checked against the one the cache was fetched
+		// from, when known.
+		if version, ok := strings.CutSuffix(entry.Version, commitSuffix); ok {
+			pinned := strings.TrimPrefix(entry.Hash, commitPrefix)
+			if _, named, ok := semver.Pseudo(version); ok && !strings.HasPrefix(pinned, named) {
+				f.fail("commit-mismatch", "%s %s: holon.sum pins commit %s, not the one the pseudo-version names",
+					entry.Path, version, pinned)
+			}
+			if got := fetchedCommit(entry.Path, version); got != "" && got != pinned {
+				f.fail("commit-mismatch", "%s %s: cached snapshot fetched from commit %s, holon.sum pins %s",
+					entry.Path, version, got, pinned)
+			}
+			continue
+		}
+
+		// Extract base version (strip /HOLON.md suffix)
+		version := entry.Version
+		isHolonMD := strings.HasSuffix(version, "/HOLON.md")
+		if isHolonMD {
+			version = strings.TrimSuffix(version, "/HOLON.md")
+		}
+
+		cachePath, _ := cacheStore().Get(entry.Path, version)
+		vendored := false
+		if cachePath == "" && mod != nil {
+			cachePath = vendoredCopy(dir, mod, entry.Path, version)
+			vendored = cachePath != ""
+		}
+
+		// Check each entry with the algorithm it was recorded with.
+		alg := hashAlgorithm(entry.Hash)
+		if _, ok := hashers[alg]; !ok {
+			f.fail("unknown-algorithm", "%s %s: unknown hash algorithm %q", entry.Path, entry.Version, alg)
+			continue
+		}
+
+		var currentHash string
+		switch {
+		case cachePath == "":
+		case isHolonMD:
+			currentHash, _ = sumHashFile(alg, filepath.Join(cachePath, "HOLON.md"))
+		default:
+			currentHash, _ = sumHashDir(alg, cachePath)
+		}
+
+		// Fetch a corrupted snapshot again, once for it and its HOLON.md.
+		key := entry.Path + "@" + version
+		want := sum.LookupAlgorithm(entry.Path, version, alg)
+		if req.Repair && !vendored && currentHash != "" && currentHash != entry.Hash && want != "" && !refetched[key] {
+			refetched[key] = true
+			if _, err := refetch(ctx, entry.Path, version, want, sumPath, false); err != nil {
+				f.fail("repair-failed", "%s %s: %s", entry.Path, entry.Version, status.Convert(err).Message())
+				continue
+			}
+			repaired = append(repaired, &pb.Dependency{Path: entry.Path, Version: version, CachePath: cachePath, Source: fetchSource(entry.Path, version)})
+			if isHolonMD {
+				currentHash, _ = sumHashFile(alg, filepath.Join(cachePath, "HOLON.md"))
+			} else {
+				currentHash, _ = sumHashDir(alg, cachePath)
+			}
+		}
+
+		if currentHash == "" {
+			f.fail("not-cached", "%s %s: not in cache", entry.Path, entry.Version)
+		} else if currentHash != entry.Hash {
+			what := "hash mismatch"
+			if vendored {
+				what = "vendored copy hash mismatch"
+			}
+			f.fail("hash-mismatch", "%s %s: %s (want %s, got %s)",
+				entry.Path, entry.Version, what, entry.Hash, currentHash)
+		}
+	}
+
+	if req.Remote {
+		for _, e := range verifyUpstream(ctx, sum) {
+			f.fail("upstream", "%s", e)
+		}
+	}
+	if req.Log {
+		for _, e := range verifyLog(ctx, sum) {
+			f.fail("log", "%s", e)
+		}
+	}
+
+	resp := &pb.VerifyResponse{Root: dir, Repaired: repaired, Findings: f}
+	for _, finding := range f {
+		if req.Strict {
+			finding.Severity = pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_ERROR
+		}
+		if finding.Severity == pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_ERROR {
+			resp.Errors = append(resp.Errors, finding.Message)
+		} else {
+			resp.Warnings = append(resp.Warnings, finding.Message)
+		}
+	}
+	resp.Ok = len(resp.Errors) == 0
+	if !resp.Ok {
+		s.events.publish(&pb.Event{Kind: pb.EventKind_EVENT_KIND_VERIFY_FAILED, Directory: dir, Errors: resp.Errors})
+	}
+	return resp, nil
+}
+
+// verifyFindings collects what Verify finds, in order.
+type verifyFindings []*pb.VerifyFinding
+
+func (f *verifyFindings) fail(code, format string, args ...any) {
+	f.add(pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_ERROR, code, format, args...)
+}
+
+func (f *verifyFindings) warn(code, format string, args ...any) {
+	f.add(pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_WARNING, code, format, args...)
+}
+
+func (f *verifyFindings) add(severity pb.DiagnosticSeverity, code, format string, args ...any) {
+	*f = append(*f, &pb.VerifyFinding{Severity: severity, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// verifyUpstream fetches every version recorded in sum from upstream into
+// a temporary directory, and reports the entries whose upstream content
+// differs from sum (a moved tag) or from the cache, and the versions
+// upstream resolves to another commit than the one sum pins.
+func verifyUpstream(ctx context.Context, sum *modfile.SumFile) []string {
+	tmp, err := os.MkdirTemp("", "atlas-verify-")
+	if err != nil {
+		return []string{fmt.Sprintf("create temp dir: %v", err)}
+	}
+	defer os.RemoveAll(tmp) //nolint:errcheck
+
+	var problems []string
+	fetched := map[string]string{} // path@version → its upstream copy, "" if the fetch failed
+	for _, entry := range sum.Entries {
+		version, isHolonMD := strings.CutSuffix(entry.Version, "/HOLON.md")
+		version, isCommit := strings.CutSuffix(version, commitSuffix)
+		alg := hashAlgorithm(entry.Hash)
+		if _, ok := hashers[alg]; !ok && !isCommit {
+			continue // reported by Verify
+		}
+		hash := func(dir string) string {
+			if isHolonMD {
+				h, _ := sumHashFile(alg, filepath.Join(dir, "HOLON.md"))
+				return h
+			}
+			h, _ := sumHashDir(alg, dir)
+			return h
+		}
+
+		key := entry.Path + "@" + version
+		upstreamDir, done := fetched[key]
+		if !done {
+			upstreamDir = filepath.Join(tmp, fmt.Sprint(len(fetched)))
+			if _, err := fetchUpstream(ctx, entry.Path, version, upstreamDir); err != nil {
+				problems = append(problems, fmt.Sprintf("%s %s: fetch from upstream: %v", entry.Path, version, err))
+				upstreamDir = ""
+			}
+			fetched[key] = upstreamDir
+		}
+		if upstreamDir == "" {
+			continue
+		}
+
+		if isCommit {
+			rev, err := readRevision(upstreamDir)
+			if err == nil && rev.Commit != "" && commitPrefix+rev.Commit != entry.Hash {
+				problems = append(problems, fmt.Sprintf("%s %s: tag moved upstream (holon.sum pins %s, upstream resolves to %s)",
+					entry.Path, version, strings.TrimPrefix(entry.Hash, commitPrefix), rev.Commit))
+			}
+			continue
+		}
+
+		upstream := hash(upstreamDir)
+		if upstream != entry.Hash {
+			problems = append(problems, fmt.Sprintf("%s %s: upstream content changed (holon.sum has %s, upstream serves %s)",
+				entry.Path, entry.Version, entry.Hash, upstream))
+		}
+		if cachePath, err := cacheStore().Get(entry.Path, version); err == nil {
+			if cached := hash(cachePath); cached != upstream {
+				problems = append(problems, fmt.Sprintf("%s %s: cache differs from upstream (cache has %s, upstream serves %s)",
+					entry.Path, entry.Version, cached, upstream))
+			}
+		}
+	}
+	return problems
+}
+
+// VerifyAll runs Verify on every requested directory and on every holon
+// discovered under the request root. A holon that cannot be verified at
+// all is reported as a failed result rather than failing the call.
+func (s *Server) VerifyAll(ctx context.Context, req *pb.VerifyAllRequest) (*pb.VerifyAllResponse, error) {
+	dirs := append([]string(nil), req.Directories...)
+	if req.Root != "" {
+		found, err := discoverHolons(req.Root)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "discover holons under %s: %v", req.Root, err)
+		}
+		dirs = append(dirs, found...)
+	}
+	if len(dirs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "directories or root is required")
+	}
+
+	resp := &pb.VerifyAllResponse{Ok: true}
+	for _, dir := range dirs {
+		result := &pb.HolonVerification{Directory: dir}
+		v, err := s.Verify(ctx, &pb.VerifyRequest{Directory: dir, Remote: req.Remote, Log: req.Log, Repair: req.Repair, Strict: req.Strict})
+		if err != nil {
+			result.Errors = []string{err.Error()}
+		} else {
+			result.Ok = v.Ok
+			result.Errors = v.Errors
+			result.Warnings = v.Warnings
+		}
+		resp.Ok = resp.Ok && result.Ok
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+// discoverHolons returns every directory under root holding a holon.mod,
+// skipping hidden directories.
+func discoverHolons(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && d.Name() == "holon.mod" {
+			dirs = append(dirs, filepath.Dir(path))
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// Graph returns the dependency tree: its edges, and its nodes with the
+// metadata renderers need (see graphNodes).
+func (s *Server) Graph(_ context.Context, req *pb.GraphRequest) (*pb.GraphResponse, error) {
+	dir := holonDir(req.Directory)
+
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+
+	edges := s.graphEdges(dir, mod, req.Filter)
+	return &pb.GraphResponse{
+		Root:  mod.HolonPath,
+		Edges: edges,
+		Nodes: s.graphNodes(dir, mod, edges),
+	}, nil
+}
+
+// defaultChunkSize is the number of edges per StreamGraph message when the
+// request does not set one.
+const defaultChunkSize = 500
+
+// StreamGraph sends the dependency tree in chunks of at most chunk_size
+// edges, then a final message carrying the summary and the nodes.
+func (s *Server) StreamGraph(req *pb.StreamGraphRequest, stream pb.RhizomeAtlasService_StreamGraphServer) error {
+	dir := holonDir(req.Directory)
+	chunkSize := int(req.ChunkSize)
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return modError(modPath, err)
+	}
+
+	edges := s.graphEdges(dir, mod, req.Filter)
+	nodes := s.graphNodes(dir, mod, edges)
+	total := len(edges)
+	for len(edges) > 0 {
+		n := min(chunkSize, len(edges))
+		if err := stream.Send(&pb.GraphChunk{Root: mod.HolonPath, Edges: edges[:n]}); err != nil {
+			return err
+		}
+		edges = edges[n:]
+	}
+
+	return stream.Send(&pb.GraphChunk{
+		Root:    mod.HolonPath,
+		Summary: &pb.GraphSummary{EdgeCount: int32(total)},
+		Nodes:   nodes,
+	})
+}
+
+// graphEdges lists the requires of the holon.mod mod of the holon in dir
+// matching filter, then, below each, the requires of the version of the
+// dependency the build list selects, and so on down the whole graph, as
+// far as the holon.mod of each is found in the cache, vendored or in the
+// local directory a require of mod is replaced by. The requires of a
+// holon are listed once, below its first edge.
+func (s *Server) graphEdges(dir string, mod *modfile.ModFile, filter map[string]string) []*pb.Edge {
+	sel := map[selKey]string{}
+	for _, selected := range s.buildList(dir, mod, func(modfile.Require) bool { return true }).Selected {
+		sel[majorKey(selected.Path, selected.Version)] = selected.Version
+	}
+
+	var edges []*pb.Edge
+	walked := map[selKey]bool{}
+	var walk func(from, fromVersion string, reqs []modfile.Require)
+	walk = func(from, fromVersion string, reqs []modfile.Require) {
+		for _, req := range reqs {
+			edges = append(edges, &pb.Edge{
+				From:        from,
+				To:          req.Path,
+				Version:     req.Version,
+				Alias:       req.Alias,
+				Metadata:    req.Meta,
+				FromVersion: fromVersion,
+			})
+
+			// Recurse into the selected version, or the local replace.
+			key := majorKey(req.Path, req.Version)
+			version, ok := sel[key]
+			if !ok && from == mod.HolonPath && mod.ResolvedPath(req.Path) != "" {
+				version, ok = req.Version, true
+			}
+			if !ok || walked[key] || req.Path == mod.HolonPath {
+				continue
+			}
+			walked[key] = true
+			subModPath := filepath.Join(dependencyDir(dir, mod, req.Path, version), "holon.mod")
+			if subMod, err := s.parseMod(subModPath); err == nil {
+				walk(req.Path, version, subMod.Require)
+			}
+		}
+	}
+
+	var roots []modfile.Require
+	for _, req := range mod.Require {
+		if req.Matches(filter) {
+			roots = append(roots, req)
+		}
+	}
+	walk(mod.HolonPath, "", roots)
+	return edges
+}
+
+// graphNodes lists the holons of the graph of the holon in dir made of
+// edges: the root, then each holon edges reach, once per major version
+// when majors coexist, at the highest version of it they require, with
+// where its sources are and the name in its HOLON.md.
+func (s *Server) graphNodes(dir string, mod *modfile.ModFile, edges []*pb.Edge) []*pb.Node {
+	root := &pb.Node{Path: mod.HolonPath, Title: holonTitle(dir)}
+	nodes := []*pb.Node{root}
+	byKey := map[selKey]*pb.Node{}
+	for _, e := range edges {
+		if e.To == root.Path {
+			continue
+		}
+		key := majorKey(e.To, e.Version)
+		n, ok := byKey[key]
+		if !ok {
+			n = &pb.Node{Path: e.To, Version: e.Version}
+			byKey[key] = n
+			nodes = append(nodes, n)
+		} else if semver.Compare(e.Version, n.Version) > 0 {
+			n.Version = e.Version
+		}
+	}
+
+	for _, n := range nodes[1:] {
+		srcDir := ""
+		src := mod.SourcePath(n.Path)
+		if local := mod.ResolvedPath(n.Path); local != "" {
+			n.Replace, n.Location, srcDir = local, "replace", localPath(dir, local)
+		} else if cached, err := cacheStore().Get(src, n.Version); err == nil {
+			n.Location, srcDir = "cache", cached
+		} else if vendored := vendoredCopy(dir, mod, src, n.Version); vendored != "" {
+			n.Location, srcDir = "vendor", vendored
+		} else {
+			n.Location = "missing"
+		}
+		if src != n.Path {
+			n.Replace = src
+		}
+		if srcDir != "" {
+			n.Title = holonTitle(srcDir)
+		}
+	}
+	return nodes
+}
+
+// holonTitle returns the name in the HOLON.md front-matter of the holon
+// in dir, or "" without one.
+func holonTitle(dir string) string {
+	fm, err := holonmd.Parse(filepath.Join(dir, "HOLON.md"))
+	if err != nil {
+		return ""
+	}
+	return fm.Name
+}
+
+// Update checks remote git tags for each dependency and updates to the
+// latest compatible semver version. Follows Minimum Version Selection:
+// the latest tag that shares the same major version.
+//
+// Before an update is applied, the capabilities declared in the HOLON.md
+// of both versions are compared. An update that removes capabilities, or
+// whose HOLON.md cannot be compared, is breaking and is only applied when
+// req.AllowBreaking is set; otherwise it is reported in Held.
+//
+// Required versions whose upstream tag was force-moved away from the
+// commit holon.sum pins are reported in Moved.
+//
+// With req.DryRun nothing is written, fetched or evicted, and the contract
+// check is skipped since it needs the new versions; the response carries
+// the plan instead.
+//
+// With req.Branching the updates are committed to git branches, pulled,
+// instead of holon.mod, and pushed with req.Push (see updateBranches).
+func (s *Server) Update(ctx context.Context, req *pb.UpdateRequest) (*pb.UpdateResponse, error) {
+	return idempotent(&s.idem, "Update", req.IdempotencyKey, req, func() (*pb.UpdateResponse, error) {
+		return s.update(ctx, req)
+	})
+}
+
+func (s *Server) update(ctx context.Context, req *pb.UpdateRequest) (_ *pb.UpdateResponse, err error) {
+	defer s.record("Update", req.Directory, &err)
+	ctx = s.withEvents(ctx)
+
+	dir := holonDir(req.Directory)
+	defer s.journal(dir, "Update", &err)()
+
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	ctx = withFetchSources(ctx, mod)
+
+	resp := &pb.UpdateResponse{Root: dir}
+	if mod.Stable && req.Channel != "" {
+		return nil, prereleaseError(modPath, fmt.Sprintf("channel %q", req.Channel))
+	}
+	branching := req.Branching != pb.UpdateBranching_UPDATE_BRANCHING_UNSPECIFIED && !req.DryRun
+	if req.Push && req.Branching == pb.UpdateBranching_UPDATE_BRANCHING_UNSPECIFIED {
+		return nil, status.Error(codes.InvalidArgument, "push needs branching")
+	}
+	var base string
+	if branching {
+		if base, err = branchBase(dir); err != nil {
+			return nil, err
+		}
+	}
+	sum, _ := s.parseSum(filepath.Join(dir, "holon.sum"))
+	resp.Moved = movedTags(ctx, mod, sum)
+
+	if req.DryRun {
+		before := mod.Clone()
+		plan := &pb.Plan{}
+		for _, u := range pendingUpdates(ctx, mod, req.Channel) {
+			src := mod.SourcePath(u.Path)
+			mod.AddRequire(u.Path, u.NewVersion)
+			if req.Changelog {
+				u.Notes = releaseNotes(ctx, src, u.OldVersion, u.NewVersion, false)
+			}
+			resp.Updated = append(resp.Updated, u)
+			if !inCache(src, u.NewVersion) {
+				plan.Fetch = append(plan.Fetch, &pb.Dependency{Path: src, Version: u.NewVersion})
+			}
+			if old, err := cacheStore().Stat(src, u.OldVersion); err == nil {
+				plan.Delete = append(plan.Delete, old.Dir)
+				plan.Bytes += old.Size
+			}
+		}
+		s.events.announceUpdates(dir, resp.Updated)
+		plan.Changes = diffMods(before, mod)
+		if len(resp.Updated) > 0 {
+			plan.Write = []string{modPath}
+		}
+		resp.Plan = plan
+		if req.Changelog {
+			resp.Changelog = aggregateChangelog(resp.Updated)
+		}
+		return resp, nil
+	}
+
+	for _, u := range pendingUpdates(ctx, mod, req.Channel) {
+		src := mod.SourcePath(u.Path)
+		removed, err := removedCapabilities(ctx, src, u.OldVersion, u.NewVersion)
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		if err != nil {
+			u.ContractError = err.Error()
+		}
+		u.RemovedCapabilities = removed
+		if (len(removed) > 0 || err != nil) && !req.AllowBreaking {
+			resp.Held = append(resp.Held, u)
+			continue
+		}
+
+		// Read the notes before the old version is evicted.
+		if req.Changelog {
+			u.Notes = releaseNotes(ctx, src, u.OldVersion, u.NewVersion, true)
+		}
+
+		// Remove old cache entry, fetch new; the branches leave the old
+		// version checked out.
+		if !branching {
+			removeFromCache(src, u.OldVersion)
+		}
+		mod.AddRequire(u.Path, u.NewVersion)
+		resp.Updated = append(resp.Updated, u)
+	}
+
+	if branching {
+		if resp.Branches, err = s.updateBranches(ctx, dir, base, resp.Updated, req); err != nil {
+			return nil, err
+		}
+	} else if len(resp.Updated) > 0 {
+		if err := s.writeMod(mod, modPath); err != nil {
+			return nil, status.Errorf(codes.Internal, "write holon.mod: %v", err)
+		}
+	}
+	if req.Changelog {
+		resp.Changelog = aggregateChangelog(resp.Updated)
+	}
+
+	return resp, nil
+}
+
+// movedTags reports the dependencies of mod whose upstream tag points to
+// another commit than the one sum pins. Those whose tags cannot be listed
+// are logged and skipped.
+func movedTags(ctx context.Context, mod *modfile.ModFile, sum *modfile.SumFile) []*pb.MovedTag {
+	var moved []*pb.MovedTag
+	for _, dep := range mod.Require {
+		src := mod.SourcePath(dep.Path)
+		pinned := pinnedCommit(sum, src, dep.Version)
+		if pinned == "" || mod.ResolvedPath(dep.Path) != "" {
+			continue
+		}
+		commits, err := remoteTagCommits(ctx, src)
+		if err != nil {
+			log.Printf("atlas update: %s: %v (tag not checked)", dep.Path, err)
+			continue
+		}
+		if upstream := commits[dep.Version]; upstream != "" && upstream != pinned {
+			moved = append(moved, &pb.MovedTag{Path: dep.Path, Version: dep.Version, PinnedCommit: pinned, UpstreamCommit: upstream})
+		}
+	}
+	return moved
+}
+
+// PendingUpdates reports the dependencies of the holon.mod in dir that
+// Update would move to a newer compatible version, without changing anything.
+func (s *Server) PendingUpdates(ctx context.Context, dir string) ([]*pb.UpdatedDependency, error) {
+	mod, err := s.parseMod(filepath.Join(dir, "holon.mod"))
+	if err != nil {
+		return nil, modError(filepath.Join(dir, "holon.mod"), err)
+	}
+	updates := pendingUpdates(withFetchSources(ctx, mod), mod, "")
+	s.events.announceUpdates(dir, updates)
+	return updates, nil
+}
+
+// pendingUpdates queries upstream tags for every dependency not replaced
+// by a local directory; those replaced by another path list its tags.
+// Dependencies whose remote cannot be reached are logged and skipped.
+// Prerelease tags are considered in channel, or in the channel of the
+// required version when channel is empty, unless mod is stable.
+func pendingUpdates(ctx context.Context, mod *modfile.ModFile, channel string) []*pb.UpdatedDependency {
+	var updated []*pb.UpdatedDependency
+	for _, dep := range mod.Require {
+		// Skip replaced dependencies
+		if mod.ResolvedPath(dep.Path) != "" {
+			continue
+		}
+
+		depChannel := channel
+		if depChannel == "" {
+			depChannel = semver.Channel(dep.Version)
+		}
+		if mod.Stable {
+			depChannel = ""
+		}
+
+		latest, err := latestCompatibleTag(ctx, mod.SourcePath(dep.Path), dep.Version, depChannel)
+		if err != nil {
+			log.Printf("atlas update: %s: %v (skipped)", dep.Path, err)
+			continue
+		}
+		if latest == dep.Version {
+			continue
+		}
+
+		updated = append(updated, &pb.UpdatedDependency{
+			Path:       dep.Path,
+			OldVersion: dep.Version,
+			NewVersion: latest,
+		})
+	}
+	return updated
+}
+
+// Vendor copies the build list, every dependency of the graph at the
+// version selected (see vendoring), from the cache to the vendor
+// directory of holon.mod, replacing the copies there. Optional
+// dependencies not pulled and the paths .holonvendorignore lists are left
+// out. See VendorRequest for the options.
+func (s *Server) Vendor(ctx context.Context, req *pb.VendorRequest) (*pb.VendorResponse, error) {
+	return idempotent(&s.idem, "Vendor", req.IdempotencyKey, req, func() (*pb.VendorResponse, error) {
+		return s.vendor(ctx, req)
+	})
+}
+
+func (s *Server) vendor(ctx context.Context, req *pb.VendorRequest) (_ *pb.VendorResponse, err error) {
+	defer s.record("Vendor", req.Directory, &err)
+
+	dir := holonDir(req.Directory)
+	mod, deps, ignore, err := s.vendoring(dir, req.Group)
+	if err != nil {
+		return nil, err
+	}
+	over := vendorBudgets(mod, deps, ignore)
+	if req.EnforceBudget && len(over) > 0 {
+		return nil, budgetError(filepath.Join(dir, "holon.mod"), over)
+	}
+
+	var plan *pb.Plan
+	if req.DryRun {
+		plan = &pb.Plan{}
+	}
+	clearVendorDir(dir, mod, plan)
+
+	var vendored []*pb.Dependency
+	for _, v := range deps {
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		size, err := vendorCopy(mod, v, ignore, req.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		if plan != nil {
+			plan.Write = append(plan.Write, v.dst)
+			plan.Bytes += size
+		}
+		vendored = append(vendored, &pb.Dependency{
+			Path:      v.dep.Path,
+			Version:   v.dep.Version,
+			CachePath: v.dst,
+			Group:     cmp.Or(v.dep.Group, modfile.DefaultGroup),
+		})
+	}
+	if plan != nil {
+		plan.Write = append(plan.Write, filepath.Join(dir, cmp.Or(mod.VendorDir, modfile.DefaultVendorDir), vendoredListFile))
+	} else if err := writeVendoredList(dir, mod, deps); err != nil {
+		return nil, status.Errorf(codes.Internal, "write %s: %v", vendoredListFile, err)
+	}
+
+	return &pb.VendorResponse{Vendored: vendored, Plan: plan, Root: dir, OverBudget: over}, nil
+}
+
+// StreamVendor vendors as Vendor does, sending the outcome of each
+// dependency as it is copied, then a summary. With req.KeepGoing, the
+// dependencies missing from the cache or failing to copy are reported and
+// the others vendored; otherwise the first failure ends the stream.
+func (s *Server) StreamVendor(req *pb.StreamVendorRequest, stream pb.RhizomeAtlasService_StreamVendorServer) (err error) {
+	defer s.record("StreamVendor", req.Directory, &err)
+
+	dir := holonDir(req.Directory)
+	mod, deps, ignore, err := s.vendoring(dir, req.Group)
+	if err != nil && !(req.KeepGoing && mod != nil) {
+		return err
+	}
+	over := vendorBudgets(mod, deps, ignore)
+	if req.EnforceBudget && len(over) > 0 {
+		return budgetError(filepath.Join(dir, "holon.mod"), over)
+	}
+	clearVendorDir(dir, mod, nil)
+
+	summary := &pb.VendorSummary{Root: dir, OverBudget: over}
+	var copied []vendorItem
+	for i, v := range deps {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		dep := v.dep
+		progress := &pb.VendorProgress{
+			Dependency: &pb.Dependency{Path: dep.Path, Version: dep.Version, Group: cmp.Or(dep.Group, modfile.DefaultGroup)},
+			Index:      int32(i + 1),
+			Total:      int32(len(deps)),
+		}
+		size, err := vendorCopy(mod, v, ignore, false)
+		if err != nil {
+			if !req.KeepGoing {
+				return err
+			}
+			progress.Error = status.Convert(err).Message()
+			summary.Failed++
+		} else {
+			progress.Dependency.CachePath = v.dst
+			progress.Bytes = size
+			summary.Vendored++
+			summary.Bytes += size
+		}
+		if err := stream.Send(progress); err != nil {
+			return err
+		}
+		if progress.Error == "" {
+			copied = append(copied, v)
+			if err := writeVendoredList(dir, mod, copied); err != nil {
+				return status.Errorf(codes.Internal, "write %s: %v", vendoredListFile, err)
+			}
+		}
+	}
+	return stream.Send(&pb.VendorProgress{Summary: summary})
+}
+
+// vendorItem is a dependency to vendor and the directory it goes to.
+type vendorItem struct {
+	dep modfile.Require
+	dst string
+}
+
+// vendoring returns the holon.mod in dir, restricted to group, the
+// dependencies to vendor and its vendor ignore list. Those are the build
+// list of its vendorable requires, each at the version the graph selects,
+// as holon.lock records it while it matches holon.mod: its requires first,
+// in order, then their dependencies. A dependency
+// selected at several major versions has each vendored side by side, one
+// directory per major. It fails with a not cached error, along with the
+// rest, if a dependency to vendor is not cached: every one must be before
+// any vendored copy is removed.
+func (s *Server) vendoring(dir, group string) (*modfile.ModFile, []vendorItem, vendorIgnore, error) {
+	modPath := filepath.Join(dir, "holon.mod")
+	full, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, nil, nil, modError(modPath, err)
+	}
+	mod, err := groupMod(full, group)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ignore, err := readVendorIgnore(dir)
+	if err != nil {
+		return nil, nil, nil, status.Errorf(codes.FailedPrecondition, "read %s: %v", vendorIgnoreFile, err)
+	}
+	lock, err := modfile.ParseLock(filepath.Join(dir, "holon.lock"))
+	if err != nil {
+		return nil, nil, nil, status.Errorf(codes.FailedPrecondition, "parse holon.lock: %v", err)
+	}
+	matches := lock.Matches(full, cmp.Or(full.Resolve, modfile.StrategyMVS), lock.With)
+	list := lockedGraph(lock, mod)
+	if !matches {
+		list = s.buildList(dir, mod, func(r modfile.Require) bool { return vendorable(mod, r) })
+	}
+	selected := map[selKey]string{}
+	for _, sel := range list.Selected {
+		selected[majorKey(sel.Path, sel.Version)] = sel.Version
 	}
-
-	modPath := filepath.Join(dir, "holon.mod")
-	if _, err := os.Stat(modPath); err == nil {
-		return nil, status.Errorf(codes.AlreadyExists, "holon.mod already exists in %s", dir)
+	// Without a matching holon.lock, the versions it records still raise
+	// those of minimal version selection: Pull resolved the graph with the
+	// strategy of holon.mod, which may select higher ones.
+	for _, sel := range lock.Selected {
+		if key := majorKey(sel.Path, sel.Version); !matches && selected[key] != "" && semver.Compare(sel.Version, selected[key]) > 0 {
+			selected[key] = sel.Version
+		}
 	}
-
-	mod := &modfile.ModFile{HolonPath: holonPath}
-	if err := mod.Write(modPath); err != nil {
-		return nil, status.Errorf(codes.Internal, "write holon.mod: %v", err)
+	coexisting := list.Coexisting()
+
+	var deps []vendorItem
+	add := func(dep modfile.Require) {
+		key := majorKey(dep.Path, dep.Version)
+		version, ok := selected[key]
+		if !ok {
+			return
+		}
+		delete(selected, key) // vendored once
+		dep.Version = version
+		dst := vendoredDir(dir, mod, dep.Path)
+		if coexisting[dep.Path] {
+			dst = vendoredMajorDir(dir, mod, dep.Path, dep.Version)
+		}
+		deps = append(deps, vendorItem{dep, dst})
+	}
+	for _, dep := range mod.Require {
+		add(dep)
+	}
+	for _, sel := range list.Selected {
+		add(modfile.Require{Path: sel.Path, Version: sel.Version})
 	}
 
-	return &pb.InitResponse{ModFile: modPath}, nil
+	var missing []string
+	for _, v := range deps {
+		if !inCache(mod.SourcePath(v.dep.Path), v.dep.Version) {
+			missing = append(missing, v.dep.Path+"@"+v.dep.Version)
+		}
+	}
+	if len(missing) > 0 {
+		return mod, deps, ignore, notCachedError(missing)
+	}
+	return mod, deps, ignore, nil
 }
 
-// Add adds a dependency to holon.mod and fetches it to the cache.
-func (s *Server) Add(_ context.Context, req *pb.AddRequest) (*pb.AddResponse, error) {
-	dir := req.Directory
-	if dir == "" {
-		dir = "."
-	}
+// vendorable reports whether dep, required by mod or one of its
+// dependencies, is vendored: it is not replaced by a local directory, nor
+// optional and not pulled.
+func vendorable(mod *modfile.ModFile, dep modfile.Require) bool {
+	return mod.ResolvedPath(dep.Path) == "" && !(dep.Optional && !inCache(mod.SourcePath(dep.Path), dep.Version))
+}
 
-	modPath := filepath.Join(dir, "holon.mod")
-	mod, err := modfile.Parse(modPath)
+// clearVendorDir removes the vendored copies of the holon in dir, and the
+// list of them, or with a plan only lists them in it. The undo journal,
+// history log and other dot-prefixed entries are atlas state, not
+// vendored dependencies.
+func clearVendorDir(dir string, mod *modfile.ModFile, plan *pb.Plan) {
+	vendorDir := filepath.Join(dir, cmp.Or(mod.VendorDir, modfile.DefaultVendorDir))
+	des, err := os.ReadDir(vendorDir)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "parse holon.mod: %v", err)
+		return
 	}
-
-	mod.AddRequire(req.Path, req.Version)
-
-	if err := mod.Write(modPath); err != nil {
-		return nil, status.Errorf(codes.Internal, "write holon.mod: %v", err)
+	for _, de := range des {
+		if de.Name() != vendoredListFile && (strings.HasPrefix(de.Name(), ".") || de.Name() == filepath.Base(historyPath(dir))) {
+			continue
+		}
+		stale := filepath.Join(vendorDir, de.Name())
+		if plan != nil {
+			plan.Delete = append(plan.Delete, stale)
+			continue
+		}
+		os.RemoveAll(stale) //nolint:errcheck
 	}
+}
 
-	// Fetch immediately
-	cachePath, err := fetchToCache(req.Path, req.Version)
+// vendorCopy copies the cached snapshot of the dependency of mod v names
+// to its directory, leaving out what ignore matches, and returns the
+// bytes copied. With dryRun, nothing is copied.
+func vendorCopy(mod *modfile.ModFile, v vendorItem, ignore vendorIgnore, dryRun bool) (int64, error) {
+	dep := v.dep
+	snapshot, err := cacheStore().Stat(mod.SourcePath(dep.Path), dep.Version)
 	if err != nil {
-		log.Printf("atlas: fetch %s@%s: %v (added to holon.mod, fetch deferred)", req.Path, req.Version, err)
-		cachePath = "" // not fatal — dependency is recorded
+		return 0, notCachedError([]string{dep.Path + "@" + dep.Version})
 	}
 
-	// Update holon.sum
-	if cachePath != "" {
-		sumPath := filepath.Join(dir, "holon.sum")
-		sum, _ := modfile.ParseSum(sumPath)
-		hash, _ := hashDir(cachePath)
-		if hash != "" {
-			sum.Set(req.Path, req.Version, "h1:"+hash)
+	size := snapshot.Size
+	if len(ignore) > 0 {
+		if size, err = ignore.size(snapshot.Dir); err != nil {
+			return 0, status.Errorf(codes.Internal, "size %s: %v", dep.Path, err)
 		}
-		holonMDHash, _ := hashFile(filepath.Join(cachePath, "HOLON.md"))
-		if holonMDHash != "" {
-			sum.Set(req.Path, req.Version+"/HOLON.md", "h1:"+holonMDHash)
+	}
+	if !dryRun {
+		if err := copyDir(snapshot.Dir, v.dst, ignore); err != nil {
+			return 0, status.Errorf(codes.Internal, "vendor %s: %v", dep.Path, err)
 		}
-		sum.Write(sumPath) //nolint:errcheck
 	}
-
-	return &pb.AddResponse{
-		Dependency: &pb.Dependency{
-			Path:      req.Path,
-			Version:   req.Version,
-			CachePath: cachePath,
-		},
-	}, nil
+	return size, nil
 }
 
-// Remove removes a dependency from holon.mod.
-func (s *Server) Remove(_ context.Context, req *pb.RemoveRequest) (*pb.RemoveResponse, error) {
-	dir := req.Directory
-	if dir == "" {
-		dir = "."
+// CleanCache purges the global holon cache directory. With req.DryRun
+// nothing is deleted; the response carries the plan instead.
+func (s *Server) CleanCache(_ context.Context, req *pb.CleanCacheRequest) (_ *pb.CleanCacheResponse, err error) {
+	defer s.record("CleanCache", "", &err)
+
+	cacheDir := CacheDir()
+	if req.DryRun {
+		entries, err := cacheStore().List()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "list cache: %v", err)
+		}
+		plan := &pb.Plan{}
+		for _, e := range entries {
+			plan.Delete = append(plan.Delete, e.Dir)
+			plan.Bytes += e.Size
+		}
+		return &pb.CleanCacheResponse{CachePath: cacheDir, Plan: plan}, nil
+	}
+	entries, _ := cacheStore().List()
+	if err := cacheStore().Clear(); err != nil {
+		return nil, status.Errorf(codes.Internal, "purge cache: %v", err)
+	}
+	ev := &pb.Event{Kind: pb.EventKind_EVENT_KIND_CACHE_CLEANED, Entries: int32(len(entries))}
+	for _, e := range entries {
+		ev.Bytes += e.Size
 	}
+	s.events.publish(ev)
+	return &pb.CleanCacheResponse{CachePath: cacheDir}, nil
+}
+
+// Describe parses the HOLON.md front-matter of the holon in the request
+// directory or, when a path is given, of that dependency: from its replace
+// target if one is active, otherwise from the cache.
+func (s *Server) Describe(_ context.Context, req *pb.DescribeRequest) (*pb.DescribeResponse, error) {
+	dir := holonDir(req.Directory)
 
 	modPath := filepath.Join(dir, "holon.mod")
-	mod, err := modfile.Parse(modPath)
+	mod, err := s.parseMod(modPath)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "parse holon.mod: %v", err)
+		return nil, modError(modPath, err)
 	}
 
-	if !mod.RemoveRequire(req.Path) {
-		return nil, status.Errorf(codes.NotFound, "dependency %q not found in holon.mod", req.Path)
+	holonDir, path, version := dir, mod.HolonPath, ""
+	if req.Path != "" {
+		path, version = req.Path, req.Version
+		dep, required := mod.RequireByName(req.Path)
+		if required {
+			path = dep.Path
+			if version == "" {
+				version = dep.Version
+			}
+		}
+		if version == "" {
+			return nil, unknownDependencyError(modPath, req.Path)
+		}
+
+		holonDir = dependencyDir(dir, mod, path, version)
 	}
 
-	if err := mod.Write(modPath); err != nil {
-		return nil, status.Errorf(codes.Internal, "write holon.mod: %v", err)
+	fm, err := holonmd.Parse(filepath.Join(holonDir, "HOLON.md"))
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "read HOLON.md of %s: %v", path, err)
 	}
 
-	return &pb.RemoveResponse{}, nil
+	desc := &pb.HolonDescription{
+		Path:         path,
+		Version:      version,
+		Name:         fm.Name,
+		Summary:      fm.Summary,
+		Capabilities: fm.Capabilities,
+		Maintainers:  fm.Maintainers,
+		Owner:        fm.Owner,
+		Contact:      fm.Contact,
+	}
+	if version != "" {
+		desc.Fetch = fetchInfoProto(mod.SourcePath(path), version)
+		if stmt := readProvenance(path, version); stmt != nil {
+			desc.Provenance = &pb.Provenance{
+				Builder:  stmt.Builder(),
+				Source:   stmt.Source(),
+				Commit:   stmt.Commit(),
+				Workflow: stmt.Workflow(),
+			}
+		}
+	}
+	return &pb.DescribeResponse{Holon: desc}, nil
 }
 
-// Pull fetches all dependencies to the cache and updates holon.sum.
-func (s *Server) Pull(_ context.Context, req *pb.PullRequest) (*pb.PullResponse, error) {
-	dir := req.Directory
-	if dir == "" {
-		dir = "."
+// FindCapability scans the HOLON.md of every holon in the dependency graph
+// for the requested capability. Holons whose HOLON.md is unavailable are
+// skipped.
+func (s *Server) FindCapability(_ context.Context, req *pb.FindCapabilityRequest) (*pb.FindCapabilityResponse, error) {
+	dir := holonDir(req.Directory)
+	if req.Capability == "" {
+		return nil, status.Error(codes.InvalidArgument, "capability is required")
 	}
 
 	modPath := filepath.Join(dir, "holon.mod")
-	mod, err := modfile.Parse(modPath)
+	mod, err := s.parseMod(modPath)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "parse holon.mod: %v", err)
+		return nil, modError(modPath, err)
 	}
 
-	sumPath := filepath.Join(dir, "holon.sum")
-	sum, _ := modfile.ParseSum(sumPath)
-
-	var fetched []*pb.Dependency
-	for _, req := range mod.Require {
-		// Skip replaced dependencies
-		if mod.ResolvedPath(req.Path) != "" {
+	resp := &pb.FindCapabilityResponse{}
+	seen := map[string]bool{}
+	for _, edge := range s.graphEdges(dir, mod, nil) {
+		key := edge.To + "@" + edge.Version
+		if seen[key] {
 			continue
 		}
+		seen[key] = true
 
-		cachePath, err := fetchToCache(req.Path, req.Version)
+		holonDir := dependencyDir(dir, mod, edge.To, edge.Version)
+		fm, err := holonmd.Parse(filepath.Join(holonDir, "HOLON.md"))
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "fetch %s@%s: %v", req.Path, req.Version, err)
-		}
-
-		hash, _ := hashDir(cachePath)
-		if hash != "" {
-			sum.Set(req.Path, req.Version, "h1:"+hash)
+			continue
 		}
-		holonMDHash, _ := hashFile(filepath.Join(cachePath, "HOLON.md"))
-		if holonMDHash != "" {
-			sum.Set(req.Path, req.Version+"/HOLON.md", "h1:"+holonMDHash)
+		for _, c := range fm.Capabilities {
+			if c == req.Capability {
+				resp.Providers = append(resp.Providers, &pb.Dependency{
+					Path:      edge.To,
+					Version:   edge.Version,
+					CachePath: holonDir,
+				})
+				break
+			}
 		}
-
-		fetched = append(fetched, &pb.Dependency{
-			Path:      req.Path,
-			Version:   req.Version,
-			CachePath: cachePath,
-		})
-	}
-
-	if err := sum.Write(sumPath); err != nil {
-		return nil, status.Errorf(codes.Internal, "write holon.sum: %v", err)
 	}
-
-	return &pb.PullResponse{Fetched: fetched}, nil
+	return resp, nil
 }
 
-// Verify checks holon.sum integrity against cached content.
-func (s *Server) Verify(_ context.Context, req *pb.VerifyRequest) (*pb.VerifyResponse, error) {
-	dir := req.Directory
-	if dir == "" {
-		dir = "."
-	}
+// Owners reads the owner, maintainers and contact from the HOLON.md of
+// every holon in the dependency graph, or only of req.Path. Holons whose
+// HOLON.md is unavailable are listed without them, so that nothing is
+// silently left out.
+func (s *Server) Owners(_ context.Context, req *pb.OwnersRequest) (*pb.OwnersResponse, error) {
+	dir := holonDir(req.Directory)
 
-	sumPath := filepath.Join(dir, "holon.sum")
-	sum, err := modfile.ParseSum(sumPath)
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "parse holon.sum: %v", err)
+		return nil, modError(modPath, err)
 	}
-
-	// Also check for active replaces
-	modPath := filepath.Join(dir, "holon.mod")
-	mod, _ := modfile.Parse(modPath)
-
-	var errors []string
-
-	if mod != nil && len(mod.Replace) > 0 {
-		for _, r := range mod.Replace {
-			errors = append(errors, fmt.Sprintf("WARNING: active replace %s => %s", r.Old, r.LocalPath))
-		}
+	want := req.Path
+	if dep, ok := mod.RequireByName(req.Path); ok {
+		want = dep.Path
 	}
 
-	for _, entry := range sum.Entries {
-		// Extract base version (strip /HOLON.md suffix)
-		version := entry.Version
-		isHolonMD := strings.HasSuffix(version, "/HOLON.md")
-		if isHolonMD {
-			version = strings.TrimSuffix(version, "/HOLON.md")
+	resp := &pb.OwnersResponse{}
+	seen := map[string]bool{}
+	for _, edge := range s.graphEdges(dir, mod, nil) {
+		key := edge.To + "@" + edge.Version
+		if seen[key] || want != "" && edge.To != want {
+			continue
 		}
+		seen[key] = true
 
-		cachePath := cachePathFor(entry.Path, version)
-
-		var currentHash string
-		if isHolonMD {
-			currentHash, _ = hashFile(filepath.Join(cachePath, "HOLON.md"))
-		} else {
-			currentHash, _ = hashDir(cachePath)
+		h := &pb.HolonOwners{Path: edge.To, Version: edge.Version}
+		if edge.From == mod.HolonPath {
+			h.LocalOwner = edge.Metadata["owner"]
 		}
-
-		if currentHash == "" {
-			errors = append(errors, fmt.Sprintf("%s %s: not in cache", entry.Path, entry.Version))
-		} else if "h1:"+currentHash != entry.Hash {
-			errors = append(errors, fmt.Sprintf("%s %s: hash mismatch (want %s, got h1:%s)",
-				entry.Path, entry.Version, entry.Hash, currentHash))
+		if fm, err := holonmd.Parse(filepath.Join(dependencyDir(dir, mod, edge.To, edge.Version), "HOLON.md")); err == nil {
+			h.Owner, h.Maintainers, h.Contact = fm.Owner, fm.Maintainers, fm.Contact
 		}
+		resp.Holons = append(resp.Holons, h)
 	}
-
-	return &pb.VerifyResponse{
-		Ok:     len(errors) == 0,
-		Errors: errors,
-	}, nil
+	if want != "" && len(resp.Holons) == 0 {
+		return nil, unknownDependencyError(modPath, req.Path)
+	}
+	return resp, nil
 }
 
-// Graph returns the dependency tree.
-func (s *Server) Graph(_ context.Context, req *pb.GraphRequest) (*pb.GraphResponse, error) {
-	dir := req.Directory
-	if dir == "" {
-		dir = "."
+// CacheList returns one page of the global cache entries.
+func (s *Server) CacheList(_ context.Context, req *pb.CacheListRequest) (*pb.CacheListResponse, error) {
+	entries, err := ListCache()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list cache: %v", err)
 	}
 
-	modPath := filepath.Join(dir, "holon.mod")
-	mod, err := modfile.Parse(modPath)
+	// NUL sorts before any path byte, so keys order like (path, version).
+	page, next, err := paginate(entries, func(e CacheEntry) string {
+		return e.Path + "\x00" + e.Version
+	}, req.PageSize, req.PageToken)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "parse holon.mod: %v", err)
+		return nil, err
 	}
 
-	var edges []*pb.Edge
-	for _, req := range mod.Require {
-		edges = append(edges, &pb.Edge{
-			From:    mod.HolonPath,
-			To:      req.Path,
-			Version: req.Version,
+	resp := &pb.CacheListResponse{NextPageToken: next}
+	for _, e := range page {
+		resp.Entries = append(resp.Entries, &pb.CacheEntry{
+			Path:      e.Path,
+			Version:   e.Version,
+			CachePath: e.Dir,
+			Size:      e.Size,
+			Fetch:     fetchInfoProto(e.Path, e.Version),
 		})
-
-		// Recurse into cached dependencies
-		cachePath := cachePathFor(req.Path, req.Version)
-		subModPath := filepath.Join(cachePath, "holon.mod")
-		if subMod, err := modfile.Parse(subModPath); err == nil {
-			for _, sub := range subMod.Require {
-				edges = append(edges, &pb.Edge{
-					From:    req.Path,
-					To:      sub.Path,
-					Version: sub.Version,
-				})
-			}
-		}
 	}
-
-	return &pb.GraphResponse{
-		Root:  mod.HolonPath,
-		Edges: edges,
-	}, nil
+	return resp, nil
 }
 
-// Update checks remote git tags for each dependency and updates to the
-// latest compatible semver version. Follows Minimum Version Selection:
-// the latest tag that shares the same major version.
-func (s *Server) Update(_ context.Context, req *pb.UpdateRequest) (*pb.UpdateResponse, error) {
-	dir := req.Directory
-	if dir == "" {
-		dir = "."
+// CacheOrphans cross-references the global cache against the holon.sum of
+// every requested directory and of every holon discovered under the
+// request root, and returns the entries none of them records, deleting
+// them if asked. Each directory must hold a holon.mod, so that a mistyped
+// one does not orphan the whole cache.
+func (s *Server) CacheOrphans(_ context.Context, req *pb.CacheOrphansRequest) (_ *pb.CacheOrphansResponse, err error) {
+	defer s.record("CacheOrphans", "", &err)
+
+	dirs := append([]string(nil), req.Directories...)
+	if req.Root != "" {
+		found, err := discoverHolons(req.Root)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "discover holons under %s: %v", req.Root, err)
+		}
+		dirs = append(dirs, found...)
 	}
-
-	modPath := filepath.Join(dir, "holon.mod")
-	mod, err := modfile.Parse(modPath)
-	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "parse holon.mod: %v", err)
+	if len(dirs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "directories or root is required")
 	}
 
-	var updated []*pb.UpdatedDependency
-	for i, dep := range mod.Require {
-		// Skip replaced dependencies
-		if mod.ResolvedPath(dep.Path) != "" {
-			continue
+	resp := &pb.CacheOrphansResponse{}
+	referenced := map[string]bool{}
+	for _, dir := range dirs {
+		modPath := filepath.Join(dir, "holon.mod")
+		if _, err := s.parseMod(modPath); err != nil {
+			return nil, modError(modPath, err)
 		}
-
-		latest, err := latestCompatibleTag(dep.Path, dep.Version)
+		sumPath := filepath.Join(dir, "holon.sum")
+		sum, err := s.parseSum(sumPath)
 		if err != nil {
-			log.Printf("atlas update: %s: %v (skipped)", dep.Path, err)
-			continue
+			return nil, sumError(sumPath, err)
 		}
-		if latest == dep.Version {
-			continue
+		resp.SumFiles = append(resp.SumFiles, sumPath)
+		// Entries for holon.mod, HOLON.md or the commit of a version
+		// reference that version too.
+		for _, e := range sum.Entries {
+			version, _, _ := strings.Cut(e.Version, "/")
+			referenced[e.Path+"@"+version] = true
 		}
+	}
 
-		// Remove old cache entry, fetch new
-		oldCache := cachePathFor(dep.Path, dep.Version)
-		os.RemoveAll(oldCache) //nolint:errcheck
-
-		mod.Require[i].Version = latest
-		updated = append(updated, &pb.UpdatedDependency{
-			Path:       dep.Path,
-			OldVersion: dep.Version,
-			NewVersion: latest,
+	entries, err := ListCache()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list cache: %v", err)
+	}
+	for _, e := range entries {
+		if referenced[e.Path+"@"+e.Version] {
+			continue
+		}
+		resp.Orphans = append(resp.Orphans, &pb.CacheEntry{
+			Path:      e.Path,
+			Version:   e.Version,
+			CachePath: e.Dir,
+			Size:      e.Size,
+			Fetch:     fetchInfoProto(e.Path, e.Version),
 		})
+		resp.Bytes += e.Size
 	}
 
-	if len(updated) > 0 {
-		if err := mod.Write(modPath); err != nil {
-			return nil, status.Errorf(codes.Internal, "write holon.mod: %v", err)
+	if req.Delete && len(resp.Orphans) > 0 {
+		for _, e := range resp.Orphans {
+			removeFromCache(e.Path, e.Version)
 		}
+		s.events.publish(&pb.Event{Kind: pb.EventKind_EVENT_KIND_CACHE_CLEANED, Entries: int32(len(resp.Orphans)), Bytes: resp.Bytes})
 	}
-
-	return &pb.UpdateResponse{Updated: updated}, nil
+	return resp, nil
 }
 
-// Vendor copies all cached dependencies to a local .holon/ directory
-// next to holon.mod. If .holon/ exists, it is recreated.
-func (s *Server) Vendor(_ context.Context, req *pb.VendorRequest) (*pb.VendorResponse, error) {
-	dir := req.Directory
+// --- helpers ---
+
+// holonDir returns the directory of the holon a request for dir, the
+// current directory when empty, is about: dir itself if it holds a
+// holon.mod, else, as the go command does, its nearest parent holding one,
+// as an absolute path. Without one, or when dir is not a directory, dir
+// is returned, so that reading holon.mod fails there.
+func holonDir(dir string) string {
 	if dir == "" {
 		dir = "."
 	}
-
-	modPath := filepath.Join(dir, "holon.mod")
-	mod, err := modfile.Parse(modPath)
+	abs, err := filepath.Abs(dir)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "parse holon.mod: %v", err)
+		return dir
 	}
-
-	vendorDir := filepath.Join(dir, ".holon")
-	// Clean existing vendor directory
-	os.RemoveAll(vendorDir) //nolint:errcheck
-
-	var vendored []*pb.Dependency
-	for _, dep := range mod.Require {
-		// Skip replaced dependencies
-		if mod.ResolvedPath(dep.Path) != "" {
-			continue
-		}
-
-		src := cachePathFor(dep.Path, dep.Version)
-		if _, err := os.Stat(src); os.IsNotExist(err) {
-			return nil, status.Errorf(codes.FailedPrecondition,
-				"%s@%s not in cache — run 'atlas pull' first", dep.Path, dep.Version)
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return dir
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "holon.mod")); err == nil {
+			if d == abs {
+				return dir
+			}
+			return d
 		}
-
-		// Destination: .holon/<last-path-component>/
-		name := filepath.Base(dep.Path)
-		dst := filepath.Join(vendorDir, name)
-
-		if err := copyDir(src, dst); err != nil {
-			return nil, status.Errorf(codes.Internal, "vendor %s: %v", dep.Path, err)
+		if filepath.Dir(d) == d {
+			return dir
 		}
-
-		vendored = append(vendored, &pb.Dependency{
-			Path:      dep.Path,
-			Version:   dep.Version,
-			CachePath: dst,
-		})
 	}
-
-	return &pb.VendorResponse{Vendored: vendored}, nil
 }
 
-// CleanCache purges the global holon cache directory.
-func (s *Server) CleanCache(_ context.Context, _ *pb.CleanCacheRequest) (*pb.CleanCacheResponse, error) {
-	cacheDir := CacheDir()
-	if err := os.RemoveAll(cacheDir); err != nil {
-		return nil, status.Errorf(codes.Internal, "purge cache: %v", err)
+// dependencyDir returns where the sources of path@version live: the
+// replace target when mod replaces that required version, else the cache,
+// else the vendored copy of that required version.
+func dependencyDir(dir string, mod *modfile.ModFile, path, version string) string {
+	if local := mod.ResolvedPath(path); local != "" {
+		if dep, ok := mod.RequireByName(path); ok && dep.Version == version {
+			return localPath(dir, local)
+		}
 	}
-	return &pb.CleanCacheResponse{CachePath: cacheDir}, nil
+	src := mod.SourcePath(path)
+	if cached, err := cacheStore().Get(src, version); err == nil {
+		return cached
+	}
+	if vendored := vendoredCopy(dir, mod, src, version); vendored != "" {
+		return vendored
+	}
+	return CachePath(src, version)
 }
 
-// --- helpers ---
-
-// cachePathFor returns the cache directory for a dependency.
-func cachePathFor(depPath, version string) string {
-	return filepath.Join(CacheDir(), depPath+"@"+version)
+// vendoredDir returns where Vendor copies the dependency at path of the
+// holon.mod in dir: <vendor dir>/<last path element>.
+func vendoredDir(dir string, mod *modfile.ModFile, path string) string {
+	return filepath.Join(dir, cmp.Or(mod.VendorDir, modfile.DefaultVendorDir), filepath.Base(path))
 }
 
-// fetchToCache clones/fetches a holon to the global cache.
-func fetchToCache(depPath, version string) (string, error) {
-	cachePath := cachePathFor(depPath, version)
-
-	// Already cached?
-	if info, err := os.Stat(cachePath); err == nil && info.IsDir() {
-		return cachePath, nil
-	}
-
-	// Construct git URL from path
-	gitURL := "https://" + depPath + ".git"
+// vendoredMajorDir returns where Vendor copies the dependency at path when
+// the graph selects several of its major versions:
+// <vendor dir>/<last path element>@v<major>.
+func vendoredMajorDir(dir string, mod *modfile.ModFile, path, version string) string {
+	return fmt.Sprintf("%s@v%d", vendoredDir(dir, mod, path), majorKey(path, version).major)
+}
 
-	// Clone at the specific tag
-	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
-		return "", fmt.Errorf("create cache dir: %w", err)
+// vendoredListFile names the file of the vendor directory listing what
+// Vendor copied there, one "<source path> <version> <directory>" line per
+// dependency, the directory relative to the vendor directory.
+const vendoredListFile = ".vendored"
+
+// writeVendoredList records in the vendor directory of the holon in dir
+// what was vendored, deps.
+func writeVendoredList(dir string, mod *modfile.ModFile, deps []vendorItem) error {
+	vendorDir := filepath.Join(dir, cmp.Or(mod.VendorDir, modfile.DefaultVendorDir))
+	var b strings.Builder
+	for _, v := range deps {
+		fmt.Fprintf(&b, "%s %s %s\n", mod.SourcePath(v.dep.Path), v.dep.Version, filepath.Base(v.dst))
 	}
-
-	cmd := exec.Command("git", "clone", "--depth=1", "--branch", version, gitURL, cachePath)
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		// Try without .git suffix
-		gitURL = "https://" + depPath
-		cmd = exec.Command("git", "clone", "--depth=1", "--branch", version, gitURL, cachePath)
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			return "", fmt.Errorf("git clone %s@%s: %w", depPath, version, err)
+	if b.Len() == 0 {
+		err := os.Remove(filepath.Join(vendorDir, vendoredListFile))
+		if os.IsNotExist(err) {
+			return nil
 		}
+		return err
 	}
-
-	// Remove .git directory — cache is read-only snapshots
-	os.RemoveAll(filepath.Join(cachePath, ".git")) //nolint:errcheck
-
-	return cachePath, nil
+	if err := os.MkdirAll(vendorDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(vendorDir, vendoredListFile), []byte(b.String()), 0o644)
 }
 
-// hashDir computes SHA-256 of all files in a directory.
-func hashDir(dir string) (string, error) {
-	h := sha256.New()
-	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+// vendoredCopy returns the vendored copy of the dependency of mod fetched
+// as src@version, or "" if there is none. The list Vendor writes says
+// which version each copy holds; without one, the copies of the requires
+// of mod are taken to hold the version required.
+func vendoredCopy(dir string, mod *modfile.ModFile, src, version string) string {
+	vendorDir := filepath.Join(dir, cmp.Or(mod.VendorDir, modfile.DefaultVendorDir))
+	if data, err := os.ReadFile(filepath.Join(vendorDir, vendoredListFile)); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if f := strings.Fields(line); len(f) == 3 && f[0] == src && f[1] == version {
+				vendored := filepath.Join(vendorDir, f[2])
+				if _, err := os.Stat(vendored); err == nil {
+					return vendored
+				}
+			}
 		}
-		if d.IsDir() {
-			return nil
+		return ""
+	}
+	for _, r := range mod.Require {
+		if r.Version != version || mod.SourcePath(r.Path) != src {
+			continue
 		}
-		// Write relative path for reproducibility
-		rel, _ := filepath.Rel(dir, path)
-		h.Write([]byte(rel))
-
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return err
+		for _, vendored := range []string{vendoredDir(dir, mod, r.Path), vendoredMajorDir(dir, mod, r.Path, version)} {
+			if _, err := os.Stat(vendored); err == nil {
+				return vendored
+			}
 		}
-		h.Write(data)
-		return nil
-	})
-	if err != nil {
-		return "", err
 	}
-	return hex.EncodeToString(h.Sum(nil)), nil
+	// A major version coexisting with another may be required indirectly.
+	vendored := vendoredMajorDir(dir, mod, src, version)
+	if _, err := os.Stat(vendored); err == nil {
+		return vendored
+	}
+	return ""
 }
 
-// hashFile computes SHA-256 of a single file.
-func hashFile(path string) (string, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return "", err
+// groupMod returns mod requiring only the dependencies of group, or mod
+// itself when group is empty.
+func groupMod(mod *modfile.ModFile, group string) (*modfile.ModFile, error) {
+	if group == "" {
+		return mod, nil
+	}
+	if !slices.Contains(mod.Groups(), group) {
+		return nil, status.Errorf(codes.InvalidArgument, "holon.mod has no require group %q (groups: %s)",
+			group, strings.Join(mod.Groups(), ", "))
 	}
-	h := sha256.Sum256(data)
-	return hex.EncodeToString(h[:]), nil
+	return mod.OnlyGroup(group), nil
 }
 
-// latestCompatibleTag queries remote git tags and returns the latest
-// version sharing the same major version (MVS-compatible).
-func latestCompatibleTag(depPath, currentVersion string) (string, error) {
-	gitURL := "https://" + depPath + ".git"
+// localPath resolves a replace target relative to the holon.mod directory.
+func localPath(dir, target string) string {
+	if filepath.IsAbs(target) {
+		return target
+	}
+	return filepath.Join(dir, target)
+}
+
+// latestCompatibleTag returns the highest tag of depPath that shares the
+// major version of currentVersion. Prerelease tags are only candidates
+// when they belong to channel (e.g. "beta"); currentVersion is returned
+// when nothing newer qualifies, and for a pseudo-version: the commit it
+// pins may be newer than every tag.
+func latestCompatibleTag(ctx context.Context, depPath, currentVersion, channel string) (string, error) {
+	if _, _, _, ok := semver.Parse(currentVersion); !ok || semver.IsPseudo(currentVersion) {
+		return currentVersion, nil
+	}
 
-	cmd := exec.Command("git", "ls-remote", "--tags", "--refs", gitURL)
-	out, err := cmd.Output()
+	tags, err := remoteTags(ctx, depPath)
 	if err != nil {
-		// Try without .git suffix
-		gitURL = "https://" + depPath
-		cmd = exec.Command("git", "ls-remote", "--tags", "--refs", gitURL)
-		out, err = cmd.Output()
-		if err != nil {
-			return "", fmt.Errorf("ls-remote %s: %w", depPath, err)
-		}
+		return "", err
 	}
+	return compatibleTag(tags, currentVersion, channel), nil
+}
 
-	currentMajor, _, _, ok := parseSemver(currentVersion)
+// compatibleTag is latestCompatibleTag among tags.
+func compatibleTag(tags []string, currentVersion, channel string) string {
+	currentMajor, _, _, ok := semver.Parse(currentVersion)
 	if !ok {
-		return currentVersion, nil
+		return currentVersion
 	}
-
-	// Collect compatible tags (same major version)
-	var candidates []string
-	for _, line := range strings.Split(string(out), "\n") {
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
+	latest := currentVersion
+	for _, tag := range tags {
+		major, _, _, ok := semver.Parse(tag)
+		if !ok || major != currentMajor {
+			continue
+		}
+		if c := semver.Channel(tag); c != "" && c != channel {
 			continue
 		}
-		ref := parts[1]
-		tag := strings.TrimPrefix(ref, "refs/tags/")
-		major, _, _, ok := parseSemver(tag)
-		if ok && major == currentMajor {
-			candidates = append(candidates, tag)
+		if semver.Compare(tag, latest) > 0 {
+			latest = tag
 		}
 	}
-
-	if len(candidates) == 0 {
-		return currentVersion, nil
-	}
-
-	sort.Slice(candidates, func(i, j int) bool {
-		return compareSemver(candidates[i], candidates[j]) < 0
-	})
-
-	return candidates[len(candidates)-1], nil
+	return latest
 }
 
-// parseSemver extracts major, minor, patch from "vM.N.P".
-func parseSemver(v string) (major, minor, patch int, ok bool) {
-	v = strings.TrimPrefix(v, "v")
-	parts := strings.SplitN(v, ".", 3)
-	if len(parts) != 3 {
-		return 0, 0, 0, false
+// latestChannelTag returns the highest prerelease tag of depPath in
+// channel, across all major versions.
+func latestChannelTag(ctx context.Context, depPath, channel string) (string, error) {
+	tags, err := remoteTags(ctx, depPath)
+	if err != nil {
+		return "", err
 	}
-	_, err1 := fmt.Sscan(parts[0], &major)
-	_, err2 := fmt.Sscan(parts[1], &minor)
-	_, err3 := fmt.Sscan(parts[2], &patch)
-	return major, minor, patch, err1 == nil && err2 == nil && err3 == nil
-}
 
-// compareSemver returns -1, 0, or 1.
-func compareSemver(a, b string) int {
-	ma, mia, pa, _ := parseSemver(a)
-	mb, mib, pb, _ := parseSemver(b)
-	if ma != mb {
-		return ma - mb
+	var latest string
+	for _, tag := range tags {
+		if _, _, _, ok := semver.Parse(tag); !ok || semver.Channel(tag) != channel {
+			continue
+		}
+		if latest == "" || semver.Compare(tag, latest) > 0 {
+			latest = tag
+		}
 	}
-	if mia != mib {
-		return mia - mib
+	if latest == "" {
+		return "", fmt.Errorf("no %s tag for %s", channel, depPath)
 	}
-	return pa - pb
+	return latest, nil
 }
 
-// copyDir recursively copies src to dst.
-func copyDir(src, dst string) error {
+// copyDir recursively copies src to dst, except the paths ignore matches.
+func copyDir(src, dst string, ignore vendorIgnore) error {
 	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -560,6 +2138,12 @@ func copyDir(src, dst string) error {
 
 		rel, _ := filepath.Rel(src, path)
 		target := filepath.Join(dst, rel)
+		if rel != "." && ignore.match(filepath.ToSlash(rel), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 
 		if d.IsDir() {
 			return os.MkdirAll(target, 0o755)
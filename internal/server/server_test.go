@@ -1,20 +1,66 @@
 package server_test
 
 import (
+	"archive/tar"
+	"bytes"
+	"cmp"
+	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"math/big"
 	"net"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/organic-programming/go-holons/pkg/transport"
 	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
 	"github.com/organic-programming/rhizome-atlas/internal/server"
+	"github.com/organic-programming/rhizome-atlas/pkg/client"
+	"github.com/organic-programming/rhizome-atlas/pkg/fetch"
+	"github.com/organic-programming/rhizome-atlas/pkg/holonmd"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+	"github.com/organic-programming/rhizome-atlas/pkg/provenance"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+	"github.com/organic-programming/rhizome-atlas/pkg/tuf"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"nhooyr.io/websocket"
 )
 
@@ -47,11 +93,12 @@ func TestInitAddRemoveGraph(t *testing.T) {
 		t.Error("expected error for duplicate init")
 	}
 
-	// Add (local dep — will fail fetch but still record in holon.mod)
+	// Add (record only — the dep cannot be fetched)
 	addResp, err := srv.Add(ctx, &pb.AddRequest{
-		Directory: dir,
-		Path:      "github.com/test/fake-dep",
-		Version:   "v0.1.0",
+		Directory:  dir,
+		Path:       "github.com/test/fake-dep",
+		Version:    "v0.1.0",
+		RecordOnly: true,
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -91,208 +138,6417 @@ func TestInitAddRemoveGraph(t *testing.T) {
 	}
 }
 
-func TestVerifyEmpty(t *testing.T) {
+func TestAddRollsBackOnFetchFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ATLAS_PROXY", "")
 	dir := t.TempDir()
 	ctx := context.Background()
 	srv := &server.Server{}
 
-	// Init and verify (no deps = all ok)
-	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/h"}) //nolint:errcheck
-
-	resp, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir})
-	if err != nil {
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/txn"}); err != nil {
 		t.Fatal(err)
 	}
-	if !resp.Ok {
-		t.Errorf("verify should be ok with no deps, got errors: %v", resp.Errors)
+	before, _ := os.ReadFile(filepath.Join(dir, "holon.mod"))
+
+	_, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "atlas.invalid/test/missing", Version: "v0.1.0"})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("err = %v, want Unavailable", err)
+	}
+	after, _ := os.ReadFile(filepath.Join(dir, "holon.mod"))
+	if string(after) != string(before) {
+		t.Errorf("holon.mod changed by a failed Add:\n%s", after)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "holon.sum")); !os.IsNotExist(err) {
+		t.Errorf("holon.sum created by a failed Add: %v", err)
 	}
 }
 
-func TestVendorAndCleanCache(t *testing.T) {
+func TestUndo(t *testing.T) {
 	dir := t.TempDir()
 	ctx := context.Background()
 	srv := &server.Server{}
 
-	// Setup: init, add a real dep (go-holons has a v0.1.0 tag)
-	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/vendor"}) //nolint:errcheck
-	srv.Add(ctx, &pb.AddRequest{
-		Directory: dir,
-		Path:      "github.com/organic-programming/go-holons",
-		Version:   "v0.1.0",
-	}) //nolint:errcheck
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/undo"}); err != nil {
+		t.Fatal(err)
+	}
+	initial, _ := os.ReadFile(filepath.Join(dir, "holon.mod"))
+	_, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "github.com/test/dep", Version: "v0.1.0", RecordOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	// Vendor
-	vendorResp, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir})
+	resp, err := srv.Undo(ctx, &pb.UndoRequest{Directory: dir})
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(vendorResp.Vendored) != 1 {
-		t.Fatalf("vendored = %d, want 1", len(vendorResp.Vendored))
+	if resp.Method != "Add" {
+		t.Errorf("undid %q, want Add", resp.Method)
+	}
+	restored, _ := os.ReadFile(filepath.Join(dir, "holon.mod"))
+	if string(restored) != string(initial) {
+		t.Errorf("holon.mod after undo:\n%s\nwant:\n%s", restored, initial)
 	}
 
-	// Check .holon/go-holons/ exists
-	vendored := filepath.Join(dir, ".holon", "go-holons")
-	if _, err := os.Stat(vendored); os.IsNotExist(err) {
-		t.Error(".holon/go-holons/ not created")
+	// Undoing Init removes holon.mod; then there is nothing left to undo.
+	if _, err := srv.Undo(ctx, &pb.UndoRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "holon.mod")); !os.IsNotExist(err) {
+		t.Errorf("holon.mod still exists after undoing Init: %v", err)
+	}
+	if _, err := srv.Undo(ctx, &pb.UndoRequest{Directory: dir}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("err = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/history"}) //nolint:errcheck
+	for _, version := range []string{"v0.1.0", "v0.2.0"} {
+		_, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "github.com/test/dep", Version: version, RecordOnly: true})
+		if err != nil {
+			t.Fatal(err)
+		}
 	}
 
-	// Clean cache
-	cacheResp, err := srv.CleanCache(ctx, &pb.CleanCacheRequest{})
+	resp, err := srv.History(ctx, &pb.HistoryRequest{Directory: dir})
 	if err != nil {
 		t.Fatal(err)
 	}
-	if cacheResp.CachePath == "" {
-		t.Error("expected cache_path in response")
+	if len(resp.Entries) != 3 {
+		t.Fatalf("entries = %v, want 3", resp.Entries)
+	}
+	latest := resp.Entries[0]
+	if latest.Method != "Add" || len(latest.Changes) != 1 || latest.Changes[0] != "github.com/test/dep v0.1.0 → v0.2.0" {
+		t.Errorf("latest entry = %v", latest)
 	}
 
-	// Verify cache is gone
-	if _, err := os.Stat(server.CacheDir()); !os.IsNotExist(err) {
-		t.Error("cache dir should not exist after clean")
+	limited, _ := srv.History(ctx, &pb.HistoryRequest{Directory: dir, Limit: 1})
+	if len(limited.Entries) != 1 {
+		t.Errorf("limit 1 returned %d entries", len(limited.Entries))
 	}
 }
 
-func TestUpdateNoRemote(t *testing.T) {
+func TestDryRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
 	dir := t.TempDir()
 	ctx := context.Background()
 	srv := &server.Server{}
 
-	// Setup with a fake dep (no remote to query)
-	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/up"}) //nolint:errcheck
-	srv.Add(ctx, &pb.AddRequest{
-		Directory: dir,
-		Path:      "github.com/test/nonexistent",
-		Version:   "v0.1.0",
-	}) //nolint:errcheck
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/dryrun"})                                     //nolint:errcheck
+	srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "github.com/test/a", Version: "v0.1.0", RecordOnly: true}) //nolint:errcheck
+	modPath := filepath.Join(dir, "holon.mod")
+	before, _ := os.ReadFile(modPath)
 
-	// Update should not fail — just log and skip
-	resp, err := srv.Update(ctx, &pb.UpdateRequest{Directory: dir})
+	addResp, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "github.com/test/b", Version: "v1.0.0", DryRun: true})
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(resp.Updated) != 0 {
-		t.Errorf("expected 0 updates for unreachable dep, got %d", len(resp.Updated))
+	plan := addResp.Plan
+	if len(plan.Changes) != 1 || plan.Changes[0] != "+ github.com/test/b v1.0.0" {
+		t.Errorf("add changes = %v", plan.Changes)
+	}
+	if len(plan.Fetch) != 1 || plan.Fetch[0].Path != "github.com/test/b" {
+		t.Errorf("add fetch = %v", plan.Fetch)
+	}
+	if len(plan.Write) != 2 {
+		t.Errorf("add write = %v, want holon.mod and holon.sum", plan.Write)
+	}
+
+	removeResp, err := srv.Remove(ctx, &pb.RemoveRequest{Directory: dir, Path: "github.com/test/a", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := removeResp.Plan.Changes; len(changes) != 1 || changes[0] != "- github.com/test/a v0.1.0" {
+		t.Errorf("remove changes = %v", changes)
+	}
+
+	if after, _ := os.ReadFile(modPath); string(after) != string(before) {
+		t.Errorf("dry runs changed holon.mod:\n%s", after)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "holon.sum")); !os.IsNotExist(err) {
+		t.Error("dry run wrote holon.sum")
+	}
+
+	cached := server.CachePath("github.com/test/a", "v0.1.0")
+	os.MkdirAll(cached, 0o755)                                              //nolint:errcheck
+	os.WriteFile(filepath.Join(cached, "HOLON.md"), []byte("12345"), 0o644) //nolint:errcheck
+	cleanResp, err := srv.CleanCache(ctx, &pb.CleanCacheRequest{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cleanResp.Plan.Bytes != 5 || len(cleanResp.Plan.Delete) != 1 {
+		t.Errorf("clean plan = %v", cleanResp.Plan)
+	}
+	if _, err := os.Stat(cached); err != nil {
+		t.Error("dry run purged the cache")
 	}
 }
 
-// --- mem:// transport test ---
+func TestIdempotencyKey(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
 
-func TestMemTransport(t *testing.T) {
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/idem"})                                         //nolint:errcheck
+	srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "github.com/test/dep", Version: "v0.1.0", RecordOnly: true}) //nolint:errcheck
+
+	req := &pb.RemoveRequest{Directory: dir, Path: "github.com/test/dep", IdempotencyKey: "k1"}
+	if _, err := srv.Remove(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	// Without the key, the retry would fail: the dependency is gone.
+	if _, err := srv.Remove(ctx, req); err != nil {
+		t.Errorf("retry with the same key: %v", err)
+	}
+
+	_, err := srv.Remove(ctx, &pb.RemoveRequest{Directory: dir, Path: "github.com/test/other", IdempotencyKey: "k1"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("key reused for another request: err = %v, want InvalidArgument", err)
+	}
+
+	// Failed calls are not remembered.
+	failing := &pb.RemoveRequest{Directory: dir, Path: "github.com/test/dep", IdempotencyKey: "k2"}
+	if _, err := srv.Remove(ctx, failing); status.Code(err) != codes.NotFound {
+		t.Fatalf("err = %v, want NotFound", err)
+	}
+	srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "github.com/test/dep", Version: "v0.1.0", RecordOnly: true}) //nolint:errcheck
+	if _, err := srv.Remove(ctx, failing); err != nil {
+		t.Errorf("retry after failure: %v", err)
+	}
+}
+
+func TestConcurrentSumUpdates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
 	dir := t.TempDir()
 	ctx := context.Background()
+	srv := &server.Server{}
 
-	mem := transport.NewMemListener()
-	s := grpc.NewServer()
-	pb.RegisterRhizomeAtlasServiceServer(s, &server.Server{})
-	go func() { _ = s.Serve(mem) }()
-	defer s.Stop()
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/concurrent"}) //nolint:errcheck
+	const n = 8
+	for i := range n {
+		cached := server.CachePath(fmt.Sprintf("github.com/test/dep%d", i), "v0.1.0")
+		os.MkdirAll(cached, 0o755)                                                  //nolint:errcheck
+		os.WriteFile(filepath.Join(cached, "README"), []byte(fmt.Sprint(i)), 0o644) //nolint:errcheck
+	}
 
-	conn, err := grpc.NewClient(
-		"passthrough:///mem",
-		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
-			return mem.Dial()
-		}),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: fmt.Sprintf("github.com/test/dep%d", i), Version: "v0.1.0"}) //nolint:errcheck
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(filepath.Join(dir, "holon.sum"))
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer conn.Close()
+	for i := range n {
+		if !strings.Contains(string(data), fmt.Sprintf("github.com/test/dep%d v0.1.0 ", i)) {
+			t.Errorf("holon.sum lost dep%d:\n%s", i, data)
+		}
+	}
+}
 
-	client := pb.NewRhizomeAtlasServiceClient(conn)
+func TestAddRemoveByAlias(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
 
-	// Init over mem://
-	initResp, err := client.Init(ctx, &pb.InitRequest{
-		Directory: dir,
-		HolonPath: "test/mem-holon",
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/alias"}) //nolint:errcheck
+	addResp, err := srv.Add(ctx, &pb.AddRequest{
+		Directory:  dir,
+		Path:       "github.com/test/long-name",
+		Version:    "v0.1.0",
+		Alias:      "ln",
+		RecordOnly: true,
 	})
 	if err != nil {
-		t.Fatalf("Init over mem://: %v", err)
+		t.Fatal(err)
 	}
-	if initResp.ModFile == "" {
-		t.Error("expected mod_file path")
+	if addResp.Dependency.Alias != "ln" {
+		t.Errorf("alias = %q", addResp.Dependency.Alias)
 	}
 
-	// Graph over mem://
-	graphResp, err := client.Graph(ctx, &pb.GraphRequest{Directory: dir})
+	graphResp, err := srv.Graph(ctx, &pb.GraphRequest{Directory: dir})
 	if err != nil {
-		t.Fatalf("Graph over mem://: %v", err)
+		t.Fatal(err)
 	}
-	if graphResp.Root != "test/mem-holon" {
-		t.Errorf("root = %q", graphResp.Root)
+	if len(graphResp.Edges) != 1 || graphResp.Edges[0].Alias != "ln" {
+		t.Fatalf("edges = %v", graphResp.Edges)
 	}
-}
 
-// --- ws:// transport test ---
+	if _, err := srv.Remove(ctx, &pb.RemoveRequest{Directory: dir, Path: "ln"}); err != nil {
+		t.Fatal(err)
+	}
+	graphResp, _ = srv.Graph(ctx, &pb.GraphRequest{Directory: dir})
+	if len(graphResp.Edges) != 0 {
+		t.Errorf("edges after remove by alias = %d", len(graphResp.Edges))
+	}
+}
 
-func TestWSTransport(t *testing.T) {
+func TestGraphSeesExternalEdits(t *testing.T) {
 	dir := t.TempDir()
 	ctx := context.Background()
+	srv := &server.Server{}
+	modPath := filepath.Join(dir, "holon.mod")
 
-	wsLis, err := transport.Listen("ws://127.0.0.1:0")
+	mod := &modfile.ModFile{HolonPath: "test/cached"}
+	mod.AddRequire("github.com/test/a", "v0.1.0")
+	if err := mod.Write(modPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Graph(ctx, &pb.GraphRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Edit holon.mod behind the server's back
+	mod.AddRequire("github.com/test/b", "v0.2.0")
+	if err := mod.Write(modPath); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := srv.Graph(ctx, &pb.GraphRequest{Directory: dir})
 	if err != nil {
-		t.Fatalf("ws listen: %v", err)
+		t.Fatal(err)
 	}
-	defer wsLis.Close()
+	if len(resp.Edges) != 2 {
+		t.Errorf("edges = %d after external edit, want 2", len(resp.Edges))
+	}
+}
 
-	s := grpc.NewServer()
-	pb.RegisterRhizomeAtlasServiceServer(s, &server.Server{})
-	reflection.Register(s)
-	go func() { _ = s.Serve(wsLis) }()
-	defer s.Stop()
+func TestGraphFilter(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
 
-	// Connect via WebSocket
-	wsAddr := wsLis.Addr().String()
-	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+	mod := &modfile.ModFile{HolonPath: "test/filter"}
+	mod.AddRequire("github.com/test/a", "v0.1.0")
+	mod.AddRequire("github.com/test/b", "v0.1.0")
+	if err := mod.SetMeta("github.com/test/a", map[string]string{"scope": "test"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
 
-	c, _, err := websocket.Dial(dialCtx, wsAddr, &websocket.DialOptions{
-		Subprotocols: []string{"grpc"},
-	})
+	resp, err := srv.Graph(ctx, &pb.GraphRequest{Directory: dir, Filter: map[string]string{"scope": "test"}})
 	if err != nil {
-		t.Fatalf("ws dial: %v", err)
+		t.Fatal(err)
 	}
-	wsConn := websocket.NetConn(dialCtx, c, websocket.MessageBinary)
+	if len(resp.Edges) != 1 || resp.Edges[0].To != "github.com/test/a" {
+		t.Fatalf("filtered edges = %v", resp.Edges)
+	}
+	if resp.Edges[0].Metadata["scope"] != "test" {
+		t.Errorf("metadata = %v", resp.Edges[0].Metadata)
+	}
+}
 
-	dialed := false
-	//nolint:staticcheck
-	conn, err := grpc.DialContext(dialCtx,
-		"passthrough:///ws",
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithContextDialer(func(_ context.Context, _ string) (net.Conn, error) {
-			if dialed {
-				return nil, fmt.Errorf("already consumed")
-			}
-			dialed = true
-			return wsConn, nil
-		}),
-		grpc.WithBlock(),
-	)
+func TestGraphNodes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := filepath.Join(t.TempDir(), "root")
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	mod := &modfile.ModFile{HolonPath: "test/root"}
+	mod.AddRequire("github.com/test/a", "v1.0.0")
+	mod.AddRequire("github.com/test/b", "v0.1.0")
+	mod.AddRequire("github.com/test/c", "v1.1.0")
+	mod.AddRequire("github.com/test/d", "v0.1.0")
+	mod.Replace = []modfile.Replace{{Old: "github.com/test/b", New: "../b"}}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	writeHolonMD(t, dir, "name: Root\n")
+	writeHolonMD(t, filepath.Join(filepath.Dir(dir), "b"), "name: B\n")
+	aDir := server.CachePath("github.com/test/a", "v1.0.0")
+	writeHolonMD(t, aDir, "name: A\n")
+	if err := os.WriteFile(filepath.Join(aDir, "holon.mod"), []byte("holon github.com/test/a\n\nrequire (\n\tgithub.com/test/c v1.2.0\n)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeHolonMD(t, server.CachePath("github.com/test/c", "v1.2.0"), "name: C\n")
+
+	want := []*pb.Node{
+		{Path: "test/root", Title: "Root"},
+		{Path: "github.com/test/a", Version: "v1.0.0", Location: "cache", Title: "A"},
+		{Path: "github.com/test/c", Version: "v1.2.0", Location: "cache", Title: "C"},
+		{Path: "github.com/test/b", Version: "v0.1.0", Location: "replace", Replace: "../b", Title: "B"},
+		{Path: "github.com/test/d", Version: "v0.1.0", Location: "missing"},
+	}
+	resp, err := srv.Graph(ctx, &pb.GraphRequest{Directory: dir})
 	if err != nil {
-		wsConn.Close()
-		t.Fatalf("grpc dial over ws: %v", err)
+		t.Fatal(err)
 	}
-	defer conn.Close()
+	if len(resp.Nodes) != len(want) {
+		t.Fatalf("nodes = %v, want %v", resp.Nodes, want)
+	}
+	for i := range want {
+		if !proto.Equal(resp.Nodes[i], want[i]) {
+			t.Errorf("node %d = %v, want %v", i, resp.Nodes[i], want[i])
+		}
+	}
+}
 
-	client := pb.NewRhizomeAtlasServiceClient(conn)
+func TestDescribe(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
 
-	// Init over ws://
-	initResp, err := client.Init(ctx, &pb.InitRequest{
-		Directory: dir,
-		HolonPath: "test/ws-holon",
-	})
+	mod := &modfile.ModFile{HolonPath: "test/self"}
+	mod.AddRequire("github.com/test/dep", "v0.1.0")
+	mod.SetAlias("github.com/test/dep", "dep") //nolint:errcheck
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	writeHolonMD(t, dir, "name: Self\nsummary: The root.\n")
+	writeHolonMD(t, server.CachePath("github.com/test/dep", "v0.1.0"),
+		"given_name: Dep\nfamily_name: Holon\ncapabilities: [kv]\n")
+
+	resp, err := srv.Describe(ctx, &pb.DescribeRequest{Directory: dir})
 	if err != nil {
-		t.Fatalf("Init over ws://: %v", err)
+		t.Fatal(err)
 	}
-	if initResp.ModFile == "" {
-		t.Error("expected mod_file path")
+	if resp.Holon.Path != "test/self" || resp.Holon.Name != "Self" {
+		t.Errorf("self = %+v", resp.Holon)
 	}
 
-	// Graph over ws://
-	graphResp, err := client.Graph(ctx, &pb.GraphRequest{Directory: dir})
+	resp, err = srv.Describe(ctx, &pb.DescribeRequest{Directory: dir, Path: "dep"})
 	if err != nil {
-		t.Fatalf("Graph over ws://: %v", err)
+		t.Fatal(err)
 	}
-	if graphResp.Root != "test/ws-holon" {
-		t.Errorf("root = %q", graphResp.Root)
+	if resp.Holon.Path != "github.com/test/dep" || resp.Holon.Version != "v0.1.0" || resp.Holon.Name != "Dep Holon" {
+		t.Errorf("dep = %+v", resp.Holon)
+	}
+	if len(resp.Holon.Capabilities) != 1 || resp.Holon.Capabilities[0] != "kv" {
+		t.Errorf("capabilities = %v", resp.Holon.Capabilities)
+	}
+
+	if _, err := srv.Describe(ctx, &pb.DescribeRequest{Directory: dir, Path: "github.com/test/unknown"}); err == nil {
+		t.Error("expected error for unknown dependency")
+	}
+}
+
+func TestStableForbidsPrerelease(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	mod := &modfile.ModFile{HolonPath: "test/stable", Stable: true}
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "github.com/test/dep", Version: "v1.0.0-beta.1"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("add prerelease: err = %v, want FailedPrecondition", err)
+	}
+	_, err = srv.Update(ctx, &pb.UpdateRequest{Directory: dir, Channel: "rc"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("update --channel: err = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestFindCapability(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	mod := &modfile.ModFile{HolonPath: "test/caps"}
+	mod.AddRequire("github.com/test/kv", "v0.1.0")
+	mod.AddRequire("github.com/test/blob", "v0.2.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	writeHolonMD(t, server.CachePath("github.com/test/kv", "v0.1.0"), "capabilities: [storage.kv]\n")
+	writeHolonMD(t, server.CachePath("github.com/test/blob", "v0.2.0"), "capabilities:\n  - storage.blob\n")
+
+	resp, err := srv.FindCapability(ctx, &pb.FindCapabilityRequest{Directory: dir, Capability: "storage.blob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Providers) != 1 || resp.Providers[0].Path != "github.com/test/blob" || resp.Providers[0].Version != "v0.2.0" {
+		t.Errorf("providers = %v", resp.Providers)
+	}
+}
+
+func TestOwners(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	content := "holon test/owners\n\nrequire (\n    github.com/test/kv v0.1.0 // owner=payments\n    github.com/test/blob v0.2.0\n)\n"
+	if err := os.WriteFile(filepath.Join(dir, "holon.mod"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeHolonMD(t, server.CachePath("github.com/test/kv", "v0.1.0"),
+		"owner: storage\nmaintainers: [alice, bob]\ncontact: storage@example.com\n")
+
+	resp, err := srv.Owners(ctx, &pb.OwnersRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*pb.HolonOwners{
+		{Path: "github.com/test/kv", Version: "v0.1.0", Owner: "storage", Maintainers: []string{"alice", "bob"},
+			Contact: "storage@example.com", LocalOwner: "payments"},
+		{Path: "github.com/test/blob", Version: "v0.2.0"},
+	}
+	if len(resp.Holons) != len(want) {
+		t.Fatalf("holons = %v, want %v", resp.Holons, want)
+	}
+	for i := range want {
+		if !proto.Equal(resp.Holons[i], want[i]) {
+			t.Errorf("holon %d = %v, want %v", i, resp.Holons[i], want[i])
+		}
+	}
+
+	resp, err = srv.Owners(ctx, &pb.OwnersRequest{Directory: dir, Path: "github.com/test/blob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Holons) != 1 || resp.Holons[0].Path != "github.com/test/blob" {
+		t.Errorf("owners of blob = %v", resp.Holons)
+	}
+	if _, err := srv.Owners(ctx, &pb.OwnersRequest{Directory: dir, Path: "github.com/test/unknown"}); status.Code(err) != codes.NotFound {
+		t.Errorf("owners of an unknown dependency: err = %v, want NotFound", err)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	gitTest(t)
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/release"}); err != nil {
+		t.Fatal(err)
+	}
+	tagRelease(t, dir, "v0.3.1")
+
+	resp, err := srv.Release(ctx, &pb.ReleaseRequest{Directory: dir, Bump: pb.ReleaseBump_RELEASE_BUMP_MINOR})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.PreviousVersion != "v0.3.1" || resp.Version != "v0.4.0" {
+		t.Errorf("release = %s → %s, want v0.3.1 → v0.4.0", resp.PreviousVersion, resp.Version)
+	}
+
+	// A dirty work tree is refused.
+	writeHolonMD(t, dir, "name: release\n")
+	if _, err := srv.Release(ctx, &pb.ReleaseRequest{Directory: dir}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("release of dirty tree: err = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestAddCommit(t *testing.T) {
+	registry := gitRegistry(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	// A release, then a fix not released yet.
+	depPath := "atlas.invalid/test/pinned"
+	repo := filepath.Join(registry, depPath)
+	writeHolonMD(t, repo, "name: pinned\n")
+	tagRelease(t, repo, "v0.1.0")
+	git := gitRepo(t, repo)
+	writeHolonMD(t, repo, "name: pinned\nversion: fixed\n")
+	git("add", ".")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-03-05T13:07:09Z")
+	git("commit", "-q", "-m", "fix")
+	commit := git("rev-parse", "HEAD")
+	want := semver.PseudoVersion(time.Date(2024, 3, 5, 13, 7, 9, 0, time.UTC), commit)
+
+	dir := t.TempDir()
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/app"}); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := srv.Resolve(ctx, &pb.ResolveRequest{Directory: dir, Path: depPath, Version: commit[:7]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Version != want || resolved.Commit != commit {
+		t.Errorf("resolve %s = %s at %s, want %s at %s", commit[:7], resolved.Version, resolved.Commit, want, commit)
+	}
+
+	added, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: commit[:7]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.Dependency.Version != want {
+		t.Fatalf("added version = %s, want %s", added.Dependency.Version, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(added.Dependency.CachePath, "HOLON.md")); !strings.Contains(string(data), "fixed") {
+		t.Errorf("cached HOLON.md = %q, want the fix", data)
+	}
+	sum, err := modfile.ParseSum(filepath.Join(dir, "holon.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sum.Lookup(depPath, want+"/commit"); got != "git:"+commit {
+		t.Errorf("holon.sum pins %q, want git:%s", got, commit)
+	}
+
+	// Update leaves the pin alone, though v0.1.0 sorts higher.
+	updated, err := srv.Update(ctx, &pb.UpdateRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.Updated) != 0 {
+		t.Errorf("update moved the pin: %v", updated.Updated)
+	}
+	verified, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified.Ok || !slices.ContainsFunc(verified.Findings, func(f *pb.VerifyFinding) bool { return f.Code == "pseudo-version" }) {
+		t.Errorf("verify = %v, want ok with a pseudo-version warning", verified.Findings)
+	}
+
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: "0123456"}); status.Code(err) != codes.NotFound {
+		t.Errorf("add of an unknown commit: err = %v, want NotFound", err)
+	}
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: "v0.0.0-20000101000000-" + commit[:12]}); status.Code(err) != codes.Unavailable {
+		t.Errorf("add of a pseudo-version with the wrong time: err = %v, want Unavailable", err)
+	}
+}
+
+func TestNewFromTemplate(t *testing.T) {
+	registry := gitRegistry(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	// Publish the template to the registry; upstream does not resolve.
+	tmpl := t.TempDir()
+	tmplPath := "atlas.invalid/test/tmpl"
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: tmpl, HolonPath: tmplPath}); err != nil {
+		t.Fatal(err)
+	}
+	writeHolonMD(t, tmpl, "name: \"{{name}}\"\ntemplate: true\n")
+	if err := os.WriteFile(filepath.Join(tmpl, ".gitignore"), []byte(".holon/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	main := filepath.Join(tmpl, "cmd", "{{name}}", "main.go")
+	if err := os.MkdirAll(filepath.Dir(main), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(main, []byte("// {{name}} by {{author}}: {{holon_path}}, {{license}} {{unknown}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git := gitRepo(t, tmpl)
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	rel, err := srv.Release(ctx, &pb.ReleaseRequest{Directory: tmpl, Publish: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "file://" + registry + "/" + tmplPath; rel.Published != want {
+		t.Errorf("published = %q, want %q", rel.Published, want)
+	}
+
+	dir := filepath.Join(t.TempDir(), "svc")
+	resp, err := srv.New(ctx, &pb.NewRequest{
+		Template:  tmplPath,
+		Directory: dir,
+		HolonPath: "github.com/org/svc",
+		Author:    "Ada",
+		Vars:      map[string]string{"license": "MIT"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Version != rel.Version {
+		t.Errorf("version = %s, want %s", resp.Version, rel.Version)
+	}
+	if want := []string{".gitignore", "HOLON.md", "cmd/svc/main.go", "holon.mod"}; !slices.Equal(resp.Files, want) {
+		t.Errorf("files = %v, want %v", resp.Files, want)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "cmd", "svc", "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "// svc by Ada: github.com/org/svc, MIT {{unknown}}\n"; got != want {
+		t.Errorf("main.go = %q, want %q", got, want)
+	}
+	mod, err := modfile.Parse(filepath.Join(dir, "holon.mod"))
+	if err != nil || mod.HolonPath != "github.com/org/svc" {
+		t.Errorf("holon.mod = %v, %v, want holon github.com/org/svc", mod, err)
+	}
+	fm, err := holonmd.Parse(filepath.Join(dir, "HOLON.md"))
+	if err != nil || fm.Name != "svc" || fm.Fields["template"] != nil {
+		t.Errorf("HOLON.md = %v, %v, want name svc and no template marker", fm, err)
+	}
+
+	if _, err := srv.New(ctx, &pb.NewRequest{Template: tmplPath, Directory: dir, HolonPath: "github.com/org/svc"}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("new into a non-empty directory: err = %v, want AlreadyExists", err)
+	}
+}
+
+// gitTest skips t when git is not installed, and gives it a scratch HOME
+// and a git identity to commit with.
+func gitTest(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+}
+
+// gitRegistry sets t up as gitTest does, to fetch from a file:// registry
+// and no proxy, and returns the registry directory: the repository of a
+// dependency is <registry>/<dep-path>.
+func gitRegistry(t *testing.T) string {
+	t.Helper()
+	gitTest(t)
+	registry := t.TempDir()
+	t.Setenv("ATLAS_REGISTRY", "file://"+registry)
+	t.Setenv("ATLAS_PROXY", "")
+	return registry
+}
+
+// gitRepo returns a function running git in dir, initialized as a
+// repository unless it is one (bare or not), that fails t on error and
+// returns the trimmed output.
+func gitRepo(t *testing.T, dir string) func(args ...string) string {
+	t.Helper()
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	if _, bare := os.Stat(filepath.Join(dir, "HEAD")); err != nil && bare != nil {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		git("init", "-q")
+	}
+	return git
+}
+
+// tagRelease commits the whole work tree of the repository in dir (see
+// gitRepo), tags the commit and returns its hash.
+func tagRelease(t *testing.T, dir, tag string) string {
+	t.Helper()
+	git := gitRepo(t, dir)
+	git("add", ".")
+	git("commit", "-q", "--allow-empty", "-m", tag)
+	git("tag", tag)
+	return git("rev-parse", "HEAD")
+}
+
+// registerFiles registers a fetcher for host serving files at version.
+func registerFiles(host, version string, files map[string]string) {
+	fetch.Register(host, filesFetcher{files: map[string]map[string]string{version: files}})
+}
+
+// writeHolonMod writes mod as the holon.mod of dir, created if needed, and
+// returns dir.
+func writeHolonMod(t *testing.T, dir string, mod *modfile.ModFile) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// writeHolonMD writes a HOLON.md with the given front-matter body in dir.
+func writeHolonMD(t *testing.T, dir, frontMatter string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\n" + frontMatter + "---\n"
+	if err := os.WriteFile(filepath.Join(dir, "HOLON.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyEmpty(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	// Init and verify (no deps = all ok)
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/h"}) //nolint:errcheck
+
+	resp, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Ok {
+		t.Errorf("verify should be ok with no deps, got errors: %v", resp.Errors)
+	}
+}
+
+func TestVerifyAll(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	good := filepath.Join(root, "good")
+	bad := filepath.Join(root, "nested", "bad")
+	hidden := filepath.Join(root, ".holon", "vendored")
+	for _, dir := range []string{good, bad, hidden} {
+		if _, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/" + filepath.Base(dir)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sum := &modfile.SumFile{}
+	sum.Set("github.com/test/missing", "v0.1.0", "h1:deadbeef")
+	if err := sum.Write(filepath.Join(bad, "holon.sum")); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := srv.VerifyAll(ctx, &pb.VerifyAllRequest{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Ok {
+		t.Error("expected overall failure")
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("results = %d, want 2 (hidden dirs skipped)", len(resp.Results))
+	}
+	for _, r := range resp.Results {
+		if want := r.Directory == good; r.Ok != want {
+			t.Errorf("%s ok = %v, want %v (%v)", r.Directory, r.Ok, want, r.Errors)
+		}
+	}
+
+	if _, err := srv.VerifyAll(ctx, &pb.VerifyAllRequest{}); err == nil {
+		t.Error("expected error without directories or root")
+	}
+}
+
+func TestVendorAndCleanCache(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	// Setup: init, add a real dep (go-holons has a v0.1.0 tag)
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/vendor"}) //nolint:errcheck
+	srv.Add(ctx, &pb.AddRequest{
+		Directory: dir,
+		Path:      "github.com/organic-programming/go-holons",
+		Version:   "v0.1.0",
+	}) //nolint:errcheck
+
+	// Vendor
+	vendorResp, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vendorResp.Vendored) != 1 {
+		t.Fatalf("vendored = %d, want 1", len(vendorResp.Vendored))
+	}
+
+	// Check .holon/go-holons/ exists
+	vendored := filepath.Join(dir, ".holon", "go-holons")
+	if _, err := os.Stat(vendored); os.IsNotExist(err) {
+		t.Error(".holon/go-holons/ not created")
+	}
+
+	// Clean cache
+	cacheResp, err := srv.CleanCache(ctx, &pb.CleanCacheRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cacheResp.CachePath == "" {
+		t.Error("expected cache_path in response")
+	}
+
+	// Verify cache is gone
+	if _, err := os.Stat(server.CacheDir()); !os.IsNotExist(err) {
+		t.Error("cache dir should not exist after clean")
+	}
+}
+
+func TestPullThroughProxy(t *testing.T) {
+	gitTest(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	// The mirror serves <proxy>/<dep-path>; the upstream host does not resolve.
+	proxy := t.TempDir()
+	depPath := "atlas.invalid/test/dep"
+	repo := filepath.Join(proxy, depPath)
+	writeHolonMD(t, repo, "name: dep\n")
+	commit := tagRelease(t, repo, "v0.1.0")
+	t.Setenv("ATLAS_PROXY", "file://"+proxy)
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/proxy"}
+	mod.AddRequire(depPath, "v0.1.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Fetched) != 1 || resp.Fetched[0].Source != "file://"+repo {
+		t.Fatalf("fetched = %v, want source file://%s", resp.Fetched, repo)
+	}
+	if _, err := os.Stat(server.CachePath(depPath, "v0.1.0") + ".info"); err != nil {
+		t.Errorf("fetch info not recorded: %v", err)
+	}
+
+	want := &pb.FetchInfo{Source: "file://" + repo, Fetcher: "git", Commit: commit}
+	list, err := srv.CacheList(ctx, &pb.CacheListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := srv.Describe(ctx, &pb.DescribeRequest{Directory: dir, Path: depPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []*pb.FetchInfo{list.Entries[0].Fetch, desc.Holon.Fetch} {
+		if _, err := time.Parse(time.RFC3339, got.GetTime()); err != nil {
+			t.Errorf("fetch time %q: %v", got.GetTime(), err)
+		}
+		if got != nil {
+			got.Time = ""
+		}
+		if !proto.Equal(got, want) {
+			t.Errorf("fetch info = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestDeclaredFetchSources(t *testing.T) {
+	gitTest(t)
+	// Neither variable set: the directives of holon.mod apply.
+	for _, env := range []string{"ATLAS_PROXY", "ATLAS_REGISTRY"} {
+		t.Setenv(env, "")
+		os.Unsetenv(env) //nolint:errcheck
+	}
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	mirror := t.TempDir()
+	depPath := "atlas.invalid/test/dep"
+	repo := filepath.Join(mirror, depPath)
+	writeHolonMD(t, repo, "name: dep\n")
+	tagRelease(t, repo, "v0.1.0")
+
+	pull := func(mod *modfile.ModFile) (*pb.PullResponse, error) {
+		t.Helper()
+		t.Setenv("HOME", t.TempDir())
+		dir := t.TempDir()
+		mod.AddRequire(depPath, "v0.1.0")
+		if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+			t.Fatal(err)
+		}
+		return srv.Pull(ctx, &pb.PullRequest{Directory: dir})
+	}
+
+	for _, mod := range []*modfile.ModFile{
+		{HolonPath: "test/proxy", Proxy: []string{"file://" + mirror}},
+		{HolonPath: "test/registry", Registry: "file://" + mirror},
+	} {
+		resp, err := pull(mod)
+		if err != nil {
+			t.Fatalf("%s: %v", mod.HolonPath, err)
+		}
+		if len(resp.Fetched) != 1 || resp.Fetched[0].Source != "file://"+repo {
+			t.Errorf("%s: fetched = %v, want source file://%s", mod.HolonPath, resp.Fetched, repo)
+		}
+	}
+
+	// An empty ATLAS_PROXY forbids the mirror holon.mod declares.
+	t.Setenv("ATLAS_PROXY", "")
+	if _, err := pull(&modfile.ModFile{HolonPath: "test/proxy", Proxy: []string{"file://" + mirror}}); err == nil {
+		t.Error("pull through a declared proxy overridden by ATLAS_PROXY: want error")
+	}
+	// ATLAS_REGISTRY replaces the registry holon.mod declares.
+	t.Setenv("ATLAS_REGISTRY", "file://"+t.TempDir())
+	if _, err := pull(&modfile.ModFile{HolonPath: "test/registry", Registry: "file://" + mirror}); err == nil {
+		t.Error("pull from a declared registry overridden by ATLAS_REGISTRY: want error")
+	}
+}
+
+func TestResolveQuery(t *testing.T) {
+	gitTest(t)
+	for _, env := range []string{"ATLAS_PROXY", "ATLAS_REGISTRY"} {
+		t.Setenv(env, "")
+		os.Unsetenv(env) //nolint:errcheck
+	}
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	registry := t.TempDir()
+	depPath := "atlas.invalid/test/dep"
+	repo := filepath.Join(registry, depPath)
+	writeHolonMD(t, repo, "name: dep\n")
+	commits := map[string]string{}
+	for _, tag := range []string{"v0.1.0", "v0.2.0"} {
+		commits[tag] = tagRelease(t, repo, tag)
+	}
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/resolve", Registry: "file://" + registry}
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The latest release, looked up upstream.
+	resp, err := srv.Resolve(ctx, &pb.ResolveRequest{Directory: dir, Path: depPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &pb.ResolveResponse{Path: depPath, Version: "v0.2.0", Source: "file://" + repo, Commit: commits["v0.2.0"]}
+	if !proto.Equal(resp, want) {
+		t.Errorf("resolve latest = %v, want %v", resp, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "holon.sum")); !os.IsNotExist(err) {
+		t.Errorf("resolve wrote holon.sum: %v", err)
+	}
+	if _, err := os.Stat(server.CachePath(depPath, "v0.2.0")); !os.IsNotExist(err) {
+		t.Errorf("resolve fetched v0.2.0: %v", err)
+	}
+
+	if _, err := srv.Resolve(ctx, &pb.ResolveRequest{Directory: dir, Path: depPath, Version: "v0.9.0"}); status.Code(err) != codes.NotFound {
+		t.Errorf("resolve of a missing version: err = %v, want NotFound", err)
+	}
+
+	// A cached version is reported from its fetch metadata.
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: "v0.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = srv.Resolve(ctx, &pb.ResolveRequest{Directory: dir, Path: depPath, Version: "v0.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &pb.ResolveResponse{Path: depPath, Version: "v0.1.0", Source: "file://" + repo, Commit: commits["v0.1.0"],
+		Cached: true, CachePath: server.CachePath(depPath, "v0.1.0")}
+	if !proto.Equal(resp, want) {
+		t.Errorf("resolve cached = %v, want %v", resp, want)
+	}
+}
+
+// storeFetcher serves every version of a dependency from memory.
+type storeFetcher struct{ tags []string }
+
+func (f storeFetcher) Resolve(path, version string) ([]fetch.Source, error) {
+	return []fetch.Source{storeSource("store://" + path + "@" + version)}, nil
+}
+
+func (f storeFetcher) Tags(context.Context, string) ([]string, error) {
+	return f.tags, nil
+}
+
+type storeSource string
+
+func (s storeSource) String() string { return string(s) }
+
+func (s storeSource) Fetch(_ context.Context, dst string) error {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dst, "HOLON.md"), []byte("---\nname: stored\n---\n"), 0o644)
+}
+
+// movingFetcher serves the current content of a mutable upstream.
+type movingFetcher struct{ content *string }
+
+func (f movingFetcher) Resolve(path, version string) ([]fetch.Source, error) {
+	return []fetch.Source{movingSource{f.content}}, nil
+}
+
+type movingSource struct{ content *string }
+
+func (movingSource) String() string { return "moving://" }
+
+func (s movingSource) Fetch(_ context.Context, dst string) error {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dst, "HOLON.md"), []byte(*s.content), 0o644)
+}
+
+func TestVerifyRemote(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+	upstream := "---\nname: moving\n---\n"
+	fetch.Register("moving.test", movingFetcher{&upstream})
+
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/verify-remote"}) //nolint:errcheck
+	added, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "moving.test/dep", Version: "v1.2.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify := func() []string {
+		t.Helper()
+		resp, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir, Remote: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Errors
+	}
+	count := func(errs []string, substr string) int {
+		n := 0
+		for _, e := range errs {
+			if strings.Contains(e, substr) {
+				n++
+			}
+		}
+		return n
+	}
+	if errs := verify(); len(errs) != 0 {
+		t.Fatalf("errors = %v, want none", errs)
+	}
+
+	// Who moved v1.2.0?
+	upstream = "---\nname: moved\n---\n"
+	errs := verify()
+	if count(errs, "upstream content changed") != 2 || count(errs, "cache differs from upstream") != 2 || count(errs, "hash mismatch") != 0 {
+		t.Errorf("tag moved upstream: errors = %v", errs)
+	}
+
+	// The cache was tampered with.
+	upstream = "---\nname: moving\n---\n"
+	if err := os.WriteFile(filepath.Join(added.Dependency.CachePath, "HOLON.md"), []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	errs = verify()
+	if count(errs, "upstream content changed") != 0 || count(errs, "cache differs from upstream") != 2 || count(errs, "hash mismatch") != 2 {
+		t.Errorf("cache tampered with: errors = %v", errs)
+	}
+}
+
+func TestRegisteredFetcher(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("fetcher.test", storeFetcher{tags: []string{"v1.0.0", "v1.1.0-beta.1", "v1.1.0", "v2.0.0"}})
+
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/fetcher"}) //nolint:errcheck
+	resp, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "fetcher.test/dep", Version: "v1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Dependency.Source != "store://fetcher.test/dep@v1.0.0" {
+		t.Errorf("source = %q", resp.Dependency.Source)
+	}
+	if _, err := os.Stat(filepath.Join(resp.Dependency.CachePath, "HOLON.md")); err != nil {
+		t.Errorf("fetched content: %v", err)
+	}
+
+	pending, err := srv.PendingUpdates(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].NewVersion != "v1.1.0" {
+		t.Errorf("pending = %v, want v1.1.0 from the fetcher's tags", pending)
+	}
+}
+
+func TestVerifyHashAlgorithms(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("fetcher.test", storeFetcher{})
+
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/hashes"}) //nolint:errcheck
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "fetcher.test/dep", Version: "v1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	sumPath := filepath.Join(dir, "holon.sum")
+	sum, err := modfile.ParseSum(sumPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range sum.Entries {
+		if alg, _, _ := modfile.SplitHash(e.Hash); alg != "h1" {
+			t.Errorf("%s %s recorded with %q, want h1", e.Path, e.Version, alg)
+		}
+	}
+
+	sum.Set("fetcher.test/other", "v1.0.0", "h9:future")
+	if err := sum.Write(sumPath); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0], `unknown hash algorithm "h9"`) {
+		t.Errorf("errors = %v, want only the h9 entry reported", resp.Errors)
+	}
+}
+
+func TestSumMigrate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("fetcher.test", storeFetcher{})
+	sumPath := filepath.Join(dir, "holon.sum")
+	algorithms := func() map[string]int {
+		t.Helper()
+		sum, err := modfile.ParseSum(sumPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count := map[string]int{}
+		for _, e := range sum.Entries {
+			alg, _, _ := modfile.SplitHash(e.Hash)
+			count[alg]++
+		}
+		return count
+	}
+	add := func(path string) {
+		t.Helper()
+		if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: path, Version: "v1.0.0"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	verify := func() {
+		t.Helper()
+		resp, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir})
+		if err != nil || !resp.Ok {
+			t.Errorf("verify = %v, %v", resp, err)
+		}
+	}
+
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/migrate"}) //nolint:errcheck
+	add("fetcher.test/a")
+
+	if _, err := srv.SumMigrate(ctx, &pb.SumMigrateRequest{Directory: dir, To: "h9"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("migrate to h9: err = %v, want InvalidArgument", err)
+	}
+	resp, err := srv.SumMigrate(ctx, &pb.SumMigrateRequest{Directory: dir, To: "h2:", DryRun: true})
+	if err != nil || len(resp.Added) != 2 {
+		t.Fatalf("dry run = %v, %v; want 2 entries added", resp, err)
+	}
+	if got := algorithms(); got["h2"] != 0 {
+		t.Errorf("dry run wrote holon.sum: %v", got)
+	}
+
+	// During the migration, both algorithms are recorded and checked.
+	if _, err := srv.SumMigrate(ctx, &pb.SumMigrateRequest{Directory: dir, To: "h2"}); err != nil {
+		t.Fatal(err)
+	}
+	add("fetcher.test/b")
+	if got := algorithms(); got["h1"] != 4 || got["h2"] != 4 {
+		t.Errorf("algorithms = %v, want 4 entries of each", got)
+	}
+	verify()
+
+	resp, err = srv.SumMigrate(ctx, &pb.SumMigrateRequest{Directory: dir, To: "h2", DropOld: true})
+	if err != nil || len(resp.Added) != 0 || len(resp.Removed) != 4 {
+		t.Fatalf("drop old = %v, %v; want the 4 h1 entries removed", resp, err)
+	}
+	add("fetcher.test/c")
+	if got := algorithms(); got["h1"] != 0 || got["h2"] != 6 {
+		t.Errorf("algorithms = %v, want only h2", got)
+	}
+	verify()
+}
+
+// offlineFetcher fails every fetch.
+type offlineFetcher struct{}
+
+func (offlineFetcher) Resolve(string, string) ([]fetch.Source, error) {
+	return nil, errors.New("offline")
+}
+
+// fakeBucket is an in-memory S3 bucket. When cut is set, the next GET
+// drops the connection after that many bytes.
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	cut     int
+	ranges  int  // ranged GETs served
+	full    bool // refuse to store snapshots
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=test-key/") {
+		http.Error(w, "unsigned", http.StatusForbidden)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(data)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			http.Error(w, "bad payload hash", http.StatusBadRequest)
+			return
+		}
+		if b.full && strings.HasSuffix(r.URL.Path, ".tar.gz") {
+			http.Error(w, "quota exceeded", http.StatusInsufficientStorage)
+			return
+		}
+		if _, exists := b.objects[r.URL.Path]; exists && r.Header.Get("If-None-Match") == "*" {
+			http.Error(w, "exists", http.StatusPreconditionFailed)
+			return
+		}
+		if !b.matches(r) {
+			http.Error(w, "changed", http.StatusPreconditionFailed)
+			return
+		}
+		b.objects[r.URL.Path] = data
+		w.Header().Set("ETag", etag(data))
+	case http.MethodGet:
+		data, ok := b.objects[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", etag(data))
+		if cut := b.cut; cut > 0 {
+			b.cut = 0
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.Write(data[:cut]) //nolint:errcheck
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		if r.Header.Get("Range") != "" {
+			b.ranges++
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	case http.MethodDelete:
+		if !b.matches(r) {
+			http.Error(w, "changed", http.StatusPreconditionFailed)
+			return
+		}
+		delete(b.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// matches reports whether the object r is for meets its If-Match. b.mu
+// must be held.
+func (b *fakeBucket) matches(r *http.Request) bool {
+	want := r.Header.Get("If-Match")
+	data, exists := b.objects[r.URL.Path]
+	return want == "" || exists && etag(data) == want
+}
+
+func etag(data []byte) string {
+	return fmt.Sprintf(`"%x"`, sha256.Sum256(data))
+}
+
+// slowFetcher serves dependencies after a delay, counting the fetches.
+type slowFetcher struct{ fetches *atomic.Int32 }
+
+func (f slowFetcher) Resolve(path, version string) ([]fetch.Source, error) {
+	f.fetches.Add(1)
+	time.Sleep(100 * time.Millisecond)
+	return storeFetcher{}.Resolve(path, version)
+}
+
+func TestRemoteCache(t *testing.T) {
+	bucket := &fakeBucket{objects: map[string][]byte{}}
+	ts := httptest.NewServer(bucket)
+	defer ts.Close()
+	t.Setenv("ATLAS_CACHE_REMOTE", "s3://farm/atlas")
+	t.Setenv("AWS_ENDPOINT_URL", ts.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret")
+	ctx := context.Background()
+	add := func(host string) (*pb.AddResponse, error) {
+		dir := t.TempDir()
+		srv := &server.Server{}
+		srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/remote"}) //nolint:errcheck
+		return srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: host + "/dep", Version: "v1.0.0"})
+	}
+
+	// A first machine fetches the dependency and shares it.
+	t.Setenv("HOME", t.TempDir())
+	fetch.Register("remote.test", storeFetcher{})
+	if _, err := add("remote.test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := bucket.objects["/farm/atlas/remote.test/dep@v1.0.0.tar.gz"]; !ok {
+		t.Fatalf("objects = %v, want the snapshot uploaded", bucket.objects)
+	}
+
+	// Another, offline, gets it from the shared store.
+	t.Setenv("HOME", t.TempDir())
+	fetch.Register("remote.test", offlineFetcher{})
+	resp, err := add("remote.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(resp.Dependency.CachePath, "HOLON.md")); err != nil {
+		t.Errorf("snapshot from the shared store: %v", err)
+	}
+
+	// Cleaning the local cache leaves the shared store alone.
+	if _, err := (&server.Server{}).CleanCache(ctx, &pb.CleanCacheRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(bucket.objects) != 1 {
+		t.Errorf("objects = %v after cache clean", bucket.objects)
+	}
+
+	// A store refusing the snapshot does not fail the fetch, which is
+	// kept locally.
+	t.Setenv("HOME", t.TempDir())
+	bucket.mu.Lock()
+	bucket.full = true
+	bucket.mu.Unlock()
+	fetch.Register("full.test", storeFetcher{})
+	if resp, err = add("full.test"); err != nil {
+		t.Fatalf("add with a full store: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(resp.Dependency.CachePath, "HOLON.md")); err != nil {
+		t.Errorf("snapshot kept locally: %v", err)
+	}
+}
+
+// fakeRegistry serves the push side of the OCI distribution API, behind
+// a token service.
+type fakeRegistry struct {
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	uploads   int
+}
+
+func (g *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if r.URL.Path == "/token" {
+		if r.URL.Query().Get("scope") != "repository:holons/dep:pull,push" {
+			http.Error(w, "bad scope", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"token":"t0k3n"}`)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer t0k3n" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="fake"`, r.Host))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	repo, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v2/"), "/")
+	repo2, rest, _ := strings.Cut(rest, "/")
+	if repo+"/"+repo2 != "holons/dep" {
+		http.NotFound(w, r)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.Method == http.MethodHead && strings.HasPrefix(rest, "blobs/"):
+		if _, ok := g.blobs[strings.TrimPrefix(rest, "blobs/")]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == http.MethodPost && rest == "blobs/uploads/":
+		g.uploads++
+		w.Header().Set("Location", fmt.Sprintf("/v2/holons/dep/blobs/uploads/%d?state=x", g.uploads))
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut && strings.HasPrefix(rest, "blobs/uploads/"):
+		digest := r.URL.Query().Get("digest")
+		if sum := sha256.Sum256(body); digest != "sha256:"+hex.EncodeToString(sum[:]) || r.URL.Query().Get("state") != "x" {
+			http.Error(w, "digest mismatch", http.StatusBadRequest)
+			return
+		}
+		g.blobs[digest] = body
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && strings.HasPrefix(rest, "manifests/"):
+		g.manifests[strings.TrimPrefix(rest, "manifests/")] = body
+		w.WriteHeader(http.StatusCreated)
+	default:
+		http.Error(w, "unsupported", http.StatusMethodNotAllowed)
+	}
+}
+
+func TestCachePush(t *testing.T) {
+	reg := &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	ts := httptest.NewServer(reg)
+	defer ts.Close()
+	t.Setenv("HOME", t.TempDir())
+	fetch.Register("oci.test", storeFetcher{})
+	ctx := context.Background()
+	srv := &server.Server{}
+	dir := t.TempDir()
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/oci"}) //nolint:errcheck
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "oci.test/dep", Version: "v1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	target := "oci://" + strings.TrimPrefix(ts.URL, "http://") + "/holons/dep"
+	resp, err := srv.CachePush(ctx, &pb.CachePushRequest{Path: "oci.test/dep", Version: "v1.0.0", Target: target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(resp.Reference, "/holons/dep:v1.0.0") {
+		t.Errorf("reference = %q, want the version as tag", resp.Reference)
+	}
+	manifest, ok := reg.manifests["v1.0.0"]
+	if !ok {
+		t.Fatalf("manifests = %v, want v1.0.0", reg.manifests)
+	}
+	if sum := sha256.Sum256(manifest); resp.Digest != "sha256:"+hex.EncodeToString(sum[:]) {
+		t.Errorf("digest = %s, not that of the manifest pushed", resp.Digest)
+	}
+	var m struct {
+		ArtifactType string `json:"artifactType"`
+		Config       struct{ Digest string }
+		Layers       []struct {
+			Digest string
+			Size   int64
+		}
+		Annotations map[string]string
+	}
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.ArtifactType != "application/vnd.organic-programming.holon.v1" || len(m.Layers) != 1 {
+		t.Fatalf("manifest = %s", manifest)
+	}
+	for _, d := range []string{m.Config.Digest, m.Layers[0].Digest} {
+		if _, ok := reg.blobs[d]; !ok {
+			t.Errorf("blob %s referenced by the manifest was not pushed", d)
+		}
+	}
+	sumPath := filepath.Join(dir, "holon.sum")
+	sum, _ := os.ReadFile(sumPath)
+	if hash := m.Annotations["org.organic-programming.holon.hash"]; hash == "" || !strings.Contains(string(sum), "oci.test/dep v1.0.0 "+hash) {
+		t.Errorf("hash annotation = %q, want that of holon.sum:\n%s", hash, sum)
+	}
+	if m.Annotations["org.opencontainers.image.source"] == "" {
+		t.Errorf("annotations = %v, want the source", m.Annotations)
+	}
+
+	// Pushing again under another tag reuses the blobs.
+	if _, err := srv.CachePush(ctx, &pb.CachePushRequest{Path: "oci.test/dep", Version: "v1.0.0", Target: target + ":stable"}); err != nil {
+		t.Fatal(err)
+	}
+	if reg.uploads != 2 {
+		t.Errorf("uploads = %d, want the 2 blobs uploaded once", reg.uploads)
+	}
+	if !bytes.Equal(reg.manifests["stable"], manifest) {
+		t.Errorf("manifest tagged stable differs from v1.0.0")
+	}
+
+	if _, err := srv.CachePush(ctx, &pb.CachePushRequest{Path: "oci.test/dep", Version: "v9.0.0", Target: target}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("push of an uncached version: err = %v, want FailedPrecondition", err)
+	}
+	if _, err := srv.CachePush(ctx, &pb.CachePushRequest{Path: "oci.test/dep", Version: "v1.0.0", Target: "https://example.com/x"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("push to a non-oci target: err = %v, want InvalidArgument", err)
+	}
+}
+
+func TestRemoteCacheResume(t *testing.T) {
+	bucket := &fakeBucket{objects: map[string][]byte{}}
+	ts := httptest.NewServer(bucket)
+	defer ts.Close()
+	t.Setenv("ATLAS_CACHE_REMOTE", "s3://farm/atlas")
+	t.Setenv("AWS_ENDPOINT_URL", ts.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret")
+	ctx := context.Background()
+	add := func() (*pb.AddResponse, error) {
+		dir := t.TempDir()
+		srv := &server.Server{}
+		srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/resume"}) //nolint:errcheck
+		return srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "resume.test/dep", Version: "v1.0.0"})
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	fetch.Register("resume.test", storeFetcher{})
+	if _, err := add(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The download drops halfway: the rest is requested, not all of it.
+	t.Setenv("HOME", t.TempDir())
+	fetch.Register("resume.test", offlineFetcher{})
+	bucket.mu.Lock()
+	bucket.cut = len(bucket.objects["/farm/atlas/resume.test/dep@v1.0.0.tar.gz"]) / 2
+	bucket.mu.Unlock()
+	resp, err := add()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(resp.Dependency.CachePath, "HOLON.md")); err != nil {
+		t.Errorf("resumed snapshot: %v", err)
+	}
+	if bucket.ranges != 1 {
+		t.Errorf("ranged GETs = %d, want 1", bucket.ranges)
+	}
+}
+
+func TestDownloadRateLimit(t *testing.T) {
+	gitTest(t)
+	for _, name := range []string{"http_proxy", "HTTP_PROXY", "https_proxy", "HTTPS_PROXY", "all_proxy", "ALL_PROXY"} {
+		t.Setenv(name, "")
+	}
+	ctx := context.Background()
+	pull := func(depPath, version string) (time.Duration, error) {
+		t.Setenv("HOME", t.TempDir())
+		dir := t.TempDir()
+		mod := &modfile.ModFile{HolonPath: "test/ratelimit"}
+		mod.AddRequire(depPath, version)
+		if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		_, err := (&server.Server{}).Pull(ctx, &pb.PullRequest{Directory: dir})
+		return time.Since(start), err
+	}
+
+	// Git fetches over HTTP go through the throttling proxy: 64 KiB of
+	// random content at 64 KiB/s takes well over half a second.
+	exportDir := t.TempDir()
+	depPath := "atlas.invalid/test/big"
+	repo := filepath.Join(exportDir, depPath)
+	writeHolonMD(t, repo, "name: big\n")
+	noise := make([]byte, 64<<10)
+	rand.Read(noise) //nolint:errcheck
+	if err := os.WriteFile(filepath.Join(repo, "noise"), noise, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tagRelease(t, repo, "v0.1.0")
+	execPath, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(&cgi.Handler{
+		Path: filepath.Join(strings.TrimSpace(string(execPath)), "git-http-backend"),
+		Env:  []string{"GIT_PROJECT_ROOT=" + exportDir, "GIT_HTTP_EXPORT_ALL=1"},
+	})
+	defer ts.Close()
+	t.Setenv("ATLAS_PROXY", ts.URL)
+
+	t.Setenv("ATLAS_RATE_LIMIT", "64k")
+	elapsed, err := pull(depPath, "v0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed < 500*time.Millisecond {
+		t.Errorf("limited git fetch took %v", elapsed)
+	}
+	t.Setenv("ATLAS_RATE_LIMIT", "")
+
+	// Shared cache downloads are paced too, here by the per-fetch limit.
+	bucket := &fakeBucket{objects: map[string][]byte{}}
+	bs := httptest.NewServer(bucket)
+	defer bs.Close()
+	t.Setenv("ATLAS_CACHE_REMOTE", "s3://farm/atlas")
+	t.Setenv("AWS_ENDPOINT_URL", bs.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret")
+	fetch.Register("ratelimit.test", storeFetcher{})
+	if _, err := pull("ratelimit.test/dep", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	size := len(bucket.objects["/farm/atlas/ratelimit.test/dep@v1.0.0.tar.gz"])
+
+	fetch.Register("ratelimit.test", offlineFetcher{})
+	t.Setenv("ATLAS_FETCH_RATE_LIMIT", strconv.Itoa(2*size))
+	if elapsed, err := pull("ratelimit.test/dep", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	} else if elapsed < 200*time.Millisecond {
+		t.Errorf("limited download of %d bytes took %v", size, elapsed)
+	}
+
+	t.Setenv("ATLAS_FETCH_RATE_LIMIT", "fast")
+	if _, err := pull(depPath, "v0.1.0"); err == nil || !strings.Contains(err.Error(), "ATLAS_FETCH_RATE_LIMIT") {
+		t.Errorf("pull with an invalid rate: %v", err)
+	}
+}
+
+func TestSharedCacheLease(t *testing.T) {
+	bucket := &fakeBucket{objects: map[string][]byte{}}
+	ts := httptest.NewServer(bucket)
+	defer ts.Close()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ATLAS_CACHE_REMOTE", "s3://farm/atlas")
+	t.Setenv("AWS_ENDPOINT_URL", ts.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret")
+	ctx := context.Background()
+	var fetches atomic.Int32
+	fetch.Register("lease.test", slowFetcher{&fetches})
+	add := func(version string) error {
+		dir := t.TempDir()
+		srv := &server.Server{}
+		srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/lease"}) //nolint:errcheck
+		_, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "lease.test/dep", Version: version})
+		return err
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = add("v1.0.0")
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetched %d times, want once", n)
+	}
+	if _, held := bucket.objects["/farm/atlas/lease.test/dep@v1.0.0.lock"]; held {
+		t.Error("lease not released")
+	}
+
+	// The lease of a holder that died expires.
+	bucket.mu.Lock()
+	bucket.objects["/farm/atlas/lease.test/dep@v2.0.0.lock"] = []byte(`{"owner":"gone","expires":"2000-01-01T00:00:00Z"}`)
+	bucket.mu.Unlock()
+	if err := add("v2.0.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A lease taken over during the fetch is left to its new holder.
+	lock := "/farm/atlas/lease.test/dep@v3.0.0.lock"
+	taken := []byte(`{"owner":"other","expires":"2100-01-01T00:00:00Z"}`)
+	done := make(chan error)
+	go func() { done <- add("v3.0.0") }()
+	for held := false; !held; {
+		time.Sleep(10 * time.Millisecond)
+		bucket.mu.Lock()
+		if _, held = bucket.objects[lock]; held {
+			bucket.objects[lock] = taken
+		}
+		bucket.mu.Unlock()
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	bucket.mu.Lock()
+	if got := bucket.objects[lock]; !bytes.Equal(got, taken) {
+		t.Errorf("lease taken over = %s, want it left in place", got)
+	}
+	bucket.mu.Unlock()
+
+	// Waiting for a lease held elsewhere stops with the call.
+	bucket.mu.Lock()
+	bucket.objects["/farm/atlas/lease.test/dep@v4.0.0.lock"] = taken
+	bucket.mu.Unlock()
+	mod := &modfile.ModFile{HolonPath: "test/lease"}
+	mod.AddRequire("lease.test/dep", "v4.0.0")
+	dir := writeHolonMod(t, t.TempDir(), mod)
+	ctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := (&server.Server{}).Pull(ctx, &pb.PullRequest{Directory: dir})
+	if err == nil || time.Since(start) > 5*time.Second {
+		t.Errorf("pull waiting for a held lease: err = %v after %v, want it canceled", err, time.Since(start))
+	}
+}
+
+func TestPeerCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	fetch.Register("peer.test", storeFetcher{})
+	add := func() (*pb.AddResponse, error) {
+		dir := t.TempDir()
+		srv := &server.Server{}
+		srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/peer"}) //nolint:errcheck
+		return srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "peer.test/dep", Version: "v1.0.0"})
+	}
+	if _, err := add(); err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	pb.RegisterRhizomeAtlasServiceServer(gs, &server.Server{})
+	go gs.Serve(lis) //nolint:errcheck
+	defer gs.Stop()
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	peer := pb.NewRhizomeAtlasServiceClient(conn)
+
+	has, err := peer.HasEntry(ctx, &pb.HasEntryRequest{Path: "peer.test/dep", Version: "v1.0.0"})
+	if err != nil || !has.Present || has.Size == 0 {
+		t.Fatalf("HasEntry = %v, %v", has, err)
+	}
+	if has, _ := peer.HasEntry(ctx, &pb.HasEntryRequest{Path: "peer.test/dep", Version: "v9.0.0"}); has.Present {
+		t.Error("HasEntry of an uncached version: present")
+	}
+	stream, err := peer.FetchEntry(ctx, &pb.FetchEntryRequest{Path: "peer.test/dep", Version: "v1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var zipped []byte
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		zipped = append(zipped, chunk.Data...)
+	}
+	if len(zipped) < 4 || string(zipped[:4]) != "PK\x03\x04" {
+		t.Errorf("FetchEntry streamed %d bytes, not a zip archive", len(zipped))
+	}
+	stream, _ = peer.FetchEntry(ctx, &pb.FetchEntryRequest{Path: "peer.test/dep", Version: "v9.0.0"})
+	if _, err := stream.Recv(); status.Code(err) != codes.NotFound {
+		t.Errorf("FetchEntry of an uncached version: err = %v, want NotFound", err)
+	}
+
+	// A snapshot the peer lacks, or a peer down, falls back to upstream.
+	for _, uri := range []string{"tcp://" + lis.Addr().String(), "tcp://127.0.0.1:1"} {
+		t.Setenv("HOME", t.TempDir())
+		t.Setenv("ATLAS_CACHE_REMOTE", uri)
+		resp, err := add()
+		if err != nil {
+			t.Fatalf("%s: %v", uri, err)
+		}
+		if resp.Dependency.Source != "store://peer.test/dep@v1.0.0" {
+			t.Errorf("%s: source = %q, want upstream", uri, resp.Dependency.Source)
+		}
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("prefetch.test", storeFetcher{})
+	fetch.Register("offline.test", offlineFetcher{})
+
+	if _, err := srv.Prefetch(ctx, &pb.PrefetchRequest{Dependencies: []string{"prefetch.test/a"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Prefetch without version: err = %v, want InvalidArgument", err)
+	}
+
+	mod := "holon test/prefetch\n\nrequire (\n\tprefetch.test/b v1.0.0\n\toffline.test/c v1.0.0\n)\n"
+	resp, err := srv.Prefetch(ctx, &pb.PrefetchRequest{
+		Dependencies: []string{"prefetch.test/a@v1.0.0", "prefetch.test/a@v1.0.0"},
+		HolonMod:     []byte(mod),
+		Wait:         true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Queued) != 3 {
+		t.Fatalf("queued = %v, want a, b and c once each", resp.Queued)
+	}
+	for _, d := range resp.Queued[:2] {
+		if _, err := os.Stat(filepath.Join(d.CachePath, "HOLON.md")); err != nil {
+			t.Errorf("%s: %v", d.Path, err)
+		}
+	}
+	if len(resp.Errors) != 1 || !strings.HasPrefix(resp.Errors[0], "offline.test/c@v1.0.0: ") {
+		t.Errorf("errors = %v, want the offline dependency only", resp.Errors)
+	}
+
+	// Cached dependencies are not queued again; others are fetched in the
+	// background.
+	resp, err = srv.Prefetch(ctx, &pb.PrefetchRequest{Dependencies: []string{"prefetch.test/a@v1.0.0", "prefetch.test/d@v1.0.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Queued) != 1 || resp.Queued[0].Path != "prefetch.test/d" {
+		t.Fatalf("queued = %v, want d only", resp.Queued)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(server.CachePath("prefetch.test/d", "v1.0.0")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("prefetch.test/d not fetched in the background")
+		}
+	}
+}
+
+// gatedFetcher serves dependencies one release at a time, recording the
+// order the fetches start in and how many run at once.
+type gatedFetcher struct {
+	release chan struct{}
+	mu      *sync.Mutex
+	started *[]string
+	running *atomic.Int32
+	peak    *atomic.Int32
+}
+
+func (f gatedFetcher) Resolve(path, version string) ([]fetch.Source, error) {
+	return []fetch.Source{gatedSource{f, path}}, nil
+}
+
+type gatedSource struct {
+	f    gatedFetcher
+	path string
+}
+
+func (s gatedSource) String() string { return "gated://" + s.path }
+
+func (s gatedSource) Fetch(ctx context.Context, dst string) error {
+	s.f.mu.Lock()
+	*s.f.started = append(*s.f.started, s.path)
+	s.f.mu.Unlock()
+	n := s.f.running.Add(1)
+	for {
+		p := s.f.peak.Load()
+		if n <= p || s.f.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	defer s.f.running.Add(-1)
+	select {
+	case <-s.f.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return storeSource("").Fetch(ctx, dst)
+}
+
+func TestFetchScheduler(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ATLAS_FETCH_CONCURRENCY", "2")
+	ctx := context.Background()
+	srv := &server.Server{}
+	f := gatedFetcher{release: make(chan struct{}), mu: &sync.Mutex{}, started: new([]string), running: new(atomic.Int32), peak: new(atomic.Int32)}
+	fetch.Register("gate.test", f)
+	started := func() []string {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return slices.Clone(*f.started)
+	}
+
+	dir := t.TempDir()
+	mod := "holon test/gate\n\nrequire (\n"
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		mod += "\tgate.test/" + name + " v1.0.0\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "holon.mod"), []byte(mod+")\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pulled := make(chan error, 1)
+	go func() {
+		_, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir})
+		pulled <- err
+	}()
+	for deadline := time.Now().Add(5 * time.Second); len(started()) < 2; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("pull did not start fetching")
+		}
+	}
+
+	// A Prefetch of a dependency the Pull is fetching joins that fetch;
+	// its other dependency is not queued behind all of the Pull's.
+	prefetched := make(chan *pb.PrefetchResponse, 1)
+	go func() {
+		resp, err := srv.Prefetch(ctx, &pb.PrefetchRequest{Dependencies: []string{"gate.test/a@v1.0.0", "gate.test/f@v1.0.0"}, Wait: true})
+		if err != nil {
+			t.Error(err)
+		}
+		prefetched <- resp
+	}()
+	time.Sleep(100 * time.Millisecond)
+	for range 6 {
+		f.release <- struct{}{}
+	}
+	if err := <-pulled; err != nil {
+		t.Fatal(err)
+	}
+	if resp := <-prefetched; resp == nil || len(resp.Errors) > 0 {
+		t.Fatalf("prefetch = %v", resp)
+	}
+
+	order := started()
+	if len(order) != 6 {
+		t.Fatalf("fetches started = %v, want each dependency once", order)
+	}
+	if i := slices.Index(order, "gate.test/f"); i == len(order)-1 {
+		t.Errorf("fetches started = %v: the prefetch waited for the whole pull", order)
+	}
+	if peak := f.peak.Load(); peak > 2 {
+		t.Errorf("%d fetches ran at once, want at most 2", peak)
+	}
+}
+
+func TestAddByDigest(t *testing.T) {
+	gitTest(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	// The snapshot hash covers each file's relative path and content.
+	content := "---\nname: pinned\n---\n"
+	h := sha256.Sum256([]byte("HOLON.md" + content))
+	digest := hex.EncodeToString(h[:])
+
+	proxy := t.TempDir()
+	depPath := "atlas.invalid/test/pinned"
+	repo := filepath.Join(proxy, depPath, "@sha256", digest)
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "HOLON.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git := gitRepo(t, repo)
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	t.Setenv("ATLAS_PROXY", "file://"+proxy)
+
+	dir := t.TempDir()
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/digest"}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: "sha256:" + digest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Dependency.CachePath == "" {
+		t.Fatal("pinned dependency was not fetched")
+	}
+	verify, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verify.Ok {
+		t.Errorf("verify: %v", verify.Errors)
+	}
+
+	_, err = srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: "sha256:beef"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("short digest: err = %v, want InvalidArgument", err)
+	}
+}
+
+func TestBundleCreateInstall(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	// A direct dependency whose cached holon.mod requires a second one.
+	writeHolonMD(t, server.CachePath("github.com/test/a", "v0.1.0"), "name: a\n")
+	sub := &modfile.ModFile{HolonPath: "github.com/test/a"}
+	sub.AddRequire("github.com/test/b", "v0.2.0")
+	if err := sub.Write(filepath.Join(server.CachePath("github.com/test/a", "v0.1.0"), "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	writeHolonMD(t, server.CachePath("github.com/test/b", "v0.2.0"), "name: b\n")
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/bundle"}
+	mod.AddRequire("github.com/test/a", "v0.1.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(t.TempDir(), "deps.bundle")
+	created, err := srv.BundleCreate(ctx, &pb.BundleCreateRequest{Directory: dir, Output: out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created.Dependencies) != 2 {
+		t.Fatalf("bundled %d dependencies, want 2", len(created.Dependencies))
+	}
+
+	// Install on a "machine" with an empty cache.
+	t.Setenv("HOME", t.TempDir())
+	target := t.TempDir()
+	installed, err := srv.BundleInstall(ctx, &pb.BundleInstallRequest{Input: out, Directory: target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(installed.Installed) != 2 || installed.ModFile == "" {
+		t.Fatalf("installed = %v, mod file %q", installed.Installed, installed.ModFile)
+	}
+	if _, err := os.Stat(filepath.Join(server.CachePath("github.com/test/b", "v0.2.0"), "HOLON.md")); err != nil {
+		t.Errorf("transitive dependency not installed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "holon.mod")); err != nil {
+		t.Errorf("holon.mod not written: %v", err)
+	}
+
+	// A bundle listing entries outside the cache, or on another entry, is
+	// refused before anything is installed.
+	for _, entry := range []string{"../../escaped@v1.0.0", "github.com/test/a/../b@v0.2.0", "github.com/test/c@../../v1"} {
+		depPath, version, _ := strings.Cut(entry, "@")
+		content := "---\nname: forged\n---\n"
+		h := sha256.Sum256([]byte("HOLON.md" + content))
+		forged := filepath.Join(t.TempDir(), "forged.bundle")
+		f, err := os.Create(forged)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+		files := map[string]string{
+			"bundle.sum": fmt.Sprintf("%s %s sha256:%x\n", depPath, version, h),
+			"holon.sum":  "",
+		}
+		if name := "cache/" + entry + "/HOLON.md"; filepath.IsLocal(name) {
+			files[name] = content
+		}
+		for name, data := range files {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.WriteString(tw, data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, c := range []io.Closer{tw, gz, f} {
+			if err := c.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, err = srv.BundleInstall(ctx, &pb.BundleInstallRequest{Input: forged})
+		if status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), "bundle.sum: invalid entry") {
+			t.Errorf("install of %s: err = %v, want InvalidArgument", entry, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(server.CacheDir()), "escaped@v1.0.0")); !os.IsNotExist(err) {
+		t.Errorf("bundle installed outside the cache: %v", err)
+	}
+}
+
+func TestSumPrune(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	// a requires t, which holon.lock selects at a higher version, as the
+	// highest strategy does.
+	aCache := server.CachePath("github.com/test/a", "v0.2.0")
+	writeHolonMD(t, aCache, "name: a\n")
+	aMod := "holon github.com/test/a\n\nrequire (\n    github.com/test/t v1.0.0\n)\n"
+	if err := os.WriteFile(filepath.Join(aCache, "holon.mod"), []byte(aMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mod := &modfile.ModFile{HolonPath: "test/prune"}
+	mod.AddRequire("github.com/test/a", "v0.2.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	lock := &modfile.LockFile{Strategy: modfile.StrategyHighest, Selected: []modfile.Selection{
+		{Path: "github.com/test/a", Version: "v0.2.0", RequiredBy: []modfile.Requirement{{Holon: "test/prune", Version: "v0.2.0"}}},
+		{Path: "github.com/test/t", Version: "v1.1.0", RequiredBy: []modfile.Requirement{{Holon: "github.com/test/a", Version: "v1.0.0"}}},
+	}}
+	if err := lock.Write(filepath.Join(dir, "holon.lock")); err != nil {
+		t.Fatal(err)
+	}
+	sum := &modfile.SumFile{}
+	sum.Set("github.com/test/a", "v0.1.0", "h1:old")
+	sum.Set("github.com/test/a", "v0.1.0/HOLON.md", "h1:oldmd")
+	sum.Set("github.com/test/a", "v0.2.0", "h1:new")
+	sum.Set("github.com/test/gone", "v1.0.0", "h1:gone")
+	sum.Set("github.com/test/t", "v0.9.0", "h1:t0.9")
+	sum.Set("github.com/test/t", "v1.0.0", "h1:t1.0")
+	sum.Set("github.com/test/t", "v1.1.0", "h1:t1.1")
+	if err := sum.Write(filepath.Join(dir, "holon.sum")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without t cached, its requirements are unknown: nothing is pruned.
+	if _, err := srv.SumPrune(ctx, &pb.SumPruneRequest{Directory: dir, DryRun: true}); errorReason(err) != client.ReasonNotCached {
+		t.Fatalf("prune with t not cached: %v, want %s", err, client.ReasonNotCached)
+	}
+	writeHolonMD(t, server.CachePath("github.com/test/t", "v1.0.0"), "name: t\n")
+
+	dry, err := srv.SumPrune(ctx, &pb.SumPruneRequest{Directory: dir, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dry.Removed) != 4 {
+		t.Errorf("dry run removed %v, want 4 entries", dry.Removed)
+	}
+
+	if _, err := srv.SumPrune(ctx, &pb.SumPruneRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	pruned, err := modfile.ParseSum(filepath.Join(dir, "holon.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned.Entries) != 3 || pruned.Lookup("github.com/test/a", "v0.2.0") != "h1:new" ||
+		pruned.Lookup("github.com/test/t", "v1.0.0") != "h1:t1.0" || pruned.Lookup("github.com/test/t", "v1.1.0") != "h1:t1.1" {
+		t.Errorf("entries after prune = %v", pruned.Entries)
+	}
+}
+
+func TestTidy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		writeHolonMD(t, server.CachePath("github.com/tidy/"+name, "v1.0.0"), "name: "+name+"\n")
+	}
+	aMod := "holon github.com/tidy/a\n\nrequire (\n    github.com/tidy/c v1.0.0\n)\n"
+	if err := os.WriteFile(filepath.Join(server.CachePath("github.com/tidy/a", "v1.0.0"), "holon.mod"), []byte(aMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a is used by a script; c is required by a; d is kept by annotation;
+	// b is only named in the journal and in a nested holon.
+	modText := "holon test/tidy\n\nrequire (\n" +
+		"    github.com/tidy/a v1.0.0\n" +
+		"    github.com/tidy/b v1.0.0\n" +
+		"    github.com/tidy/c v1.0.0\n" +
+		"    github.com/tidy/d v1.0.0 // tidy=keep\n" +
+		")\n"
+	files := map[string]string{
+		"holon.mod":     modText,
+		"build.sh":      "cp -r \"$HOLON_DEP_A\"/lib out/\n",
+		".holon/notes":  "github.com/tidy/b\n",
+		"sub/holon.mod": "holon test/tidy/sub\n",
+		"sub/run.sh":    "echo $HOLON_DEP_B\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sum := &modfile.SumFile{}
+	sum.Set("github.com/tidy/a", "v1.0.0", "h1:a")
+	sum.Set("github.com/tidy/b", "v1.0.0", "h1:b")
+	sum.Set("github.com/tidy/gone", "v1.0.0", "h1:gone")
+	sum.Set("github.com/tidy/c", "v1.1.0", "h1:c1.1")
+	if err := sum.Write(filepath.Join(dir, "holon.sum")); err != nil {
+		t.Fatal(err)
+	}
+	// holon.lock selects c at a higher version, as the highest strategy
+	// does: its entry is reachable.
+	lock := &modfile.LockFile{Strategy: modfile.StrategyHighest, Selected: []modfile.Selection{
+		{Path: "github.com/tidy/c", Version: "v1.1.0", RequiredBy: []modfile.Requirement{{Holon: "test/tidy", Version: "v1.0.0"}}},
+	}}
+	if err := lock.Write(filepath.Join(dir, "holon.lock")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := func(es []*pb.SumEntry) []string {
+		var out []string
+		for _, e := range es {
+			out = append(out, e.Path+"@"+e.Version)
+		}
+		slices.Sort(out)
+		return out
+	}
+	dry, err := srv.Tidy(ctx, &pb.TidyRequest{Directory: dir, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dry.Dropped) != 1 || dry.Dropped[0].Path != "github.com/tidy/b" {
+		t.Errorf("dropped = %v, want github.com/tidy/b", dry.Dropped)
+	}
+	if got, want := entries(dry.Removed), []string{"github.com/tidy/b@v1.0.0", "github.com/tidy/gone@v1.0.0"}; !slices.Equal(got, want) {
+		t.Errorf("removed = %v, want %v", got, want)
+	}
+	if got, want := entries(dry.Added), []string{
+		"github.com/tidy/c@v1.0.0", "github.com/tidy/c@v1.0.0/HOLON.md",
+		"github.com/tidy/d@v1.0.0", "github.com/tidy/d@v1.0.0/HOLON.md",
+	}; !slices.Equal(got, want) {
+		t.Errorf("added = %v, want %v", got, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "holon.mod")); string(data) != modText {
+		t.Errorf("dry run wrote holon.mod:\n%s", data)
+	}
+
+	// With --sum-only, b stays required and its entry reachable.
+	sumOnly, err := srv.Tidy(ctx, &pb.TidyRequest{Directory: dir, DryRun: true, KeepRequires: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sumOnly.Dropped) != 0 || !slices.Equal(entries(sumOnly.Removed), []string{"github.com/tidy/gone@v1.0.0"}) {
+		t.Errorf("sum-only dropped %v, removed %v", sumOnly.Dropped, entries(sumOnly.Removed))
+	}
+
+	if _, err := srv.Tidy(ctx, &pb.TidyRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	tidied, err := modfile.Parse(filepath.Join(dir, "holon.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var required []string
+	for _, r := range tidied.Require {
+		required = append(required, r.Path)
+	}
+	if want := []string{"github.com/tidy/a", "github.com/tidy/c", "github.com/tidy/d"}; !slices.Equal(required, want) {
+		t.Errorf("requires after tidy = %v, want %v", required, want)
+	}
+	sum, err = modfile.ParseSum(filepath.Join(dir, "holon.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Lookup("github.com/tidy/b", "v1.0.0") != "" || sum.Lookup("github.com/tidy/d", "v1.0.0") == "" ||
+		sum.Lookup("github.com/tidy/c", "v1.1.0") != "h1:c1.1" {
+		t.Errorf("entries after tidy = %v", sum.Entries)
+	}
+
+	again, err := srv.Tidy(ctx, &pb.TidyRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Dropped)+len(again.Removed)+len(again.Added) != 0 {
+		t.Errorf("second tidy changed %v %v %v", again.Dropped, again.Removed, again.Added)
+	}
+}
+
+func TestUpdateNoRemote(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	// Setup with a fake dep (no remote to query)
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/up"}) //nolint:errcheck
+	srv.Add(ctx, &pb.AddRequest{
+		Directory:  dir,
+		Path:       "github.com/test/nonexistent",
+		Version:    "v0.1.0",
+		RecordOnly: true,
+	}) //nolint:errcheck
+
+	// Update should not fail — just log and skip
+	resp, err := srv.Update(ctx, &pb.UpdateRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Updated) != 0 {
+		t.Errorf("expected 0 updates for unreachable dep, got %d", len(resp.Updated))
+	}
+	if len(resp.Held) != 0 {
+		t.Errorf("expected 0 held updates for unreachable dep, got %d", len(resp.Held))
+	}
+}
+
+func TestUpdateHoldsBreaking(t *testing.T) {
+	registry := gitRegistry(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	depPath := "atlas.invalid/test/contract"
+	repo := filepath.Join(registry, depPath)
+	writeHolonMD(t, repo, "name: contract\ncapabilities: [a, b]\n")
+	tagRelease(t, repo, "v0.1.0")
+	writeHolonMD(t, repo, "name: contract\ncapabilities: [a]\n")
+	tagRelease(t, repo, "v0.2.0")
+
+	dir := t.TempDir()
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/contract"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: "v0.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// v0.2.0 drops capability b: it is held unless allow_breaking.
+	resp, err := srv.Update(ctx, &pb.UpdateRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Updated) != 0 || len(resp.Held) != 1 || resp.Held[0].NewVersion != "v0.2.0" ||
+		fmt.Sprint(resp.Held[0].RemovedCapabilities) != "[b]" || resp.Held[0].ContractError != "" {
+		t.Fatalf("update = %v, want v0.2.0 held for removing b", resp)
+	}
+	if mod, _ := modfile.Parse(filepath.Join(dir, "holon.mod")); mod.Require[0].Version != "v0.1.0" {
+		t.Errorf("held update applied: %s", mod.Require[0].Version)
+	}
+	resp, err = srv.Update(ctx, &pb.UpdateRequest{Directory: dir, AllowBreaking: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Updated) != 1 || resp.Updated[0].NewVersion != "v0.2.0" || len(resp.Held) != 0 {
+		t.Fatalf("update allowing breaking = %v, want v0.2.0 applied", resp)
+	}
+
+	// A HOLON.md that cannot be read leaves the contract unchecked, which
+	// holds the update the same way.
+	if err := os.WriteFile(filepath.Join(repo, "HOLON.md"), []byte("---\nnot front-matter\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tagRelease(t, repo, "v0.3.0")
+	resp, err = srv.Update(ctx, &pb.UpdateRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Updated) != 0 || len(resp.Held) != 1 || resp.Held[0].ContractError == "" {
+		t.Fatalf("update = %v, want v0.3.0 held for its contract error", resp)
+	}
+	resp, err = srv.Update(ctx, &pb.UpdateRequest{Directory: dir, AllowBreaking: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Updated) != 1 || resp.Updated[0].NewVersion != "v0.3.0" {
+		t.Fatalf("update allowing breaking = %v, want v0.3.0 applied", resp)
+	}
+}
+
+func TestCacheListPagination(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	// "dep" and "dep-x" order differently as "path@version" strings.
+	for _, p := range []string{"test/dep@v1.0.0", "test/dep@v1.1.0", "test/dep-x@v1.0.0", "test/other@v0.1.0", "test/z@v2.0.0"} {
+		if err := os.MkdirAll(filepath.Join(server.CacheDir(), p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	req := &pb.CacheListRequest{PageSize: 2}
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+		resp, err := srv.CacheList(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Entries) > 2 {
+			t.Errorf("page has %d entries, want at most 2", len(resp.Entries))
+		}
+		for _, e := range resp.Entries {
+			got = append(got, e.Path+"@"+e.Version)
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+
+	want := []string{"test/dep@v1.0.0", "test/dep@v1.1.0", "test/dep-x@v1.0.0", "test/other@v0.1.0", "test/z@v2.0.0"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+
+	if _, err := srv.CacheList(ctx, &pb.CacheListRequest{PageToken: "!!"}); err == nil {
+		t.Error("expected error for invalid page_token")
+	}
+}
+
+// --- mem:// transport test ---
+
+func TestMemTransport(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	mem := transport.NewMemListener()
+	s := grpc.NewServer()
+	pb.RegisterRhizomeAtlasServiceServer(s, &server.Server{})
+	go func() { _ = s.Serve(mem) }()
+	defer s.Stop()
+
+	conn, err := grpc.NewClient(
+		"passthrough:///mem",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return mem.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := pb.NewRhizomeAtlasServiceClient(conn)
+
+	// Init over mem://
+	initResp, err := client.Init(ctx, &pb.InitRequest{
+		Directory: dir,
+		HolonPath: "test/mem-holon",
+	})
+	if err != nil {
+		t.Fatalf("Init over mem://: %v", err)
+	}
+	if initResp.ModFile == "" {
+		t.Error("expected mod_file path")
+	}
+
+	// Graph over mem://
+	graphResp, err := client.Graph(ctx, &pb.GraphRequest{Directory: dir})
+	if err != nil {
+		t.Fatalf("Graph over mem://: %v", err)
+	}
+	if graphResp.Root != "test/mem-holon" {
+		t.Errorf("root = %q", graphResp.Root)
+	}
+}
+
+func TestStreamGraph(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	mem := transport.NewMemListener()
+	s := grpc.NewServer()
+	pb.RegisterRhizomeAtlasServiceServer(s, &server.Server{})
+	go func() { _ = s.Serve(mem) }()
+	defer s.Stop()
+
+	conn, err := grpc.NewClient(
+		"passthrough:///mem",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return mem.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewRhizomeAtlasServiceClient(conn)
+
+	mod := &modfile.ModFile{HolonPath: "test/stream"}
+	for i := 0; i < 5; i++ {
+		mod.AddRequire(fmt.Sprintf("github.com/test/dep-%d", i), "v0.1.0")
+	}
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := client.StreamGraph(ctx, &pb.StreamGraphRequest{Directory: dir, ChunkSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chunks, edges int
+	var summary *pb.GraphSummary
+	var nodes []*pb.Node
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chunk.Root != "test/stream" {
+			t.Errorf("chunk root = %q", chunk.Root)
+		}
+		chunks++
+		edges += len(chunk.Edges)
+		summary = chunk.Summary
+		nodes = chunk.Nodes
+	}
+	// 2 + 2 + 1 edges, then the summary
+	if chunks != 4 || edges != 5 {
+		t.Errorf("chunks = %d, edges = %d; want 4, 5", chunks, edges)
+	}
+	if summary == nil || summary.EdgeCount != 5 {
+		t.Errorf("summary = %v, want edge_count 5", summary)
+	}
+	if len(nodes) != 6 || nodes[0].Path != "test/stream" {
+		t.Errorf("nodes of the last chunk = %v, want the root and 5 dependencies", nodes)
+	}
+}
+
+// --- ws:// transport test ---
+
+func TestWSTransport(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	wsLis, err := transport.Listen("ws://127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ws listen: %v", err)
+	}
+	defer wsLis.Close()
+
+	s := grpc.NewServer()
+	pb.RegisterRhizomeAtlasServiceServer(s, &server.Server{})
+	reflection.Register(s)
+	go func() { _ = s.Serve(wsLis) }()
+	defer s.Stop()
+
+	// Connect via WebSocket
+	wsAddr := wsLis.Addr().String()
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	c, _, err := websocket.Dial(dialCtx, wsAddr, &websocket.DialOptions{
+		Subprotocols: []string{"grpc"},
+	})
+	if err != nil {
+		t.Fatalf("ws dial: %v", err)
+	}
+	wsConn := websocket.NetConn(dialCtx, c, websocket.MessageBinary)
+
+	dialed := false
+	//nolint:staticcheck
+	conn, err := grpc.DialContext(dialCtx,
+		"passthrough:///ws",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(_ context.Context, _ string) (net.Conn, error) {
+			if dialed {
+				return nil, fmt.Errorf("already consumed")
+			}
+			dialed = true
+			return wsConn, nil
+		}),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		wsConn.Close()
+		t.Fatalf("grpc dial over ws: %v", err)
+	}
+	defer conn.Close()
+
+	client := pb.NewRhizomeAtlasServiceClient(conn)
+
+	// Init over ws://
+	initResp, err := client.Init(ctx, &pb.InitRequest{
+		Directory: dir,
+		HolonPath: "test/ws-holon",
+	})
+	if err != nil {
+		t.Fatalf("Init over ws://: %v", err)
+	}
+	if initResp.ModFile == "" {
+		t.Error("expected mod_file path")
+	}
+
+	// Graph over ws://
+	graphResp, err := client.Graph(ctx, &pb.GraphRequest{Directory: dir})
+	if err != nil {
+		t.Fatalf("Graph over ws://: %v", err)
+	}
+	if graphResp.Root != "test/ws-holon" {
+		t.Errorf("root = %q", graphResp.Root)
+	}
+}
+
+// --- TLS ---
+
+// writeCert writes a certificate for 127.0.0.1 and its key as PEM files in
+// dir, signed by parent (self-signed when nil), and returns them.
+func writeCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)      //nolint:errcheck
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600) //nolint:errcheck
+	return cert, key, certFile, keyFile
+}
+
+func TestTLSOptions(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey, caFile, _ := writeCert(t, dir, "ca", nil, nil)
+	_, _, certFile, keyFile := writeCert(t, dir, "server", ca, caKey)
+	_, _, clientCert, clientKey := writeCert(t, dir, "client", ca, caKey)
+
+	for _, bad := range []server.TLSOptions{
+		{CertFile: certFile},
+		{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.0"},
+		{CertFile: certFile, KeyFile: keyFile, CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+		{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3", CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}},
+		{CertFile: certFile, KeyFile: keyFile, ClientAuth: "require-and-verify"},
+		{CertFile: certFile, KeyFile: keyFile, ClientAuth: "maybe", ClientCAFile: caFile},
+	} {
+		if _, err := bad.Config(); err == nil {
+			t.Errorf("Config(%+v) succeeded", bad)
+		}
+	}
+
+	cfg, err := server.TLSOptions{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		CipherSuites: server.ParseCipherSuites("TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"),
+		ClientCAFile: caFile,
+	}.Config()
+	if err != nil {
+		t.Fatalf("Config: %v", err)
+	}
+	if cfg.MinVersion != tls.VersionTLS12 || cfg.ClientAuth != tls.RequireAndVerifyClientCert || len(cfg.CipherSuites) != 2 {
+		t.Fatalf("Config = min %x, client auth %v, %d suites", cfg.MinVersion, cfg.ClientAuth, len(cfg.CipherSuites))
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer(grpc.Creds(credentials.NewTLS(cfg)))
+	pb.RegisterRhizomeAtlasServiceServer(gs, &server.Server{})
+	go func() { _ = gs.Serve(lis) }()
+	defer gs.Stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	pair, err := tls.LoadX509KeyPair(clientCert, clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := func(client *tls.Config) error {
+		client.RootCAs = roots
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(client)))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = pb.NewRhizomeAtlasServiceClient(conn).HasEntry(ctx, &pb.HasEntryRequest{Path: "example.com/x", Version: "v1.0.0"})
+		return err
+	}
+
+	if err := call(&tls.Config{Certificates: []tls.Certificate{pair}}); err != nil {
+		t.Fatalf("with a client certificate: %v", err)
+	}
+	if err := call(&tls.Config{}); err == nil {
+		t.Fatal("succeeded without a client certificate")
+	}
+	if err := call(&tls.Config{Certificates: []tls.Certificate{pair}, MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}}); err == nil {
+		t.Fatal("succeeded with a cipher suite not allowed")
+	}
+}
+
+// --- unix:// transport ---
+
+func TestUnixSocket(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sock := filepath.Join(t.TempDir(), "atlas.sock")
+
+	// A socket left behind by a server that died is replaced.
+	stale, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	srv := &server.Server{}
+	served := make(chan error, 1)
+	go func() { served <- srv.ListenAndServe("unix://"+sock, false) }()
+
+	c, err := client.Dial("unix://" + sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_, err := c.Service().HasEntry(ctx, &pb.HasEntryRequest{Path: "example.com/x", Version: "v1.0.0"})
+		cancel()
+		if err == nil {
+			break
+		}
+		select {
+		case err := <-served:
+			t.Fatalf("ListenAndServe: %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("HasEntry over unix socket: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	info, err := os.Stat(sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o660 {
+		t.Errorf("socket mode = %v, want 0660", info.Mode().Perm())
+	}
+
+	// A socket still served is not taken over, nor is another file.
+	if err := srv.ListenAndServe("unix://"+sock, false); err == nil || !strings.Contains(err.Error(), "another server") {
+		t.Errorf("second ListenAndServe = %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(file, nil, 0o644) //nolint:errcheck
+	if err := srv.ListenAndServe("unix://"+file, false); err == nil {
+		t.Error("ListenAndServe over a regular file succeeded")
+	}
+}
+
+// --- ServeOptions ---
+
+func TestServeOptions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sock := filepath.Join(t.TempDir(), "atlas.sock")
+
+	var calls atomic.Int32
+	started := make(chan *grpc.Server, 1)
+	stopped := make(chan error, 1)
+	srv := &server.Server{}
+	go srv.Serve("unix://"+sock, server.ServeOptions{ //nolint:errcheck // reported to OnStop
+		UnaryInterceptors: []grpc.UnaryServerInterceptor{
+			func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				calls.Add(1)
+				return handler(ctx, req)
+			},
+		},
+		Register: func(gs *grpc.Server) {
+			healthpb.RegisterHealthServer(gs, health.NewServer())
+		},
+		OnStart: func(gs *grpc.Server) { started <- gs },
+		OnStop:  func(err error) { stopped <- err },
+	})
+	gs := <-started
+
+	conn, err := grpc.NewClient("unix://"+sock, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := pb.NewRhizomeAtlasServiceClient(conn).HasEntry(ctx, &pb.HasEntryRequest{Path: "example.com/x", Version: "v1.0.0"}, grpc.WaitForReady(true)); err != nil {
+		t.Fatalf("HasEntry: %v", err)
+	}
+	if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("health Check: %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("interceptor saw %d calls, want 2", n)
+	}
+
+	gs.GracefulStop()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("OnStop(%v)", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnStop not called")
+	}
+	if _, err := os.Stat(sock); !os.IsNotExist(err) {
+		t.Errorf("socket left behind: %v", err)
+	}
+}
+
+func TestReadOnlyServe(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sock := filepath.Join(t.TempDir(), "atlas.sock")
+	dir := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv := &server.Server{}
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/readonly"}); err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan *grpc.Server, 1)
+	go srv.Serve("unix://"+sock, server.ServeOptions{ //nolint:errcheck // stopped below
+		ReadOnly:   true,
+		Reflection: true,
+		Register:   func(gs *grpc.Server) { healthpb.RegisterHealthServer(gs, health.NewServer()) },
+		OnStart:    func(gs *grpc.Server) { started <- gs },
+	})
+	gs := <-started
+	defer gs.Stop()
+	conn, err := grpc.NewClient("unix://"+sock, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	rpc := pb.NewRhizomeAtlasServiceClient(conn)
+
+	// Queries and dry runs are served.
+	if _, err := rpc.Graph(ctx, &pb.GraphRequest{Directory: dir}, grpc.WaitForReady(true)); err != nil {
+		t.Errorf("Graph: %v", err)
+	}
+	if _, err := rpc.Add(ctx, &pb.AddRequest{Directory: dir, Path: "example.com/x", Version: "v1.0.0", DryRun: true}); err != nil {
+		t.Errorf("Add dry run: %v", err)
+	}
+
+	// So are the other services: reflection, and those registered.
+	if services, err := listServices(ctx, conn); err != nil || !slices.Contains(services, "rhizome_atlas.v1.RhizomeAtlasService") {
+		t.Errorf("reflection lists %v, %v", services, err)
+	}
+	if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("health check: %v", err)
+	}
+
+	// Anything writing is refused, and nothing is written.
+	_, err = rpc.Add(ctx, &pb.AddRequest{Directory: dir, Path: "example.com/x", Version: "v1.0.0", RecordOnly: true})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Add: err = %v, want PermissionDenied", err)
+	}
+	if mod, _ := modfile.Parse(filepath.Join(dir, "holon.mod")); len(mod.Require) != 0 {
+		t.Errorf("holon.mod written: %v", mod.Require)
+	}
+	if _, err := rpc.CleanCache(ctx, &pb.CleanCacheRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("CleanCache: err = %v, want PermissionDenied", err)
+	}
+	if _, err := rpc.Verify(ctx, &pb.VerifyRequest{Directory: dir, Repair: true}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Verify with repair: err = %v, want PermissionDenied", err)
+	}
+	stream, err := rpc.StreamVendor(ctx, &pb.StreamVendorRequest{Directory: dir})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("StreamVendor: err = %v, want PermissionDenied", err)
+	}
+}
+
+// listServices lists the services conn serves, through reflection.
+func listServices(ctx context.Context, conn *grpc.ClientConn) ([]string, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend() //nolint:errcheck
+	req := &reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}}
+	if err := stream.Send(req); err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	var services []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		services = append(services, s.Name)
+	}
+	return services, nil
+}
+
+func TestAuthServe(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sock := filepath.Join(t.TempDir(), "atlas.sock")
+	dir := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv := &server.Server{}
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/auth"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfgFile := filepath.Join(t.TempDir(), "auth")
+	if err := os.WriteFile(cfgFile, []byte("# who grants\ntoken reader read\ntoken ci read Add # record only\ntoken root admin\nanonymous Graph\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := server.LoadAuthConfig(cfgFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfgFile, []byte("token x read Fly\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.LoadAuthConfig(cfgFile); err == nil || !strings.Contains(err.Error(), `"Fly"`) {
+		t.Errorf("unknown method: err = %v", err)
+	}
+
+	started := make(chan *grpc.Server, 1)
+	go srv.Serve("unix://"+sock, server.ServeOptions{ //nolint:errcheck // stopped below
+		Auth:       cfg,
+		Reflection: true,
+		Register:   func(gs *grpc.Server) { healthpb.RegisterHealthServer(gs, health.NewServer()) },
+		OnStart:    func(gs *grpc.Server) { started <- gs },
+	})
+	gs := <-started
+	defer gs.Stop()
+	dial := func(opts ...client.Option) *client.Client {
+		c, err := client.Dial("unix://"+sock, append(opts, client.WithRetryPolicy(client.RetryPolicy{}))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { c.Close() })
+		return c
+	}
+	add := &pb.AddRequest{Directory: dir, Path: "example.com/x", Version: "v1.0.0", RecordOnly: true}
+
+	// Anonymous calls get the grants of anonymous; an unknown token none.
+	anonymous := dial()
+	if _, err := anonymous.Graph(ctx, dir); err != nil {
+		t.Errorf("Graph without token: %v", err)
+	}
+	if _, err := anonymous.Verify(ctx, dir); !errors.Is(err, client.ErrPermissionDenied) {
+		t.Errorf("Verify without token: err = %v, want ErrPermissionDenied", err)
+	}
+	if _, err := dial(client.WithToken("guess")).Graph(ctx, dir); !errors.Is(err, client.ErrUnauthenticated) {
+		t.Errorf("Graph with unknown token: err = %v, want ErrUnauthenticated", err)
+	}
+
+	// read serves what a read-only server does.
+	reader := dial(client.WithToken("reader"))
+	if _, err := reader.Graph(ctx, dir); err != nil {
+		t.Errorf("Graph as reader: %v", err)
+	}
+	if _, err := reader.Add(ctx, add); !errors.Is(err, client.ErrPermissionDenied) {
+		t.Errorf("Add as reader: err = %v, want ErrPermissionDenied", err)
+	}
+	stream, err := reader.Service().StreamVendor(ctx, &pb.StreamVendorRequest{Directory: dir})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("StreamVendor as reader: err = %v, want PermissionDenied", err)
+	}
+
+	// read allows reflection, but not the other services registered.
+	conn, err := grpc.NewClient("unix://"+sock, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	readerCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer reader")
+	if services, err := listServices(readerCtx, conn); err != nil || !slices.Contains(services, "grpc.health.v1.Health") {
+		t.Errorf("reflection as reader lists %v, %v", services, err)
+	}
+	if _, err := listServices(ctx, conn); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("reflection without token: err = %v, want PermissionDenied", err)
+	}
+	if _, err := healthpb.NewHealthClient(conn).Check(readerCtx, &healthpb.HealthCheckRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("health check as reader: err = %v, want PermissionDenied", err)
+	}
+
+	// A method granted by name is served, and no other.
+	ci := dial(client.WithToken("ci"))
+	if _, err := ci.Add(ctx, add); err != nil {
+		t.Errorf("Add as ci: %v", err)
+	}
+	if _, err := ci.Service().CleanCache(ctx, &pb.CleanCacheRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("CleanCache as ci: err = %v, want PermissionDenied", err)
+	}
+	if _, err := dial(client.WithToken("root")).Remove(ctx, &pb.RemoveRequest{Directory: dir, Path: "example.com/x"}); err != nil {
+		t.Errorf("Remove as root: %v", err)
+	}
+}
+
+// --- Long-running operations ---
+
+func TestLongRunningOperations(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	fetch.Register("lro.test", storeFetcher{})
+
+	mem := transport.NewMemListener()
+	s := grpc.NewServer()
+	pb.RegisterRhizomeAtlasServiceServer(s, &server.Server{})
+	go func() { _ = s.Serve(mem) }()
+	defer s.Stop()
+	conn, err := grpc.NewClient("passthrough:///mem",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return mem.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	rpc := pb.NewRhizomeAtlasServiceClient(conn)
+
+	if _, err := rpc.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/lro"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rpc.Add(ctx, &pb.AddRequest{Directory: dir, Path: "lro.test/dep", Version: "v1.0.0", RecordOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	op, err := rpc.StartPull(ctx, &pb.PullRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Id == "" || op.Method != "Pull" {
+		t.Fatalf("StartPull = %v", op)
+	}
+
+	// A client that lost its stream watches again, or polls, by ID.
+	stream, err := rpc.WatchOperation(ctx, &pb.GetOperationRequest{Id: op.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last *pb.Operation
+	for {
+		o, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		last = o
+	}
+	if last == nil || !last.Done || last.ErrorCode != 0 || len(last.Pull.GetFetched()) != 1 {
+		t.Fatalf("last watched = %v", last)
+	}
+	got, err := rpc.GetOperation(ctx, &pb.GetOperationRequest{Id: op.Id})
+	if err != nil || !proto.Equal(got, last) {
+		t.Errorf("GetOperation = %v, %v, want %v", got, err, last)
+	}
+
+	op, err = rpc.StartUpdate(ctx, &pb.UpdateRequest{Directory: filepath.Join(dir, "missing")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !op.Done && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		if op, err = rpc.GetOperation(ctx, &pb.GetOperationRequest{Id: op.Id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !op.Done || codes.Code(op.ErrorCode) != codes.NotFound || op.Update != nil {
+		t.Errorf("StartUpdate without holon.mod = %v", op)
+	}
+
+	if _, err := rpc.GetOperation(ctx, &pb.GetOperationRequest{Id: "nope"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetOperation(unknown) = %v, want NotFound", err)
+	}
+}
+
+// blockingFetcher writes part of a snapshot, signals started, then blocks
+// until its fetch is canceled.
+type blockingFetcher struct{ started chan struct{} }
+
+func (f blockingFetcher) Resolve(path, version string) ([]fetch.Source, error) {
+	return []fetch.Source{blockingSource(f)}, nil
+}
+
+type blockingSource blockingFetcher
+
+func (blockingSource) String() string { return "blocking://" }
+
+func (b blockingSource) Fetch(ctx context.Context, dst string) error {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	os.WriteFile(filepath.Join(dst, "partial"), []byte("x"), 0o644) //nolint:errcheck
+	close(b.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCancelOperation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+	started := make(chan struct{})
+	fetch.Register("cancel.test", blockingFetcher{started: started})
+
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/cancel"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "cancel.test/huge", Version: "v1.0.0", RecordOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	op, err := srv.StartPull(ctx, &pb.PullRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if _, err := srv.CancelOperation(ctx, &pb.CancelOperationRequest{Id: op.Id}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !op.Done && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		if op, err = srv.GetOperation(ctx, &pb.GetOperationRequest{Id: op.Id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !op.Done || codes.Code(op.ErrorCode) != codes.Canceled {
+		t.Fatalf("canceled pull = %v", op)
+	}
+
+	// Nothing of the partial fetch is left, and holon.sum is untouched.
+	if _, err := os.Stat(server.CachePath("cancel.test/huge", "v1.0.0")); !os.IsNotExist(err) {
+		t.Errorf("cache entry after cancel: %v", err)
+	}
+	if staged, _ := filepath.Glob(filepath.Join(filepath.Dir(server.CacheDir()), "fetch-*")); len(staged) > 0 {
+		t.Errorf("staging left behind: %v", staged)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "holon.sum")); !os.IsNotExist(err) {
+		t.Errorf("holon.sum after cancel: %v", err)
+	}
+
+	// Canceling again, once done, changes nothing.
+	again, err := srv.CancelOperation(ctx, &pb.CancelOperationRequest{Id: op.Id})
+	if err != nil || !proto.Equal(again, op) {
+		t.Errorf("CancelOperation(done) = %v, %v", again, err)
+	}
+	if _, err := srv.CancelOperation(ctx, &pb.CancelOperationRequest{Id: "nope"}); status.Code(err) != codes.NotFound {
+		t.Errorf("CancelOperation(unknown) = %v, want NotFound", err)
+	}
+}
+
+// TestCancelAdd checks that canceling the context of an Add stops its
+// fetch and leaves holon.mod as it was, and that Vendor stops too.
+func TestCancelAdd(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	srv := &server.Server{}
+	started := make(chan struct{})
+	fetch.Register("cancel.add.test", blockingFetcher{started: started})
+	if _, err := srv.Init(context.Background(), &pb.InitRequest{Directory: dir, HolonPath: "test/cancel"}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(filepath.Join(dir, "holon.mod"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "cancel.add.test/huge", Version: "v1.0.0"})
+		errc <- err
+	}()
+	<-started
+	cancel()
+	select {
+	case err := <-errc:
+		if status.Code(err) != codes.Canceled {
+			t.Fatalf("canceled add = %v, want Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("add not stopped by its context")
+	}
+	if after, _ := os.ReadFile(filepath.Join(dir, "holon.mod")); !bytes.Equal(after, before) {
+		t.Errorf("holon.mod after cancel:\n%s", after)
+	}
+	if _, err := os.Stat(server.CachePath("cancel.add.test/huge", "v1.0.0")); !os.IsNotExist(err) {
+		t.Errorf("cache entry after cancel: %v", err)
+	}
+
+	if _, err := srv.Add(context.Background(), &pb.AddRequest{Directory: dir, Path: "cancel.add.test/huge", Version: "v1.0.0", RecordOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(server.CachePath("cancel.add.test/huge", "v1.0.0"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir}); status.Code(err) != codes.Canceled {
+		t.Errorf("vendor with a canceled context = %v, want Canceled", err)
+	}
+}
+
+func TestMirrorSync(t *testing.T) {
+	gitTest(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	upstream, mirror := t.TempDir(), t.TempDir()
+	holon := func(depPath string, tags ...string) string {
+		repo := filepath.Join(upstream, depPath)
+		writeHolonMD(t, repo, "name: "+filepath.Base(depPath)+"\n")
+		git := gitRepo(t, repo)
+		git("add", ".")
+		git("commit", "-q", "-m", "init")
+		for _, tag := range tags {
+			git("tag", tag)
+		}
+		return repo
+	}
+	a := holon("atlas.invalid/org/a", "v1.0.0", "v1.1.0", "v1.2.0", "v2.0.0-rc.1")
+	holon("atlas.invalid/org/b", "v0.1.0")
+	holon("atlas.invalid/other/c", "v1.0.0")
+
+	req := &pb.MirrorSyncRequest{From: upstream, To: mirror, Paths: []string{"atlas.invalid/org/*"}, Latest: 2}
+	resp, err := srv.MirrorSync(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, h := range resp.Holons {
+		got[h.Path] = strings.Join(h.Versions, ",") + " +" + strings.Join(h.Added, ",")
+	}
+	want := map[string]string{
+		"atlas.invalid/org/a": "v1.2.0,v1.1.0 +v1.2.0,v1.1.0",
+		"atlas.invalid/org/b": "v0.1.0 +v0.1.0",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) || len(resp.Errors) > 0 {
+		t.Fatalf("first sync = %v, errors %v; want %v", got, resp.Errors, want)
+	}
+
+	// A later sync only copies the new versions.
+	gitRepo(t, a)("tag", "v1.3.0")
+	if resp, err = srv.MirrorSync(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range resp.Holons {
+		if wantAdded := map[string]string{"atlas.invalid/org/a": "v1.3.0"}[h.Path]; strings.Join(h.Added, ",") != wantAdded {
+			t.Errorf("second sync of %s added %v, want %q", h.Path, h.Added, wantAdded)
+		}
+	}
+
+	// The mirror serves pulls.
+	t.Setenv("ATLAS_PROXY", "file://"+mirror)
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/mirror"}
+	mod.AddRequire("atlas.invalid/org/a", "v1.3.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(server.CachePath("atlas.invalid/org/a", "v1.3.0"), "HOLON.md")); err != nil {
+		t.Errorf("pulled from the mirror: %v", err)
+	}
+}
+
+func TestMirrorTUF(t *testing.T) {
+	gitTest(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	keys := t.TempDir()
+	for _, role := range []string{"root", "targets", "snapshot", "timestamp"} {
+		seed := sha256.Sum256([]byte(role))
+		if err := os.WriteFile(filepath.Join(keys, role+".key"), []byte(hex.EncodeToString(seed[:])+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("ATLAS_TUF_KEYS", keys)
+	upstream, mirror := t.TempDir(), t.TempDir()
+	repo := filepath.Join(upstream, "atlas.invalid/org/a")
+	writeHolonMD(t, repo, "name: a\n")
+	commit := tagRelease(t, repo, "v1.0.0")
+	resp, err := srv.MirrorSync(ctx, &pb.MirrorSyncRequest{From: upstream, To: mirror, Paths: []string{"atlas.invalid/org/*"}})
+	if err != nil || len(resp.Errors) > 0 {
+		t.Fatalf("MirrorSync = %v, %v", resp, err)
+	}
+	var targets tuf.Targets
+	data, err := os.ReadFile(filepath.Join(mirror, "@tuf", "targets.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tuf.Peek(data, &targets); err != nil {
+		t.Fatal(err)
+	}
+	if target := targets.Targets["atlas.invalid/org/a@v1.0.0"]; target.Commit != commit || target.Hash == "" {
+		t.Fatalf("target of v1.0.0 = %+v, want commit %s and a hash", target, commit)
+	}
+
+	// A client trusting the root of the mirror pulls what it signs...
+	root := filepath.Join(t.TempDir(), "root.json")
+	if data, err = os.ReadFile(filepath.Join(mirror, "@tuf", "root.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(root, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ATLAS_TUF_KEYS", "")
+	t.Setenv("ATLAS_TUF_ROOT", root)
+	t.Setenv("ATLAS_REGISTRY", "")
+	t.Setenv("ATLAS_PROXY", "file://"+mirror)
+	dir := t.TempDir()
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/tuf"}) //nolint:errcheck
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "atlas.invalid/org/a", Version: "v1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// ... but not a tag the mirror serves without the targets key.
+	mirrored := gitRepo(t, filepath.Join(mirror, "atlas.invalid/org/a"))
+	mirrored("tag", "v1.1.0", commit)
+	_, err = srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "atlas.invalid/org/a", Version: "v1.1.0"})
+	if err == nil || !strings.Contains(err.Error(), "atlas.invalid/org/a@v1.1.0 is not signed in the TUF targets of file://"+mirror) {
+		t.Fatalf("add of an unsigned tag: err = %v, want it refused", err)
+	}
+	upd, err := srv.Update(ctx, &pb.UpdateRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(upd.Updated)+len(upd.Held) > 0 {
+		t.Errorf("update to %v %v, tags not signed", upd.Updated, upd.Held)
+	}
+}
+
+func TestChecksumLog(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("tlog.test", storeFetcher{})
+	dir := t.TempDir()
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/tlog"}) //nolint:errcheck
+	for _, version := range []string{"v1.0.0", "v1.1.0"} {
+		if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "tlog.test/dep", Version: version}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	pb.RegisterRhizomeAtlasServiceServer(gs, &server.Server{})
+	go gs.Serve(lis) //nolint:errcheck
+	defer gs.Stop()
+	uri := "tcp://" + lis.Addr().String()
+	t.Setenv("ATLAS_CACHE_REMOTE", uri)
+	c, err := client.Dial(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	rpc := c.Service()
+
+	// Serving a snapshot logs it.
+	serve := func(version string) {
+		t.Helper()
+		stream, err := rpc.FetchEntry(ctx, &pb.FetchEntryRequest{Path: "tlog.test/dep", Version: version})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for {
+			if _, err := stream.Recv(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	serve("v1.0.0")
+	serve("v1.0.0")
+	head, err := rpc.GetLogHead(ctx, &pb.GetLogHeadRequest{})
+	if err != nil || head.Size != 1 {
+		t.Fatalf("head = %v, %v; want one record", head, err)
+	}
+	verify := func() []string {
+		t.Helper()
+		resp, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir, Log: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Errors
+	}
+	if errs := verify(); len(errs) > 0 {
+		t.Fatalf("verify against the log: %v", errs)
+	}
+	serve("v1.1.0")
+	if errs := verify(); len(errs) > 0 {
+		t.Fatalf("verify against the grown log: %v", errs)
+	}
+
+	// A client pinning the key of the server only takes heads it signed,
+	// until they expire.
+	seed := make([]byte, ed25519.SeedSize)
+	rand.Read(seed) //nolint:errcheck
+	keyFile := filepath.Join(t.TempDir(), "log.key")
+	if err := os.WriteFile(keyFile, []byte(hex.EncodeToString(seed)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	public := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+	t.Setenv("ATLAS_LOG_KEY", hex.EncodeToString(public))
+	if errs := verify(); len(errs) != 1 || !strings.Contains(errs[0], "not signed") {
+		t.Errorf("verify against unsigned heads: %v", errs)
+	}
+	t.Setenv("ATLAS_LOG_SIGNING_KEY", keyFile)
+	if head, err := rpc.GetLogHead(ctx, &pb.GetLogHeadRequest{}); err != nil || !bytes.Equal(head.PublicKey, public) {
+		t.Fatalf("signed head = %v, %v", head, err)
+	}
+	if errs := verify(); len(errs) > 0 {
+		t.Errorf("verify against signed heads: %v", errs)
+	}
+	t.Setenv("ATLAS_LOG_HEAD_TTL", "1ns")
+	if errs := verify(); len(errs) != 1 || !strings.Contains(errs[0], "expired") {
+		t.Errorf("verify against an expired head: %v", errs)
+	}
+	t.Setenv("ATLAS_LOG_HEAD_TTL", "")
+	other, _, _ := ed25519.GenerateKey(nil)
+	t.Setenv("ATLAS_LOG_KEY", hex.EncodeToString(other))
+	if errs := verify(); len(errs) != 1 || !strings.Contains(errs[0], "not signed with the key") {
+		t.Errorf("verify against heads signed with another key: %v", errs)
+	}
+	t.Setenv("ATLAS_LOG_KEY", "")
+
+	// Content served differently for a version shows up.
+	records := filepath.Join(home, ".holon", "log", "records")
+	f, err := os.OpenFile(records, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(f, "tlog.test/dep v1.0.0 h1:%064x\n", 0)
+	f.Close()
+	if errs := verify(); len(errs) != 1 || !strings.Contains(errs[0], "served h1:000") {
+		t.Errorf("verify against a divergent log: %v", errs)
+	}
+
+	// So does a log rewritten rather than appended to.
+	data, err := os.ReadFile(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, rest, _ := strings.Cut(string(data), "\n")
+	forged := first[:strings.LastIndex(first, " ")] + " h1:00\n" + rest
+	if err := os.WriteFile(records, []byte(forged), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if errs := verify(); len(errs) != 1 || !strings.Contains(errs[0], "not an extension") {
+		t.Errorf("verify against a forked log: %v", errs)
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	gitTest(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	proxy := t.TempDir()
+	depPath := "atlas.invalid/test/attested"
+	repo := filepath.Join(proxy, depPath)
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: repo, HolonPath: depPath}); err != nil {
+		t.Fatal(err)
+	}
+	writeHolonMD(t, repo, "name: attested\n")
+	tagRelease(t, repo, "v0.1.0")
+	gitIn := gitRepo(t, repo)
+	t.Setenv("ATLAS_PROXY", "file://"+proxy)
+
+	resp, err := srv.Release(ctx, &pb.ReleaseRequest{Directory: repo, Builder: "https://ci.example.com/runner", Workflow: ".ci/release.yml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Version != "v0.1.1" || !resp.Attested {
+		t.Fatalf("release = %v", resp)
+	}
+
+	// A statement attesting to another commit is refused at release, and
+	// at fetch when tagged by hand.
+	forged, _ := json.Marshal(provenance.New(depPath+"@v0.9.0", "git+https://"+depPath, "0000", "https://ci.example.com/runner", ""))
+	if _, err := srv.Release(ctx, &pb.ReleaseRequest{Directory: repo, Provenance: forged}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("release with forged provenance: err = %v, want InvalidArgument", err)
+	}
+	block, _ := provenance.Encode(provenance.New(depPath+"@v0.9.0", "git+https://"+depPath, "0000", "https://ci.example.com/runner", ""))
+	gitIn("tag", "-a", "v0.9.0", "-m", "Release v0.9.0\n\n"+block)
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/provenance", Provenance: true}
+	mod.AddRequire(depPath, "v0.1.1")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	desc, err := srv.Describe(ctx, &pb.DescribeRequest{Directory: dir, Path: depPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := desc.Holon.Provenance
+	if p == nil || p.Builder != "https://ci.example.com/runner" || p.Workflow != ".ci/release.yml" || p.Commit != gitIn("rev-parse", "v0.1.1^{commit}") {
+		t.Errorf("provenance = %v", p)
+	}
+	if f := desc.Holon.Fetch; f.GetCommit() != p.GetCommit() || f.GetTagObject() != gitIn("rev-parse", "v0.1.1") {
+		t.Errorf("fetch info = %v, want the commit and tag object of v0.1.1", f)
+	}
+
+	// The provenance directive refuses the unattested v0.1.0.
+	_, err = srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: "v0.1.0"})
+	if status.Code(err) != codes.FailedPrecondition || !strings.Contains(err.Error(), "provenance") {
+		t.Errorf("add of unattested release: err = %v, want FailedPrecondition", err)
+	}
+	if _, err := os.Stat(server.CachePath(depPath, "v0.1.0")); !os.IsNotExist(err) {
+		t.Errorf("refused snapshot left in cache: %v", err)
+	}
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: "v0.9.0"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("add of forged release: err = %v, want Unavailable", err)
+	}
+}
+
+func TestTrustOnFirstUse(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	registerFiles("lib.tofu.test", "v1.0.0", map[string]string{"lib.go": "package lib\n"})
+	depPath := "lib.tofu.test/a"
+
+	newHolon := func(name string) string {
+		return writeHolonMod(t, t.TempDir(), &modfile.ModFile{HolonPath: name, TrustOnFirstUse: true})
+	}
+	dir := newHolon("test/tofu")
+
+	// The first Add must be confirmed, and reports what to confirm.
+	_, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: "v1.0.0"})
+	var info *errdetails.ErrorInfo
+	for _, d := range status.Convert(err).Details() {
+		if i, ok := d.(*errdetails.ErrorInfo); ok {
+			info = i
+		}
+	}
+	if status.Code(err) != codes.FailedPrecondition || info.GetReason() != client.ReasonTrustRequired || info.Metadata["hash"] == "" {
+		t.Fatalf("unconfirmed first add: %v, %v", err, info)
+	}
+	if _, err := os.Stat(server.CachePath(depPath, "v1.0.0")); !os.IsNotExist(err) {
+		t.Errorf("untrusted snapshot left in cache: %v", err)
+	}
+	hash := info.Metadata["hash"]
+
+	// The confirmed hash must be what is fetched.
+	_, err = srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: "v1.0.0", Trust: true, TrustHash: "h1:other"})
+	if status.Code(err) != codes.DataLoss {
+		t.Errorf("add confirming another hash: err = %v, want DataLoss", err)
+	}
+	resp, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: "v1.0.0", Trust: true, TrustHash: hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Trusted.GetHash() != hash {
+		t.Errorf("trusted = %v, want hash %s", resp.Trusted, hash)
+	}
+
+	// Trusted once, it is for every holon of the machine.
+	other := newHolon("test/tofu2")
+	resp, err = srv.Add(ctx, &pb.AddRequest{Directory: other, Path: depPath, Version: "v1.0.0"})
+	if err != nil || resp.Trusted != nil {
+		t.Fatalf("add of a trusted dependency: %v, %v", resp, err)
+	}
+
+	// Content that changed upstream is refused when fetched again.
+	registerFiles("lib.tofu.test", "v1.0.0", map[string]string{"lib.go": "package moved\n"})
+	if err := os.RemoveAll(server.CachePath(depPath, "v1.0.0")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(other, "holon.sum")); err != nil {
+		t.Fatal(err)
+	}
+	_, err = srv.Pull(ctx, &pb.PullRequest{Directory: other})
+	if status.Code(err) != codes.DataLoss || !strings.Contains(err.Error(), "trusted on first use") {
+		t.Errorf("pull of changed content: err = %v, want DataLoss", err)
+	}
+	if _, err := os.Stat(server.CachePath(depPath, "v1.0.0")); !os.IsNotExist(err) {
+		t.Errorf("mismatching snapshot left in cache: %v", err)
+	}
+	pull, err := srv.Pull(ctx, &pb.PullRequest{Directory: other, Force: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pull.Trusted) != 1 {
+		t.Errorf("forced pull trusted %v, want the new content", pull.Trusted)
+	}
+
+	// Pull trusts what was never trusted as fetched.
+	registerFiles("lib.tofu.test", "v1.0.0", map[string]string{"lib.go": "package lib\n"})
+	mod, _ := modfile.Parse(filepath.Join(other, "holon.mod"))
+	mod.AddRequire("lib.tofu.test/b", "v1.0.0")
+	if err := mod.Write(filepath.Join(other, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	pull, err = srv.Pull(ctx, &pb.PullRequest{Directory: other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pull.Trusted) != 1 || pull.Trusted[0].Path != "lib.tofu.test/b" {
+		t.Errorf("pull trusted %v, want lib.tofu.test/b", pull.Trusted)
+	}
+}
+
+func TestReproduce(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	content := "first"
+	fetch.Register("stable.reproduce.test", storeFetcher{})
+	fetch.Register("moving.reproduce.test", movingFetcher{&content})
+	fetch.Register("offline.reproduce.test", offlineFetcher{})
+
+	dir := t.TempDir()
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/reproduce"}) //nolint:errcheck
+	for _, path := range []string{"stable.reproduce.test/dep", "moving.reproduce.test/dep"} {
+		if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: path, Version: "v1.0.0"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "offline.reproduce.test/dep", Version: "v1.0.0", RecordOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := srv.Reproduce(ctx, &pb.ReproduceRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Reproducible || len(resp.Results) != 3 {
+		t.Fatalf("reproduce = %v, want 3 results, not all reproduced", resp)
+	}
+	if r := resp.Results[0]; !r.Reproduced || r.UpstreamHash != r.SumHash || r.CacheHash != r.SumHash || r.Source == "" {
+		t.Errorf("stable dependency: %v", r)
+	}
+	if r := resp.Results[1]; !r.Reproduced {
+		t.Errorf("moving dependency before it moved: %v", r)
+	}
+	if r := resp.Results[2]; r.Reproduced || r.UpstreamHash != "" || len(r.Problems) != 2 {
+		t.Errorf("offline dependency: %v", r)
+	}
+
+	// Once upstream moves, it no longer reproduces holon.sum nor the cache.
+	content = "second"
+	resp, err = srv.Reproduce(ctx, &pb.ReproduceRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := resp.Results[1]; r.Reproduced || r.UpstreamHash == r.SumHash || len(r.Problems) != 2 {
+		t.Errorf("moved dependency: %v", r)
+	}
+}
+
+// modFetcher serves a holon.mod per version.
+type modFetcher map[string]string
+
+func (f modFetcher) Resolve(path, version string) ([]fetch.Source, error) {
+	return []fetch.Source{modSource(f[version])}, nil
+}
+
+type modSource string
+
+func (modSource) String() string { return "mod://" }
+
+func (s modSource) Fetch(_ context.Context, dst string) error {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dst, "holon.mod"), []byte(s), 0o644)
+}
+
+func TestImpact(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("a.impact.test", modFetcher{
+		"v1.2.0": "holon a.impact.test/x\n\nrequire (\n    b.impact.test/y v1.1.0\n    c.impact.test/z v2.0.0\n)\n",
+	})
+	fetch.Register("b.impact.test", modFetcher{
+		"v1.1.0": "holon b.impact.test/y\n\nrequire (\n    d.impact.test/w v1.1.0\n)\n",
+	})
+	fetch.Register("c.impact.test", offlineFetcher{})
+	fetch.Register("d.impact.test", modFetcher{"v1.1.0": "holon d.impact.test/w\n"})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/impact"}
+	mod.AddRequire("a.impact.test/x", "v1.0.0")
+	mod.AddRequire("b.impact.test/y", "v1.0.0")
+	mod.AddRequire("c.impact.test/z", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	// Cached: x and y at their old versions, y requiring w, and w.
+	cache := func(path, version string, reqs ...string) {
+		t.Helper()
+		m := &modfile.ModFile{HolonPath: path}
+		for i := 0; i < len(reqs); i += 2 {
+			m.AddRequire(reqs[i], reqs[i+1])
+		}
+		if err := os.MkdirAll(server.CachePath(path, version), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Write(filepath.Join(server.CachePath(path, version), "holon.mod")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache("a.impact.test/x", "v1.0.0", "b.impact.test/y", "v1.0.0")
+	cache("b.impact.test/y", "v1.0.0", "d.impact.test/w", "v1.0.0")
+	cache("d.impact.test/w", "v1.0.0")
+	before, _ := os.ReadFile(filepath.Join(dir, "holon.mod"))
+
+	resp, err := srv.Impact(ctx, &pb.ImpactRequest{Directory: dir, Path: "a.impact.test/x", Version: "v1.2.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes []string
+	for _, c := range resp.Changes {
+		changes = append(changes, fmt.Sprintf("%s %s %s→%s", c.Holon, c.Path, c.OldVersion, c.NewVersion))
+	}
+	wantChanges := []string{
+		"a.impact.test/x b.impact.test/y v1.0.0→v1.1.0",
+		"a.impact.test/x c.impact.test/z →v2.0.0",
+		"b.impact.test/y d.impact.test/w v1.0.0→v1.1.0",
+		"test/impact a.impact.test/x v1.0.0→v1.2.0",
+	}
+	if !slices.Equal(changes, wantChanges) {
+		t.Errorf("changes = %q, want %q", changes, wantChanges)
+	}
+	// w changes through y alone; z gets a second major besides v1.
+	var sels []string
+	for _, s := range resp.Selections {
+		sels = append(sels, fmt.Sprintf("%s %s→%s %v", s.Path, s.OldVersion, s.NewVersion, s.Raised))
+	}
+	wantSels := []string{
+		"a.impact.test/x v1.0.0→v1.2.0 []",
+		"b.impact.test/y v1.0.0→v1.1.0 [test/impact]",
+		"c.impact.test/z →v2.0.0 []",
+		"d.impact.test/w v1.0.0→v1.1.0 []",
+	}
+	if !slices.Equal(sels, wantSels) {
+		t.Errorf("selections = %q, want %q", sels, wantSels)
+	}
+	if len(resp.Conflicts) != 1 || resp.Conflicts[0].Path != "c.impact.test/z" || len(resp.Conflicts[0].RequiredBy) != 2 {
+		t.Errorf("conflicts = %v, want c.impact.test/z", resp.Conflicts)
+	}
+	// z, neither cached nor fetched, is reported rather than failing.
+	if len(resp.Unresolved) != 2 || !strings.HasPrefix(resp.Unresolved[0], "c.impact.test/z@v1.0.0: ") ||
+		!strings.HasPrefix(resp.Unresolved[1], "c.impact.test/z@v2.0.0: ") {
+		t.Errorf("unresolved = %q, want both versions of c.impact.test/z", resp.Unresolved)
+	}
+
+	// Nothing was written, nor cached.
+	if after, _ := os.ReadFile(filepath.Join(dir, "holon.mod")); !bytes.Equal(after, before) {
+		t.Errorf("holon.mod changed:\n%s", after)
+	}
+	for _, dep := range []string{"a.impact.test/x@v1.2.0", "b.impact.test/y@v1.1.0", "d.impact.test/w@v1.1.0"} {
+		path, version, _ := strings.Cut(dep, "@")
+		if _, err := os.Stat(server.CachePath(path, version)); !os.IsNotExist(err) {
+			t.Errorf("simulated %s cached: %v", dep, err)
+		}
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	gitTest(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	proxy := t.TempDir()
+	depPath := "atlas.invalid/test/aging"
+	repo := filepath.Join(proxy, depPath)
+	writeHolonMD(t, repo, "name: aging\n")
+	git := gitRepo(t, repo)
+	git("add", ".")
+	for _, release := range []struct{ tag, date string }{
+		{"v1.0.0", "2024-01-01T00:00:00Z"},
+		{"v1.1.0", "2024-03-01T00:00:00Z"},
+		{"v2.0.0", "2024-09-01T00:00:00Z"},
+	} {
+		t.Setenv("GIT_AUTHOR_DATE", release.date)
+		t.Setenv("GIT_COMMITTER_DATE", release.date)
+		git("commit", "-q", "--allow-empty", "-m", release.tag)
+		git("tag", "-a", release.tag, "-m", "Release "+release.tag)
+	}
+	t.Setenv("ATLAS_PROXY", "file://"+proxy)
+	fetch.Register("undated.test", storeFetcher{tags: []string{"v1.0.0", "v1.1.0"}})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/freshness"}
+	mod.AddRequire(depPath, "v1.0.0")
+	mod.AddRequire("undated.test/dep", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := srv.Freshness(ctx, &pb.FreshnessRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StaleDays != 90 || len(resp.Dependencies) != 2 {
+		t.Fatalf("freshness = %v", resp)
+	}
+	if d := resp.Dependencies[0]; d.Latest != "v2.0.0" || d.VersionsBehind != 2 || d.DaysBehind != 244 || !d.Stale ||
+		d.VersionTime != "2024-01-01T00:00:00Z" || d.Error != "" {
+		t.Errorf("git dependency: %v", d)
+	}
+	if d := resp.Dependencies[1]; d.Latest != "v1.1.0" || d.VersionsBehind != 1 || d.Stale || d.Error == "" {
+		t.Errorf("undatable dependency: %v", d)
+	}
+
+	resp, err = srv.Freshness(ctx, &pb.FreshnessRequest{Directory: dir, StaleDays: 365})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Dependencies[0].Stale {
+		t.Errorf("244 days behind is stale with a 365-day threshold")
+	}
+}
+
+func TestDeprecation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	fetch.Register("deprecating.test", filesFetcher{
+		tags: []string{"v1.2.0", "v1.2.1"},
+		files: map[string]map[string]string{
+			"v1.2.0": {"holon.mod": "holon deprecating.test/log\n"},
+			"v1.2.1": {"holon.mod": "holon deprecating.test/log\ndeprecated v1.2.0 => v1.2.1 // corrupts its buffer\n"},
+		},
+	})
+	fetch.Register("retired.test", filesFetcher{
+		tags: []string{"v1.0.0"},
+		files: map[string]map[string]string{
+			"v1.0.0": {"HOLON.md": "---\nname: old\ndeprecated: superseded by tracing\nsuccessor: tracing.test/tracing\n---\n"},
+		},
+	})
+	fetch.Register("current.test", filesFetcher{
+		tags: []string{"v1.0.0", "v1.1.0"},
+		files: map[string]map[string]string{
+			"v1.0.0": {"holon.mod": "holon current.test/ok\n"},
+			"v1.1.0": {"holon.mod": "holon current.test/ok\ndeprecated v1.0.1 // broken\n"},
+		},
+	})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/deprecation"}
+	mod.AddRequire("deprecating.test/log", "v1.2.0")
+	mod.AddRequire("retired.test/old", "v1.0.0")
+	mod.AddRequire("current.test/ok", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*pb.Deprecation{
+		{Path: "deprecating.test/log", Version: "v1.2.0", Message: "corrupts its buffer", Successor: "v1.2.1", DeclaredBy: "v1.2.1"},
+		{Path: "retired.test/old", Version: "v1.0.0", Holon: true, Message: "superseded by tracing", Successor: "tracing.test/tracing", DeclaredBy: "v1.0.0"},
+	}
+
+	pulled, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pulled.Deprecated) != len(want) {
+		t.Fatalf("pull deprecated = %v, want %v", pulled.Deprecated, want)
+	}
+	for i := range want {
+		if !proto.Equal(pulled.Deprecated[i], want[i]) {
+			t.Errorf("pull deprecated %d = %v, want %v", i, pulled.Deprecated[i], want[i])
+		}
+	}
+
+	fresh, err := srv.Freshness(ctx, &pb.FreshnessRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, d := range fresh.Dependencies {
+		var w *pb.Deprecation
+		if i < len(want) {
+			w = want[i]
+		}
+		if !proto.Equal(d.Deprecation, w) {
+			t.Errorf("freshness of %s: deprecation = %v, want %v", d.Path, d.Deprecation, w)
+		}
+	}
+}
+
+// filesFetcher serves the given files per version.
+type filesFetcher struct {
+	tags  []string
+	files map[string]map[string]string
+}
+
+func (f filesFetcher) Resolve(path, version string) ([]fetch.Source, error) {
+	return []fetch.Source{filesSource(f.files[version])}, nil
+}
+
+func (f filesFetcher) Tags(context.Context, string) ([]string, error) {
+	return f.tags, nil
+}
+
+type filesSource map[string]string
+
+func (filesSource) String() string { return "files://" }
+
+func (s filesSource) Fetch(_ context.Context, dst string) error {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	for name, content := range s {
+		if err := os.WriteFile(filepath.Join(dst, name), []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestUpdateBranches(t *testing.T) {
+	gitTest(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	fetch.Register("branches.test", filesFetcher{
+		tags: []string{"v1.0.0", "v1.1.0"},
+		files: map[string]map[string]string{
+			"v1.0.0": {"lib.txt": "one"},
+			"v1.1.0": {"lib.txt": "two"},
+		},
+	})
+
+	origin, dir := t.TempDir(), t.TempDir()
+	git := gitRepo(t, dir)
+	git("init", "-q", "--bare", origin)
+	gitOrigin := gitRepo(t, origin)
+	git("remote", "add", "origin", origin)
+	mod := &modfile.ModFile{HolonPath: "test/branches"}
+	mod.AddRequire("branches.test/a", "v1.0.0")
+	mod.AddRequire("branches.test/b", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	head, branch := git("rev-parse", "HEAD"), git("symbolic-ref", "--short", "HEAD")
+	modBefore, _ := os.ReadFile(filepath.Join(dir, "holon.mod"))
+
+	if _, err := srv.Update(ctx, &pb.UpdateRequest{Directory: dir, Push: true}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("push without branching: err = %v, want InvalidArgument", err)
+	}
+
+	resp, err := srv.Update(ctx, &pb.UpdateRequest{
+		Directory: dir,
+		Branching: pb.UpdateBranching_UPDATE_BRANCHING_PER_DEPENDENCY,
+		Push:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Branches) != 2 {
+		t.Fatalf("branches = %v, want one per dependency", resp.Branches)
+	}
+	for i, path := range []string{"branches.test/a", "branches.test/b"} {
+		b := resp.Branches[i]
+		if want := "atlas/update/" + path + "@v1.1.0"; b.Name != want || !b.Pushed {
+			t.Errorf("branch %d = %v, want %s pushed", i, b, want)
+		}
+		if got := gitOrigin("rev-parse", "refs/heads/"+b.Name); got != b.Commit {
+			t.Errorf("origin %s = %s, want %s", b.Name, got, b.Commit)
+		}
+		if parent := git("rev-parse", b.Commit+"^"); parent != head {
+			t.Errorf("%s forked from %s, want %s", b.Name, parent, head)
+		}
+		branchMod := git("show", b.Commit+":holon.mod")
+		if !strings.Contains(branchMod, path+" v1.1.0") || strings.Count(branchMod, "v1.1.0") != 1 {
+			t.Errorf("holon.mod on %s:\n%s", b.Name, branchMod)
+		}
+		if sum := git("show", b.Commit+":holon.sum"); !strings.Contains(sum, path+" v1.1.0 ") {
+			t.Errorf("holon.sum on %s lacks %s v1.1.0:\n%s", b.Name, path, sum)
+		}
+		if msg := git("log", "-1", "--format=%s", b.Commit); msg != "Update "+path+" to v1.1.0" {
+			t.Errorf("commit message on %s = %q", b.Name, msg)
+		}
+	}
+	if got := git("symbolic-ref", "--short", "HEAD"); got != branch {
+		t.Errorf("left on %s, want %s", got, branch)
+	}
+	if modAfter, _ := os.ReadFile(filepath.Join(dir, "holon.mod")); string(modAfter) != string(modBefore) {
+		t.Errorf("holon.mod changed in the work tree:\n%s", modAfter)
+	}
+
+	resp, err = srv.Update(ctx, &pb.UpdateRequest{Directory: dir, Branching: pb.UpdateBranching_UPDATE_BRANCHING_BATCH})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Branches) != 1 || resp.Branches[0].Name != "atlas/updates" || resp.Branches[0].Pushed || len(resp.Branches[0].Updated) != 2 {
+		t.Fatalf("batch branches = %v", resp.Branches)
+	}
+	if branchMod := git("show", "atlas/updates:holon.mod"); strings.Count(branchMod, "v1.1.0") != 2 {
+		t.Errorf("holon.mod on atlas/updates:\n%s", branchMod)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "holon.mod"), append(modBefore, '\n'), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Update(ctx, &pb.UpdateRequest{Directory: dir, Branching: pb.UpdateBranching_UPDATE_BRANCHING_BATCH}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("dirty work tree: err = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestUpdateChangelog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	forge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/org/released/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `[
+  {"tag_name": "v1.2.0", "body": "### Fixed\n- the leak"},
+  {"tag_name": "v1.1.0", "body": "Adds streaming."},
+  {"tag_name": "v1.0.0", "body": "First."}
+]`)
+	}))
+	defer forge.Close()
+	t.Setenv("ATLAS_FORGES", "forge.changelog.test="+forge.URL)
+	tags := []string{"v1.0.0", "v1.1.0", "v1.2.0"}
+	fetch.Register("forge.changelog.test", storeFetcher{tags: tags})
+	fetch.Register("files.changelog.test", filesFetcher{tags: tags, files: map[string]map[string]string{
+		"v1.0.0": {"CHANGELOG.md": "# Changelog\n\n## v1.0.0\n\nFirst.\n"},
+		"v1.2.0": {"CHANGELOG.md": "# Changelog\n\n## v1.2.0\n\n### Changed\n- faster\n\n## v1.1.0\n\nMore.\n\n## v1.0.0\n\nFirst.\n"},
+	}})
+
+	dir := t.TempDir()
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/changelog"}) //nolint:errcheck
+	for _, path := range []string{"forge.changelog.test/org/released", "files.changelog.test/dep"} {
+		if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: path, Version: "v1.0.0"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp, err := srv.Update(ctx, &pb.UpdateRequest{Directory: dir, Changelog: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Updated) != 2 {
+		t.Fatalf("updated = %v", resp.Updated)
+	}
+	want := `## Dependency updates
+
+### forge.changelog.test/org/released v1.0.0 → v1.2.0
+
+#### v1.2.0
+
+##### Fixed
+- the leak
+
+#### v1.1.0
+
+Adds streaming.
+
+### files.changelog.test/dep v1.0.0 → v1.2.0
+
+#### v1.2.0
+
+##### Changed
+- faster
+
+#### v1.1.0
+
+More.
+`
+	if resp.Changelog != want {
+		t.Errorf("changelog:\n%s\nwant:\n%s", resp.Changelog, want)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("x.resolve.test", filesFetcher{tags: []string{"v1.0.0", "v1.1.0", "v1.2.0", "v1.3.0-rc.1", "v2.0.0"}})
+	fetch.Register("y.resolve.test", filesFetcher{
+		tags: []string{"v1.0.0"},
+		files: map[string]map[string]string{
+			"v1.0.0": {"holon.mod": "holon y.resolve.test/y\n\nrequire (\n    x.resolve.test/x v1.1.0\n)\n"},
+		},
+	})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/resolve"}
+	mod.AddRequire("x.resolve.test/x", "v1.0.0")
+	mod.AddRequire("y.resolve.test/y", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		strategy, want string
+	}{
+		{"", "v1.1.0"},
+		{modfile.StrategyHighest, "v1.2.0"},
+	} {
+		resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir, Resolve: tt.strategy})
+		if err != nil {
+			t.Fatalf("pull with %q: %v", tt.strategy, err)
+		}
+		if _, err := os.Stat(server.CachePath("x.resolve.test/x", tt.want)); err != nil {
+			t.Errorf("%s: selected version not cached: %v", resp.Strategy, err)
+		}
+
+		lock, err := modfile.ParseLock(filepath.Join(dir, "holon.lock"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lock.Strategy != resp.Strategy || lock.Strategy != cmp.Or(tt.strategy, modfile.StrategyMVS) {
+			t.Errorf("lock strategy = %q, response %q, want %q", lock.Strategy, resp.Strategy, tt.strategy)
+		}
+		x, ok := lock.Lookup("x.resolve.test/x")
+		if !ok || x.Version != tt.want {
+			t.Fatalf("%s: x selected %v, want %s", lock.Strategy, x, tt.want)
+		}
+		want := []modfile.Requirement{{Holon: "test/resolve", Version: "v1.0.0"}, {Holon: "y.resolve.test/y", Version: "v1.1.0"}}
+		if !slices.Equal(x.RequiredBy, want) {
+			t.Errorf("%s: x required by %v, want %v", lock.Strategy, x.RequiredBy, want)
+		}
+		if y, ok := lock.Lookup("y.resolve.test/y"); !ok || y.Version != "v1.0.0" {
+			t.Errorf("%s: y selected %v, want v1.0.0", lock.Strategy, y)
+		}
+	}
+
+	// A resolve directive in holon.mod sets the strategy; a bad one is refused.
+	mod.Resolve = modfile.StrategyHighest
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil || resp.Strategy != modfile.StrategyHighest {
+		t.Errorf("pull with resolve directive: %v, %v", resp, err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir, Resolve: "newest"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unknown strategy: %v, want InvalidArgument", err)
+	}
+}
+
+// errorReason returns the reason of the ErrorInfo err carries, if any.
+func errorReason(err error) string {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestLock(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("x.lock.test", filesFetcher{tags: []string{"v1.0.0", "v1.1.0"}})
+	fetch.Register("y.lock.test", filesFetcher{
+		tags: []string{"v1.0.0"},
+		files: map[string]map[string]string{
+			"v1.0.0": {"holon.mod": "holon y.lock.test/y\n\nrequire (\n    x.lock.test/x v1.0.0\n)\n"},
+		},
+	})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/lock", Resolve: modfile.StrategyHighest}
+	mod.AddRequire("y.lock.test/y", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	selected := func() string {
+		lock, err := modfile.ParseLock(filepath.Join(dir, "holon.lock"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, _ := lock.Lookup("x.lock.test/x")
+		return x.Version
+	}
+
+	// A locked pull needs a holon.lock matching holon.mod.
+	_, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir, Locked: true})
+	if reason := errorReason(err); reason != client.ReasonLockStale {
+		t.Fatalf("locked pull without holon.lock: %v, reason %q, want %s", err, reason, client.ReasonLockStale)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if v := selected(); v != "v1.1.0" {
+		t.Fatalf("x selected %s, want v1.1.0", v)
+	}
+
+	// A release published meanwhile changes nothing while holon.lock
+	// matches: pulls keep to it.
+	fetch.Register("x.lock.test", filesFetcher{tags: []string{"v1.0.0", "v1.1.0", "v1.2.0"}})
+	for _, locked := range []bool{false, true} {
+		resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir, Locked: locked})
+		if err != nil {
+			t.Fatalf("pull (locked %v): %v", locked, err)
+		}
+		if slices.ContainsFunc(resp.Fetched, func(d *pb.Dependency) bool { return d.Version == "v1.2.0" }) {
+			t.Errorf("pull (locked %v) fetched v1.2.0", locked)
+		}
+	}
+	if v := selected(); v != "v1.1.0" {
+		t.Errorf("x selected %s after pulls, want v1.1.0", v)
+	}
+
+	// Lock resolves again; --check only tells.
+	resp, err := srv.Lock(ctx, &pb.LockRequest{Directory: dir, DryRun: true})
+	if err != nil || !resp.Changed || selected() != "v1.1.0" {
+		t.Fatalf("lock dry run: %v, %v; x selected %s", resp, err, selected())
+	}
+	if resp, err = srv.Lock(ctx, &pb.LockRequest{Directory: dir}); err != nil || !resp.Changed || selected() != "v1.2.0" {
+		t.Fatalf("lock: %v, %v; x selected %s", resp, err, selected())
+	}
+	if resp, err = srv.Lock(ctx, &pb.LockRequest{Directory: dir}); err != nil || resp.Changed {
+		t.Errorf("lock again: %v, %v, want unchanged", resp, err)
+	}
+
+	// Undo restores the holon.lock Lock replaced.
+	if undone, err := srv.Undo(ctx, &pb.UndoRequest{Directory: dir}); err != nil || undone.Method != "Lock" || selected() != "v1.1.0" {
+		t.Fatalf("undo lock: %v, %v; x selected %s, want v1.1.0", undone, err, selected())
+	}
+	if _, err := srv.Lock(ctx, &pb.LockRequest{Directory: dir}); err != nil || selected() != "v1.2.0" {
+		t.Fatalf("lock after undo: %v; x selected %s", err, selected())
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir, Locked: true}); err != nil {
+		t.Fatal(err)
+	}
+	vendored, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.ContainsFunc(vendored.Vendored, func(d *pb.Dependency) bool { return d.Path == "x.lock.test/x" && d.Version == "v1.2.0" }) {
+		t.Errorf("vendored %v, want x at v1.2.0", vendored.Vendored)
+	}
+
+	// A change of holon.mod resolves the graph again.
+	mod.Resolve = ""
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir, Locked: true}); errorReason(err) != client.ReasonLockStale {
+		t.Errorf("locked pull after holon.mod changed: %v", err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil || selected() != "v1.0.0" {
+		t.Errorf("pull after holon.mod changed: %v; x selected %s, want v1.0.0", err, selected())
+	}
+}
+
+func TestCoexistingMajors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("x.majors.test", filesFetcher{
+		tags: []string{"v1.0.0", "v2.0.0"},
+		files: map[string]map[string]string{
+			"v1.0.0": {"HOLON.md": "---\nname: x1\n---\n"},
+			"v2.0.0": {"HOLON.md": "---\nname: x2\n---\n"},
+		},
+	})
+	fetch.Register("y.majors.test", filesFetcher{
+		tags: []string{"v1.0.0"},
+		files: map[string]map[string]string{
+			"v1.0.0": {"holon.mod": "holon y.majors.test/y\n\nrequire (\n    x.majors.test/x v2.0.0\n)\n"},
+		},
+	})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/majors"}
+	mod.AddRequire("x.majors.test/x", "v1.0.0")
+	mod.AddRequire("y.majors.test/y", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var selected []string
+	for _, r := range resp.Resolved {
+		selected = append(selected, r.Path+"@"+r.Version)
+	}
+	want := []string{"x.majors.test/x@v1.0.0", "x.majors.test/x@v2.0.0", "y.majors.test/y@v1.0.0"}
+	if !slices.Equal(selected, want) {
+		t.Fatalf("resolved = %v, want %v", selected, want)
+	}
+	lock, err := modfile.ParseLock(filepath.Join(dir, "holon.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lock.Coexisting(); !maps.Equal(got, map[string]bool{"x.majors.test/x": true}) {
+		t.Errorf("coexisting = %v", got)
+	}
+
+	if _, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"x@v1": "x1", "x@v2": "x2"} {
+		fm, err := holonmd.Parse(filepath.Join(dir, ".holon", name, "HOLON.md"))
+		if err != nil || fm.Name != want {
+			t.Errorf("%s: %v, %v, want name %s", name, fm, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".holon", "x")); !os.IsNotExist(err) {
+		t.Errorf(".holon/x: %v, want only versioned directories", err)
+	}
+
+	// The graph has a node per major.
+	graph, err := srv.Graph(ctx, &pb.GraphRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nodes []string
+	for _, n := range graph.Nodes[1:] {
+		nodes = append(nodes, n.Path+"@"+n.Version+" "+n.Title)
+	}
+	slices.Sort(nodes)
+	if want := []string{"x.majors.test/x@v1.0.0 x1", "x.majors.test/x@v2.0.0 x2", "y.majors.test/y@v1.0.0 "}; !slices.Equal(nodes, want) {
+		t.Errorf("graph nodes = %q, want %q", nodes, want)
+	}
+
+	// Each edge leads from the node of the version listing it to the node
+	// of the major it requires.
+	byMajor := map[string]string{}
+	for _, n := range graph.Nodes[1:] {
+		major, _, _, _ := semver.Parse(n.Version)
+		byMajor[fmt.Sprintf("%s@v%d", n.Path, major)] = n.Version
+	}
+	var edges []string
+	for _, e := range graph.Edges {
+		major, _, _, _ := semver.Parse(e.Version)
+		if _, ok := byMajor[fmt.Sprintf("%s@v%d", e.To, major)]; !ok {
+			t.Errorf("edge to %s@%s has no node", e.To, e.Version)
+		}
+		edges = append(edges, fmt.Sprintf("%s@%s → %s@%s", e.From, e.FromVersion, e.To, e.Version))
+	}
+	if want := []string{
+		"test/majors@ → x.majors.test/x@v1.0.0",
+		"test/majors@ → y.majors.test/y@v1.0.0",
+		"y.majors.test/y@v1.0.0 → x.majors.test/x@v2.0.0",
+	}; !slices.Equal(edges, want) {
+		t.Errorf("graph edges = %q, want %q", edges, want)
+	}
+}
+
+func TestWildcardReplace(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("new.replace.test", filesFetcher{
+		tags:  []string{"v1.0.0", "v1.1.0"},
+		files: map[string]map[string]string{"v1.0.0": {"README": "moved"}},
+	})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/replace"}
+	mod.AddRequire("old.replace.test/org/a", "v1.0.0")
+	mod.AddRequire("mono.replace.test/b", "v1.0.0")
+	mod.Replace = []modfile.Replace{
+		{Old: "old.replace.test/*", New: "new.replace.test/*"},
+		{Old: "mono.replace.test/*", New: "./mono/*"},
+	}
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The remote replace is fetched, cached and hashed as its new path;
+	// the local one is not fetched.
+	resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Fetched) != 1 || resp.Fetched[0].Path != "new.replace.test/org/a" {
+		t.Fatalf("fetched = %v, want new.replace.test/org/a", resp.Fetched)
+	}
+	if _, err := os.Stat(filepath.Join(server.CachePath("new.replace.test/org/a", "v1.0.0"), "README")); err != nil {
+		t.Errorf("replacement not cached: %v", err)
+	}
+	sum, err := modfile.ParseSum(filepath.Join(dir, "holon.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Lookup("new.replace.test/org/a", "v1.0.0") == "" {
+		t.Errorf("replacement not in holon.sum:\n%v", sum.Entries)
+	}
+
+	// Updates list the tags of the new path.
+	up, err := srv.Update(ctx, &pb.UpdateRequest{Directory: dir, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(up.Updated) != 1 || up.Updated[0].Path != "old.replace.test/org/a" || up.Updated[0].NewVersion != "v1.1.0" {
+		t.Errorf("updates = %v, want old.replace.test/org/a to v1.1.0", up.Updated)
+	}
+}
+
+func TestRequireGroups(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	files := map[string]map[string]string{"v1.0.0": {"README": "content"}}
+	fetch.Register("run.groups.test", filesFetcher{files: files})
+	fetch.Register("test.groups.test", filesFetcher{files: files})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/groups"}
+	mod.AddRequire("run.groups.test/lib", "v1.0.0")
+	mod.AddRequire("test.groups.test/fixtures", "v1.0.0")
+	mod.Require[1].Group = "test"
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir, Group: modfile.DefaultGroup})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Fetched) != 1 || resp.Fetched[0].Path != "run.groups.test/lib" || resp.Fetched[0].Group != modfile.DefaultGroup {
+		t.Errorf("pulled %v, want run.groups.test/lib only", resp.Fetched)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "holon.lock")); !os.IsNotExist(err) {
+		t.Errorf("pull of one group wrote holon.lock: %v", err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir, Group: "dev"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unknown group: %v, want InvalidArgument", err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	vend, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir, Group: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vend.Vendored) != 1 || vend.Vendored[0].Path != "test.groups.test/fixtures" || vend.Vendored[0].Group != "test" {
+		t.Errorf("vendored %v, want test.groups.test/fixtures only", vend.Vendored)
+	}
+
+	out := filepath.Join(t.TempDir(), "app.bundle")
+	bundle, err := srv.BundleCreate(ctx, &pb.BundleCreateRequest{Directory: dir, Output: out, Group: modfile.DefaultGroup})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Dependencies) != 1 || bundle.Dependencies[0].Path != "run.groups.test/lib" {
+		t.Errorf("bundled %v, want run.groups.test/lib only", bundle.Dependencies)
+	}
+}
+
+func TestOptionalDependencies(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("core.optional.test", filesFetcher{files: map[string]map[string]string{
+		"v1.0.0": {"holon.mod": "holon core.optional.test/core\n\nrequire (\n    gpu.optional.test/gpu v2.0.0 optional\n)\n"},
+	}})
+	fetch.Register("otel.optional.test", filesFetcher{})
+	fetch.Register("gpu.optional.test", filesFetcher{})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/optional"}
+	mod.AddRequire("core.optional.test/core", "v1.0.0")
+	mod.AddRequire("otel.optional.test/otel", "v0.4.0")
+	mod.Require[1].Optional = true
+	mod.Require[1].Alias = "otel"
+	mod.Require[1].Meta = map[string]string{"provides": "tracing"}
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	pulled := func(with ...string) []string {
+		t.Helper()
+		resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir, With: with})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, d := range resp.Fetched {
+			paths = append(paths, d.Path)
+		}
+		slices.Sort(paths)
+		return paths
+	}
+
+	if got, want := pulled(), []string{"core.optional.test/core"}; !slices.Equal(got, want) {
+		t.Errorf("pulled %q, want %q", got, want)
+	}
+	for _, dep := range []string{"otel.optional.test/otel@v0.4.0", "gpu.optional.test/gpu@v2.0.0"} {
+		path, version, _ := strings.Cut(dep, "@")
+		if _, err := os.Stat(server.CachePath(path, version)); err == nil {
+			t.Errorf("%s fetched without being asked for", dep)
+		}
+	}
+	if got, want := pulled("tracing"), []string{"core.optional.test/core", "otel.optional.test/otel"}; !slices.Equal(got, want) {
+		t.Errorf("with tracing: pulled %q, want %q", got, want)
+	}
+	if got, want := pulled("gpu.optional.test/gpu"), []string{"core.optional.test/core", "gpu.optional.test/gpu"}; !slices.Equal(got, want) {
+		t.Errorf("with gpu: pulled %q, want %q", got, want)
+	}
+
+	// Vendoring includes the optional dependencies pulled, of holon.mod or
+	// of a dependency, without failing on the other.
+	if err := os.RemoveAll(server.CachePath("otel.optional.test/otel", "v0.4.0")); err != nil {
+		t.Fatal(err)
+	}
+	if vend, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir}); err != nil || len(vend.Vendored) != 2 {
+		t.Errorf("vendor: %v, %v", vend, err)
+	}
+}
+
+func TestVendorIgnore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("lib.vendorignore.test", filesFetcher{files: map[string]map[string]string{
+		"v1.0.0": {
+			"lib.go":      "package lib",
+			"lib_test.go": "package lib",
+			"docs":        "not a directory, so kept",
+			"README.md":   "readme",
+		},
+	}})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/vendorignore"}
+	mod.AddRequire("lib.vendorignore.test/lib", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	ignore := "# not needed to build\n*_test.go\n/README.md\ndocs/\n"
+	if err := os.WriteFile(filepath.Join(dir, ".holonvendorignore"), []byte(ignore), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(len("package lib") + len("not a directory, so kept")); plan.Plan.Bytes != want {
+		t.Errorf("plan bytes = %d, want %d", plan.Plan.Bytes, want)
+	}
+	resp, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(resp.Vendored[0].CachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"docs", "lib.go"}; !slices.Equal(names, want) {
+		t.Errorf("vendored %q, want %q", names, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".holonvendorignore"), []byte("[\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("bad pattern: %v, want FailedPrecondition", err)
+	}
+}
+
+func TestSizeBudgets(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	big := strings.Repeat("x", 3000)
+	fetch.Register("budget.test", filesFetcher{files: map[string]map[string]string{
+		"v1.0.0": {"data": big, "data_test.go": big},
+	}})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/budget", Budget: 4 << 10, TotalBudget: 8 << 10}
+	mod.AddRequire("budget.test/a", "v1.0.0")
+	mod.AddRequire("budget.test/b", "v1.0.0")
+	mod.AddRequire("budget.test/c", "v1.0.0")
+	if err := mod.SetMeta("budget.test/c", map[string]string{"budget": "8k"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	// a and b exceed the budget of each dependency, c has its own; the
+	// three exceed the total.
+	resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*pb.BudgetExceeded{
+		{Dependency: "budget.test/a@v1.0.0", Size: 6000, Budget: 4 << 10},
+		{Dependency: "budget.test/b@v1.0.0", Size: 6000, Budget: 4 << 10},
+		{Size: 18000, Budget: 8 << 10},
+	}
+	if len(resp.OverBudget) != len(want) {
+		t.Fatalf("over budget = %v, want %v", resp.OverBudget, want)
+	}
+	for i := range want {
+		if !proto.Equal(resp.OverBudget[i], want[i]) {
+			t.Errorf("over budget %d = %v, want %v", i, resp.OverBudget[i], want[i])
+		}
+	}
+
+	os.Remove(filepath.Join(dir, "holon.sum")) //nolint:errcheck
+	_, err = srv.Pull(ctx, &pb.PullRequest{Directory: dir, EnforceBudget: true})
+	if status.Code(err) != codes.FailedPrecondition || !strings.Contains(err.Error(), "budget.test/a@v1.0.0 is 6000 bytes, over its budget of 4k") {
+		t.Errorf("enforced pull: err = %v, want FailedPrecondition naming a", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "holon.sum")); !os.IsNotExist(err) {
+		t.Errorf("enforced pull wrote holon.sum: %v", err)
+	}
+
+	// Vendored without their tests, the dependencies fit their own
+	// budgets but not the total.
+	if err := os.WriteFile(filepath.Join(dir, ".holonvendorignore"), []byte("*_test.go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	vendored, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vendored.OverBudget) != 1 || vendored.OverBudget[0].Size != 9000 {
+		t.Errorf("vendor over budget = %v, want the total only", vendored.OverBudget)
+	}
+	mod.TotalBudget = 0
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, ".holonvendorignore")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir, EnforceBudget: true}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("enforced vendor: err = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestBuildList(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("a.buildlist.test", filesFetcher{files: map[string]map[string]string{
+		"v1.0.0": {"holon.mod": "holon a.buildlist.test/a\n\nrequire (\n    b.buildlist.test/b v1.0.0\n)\n"},
+	}})
+	fetch.Register("b.buildlist.test", filesFetcher{files: map[string]map[string]string{
+		"v1.0.0": {"holon.mod": "holon b.buildlist.test/b\n\nrequire (\n    c.buildlist.test/c v1.1.0\n)\n"},
+	}})
+	fetch.Register("c.buildlist.test", filesFetcher{files: map[string]map[string]string{
+		"v1.0.0": {"c.go": "package c // v1.0.0\n"},
+		"v1.1.0": {"c.go": "package c // v1.1.0\n"},
+	}})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/buildlist"}
+	mod.AddRequire("a.buildlist.test/a", "v1.0.0")
+	mod.AddRequire("c.buildlist.test/c", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The graph goes all the way down, c at the version b requires.
+	graph, err := srv.Graph(ctx, &pb.GraphRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var edges []string
+	for _, e := range graph.Edges {
+		edges = append(edges, e.From+" -> "+e.To+"@"+e.Version)
+	}
+	want := []string{
+		"test/buildlist -> a.buildlist.test/a@v1.0.0",
+		"a.buildlist.test/a -> b.buildlist.test/b@v1.0.0",
+		"b.buildlist.test/b -> c.buildlist.test/c@v1.1.0",
+		"test/buildlist -> c.buildlist.test/c@v1.0.0",
+	}
+	if !slices.Equal(edges, want) {
+		t.Errorf("edges = %q, want %q", edges, want)
+	}
+	for _, n := range graph.Nodes {
+		if n.Path == "c.buildlist.test/c" && n.Version != "v1.1.0" {
+			t.Errorf("c selected at %s, want v1.1.0", n.Version)
+		}
+	}
+
+	// Vendoring copies the build list, c at the version selected.
+	resp, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vendored []string
+	for _, d := range resp.Vendored {
+		vendored = append(vendored, d.Path+"@"+d.Version)
+	}
+	if want := []string{"a.buildlist.test/a@v1.0.0", "c.buildlist.test/c@v1.1.0", "b.buildlist.test/b@v1.0.0"}; !slices.Equal(vendored, want) {
+		t.Errorf("vendored %q, want %q", vendored, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".holon", "c", "c.go")); !strings.Contains(string(data), "v1.1.0") {
+		t.Errorf("vendored c = %q, want v1.1.0", data)
+	}
+
+	// Without the cache, each vendored copy is found at its version only.
+	if err := os.RemoveAll(server.CacheDir()); err != nil {
+		t.Fatal(err)
+	}
+	graph, err = srv.Graph(ctx, &pb.GraphRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range graph.Nodes[1:] {
+		if n.Location != "vendor" {
+			t.Errorf("%s@%s is in %q, want vendor", n.Path, n.Version, n.Location)
+		}
+	}
+}
+
+func TestVendorDir(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("lib.vendordir.test", filesFetcher{files: map[string]map[string]string{
+		"v1.0.0": {"holon.mod": "holon lib.vendordir.test/lib\n\nrequire (\n    sub.vendordir.test/sub v0.1.0\n)\n"},
+	}})
+	fetch.Register("sub.vendordir.test", filesFetcher{})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/vendordir", VendorDir: "third_party/holons"}
+	mod.AddRequire("lib.vendordir.test/lib", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: "lib.vendordir.test/lib", Version: "v1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The dependencies of lib are vendored too, once pulled.
+	if _, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir}); status.Code(err) != codes.FailedPrecondition || !strings.Contains(err.Error(), "sub.vendordir.test/sub@v0.1.0") {
+		t.Errorf("vendor before pull: err = %v, want sub not cached", err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "third_party", "holons", "lib"); len(resp.Vendored) != 2 || resp.Vendored[0].CachePath != want {
+		t.Fatalf("vendored %v, want %s and sub", resp.Vendored, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".holon", "lib")); !os.IsNotExist(err) {
+		t.Errorf("vendored to .holon/ too: %v", err)
+	}
+
+	// Without the cache, the graph and verify read the vendored copy.
+	if err := os.RemoveAll(server.CacheDir()); err != nil {
+		t.Fatal(err)
+	}
+	graph, err := srv.Graph(ctx, &pb.GraphRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Edges) != 2 || graph.Edges[1].To != "sub.vendordir.test/sub" {
+		t.Errorf("edges = %v, want lib and its sub", graph.Edges)
+	}
+	verify, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verify.Ok {
+		t.Errorf("verify of the vendored copy: %v", verify.Errors)
+	}
+}
+
+func TestHolonRootLookup(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("lib.root.test", filesFetcher{})
+
+	root := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/root"}
+	mod.AddRequire("lib.root.test/lib", "v1.0.0")
+	if err := mod.Write(filepath.Join(root, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(root, "cmd", "tool")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// From a subdirectory, the holon.mod of the nearest parent is used.
+	resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: sub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Root != root {
+		t.Errorf("root = %q, want %q", resp.Root, root)
+	}
+	if _, err := os.Stat(filepath.Join(root, "holon.sum")); err != nil {
+		t.Errorf("holon.sum not written at the root: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(sub, "holon.sum")); !os.IsNotExist(err) {
+		t.Errorf("holon.sum written in the subdirectory: %v", err)
+	}
+	graph, err := srv.Graph(ctx, &pb.GraphRequest{Directory: sub})
+	if err != nil || graph.Root != "test/root" {
+		t.Errorf("graph from a subdirectory: %v, %v", graph, err)
+	}
+
+	// The holon's own directory is kept as given.
+	if resp, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: root}); err != nil || resp.Root != root {
+		t.Errorf("verify at the root: %v, %v", resp, err)
+	}
+
+	// Outside any holon, holon.mod is still not found.
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: t.TempDir()}); status.Code(err) != codes.NotFound {
+		t.Errorf("pull outside a holon: %v, want NotFound", err)
+	}
+}
+
+func TestCheckCache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	holon := filepath.Join(home, ".holon")
+	old := time.Now().Add(-2 * time.Hour)
+	write := func(rel, content string) string {
+		path := filepath.Join(holon, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	age := func(path string) {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("cache/example.com/good@v1.0.0/main.go", "package good\n")
+	write("cache/example.com/clone@v1.0.0/.git/HEAD", "ref: refs/heads/main\n")
+	write("cache/example.com/clone@v1.0.0.info", "{}\n")
+	if err := os.MkdirAll(filepath.Join(holon, "cache/example.com/empty@v1.0.0/sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write("fetch-123/snapshot/a.go", "package a\n")
+	age(filepath.Join(holon, "fetch-123"))
+	write("fetch-456/snapshot/a.go", "package a\n") // a fetch still running
+	age(write("partial/abc/shallow.lock", ""))
+	write("partial/abc/HEAD", "ref: refs/heads/main\n")
+
+	srv := &server.Server{}
+	repairs, err := srv.CheckCache(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems := map[string]string{}
+	for _, r := range repairs {
+		rel, _ := filepath.Rel(holon, r.Path)
+		problems[filepath.ToSlash(rel)] = r.Problem
+		if r.Action != "removed" {
+			t.Errorf("%s: action %q", rel, r.Action)
+		}
+	}
+	want := map[string]string{
+		"cache/example.com/clone@v1.0.0": "partial clone",
+		"cache/example.com/empty@v1.0.0": "empty snapshot",
+		"fetch-123":                      "interrupted fetch",
+		"partial/abc/shallow.lock":       "lock remnant",
+	}
+	if !maps.Equal(problems, want) {
+		t.Errorf("repairs = %v, want %v", problems, want)
+	}
+	for _, rel := range []string{"cache/example.com/clone@v1.0.0", "cache/example.com/clone@v1.0.0.info", "fetch-123", "partial/abc/shallow.lock"} {
+		if _, err := os.Stat(filepath.Join(holon, rel)); !os.IsNotExist(err) {
+			t.Errorf("%s still there: %v", rel, err)
+		}
+	}
+	for _, rel := range []string{"cache/example.com/good@v1.0.0/main.go", "fetch-456", "partial/abc/HEAD"} {
+		if _, err := os.Stat(filepath.Join(holon, rel)); err != nil {
+			t.Errorf("%s removed: %v", rel, err)
+		}
+	}
+	if got := srv.CacheRepairs(); len(got) != len(repairs) {
+		t.Errorf("CacheRepairs() = %v, want %v", got, repairs)
+	}
+
+	// Quarantined remnants are moved aside instead.
+	write("cache/example.com/clone@v2.0.0/.git/HEAD", "ref: refs/heads/main\n")
+	repairs, err = srv.CheckCache(true)
+	if err != nil || len(repairs) != 1 {
+		t.Fatalf("CheckCache(true) = %v, %v", repairs, err)
+	}
+	moved := strings.TrimPrefix(repairs[0].Action, "quarantined to ")
+	if !strings.HasPrefix(moved, filepath.Join(holon, "quarantine")) {
+		t.Fatalf("action = %q", repairs[0].Action)
+	}
+	if _, err := os.Stat(filepath.Join(moved, ".git", "HEAD")); err != nil {
+		t.Errorf("not quarantined: %v", err)
+	}
+	if _, err := os.Stat(repairs[0].Path); !os.IsNotExist(err) {
+		t.Errorf("still in the cache: %v", err)
+	}
+}
+
+func TestRepairCorruptedCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	register := func(content string) {
+		registerFiles("lib.repair.test", "v1.0.0", map[string]string{"lib.go": content, "HOLON.md": "# lib\n"})
+	}
+	register("package lib\n")
+
+	mod := &modfile.ModFile{HolonPath: "test/repair"}
+	mod.AddRequire("lib.repair.test/lib", "v1.0.0")
+	dir := writeHolonMod(t, t.TempDir(), mod)
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	corrupt := func() {
+		t.Helper()
+		path := filepath.Join(server.CachePath("lib.repair.test/lib", "v1.0.0"), "lib.go")
+		if err := os.WriteFile(path, []byte("package corrupted\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without repair, a corrupted snapshot fails Pull and Verify.
+	corrupt()
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); status.Code(err) != codes.DataLoss {
+		t.Errorf("pull of a corrupted snapshot: %v, want DataLoss", err)
+	}
+	if resp, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir}); err != nil || resp.Ok {
+		t.Errorf("verify of a corrupted snapshot: %v, %v", resp, err)
+	}
+
+	// With it, the snapshot is fetched again.
+	resp, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir, Repair: true})
+	if err != nil || !resp.Ok || len(resp.Repaired) != 1 || resp.Repaired[0].Path != "lib.repair.test/lib" {
+		t.Fatalf("verify --repair: %v, %v", resp, err)
+	}
+	corrupt()
+	pull, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir, Repair: true})
+	if err != nil || len(pull.Repaired) != 1 {
+		t.Fatalf("pull --repair: %v, %v", pull, err)
+	}
+	if resp, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir}); err != nil || !resp.Ok {
+		t.Errorf("verify after repair: %v, %v", resp, err)
+	}
+
+	// Content changed upstream still fails once fetched again.
+	register("package moved\n")
+	corrupt()
+	_, err = srv.Pull(ctx, &pb.PullRequest{Directory: dir, Repair: true})
+	if status.Code(err) != codes.DataLoss || !strings.Contains(err.Error(), "upstream content changed") {
+		t.Errorf("pull --repair of a moved tag: %v, want DataLoss", err)
+	}
+	resp, err = srv.Verify(ctx, &pb.VerifyRequest{Directory: dir, Repair: true})
+	if err != nil || resp.Ok || len(resp.Repaired) != 0 {
+		t.Errorf("verify --repair of a moved tag: %v, %v", resp, err)
+	}
+}
+
+func TestStreamVendor(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	fetch.Register("lib.streamvendor.test", filesFetcher{files: map[string]map[string]string{
+		"v1.0.0": {"lib.go": "package lib"},
+	}})
+
+	client := serveMem(t, &server.Server{})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/streamvendor"}
+	mod.AddRequire("lib.streamvendor.test/a", "v1.0.0")
+	mod.AddRequire("lib.streamvendor.test/b", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	mod.AddRequire("github.com/test/missing", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	vendor := func(keepGoing bool) ([]*pb.VendorProgress, error) {
+		stream, err := client.StreamVendor(ctx, &pb.StreamVendorRequest{Directory: dir, KeepGoing: keepGoing})
+		if err != nil {
+			return nil, err
+		}
+		var msgs []*pb.VendorProgress
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				return msgs, nil
+			}
+			if err != nil {
+				return msgs, err
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+
+	// A dependency missing from the cache stops vendoring up front.
+	if msgs, err := vendor(false); status.Code(err) != codes.FailedPrecondition || len(msgs) != 0 {
+		t.Errorf("vendor with a missing dependency: %v, %v", msgs, err)
+	}
+
+	// With keep_going, it is reported and the others are vendored.
+	msgs, err := vendor(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 4 {
+		t.Fatalf("got %d messages, want 3 dependencies and a summary", len(msgs))
+	}
+	for i, msg := range msgs[:3] {
+		if msg.Index != int32(i+1) || msg.Total != 3 {
+			t.Errorf("message %d: index %d of %d", i, msg.Index, msg.Total)
+		}
+		missing := msg.Dependency.Path == "github.com/test/missing"
+		if missing != (msg.Error != "") {
+			t.Errorf("%s: error %q", msg.Dependency.Path, msg.Error)
+		}
+		if !missing {
+			if _, err := os.Stat(filepath.Join(msg.Dependency.CachePath, "lib.go")); err != nil {
+				t.Errorf("%s not vendored: %v", msg.Dependency.Path, err)
+			}
+		}
+	}
+	summary := msgs[3].Summary
+	if summary == nil || summary.Vendored != 2 || summary.Failed != 1 || summary.Bytes != 2*int64(len("package lib")) || summary.Root != dir {
+		t.Errorf("summary = %v", summary)
+	}
+}
+
+func TestStreamPull(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	fetch.Register("lib.streampull.test", filesFetcher{files: map[string]map[string]string{
+		"v1.0.0": {"lib.go": "package lib"},
+	}})
+
+	client := serveMem(t, &server.Server{})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/streampull"}
+	mod.AddRequire("lib.streampull.test/a", "v1.0.0")
+	mod.AddRequire("lib.streampull.test/b", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	// pull returns the stages each dependency went through, and the result.
+	pull := func() (map[string][]pb.PullStage, *pb.PullResponse) {
+		t.Helper()
+		stream, err := client.StreamPull(ctx, &pb.PullRequest{Directory: dir})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stages := map[string][]pb.PullStage{}
+		var result *pb.PullResponse
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				return stages, result
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != nil {
+				t.Fatalf("message after the result: %v", msg)
+			}
+			if result = msg.Result; result != nil {
+				continue
+			}
+			// How often the transfer is measured depends on timing.
+			if msg.Stage != pb.PullStage_PULL_STAGE_TRANSFERRING {
+				stages[msg.Dependency.Path] = append(stages[msg.Dependency.Path], msg.Stage)
+			}
+		}
+	}
+
+	stages, result := pull()
+	want := []pb.PullStage{pb.PullStage_PULL_STAGE_STARTED, pb.PullStage_PULL_STAGE_HASHED, pb.PullStage_PULL_STAGE_DONE}
+	for _, path := range []string{"lib.streampull.test/a", "lib.streampull.test/b"} {
+		if !slices.Equal(stages[path], want) {
+			t.Errorf("%s went through %v, want %v", path, stages[path], want)
+		}
+	}
+	if result == nil || len(result.Fetched) != 2 || result.Root != dir {
+		t.Fatalf("result = %v", result)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "holon.sum")); err != nil {
+		t.Errorf("holon.sum not written: %v", err)
+	}
+
+	// Cached dependencies are not fetched again.
+	stages, result = pull()
+	want = []pb.PullStage{pb.PullStage_PULL_STAGE_HASHED, pb.PullStage_PULL_STAGE_DONE}
+	if !slices.Equal(stages["lib.streampull.test/a"], want) || result == nil || len(result.Fetched) != 2 {
+		t.Errorf("pull of cached dependencies: %v, %v", stages, result)
+	}
+}
+
+// serveMem serves srv on a mem:// listener and returns a client for it.
+func serveMem(t *testing.T, srv *server.Server) pb.RhizomeAtlasServiceClient {
+	t.Helper()
+	mem := transport.NewMemListener()
+	s := grpc.NewServer()
+	pb.RegisterRhizomeAtlasServiceServer(s, srv)
+	go func() { _ = s.Serve(mem) }()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient(
+		"passthrough:///mem",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return mem.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewRhizomeAtlasServiceClient(conn)
+}
+
+func TestSubscribe(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fetch.Register("lib.events.test", filesFetcher{
+		tags:  []string{"v1.0.0", "v1.1.0"},
+		files: map[string]map[string]string{"v1.0.0": {"lib.go": "package lib"}},
+	})
+	srv := &server.Server{}
+	client := serveMem(t, srv)
+
+	subscribe := func(kinds ...pb.EventKind) pb.RhizomeAtlasService_SubscribeClient {
+		t.Helper()
+		stream, err := client.Subscribe(ctx, &pb.SubscribeRequest{Kinds: kinds})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := stream.Header(); err != nil {
+			t.Fatal(err)
+		}
+		return stream
+	}
+	all := subscribe()
+	verifies := subscribe(pb.EventKind_EVENT_KIND_VERIFY_FAILED)
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/events"}
+	mod.AddRequire("lib.events.test/lib", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	corrupted := filepath.Join(server.CachePath("lib.events.test/lib", "v1.0.0"), "lib.go")
+	if err := os.WriteFile(corrupted, []byte("package corrupted"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 { // an update is announced once
+		if _, err := srv.PendingUpdates(ctx, dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := srv.CleanCache(ctx, &pb.CleanCacheRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []pb.EventKind{
+		pb.EventKind_EVENT_KIND_FETCH_STARTED,
+		pb.EventKind_EVENT_KIND_FETCH_FINISHED,
+		pb.EventKind_EVENT_KIND_VERIFY_FAILED,
+		pb.EventKind_EVENT_KIND_UPDATE_AVAILABLE,
+		pb.EventKind_EVENT_KIND_CACHE_CLEANED,
+	}
+	for i, kind := range want {
+		ev, err := all.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.Kind != kind || ev.Sequence != uint64(i+1) {
+			t.Fatalf("event %d = %v, want %v", i+1, ev, kind)
+		}
+		switch kind {
+		case pb.EventKind_EVENT_KIND_FETCH_FINISHED:
+			if ev.Dependency.GetPath() != "lib.events.test/lib" || ev.Dependency.CachePath == "" || len(ev.Errors) != 0 {
+				t.Errorf("fetch finished = %v", ev)
+			}
+		case pb.EventKind_EVENT_KIND_VERIFY_FAILED:
+			if ev.Directory != dir || len(ev.Errors) == 0 {
+				t.Errorf("verify failed = %v", ev)
+			}
+		case pb.EventKind_EVENT_KIND_UPDATE_AVAILABLE:
+			if ev.Dependency.GetVersion() != "v1.0.0" || ev.NewVersion != "v1.1.0" {
+				t.Errorf("update available = %v", ev)
+			}
+		case pb.EventKind_EVENT_KIND_CACHE_CLEANED:
+			if ev.Entries != 1 || ev.Bytes != int64(len("package corrupted")) {
+				t.Errorf("cache cleaned = %v", ev)
+			}
+		}
+	}
+	if ev, err := verifies.Recv(); err != nil || ev.Kind != pb.EventKind_EVENT_KIND_VERIFY_FAILED || ev.Sequence != 3 {
+		t.Errorf("filtered subscriber got %v, %v", ev, err)
+	}
+
+	cancel()
+	if _, err := all.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("after cancel: %v, want Canceled", err)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("diag.test", storeFetcher{tags: []string{"v1.0.0", "v1.1.0-beta.1", "v1.1.0", "v2.0.0"}})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/diagnose"}
+	mod.AddRequire("diag.test/cached", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The saved file has no problem.
+	resp, err := srv.Diagnose(ctx, &pb.DiagnoseRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Diagnostics) != 0 {
+		t.Errorf("saved holon.mod: %v", resp.Diagnostics)
+	}
+
+	// An edited one is checked instead of the file.
+	content := "holon test/diagnose\nstable\n\nrequire (\n" +
+		"    diag.test/cached v1.0.0\n" + // 5
+		"    diag.test/missing v9.9.9\n" + // 6
+		"    diag.test/beta v1.1.0-beta.1\n" + // 7
+		"    diag.test/bad latest\n" + // 8
+		")\n\nreplace (\n" +
+		"    other.test/x => ../x\n" + // 12
+		")\n"
+	resp, err = srv.Diagnose(ctx, &pb.DiagnoseRequest{Directory: dir, Content: content, Remote: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range resp.Diagnostics {
+		got = append(got, fmt.Sprintf("%d:%d-%d %s", d.Line, d.Column, d.EndColumn, d.Code))
+	}
+	want := []string{
+		"5:22-28 update-available",
+		"6:23-29 unknown-version",
+		"6:5-22 not-cached",
+		"7:20-33 prerelease-forbidden",
+		"7:5-19 not-cached",
+		"8:19-25 invalid-version",
+		"12:5-25 unused-replace",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("diagnostics = %q, want %q", got, want)
+	}
+
+	update := resp.Diagnostics[0].Fixes
+	if len(update) != 1 || update[0].Title != "Update to v1.1.0" ||
+		!proto.Equal(update[0].Edits[0], &pb.TextEdit{Line: 5, Column: 22, EndLine: 5, EndColumn: 28, NewText: "v1.1.0"}) {
+		t.Errorf("update fix = %v", update)
+	}
+	unknown := resp.Diagnostics[1].Fixes
+	if len(unknown) != 2 || unknown[0].Title != "Update to v2.0.0" || unknown[1].Title != "Remove diag.test/missing" ||
+		!proto.Equal(unknown[1].Edits[0], &pb.TextEdit{Line: 6, Column: 1, EndLine: 7, EndColumn: 1}) {
+		t.Errorf("unknown version fixes = %v", unknown)
+	}
+	if beta := resp.Diagnostics[3].Fixes; len(beta) != 1 || beta[0].Title != "Update to v1.1.0" {
+		t.Errorf("prerelease fixes = %v", beta)
+	}
+
+	// A line that does not parse is reported alone.
+	resp, err = srv.Diagnose(ctx, &pb.DiagnoseRequest{Directory: dir, Content: "holon test/diagnose\nrequire (\n  diag.test/cached\n)\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Line != 3 || resp.Diagnostics[0].Column != 3 || resp.Diagnostics[0].Code != "invalid-line" {
+		t.Errorf("parse error: %v", resp.Diagnostics)
+	}
+
+	// A cached snapshot no longer matching holon.sum.
+	cached, err := server.CachedDir("diag.test/cached", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cached, "HOLON.md"), []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = srv.Diagnose(ctx, &pb.DiagnoseRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Code != "hash-mismatch" {
+		t.Errorf("tampered cache: %v", resp.Diagnostics)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("env.test", storeFetcher{})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/env"}
+	mod.AddRequire("env.test/go-holons", "v1.0.0")
+	mod.AddRequire("env.test/vendored", "v1.0.0")
+	mod.AddRequire("other.test/local", "v1.0.0")
+	mod.Replace = []modfile.Replace{{Old: "other.test/local", New: "../local"}}
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(server.CachePath("env.test/vendored", "v1.0.0")); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := srv.Env(ctx, &pb.EnvRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, dep := range resp.Dependencies {
+		got = append(got, dep.Variable+"="+dep.Dir+" ("+dep.Location+")")
+	}
+	want := []string{
+		"HOLON_DEP_GO_HOLONS=" + server.CachePath("env.test/go-holons", "v1.0.0") + " (cache)",
+		"HOLON_DEP_VENDORED=" + filepath.Join(dir, ".holon", "vendored") + " (vendor)",
+		"HOLON_DEP_LOCAL=" + filepath.Join(filepath.Dir(dir), "local") + " (replace)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("env = %q, want %q", got, want)
+	}
+
+	// An alias names the variable; names must not clash.
+	mod.Require = append(mod.Require, modfile.Require{Path: "env.test/x/vendored", Version: "v1.0.0"})
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Env(ctx, &pb.EnvRequest{Directory: dir}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("clashing names: %v, want FailedPrecondition", err)
+	}
+	mod.Require[3].Alias = "other-vendored"
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = srv.Env(ctx, &pb.EnvRequest{Directory: dir})
+	if err != nil || len(resp.Dependencies) != 4 || resp.Dependencies[3].Variable != "HOLON_DEP_OTHER_VENDORED" {
+		t.Errorf("aliased: %v, %v", resp, err)
+	}
+
+	// A dependency neither cached nor vendored is reported.
+	for _, p := range []string{server.CachePath("env.test/go-holons", "v1.0.0"), filepath.Join(dir, ".holon", "go-holons")} {
+		if err := os.RemoveAll(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := srv.Env(ctx, &pb.EnvRequest{Directory: dir}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("not cached: %v, want FailedPrecondition", err)
+	}
+}
+
+func TestReplaceDiff(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	upstream := map[string]string{
+		"HOLON.md": "---\nname: lib\n---\n",
+		"lib.go":   "package lib\n\nfunc A() {}\n\nfunc B() {}\n\nfunc C() {}\n\nfunc D() {}\n\nfunc E() {}\n\nfunc F() {}\n\nfunc G() {}\n",
+		"old.go":   "package lib\n",
+		"logo.png": "\x89PNG\x00\x01",
+		"USE.md":   "# Use\n",
+	}
+	fetch.Register("replacediff.test", filesFetcher{tags: []string{"v1.0.0"}, files: map[string]map[string]string{"v1.0.0": upstream}})
+
+	root := t.TempDir()
+	dir, local := filepath.Join(root, "app"), filepath.Join(root, "lib")
+	mod := &modfile.ModFile{HolonPath: "test/replacediff"}
+	mod.AddRequire("replacediff.test/lib", "v1.0.0")
+	mod.Replace = []modfile.Replace{{Old: "replacediff.test/lib", New: "../lib"}}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range upstream {
+		switch name {
+		case "lib.go":
+			content = strings.Replace(content, "func B() {}", "func B() { fixed() }", 1) + "\nfunc fixed() {}"
+		case "old.go":
+			continue
+		case "logo.png":
+			content += "\x02"
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(local, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(local, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range map[string]string{"new.go": "package lib\n", ".git/HEAD": "ref: refs/heads/main\n"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(local, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(local, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The version required is fetched to be compared with.
+	resp, err := srv.ReplaceDiff(ctx, &pb.ReplaceDiffRequest{Directory: dir, Path: "replacediff.test/lib"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Version != "v1.0.0" || resp.CachePath != server.CachePath("replacediff.test/lib", "v1.0.0") || resp.ReplaceDir != local {
+		t.Errorf("compared %s@%s in %s with %s", resp.Path, resp.Version, resp.CachePath, resp.ReplaceDir)
+	}
+	var got []string
+	for _, f := range resp.Files {
+		got = append(got, fmt.Sprintf("%s %s binary=%v +%d -%d", f.Change, f.Path, f.Binary, f.Added, f.Deleted))
+	}
+	want := []string{
+		"FILE_CHANGE_MODIFIED lib.go binary=false +3 -1",
+		"FILE_CHANGE_MODIFIED logo.png binary=true +0 -0",
+		"FILE_CHANGE_ADDED new.go binary=false +1 -0",
+		"FILE_CHANGE_DELETED old.go binary=false +0 -1",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("files = %q, want %q", got, want)
+	}
+	wantDiff := `--- a/lib.go
++++ b/lib.go
+@@ -2,7 +2,7 @@
+ 
+ func A() {}
+ 
+-func B() {}
++func B() { fixed() }
+ 
+ func C() {}
+ 
+@@ -13,3 +13,5 @@
+ func F() {}
+ 
+ func G() {}
++
++func fixed() {}
+\ No newline at end of file
+`
+	if resp.Files[0].Diff != wantDiff {
+		t.Errorf("diff:\n%s\nwant:\n%s", resp.Files[0].Diff, wantDiff)
+	}
+	if d := resp.Files[2].Diff; d != "--- /dev/null\n+++ b/new.go\n@@ -0,0 +1 @@\n+package lib\n" {
+		t.Errorf("diff of added file:\n%s", d)
+	}
+
+	// With stat, only the counts; a dependency not replaced locally has no
+	// diff.
+	if resp, err := srv.ReplaceDiff(ctx, &pb.ReplaceDiffRequest{Directory: dir, Path: "replacediff.test/lib", Stat: true}); err != nil || resp.Files[0].Diff != "" || resp.Files[0].Added != 3 {
+		t.Errorf("stat: %v, %v", resp, err)
+	}
+	mod.Replace = nil
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.ReplaceDiff(ctx, &pb.ReplaceDiffRequest{Directory: dir, Path: "replacediff.test/lib"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("not replaced: err = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestReplacePromote(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	patched := "package lib\n\nfunc A() { fixed() }\n\nfunc fixed() {}\n"
+	fetch.Register("promote.test", filesFetcher{tags: []string{"v1.0.1"}, files: map[string]map[string]string{
+		"v1.0.1": {"HOLON.md": "---\nname: lib\n---\n", "lib.go": patched},
+	}})
+
+	root := t.TempDir()
+	local := filepath.Join(root, "lib")
+	for name, content := range map[string]string{"HOLON.md": "---\nname: lib\n---\n", "lib.go": patched, "wip.go": "package lib\n"} {
+		if err := os.MkdirAll(local, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(local, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newHolon := func(name string) string {
+		dir := filepath.Join(root, name)
+		mod := &modfile.ModFile{HolonPath: "test/" + name}
+		mod.AddRequire("promote.test/lib", "v1.0.0")
+		if err := mod.SetAlias("promote.test/lib", "lib"); err != nil {
+			t.Fatal(err)
+		}
+		mod.Replace = []modfile.Replace{{Old: "promote.test/lib", New: "../lib"}}
+		return writeHolonMod(t, dir, mod)
+	}
+
+	// A dry run plans the fetch and writes nothing.
+	dir := newHolon("app")
+	before, _ := os.ReadFile(filepath.Join(dir, "holon.mod"))
+	dry, err := srv.ReplacePromote(ctx, &pb.ReplacePromoteRequest{Directory: dir, Path: "lib", To: "promote.test/fork", Version: "v1.0.1", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dry.Plan == nil || len(dry.Plan.Fetch) != 1 || len(dry.Plan.Changes) == 0 {
+		t.Errorf("plan = %v", dry.Plan)
+	}
+	if after, _ := os.ReadFile(filepath.Join(dir, "holon.mod")); !bytes.Equal(before, after) {
+		t.Errorf("dry run wrote holon.mod:\n%s", after)
+	}
+
+	// The require moves to the fork, keeping its alias.
+	resp, err := srv.ReplacePromote(ctx, &pb.ReplacePromoteRequest{Directory: dir, Path: "lib", To: "promote.test/fork", Version: "v1.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod, err := modfile.Parse(filepath.Join(dir, "holon.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dep, ok := mod.RequireByName("lib"); !ok || dep.Path != "promote.test/fork" || dep.Version != "v1.0.1" || len(mod.Replace) != 0 {
+		t.Errorf("after promote: require %v, replace %v", mod.Require, mod.Replace)
+	}
+	sum, err := modfile.ParseSum(filepath.Join(dir, "holon.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Lookup("promote.test/fork", "v1.0.1") == "" {
+		t.Errorf("holon.sum lacks the fork: %v", sum.Entries)
+	}
+	if len(resp.Differences) != 1 || resp.Differences[0].Path != "wip.go" || resp.Differences[0].Change != pb.FileChange_FILE_CHANGE_ADDED {
+		t.Errorf("differences = %v, want wip.go added", resp.Differences)
+	}
+	_, err = srv.ReplacePromote(ctx, &pb.ReplacePromoteRequest{Directory: dir, Path: "lib", To: "promote.test/fork", Version: "v1.0.1"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("promoting again: %v, want FailedPrecondition", err)
+	}
+
+	// With KeepPath, the require keeps its path, replaced remotely.
+	dir = newHolon("kept")
+	resp, err = srv.ReplacePromote(ctx, &pb.ReplacePromoteRequest{Directory: dir, Path: "promote.test/lib", To: "promote.test/fork", Version: "v1.0.1", KeepPath: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Replace != "promote.test/lib => promote.test/fork" {
+		t.Errorf("replace = %q", resp.Replace)
+	}
+	if mod, err = modfile.Parse(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if dep, ok := mod.RequireByName("lib"); !ok || dep.Path != "promote.test/lib" || dep.Version != "v1.0.1" || mod.SourcePath(dep.Path) != "promote.test/fork" {
+		t.Errorf("after promote --keep-path: require %v, replace %v", mod.Require, mod.Replace)
+	}
+}
+
+func TestSubmodules(t *testing.T) {
+	gitTest(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	proxy := t.TempDir()
+
+	// The holon pins a commit of a library, which is not the tip of its
+	// repository, through a relative URL.
+	lib := filepath.Join(proxy, "atlas.invalid/test/lib")
+	writeHolonMD(t, lib, "name: lib\n")
+	gitLib := gitRepo(t, lib)
+	gitLib("add", ".")
+	gitLib("commit", "-q", "-m", "lib")
+	pinned := gitLib("rev-parse", "HEAD")
+	gitLib("commit", "-q", "--allow-empty", "-m", "later")
+
+	depPath := "atlas.invalid/test/super"
+	super := filepath.Join(proxy, depPath)
+	writeHolonMD(t, super, "name: super\n")
+	if err := os.WriteFile(filepath.Join(super, ".gitmodules"), []byte("[submodule \"third_party.lib\"]\n\tpath = third_party/lib\n\turl = ../lib\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitSuper := gitRepo(t, super)
+	gitSuper("add", ".")
+	gitSuper("update-index", "--add", "--cacheinfo", "160000,"+pinned+",third_party/lib")
+	gitSuper("commit", "-q", "-m", "super")
+	gitSuper("tag", "v1.0.0")
+	t.Setenv("ATLAS_PROXY", "file://"+proxy)
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/submodules"}
+	mod.AddRequire(depPath, "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	for env, want := range map[string]string{
+		"":            "set ATLAS_SUBMODULE_HOSTS",
+		"example.com": "only ATLAS_SUBMODULE_HOSTS=* allows",
+	} {
+		t.Setenv("ATLAS_SUBMODULE_HOSTS", env)
+		if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ATLAS_SUBMODULE_HOSTS=%q: err = %v, want %q", env, err, want)
+		}
+	}
+
+	t.Setenv("ATLAS_SUBMODULE_HOSTS", "*")
+	resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(resp.Fetched[0].CachePath, "third_party/lib/HOLON.md"))
+	if err != nil || !strings.Contains(string(data), "name: lib") {
+		t.Errorf("submodule content = %q, %v", data, err)
+	}
+
+	// The submodule content is hashed: a stray edit to it is caught.
+	if err := os.WriteFile(filepath.Join(resp.Fetched[0].CachePath, "third_party/lib/HOLON.md"), []byte("edited"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir}); err != nil || v.Ok {
+		t.Errorf("verify after editing the submodule = %v, %v, want a mismatch", v, err)
+	}
+}
+
+func TestFetchSandbox(t *testing.T) {
+	gitTest(t)
+	realGit, _ := exec.LookPath("git")
+	home := os.Getenv("HOME")
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	proxy := t.TempDir()
+	depPath := "atlas.invalid/test/sandboxed"
+	repo := filepath.Join(proxy, depPath)
+	writeHolonMD(t, repo, "name: sandboxed\n")
+	tagRelease(t, repo, "v0.1.0")
+	gitRepo(t, repo)("tag", "v0.2.0")
+	t.Setenv("ATLAS_PROXY", "file://"+proxy)
+
+	// A global git configuration redirecting the proxy away would break
+	// the fetch if it were read.
+	gitconfig := fmt.Sprintf("[url \"file://%s/\"]\n\tinsteadOf = file://%s/\n", t.TempDir(), proxy)
+	if err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte(gitconfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A git wrapper logs the environment every command runs with.
+	bin, log := t.TempDir(), filepath.Join(t.TempDir(), "git.log")
+	wrapper := fmt.Sprintf("#!/bin/sh\necho \"$HOME $SECRET_TOKEN $*\" >> %s\nexec %s \"$@\"\n", log, realGit)
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte(wrapper), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SECRET_TOKEN", "s3cret")
+
+	pull := func(version string) []string {
+		t.Helper()
+		os.Remove(log) //nolint:errcheck
+		dir := t.TempDir()
+		mod := &modfile.ModFile{HolonPath: "test/sandbox"}
+		mod.AddRequire(depPath, version)
+		if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+
+	for _, line := range pull("v0.1.0") {
+		if strings.HasPrefix(line, home+" ") || strings.Contains(line, "s3cret") {
+			t.Errorf("git ran unsandboxed: %s", line)
+		}
+	}
+
+	// Fetches from hosts allowed credentials inherit the environment.
+	t.Setenv("ATLAS_FETCH_CREDENTIALS", "example.com, atlas.invalid")
+	inherited := false
+	for _, line := range pull("v0.2.0") {
+		remote := strings.Contains(line, "https://atlas.invalid/")
+		if remote != strings.HasPrefix(line, home+" s3cret ") {
+			t.Errorf("credentials only go to atlas.invalid: %s", line)
+		}
+		inherited = inherited || remote
+	}
+	if !inherited {
+		t.Error("no fetch from atlas.invalid")
+	}
+}
+
+// TestFetchWithoutGit checks that a host without git fetches in process,
+// with go-git: tags, commits and trees, but not submodules.
+func TestFetchWithoutGit(t *testing.T) {
+	gitTest(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("nogit.test", storeFetcher{})
+
+	proxy := t.TempDir()
+	t.Setenv("ATLAS_PROXY", "file://"+proxy)
+	depPath := "git.nogit.example/b"
+	repo := filepath.Join(proxy, depPath)
+	git := gitRepo(t, repo)
+	writeHolonMD(t, repo, "name: b\n")
+	v1 := tagRelease(t, repo, "v1.0.0")
+	git("commit", "-q", "--allow-empty", "-m", "v1.1.0")
+	git("tag", "-a", "-m", "Release v1.1.0", "v1.1.0")
+	v11 := git("rev-parse", "HEAD")
+	subPath := "git.nogit.example/sub"
+	sub := filepath.Join(proxy, subPath)
+	gitRepo(t, sub)
+	writeHolonMD(t, sub, "name: sub\n")
+	if err := os.WriteFile(filepath.Join(sub, ".gitmodules"), []byte("[submodule \"b\"]\n\tpath = b\n\turl = ../b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tagRelease(t, sub, "v1.0.0")
+	t.Setenv("PATH", t.TempDir())
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/nogit"}
+	mod.AddRequire("nogit.test/a", "v1.0.0")
+	mod.AddRequire(depPath, "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatalf("pull without git: %v", err)
+	}
+	desc, err := srv.Describe(ctx, &pb.DescribeRequest{Directory: dir, Path: depPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := desc.Holon.GetFetch().GetCommit(); got != v1 {
+		t.Errorf("commit of %s@v1.0.0 = %q, want %s", depPath, got, v1)
+	}
+
+	updated, err := srv.Update(ctx, &pb.UpdateRequest{Directory: dir})
+	if err != nil {
+		t.Fatalf("update without git: %v", err)
+	}
+	if len(updated.Updated) != 1 || updated.Updated[0].NewVersion != "v1.1.0" {
+		t.Fatalf("updated = %v, want %s to v1.1.0", updated.Updated, depPath)
+	}
+	info, err := os.ReadFile(server.CachePath(depPath, "v1.1.0") + ".info")
+	if err != nil || !strings.Contains(string(info), v11) || !strings.Contains(string(info), `"tag_object"`) {
+		t.Errorf("fetch info of the annotated v1.1.0 = %s, %v, want commit %s and its tag", info, err, v11)
+	}
+
+	added, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: depPath, Version: v1[:10]})
+	if err != nil {
+		t.Fatalf("add a commit without git: %v", err)
+	}
+	if v := added.Dependency.GetVersion(); !strings.HasSuffix(v, "-"+v1[:12]) {
+		t.Errorf("version of commit %s = %s, want its pseudo-version", v1[:10], v)
+	}
+
+	_, err = srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: subPath, Version: "v1.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "submodules") {
+		t.Errorf("submodules without git: err = %v, want them refused", err)
+	}
+}
+
+func TestGitAuth(t *testing.T) {
+	gitTest(t)
+	realGit, _ := exec.LookPath("git")
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	proxy := t.TempDir()
+	depPath := "atlas.invalid/private/lib"
+	repo := filepath.Join(proxy, depPath)
+	writeHolonMD(t, repo, "name: lib\n")
+	tagRelease(t, repo, "v0.1.0")
+	gitRepo(t, repo)("tag", "v0.2.0")
+	gitRepo(t, repo)("tag", "v0.3.0")
+	t.Setenv("ATLAS_PROXY", "file://"+proxy)
+
+	// A git wrapper logs the configuration and SSH command each command
+	// gets from its environment.
+	bin, log := t.TempDir(), filepath.Join(t.TempDir(), "git.log")
+	wrapper := fmt.Sprintf("#!/bin/sh\necho \"$GIT_CONFIG_KEY_0=$GIT_CONFIG_VALUE_0|$GIT_SSH_COMMAND|$*\" >> %s\nexec %s \"$@\"\n", log, realGit)
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte(wrapper), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	pull := func(version string) []string {
+		t.Helper()
+		os.Remove(log) //nolint:errcheck
+		dir := t.TempDir()
+		mod := &modfile.ModFile{HolonPath: "test/gitauth"}
+		mod.AddRequire(depPath, version)
+		if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+	// checkHeader checks that only the fetches from the upstream
+	// repository carry the Authorization header of user:password.
+	checkHeader := func(lines []string, user, password string) {
+		t.Helper()
+		header := "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+		sent := false
+		for _, line := range lines {
+			upstream := strings.Contains(line, " https://atlas.invalid/private/lib")
+			if upstream != strings.Contains(line, header) {
+				t.Errorf("credentials only go to the upstream repository: %s", line)
+			}
+			sent = sent || upstream
+		}
+		if !sent {
+			t.Error("no fetch from the upstream repository")
+		}
+	}
+
+	t.Setenv("ATLAS_GIT_AUTH", "atlas.invalid/other=ssh, atlas.invalid/private=token:LIB_TOKEN")
+	t.Setenv("LIB_TOKEN", "t0ken")
+	checkHeader(pull("v0.1.0"), "x-access-token", "t0ken")
+
+	netrc := filepath.Join(t.TempDir(), "netrc")
+	if err := os.WriteFile(netrc, []byte("machine example.com login other password wrong\nmachine atlas.invalid\n  login me\n  password pw\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NETRC", netrc)
+	t.Setenv("ATLAS_GIT_AUTH", "atlas.invalid=netrc")
+	checkHeader(pull("v0.2.0"), "me", "pw")
+
+	// Over SSH, the keys of the user are offered instead.
+	t.Setenv("ATLAS_GIT_AUTH", "atlas.invalid/private=ssh")
+	overSSH := false
+	for _, line := range pull("v0.3.0") {
+		if strings.Contains(line, "https://atlas.invalid/") {
+			t.Errorf("fetched over HTTPS: %s", line)
+		}
+		if strings.Contains(line, " ssh://git@atlas.invalid/private/lib") {
+			overSSH = true
+			if !strings.Contains(line, "|ssh -o BatchMode=yes|") {
+				t.Errorf("SSH sandboxed: %s", line)
+			}
+		}
+	}
+	if !overSSH {
+		t.Error("no fetch over SSH")
+	}
+}
+
+func TestFetchChecksMismatch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+	upstream := "---\nname: moving\n---\n"
+	fetch.Register("moving.fetchcheck.test", movingFetcher{&upstream})
+	const dep = "moving.fetchcheck.test/dep"
+
+	srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/fetch-check"}) //nolint:errcheck
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: dep, Version: "v1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	sumPath := filepath.Join(dir, "holon.sum")
+	recorded, err := os.ReadFile(sumPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The tag moved upstream: what is fetched again is refused.
+	upstream = "---\nname: moved\n---\n"
+	evict := func() {
+		t.Helper()
+		if err := os.RemoveAll(server.CachePath(dep, "v1.0.0")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	refused := func(what string, err error) {
+		t.Helper()
+		if status.Code(err) != codes.DataLoss || !strings.Contains(err.Error(), "--force") {
+			t.Errorf("%s: err = %v, want DataLoss suggesting --force", what, err)
+		}
+		if _, err := os.Stat(server.CachePath(dep, "v1.0.0")); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: mismatched snapshot cached: %v", what, err)
+		}
+		if data, _ := os.ReadFile(sumPath); !bytes.Equal(data, recorded) {
+			t.Errorf("%s: holon.sum changed:\n%s", what, data)
+		}
+	}
+	evict()
+	_, err = srv.Pull(ctx, &pb.PullRequest{Directory: dir})
+	refused("pull", err)
+	_, err = srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: dep, Version: "v1.0.0"})
+	refused("add", err)
+
+	// With force, the new content is kept and replaces the entry.
+	resp, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir, Force: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(resp.Fetched[0].CachePath, "HOLON.md")); string(data) != upstream {
+		t.Errorf("cached = %q, want %q", data, upstream)
+	}
+	if data, _ := os.ReadFile(sumPath); bytes.Equal(data, recorded) {
+		t.Error("holon.sum not updated")
+	}
+	if _, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	upstream = "---\nname: moved again\n---\n"
+	evict()
+	if _, err := srv.Add(ctx, &pb.AddRequest{Directory: dir, Path: dep, Version: "v1.0.0", Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Errorf("pull after add --force: %v", err)
+	}
+}
+
+func TestPinnedCommits(t *testing.T) {
+	gitTest(t)
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	proxy := t.TempDir()
+	depPath := "atlas.invalid/test/pinned"
+	repo := filepath.Join(proxy, depPath)
+	writeHolonMD(t, repo, "name: pinned\n")
+	gitIn := gitRepo(t, repo)
+	gitIn("add", ".")
+	gitIn("commit", "-q", "-m", "init")
+	gitIn("tag", "-a", "v1.0.0", "-m", "Release v1.0.0")
+	first := gitIn("rev-parse", "v1.0.0^{commit}")
+	t.Setenv("ATLAS_PROXY", "file://"+proxy)
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/pinned"}
+	mod.AddRequire(depPath, "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := modfile.ParseSum(filepath.Join(dir, "holon.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sum.Lookup(depPath, "v1.0.0/commit"); got != "git:"+first {
+		t.Fatalf("pinned commit = %q, want git:%s", got, first)
+	}
+
+	// The tag is force-moved to another commit with the same content.
+	gitIn("commit", "-q", "--allow-empty", "-m", "moved")
+	gitIn("tag", "-f", "-a", "v1.0.0", "-m", "Release v1.0.0 again")
+	second := gitIn("rev-parse", "v1.0.0^{commit}")
+
+	if v, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir}); err != nil || !v.Ok {
+		t.Errorf("verify of the cache: %v, %v", v, err)
+	}
+	v, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir, Remote: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Ok || !slices.ContainsFunc(v.Errors, func(e string) bool { return strings.Contains(e, "tag moved upstream") }) {
+		t.Errorf("verify --remote errors = %v, want the moved tag", v.Errors)
+	}
+
+	u, err := srv.Update(ctx, &pb.UpdateRequest{Directory: dir, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &pb.MovedTag{Path: depPath, Version: "v1.0.0", PinnedCommit: first, UpstreamCommit: second}
+	if len(u.Moved) != 1 || !proto.Equal(u.Moved[0], want) {
+		t.Errorf("moved = %v, want %v", u.Moved, want)
+	}
+
+	// Fetched again, the moved tag is refused unless forced.
+	if err := os.RemoveAll(server.CachePath(depPath, "v1.0.0")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); status.Code(err) != codes.DataLoss {
+		t.Errorf("pull of the moved tag: err = %v, want DataLoss", err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir, Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if sum, _ = modfile.ParseSum(filepath.Join(dir, "holon.sum")); sum.Lookup(depPath, "v1.0.0/commit") != "git:"+second {
+		t.Errorf("pinned commit after --force = %q, want git:%s", sum.Lookup(depPath, "v1.0.0/commit"), second)
+	}
+}
+
+func TestInitFromExisting(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	files := func(v string) map[string]string {
+		return map[string]string{"holon.mod": "holon adopt.test/lib\n", "lib.txt": "lib " + v}
+	}
+	fetch.Register("adopt.test", filesFetcher{files: map[string]map[string]string{
+		"v1.0.0": files("v1.0.0"),
+		"v1.1.0": files("v1.1.0"),
+		"v2.0.0": {"bare.txt": "bare"},
+	}})
+	if _, err := srv.Prefetch(ctx, &pb.PrefetchRequest{
+		Dependencies: []string{"adopt.test/lib@v1.0.0", "adopt.test/lib@v1.1.0", "adopt.test/bare@v2.0.0"},
+		Wait:         true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/adopt", FromExisting: true}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Init without .holon: err = %v, want FailedPrecondition", err)
+	}
+	vendored := map[string]map[string]string{
+		"lib":     files("v1.1.0"),
+		"bare":    {"bare.txt": "bare"},
+		"tagged":  {"holon.mod": "holon adopt.test/tagged\n", "HOLON.md": "---\nversion: v0.3.0\n---\n"},
+		"orphan":  {"holon.mod": "holon adopt.test/orphan\n"},
+		"mystery": {"notes.txt": "?"},
+	}
+	for name, content := range vendored {
+		if err := (filesSource(content)).Fetch(ctx, filepath.Join(dir, ".holon", name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/adopt", FromExisting: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod, err := modfile.Parse(filepath.Join(dir, "holon.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range mod.Require {
+		got = append(got, r.Path+" "+r.Version)
+	}
+	slices.Sort(got)
+	want := []string{"adopt.test/bare v2.0.0", "adopt.test/lib v1.1.0", "adopt.test/tagged v0.3.0"}
+	if !slices.Equal(got, want) {
+		t.Errorf("requires = %q, want %q", got, want)
+	}
+	if len(resp.Inferred) != 3 {
+		t.Errorf("inferred = %v, want 3 dependencies", resp.Inferred)
+	}
+	if len(resp.Unresolved) != 2 ||
+		!slices.ContainsFunc(resp.Unresolved, func(u string) bool { return strings.HasPrefix(u, ".holon/orphan: ") }) ||
+		!slices.ContainsFunc(resp.Unresolved, func(u string) bool { return strings.HasPrefix(u, ".holon/mystery: ") }) {
+		t.Errorf("unresolved = %q, want orphan and mystery", resp.Unresolved)
+	}
+}
+
+func TestCacheOrphans(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("orphans.test", storeFetcher{})
+
+	root := t.TempDir()
+	for name, version := range map[string]string{"a": "v1.0.0", "b": "v1.1.0"} {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		mod := &modfile.ModFile{HolonPath: "test/" + name}
+		mod.AddRequire("orphans.test/x", version)
+		if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := srv.Prefetch(ctx, &pb.PrefetchRequest{
+		Dependencies: []string{"orphans.test/x@v0.9.0", "orphans.test/y@v1.0.0"},
+		Wait:         true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := srv.CacheOrphans(ctx, &pb.CacheOrphansRequest{Directories: []string{filepath.Join(root, "typo")}}); status.Code(err) != codes.NotFound {
+		t.Errorf("directory without holon.mod: err = %v, want NotFound", err)
+	}
+
+	orphans := func(req *pb.CacheOrphansRequest) []string {
+		t.Helper()
+		resp, err := srv.CacheOrphans(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range resp.Orphans {
+			got = append(got, e.Path+"@"+e.Version)
+		}
+		return got
+	}
+	if got, want := orphans(&pb.CacheOrphansRequest{Root: root}), []string{"orphans.test/x@v0.9.0", "orphans.test/y@v1.0.0"}; !slices.Equal(got, want) {
+		t.Errorf("orphans under root = %q, want %q", got, want)
+	}
+	if got, want := orphans(&pb.CacheOrphansRequest{Directories: []string{filepath.Join(root, "a")}}), []string{"orphans.test/x@v0.9.0", "orphans.test/x@v1.1.0", "orphans.test/y@v1.0.0"}; !slices.Equal(got, want) {
+		t.Errorf("orphans of a = %q, want %q", got, want)
+	}
+
+	orphans(&pb.CacheOrphansRequest{Root: root, Delete: true})
+	for version, cached := range map[string]bool{"v0.9.0": false, "v1.0.0": true, "v1.1.0": true} {
+		if _, err := os.Stat(server.CachePath("orphans.test/x", version)); (err == nil) != cached {
+			t.Errorf("x@%s cached = %v, want %v", version, err == nil, cached)
+		}
+	}
+	if got := orphans(&pb.CacheOrphansRequest{Root: root}); len(got) != 0 {
+		t.Errorf("orphans after --delete = %q, want none", got)
+	}
+}
+
+func TestCacheSeed(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("seed.test", storeFetcher{})
+
+	dir := t.TempDir()
+	mod := &modfile.ModFile{HolonPath: "test/seed"}
+	mod.AddRequire("seed.test/a", "v1.0.0")
+	mod.AddRequire("seed.test/b", "v1.2.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Vendor(ctx, &pb.VendorRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh machine: the vendored copies are all there is.
+	t.Setenv("HOME", t.TempDir())
+	plan, err := srv.CacheSeed(ctx, &pb.CacheSeedRequest{Directory: dir, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{server.CachePath("seed.test/a", "v1.0.0"), server.CachePath("seed.test/b", "v1.2.0")}; plan.Plan == nil || !slices.Equal(plan.Plan.Write, want) {
+		t.Fatalf("dry run plan = %v, want writes %q", plan.Plan, want)
+	}
+	if _, err := os.Stat(server.CacheDir()); err == nil {
+		t.Error("dry run created the cache")
+	}
+
+	resp, err := srv.CacheSeed(ctx, &pb.CacheSeedRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Seeded) != 2 || len(resp.Present) != 0 {
+		t.Fatalf("seeded %d, present %d, want 2 and 0", len(resp.Seeded), len(resp.Present))
+	}
+	if _, err := os.Stat(filepath.Join(server.CachePath("seed.test/b", "v1.2.0"), "HOLON.md")); err != nil {
+		t.Errorf("b not seeded: %v", err)
+	}
+	if _, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir}); err != nil {
+		t.Errorf("verify after seeding: %v", err)
+	}
+	if resp, err = srv.CacheSeed(ctx, &pb.CacheSeedRequest{Directory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Seeded) != 0 || len(resp.Present) != 2 {
+		t.Errorf("seeding again: seeded %d, present %d, want 0 and 2", len(resp.Seeded), len(resp.Present))
+	}
+
+	// A tampered copy installs nothing.
+	t.Setenv("HOME", t.TempDir())
+	if err := os.WriteFile(filepath.Join(dir, ".holon", "b", "HOLON.md"), []byte("---\nname: tampered\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = srv.CacheSeed(ctx, &pb.CacheSeedRequest{Directory: dir})
+	if status.Code(err) != codes.DataLoss || errorReason(err) != client.ReasonHashMismatch {
+		t.Fatalf("tampered copy: err = %v, want a hash mismatch", err)
+	}
+	if _, err := os.Stat(server.CachePath("seed.test/a", "v1.0.0")); err == nil {
+		t.Error("a was seeded despite the mismatch of b")
+	}
+}
+
+func TestExpiredPins(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+	fetch.Register("expiry.test", storeFetcher{tags: []string{"v1.0.0"}})
+
+	dir := t.TempDir()
+	content := `holon test/expiry
+
+require (
+    expiry.test/a v1.0.0 // until=2000-01-01
+    expiry.test/b v1.0.0
+    expiry.test/c v1.0.0 // until=2999-01-01
+)
+
+replace (
+    expiry.test/b => ../b // until=2000-01-01
+)
+`
+	if err := os.WriteFile(filepath.Join(dir, "holon.mod"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modPath := filepath.Join(dir, "holon.mod")
+	want := []string{
+		modPath + ":4: expiry.test/a v1.0.0 was to stay until 2000-01-01 — update it or push back its until date",
+		modPath + ":10: replace expiry.test/b => ../b was to stay until 2000-01-01 — remove it or push back its until date",
+	}
+
+	f, err := srv.Freshness(ctx, &pb.FreshnessRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(f.Expired, want) {
+		t.Errorf("freshness expired = %q, want %q", f.Expired, want)
+	}
+
+	v, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range want {
+		if !slices.Contains(v.Warnings, w) {
+			t.Errorf("verify warnings = %q, want %q", v.Warnings, w)
+		}
+	}
+
+	d, err := srv.Diagnose(ctx, &pb.DiagnoseRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int32
+	for _, diag := range d.Diagnostics {
+		if diag.Code == "expired" {
+			lines = append(lines, diag.Line)
+		}
+	}
+	if !slices.Equal(lines, []int32{4, 10}) {
+		t.Errorf("expired diagnostics on lines %v, want 4 and 10", lines)
+	}
+}
+
+func TestVerifySeverity(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	dir := t.TempDir()
+	content := `holon test/severity
+
+require (
+    severity.test/a v1.0.0-rc.1
+    severity.test/b v1.0.0
+)
+
+replace (
+    severity.test/b => ../b
+)
+`
+	if err := os.WriteFile(filepath.Join(dir, "holon.mod"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := &modfile.SumFile{}
+	if err := sum.Write(filepath.Join(dir, "holon.sum")); err != nil {
+		t.Fatal(err)
+	}
+
+	byCode := func(v *pb.VerifyResponse, severity pb.DiagnosticSeverity) []string {
+		var got []string
+		for _, f := range v.Findings {
+			if f.Severity == severity {
+				got = append(got, f.Code)
+			}
+		}
+		return got
+	}
+	warnings := []string{"active-replace", "prerelease"}
+
+	v, err := srv.Verify(ctx, &pb.VerifyRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Ok || len(v.Errors) != 0 || len(v.Warnings) != 2 {
+		t.Errorf("verify = ok %v, errors %q, warnings %q; want ok with 2 warnings", v.Ok, v.Errors, v.Warnings)
+	}
+	if got := byCode(v, pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_WARNING); !slices.Equal(got, warnings) {
+		t.Errorf("warning codes = %q, want %q", got, warnings)
+	}
+
+	// --strict escalates them.
+	v, err = srv.Verify(ctx, &pb.VerifyRequest{Directory: dir, Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Ok || len(v.Errors) != 2 || len(v.Warnings) != 0 {
+		t.Errorf("verify --strict = ok %v, errors %q, warnings %q; want 2 errors", v.Ok, v.Errors, v.Warnings)
+	}
+	if got := byCode(v, pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_ERROR); !slices.Equal(got, warnings) {
+		t.Errorf("error codes = %q, want %q", got, warnings)
+	}
+
+	// An integrity problem is an error either way.
+	sum.Set("severity.test/c", "v1.0.0", "h1:deadbeef")
+	if err := sum.Write(filepath.Join(dir, "holon.sum")); err != nil {
+		t.Fatal(err)
+	}
+	v, err = srv.Verify(ctx, &pb.VerifyRequest{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := byCode(v, pb.DiagnosticSeverity_DIAGNOSTIC_SEVERITY_ERROR); v.Ok || !slices.Equal(got, []string{"not-cached"}) {
+		t.Errorf("verify = ok %v, error codes %q; want not-cached", v.Ok, got)
 	}
 }
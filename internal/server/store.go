@@ -0,0 +1,217 @@
+package server
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CacheStore keeps the dependency snapshots of the global cache, each a
+// tree of files identified by path@version. Handlers only read snapshots
+// from the local directory Get returns, so a backend keeping them
+// elsewhere (e.g. as zips in an object store) materializes them there.
+type CacheStore interface {
+	// Get returns a local directory holding path@version. It fails with
+	// an error matching fs.ErrNotExist when the snapshot is not stored,
+	// and fs.ErrInvalid when path@version cannot name one.
+	Get(path, version string) (string, error)
+	// Put stores the tree in src as path@version. src is consumed: it may
+	// be moved into the store.
+	Put(path, version, src string) error
+	// Stat describes path@version, failing as Get does.
+	Stat(path, version string) (CacheEntry, error)
+	// List returns every snapshot, sorted by path and version.
+	List() ([]CacheEntry, error)
+	// Delete removes path@version. Deleting a missing snapshot is not an
+	// error.
+	Delete(path, version string) error
+	// Clear removes every snapshot.
+	Clear() error
+}
+
+// cacheStore returns the CacheStore behind the global cache: the cache
+// directory, in front of the shared cache named by ATLAS_CACHE_REMOTE if
+// set.
+func cacheStore() CacheStore {
+	uri := os.Getenv(remoteCacheEnv)
+	if uri == "" {
+		return fsStore{}
+	}
+	remote, err := newRemoteStore(uri)
+	if err != nil {
+		return errStore{err}
+	}
+	return tieredStore{remote: remote}
+}
+
+// inCache reports whether path@version is in the cache.
+func inCache(path, version string) bool {
+	_, err := cacheStore().Get(path, version)
+	return err == nil
+}
+
+// CachedDir returns the local directory of the cached snapshot of
+// path@version, as CacheStore.Get does.
+func CachedDir(path, version string) (string, error) {
+	return cacheStore().Get(path, version)
+}
+
+// fsStore keeps snapshots as directories under CacheDir, at
+// <cache>/<dep-path>@<version>/.
+type fsStore struct{}
+
+func (fsStore) Get(path, version string) (string, error) {
+	if !validSnapshot(path, version) {
+		return "", &fs.PathError{Op: "get", Path: path + "@" + version, Err: fs.ErrInvalid}
+	}
+	dir := CachePath(path, version)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", &fs.PathError{Op: "get", Path: dir, Err: fs.ErrNotExist}
+	}
+	return dir, nil
+}
+
+func (st fsStore) Put(path, version, src string) error {
+	if !validSnapshot(path, version) {
+		return &fs.PathError{Op: "put", Path: path + "@" + version, Err: fs.ErrInvalid}
+	}
+	dst := CachePath(path, version)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+	return os.Rename(src, dst)
+}
+
+func (st fsStore) Stat(path, version string) (CacheEntry, error) {
+	dir, err := st.Get(path, version)
+	if err != nil {
+		return CacheEntry{}, err
+	}
+	size, err := dirSize(dir)
+	if err != nil {
+		return CacheEntry{}, err
+	}
+	return CacheEntry{Path: path, Version: version, Dir: dir, Size: size}, nil
+}
+
+func (fsStore) List() ([]CacheEntry, error) {
+	return listCacheDir(CacheDir())
+}
+
+func (st fsStore) Delete(path, version string) error {
+	dir, err := st.Get(path, version)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+func (fsStore) Clear() error {
+	return os.RemoveAll(CacheDir())
+}
+
+// validSnapshot reports whether path@version names a snapshot inside the
+// cache, rather than e.g. "../x".
+func validSnapshot(path, version string) bool {
+	return strings.HasPrefix(CachePath(path, version), CacheDir()+string(filepath.Separator))
+}
+
+// errStore fails every operation, for a misconfigured CacheStore.
+type errStore struct{ err error }
+
+func (e errStore) Get(string, string) (string, error)      { return "", e.err }
+func (e errStore) Put(string, string, string) error        { return e.err }
+func (e errStore) Stat(string, string) (CacheEntry, error) { return CacheEntry{}, e.err }
+func (e errStore) List() ([]CacheEntry, error)             { return nil, e.err }
+func (e errStore) Delete(string, string) error             { return e.err }
+func (e errStore) Clear() error                            { return e.err }
+
+// CacheEntry is one dependency snapshot in the global cache.
+type CacheEntry struct {
+	Path    string // e.g. "github.com/org/dep"
+	Version string // e.g. "v1.2.0"
+	Dir     string // absolute directory of the snapshot
+	Size    int64  // total size of its files, in bytes
+}
+
+// ListCache returns every entry of the global cache, sorted by path and
+// version.
+func ListCache() ([]CacheEntry, error) {
+	return cacheStore().List()
+}
+
+// listCacheDir lists the snapshots under root. A missing root yields no
+// entries.
+func listCacheDir(root string) ([]CacheEntry, error) {
+	var entries []CacheEntry
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() || path == root {
+			return nil
+		}
+
+		// Snapshots live at <cache>/<dep-path>@<version>/
+		if !strings.Contains(d.Name(), "@") {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		at := strings.LastIndex(rel, "@")
+		size, err := dirSize(path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, CacheEntry{
+			Path:    rel[:at],
+			Version: rel[at+1:],
+			Dir:     path,
+			Size:    size,
+		})
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].Version < entries[j].Version
+	})
+	return entries, nil
+}
+
+// dirSize sums the sizes of all regular files under dir.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
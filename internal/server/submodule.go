@@ -0,0 +1,165 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// submoduleHostsEnv lists, comma-separated, the hosts the git submodules
+// of fetched holons may be fetched from, or "*" for any, local URLs
+// included. Unset, a holon with submodules fails to fetch rather than
+// being cached with their directories empty.
+const submoduleHostsEnv = "ATLAS_SUBMODULE_HOSTS"
+
+// maxSubmoduleDepth bounds how deep submodules nest.
+const maxSubmoduleDepth = 8
+
+// submodule is a submodule declared by .gitmodules, at the commit its
+// superproject pins.
+type submodule struct {
+	name, path, url, commit string
+}
+
+// fetchSubmodules fetches into repo, then checks out into dst, the
+// submodules that dst/.gitmodules declares, at the commits treeish of repo
+// pins, and theirs in turn. Relative submodule URLs are resolved against
+// superURL, the repository treeish was fetched from.
+func fetchSubmodules(ctx context.Context, repo, treeish, superURL, dst string, depth int) error {
+	if _, err := os.Stat(filepath.Join(dst, ".gitmodules")); errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if depth == maxSubmoduleDepth {
+		return fmt.Errorf("submodules nested more than %d deep", maxSubmoduleDepth)
+	}
+	subs, err := readSubmodules(ctx, repo, treeish, superURL, dst)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if err := allowSubmodule(sub.url); err != nil {
+			return fmt.Errorf("submodule %s: %w", sub.path, err)
+		}
+		if err := fetchCommit(ctx, repo, sub.url, sub.commit); err != nil {
+			return fmt.Errorf("submodule %s: fetch %s from %s: %w", sub.path, sub.commit, sub.url, err)
+		}
+		subDst := filepath.Join(dst, filepath.FromSlash(sub.path))
+		if err := os.MkdirAll(subDst, 0o755); err != nil {
+			return err
+		}
+		if err := runGit(ctx, repo, "--work-tree="+subDst, "checkout", "--quiet", "--force", sub.commit, "--", "."); err != nil {
+			return fmt.Errorf("submodule %s: %w", sub.path, err)
+		}
+		if err := fetchSubmodules(ctx, repo, sub.commit, sub.url, subDst, depth+1); err != nil {
+			return fmt.Errorf("submodule %s: %w", sub.path, err)
+		}
+	}
+	return nil
+}
+
+// readSubmodules returns the submodules dst/.gitmodules declares that
+// treeish of repo pins a commit of. A declared path holding anything else
+// is left alone, as git does.
+func readSubmodules(ctx context.Context, repo, treeish, superURL, dst string) ([]submodule, error) {
+	out, err := gitOutput(ctx, "", "config", "--file", filepath.Join(dst, ".gitmodules"), "--get-regexp", `^submodule\..*\.(path|url)$`)
+	if err != nil {
+		return nil, fmt.Errorf("read .gitmodules: %w", err)
+	}
+	byName := map[string]*submodule{}
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		// Format: submodule.<name>.<field>, the name possibly dotted
+		rest := strings.TrimPrefix(key, "submodule.")
+		i := strings.LastIndex(rest, ".")
+		name, field := rest[:i], rest[i+1:]
+		if byName[name] == nil {
+			byName[name] = &submodule{name: name}
+			names = append(names, name)
+		}
+		if field == "path" {
+			byName[name].path = value
+		} else {
+			byName[name].url = submoduleURL(superURL, value)
+		}
+	}
+
+	var subs []submodule
+	for _, name := range names {
+		sub := byName[name]
+		if sub.path == "" || sub.url == "" {
+			return nil, fmt.Errorf(".gitmodules: submodule %q needs a path and a url", name)
+		}
+		if !filepath.IsLocal(filepath.FromSlash(sub.path)) {
+			return nil, fmt.Errorf(".gitmodules: submodule %q: path %q leaves the holon", name, sub.path)
+		}
+		// Format: <mode> <type> <object>\t<path>
+		entry, err := gitOutput(ctx, repo, "ls-tree", treeish, "--", sub.path)
+		if err != nil {
+			return nil, err
+		}
+		if f := strings.Fields(entry); len(f) >= 3 && f[1] == "commit" {
+			sub.commit = f[2]
+			subs = append(subs, *sub)
+		}
+	}
+	return subs, nil
+}
+
+// submoduleURL resolves the URL of a submodule, relative ("./" or "../")
+// to that of its superproject as git does.
+func submoduleURL(superURL, subURL string) string {
+	if !strings.HasPrefix(subURL, "./") && !strings.HasPrefix(subURL, "../") {
+		return subURL
+	}
+	superURL = strings.TrimSuffix(superURL, "/")
+	if u, err := url.Parse(superURL); err == nil && u.Scheme != "" {
+		u.Path = path.Join(u.Path, subURL)
+		return u.String()
+	}
+	if host := urlHost(superURL); host != "" {
+		prefix, repoPath, _ := strings.Cut(superURL, ":")
+		return prefix + ":" + path.Join(repoPath, subURL)
+	}
+	return filepath.Join(superURL, filepath.FromSlash(subURL))
+}
+
+// allowSubmodule fails unless ATLAS_SUBMODULE_HOSTS lets submodules be
+// fetched from gitURL.
+func allowSubmodule(gitURL string) error {
+	hosts := strings.TrimSpace(os.Getenv(submoduleHostsEnv))
+	if hosts == "" {
+		return fmt.Errorf("git submodules are not fetched: set %s to the hosts they may come from, %s among them", submoduleHostsEnv, cmp.Or(urlHost(gitURL), "*"))
+	}
+	host := urlHost(gitURL)
+	for _, h := range strings.Split(hosts, ",") {
+		if h = strings.TrimSpace(h); h == "*" || (host != "" && strings.EqualFold(h, host)) {
+			return nil
+		}
+	}
+	if host == "" {
+		return fmt.Errorf("%s is local, which only %s=* allows", gitURL, submoduleHostsEnv)
+	}
+	return fmt.Errorf("%s is not listed in %s", host, submoduleHostsEnv)
+}
+
+// fetchCommit shallow-fetches commit from gitURL into repo.
+func fetchCommit(ctx context.Context, repo, gitURL, commit string) error {
+	limit, done, err := gitLimitConfig(ctx, gitURL)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return runRemoteGit(ctx, repo, gitURL, append(limit, "fetch", "--quiet", "--depth=1", gitURL, commit)...)
+}
@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SumPrune drops the holon.sum entries of versions that are no longer
+// reachable from holon.mod: neither required directly, nor selected in its
+// build list or holon.lock. The build list is read from the cached or
+// vendored holon.mod of each dependency, so every selected version must be
+// there for its own requirements to be known.
+func (s *Server) SumPrune(_ context.Context, req *pb.SumPruneRequest) (_ *pb.SumPruneResponse, err error) {
+	defer s.record("SumPrune", req.Directory, &err)
+
+	dir := holonDir(req.Directory)
+	defer s.journal(dir, "SumPrune", &err)()
+
+	mod, err := s.parseMod(filepath.Join(dir, "holon.mod"))
+	if err != nil {
+		return nil, modError(filepath.Join(dir, "holon.mod"), err)
+	}
+	sumPath := filepath.Join(dir, "holon.sum")
+	sum, err := s.parseSum(sumPath)
+	if err != nil {
+		return nil, sumError(sumPath, err)
+	}
+
+	deps, err := s.sumReachable(dir, mod)
+	if err != nil {
+		return nil, err
+	}
+	reachable := map[string]bool{}
+	for _, dep := range deps {
+		reachable[dep.Path+"@"+dep.Version] = true
+	}
+
+	keep := func(e modfile.SumEntry) bool {
+		version := strings.TrimSuffix(strings.TrimSuffix(e.Version, "/HOLON.md"), commitSuffix)
+		return reachable[e.Path+"@"+version]
+	}
+
+	var removed []modfile.SumEntry
+	if req.DryRun {
+		removed = sum.Retain(keep)
+	} else {
+		err := s.updateSum(sumPath, func(sum *modfile.SumFile) bool {
+			removed = sum.Retain(keep)
+			return len(removed) > 0
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "update holon.sum: %v", err)
+		}
+	}
+
+	resp := &pb.SumPruneResponse{}
+	for _, e := range removed {
+		resp.Removed = append(resp.Removed, &pb.SumEntry{Path: e.Path, Version: e.Version, Hash: e.Hash})
+	}
+	return resp, nil
+}
+
+// sumReachable returns the versions whose holon.sum entries the holon in
+// dir, of holon.mod mod, keeps: those mod requires, by path and by source
+// path, and, by source path, those of its build list and those holon.lock
+// selects. Optional dependencies not pulled add none of their
+// requirements. Every dependency of the build list must be cached or
+// vendored, as its own requirements are unknown otherwise.
+func (s *Server) sumReachable(dir string, mod *modfile.ModFile) ([]modfile.Require, error) {
+	lock, err := modfile.ParseLock(filepath.Join(dir, "holon.lock"))
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "parse holon.lock: %v", err)
+	}
+	var deps []modfile.Require
+	seen := map[string]bool{}
+	add := func(path, version string) {
+		if key := path + "@" + version; !seen[key] {
+			seen[key] = true
+			deps = append(deps, modfile.Require{Path: path, Version: version})
+		}
+	}
+	for _, dep := range mod.Require {
+		add(dep.Path, dep.Version)
+		add(mod.SourcePath(dep.Path), dep.Version)
+	}
+	// Optional dependencies not pulled add none of their requirements.
+	list := s.buildList(dir, mod, func(r modfile.Require) bool {
+		return !r.Optional || inCache(mod.SourcePath(r.Path), r.Version)
+	})
+	var missing []string
+	for _, sel := range list.Selected {
+		if _, err := os.Stat(dependencyDir(dir, mod, sel.Path, sel.Version)); err != nil {
+			missing = append(missing, sel.Path+"@"+sel.Version)
+		}
+		add(mod.SourcePath(sel.Path), sel.Version)
+	}
+	if len(missing) > 0 {
+		return nil, notCachedError(missing)
+	}
+	for _, sel := range lock.Selected {
+		add(mod.SourcePath(sel.Path), sel.Version)
+	}
+	return deps, nil
+}
+
+// SumMigrate records every holon.sum entry with the algorithm req.To as
+// well, hashing the cached snapshots. Each snapshot must still match the
+// entry it is migrated from. Older entries are kept, for tools that do not
+// know req.To yet, unless req.DropOld is set.
+func (s *Server) SumMigrate(_ context.Context, req *pb.SumMigrateRequest) (_ *pb.SumMigrateResponse, err error) {
+	defer s.record("SumMigrate", req.Directory, &err)
+
+	dir := holonDir(req.Directory)
+	to := strings.TrimSuffix(req.To, ":")
+	if _, ok := hashers[to]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown hash algorithm %q", req.To)
+	}
+	defer s.journal(dir, "SumMigrate", &err)()
+
+	sumPath := filepath.Join(dir, "holon.sum")
+	var added, removed []modfile.SumEntry
+	var migrateErr error
+	migrate := func(sum *modfile.SumFile) bool {
+		added, removed, migrateErr = migrateSum(sum, to, req.DropOld)
+		return migrateErr == nil && len(added)+len(removed) > 0
+	}
+	if req.DryRun {
+		sum, err := s.parseSum(sumPath)
+		if err != nil {
+			return nil, sumError(sumPath, err)
+		}
+		migrate(sum.Clone())
+	} else if err := s.updateSum(sumPath, migrate); err != nil && migrateErr == nil {
+		return nil, status.Errorf(codes.Internal, "update holon.sum: %v", err)
+	}
+	if migrateErr != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", migrateErr)
+	}
+
+	resp := &pb.SumMigrateResponse{}
+	for _, e := range added {
+		resp.Added = append(resp.Added, &pb.SumEntry{Path: e.Path, Version: e.Version, Hash: e.Hash})
+	}
+	for _, e := range removed {
+		resp.Removed = append(resp.Removed, &pb.SumEntry{Path: e.Path, Version: e.Version, Hash: e.Hash})
+	}
+	return resp, nil
+}
+
+// migrateSum adds to sum the hash with algorithm to of every entry lacking
+// one and, with dropOld, then removes the entries of other algorithms.
+func migrateSum(sum *modfile.SumFile, to string, dropOld bool) (added, removed []modfile.SumEntry, err error) {
+	for _, e := range slices.Clone(sum.Entries) {
+		if sum.LookupAlgorithm(e.Path, e.Version, to) != "" || strings.HasSuffix(e.Version, commitSuffix) {
+			continue
+		}
+		version, isHolonMD := strings.CutSuffix(e.Version, "/HOLON.md")
+		dir, err := cacheStore().Get(e.Path, version)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s %s: not in cache (run atlas pull first)", e.Path, e.Version)
+		}
+		hash := func(alg string) (string, error) {
+			if isHolonMD {
+				return sumHashFile(alg, filepath.Join(dir, "HOLON.md"))
+			}
+			return sumHashDir(alg, dir)
+		}
+
+		if old, err := hash(hashAlgorithm(e.Hash)); err != nil || old != e.Hash {
+			return nil, nil, fmt.Errorf("%s %s: cache does not match holon.sum, not migrating it", e.Path, e.Version)
+		}
+		migrated, err := hash(to)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s %s: %w", e.Path, e.Version, err)
+		}
+		sum.Set(e.Path, e.Version, migrated)
+		added = append(added, modfile.SumEntry{Path: e.Path, Version: e.Version, Hash: migrated})
+	}
+	if dropOld {
+		removed = sum.Retain(func(e modfile.SumEntry) bool {
+			return hashAlgorithm(e.Hash) == to || strings.HasSuffix(e.Version, commitSuffix)
+		})
+	}
+	return added, removed, nil
+}
+
+// SumMerge writes the union of two holon.sum files to req.Output, or over
+// req.Ours when no output is given. Entries recorded with different hashes
+// on each side are reported as conflicts and nothing is written. It is
+// meant to run as a git merge driver.
+func (s *Server) SumMerge(_ context.Context, req *pb.SumMergeRequest) (_ *pb.SumMergeResponse, err error) {
+	defer s.record("SumMerge", filepath.Dir(req.Ours), &err)
+
+	if req.Ours == "" || req.Theirs == "" {
+		return nil, status.Error(codes.InvalidArgument, "ours and theirs are required")
+	}
+	ours, err := modfile.ParseSum(req.Ours)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "parse %s: %v", req.Ours, err)
+	}
+	theirs, err := modfile.ParseSum(req.Theirs)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "parse %s: %v", req.Theirs, err)
+	}
+
+	merged, conflicts := modfile.MergeSum(ours, theirs)
+	if len(conflicts) > 0 {
+		resp := &pb.SumMergeResponse{}
+		for _, c := range conflicts {
+			resp.Conflicts = append(resp.Conflicts, &pb.SumConflict{
+				Path:    c.Path,
+				Version: c.Version,
+				Ours:    c.Ours,
+				Theirs:  c.Theirs,
+			})
+		}
+		return resp, nil
+	}
+
+	output := req.Output
+	if output == "" {
+		output = req.Ours
+	}
+	if err := merged.Write(output); err != nil {
+		return nil, status.Errorf(codes.Internal, "write %s: %v", output, err)
+	}
+	return &pb.SumMergeResponse{Output: output, Entries: int32(len(merged.Entries))}, nil
+}
+
+// ModMerge merges two holon.mod files structurally (see modfile.MergeMod)
+// and writes the result to req.Output, or over req.Ours when no output is
+// given. On a conflict nothing is written. It is meant to run as a git
+// merge driver.
+func (s *Server) ModMerge(_ context.Context, req *pb.ModMergeRequest) (_ *pb.ModMergeResponse, err error) {
+	defer s.record("ModMerge", filepath.Dir(req.Ours), &err)
+
+	if req.Ours == "" || req.Theirs == "" {
+		return nil, status.Error(codes.InvalidArgument, "ours and theirs are required")
+	}
+	var files [3]*modfile.ModFile
+	for i, path := range []string{req.Base, req.Ours, req.Theirs} {
+		if path == "" {
+			continue
+		}
+		if files[i], err = modfile.Parse(path); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "parse %s: %v", path, err)
+		}
+	}
+
+	merged, err := modfile.MergeMod(files[0], files[1], files[2])
+	if err != nil {
+		return &pb.ModMergeResponse{Conflict: err.Error()}, nil
+	}
+
+	output := req.Output
+	if output == "" {
+		output = req.Ours
+	}
+	if err := merged.Write(output); err != nil {
+		return nil, status.Errorf(codes.Internal, "write %s: %v", output, err)
+	}
+	return &pb.ModMergeResponse{Output: output}, nil
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/holonmd"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// templateMarker matches the front-matter line of HOLON.md that makes a
+// holon a template; the holons created from it do not carry it.
+var templateMarker = regexp.MustCompile(`(?m)^template:[ \t]*true[ \t]*\r?\n`)
+
+// New creates a holon in req.Directory from the files of a template
+// holon, fetched through the cache like any dependency. "{{holon_path}}",
+// "{{name}}", "{{author}}" and "{{<key>}}" for each of req.Vars are
+// substituted in file names and text files; other "{{...}}" are left as
+// they are. The holon.mod of the template, if any, is rewritten with the
+// new holon path.
+func (s *Server) New(ctx context.Context, req *pb.NewRequest) (_ *pb.NewResponse, err error) {
+	defer s.record("New", req.Directory, &err)
+	ctx = s.withEvents(ctx)
+
+	if req.Template == "" || req.HolonPath == "" {
+		return nil, status.Error(codes.InvalidArgument, "template and holon_path are required")
+	}
+	dir := req.Directory
+	if dir == "" {
+		dir = path.Base(req.HolonPath)
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, status.Errorf(codes.AlreadyExists, "%s is not empty", dir)
+	}
+
+	tmplPath, version, _ := strings.Cut(req.Template, "@")
+	if version == "" {
+		tags, err := remoteTags(ctx, tmplPath)
+		if err != nil {
+			return nil, status.Errorf(codes.Unavailable, "list versions of %s: %v", tmplPath, err)
+		}
+		if version = latestTag(tags); version == "" {
+			return nil, status.Errorf(codes.NotFound, "%s has no release", tmplPath)
+		}
+	}
+	src, err := fetchToCache(ctx, tmplPath, version)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "fetch %s@%s: %v", tmplPath, version, err)
+	}
+	fm, err := holonmd.Parse(filepath.Join(src, "HOLON.md"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s@%s: parse HOLON.md: %v", tmplPath, version, err)
+	}
+	if fm == nil || fm.Fields["template"] != "true" {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s@%s is not a template: its HOLON.md does not declare template: true", tmplPath, version)
+	}
+
+	vars := templateVars(req)
+	files, err := instantiate(src, dir, vars)
+	if err != nil {
+		os.RemoveAll(dir) //nolint:errcheck
+		return nil, status.Errorf(codes.Internal, "instantiate %s@%s: %v", tmplPath, version, err)
+	}
+
+	modPath := filepath.Join(dir, "holon.mod")
+	if mod, err := s.parseMod(modPath); err == nil {
+		mod.HolonPath = req.HolonPath
+		if err := s.writeMod(mod, modPath); err != nil {
+			return nil, status.Errorf(codes.Internal, "write holon.mod: %v", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s@%s: parse holon.mod: %v", tmplPath, version, err)
+	}
+
+	return &pb.NewResponse{Template: tmplPath, Version: version, Files: files}, nil
+}
+
+// templateVars returns the substitutions of req, keyed by placeholder.
+func templateVars(req *pb.NewRequest) map[string]string {
+	name := req.Name
+	if name == "" {
+		name = path.Base(req.HolonPath)
+	}
+	author := req.Author
+	if author == "" {
+		author, _ = git(".", "config", "user.name")
+	}
+
+	vars := map[string]string{}
+	for k, v := range req.Vars {
+		vars["{{"+k+"}}"] = v
+	}
+	vars["{{holon_path}}"] = req.HolonPath
+	vars["{{name}}"] = name
+	vars["{{author}}"] = author
+	return vars
+}
+
+// instantiate copies the template at src into dst, substituting vars, and
+// returns the files created, relative to dst. Files that are not valid
+// UTF-8 are copied as they are.
+func instantiate(src, dst string, vars map[string]string) ([]string, error) {
+	pairs := make([]string, 0, 2*len(vars))
+	for k, v := range vars {
+		pairs = append(pairs, k, v)
+	}
+	r := strings.NewReplacer(pairs...)
+
+	var files []string
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(src, p)
+		target := filepath.Join(dst, r.Replace(rel))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if utf8.Valid(data) {
+			data = []byte(r.Replace(string(data)))
+		}
+		if rel == "HOLON.md" {
+			data = templateMarker.ReplaceAll(data, nil)
+		}
+		if err := os.WriteFile(target, data, info.Mode().Perm()); err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(r.Replace(rel)))
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Tidy drops the requires of holon.mod that nothing references (see
+// unusedRequires), unless req.KeepRequires, then brings holon.sum to the
+// dependencies still reachable, as go mod tidy does: the entries of those
+// no longer are removed, keeping those of the build list and holon.lock as
+// SumPrune does, and those missing are added for the dependencies cached,
+// hashing their snapshots. Every reachable dependency must be cached so
+// that its own requirements are known.
+func (s *Server) Tidy(_ context.Context, req *pb.TidyRequest) (_ *pb.TidyResponse, err error) {
+	defer s.record("Tidy", req.Directory, &err)
+
+	dir := holonDir(req.Directory)
+	defer s.journal(dir, "Tidy", &err)()
+
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	sumPath := filepath.Join(dir, "holon.sum")
+	sum, err := s.parseSum(sumPath)
+	if err != nil {
+		return nil, sumError(sumPath, err)
+	}
+	deps, err := s.closure(mod)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+
+	resp := &pb.TidyResponse{Root: dir}
+	tidied := mod.Clone()
+	if !req.KeepRequires {
+		unused, err := s.unusedRequires(dir, mod, deps)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "scan %s: %v", dir, err)
+		}
+		for _, dep := range unused {
+			tidied.RemoveRequire(dep.Path)
+			resp.Dropped = append(resp.Dropped, &pb.Dependency{Path: dep.Path, Version: dep.Version, Alias: dep.Alias, Group: dep.Group})
+		}
+	}
+
+	reachable, err := s.sumReachable(dir, tidied)
+	if err != nil {
+		return nil, err
+	}
+
+	var added, removed []modfile.SumEntry
+	var tidyErr error
+	tidy := func(sum *modfile.SumFile) bool {
+		added, removed, tidyErr = tidySum(sum, reachable)
+		return tidyErr == nil && len(added)+len(removed) > 0
+	}
+	if req.DryRun {
+		tidy(sum.Clone())
+	} else {
+		if len(resp.Dropped) > 0 {
+			if err := s.writeMod(tidied, modPath); err != nil {
+				return nil, status.Errorf(codes.Internal, "write holon.mod: %v", err)
+			}
+		}
+		if err := s.updateSum(sumPath, tidy); err != nil && tidyErr == nil {
+			return nil, status.Errorf(codes.Internal, "update holon.sum: %v", err)
+		}
+	}
+	if tidyErr != nil {
+		return nil, status.Errorf(codes.Internal, "%v", tidyErr)
+	}
+
+	for _, e := range removed {
+		resp.Removed = append(resp.Removed, &pb.SumEntry{Path: e.Path, Version: e.Version, Hash: e.Hash})
+	}
+	for _, e := range added {
+		resp.Added = append(resp.Added, &pb.SumEntry{Path: e.Path, Version: e.Version, Hash: e.Hash})
+	}
+	return resp, nil
+}
+
+// tidySum removes from sum the entries of versions not in reachable (see
+// Server.sumReachable), and records the hashes of the snapshots of those it
+// lacks, with the algorithms it already uses.
+func tidySum(sum *modfile.SumFile, reachable []modfile.Require) (added, removed []modfile.SumEntry, err error) {
+	algs := sumAlgorithms(sum)
+	keep := map[string]bool{}
+	for _, dep := range reachable {
+		keep[dep.Path+"@"+dep.Version] = true
+	}
+	removed = sum.Retain(func(e modfile.SumEntry) bool {
+		version := strings.TrimSuffix(strings.TrimSuffix(e.Version, "/HOLON.md"), commitSuffix)
+		return keep[e.Path+"@"+version]
+	})
+
+	before := slices.Clone(sum.Entries)
+	for _, dep := range reachable {
+		if sum.Lookup(dep.Path, dep.Version) != "" {
+			continue
+		}
+		cachePath, err := cacheStore().Get(dep.Path, dep.Version)
+		if err != nil {
+			continue // optional not pulled, or not by source path
+		}
+		if err := setSnapshotHashes(sum, algs, dep.Path, dep.Version, cachePath); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, e := range sum.Entries {
+		if !slices.Contains(before, e) {
+			added = append(added, e)
+		}
+	}
+	return added, removed, nil
+}
+
+// unusedRequires returns the requires of the holon in dir that Tidy
+// drops: those that no dependency of its closure deps requires, that are
+// not annotated tidy=keep, and that no text file of the holon names, by
+// path or by the variable atlas exec sets for them. holon.mod, holon.sum,
+// holon.lock, the vendor directory and nested holons are not searched.
+func (s *Server) unusedRequires(dir string, mod *modfile.ModFile, deps []modfile.Require) ([]modfile.Require, error) {
+	required := map[string]bool{}
+	for _, dep := range deps {
+		cachePath, err := cacheStore().Get(dep.Path, dep.Version)
+		if err != nil {
+			continue
+		}
+		if sub, err := s.parseMod(filepath.Join(cachePath, "holon.mod")); err == nil {
+			for _, r := range sub.Require {
+				required[r.Path] = true
+			}
+		}
+	}
+	var candidates []modfile.Require
+	for _, r := range mod.Require {
+		if r.Meta["tidy"] != "keep" && !required[r.Path] && !required[mod.SourcePath(r.Path)] {
+			candidates = append(candidates, r)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	vendorDir := filepath.Join(dir, cmp.Or(mod.VendorDir, modfile.DefaultVendorDir))
+	referenced := map[string]bool{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			if d.Name() == ".git" || d.Name() == ".holon" || path == vendorDir {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "holon.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch rel, _ := filepath.Rel(dir, path); rel {
+		case "holon.mod", "holon.sum", "holon.lock":
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if isBinary(data) {
+			return nil
+		}
+		for _, r := range candidates {
+			if bytes.Contains(data, []byte(r.Path)) || bytes.Contains(data, []byte(envVariable(cmp.Or(r.Alias, r.Path)))) {
+				referenced[r.Path] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var unused []modfile.Require
+	for _, r := range candidates {
+		if !referenced[r.Path] {
+			unused = append(unused, r)
+		}
+	}
+	return unused, nil
+}
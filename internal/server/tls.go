@@ -0,0 +1,128 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLSOptions configures TLS for "atlas serve", on both the gRPC listener
+// and the web dashboard. Every setting left empty gets a hardened default
+// rather than the library's.
+type TLSOptions struct {
+	CertFile, KeyFile string
+
+	// MinVersion is "1.2" (the default) or "1.3".
+	MinVersion string
+
+	// CipherSuites names the suites allowed for TLS 1.2, as in
+	// tls.CipherSuites; insecure suites are rejected. TLS 1.3 suites are
+	// not configurable. Empty means Go's secure defaults.
+	CipherSuites []string
+
+	// ClientCAFile holds the CAs client certificates must chain to.
+	ClientCAFile string
+
+	// ClientAuth is "none", "request", "require", "verify-if-given" or
+	// "require-and-verify". It defaults to "require-and-verify" with
+	// ClientCAFile, "none" otherwise.
+	ClientAuth string
+}
+
+// Enabled reports whether any TLS setting is given.
+func (o TLSOptions) Enabled() bool {
+	return o.CertFile != "" || o.KeyFile != "" || o.MinVersion != "" ||
+		len(o.CipherSuites) > 0 || o.ClientCAFile != "" || o.ClientAuth != ""
+}
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify-if-given":    tls.VerifyClientCertIfGiven,
+	"require-and-verify": tls.RequireAndVerifyClientCert,
+}
+
+// Config builds the tls.Config described by o.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	if o.CertFile == "" || o.KeyFile == "" {
+		return nil, fmt.Errorf("tls: both a certificate and a key are required")
+	}
+	cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("tls: %w", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if o.MinVersion != "" {
+		v, ok := tlsVersions[o.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("tls: unsupported minimum version %q (want 1.2 or 1.3)", o.MinVersion)
+		}
+		cfg.MinVersion = v
+	}
+
+	if len(o.CipherSuites) > 0 {
+		if cfg.MinVersion == tls.VersionTLS13 {
+			return nil, fmt.Errorf("tls: cipher suites cannot be configured for TLS 1.3")
+		}
+		ids := map[string]uint16{}
+		for _, s := range tls.CipherSuites() {
+			ids[s.Name] = s.ID
+		}
+		for _, name := range o.CipherSuites {
+			id, ok := ids[name]
+			if !ok {
+				return nil, fmt.Errorf("tls: unknown or insecure cipher suite %q", name)
+			}
+			cfg.CipherSuites = append(cfg.CipherSuites, id)
+		}
+	}
+
+	auth := o.ClientAuth
+	if auth == "" && o.ClientCAFile != "" {
+		auth = "require-and-verify"
+	}
+	if auth != "" {
+		t, ok := clientAuthTypes[auth]
+		if !ok {
+			return nil, fmt.Errorf("tls: unknown client auth %q", auth)
+		}
+		cfg.ClientAuth = t
+	}
+	if o.ClientCAFile != "" {
+		pem, err := os.ReadFile(o.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificates in %s", o.ClientCAFile)
+		}
+		cfg.ClientCAs = pool
+	} else if cfg.ClientAuth == tls.VerifyClientCertIfGiven || cfg.ClientAuth == tls.RequireAndVerifyClientCert {
+		return nil, fmt.Errorf("tls: client auth %q needs a client CA", auth)
+	}
+	return cfg, nil
+}
+
+// ParseCipherSuites splits a comma-separated list of cipher suite names.
+func ParseCipherSuites(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
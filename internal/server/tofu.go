@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// knownHolonsPath returns the file recording, for the machine, what was
+// trusted of each dependency on first use under the tofu directive, one
+// "<path> <version> <hash> <commit> <source>" line per snapshot, "-"
+// standing for an unknown commit or source. The last line of a snapshot
+// wins.
+func knownHolonsPath() string {
+	return filepath.Join(filepath.Dir(CacheDir()), "known_holons")
+}
+
+// knownHolonsMu serializes the appends of the process to knownHolonsPath.
+var knownHolonsMu sync.Mutex
+
+// knownHolon returns what was trusted of depPath@version, or nil.
+func knownHolon(depPath, version string) (*pb.TrustRecord, error) {
+	f, err := os.Open(knownHolonsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var known *pb.TrustRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 5 || fields[0] != depPath || fields[1] != version {
+			continue
+		}
+		known = &pb.TrustRecord{Hash: fields[2], Commit: unknownField(fields[3]), Source: unknownField(fields[4])}
+	}
+	return known, scanner.Err()
+}
+
+func unknownField(field string) string {
+	if field == "-" {
+		return ""
+	}
+	return field
+}
+
+// trustHolon records in knownHolonsPath that depPath@version is trusted
+// as rec.
+func trustHolon(depPath, version string, rec *pb.TrustRecord) error {
+	knownHolonsMu.Lock()
+	defer knownHolonsMu.Unlock()
+	path := knownHolonsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%s %s %s %s %s\n", depPath, version, rec.Hash, cmp.Or(rec.Commit, "-"), cmp.Or(rec.Source, "-"))
+	return cmp.Or(err, f.Close())
+}
+
+// checkTrust checks the snapshot of depPath@version in dir against what
+// was trusted of it on first use. One hashing otherwise fails with
+// HASH_MISMATCH, unless force, which trusts it instead. For one never
+// trusted, the record to trust it as is returned if accept, for the caller
+// to pass to trustHolon once the snapshot is installed; otherwise it fails
+// with TRUST_REQUIRED. A confirmed hash other than "" must be the
+// snapshot's.
+func checkTrust(modPath, depPath, version, dir string, accept, force bool, confirmed string) (*pb.TrustRecord, error) {
+	dep := depPath + "@" + version
+	known, err := knownHolon(depPath, version)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "read %s: %v", knownHolonsPath(), err)
+	}
+	alg := defaultHashAlgorithm
+	if known != nil {
+		alg = hashAlgorithm(known.Hash)
+	}
+	hash, err := sumHashDir(alg, dir)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "hash %s: %v", dep, err)
+	}
+	rec := &pb.TrustRecord{Hash: hash, Commit: fetchedCommit(depPath, version), Source: fetchSource(depPath, version)}
+
+	switch {
+	case known != nil && known.Hash == hash:
+		return nil, nil
+	case known != nil && !force:
+		return nil, hashMismatchError(dep, knownHolonsPath(),
+			"snapshot hashes to %s, but %s was trusted on first use; use --force to trust it instead", hash, known.Hash)
+	case known == nil && !accept:
+		return nil, trustRequiredError(modPath, dep, rec)
+	case confirmed != "" && confirmed != hash:
+		return nil, hashMismatchError(dep, knownHolonsPath(), "snapshot hashes to %s, not %s as confirmed", hash, confirmed)
+	}
+	return rec, nil
+}
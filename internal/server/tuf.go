@@ -0,0 +1,300 @@
+package server
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+	"github.com/organic-programming/rhizome-atlas/pkg/tuf"
+)
+
+// tufKeysEnv names a directory holding the keys a registry or mirror
+// signs its TUF metadata with (see package tuf), Ed25519 seeds of 32 bytes
+// in hex: <role>.key, or <role>-<name>.key for several keys of a role,
+// for the roles root, targets, snapshot and timestamp; retired-<name>.key
+// for root keys replaced, which sign the next root; and <role>.threshold
+// for a role more than one key must sign. Unset, MirrorSync and Release
+// publish no metadata.
+const tufKeysEnv = "ATLAS_TUF_KEYS"
+
+// tufRootEnv names the root.json, from the registry or mirror operator,
+// that a client trusts the metadata of the registry and proxies with.
+// Set, every tag fetched through them must be a target their metadata
+// signs; unset, their metadata is not read.
+const tufRootEnv = "ATLAS_TUF_ROOT"
+
+// tufDir is where a registry or mirror serves its TUF metadata, under its
+// prefix.
+const tufDir = "@tuf"
+
+// tufRefresh bounds how long a client reuses the targets of a prefix
+// before it updates them.
+const tufRefresh = time.Minute
+
+// tufKeys returns the keys of tufKeysEnv, or false if unset.
+func tufKeys() (tuf.Keys, bool, error) {
+	dir := os.Getenv(tufKeysEnv)
+	if dir == "" {
+		return tuf.Keys{}, false, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return tuf.Keys{}, false, fmt.Errorf("%s: %v", tufKeysEnv, err)
+	}
+	keys := tuf.Keys{Roles: map[string][]ed25519.PrivateKey{}, Thresholds: map[string]int{}}
+	for _, e := range entries {
+		name := e.Name()
+		role, isKey := strings.CutSuffix(name, ".key")
+		role, _, _ = strings.Cut(role, "-")
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return tuf.Keys{}, false, fmt.Errorf("%s: %v", tufKeysEnv, err)
+		}
+		switch {
+		case isKey:
+			key, err := tuf.ParseKey(data)
+			if err != nil {
+				return tuf.Keys{}, false, fmt.Errorf("%s: %s: %v", tufKeysEnv, name, err)
+			}
+			if role == "retired" {
+				keys.Retired = append(keys.Retired, key)
+			} else {
+				keys.Roles[role] = append(keys.Roles[role], key)
+			}
+		case strings.HasSuffix(name, ".threshold"):
+			n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+			if err != nil || n < 1 {
+				return tuf.Keys{}, false, fmt.Errorf("%s: %s: want a positive number", tufKeysEnv, name)
+			}
+			keys.Thresholds[strings.TrimSuffix(name, ".threshold")] = n
+		}
+	}
+	return keys, true, nil
+}
+
+// signRepository publishes the TUF metadata of the repositories under dir,
+// a registry or mirror, in dir/@tuf, if tufKeysEnv is set: its targets are
+// the semver tags of every repository, with the commit each names and,
+// for those without submodules, the holon.sum hash of its content.
+func signRepository(ctx context.Context, dir string) error {
+	keys, ok, err := tufKeys()
+	if err != nil || !ok {
+		return err
+	}
+	meta := filepath.Join(dir, tufDir)
+	var last tuf.Targets
+	if data, err := os.ReadFile(filepath.Join(meta, "targets.json")); err == nil {
+		tuf.Peek(data, &last) //nolint:errcheck
+	}
+	repos, err := findRepos(dir)
+	if err != nil {
+		return err
+	}
+	targets := map[string]tuf.Target{}
+	for _, p := range repos {
+		repo := filepath.Join(dir, filepath.FromSlash(p))
+		out, err := gitOutput(ctx, repo, "for-each-ref", "--format=%(refname:short) %(objectname) %(*objectname)", "refs/tags")
+		if err != nil {
+			return fmt.Errorf("list tags of %s: %w", p, err)
+		}
+		for _, line := range strings.Split(out, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			tag, commit := fields[0], fields[len(fields)-1]
+			if _, _, _, ok := semver.Parse(tag); !ok {
+				continue
+			}
+			name := tuf.TargetName(p, tag)
+			if t, ok := last.Targets[name]; ok && t.Commit == commit {
+				targets[name] = t // hashed already
+				continue
+			}
+			target := tuf.Target{Commit: commit}
+			if target.Hash, err = treeHash(ctx, repo, commit); err != nil {
+				return fmt.Errorf("hash %s: %w", name, err)
+			}
+			targets[name] = target
+		}
+	}
+	return tuf.Publish(meta, keys, targets, time.Now())
+}
+
+// treeHash returns the holon.sum hash of the content of commit in repo, as
+// fetched, or "" if it has submodules: their content is not in repo.
+func treeHash(ctx context.Context, repo, commit string) (string, error) {
+	if modules, err := gitOutput(ctx, repo, "ls-tree", "--name-only", commit, ".gitmodules"); err != nil || modules != "" {
+		return "", err
+	}
+	tmp, err := os.MkdirTemp("", "atlas-tuf-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp) //nolint:errcheck
+	tree := filepath.Join(tmp, "tree")
+	cmd, done, err := gitCommand(ctx, "", withGitDir(repo, []string{"--work-tree=" + tree, "checkout", "--quiet", "--force", commit, "--", "."})...)
+	if err != nil {
+		return "", err
+	}
+	defer done()
+	// Keep the index of the repository as it is.
+	cmd.Env = append(cmd.Environ(), "GIT_INDEX_FILE="+filepath.Join(tmp, "index"))
+	cmd.Stderr = os.Stderr
+	if err := os.MkdirAll(tree, 0o755); err != nil {
+		return "", err
+	}
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return sumHashDir(defaultHashAlgorithm, tree)
+}
+
+// tufTargets caches the targets verified per prefix.
+var tufTargets struct {
+	sync.Mutex
+	byPrefix map[string]tufCached
+}
+
+type tufCached struct {
+	targets *tuf.Targets
+	at      time.Time
+}
+
+// signedTargets returns the targets the TUF metadata of prefix, a registry
+// or proxy, signs, verified from the root of tufRootEnv, or nil if unset.
+// What the client trusts of each prefix is kept next to the cache.
+func signedTargets(ctx context.Context, prefix string) (*tuf.Targets, error) {
+	path := os.Getenv(tufRootEnv)
+	if path == "" {
+		return nil, nil
+	}
+	tufTargets.Lock()
+	defer tufTargets.Unlock()
+	key := path + "\x00" + prefix
+	if c, ok := tufTargets.byPrefix[key]; ok && time.Since(c.at) < tufRefresh {
+		return c.targets, nil
+	}
+	root, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", tufRootEnv, err)
+	}
+	sum := sha256.Sum256([]byte(prefix))
+	state := filepath.Join(filepath.Dir(CacheDir()), "tuf", hex.EncodeToString(sum[:8]))
+	targets, err := tuf.Update(state, root, tufFetch(ctx, prefix), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("TUF metadata of %s: %w", prefix, err)
+	}
+	if tufTargets.byPrefix == nil {
+		tufTargets.byPrefix = map[string]tufCached{}
+	}
+	tufTargets.byPrefix[key] = tufCached{targets, time.Now()}
+	return targets, nil
+}
+
+// tufFetch returns the FetchFunc reading the metadata prefix serves in
+// prefix/@tuf, from a directory or over HTTP(S).
+func tufFetch(ctx context.Context, prefix string) tuf.FetchFunc {
+	return func(name string) ([]byte, error) {
+		if dir := localDir(prefix); dir != "" {
+			data, err := os.ReadFile(filepath.Join(dir, tufDir, name))
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("%s: %w", name, tuf.ErrNotFound)
+			}
+			return data, err
+		}
+		if !strings.HasPrefix(prefix, "https://") && !strings.HasPrefix(prefix, "http://") {
+			return nil, fmt.Errorf("%s: TUF metadata is read from a directory or over HTTP(S)", prefix)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, prefix+"/"+tufDir+"/"+name, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, fmt.Errorf("%s: %w", name, tuf.ErrNotFound)
+		case resp.StatusCode != http.StatusOK:
+			return nil, fmt.Errorf("GET %s: %s", req.URL, resp.Status)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	}
+}
+
+// prefixOf returns the registry or proxy prefix gitURL, a URL of depPath,
+// goes through, or "" if it is the upstream repository.
+func (src fetchSources) prefixOf(gitURL, depPath string) string {
+	for _, prefix := range append([]string{src.registry}, src.proxies...) {
+		prefix = strings.TrimRight(strings.TrimSpace(prefix), "/")
+		if prefix != "" && gitURL == prefix+"/"+depPath {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// checkTarget checks that the tag version of depPath, fetched through
+// prefix into dst at commit, is a target the metadata of prefix signs,
+// naming the same commit and content.
+func checkTarget(ctx context.Context, prefix, depPath, version, commit, dst string) error {
+	targets, err := signedTargets(ctx, prefix)
+	if err != nil || targets == nil {
+		return err
+	}
+	target, ok := targets.Targets[tuf.TargetName(depPath, version)]
+	switch {
+	case !ok:
+		return fmt.Errorf("%s@%s is not signed in the TUF targets of %s", depPath, version, prefix)
+	case target.Commit != commit:
+		return fmt.Errorf("%s@%s is commit %s, the TUF targets of %s sign %s", depPath, version, commit, prefix, target.Commit)
+	case target.Hash != "":
+		if hash, err := sumHashDir(hashAlgorithm(target.Hash), dst); err != nil || hash != target.Hash {
+			return fmt.Errorf("%s@%s hashes to %s, the TUF targets of %s sign %s", depPath, version, hash, prefix, target.Hash)
+		}
+	}
+	return nil
+}
+
+// checkSignedArchive checks a snapshot of depPath@version read from a
+// shared cache, in dir, against the hash the TUF metadata of the registry
+// and proxies of the environment sign for it, if any does.
+func checkSignedArchive(depPath, version, dir string) error {
+	if os.Getenv(tufRootEnv) == "" {
+		return nil
+	}
+	src := sourcesFrom(context.Background())
+	for _, prefix := range append([]string{src.registry}, src.proxies...) {
+		prefix = strings.TrimRight(strings.TrimSpace(prefix), "/")
+		if prefix == "" {
+			continue
+		}
+		targets, err := signedTargets(context.Background(), prefix)
+		if err != nil {
+			return err
+		}
+		target, ok := targets.Targets[tuf.TargetName(depPath, version)]
+		if !ok || target.Hash == "" {
+			continue
+		}
+		if hash, err := sumHashDir(hashAlgorithm(target.Hash), dir); err != nil || hash != target.Hash {
+			return fmt.Errorf("%s@%s from the shared cache hashes to %s, the TUF targets of %s sign %s", depPath, version, hash, prefix, target.Hash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"os"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+)
+
+// fileTxn remembers the contents of files before an operation rewrites
+// them, so that a failed operation can put them back. Of a holon.sum, it
+// remembers the entries the operation set instead, since other operations
+// may record theirs in the same file meanwhile (see Server.updateSum).
+type fileTxn struct {
+	paths []string
+	saved map[string][]byte // nil for a file that did not exist
+
+	sumPaths []string
+	sums     map[string]*sumEdits
+}
+
+// sumEdits are the entries a transaction set in a holon.sum.
+type sumEdits struct {
+	set     []modfile.SumEntry
+	was     []string // the hash of the same algorithm each replaced, "" if none
+	created bool     // the file did not exist
+}
+
+// save records the current contents of path. Only the first call for a
+// path counts.
+func (t *fileTxn) save(path string) error {
+	if _, ok := t.saved[path]; ok {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if t.saved == nil {
+		t.saved = map[string][]byte{}
+	}
+	t.paths = append(t.paths, path)
+	t.saved[path] = data
+	return nil
+}
+
+// saveSum records the entries that changed from before to after, the
+// contents of the holon.sum at path before and after the operation edited
+// it. It is called under the lock of Server.updateSum, before after is
+// written.
+func (t *fileTxn) saveSum(path string, before, after *modfile.SumFile) {
+	edits, ok := t.sums[path]
+	if !ok {
+		_, err := os.Stat(path)
+		edits = &sumEdits{created: os.IsNotExist(err)}
+		if t.sums == nil {
+			t.sums = map[string]*sumEdits{}
+		}
+		t.sumPaths = append(t.sumPaths, path)
+		t.sums[path] = edits
+	}
+	for _, e := range after.Entries {
+		alg, _, _ := modfile.SplitHash(e.Hash)
+		if was := before.LookupAlgorithm(e.Path, e.Version, alg); was != e.Hash {
+			edits.set = append(edits.set, e)
+			edits.was = append(edits.was, was)
+		}
+	}
+}
+
+// rollback restores every saved file, removing those that did not exist,
+// and invalidates their parse cache entries. In a holon.sum, only the
+// entries the transaction set and nothing replaced since are restored; a
+// holon.sum it created is removed if that leaves it empty.
+func (t *fileTxn) rollback(s *Server) {
+	for _, path := range t.paths {
+		if data := t.saved[path]; data == nil {
+			os.Remove(path) //nolint:errcheck
+		} else {
+			os.WriteFile(path, data, 0o644) //nolint:errcheck
+		}
+		s.mods.invalidate(path)
+		s.sums.invalidate(path)
+	}
+	for _, path := range t.sumPaths {
+		edits := t.sums[path]
+		s.updateSum(path, func(sum *modfile.SumFile) bool { //nolint:errcheck
+			changed := false
+			for i := len(edits.set) - 1; i >= 0; i-- {
+				e, was := edits.set[i], edits.was[i]
+				alg, _, _ := modfile.SplitHash(e.Hash)
+				if sum.LookupAlgorithm(e.Path, e.Version, alg) != e.Hash {
+					continue // set again since
+				}
+				changed = true
+				if was != "" {
+					sum.Set(e.Path, e.Version, was)
+				} else {
+					sum.Retain(func(other modfile.SumEntry) bool { return other != e })
+				}
+			}
+			if edits.created && len(sum.Entries) == 0 {
+				os.Remove(path) //nolint:errcheck
+				s.sums.invalidate(path)
+				return false
+			}
+			return changed
+		})
+	}
+}
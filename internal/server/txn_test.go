@@ -0,0 +1,64 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+)
+
+// TestTxnRollbackKeepsOtherSumEntries checks that rolling a transaction
+// back only undoes the holon.sum entries it set, not those another
+// operation recorded meanwhile.
+func TestTxnRollbackKeepsOtherSumEntries(t *testing.T) {
+	s := &Server{}
+	path := filepath.Join(t.TempDir(), "holon.sum")
+	set := func(txn *fileTxn, entries ...modfile.SumEntry) {
+		t.Helper()
+		err := s.updateSum(path, func(sum *modfile.SumFile) bool {
+			before := sum.Clone()
+			for _, e := range entries {
+				sum.Set(e.Path, e.Version, e.Hash)
+			}
+			if txn != nil {
+				txn.saveSum(path, before, sum)
+			}
+			return true
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A transaction creating holon.sum removes it again.
+	var txn fileTxn
+	set(&txn, modfile.SumEntry{Path: "example.com/a", Version: "v1.0.0", Hash: "h1:a"})
+	txn.rollback(s)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("holon.sum left behind by a rolled back transaction: %v", err)
+	}
+
+	set(nil, modfile.SumEntry{Path: "example.com/kept", Version: "v1.0.0", Hash: "h1:kept"},
+		modfile.SumEntry{Path: "example.com/b", Version: "v1.0.0", Hash: "h1:old"})
+	txn = fileTxn{}
+	set(&txn, modfile.SumEntry{Path: "example.com/a", Version: "v1.0.0", Hash: "h1:a"},
+		modfile.SumEntry{Path: "example.com/b", Version: "v1.0.0", Hash: "h1:new"})
+	set(nil, modfile.SumEntry{Path: "example.com/other", Version: "v1.0.0", Hash: "h1:other"})
+	txn.rollback(s)
+
+	sum, err := modfile.ParseSum(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ path, want string }{
+		{"example.com/kept", "h1:kept"},
+		{"example.com/b", "h1:old"},
+		{"example.com/other", "h1:other"},
+		{"example.com/a", ""},
+	} {
+		if got := sum.Lookup(c.path, "v1.0.0"); got != c.want {
+			t.Errorf("%s after rollback = %q, want %q", c.path, got, c.want)
+		}
+	}
+}
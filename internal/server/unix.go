@@ -0,0 +1,78 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/organic-programming/rhizome-atlas/internal/npipe"
+
+	"github.com/organic-programming/go-holons/pkg/transport"
+)
+
+// socketMode is the mode of the unix sockets "atlas serve" listens on:
+// only their owner and group may connect, so that who can use a local
+// daemon is decided by file permissions.
+const socketMode = 0o660
+
+// socketPath returns the path of a "unix:///path/to.sock" URI.
+func socketPath(listenURI string) (string, bool) {
+	u, err := url.Parse(listenURI)
+	if err != nil || u.Scheme != "unix" {
+		return "", false
+	}
+	return u.Host + u.Path, true
+}
+
+// ownsTransport reports whether listen opens listenURI itself rather than
+// leaving it to the go-holons transport.
+func ownsTransport(listenURI string) bool {
+	u, err := url.Parse(listenURI)
+	return err == nil && (u.Scheme == "unix" || u.Scheme == "npipe")
+}
+
+// listen opens the listener of listenURI. Unix sockets are created here
+// rather than by the transport: a stale socket left by a server that died
+// is replaced, one still served is not, and the socket gets socketMode.
+// So are Windows named pipes, which the transport does not know.
+func listen(listenURI string) (net.Listener, error) {
+	if strings.HasPrefix(listenURI, "npipe:") {
+		name, err := npipe.Path(listenURI)
+		if err != nil {
+			return nil, err
+		}
+		return npipe.Listen(name)
+	}
+	path, ok := socketPath(listenURI)
+	if !ok {
+		return transport.Listen(listenURI)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("%s: missing socket path", listenURI)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != os.ModeSocket {
+			return nil, fmt.Errorf("%s: exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: another server is listening", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, socketMode); err != nil {
+		lis.Close()
+		return nil, err
+	}
+	return lis, nil
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/pkg/client"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// updateBranchPrefix, followed by <path>@<version>, names the branch of
+// each dependency update; updateBatchBranch that of all of them.
+const (
+	updateBranchPrefix = "atlas/update/"
+	updateBatchBranch  = "atlas/updates"
+)
+
+// branchBase returns what the git work tree of dir has checked out: its
+// branch, or its commit when HEAD is detached. It fails if a tracked file
+// has uncommitted changes, which the branches would otherwise carry.
+func branchBase(dir string) (string, error) {
+	dirty, err := git(dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return "", status.Errorf(codes.FailedPrecondition, "git status: %v", err)
+	}
+	if dirty != "" {
+		return "", detailed(codes.FailedPrecondition, client.ReasonDirtyWorkTree, map[string]string{"file": dir}, nil, "work tree has uncommitted changes")
+	}
+	if branch, err := git(dir, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		return branch, nil
+	}
+	commit, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", status.Errorf(codes.FailedPrecondition, "git rev-parse HEAD: %v", err)
+	}
+	return commit, nil
+}
+
+// updateBranches commits updated to branches forked from base, one per
+// dependency or one for all of them as req.Branching asks, and pushes
+// them with req.Push. Each has holon.mod updated, then pulled so that
+// holon.sum and holon.lock match. base is checked out again at the end,
+// whatever happens.
+func (s *Server) updateBranches(ctx context.Context, dir, base string, updated []*pb.UpdatedDependency, req *pb.UpdateRequest) (_ []*pb.UpdateBranch, err error) {
+	start, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "git rev-parse HEAD: %v", err)
+	}
+	// A file git does not track is left out of the commits, or checking
+	// base out again would delete it.
+	untracked, err := git(dir, "ls-files", "--others", "--", "holon.sum", "holon.lock")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "git ls-files: %v", err)
+	}
+	var files []string
+	for _, name := range []string{"holon.mod", "holon.sum", "holon.lock"} {
+		if !slices.Contains(strings.Fields(untracked), name) {
+			files = append(files, name)
+		}
+	}
+	defer func() {
+		if cerr := s.checkout(dir, base); cerr != nil && err == nil {
+			err = status.Errorf(codes.Internal, "check out %s again: %v", base, cerr)
+		}
+	}()
+
+	groups := [][]*pb.UpdatedDependency{updated}
+	if req.Branching == pb.UpdateBranching_UPDATE_BRANCHING_PER_DEPENDENCY {
+		groups = nil
+		for _, u := range updated {
+			groups = append(groups, []*pb.UpdatedDependency{u})
+		}
+	}
+
+	var branches []*pb.UpdateBranch
+	for _, group := range groups {
+		name := updateBatchBranch
+		if req.Branching == pb.UpdateBranching_UPDATE_BRANCHING_PER_DEPENDENCY {
+			name = updateBranchPrefix + group[0].Path + "@" + group[0].NewVersion
+		}
+		b, err := s.commitUpdates(ctx, dir, start, name, files, group, req.Changelog)
+		if err != nil {
+			return nil, err
+		}
+		if req.Push {
+			if _, err := git(dir, "push", "--quiet", "--force", "origin", name); err != nil {
+				return nil, status.Errorf(codes.Unavailable, "git push %s: %v", name, err)
+			}
+			b.Pushed = true
+		}
+		branches = append(branches, b)
+	}
+	return branches, nil
+}
+
+// commitUpdates checks out the branch name, reset to start, and commits
+// updated to it, with those of files that exist once pulled.
+func (s *Server) commitUpdates(ctx context.Context, dir, start, name string, files []string, updated []*pb.UpdatedDependency, notes bool) (*pb.UpdateBranch, error) {
+	if err := s.checkout(dir, "-B", name, start); err != nil {
+		return nil, status.Errorf(codes.Internal, "create branch %s: %v", name, err)
+	}
+	modPath := filepath.Join(dir, "holon.mod")
+	mod, err := s.parseMod(modPath)
+	if err != nil {
+		return nil, modError(modPath, err)
+	}
+	for _, u := range updated {
+		mod.AddRequire(u.Path, u.NewVersion)
+	}
+	if err := s.writeMod(mod, modPath); err != nil {
+		return nil, status.Errorf(codes.Internal, "write holon.mod: %v", err)
+	}
+	if _, err := s.pull(ctx, &pb.PullRequest{Directory: dir}); err != nil {
+		return nil, err
+	}
+
+	add := []string{"add", "--"}
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+			add = append(add, file)
+		}
+	}
+	if _, err := git(dir, add...); err != nil {
+		return nil, status.Errorf(codes.Internal, "git add: %v", err)
+	}
+	if _, err := git(dir, "commit", "--quiet", "-m", updateCommitMessage(updated, notes)); err != nil {
+		return nil, status.Errorf(codes.Internal, "git commit on %s: %v", name, err)
+	}
+	commit, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "git rev-parse HEAD: %v", err)
+	}
+	return &pb.UpdateBranch{Name: name, Commit: commit, Updated: updated}, nil
+}
+
+// checkout runs git checkout with args in dir, discarding the changes of
+// the work tree, and drops the parsed holon.mod and holon.sum it replaced.
+func (s *Server) checkout(dir string, args ...string) error {
+	defer s.mods.invalidate(filepath.Join(dir, "holon.mod"))
+	defer s.sums.invalidate(filepath.Join(dir, "holon.sum"))
+	_, err := git(dir, append([]string{"checkout", "--quiet", "--force"}, args...)...)
+	return err
+}
+
+// updateCommitMessage describes the commit of updated, with their release
+// notes, when collected, as its body.
+func updateCommitMessage(updated []*pb.UpdatedDependency, notes bool) string {
+	subject := "Update dependencies"
+	if len(updated) == 1 {
+		subject = fmt.Sprintf("Update %s to %s", updated[0].Path, updated[0].NewVersion)
+	}
+	if notes {
+		return subject + "\n\n" + aggregateChangelog(updated)
+	}
+	var body strings.Builder
+	for _, u := range updated {
+		fmt.Fprintf(&body, "- %s: %s → %s\n", u.Path, u.OldVersion, u.NewVersion)
+	}
+	return subject + "\n\n" + body.String()
+}
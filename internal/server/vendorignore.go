@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// vendorIgnoreFile lists, next to holon.mod, the paths Vendor leaves out
+// of every vendored dependency: one glob per line, as in path.Match, with
+// blank lines and lines starting with # ignored. A pattern containing a
+// slash matches paths from the root of the dependency, one without matches
+// the name of a file or directory at any depth, and a trailing slash only
+// matches directories:
+//
+//	# tests and docs are not needed to build
+//	*_test.go
+//	/docs/
+//	testdata/
+const vendorIgnoreFile = ".holonvendorignore"
+
+// vendorIgnore holds the patterns of a vendorIgnoreFile.
+type vendorIgnore []string
+
+// readVendorIgnore reads the vendorIgnoreFile in dir. A missing file
+// ignores nothing.
+func readVendorIgnore(dir string) (vendorIgnore, error) {
+	f, err := os.Open(filepath.Join(dir, vendorIgnoreFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ig vendorIgnore
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		p := strings.TrimSpace(scanner.Text())
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		if _, err := path.Match(strings.Trim(p, "/"), ""); err != nil {
+			return nil, fmt.Errorf("%s:%d: %q: %w", vendorIgnoreFile, line, p, err)
+		}
+		ig = append(ig, p)
+	}
+	return ig, scanner.Err()
+}
+
+// match reports whether rel, a slash-separated path relative to the root
+// of a dependency, is ignored.
+func (ig vendorIgnore) match(rel string, dir bool) bool {
+	for _, p := range ig {
+		p, dirOnly := strings.CutSuffix(p, "/")
+		if dirOnly && !dir {
+			continue
+		}
+		name := rel
+		if !strings.Contains(p, "/") {
+			name = path.Base(rel)
+		}
+		if ok, _ := path.Match(strings.TrimPrefix(p, "/"), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// size returns the size of the files of src that are not ignored.
+func (ig vendorIgnore) size(src string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == src {
+			return err
+		}
+		rel, _ := filepath.Rel(src, p)
+		if ig.match(filepath.ToSlash(rel), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,115 @@
+package web
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/internal/server"
+)
+
+//go:embed dashboard.html
+var dashboardSource string
+
+var dashboardTmpl = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"bytes": formatBytes,
+}).Parse(dashboardSource))
+
+// Dashboard is the data rendered by the dashboard page.
+type Dashboard struct {
+	Holons     []HolonStatus
+	Cache      []server.CacheEntry
+	CacheSize  int64
+	Operations []server.Operation
+	CacheErr   string
+
+	// CacheRepairs are those of the cache check the daemon ran as it
+	// started, if any.
+	CacheRepairs []server.CacheRepair
+}
+
+// HolonStatus summarizes one holon directory served by the daemon.
+type HolonStatus struct {
+	Directory string
+	HolonPath string
+	Requires  []*pb.Edge
+	Verify    *pb.VerifyResponse
+	Updates   []*pb.UpdatedDependency
+	Err       string
+}
+
+// DashboardHandler serves a read-only console over srv for the holons in
+// dirs. The graph of each holon is mounted under /graph/<index>/.
+func DashboardHandler(srv *server.Server, dirs []string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		d := BuildDashboard(r.Context(), srv, dirs)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := dashboardTmpl.Execute(w, d); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	mux.HandleFunc("GET /dashboard.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, BuildDashboard(r.Context(), srv, dirs))
+	})
+	for i, dir := range dirs {
+		prefix := fmt.Sprintf("/graph/%d", i)
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, GraphHandler(srv, dir)))
+	}
+	return mux
+}
+
+// BuildDashboard gathers the dashboard data using read-only calls only.
+func BuildDashboard(ctx context.Context, srv *server.Server, dirs []string) *Dashboard {
+	d := &Dashboard{Operations: srv.RecentOperations(), CacheRepairs: srv.CacheRepairs()}
+
+	for _, dir := range dirs {
+		h := HolonStatus{Directory: dir}
+		graph, err := srv.Graph(ctx, &pb.GraphRequest{Directory: dir})
+		if err != nil {
+			h.Err = err.Error()
+			d.Holons = append(d.Holons, h)
+			continue
+		}
+		h.HolonPath = graph.Root
+		for _, e := range graph.Edges {
+			if e.From == graph.Root {
+				h.Requires = append(h.Requires, e)
+			}
+		}
+		if h.Verify, err = srv.Verify(ctx, &pb.VerifyRequest{Directory: dir}); err != nil {
+			h.Err = err.Error()
+		}
+		if h.Updates, err = srv.PendingUpdates(ctx, dir); err != nil {
+			h.Err = err.Error()
+		}
+		d.Holons = append(d.Holons, h)
+	}
+
+	entries, err := server.ListCache()
+	if err != nil {
+		d.CacheErr = err.Error()
+	}
+	d.Cache = entries
+	for _, e := range entries {
+		d.CacheSize += e.Size
+	}
+	return d
+}
+
+// formatBytes renders a byte count for humans.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,168 @@
+// Package web implements the browser facet of Rhizome Atlas.
+// Pages are static assets; their data comes from JSON endpoints that call
+// the gRPC service implementation, so the browser sees exactly what the
+// CLI and gRPC facets see.
+package web
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/internal/server"
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+)
+
+//go:embed graph.html
+var graphPage []byte
+
+// GraphView is the JSON payload behind the graph page.
+type GraphView struct {
+	Root  string      `json:"root"`
+	Edges []GraphEdge `json:"edges"`
+	Nodes []GraphNode `json:"nodes"`
+	// Conflicts maps the ID of a node to the distinct versions required
+	// of it across the graph, for nodes required at more than one version.
+	Conflicts map[string][]string `json:"conflicts"`
+}
+
+// GraphEdge is one edge of the graph, from the node FromID to the node
+// ToID.
+type GraphEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Version string `json:"version"`
+	FromID  string `json:"from_id"`
+	ToID    string `json:"to_id"`
+}
+
+// GraphNode is one holon of the graph, as the Graph RPC describes it.
+// Its ID is its path, followed by @v<major> when several majors of it
+// coexist in the graph.
+type GraphNode struct {
+	ID       string `json:"id"`
+	Path     string `json:"path"`
+	Version  string `json:"version,omitempty"`
+	Location string `json:"location,omitempty"`
+	Replace  string `json:"replace,omitempty"`
+	Title    string `json:"title,omitempty"`
+}
+
+// GraphHandler serves an interactive view of the dependency graph of the
+// holon.mod in dir.
+//
+//	GET /            the graph page
+//	GET /graph.json  the graph as a GraphView
+//	GET /holon.md    the cached HOLON.md of ?path=&version=
+func GraphHandler(srv *server.Server, dir string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(graphPage) //nolint:errcheck
+	})
+	mux.HandleFunc("GET /graph.json", func(w http.ResponseWriter, r *http.Request) {
+		view, err := BuildGraphView(r.Context(), srv, dir)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, view)
+	})
+	mux.HandleFunc("GET /holon.md", serveHolonMD)
+	return mux
+}
+
+// BuildGraphView calls the Graph RPC for dir and annotates version conflicts.
+func BuildGraphView(ctx context.Context, srv *server.Server, dir string) (*GraphView, error) {
+	resp, err := srv.Graph(ctx, &pb.GraphRequest{Directory: dir})
+	if err != nil {
+		return nil, err
+	}
+
+	majors := map[string]map[string]bool{}
+	for _, n := range resp.Nodes[1:] {
+		if majors[n.Path] == nil {
+			majors[n.Path] = map[string]bool{}
+		}
+		majors[n.Path][major(n.Version)] = true
+	}
+	nodeID := func(path, version string) string {
+		if len(majors[path]) > 1 {
+			return path + "@" + major(version)
+		}
+		return path
+	}
+
+	view := &GraphView{Root: resp.Root, Edges: []GraphEdge{}, Nodes: []GraphNode{}, Conflicts: map[string][]string{}}
+	for _, n := range resp.Nodes {
+		view.Nodes = append(view.Nodes, GraphNode{ID: nodeID(n.Path, n.Version), Path: n.Path, Version: n.Version, Location: n.Location, Replace: n.Replace, Title: n.Title})
+	}
+	versions := map[string][]string{}
+	for _, e := range resp.Edges {
+		from, to := nodeID(e.From, e.FromVersion), nodeID(e.To, e.Version)
+		view.Edges = append(view.Edges, GraphEdge{From: e.From, To: e.To, Version: e.Version, FromID: from, ToID: to})
+		seen := false
+		for _, v := range versions[to] {
+			if v == e.Version {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			versions[to] = append(versions[to], e.Version)
+		}
+	}
+	for id, vs := range versions {
+		if len(vs) > 1 {
+			view.Conflicts[id] = vs
+		}
+	}
+	return view, nil
+}
+
+// major returns the major version of version, such as "v2", or version
+// itself if it is not semver.
+func major(version string) string {
+	if m, _, _, ok := semver.Parse(version); ok {
+		return "v" + strconv.Itoa(m)
+	}
+	return version
+}
+
+// serveHolonMD returns the HOLON.md of a cached dependency.
+func serveHolonMD(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	version := r.URL.Query().Get("version")
+	if path == "" || version == "" {
+		http.Error(w, "path and version are required", http.StatusBadRequest)
+		return
+	}
+
+	dir, err := server.CachedDir(path, version)
+	if errors.Is(err, fs.ErrInvalid) {
+		http.Error(w, "invalid dependency path", http.StatusBadRequest)
+		return
+	}
+	var data []byte
+	if err == nil {
+		data, err = os.ReadFile(filepath.Join(dir, "HOLON.md"))
+	}
+	if err != nil {
+		http.Error(w, path+"@"+version+" not in cache", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(data) //nolint:errcheck
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v) //nolint:errcheck
+}
@@ -0,0 +1,161 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/internal/server"
+	"github.com/organic-programming/rhizome-atlas/internal/web"
+	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
+)
+
+func TestGraphViewConflicts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	root := &modfile.ModFile{HolonPath: "test/root"}
+	root.AddRequire("test/a", "v1.0.0")
+	root.AddRequire("test/c", "v1.1.0")
+	if err := root.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	// test/a (cached) requires an older test/c
+	sub := &modfile.ModFile{HolonPath: "test/a"}
+	sub.AddRequire("test/c", "v1.0.0")
+	if err := sub.Write(filepath.Join(server.CachePath("test/a", "v1.0.0"), "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := web.BuildGraphView(context.Background(), &server.Server{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(view.Edges) != 3 {
+		t.Fatalf("edges = %d, want 3", len(view.Edges))
+	}
+	if len(view.Conflicts) != 1 || len(view.Conflicts["test/c"]) != 2 {
+		t.Errorf("conflicts = %v, want test/c at two versions", view.Conflicts)
+	}
+}
+
+func TestGraphViewMajors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	root := &modfile.ModFile{HolonPath: "test/root"}
+	root.AddRequire("test/x", "v1.0.0")
+	root.AddRequire("test/y", "v1.0.0")
+	if err := root.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	// test/y (cached) requires the next major of test/x: both coexist.
+	sub := &modfile.ModFile{HolonPath: "test/y"}
+	sub.AddRequire("test/x", "v2.0.0")
+	if err := sub.Write(filepath.Join(server.CachePath("test/y", "v1.0.0"), "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := web.BuildGraphView(context.Background(), &server.Server{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nodes, edges []string
+	for _, n := range view.Nodes {
+		nodes = append(nodes, n.ID)
+	}
+	for _, e := range view.Edges {
+		edges = append(edges, e.FromID+" "+e.ToID)
+	}
+	if want := []string{"test/root", "test/x@v1", "test/y", "test/x@v2"}; !slices.Equal(nodes, want) {
+		t.Errorf("nodes = %q, want %q", nodes, want)
+	}
+	if want := []string{"test/root test/x@v1", "test/root test/y", "test/y test/x@v2"}; !slices.Equal(edges, want) {
+		t.Errorf("edges = %q, want %q", edges, want)
+	}
+	if len(view.Conflicts) != 0 {
+		t.Errorf("conflicts = %v, want none across majors", view.Conflicts)
+	}
+}
+
+func TestGraphHandler(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	mod := &modfile.ModFile{HolonPath: "test/root"}
+	mod.AddRequire("test/a", "v1.0.0")
+	if err := mod.Write(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	cached := server.CachePath("test/a", "v1.0.0")
+	if err := os.MkdirAll(cached, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cached, "HOLON.md"), []byte("# A"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := web.GraphHandler(&server.Server{}, dir)
+
+	for _, tc := range []struct {
+		url  string
+		code int
+	}{
+		{"/", http.StatusOK},
+		{"/graph.json", http.StatusOK},
+		{"/holon.md?path=test/a&version=v1.0.0", http.StatusOK},
+		{"/holon.md?path=test/b&version=v1.0.0", http.StatusNotFound},
+		{"/holon.md?path=../../etc&version=v1", http.StatusBadRequest},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tc.url, nil))
+		if rec.Code != tc.code {
+			t.Errorf("GET %s = %d, want %d", tc.url, rec.Code, tc.code)
+		}
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	srv := &server.Server{}
+
+	if _, err := srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/dash"}); err != nil {
+		t.Fatal(err)
+	}
+	cached := server.CachePath("test/a", "v1.0.0")
+	if err := os.MkdirAll(cached, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cached, "HOLON.md"), []byte("# A"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := web.BuildDashboard(ctx, srv, []string{dir})
+	if len(d.Holons) != 1 || d.Holons[0].HolonPath != "test/dash" {
+		t.Fatalf("holons = %+v", d.Holons)
+	}
+	if !d.Holons[0].Verify.Ok {
+		t.Errorf("verify errors: %v", d.Holons[0].Verify.Errors)
+	}
+	if len(d.Cache) != 1 || d.Cache[0].Path != "test/a" || d.Cache[0].Size != 3 {
+		t.Errorf("cache = %+v", d.Cache)
+	}
+	if len(d.Operations) != 1 || d.Operations[0].Method != "Init" {
+		t.Errorf("operations = %+v", d.Operations)
+	}
+
+	rec := httptest.NewRecorder()
+	web.DashboardHandler(srv, []string{dir}).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET / = %d: %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,270 @@
+// Package atlas runs the core holon dependency operations in-process, for
+// build tools that embed atlas instead of talking to "atlas serve".
+//
+// Its API uses plain Go types: no protobuf message or gRPC status crosses
+// it. Failed operations return an *Error, which matches ErrNotFound,
+// ErrInvalidArgument, ErrFailedPrecondition or ErrUnavailable with
+// errors.Is.
+//
+//	var a atlas.Atlas
+//	if err := a.Init(ctx, dir, "github.com/org/tool"); err != nil { ... }
+//	dep, err := a.Add(ctx, dir, "github.com/org/dep", "v1.2.0", atlas.AddOptions{})
+package atlas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/internal/server"
+)
+
+// Atlas performs operations on holons on the local file system and the
+// global cache (~/.holon/cache). The zero value is ready to use; an Atlas
+// must not be copied after first use. It is safe for concurrent use.
+type Atlas struct {
+	srv server.Server
+}
+
+// Dependency is a required holon.
+type Dependency struct {
+	Path    string
+	Version string
+	// Dir is where the content lives: the cache snapshot, or the vendored
+	// copy for Vendor. Empty when not fetched.
+	Dir string
+	// Alias is the short name declared in holon.mod, if any.
+	Alias string
+	// Source is the git URL that served the content, if known.
+	Source string
+}
+
+// Graph is the dependency graph of a holon.
+type Graph struct {
+	Root  string
+	Edges []Edge
+	// Nodes lists every holon of the graph, the root first.
+	Nodes []Node
+}
+
+// Edge is a require of holon From on To at Version.
+type Edge struct {
+	From, To, Version string
+	Alias             string
+	// Metadata holds the annotations of the require line.
+	Metadata map[string]string
+	// FromVersion is the version of From whose holon.mod lists the
+	// require; empty for the root.
+	FromVersion string
+}
+
+// Node is a holon of the graph, or a major version of it when majors
+// coexist.
+type Node struct {
+	Path string
+	// Version is the highest version of its major the edges to Path
+	// require; empty for the root.
+	Version string
+	// Location is where the sources are: "replace", "cache", "vendor" or
+	// "missing"; empty for the root.
+	Location string
+	// Replace is the local directory or path the root holon.mod replaces
+	// Path with, if any.
+	Replace string
+	// Title is the name in its HOLON.md, if found.
+	Title string
+}
+
+// AddOptions configures Add.
+type AddOptions struct {
+	// Alias is a short name for the dependency.
+	Alias string
+	// RecordOnly records the dependency in holon.mod without fetching it
+	// or touching holon.sum.
+	RecordOnly bool
+}
+
+// GraphOptions configures Graph.
+type GraphOptions struct {
+	// Filter keeps only the requires annotated with all of these
+	// key=value pairs, and the edges below them.
+	Filter map[string]string
+}
+
+// ServeOptions configures the gRPC server of Serve: interceptors, services
+// to register next to atlas, and hooks called when it starts and stops.
+type ServeOptions = server.ServeOptions
+
+// AuthConfig authorizes the calls of a server per method, for
+// ServeOptions.Auth: see "atlas serve --auth".
+type AuthConfig = server.AuthConfig
+
+// LoadAuthConfig reads an AuthConfig from the file at path.
+func LoadAuthConfig(path string) (*AuthConfig, error) {
+	return server.LoadAuthConfig(path)
+}
+
+// Serve serves the atlas gRPC service on listenURI, as "atlas serve" does,
+// until interrupted. It is for programs embedding the daemon rather than
+// the operations.
+func (a *Atlas) Serve(listenURI string, opts ServeOptions) error {
+	return a.srv.Serve(listenURI, opts)
+}
+
+// Init creates holon.mod in dir for the holon at holonPath.
+func (a *Atlas) Init(ctx context.Context, dir, holonPath string) error {
+	_, err := a.srv.Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: holonPath})
+	return convert("init", err)
+}
+
+// Add requires path at version in the holon.mod of dir, fetching it and
+// recording its hashes in holon.sum unless opts.RecordOnly is set. A
+// version of "@<channel>" resolves to the latest prerelease of that channel.
+func (a *Atlas) Add(ctx context.Context, dir, path, version string, opts AddOptions) (Dependency, error) {
+	resp, err := a.srv.Add(ctx, &pb.AddRequest{
+		Directory:  dir,
+		Path:       path,
+		Version:    version,
+		Alias:      opts.Alias,
+		RecordOnly: opts.RecordOnly,
+	})
+	if err != nil {
+		return Dependency{}, convert("add", err)
+	}
+	return dependency(resp.Dependency), nil
+}
+
+// Pull fetches every dependency of the holon in dir to the cache and
+// records their hashes in holon.sum.
+func (a *Atlas) Pull(ctx context.Context, dir string) ([]Dependency, error) {
+	resp, err := a.srv.Pull(ctx, &pb.PullRequest{Directory: dir})
+	if err != nil {
+		return nil, convert("pull", err)
+	}
+	return dependencies(resp.Fetched), nil
+}
+
+// Verify checks the holon.sum of the holon in dir against the cache and
+// returns the problems found; none means it verified.
+func (a *Atlas) Verify(ctx context.Context, dir string) ([]string, error) {
+	resp, err := a.srv.Verify(ctx, &pb.VerifyRequest{Directory: dir})
+	if err != nil {
+		return nil, convert("verify", err)
+	}
+	return resp.Errors, nil
+}
+
+// Graph returns the dependency graph of the holon in dir.
+func (a *Atlas) Graph(ctx context.Context, dir string, opts GraphOptions) (Graph, error) {
+	resp, err := a.srv.Graph(ctx, &pb.GraphRequest{Directory: dir, Filter: opts.Filter})
+	if err != nil {
+		return Graph{}, convert("graph", err)
+	}
+	g := Graph{Root: resp.Root}
+	for _, e := range resp.Edges {
+		g.Edges = append(g.Edges, Edge{
+			From:        e.From,
+			To:          e.To,
+			Version:     e.Version,
+			Alias:       e.Alias,
+			Metadata:    e.Metadata,
+			FromVersion: e.FromVersion,
+		})
+	}
+	for _, n := range resp.Nodes {
+		g.Nodes = append(g.Nodes, Node{
+			Path:     n.Path,
+			Version:  n.Version,
+			Location: n.Location,
+			Replace:  n.Replace,
+			Title:    n.Title,
+		})
+	}
+	return g, nil
+}
+
+// Vendor copies the cached dependencies of the holon in dir to dir/.holon/.
+func (a *Atlas) Vendor(ctx context.Context, dir string) ([]Dependency, error) {
+	resp, err := a.srv.Vendor(ctx, &pb.VendorRequest{Directory: dir})
+	if err != nil {
+		return nil, convert("vendor", err)
+	}
+	return dependencies(resp.Vendored), nil
+}
+
+func dependency(d *pb.Dependency) Dependency {
+	return Dependency{
+		Path:    d.Path,
+		Version: d.Version,
+		Dir:     d.CachePath,
+		Alias:   d.Alias,
+		Source:  d.Source,
+	}
+}
+
+func dependencies(ds []*pb.Dependency) []Dependency {
+	out := make([]Dependency, 0, len(ds))
+	for _, d := range ds {
+		out = append(out, dependency(d))
+	}
+	return out
+}
+
+// Errors returned by Atlas match these with errors.Is.
+var (
+	// ErrNotFound: a missing holon.mod, dependency or tag.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidArgument: e.g. a malformed digest or a duplicate alias.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrFailedPrecondition: the holon is not in a state allowing the
+	// operation, e.g. a dependency missing from the cache.
+	ErrFailedPrecondition = errors.New("failed precondition")
+	// ErrUnavailable: an upstream repository could not be reached.
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// codeErrors maps the codes of server errors, by name, to the sentinels.
+var codeErrors = map[string]error{
+	"NotFound":           ErrNotFound,
+	"InvalidArgument":    ErrInvalidArgument,
+	"FailedPrecondition": ErrFailedPrecondition,
+	"Unavailable":        ErrUnavailable,
+}
+
+// Error is a failed operation.
+type Error struct {
+	Op      string // e.g. "add"
+	Message string
+	// Reason tells what failed without parsing Message, e.g.
+	// "HASH_MISMATCH": one of the Reason constants of pkg/client, or
+	// empty. Metadata may hold the keys documented there: "dependency",
+	// "file" and "run".
+	Reason   string
+	Metadata map[string]string
+
+	kind error // sentinel, if any
+}
+
+func (e *Error) Error() string {
+	if e.kind != nil {
+		return fmt.Sprintf("atlas %s: %v: %s", e.Op, e.kind, e.Message)
+	}
+	return fmt.Sprintf("atlas %s: %s", e.Op, e.Message)
+}
+
+// Unwrap returns the sentinel of the kind of e, if any.
+func (e *Error) Unwrap() error { return e.kind }
+
+// convert turns a server error into an *Error. Errors the server did not
+// answer with, such as a canceled context, are wrapped as they are.
+func convert(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := server.AsStatusError(err)
+	if !ok {
+		return fmt.Errorf("atlas %s: %w", op, err)
+	}
+	return &Error{Op: op, Message: st.Message, Reason: st.Reason, Metadata: st.Metadata, kind: codeErrors[st.Code]}
+}
@@ -0,0 +1,50 @@
+package atlas_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/atlas"
+)
+
+func TestAtlas(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	var a atlas.Atlas
+
+	_, err := a.Graph(ctx, dir, atlas.GraphOptions{})
+	var aerr *atlas.Error
+	if !errors.Is(err, atlas.ErrNotFound) || !errors.As(err, &aerr) || aerr.Reason != "MOD_UNREADABLE" || aerr.Metadata["run"] == "" {
+		t.Fatalf("Graph without holon.mod: err = %v, want ErrNotFound for an unreadable holon.mod", err)
+	}
+
+	if err := a.Init(ctx, dir, "test/atlas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "holon.mod")); err != nil {
+		t.Fatal(err)
+	}
+	dep, err := a.Add(ctx, dir, "github.com/test/dep", "v0.1.0", atlas.AddOptions{Alias: "d", RecordOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dep.Alias != "d" || dep.Dir != "" {
+		t.Errorf("dep = %+v", dep)
+	}
+
+	g, err := a.Graph(ctx, dir, atlas.GraphOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Root != "test/atlas" || len(g.Edges) != 1 || g.Edges[0].To != "github.com/test/dep" {
+		t.Errorf("graph = %+v", g)
+	}
+
+	if _, err := a.Vendor(ctx, dir); !errors.Is(err, atlas.ErrFailedPrecondition) {
+		t.Errorf("Vendor of an unfetched dependency: err = %v, want ErrFailedPrecondition", err)
+	}
+}
@@ -0,0 +1,308 @@
+// Package client is a Go client for the Rhizome Atlas gRPC service.
+//
+// It dials the transport URIs "atlas serve" listens on (tcp://, unix://,
+// npipe://, ws://, wss://), or an in-process mem:// listener, retries
+// calls that are safe to retry, and turns gRPC statuses into errors that
+// can be matched with errors.Is:
+//
+//	c, err := client.Dial("tcp://localhost:9090")
+//	...
+//	defer c.Close()
+//	deps, err := c.Pull(ctx, ".")
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/organic-programming/go-holons/pkg/transport"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"nhooyr.io/websocket"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/internal/npipe"
+)
+
+// RetryPolicy says how often a call that failed to reach the server, with
+// codes.Unavailable, is attempted again. Unavailable errors the server
+// answers with, such as a failed upstream fetch, are not retried.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt; 1 or less disables retries.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled for each next.
+	Backoff time.Duration
+}
+
+// DefaultRetryPolicy is used unless WithRetryPolicy says otherwise.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, Backoff: 200 * time.Millisecond}
+
+// Option configures a Client.
+type Option func(*options)
+
+type options struct {
+	retry    RetryPolicy
+	dialOpts []grpc.DialOption
+	callOpts []grpc.CallOption
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) { o.retry = p }
+}
+
+// WithDialOptions adds gRPC dial options, e.g. interceptors.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) { o.dialOpts = append(o.dialOpts, opts...) }
+}
+
+// WithMaxMessageSize bounds the messages the client sends and receives to
+// n bytes instead of the gRPC default of 4 MiB, which the graph of a big
+// workspace can exceed. The server must accept as much: see
+// "atlas serve --max-msg-size".
+func WithMaxMessageSize(n int) Option {
+	return func(o *options) {
+		o.callOpts = append(o.callOpts, grpc.MaxCallRecvMsgSize(n), grpc.MaxCallSendMsgSize(n))
+	}
+}
+
+// WithToken sends token as the bearer token of every call, for servers
+// authorizing calls per method: see "atlas serve --auth".
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.dialOpts = append(o.dialOpts, grpc.WithPerRPCCredentials(tokenCredentials(token)))
+	}
+}
+
+// tokenCredentials sends a bearer token. The transports of Dial are not
+// secured, so it does not require them to be.
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (tokenCredentials) RequireTransportSecurity() bool { return false }
+
+// WithGzip compresses calls with gzip, and so the responses of the server.
+func WithGzip() Option {
+	return func(o *options) { o.callOpts = append(o.callOpts, grpc.UseCompressor(gzip.Name)) }
+}
+
+// Client calls a Rhizome Atlas server. It is safe for concurrent use.
+type Client struct {
+	conn  *grpc.ClientConn
+	rpc   pb.RhizomeAtlasServiceClient
+	retry RetryPolicy
+}
+
+// Dial connects to the server listening on uri: "tcp://host:port",
+// "unix:///path/to.sock", "npipe:////./pipe/<name>" (Windows), or
+// "ws://host:port[/path]" (and wss://). The connection is established
+// lazily, on the first call.
+func Dial(uri string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("parse %q: %w", uri, err)
+	}
+
+	var dial func(ctx context.Context, _ string) (net.Conn, error)
+	switch u.Scheme {
+	case "tcp":
+		dial = func(ctx context.Context, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "tcp", u.Host)
+		}
+	case "unix":
+		path := u.Host + u.Path
+		dial = func(ctx context.Context, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		}
+	case "npipe":
+		name, err := npipe.Path(uri)
+		if err != nil {
+			return nil, err
+		}
+		dial = func(ctx context.Context, _ string) (net.Conn, error) {
+			return npipe.Dial(ctx, name)
+		}
+	case "ws", "wss":
+		dial = func(ctx context.Context, _ string) (net.Conn, error) {
+			c, _, err := websocket.Dial(ctx, uri, &websocket.DialOptions{Subprotocols: []string{"grpc"}})
+			if err != nil {
+				return nil, err
+			}
+			// The connection outlives ctx, which only bounds the dial.
+			return websocket.NetConn(context.Background(), c, websocket.MessageBinary), nil
+		}
+	case "mem":
+		return nil, fmt.Errorf("%s: mem:// is in-process only, use NewMem", uri)
+	default:
+		return nil, fmt.Errorf("%s: unsupported scheme %q", uri, u.Scheme)
+	}
+	return newClient(u.Scheme, dial, opts)
+}
+
+// NewMem connects to a server serving l in the same process.
+func NewMem(l *transport.MemListener, opts ...Option) (*Client, error) {
+	return newClient("mem", func(context.Context, string) (net.Conn, error) {
+		return l.Dial()
+	}, opts)
+}
+
+func newClient(scheme string, dial func(context.Context, string) (net.Conn, error), opts []Option) (*Client, error) {
+	o := options{retry: DefaultRetryPolicy}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithContextDialer(dial),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(o.callOpts...),
+	}, o.dialOpts...)
+
+	conn, err := grpc.NewClient("passthrough:///"+scheme, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, rpc: pb.NewRhizomeAtlasServiceClient(conn), retry: o.retry}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Service returns the generated stub, for RPCs without a method here.
+// Its calls are neither retried nor given typed errors.
+func (c *Client) Service() pb.RhizomeAtlasServiceClient {
+	return c.rpc
+}
+
+// Pull fetches the dependencies of the holon in dir to the server's cache
+// and records their hashes in holon.sum.
+func (c *Client) Pull(ctx context.Context, dir string) ([]*pb.Dependency, error) {
+	resp, err := call(ctx, c, "Pull", func(ctx context.Context) (*pb.PullResponse, error) {
+		return c.rpc.Pull(ctx, &pb.PullRequest{Directory: dir})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Fetched, nil
+}
+
+// Graph returns the dependency graph of the holon in dir.
+func (c *Client) Graph(ctx context.Context, dir string) (*pb.GraphResponse, error) {
+	return call(ctx, c, "Graph", func(ctx context.Context) (*pb.GraphResponse, error) {
+		return c.rpc.Graph(ctx, &pb.GraphRequest{Directory: dir})
+	})
+}
+
+// Verify checks the holon.sum of the holon in dir against the cache. A
+// mismatch is reported in the response, not as an error.
+func (c *Client) Verify(ctx context.Context, dir string) (*pb.VerifyResponse, error) {
+	return call(ctx, c, "Verify", func(ctx context.Context) (*pb.VerifyResponse, error) {
+		return c.rpc.Verify(ctx, &pb.VerifyRequest{Directory: dir})
+	})
+}
+
+// Add adds a dependency. Without an idempotency key in req, one is
+// generated (on a copy of req) so that retries cannot apply the Add twice.
+func (c *Client) Add(ctx context.Context, req *pb.AddRequest) (*pb.AddResponse, error) {
+	if req.IdempotencyKey == "" {
+		req = proto.CloneOf(req)
+		req.IdempotencyKey = newKey()
+	}
+	return call(ctx, c, "Add", func(ctx context.Context) (*pb.AddResponse, error) {
+		return c.rpc.Add(ctx, req)
+	})
+}
+
+// Remove removes a dependency, with an idempotency key as Add does.
+func (c *Client) Remove(ctx context.Context, req *pb.RemoveRequest) (*pb.RemoveResponse, error) {
+	if req.IdempotencyKey == "" {
+		req = proto.CloneOf(req)
+		req.IdempotencyKey = newKey()
+	}
+	return call(ctx, c, "Remove", func(ctx context.Context) (*pb.RemoveResponse, error) {
+		return c.rpc.Remove(ctx, req)
+	})
+}
+
+// Update updates dependencies, with an idempotency key as Add does.
+func (c *Client) Update(ctx context.Context, req *pb.UpdateRequest) (*pb.UpdateResponse, error) {
+	if req.IdempotencyKey == "" {
+		req = proto.CloneOf(req)
+		req.IdempotencyKey = newKey()
+	}
+	return call(ctx, c, "Update", func(ctx context.Context) (*pb.UpdateResponse, error) {
+		return c.rpc.Update(ctx, req)
+	})
+}
+
+// Vendor copies dependencies to .holon/, with an idempotency key as Add
+// does.
+func (c *Client) Vendor(ctx context.Context, req *pb.VendorRequest) (*pb.VendorResponse, error) {
+	if req.IdempotencyKey == "" {
+		req = proto.CloneOf(req)
+		req.IdempotencyKey = newKey()
+	}
+	return call(ctx, c, "Vendor", func(ctx context.Context) (*pb.VendorResponse, error) {
+		return c.rpc.Vendor(ctx, req)
+	})
+}
+
+// call runs rpc under the retry policy of c and wraps its final error.
+// Only RPCs that are safe to repeat go through call: read-only ones, Pull,
+// and mutations carrying an idempotency key.
+func call[T any](ctx context.Context, c *Client, method string, rpc func(context.Context) (T, error)) (T, error) {
+	backoff := c.retry.Backoff
+	for attempt := 1; ; attempt++ {
+		resp, err := rpc(ctx)
+		if err == nil {
+			return resp, nil
+		}
+		if !transient(err) || attempt >= c.retry.MaxAttempts {
+			return resp, wrap(method, err)
+		}
+		select {
+		case <-ctx.Done():
+			return resp, wrap(method, err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// transient reports whether err is a failure to reach the server, which a
+// retry may get past: Unavailable without an ErrorInfo. The server attaches
+// one to the Unavailable errors it answers with itself, e.g. an upstream
+// fetch that failed (ReasonFetchFailed), and those are not retried.
+func transient(err error) bool {
+	st := status.Convert(err)
+	if st.Code() != codes.Unavailable {
+		return false
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Domain == ErrorDomain {
+			return false
+		}
+	}
+	return true
+}
+
+// newKey returns a random idempotency key.
+func newKey() string {
+	b := make([]byte, 16)
+	rand.Read(b) //nolint:errcheck // never fails
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,171 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/organic-programming/go-holons/pkg/transport"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/organic-programming/rhizome-atlas/gen/go/rhizome_atlas/v1"
+	"github.com/organic-programming/rhizome-atlas/internal/server"
+	"github.com/organic-programming/rhizome-atlas/pkg/client"
+)
+
+// serveMem serves impl on a mem:// listener and returns a client for it.
+func serveMem(t *testing.T, impl pb.RhizomeAtlasServiceServer, opts ...client.Option) *client.Client {
+	t.Helper()
+	mem := transport.NewMemListener()
+	s := grpc.NewServer()
+	pb.RegisterRhizomeAtlasServiceServer(s, impl)
+	go func() { _ = s.Serve(mem) }()
+	t.Cleanup(s.Stop)
+
+	c, err := client.NewMem(mem, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClient(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := context.Background()
+	c := serveMem(t, &server.Server{})
+
+	_, err := c.Graph(ctx, dir)
+	if !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("Graph without holon.mod: err = %v, want ErrNotFound", err)
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Error("status.Code does not see through client errors")
+	}
+	var cerr *client.Error
+	if !errors.As(err, &cerr) || cerr.Reason != client.ReasonModUnreadable || cerr.Metadata["run"] == "" {
+		t.Errorf("Graph without holon.mod: error details = %+v", cerr)
+	}
+
+	if _, err := c.Service().Init(ctx, &pb.InitRequest{Directory: dir, HolonPath: "test/client"}); err != nil {
+		t.Fatal(err)
+	}
+	add := &pb.AddRequest{Directory: dir, Path: "github.com/test/dep", Version: "v0.1.0", RecordOnly: true}
+	if _, err := c.Add(ctx, add); err != nil {
+		t.Fatal(err)
+	}
+	if add.IdempotencyKey != "" {
+		t.Error("Add modified the caller's request")
+	}
+
+	graph, err := c.Graph(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if graph.Root != "test/client" || len(graph.Edges) != 1 {
+		t.Errorf("graph = %v", graph)
+	}
+	if _, err := c.Verify(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Vendoring lists every dependency missing from the cache.
+	add = &pb.AddRequest{Directory: dir, Path: "github.com/test/other", Version: "v0.2.0", RecordOnly: true}
+	if _, err := c.Add(ctx, add); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Vendor(ctx, &pb.VendorRequest{Directory: dir})
+	if !errors.As(err, &cerr) || cerr.Reason != client.ReasonNotCached || cerr.Metadata["run"] != "atlas pull" {
+		t.Fatalf("Vendor with uncached deps: err = %v", err)
+	}
+	var subjects []string
+	for _, v := range cerr.Violations {
+		subjects = append(subjects, v.Subject)
+	}
+	if len(subjects) != 2 || subjects[0] != "github.com/test/dep@v0.1.0" || subjects[1] != "github.com/test/other@v0.2.0" {
+		t.Errorf("violations = %v", subjects)
+	}
+	if len(status.Convert(err).Details()) != 2 {
+		t.Error("status.Convert drops the error details")
+	}
+}
+
+// flaky fails the first failures Graph calls with Unavailable, as the
+// server does for an upstream fetch that failed if fetchFailed is set.
+type flaky struct {
+	pb.UnimplementedRhizomeAtlasServiceServer
+	failures, calls int
+	fetchFailed     bool
+}
+
+func (f *flaky) Graph(context.Context, *pb.GraphRequest) (*pb.GraphResponse, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		st := status.New(codes.Unavailable, "try again")
+		if f.fetchFailed {
+			st, _ = st.WithDetails(&errdetails.ErrorInfo{Reason: client.ReasonFetchFailed, Domain: client.ErrorDomain})
+		}
+		return nil, st.Err()
+	}
+	return &pb.GraphResponse{Root: "test/flaky"}, nil
+}
+
+func TestClientRetry(t *testing.T) {
+	ctx := context.Background()
+	policy := client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 3})
+
+	f := &flaky{failures: 2}
+	graph, err := serveMem(t, f, policy).Graph(ctx, ".")
+	if err != nil || graph.Root != "test/flaky" || f.calls != 3 {
+		t.Errorf("graph = %v, err = %v after %d calls", graph, err, f.calls)
+	}
+
+	f = &flaky{failures: 3}
+	if _, err := serveMem(t, f, policy).Graph(ctx, "."); !errors.Is(err, client.ErrUnavailable) || f.calls != 3 {
+		t.Errorf("err = %v after %d calls, want ErrUnavailable after 3", err, f.calls)
+	}
+
+	// The server could not fetch upstream: retrying would fetch again.
+	f = &flaky{failures: 1, fetchFailed: true}
+	if _, err := serveMem(t, f, policy).Graph(ctx, "."); !errors.Is(err, client.ErrUnavailable) || f.calls != 1 {
+		t.Errorf("err = %v after %d calls, want ErrUnavailable after 1", err, f.calls)
+	}
+}
+
+// bigGraph answers Graph with a response of about 6 MiB.
+type bigGraph struct {
+	pb.UnimplementedRhizomeAtlasServiceServer
+}
+
+func (bigGraph) Graph(context.Context, *pb.GraphRequest) (*pb.GraphResponse, error) {
+	resp := &pb.GraphResponse{Root: "test/big"}
+	for i := range 60000 {
+		resp.Edges = append(resp.Edges, &pb.Edge{
+			From:    "test/big",
+			To:      fmt.Sprintf("github.com/test/dependency-with-a-long-path-%06d", i),
+			Version: "v1.0.0",
+		})
+	}
+	return resp, nil
+}
+
+func TestClientMessageSize(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := serveMem(t, bigGraph{}).Graph(ctx, "."); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("graph over 4 MiB: err = %v, want ResourceExhausted", err)
+	}
+	graph, err := serveMem(t, bigGraph{}, client.WithMaxMessageSize(64<<20)).Graph(ctx, ".")
+	if err != nil || len(graph.Edges) != 60000 {
+		t.Errorf("graph with a 64 MiB bound: err = %v", err)
+	}
+	graph, err = serveMem(t, bigGraph{}, client.WithMaxMessageSize(64<<20), client.WithGzip()).Graph(ctx, ".")
+	if err != nil || len(graph.Edges) != 60000 {
+		t.Errorf("graph compressed: err = %v", err)
+	}
+}
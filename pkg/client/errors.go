@@ -0,0 +1,141 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Errors returned by Client methods match these with errors.Is, after the
+// gRPC code the server answered with.
+var (
+	// ErrNotFound: a missing holon.mod, dependency or tag.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidArgument: a malformed request, e.g. a bad digest or a
+	// reused idempotency key.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrFailedPrecondition: the holon is not in a state allowing the
+	// call, e.g. a dependency missing from the cache.
+	ErrFailedPrecondition = errors.New("failed precondition")
+	// ErrUnavailable: the server, or an upstream it fetches from, could
+	// not be reached, even after retries.
+	ErrUnavailable = errors.New("unavailable")
+	// ErrUnauthenticated: the server authorizes calls per method and the
+	// token is missing or unknown.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrPermissionDenied: the server is read-only, or does not grant the
+	// method to the caller.
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// ErrorDomain is the domain of the google.rpc.ErrorInfo the server
+// attaches to its errors. Its reason, one of the Reason constants, tells
+// what failed without parsing the message. Its metadata may hold
+// "dependency" (<path>@<version>), "file" (the file at fault) and "run"
+// (the command that fixes the failure).
+const ErrorDomain = "rhizome-atlas.organic-programming"
+
+// Reasons of the ErrorInfo details.
+const (
+	// ReasonModUnreadable: holon.mod is missing or cannot be parsed.
+	ReasonModUnreadable = "MOD_UNREADABLE"
+	// ReasonSumUnreadable: holon.sum cannot be parsed.
+	ReasonSumUnreadable = "SUM_UNREADABLE"
+	// ReasonDependencyUnknown: the dependency is not required in holon.mod.
+	ReasonDependencyUnknown = "DEPENDENCY_UNKNOWN"
+	// ReasonNotCached: dependencies are missing from the cache. A
+	// google.rpc.PreconditionFailure lists each of them.
+	ReasonNotCached = "NOT_CACHED"
+	// ReasonFetchFailed: the dependency could not be fetched from upstream.
+	ReasonFetchFailed = "FETCH_FAILED"
+	// ReasonHashMismatch: content does not hash to what holon.sum, or a
+	// bundle, records.
+	ReasonHashMismatch = "HASH_MISMATCH"
+	// ReasonPrereleaseForbidden: the stable directive of holon.mod forbids
+	// prerelease versions.
+	ReasonPrereleaseForbidden = "PRERELEASE_FORBIDDEN"
+	// ReasonNothingToUndo: no change is journaled.
+	ReasonNothingToUndo = "NOTHING_TO_UNDO"
+	// ReasonDirtyWorkTree: the git work tree has uncommitted changes.
+	ReasonDirtyWorkTree = "DIRTY_WORK_TREE"
+	// ReasonProvenanceMissing: the provenance directive of holon.mod
+	// requires a provenance attestation the dependency's release lacks.
+	ReasonProvenanceMissing = "PROVENANCE_MISSING"
+	// ReasonBudgetExceeded: dependencies exceed the size budgets of
+	// holon.mod. A google.rpc.PreconditionFailure lists each budget
+	// exceeded, its subject being the dependency or "total".
+	ReasonBudgetExceeded = "BUDGET_EXCEEDED"
+	// ReasonTrustRequired: the tofu directive of holon.mod requires the
+	// first Add of the dependency to be confirmed. The metadata holds the
+	// "hash", "commit" and "source" of the snapshot to trust.
+	ReasonTrustRequired = "TRUST_REQUIRED"
+	// ReasonLockStale: a locked Pull found holon.lock missing, or not
+	// matching holon.mod.
+	ReasonLockStale = "LOCK_STALE"
+)
+
+var codeErrors = map[codes.Code]error{
+	codes.NotFound:           ErrNotFound,
+	codes.InvalidArgument:    ErrInvalidArgument,
+	codes.FailedPrecondition: ErrFailedPrecondition,
+	codes.Unavailable:        ErrUnavailable,
+	codes.Unauthenticated:    ErrUnauthenticated,
+	codes.PermissionDenied:   ErrPermissionDenied,
+}
+
+// Error is a failed RPC.
+type Error struct {
+	Method  string
+	Code    codes.Code
+	Message string
+
+	// Reason and Metadata come from the ErrorInfo of the status, if any.
+	Reason   string
+	Metadata map[string]string
+	// Violations come from its PreconditionFailure, if any.
+	Violations []*errdetails.PreconditionFailure_Violation
+
+	status *status.Status
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("atlas %s: %s: %s", e.Method, e.Code, e.Message)
+}
+
+// Is reports whether target is the sentinel for e.Code.
+func (e *Error) Is(target error) bool {
+	sentinel, ok := codeErrors[e.Code]
+	return ok && sentinel == target
+}
+
+// GRPCStatus lets status.Code and status.FromError see through an Error.
+func (e *Error) GRPCStatus() *status.Status {
+	if e.status != nil {
+		return e.status
+	}
+	return status.New(e.Code, e.Message)
+}
+
+// wrap turns an RPC error into an *Error. Errors that carry no gRPC
+// status, such as a canceled context, are returned as they are.
+func wrap(method string, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	e := &Error{Method: method, Code: st.Code(), Message: st.Message(), status: st}
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			if d.Domain == ErrorDomain {
+				e.Reason, e.Metadata = d.Reason, d.Metadata
+			}
+		case *errdetails.PreconditionFailure:
+			e.Violations = append(e.Violations, d.Violations...)
+		}
+	}
+	return e
+}
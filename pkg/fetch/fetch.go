@@ -0,0 +1,103 @@
+// Package fetch lets programs embedding atlas choose where dependencies
+// are fetched from, per host. Dependencies of a host without a registered
+// Fetcher are cloned with git from their upstream repository, falling
+// back to the ATLAS_PROXY mirrors.
+//
+// An internal artifact store is plugged in once, before any fetch:
+//
+//	fetch.Register("artifacts.corp.example", storeFetcher{})
+package fetch
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source is one location serving the content of a dependency.
+type Source interface {
+	// String identifies the source, e.g. its URL. It is recorded with the
+	// cached snapshot and reported as the dependency's source.
+	String() string
+	// Fetch writes the content into dst, a directory that does not exist
+	// yet. A failed Fetch may leave dst behind; the caller removes it.
+	Fetch(ctx context.Context, dst string) error
+}
+
+// ProvenanceFile returns where a Source may write the provenance
+// attestation of the content it fetched into dst: an in-toto statement
+// with a SLSA provenance predicate, as JSON (see package provenance).
+// atlas keeps it with the cached snapshot.
+func ProvenanceFile(dst string) string {
+	return dst + ".provenance.json"
+}
+
+// Revision identifies the content a Source fetched.
+type Revision struct {
+	// Commit is the hash of the commit the content was checked out from.
+	Commit string `json:"commit,omitempty"`
+	// TagObject is the hash of the annotated tag naming it, if any.
+	TagObject string `json:"tag_object,omitempty"`
+}
+
+// RevisionFile returns where a Source may write the Revision of the
+// content it fetched into dst, as JSON. atlas records it with the cached
+// snapshot.
+func RevisionFile(dst string) string {
+	return dst + ".revision.json"
+}
+
+// Fetcher resolves dependencies to the sources serving them.
+type Fetcher interface {
+	// Resolve returns the sources of path@version, to be tried in order.
+	// version is a tag, a pseudo-version pinning a commit (see package
+	// semver), or "sha256:<hex>" for content pinned by digest, which atlas
+	// checks once fetched.
+	Resolve(path, version string) ([]Source, error)
+}
+
+// Tagger is implemented by Fetchers able to list the versions of a
+// dependency. Update and "@channel" versions need it.
+type Tagger interface {
+	Tags(ctx context.Context, path string) ([]string, error)
+}
+
+// Dater is implemented by Fetchers able to tell when versions of a
+// dependency were tagged. The freshness report needs it.
+type Dater interface {
+	// TagTimes returns the time each of tags was made.
+	TagTimes(ctx context.Context, path string, tags []string) (map[string]time.Time, error)
+}
+
+// Committer is implemented by Fetchers able to find a commit of a
+// dependency, to pin it by pseudo-version. Adding a dependency at a commit
+// needs it.
+type Committer interface {
+	// Commit returns the full hash of the commit rev, a hash or a prefix
+	// of one, and the time it was made.
+	Commit(ctx context.Context, path, rev string) (hash string, at time.Time, err error)
+}
+
+var (
+	mu       sync.RWMutex
+	fetchers = map[string]Fetcher{}
+)
+
+// Register makes f fetch the dependencies whose path starts with host,
+// e.g. "corp.example" for "corp.example/team/dep". Registering a host
+// again replaces its Fetcher.
+func Register(host string, f Fetcher) {
+	mu.Lock()
+	defer mu.Unlock()
+	fetchers[host] = f
+}
+
+// Lookup returns the Fetcher registered for the host of path.
+func Lookup(path string) (Fetcher, bool) {
+	host, _, _ := strings.Cut(path, "/")
+	mu.RLock()
+	defer mu.RUnlock()
+	f, ok := fetchers[host]
+	return f, ok
+}
@@ -0,0 +1,206 @@
+// Package holonmd parses the YAML front-matter of HOLON.md files.
+//
+// Only the subset of YAML used by holon identities is supported: top-level
+// "key: value" pairs whose values are scalars, inline lists ([a, "b"]) or
+// block lists ("- item" lines). Nested mappings are skipped.
+package holonmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FrontMatter is the parsed front-matter of a HOLON.md.
+type FrontMatter struct {
+	// Name is "name", or "given_name family_name" for identity files.
+	Name string
+	// Summary is "summary", or the identity "motto".
+	Summary string
+	// Capabilities lists what the holon provides.
+	Capabilities []string
+	// Maintainers is "maintainers", or the identity "composer".
+	Maintainers []string
+	// Owner is "owner": the team or organization answering for the holon.
+	Owner string
+	// Contact is "contact": how to reach the owner, e.g. an address or URL.
+	Contact string
+
+	// Fields holds every top-level key: scalars as string, lists as []string.
+	Fields map[string]any
+}
+
+// Parse reads the front-matter of the HOLON.md at path.
+func Parse(path string) (*FrontMatter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBytes(data)
+}
+
+// ParseBytes parses the front-matter at the start of a HOLON.md. A
+// document without front-matter yields an empty FrontMatter.
+func ParseBytes(data []byte) (*FrontMatter, error) {
+	fm := &FrontMatter{Fields: map[string]any{}}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	started := false
+	var listKey string // key whose block list is being read
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+
+		if !started {
+			if line == "" {
+				continue
+			}
+			if line != "---" {
+				return fm, nil // no front-matter
+			}
+			started = true
+			continue
+		}
+		if line == "---" {
+			fm.derive()
+			return fm, nil
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		indented := raw[0] == ' ' || raw[0] == '\t'
+		if indented {
+			if listKey != "" && strings.HasPrefix(line, "- ") {
+				item := scalar(strings.TrimPrefix(line, "- "))
+				fm.Fields[listKey] = append(fm.Fields[listKey].([]string), item)
+			}
+			continue // nested mappings are not supported
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("front-matter line %d: expected key: value, got %q", lineNo, line)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		listKey = ""
+
+		switch {
+		case value == "":
+			listKey = key
+			fm.Fields[key] = []string{}
+		case strings.HasPrefix(value, "["):
+			fm.Fields[key] = inlineList(value)
+		default:
+			fm.Fields[key] = scalar(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if started {
+		return nil, fmt.Errorf("unterminated front-matter")
+	}
+	return fm, nil
+}
+
+// derive fills the well-known fields from the raw ones.
+func (fm *FrontMatter) derive() {
+	fm.Name = fm.String("name")
+	if fm.Name == "" {
+		fm.Name = strings.TrimSpace(fm.String("given_name") + " " + fm.String("family_name"))
+	}
+	fm.Summary = fm.String("summary")
+	if fm.Summary == "" {
+		fm.Summary = fm.String("motto")
+	}
+	fm.Capabilities = fm.List("capabilities")
+	fm.Maintainers = fm.List("maintainers")
+	if len(fm.Maintainers) == 0 && fm.String("composer") != "" {
+		fm.Maintainers = []string{fm.String("composer")}
+	}
+	fm.Owner = fm.String("owner")
+	fm.Contact = fm.String("contact")
+}
+
+// String returns a scalar field, or "" if it is missing or a list.
+func (fm *FrontMatter) String(key string) string {
+	s, _ := fm.Fields[key].(string)
+	return s
+}
+
+// List returns a list field. A scalar field is returned as a one-item list.
+func (fm *FrontMatter) List(key string) []string {
+	switch v := fm.Fields[key].(type) {
+	case []string:
+		return v
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	}
+	return nil
+}
+
+// scalar decodes a YAML scalar: quoted strings are unquoted, null and ~
+// become "", and trailing comments on plain values are dropped.
+func scalar(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) >= 2 && v[0] == '"' {
+		if end := strings.LastIndex(v, `"`); end > 0 {
+			if s, err := strconv.Unquote(v[:end+1]); err == nil {
+				return s
+			}
+		}
+	}
+	if len(v) >= 2 && v[0] == '\'' {
+		if end := strings.LastIndex(v, "'"); end > 0 {
+			return strings.ReplaceAll(v[1:end], "''", "'")
+		}
+	}
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = strings.TrimSpace(v[:i])
+	}
+	if v == "null" || v == "~" {
+		return ""
+	}
+	return v
+}
+
+// inlineList decodes "[a, "b", c]".
+func inlineList(v string) []string {
+	v = strings.TrimSpace(v)
+	if i := strings.LastIndex(v, "]"); i >= 0 {
+		v = v[:i]
+	}
+	v = strings.TrimPrefix(v, "[")
+
+	items := []string{}
+	var cur strings.Builder
+	var quote rune
+	for _, c := range v {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			cur.WriteRune(c)
+		case c == '"' || c == '\'':
+			quote = c
+			cur.WriteRune(c)
+		case c == ',':
+			items = append(items, scalar(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteRune(c)
+		}
+	}
+	if strings.TrimSpace(cur.String()) != "" {
+		items = append(items, scalar(cur.String()))
+	}
+	return items
+}
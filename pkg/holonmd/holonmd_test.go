@@ -0,0 +1,84 @@
+package holonmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/holonmd"
+)
+
+func TestParseIdentity(t *testing.T) {
+	// This holon's own HOLON.md uses the identity format.
+	fm, err := holonmd.Parse(filepath.Join("..", "..", "HOLON.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Name != "Rhizome Atlas" {
+		t.Errorf("Name = %q", fm.Name)
+	}
+	if fm.Summary != "Know what you need." {
+		t.Errorf("Summary = %q", fm.Summary)
+	}
+	if len(fm.Maintainers) != 1 || fm.Maintainers[0] != "B. ALTER" {
+		t.Errorf("Maintainers = %v", fm.Maintainers)
+	}
+	if aliases := fm.List("aliases"); len(aliases) != 2 || aliases[0] != "atlas" {
+		t.Errorf("aliases = %v", aliases)
+	}
+	if fm.String("binary_path") != "" {
+		t.Errorf("null should parse as empty, got %q", fm.String("binary_path"))
+	}
+}
+
+func TestParseExplicitFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "HOLON.md")
+	content := `---
+# identity
+name: "Dep Holon"
+summary: Does one thing. # well
+capabilities:
+  - storage.kv
+  - 'storage.blob'
+maintainers: [alice, "bob"]
+owner: platform
+contact: "platform@example.com"
+extra:
+  nested: ignored
+---
+
+# Dep Holon
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fm, err := holonmd.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Name != "Dep Holon" || fm.Summary != "Does one thing." {
+		t.Errorf("Name, Summary = %q, %q", fm.Name, fm.Summary)
+	}
+	if len(fm.Capabilities) != 2 || fm.Capabilities[1] != "storage.blob" {
+		t.Errorf("Capabilities = %v", fm.Capabilities)
+	}
+	if len(fm.Maintainers) != 2 || fm.Maintainers[1] != "bob" {
+		t.Errorf("Maintainers = %v", fm.Maintainers)
+	}
+	if fm.Owner != "platform" || fm.Contact != "platform@example.com" {
+		t.Errorf("Owner, Contact = %q, %q", fm.Owner, fm.Contact)
+	}
+}
+
+func TestParseWithoutFrontMatter(t *testing.T) {
+	fm, err := holonmd.ParseBytes([]byte("# Just a title\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Name != "" || len(fm.Fields) != 0 {
+		t.Errorf("expected empty front-matter, got %+v", fm)
+	}
+	if _, err := holonmd.ParseBytes([]byte("---\nname: x\n")); err == nil {
+		t.Error("expected error for unterminated front-matter")
+	}
+}
@@ -0,0 +1,186 @@
+package modfile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Resolution strategies, for the graph's dependencies required at several
+// versions.
+const (
+	// StrategyMVS selects the highest version required: the minimum
+	// version satisfying every requirement.
+	StrategyMVS = "mvs"
+	// StrategyHighest selects the highest version released with the same
+	// major version as the highest one required.
+	StrategyHighest = "highest"
+)
+
+// ValidateStrategy checks that s names a resolution strategy.
+func ValidateStrategy(s string) error {
+	if s != StrategyMVS && s != StrategyHighest {
+		return fmt.Errorf("unknown resolution strategy %q: want %s or %s", s, StrategyMVS, StrategyHighest)
+	}
+	return nil
+}
+
+// LockFile represents a parsed holon.lock file: the version the graph
+// selected for each dependency, direct or not, with the requirements it
+// was selected from. A dependency required at several major versions has
+// one selection per major: they coexist in the graph.
+type LockFile struct {
+	Strategy string
+	// With lists, sorted, the optional dependencies and capabilities the
+	// graph was resolved with.
+	With     []string
+	Selected []Selection
+}
+
+// Selection is the version selected for a dependency of the graph.
+type Selection struct {
+	Path    string
+	Version string
+	// RequiredBy lists the holons requiring Path, with the version each
+	// requires.
+	RequiredBy []Requirement
+}
+
+// Requirement is a holon's requirement of a dependency.
+type Requirement struct {
+	Holon   string
+	Version string
+}
+
+// Lookup returns the selection of path; the first, of the lowest major,
+// when several coexist.
+func (l *LockFile) Lookup(path string) (Selection, bool) {
+	for _, s := range l.Selected {
+		if s.Path == path {
+			return s, true
+		}
+	}
+	return Selection{}, false
+}
+
+// Matches reports whether l was resolved with strategy and with from the
+// requirements of mod, as they are: it records the requirements of mod
+// that resolution starts from, and no other. Pull and Vendor keep to the
+// selections of a lock that matches, rather than resolving again.
+func (l *LockFile) Matches(mod *ModFile, strategy string, with []string) bool {
+	if l.Strategy != strategy || !slices.Equal(l.With, SortedWith(with)) {
+		return false
+	}
+	var locked, required []string
+	for _, s := range l.Selected {
+		for _, r := range s.RequiredBy {
+			if r.Holon == mod.HolonPath {
+				locked = append(locked, s.Path+" "+r.Version)
+			}
+		}
+	}
+	for _, r := range mod.Require {
+		if r.Path != mod.HolonPath && mod.ResolvedPath(r.Path) == "" && r.Wanted(with) {
+			required = append(required, r.Path+" "+r.Version)
+		}
+	}
+	slices.Sort(locked)
+	slices.Sort(required)
+	return slices.Equal(locked, required)
+}
+
+// SortedWith returns with sorted, without duplicates, as LockFile.With
+// records it.
+func SortedWith(with []string) []string {
+	sorted := slices.Clone(with)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
+
+// Coexisting returns the paths selected at several major versions.
+func (l *LockFile) Coexisting() map[string]bool {
+	seen := map[string]bool{}
+	coexisting := map[string]bool{}
+	for _, s := range l.Selected {
+		if seen[s.Path] {
+			coexisting[s.Path] = true
+		}
+		seen[s.Path] = true
+	}
+	return coexisting
+}
+
+// ParseLock reads and parses a holon.lock file. A missing file is empty.
+func ParseLock(path string) (*LockFile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &LockFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &LockFile{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if strategy, ok := strings.CutPrefix(line, "strategy "); ok {
+			lock.Strategy = strategy
+			continue
+		}
+		if with, ok := strings.CutPrefix(line, "with "); ok {
+			lock.With = strings.Fields(with)
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid holon.lock line: %q", line)
+		}
+		// Indented lines are the requirements of the selection above.
+		if raw[0] == ' ' || raw[0] == '\t' {
+			if len(lock.Selected) == 0 {
+				return nil, fmt.Errorf("requirement before any selection: %q", line)
+			}
+			sel := &lock.Selected[len(lock.Selected)-1]
+			sel.RequiredBy = append(sel.RequiredBy, Requirement{Holon: parts[0], Version: parts[1]})
+			continue
+		}
+		lock.Selected = append(lock.Selected, Selection{Path: parts[0], Version: parts[1]})
+	}
+	return lock, scanner.Err()
+}
+
+// Format returns the content of the holon.lock file l represents.
+func (l *LockFile) Format() []byte {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "// Written by atlas pull: the version selected for each dependency,")
+	fmt.Fprintln(&b, "// under it the holons requiring it and the version each requires.")
+	fmt.Fprintf(&b, "strategy %s\n", l.Strategy)
+	if len(l.With) > 0 {
+		fmt.Fprintf(&b, "with %s\n", strings.Join(l.With, " "))
+	}
+	for _, s := range l.Selected {
+		fmt.Fprintf(&b, "\n%s %s\n", s.Path, s.Version)
+		for _, r := range s.RequiredBy {
+			fmt.Fprintf(&b, "    %s %s\n", r.Holon, r.Version)
+		}
+	}
+	return b.Bytes()
+}
+
+// Write serializes a LockFile to disk.
+func (l *LockFile) Write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, l.Format(), 0o644)
+}
@@ -0,0 +1,296 @@
+package modfile
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+)
+
+// SumConflict is a path@version recorded with different hashes of the same
+// algorithm by two holon.sum files.
+type SumConflict struct {
+	Path    string
+	Version string
+	Ours    string
+	Theirs  string
+}
+
+// MergeSum returns the union of two holon.sum files. An entry present in
+// both with the same algorithm must carry the same hash: every one that
+// does not is a genuine conflict, returned instead of a merged file.
+func MergeSum(ours, theirs *SumFile) (*SumFile, []SumConflict) {
+	merged := ours.Clone()
+	var conflicts []SumConflict
+	for _, e := range theirs.Entries {
+		alg, _, _ := SplitHash(e.Hash)
+		switch hash := merged.LookupAlgorithm(e.Path, e.Version, alg); hash {
+		case "":
+			merged.Entries = append(merged.Entries, e)
+		case e.Hash:
+		default:
+			conflicts = append(conflicts, SumConflict{
+				Path:    e.Path,
+				Version: e.Version,
+				Ours:    hash,
+				Theirs:  e.Hash,
+			})
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, conflicts
+	}
+	return merged, nil
+}
+
+// MergeMod merges two edits of a holon.mod structurally, as a git merge
+// driver would. base, their common ancestor, may be nil.
+//
+// Each directive and field is merged three-way: the side that changed it
+// from base wins, and both changing it differently is a conflict. A
+// require, replace or deprecated directive present on one side only is
+// kept, unless base shows that the other side removed it and this side
+// left it unchanged. A require kept at the same version on both sides has
+// its alias, group, optional flag and annotations merged, each annotation
+// on its own; when both sides require a path at different versions, the
+// higher semver wins along with that side's alias and annotations.
+// Differing holon paths, replace targets, deprecations of a version or
+// unordered versions (e.g. two digests) are conflicts.
+func MergeMod(base, ours, theirs *ModFile) (*ModFile, error) {
+	if base == nil {
+		base = &ModFile{}
+	}
+	if ours.HolonPath != theirs.HolonPath {
+		return nil, fmt.Errorf("holon path: ours %q, theirs %q", ours.HolonPath, theirs.HolonPath)
+	}
+	merged := &ModFile{HolonPath: ours.HolonPath}
+	var ok bool
+	// Flags cannot conflict: of two differing sides, one left base as is.
+	merged.Stable, _ = merge3(base.Stable, ours.Stable, theirs.Stable)
+	merged.Provenance, _ = merge3(base.Provenance, ours.Provenance, theirs.Provenance)
+	merged.TrustOnFirstUse, _ = merge3(base.TrustOnFirstUse, ours.TrustOnFirstUse, theirs.TrustOnFirstUse)
+	if merged.Resolve, ok = merge3(base.Resolve, ours.Resolve, theirs.Resolve); !ok {
+		return nil, fmt.Errorf("resolve: ours %q, theirs %q", ours.Resolve, theirs.Resolve)
+	}
+	if merged.VendorDir, ok = merge3(base.VendorDir, ours.VendorDir, theirs.VendorDir); !ok {
+		return nil, fmt.Errorf("vendor: ours %q, theirs %q", ours.VendorDir, theirs.VendorDir)
+	}
+	if merged.Registry, ok = merge3(base.Registry, ours.Registry, theirs.Registry); !ok {
+		return nil, fmt.Errorf("registry: ours %q, theirs %q", ours.Registry, theirs.Registry)
+	}
+	switch {
+	case slices.Equal(ours.Proxy, theirs.Proxy), slices.Equal(theirs.Proxy, base.Proxy):
+		merged.Proxy = ours.Proxy
+	case slices.Equal(ours.Proxy, base.Proxy):
+		merged.Proxy = theirs.Proxy
+	default:
+		return nil, fmt.Errorf("proxy: ours %q, theirs %q", ours.Proxy, theirs.Proxy)
+	}
+	if merged.Budget, ok = merge3(base.Budget, ours.Budget, theirs.Budget); !ok {
+		return nil, fmt.Errorf("budget: ours %s, theirs %s", FormatSize(ours.Budget), FormatSize(theirs.Budget))
+	}
+	if merged.TotalBudget, ok = merge3(base.TotalBudget, ours.TotalBudget, theirs.TotalBudget); !ok {
+		return nil, fmt.Errorf("budget total: ours %s, theirs %s", FormatSize(ours.TotalBudget), FormatSize(theirs.TotalBudget))
+	}
+
+	find := func(reqs []Require, path string) (Require, bool) {
+		i := slices.IndexFunc(reqs, func(r Require) bool { return r.Path == path })
+		if i < 0 {
+			return Require{}, false
+		}
+		return reqs[i], true
+	}
+
+	for _, o := range ours.Require {
+		t, ok := find(theirs.Require, o.Path)
+		if !ok {
+			if b, inBase := find(base.Require, o.Path); inBase && b.Version == o.Version {
+				continue // removed by theirs
+			}
+			merged.Require = append(merged.Require, o)
+			continue
+		}
+		switch {
+		case o.Version == t.Version:
+			b, _ := find(base.Require, o.Path)
+			r, err := mergeRequire(b, o, t)
+			if err != nil {
+				return nil, err
+			}
+			merged.Require = append(merged.Require, r)
+		case !orderable(o.Version, t.Version):
+			return nil, fmt.Errorf("require %s: ours %s, theirs %s", o.Path, o.Version, t.Version)
+		case semver.Compare(t.Version, o.Version) > 0:
+			merged.Require = append(merged.Require, t)
+		default:
+			merged.Require = append(merged.Require, o)
+		}
+	}
+	for _, t := range theirs.Require {
+		if _, ok := find(ours.Require, t.Path); ok {
+			continue
+		}
+		if b, inBase := find(base.Require, t.Path); inBase && b.Version == t.Version {
+			continue // removed by ours
+		}
+		merged.Require = append(merged.Require, t)
+	}
+
+	replaced := func(reps []Replace, old string) (Replace, bool) {
+		i := slices.IndexFunc(reps, func(r Replace) bool { return r.Old == old })
+		if i < 0 {
+			return Replace{}, false
+		}
+		return reps[i], true
+	}
+	for _, o := range ours.Replace {
+		t, ok := replaced(theirs.Replace, o.Old)
+		if !ok {
+			if b, inBase := replaced(base.Replace, o.Old); inBase && b.New == o.New {
+				continue // removed by theirs
+			}
+		} else if t.New != o.New {
+			return nil, fmt.Errorf("replace %s: ours %s, theirs %s", o.Old, o.New, t.New)
+		} else {
+			b, _ := replaced(base.Replace, o.Old)
+			meta, err := mergeMeta(b.Meta, o.Meta, t.Meta)
+			if err != nil {
+				return nil, fmt.Errorf("replace %s: %w", o.Old, err)
+			}
+			o.Meta = meta
+		}
+		merged.Replace = append(merged.Replace, o)
+	}
+	for _, t := range theirs.Replace {
+		if _, ok := replaced(ours.Replace, t.Old); ok {
+			continue
+		}
+		if b, inBase := replaced(base.Replace, t.Old); inBase && b.New == t.New {
+			continue // removed by ours
+		}
+		merged.Replace = append(merged.Replace, t)
+	}
+
+	deprecation := func(ds []Deprecation, version string) (Deprecation, bool) {
+		i := slices.IndexFunc(ds, func(d Deprecation) bool { return d.Version == version })
+		if i < 0 {
+			return Deprecation{}, false
+		}
+		return ds[i], true
+	}
+	for _, o := range ours.Deprecated {
+		t, ok := deprecation(theirs.Deprecated, o.Version)
+		if !ok {
+			if b, inBase := deprecation(base.Deprecated, o.Version); inBase && b == o {
+				continue // removed by theirs
+			}
+		} else if t != o {
+			return nil, fmt.Errorf("deprecated %s: ours %q, theirs %q", cmp.Or(o.Version, "holon"), o.String(), t.String())
+		}
+		merged.Deprecated = append(merged.Deprecated, o)
+	}
+	for _, t := range theirs.Deprecated {
+		if _, ok := deprecation(ours.Deprecated, t.Version); ok {
+			continue
+		}
+		if b, inBase := deprecation(base.Deprecated, t.Version); inBase && b == t {
+			continue // removed by ours
+		}
+		merged.Deprecated = append(merged.Deprecated, t)
+	}
+
+	if err := merged.checkAliases(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// merge3 merges a field edited by ours and theirs from base: the side that
+// changed it wins. ok is false when both changed it differently.
+func merge3[T comparable](base, ours, theirs T) (merged T, ok bool) {
+	switch {
+	case ours == theirs, theirs == base:
+		return ours, true
+	case ours == base:
+		return theirs, true
+	}
+	return ours, false
+}
+
+// mergeRequire merges the edits ours and theirs made to b, the require of
+// base (zero if none), keeping the same version.
+func mergeRequire(b, o, t Require) (Require, error) {
+	merged := o
+	var ok bool
+	if merged.Alias, ok = merge3(b.Alias, o.Alias, t.Alias); !ok {
+		return Require{}, fmt.Errorf("require %s: alias: ours %q, theirs %q", o.Path, o.Alias, t.Alias)
+	}
+	if merged.Group, ok = merge3(b.Group, o.Group, t.Group); !ok {
+		return Require{}, fmt.Errorf("require %s: group: ours %q, theirs %q", o.Path, o.Group, t.Group)
+	}
+	merged.Optional, _ = merge3(b.Optional, o.Optional, t.Optional)
+	meta, err := mergeMeta(b.Meta, o.Meta, t.Meta)
+	if err != nil {
+		return Require{}, fmt.Errorf("require %s: %w", o.Path, err)
+	}
+	merged.Meta = meta
+	return merged, nil
+}
+
+// mergeMeta merges the annotations of a line, each key as merge3 does: an
+// annotation added, changed or removed on one side only is taken.
+func mergeMeta(base, ours, theirs map[string]string) (map[string]string, error) {
+	type value struct {
+		v   string
+		set bool
+	}
+	var keys []string
+	for _, m := range []map[string]string{base, ours, theirs} {
+		for k := range m {
+			if !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+	slices.Sort(keys)
+	var merged map[string]string
+	for _, k := range keys {
+		b, bSet := base[k]
+		o, oSet := ours[k]
+		t, tSet := theirs[k]
+		v, ok := merge3(value{b, bSet}, value{o, oSet}, value{t, tSet})
+		if !ok {
+			return nil, fmt.Errorf("annotation %s: ours %q, theirs %q", k, o, t)
+		}
+		if v.set {
+			if merged == nil {
+				merged = map[string]string{}
+			}
+			merged[k] = v.v
+		}
+	}
+	return merged, nil
+}
+
+// orderable reports whether two versions can be ordered by semver.
+func orderable(a, b string) bool {
+	_, _, _, okA := semver.Parse(a)
+	_, _, _, okB := semver.Parse(b)
+	return okA && okB
+}
+
+// checkAliases reports an alias claimed by two requires.
+func (m *ModFile) checkAliases() error {
+	seen := map[string]string{}
+	for _, r := range m.Require {
+		if r.Alias == "" {
+			continue
+		}
+		if other, dup := seen[r.Alias]; dup {
+			return fmt.Errorf("alias %q: used by %s and %s", r.Alias, other, r.Path)
+		}
+		seen[r.Alias] = r.Path
+	}
+	return nil
+}
@@ -4,45 +4,360 @@ package modfile
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
+	"maps"
+	"math"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // ModFile represents a parsed holon.mod file.
 type ModFile struct {
 	HolonPath string
-	Require   []Require
-	Replace   []Replace
+	// Stable is set by the "stable" directive and forbids prerelease
+	// versions (e.g. v1.2.0-beta.1) in Require.
+	Stable bool
+	// Provenance is set by the "provenance" directive and requires every
+	// fetched dependency to come with a provenance attestation.
+	Provenance bool
+	// TrustOnFirstUse is set by the "tofu" directive: the first Add of a
+	// dependency must be confirmed, and every later fetch of it must
+	// match what was trusted then.
+	TrustOnFirstUse bool
+	// Resolve is set by the "resolve <strategy>" directive and chooses how
+	// disagreeing requirements of the graph are resolved: StrategyMVS
+	// when empty, or StrategyHighest.
+	Resolve string
+	// VendorDir is set by the "vendor <dir>" directive: where Vendor copies
+	// the dependencies, relative to holon.mod, instead of DefaultVendorDir.
+	VendorDir string
+	// Registry is set by the "registry <url>" directive: the URL prefix
+	// serving <prefix>/<dep-path> as a git repository that the project
+	// fetches its dependencies from first.
+	Registry string
+	// Proxy is set by the "proxy <url>[,<url>...]" directive: the mirrors
+	// the project falls back to, as ATLAS_PROXY lists them.
+	Proxy []string
+	// Budget is set by the "budget <size>" directive: the most a snapshot
+	// of each dependency may weigh, in bytes, unless its require line sets
+	// its own (see Require.Budget). 0 for none.
+	Budget int64
+	// TotalBudget is set by the "budget total <size>" directive: the most
+	// the snapshots of the whole closure may weigh together, in bytes. 0
+	// for none.
+	TotalBudget int64
+	// Deprecated holds the "deprecated" directives, by which the authors
+	// of the holon retire it or some of its versions. Consumers read them
+	// from its latest release (see DeprecationOf).
+	Deprecated []Deprecation
+	Require    []Require
+	Replace    []Replace
+}
+
+// Deprecation is a "deprecated [<version>] [=> <successor>] [// <message>]"
+// directive.
+type Deprecation struct {
+	// Version is the version deprecated; empty for the whole holon.
+	Version string
+	// Successor is what to use instead, if the authors suggest anything:
+	// a version, or a holon path with an optional @version.
+	Successor string
+	// Message is the text of the trailing comment.
+	Message string
 }
 
 // Require is a single dependency declaration.
 type Require struct {
 	Path    string
 	Version string
+	Alias   string // optional short name, from "<path> <version> as <alias>"
+	// Group is the group of the "require <group> (" block declaring the
+	// dependency, e.g. "dev" or "test"; empty for DefaultGroup.
+	Group string
+	// Optional is set by a trailing "optional" on the line: the dependency
+	// is only fetched when asked for, by name or by a capability it
+	// provides (see Provides).
+	Optional bool
+
+	// Meta holds the key=value annotations of the line's trailing
+	// comment, e.g. "// scope=test owner=platform". Nil when there are none.
+	Meta map[string]string
+
+	// Line is the line declaring the dependency in the parsed file,
+	// counted from 1; 0 when it was not parsed.
+	Line int
+}
+
+// Provides returns the capabilities r declares with its "provides"
+// annotation, a comma-separated list, e.g. "// provides=tracing,metrics".
+func (r Require) Provides() []string {
+	if r.Meta["provides"] == "" {
+		return nil
+	}
+	return strings.Split(r.Meta["provides"], ",")
+}
+
+// Wanted reports whether r is to be fetched when with names the optional
+// dependencies asked for, by path, alias or capability provided. Only
+// optional dependencies can be left out.
+func (r Require) Wanted(with []string) bool {
+	if !r.Optional {
+		return true
+	}
+	return slices.ContainsFunc(with, func(name string) bool {
+		return name == r.Path || r.Alias != "" && name == r.Alias || slices.Contains(r.Provides(), name)
+	})
+}
+
+// DefaultVendorDir is where Vendor copies the dependencies of a holon.mod
+// without a vendor directive, relative to it.
+const DefaultVendorDir = ".holon"
+
+// ValidateVendorDir checks that dir can be a vendor directory: a relative
+// slash-separated path inside the holon.
+func ValidateVendorDir(dir string) error {
+	if dir == "" || path.IsAbs(dir) || filepath.IsAbs(dir) || path.Clean(dir) != dir ||
+		dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+		return fmt.Errorf("invalid vendor directory %q: want a clean relative path inside the holon", dir)
+	}
+	return nil
+}
+
+// ValidateSourceURL checks that url can be a registry or proxy: an
+// absolute URL without spaces, e.g. https://holons.example.com,
+// ssh://git@git.example.com/holons or file:///srv/holons, or an scp-like
+// SSH address, e.g. git@git.example.com:holons.
+func ValidateSourceURL(url string) error {
+	scheme, rest, ok := strings.Cut(url, "://")
+	if !ok {
+		// An scp-like address: [<user>@]<host>:<path>.
+		scheme, rest, ok = strings.Cut(url, ":")
+		ok = ok && len(scheme) > 1 && !strings.Contains(scheme, "/")
+	}
+	if !ok || scheme == "" || rest == "" || strings.ContainsAny(url, " \t,") {
+		return fmt.Errorf("invalid source URL %q: want <scheme>://<host>[/<path>] or [<user>@]<host>:<path>", url)
+	}
+	return nil
+}
+
+// DefaultGroup is the group of the dependencies declared in the plain
+// "require (" block: those the holon needs at run time.
+const DefaultGroup = "runtime"
+
+// InGroup reports whether r belongs to group.
+func (r Require) InGroup(group string) bool {
+	return r.Group == group || r.Group == "" && group == DefaultGroup
+}
+
+// ValidateGroup checks that group can name a require block.
+func ValidateGroup(group string) error {
+	if group == "" || strings.ContainsAny(group, " \t()/") {
+		return fmt.Errorf("invalid group %q", group)
+	}
+	return nil
+}
+
+// Matches reports whether r carries every key=value pair of filter.
+func (r Require) Matches(filter map[string]string) bool {
+	for k, v := range filter {
+		if r.Meta[k] != v {
+			return false
+		}
+	}
+	return true
 }
 
-// Replace is a local path override for a dependency.
+// Replace overrides where a dependency comes from: a local directory, or
+// another path it is fetched from. Old may end in "/*" to replace every
+// path under it, and New then ends in "/*", standing for the rest of the
+// path.
 type Replace struct {
-	Old       string // remote path
-	LocalPath string // local directory (relative to holon.mod)
+	Old  string // remote path, or path pattern
+	New  string // local directory (relative to holon.mod) or remote path
+	Line int    // in the parsed file, from 1; 0 when it was not parsed
+	// Meta holds the key=value annotations of the line's trailing
+	// comment, as Require.Meta does. Nil when there are none.
+	Meta map[string]string
+}
+
+// UntilKey is the annotation giving the last day, as YYYY-MM-DD, a require
+// or replace is meant to stay, e.g. "// until=2025-06-01": the date a
+// temporary pin or replace is due for review.
+const UntilKey = "until"
+
+// untilLayout is the format of the until annotation.
+const untilLayout = "2006-01-02"
+
+// Until returns the date of the until annotation of r, if any.
+func (r Require) Until() (time.Time, bool) { return until(r.Meta) }
+
+// Until returns the date of the until annotation of r, if any.
+func (r Replace) Until() (time.Time, bool) { return until(r.Meta) }
+
+// Expired reports whether the until date has passed at now: now is a
+// day after it or later.
+func Expired(until, now time.Time) bool {
+	return now.Format(untilLayout) > until.Format(untilLayout)
+}
+
+func until(meta map[string]string) (time.Time, bool) {
+	t, err := time.Parse(untilLayout, meta[UntilKey])
+	return t, err == nil
+}
+
+// validateUntil checks the until annotation of meta, if any.
+func validateUntil(meta map[string]string) error {
+	v, ok := meta[UntilKey]
+	if !ok {
+		return nil
+	}
+	if _, err := time.Parse(untilLayout, v); err != nil {
+		return fmt.Errorf("invalid %s=%q: want YYYY-MM-DD", UntilKey, v)
+	}
+	return nil
+}
+
+// BudgetKey is the annotation setting the size budget of one dependency,
+// e.g. "// budget=8M", overriding the budget directive.
+const BudgetKey = "budget"
+
+// Budget returns the size budget of the budget annotation of r, if any.
+func (r Require) Budget() (int64, bool) {
+	size, err := ParseSize(r.Meta[BudgetKey])
+	return size, err == nil && size > 0
+}
+
+// BudgetOf returns the size budget of the dependency at depPath, which
+// may be the path a require is replaced by: that of its require line,
+// else the budget directive's. 0 for none.
+func (m *ModFile) BudgetOf(depPath string) int64 {
+	for _, r := range m.Require {
+		if r.Path == depPath || m.SourcePath(r.Path) == depPath {
+			if size, ok := r.Budget(); ok {
+				return size
+			}
+		}
+	}
+	return m.Budget
+}
+
+// ParseSize parses a size in bytes, with an optional k, M or G suffix
+// (powers of 1024), e.g. "512k" or "2M".
+func ParseSize(s string) (int64, error) {
+	num, mult := s, int64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'k', 'K':
+			mult = 1 << 10
+		case 'm', 'M':
+			mult = 1 << 20
+		case 'g', 'G':
+			mult = 1 << 30
+		}
+	}
+	if mult > 1 {
+		num = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/mult {
+		return 0, fmt.Errorf("invalid size %q: want bytes, e.g. 512k or 2M", s)
+	}
+	return n * mult, nil
+}
+
+// FormatSize renders size as ParseSize reads it, with the largest suffix
+// dividing it.
+func FormatSize(size int64) string {
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"G", 1 << 30}, {"M", 1 << 20}, {"k", 1 << 10}} {
+		if size >= u.mult && size%u.mult == 0 {
+			return strconv.FormatInt(size/u.mult, 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(size, 10)
+}
+
+// validateBudget checks the budget annotation of meta, if any.
+func validateBudget(meta map[string]string) error {
+	v, ok := meta[BudgetKey]
+	if !ok {
+		return nil
+	}
+	if _, err := ParseSize(v); err != nil {
+		return fmt.Errorf("invalid %s=%q: %w", BudgetKey, v, err)
+	}
+	return nil
+}
+
+// Local reports whether r replaces with a local directory: New starts
+// with ./ or ../, or is absolute.
+func (r Replace) Local() bool {
+	return r.New == "." || r.New == ".." || strings.HasPrefix(r.New, "./") ||
+		strings.HasPrefix(r.New, "../") || filepath.IsAbs(r.New)
+}
+
+// Match returns what r replaces depPath with, the rest of depPath under a
+// wildcard Old substituted for the wildcard of New.
+func (r Replace) Match(depPath string) (string, bool) {
+	prefix, wild := strings.CutSuffix(r.Old, "/*")
+	if !wild {
+		return r.New, depPath == r.Old
+	}
+	rest, ok := strings.CutPrefix(depPath, prefix+"/")
+	if !ok || rest == "" {
+		return "", false
+	}
+	if base, ok := strings.CutSuffix(r.New, "/*"); ok {
+		if r.Local() {
+			return filepath.Join(base, filepath.FromSlash(rest)), true
+		}
+		return base + "/" + rest, true
+	}
+	return r.New, true
 }
 
 // Parse reads and parses a holon.mod file.
 func Parse(path string) (*ModFile, error) {
-	f, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+	return ParseBytes(data)
+}
+
+// ParseError is the error ParseBytes returns for an invalid line.
+type ParseError struct {
+	Line int // counted from 1
+	Err  error
+}
+
+func (e *ParseError) Error() string { return fmt.Sprintf("line %d: %v", e.Line, e.Err) }
+
+func (e *ParseError) Unwrap() error { return e.Err }
 
+// ParseBytes parses the contents of a holon.mod file. An invalid line
+// fails with a *ParseError.
+func ParseBytes(data []byte) (*ModFile, error) {
 	mod := &ModFile{}
-	scanner := bufio.NewScanner(f)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	var inBlock string // "require" or "replace"
+	var group string   // of the require block
+	lineNo := 0
+	invalid := func(err error) (*ModFile, error) {
+		return nil, &ParseError{Line: lineNo, Err: err}
+	}
 
 	for scanner.Scan() {
+		lineNo++
 		line := strings.TrimSpace(scanner.Text())
 
 		// Skip empty lines and comments
@@ -55,7 +370,14 @@ func Parse(path string) (*ModFile, error) {
 			inBlock = ""
 			continue
 		}
-		if line == "require (" {
+		if f := strings.Fields(line); (len(f) == 2 || len(f) == 3) && f[0] == "require" && f[len(f)-1] == "(" {
+			group = ""
+			if len(f) == 3 && f[1] != DefaultGroup {
+				if err := ValidateGroup(f[1]); err != nil {
+					return invalid(err)
+				}
+				group = f[1]
+			}
 			inBlock = "require"
 			continue
 		}
@@ -70,31 +392,201 @@ func Parse(path string) (*ModFile, error) {
 			continue
 		}
 
+		// Stable directive
+		if line == "stable" {
+			mod.Stable = true
+			continue
+		}
+
+		// Provenance directive
+		if line == "provenance" {
+			mod.Provenance = true
+			continue
+		}
+
+		// Trust on first use directive
+		if line == "tofu" {
+			mod.TrustOnFirstUse = true
+			continue
+		}
+
+		// Resolve directive
+		if strategy, ok := strings.CutPrefix(line, "resolve "); ok {
+			if err := ValidateStrategy(strategy); err != nil {
+				return invalid(err)
+			}
+			mod.Resolve = strategy
+			continue
+		}
+
+		// Vendor directive
+		if vdir, ok := strings.CutPrefix(line, "vendor "); ok {
+			if err := ValidateVendorDir(vdir); err != nil {
+				return invalid(err)
+			}
+			mod.VendorDir = vdir
+			continue
+		}
+
+		// Registry and proxy directives
+		if url, ok := strings.CutPrefix(line, "registry "); ok {
+			if err := ValidateSourceURL(url); err != nil {
+				return invalid(err)
+			}
+			mod.Registry = strings.TrimRight(url, "/")
+			continue
+		}
+		if urls, ok := strings.CutPrefix(line, "proxy "); ok {
+			mod.Proxy = nil
+			for _, url := range strings.Split(urls, ",") {
+				url = strings.TrimSpace(url)
+				if err := ValidateSourceURL(url); err != nil {
+					return invalid(err)
+				}
+				mod.Proxy = append(mod.Proxy, strings.TrimRight(url, "/"))
+			}
+			continue
+		}
+
+		// Budget directives
+		if budget, ok := strings.CutPrefix(line, "budget "); ok {
+			total, isTotal := strings.CutPrefix(budget, "total ")
+			size, err := ParseSize(strings.TrimSpace(total))
+			if err != nil {
+				return invalid(err)
+			}
+			if isTotal {
+				mod.TotalBudget = size
+			} else {
+				mod.Budget = size
+			}
+			continue
+		}
+
+		// Deprecated directive
+		if rest, ok := strings.CutPrefix(line+" ", "deprecated "); ok {
+			d, err := parseDeprecation(rest)
+			if err != nil {
+				return invalid(err)
+			}
+			mod.Deprecated = append(mod.Deprecated, d)
+			continue
+		}
+
 		// Inside a block
 		switch inBlock {
 		case "require":
+			line, comment, _ := strings.Cut(line, "//")
 			parts := strings.Fields(line)
-			if len(parts) != 2 {
-				return nil, fmt.Errorf("invalid require line: %q", line)
+			req := Require{Group: group, Meta: parseMeta(comment), Line: lineNo}
+			if err := validateUntil(req.Meta); err != nil {
+				return invalid(fmt.Errorf("invalid require line: %q: %w", line, err))
 			}
-			mod.Require = append(mod.Require, Require{Path: parts[0], Version: parts[1]})
+			if err := validateBudget(req.Meta); err != nil {
+				return invalid(fmt.Errorf("invalid require line: %q: %w", line, err))
+			}
+			if len(parts) > 2 && parts[len(parts)-1] == "optional" {
+				req.Optional = true
+				parts = parts[:len(parts)-1]
+			}
+			switch {
+			case len(parts) == 2:
+				req.Path, req.Version = parts[0], parts[1]
+			case len(parts) == 4 && parts[2] == "as":
+				req.Path, req.Version, req.Alias = parts[0], parts[1], parts[3]
+				if err := ValidateAlias(req.Alias); err != nil {
+					return invalid(fmt.Errorf("invalid require line: %q: %w", line, err))
+				}
+				if _, dup := mod.RequireByName(req.Alias); dup {
+					return invalid(fmt.Errorf("duplicate alias %q", req.Alias))
+				}
+			default:
+				return invalid(fmt.Errorf("invalid require line: %q", line))
+			}
+			mod.Require = append(mod.Require, req)
 
 		case "replace":
-			// Format: <old> => <local>
+			// Format: <old> => <local|path>, either side may end in /*
+			line, comment, _ := strings.Cut(line, "//")
 			parts := strings.SplitN(line, " => ", 2)
 			if len(parts) != 2 {
-				return nil, fmt.Errorf("invalid replace line: %q", line)
+				return invalid(fmt.Errorf("invalid replace line: %q", line))
+			}
+			r := Replace{
+				Old:  strings.TrimSpace(parts[0]),
+				New:  strings.TrimSpace(parts[1]),
+				Line: lineNo,
+				Meta: parseMeta(comment),
 			}
-			mod.Replace = append(mod.Replace, Replace{
-				Old:       strings.TrimSpace(parts[0]),
-				LocalPath: strings.TrimSpace(parts[1]),
-			})
+			if err := validateUntil(r.Meta); err != nil {
+				return invalid(fmt.Errorf("invalid replace line: %q: %w", line, err))
+			}
+			if strings.HasSuffix(r.New, "/*") && !strings.HasSuffix(r.Old, "/*") {
+				return invalid(fmt.Errorf("invalid replace line: %q: wildcard target needs a wildcard path", line))
+			}
+			if strings.Contains(strings.TrimSuffix(r.Old, "/*"), "*") || strings.Contains(strings.TrimSuffix(r.New, "/*"), "*") {
+				return invalid(fmt.Errorf("invalid replace line: %q: * is only allowed as the last element", line))
+			}
+			mod.Replace = append(mod.Replace, r)
 		}
 	}
 
 	return mod, scanner.Err()
 }
 
+// parseDeprecation parses what follows "deprecated" on a directive line.
+func parseDeprecation(rest string) (Deprecation, error) {
+	rest, message, _ := strings.Cut(rest, "//")
+	d := Deprecation{Message: strings.TrimSpace(message)}
+	target, successor, hasSuccessor := strings.Cut(rest, "=>")
+	switch f := strings.Fields(target); len(f) {
+	case 0:
+	case 1:
+		d.Version = f[0]
+	default:
+		return Deprecation{}, fmt.Errorf("invalid deprecated directive: want deprecated [<version>] [=> <successor>] [// <message>]")
+	}
+	if hasSuccessor {
+		f := strings.Fields(successor)
+		if len(f) != 1 {
+			return Deprecation{}, fmt.Errorf("invalid deprecated directive: want one successor after =>")
+		}
+		d.Successor = f[0]
+	}
+	return d, nil
+}
+
+// String formats d as its directive line.
+func (d Deprecation) String() string {
+	line := "deprecated"
+	if d.Version != "" {
+		line += " " + d.Version
+	}
+	if d.Successor != "" {
+		line += " => " + d.Successor
+	}
+	if d.Message != "" {
+		line += " // " + d.Message
+	}
+	return line
+}
+
+// DeprecationOf returns the deprecation of version m declares: that of
+// the version, else that of the whole holon.
+func (m *ModFile) DeprecationOf(version string) (Deprecation, bool) {
+	for _, d := range m.Deprecated {
+		if d.Version != "" && d.Version == version {
+			return d, true
+		}
+	}
+	for _, d := range m.Deprecated {
+		if d.Version == "" {
+			return d, true
+		}
+	}
+	return Deprecation{}, false
+}
+
 // Write serializes a ModFile to disk.
 func (m *ModFile) Write(path string) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
@@ -107,12 +599,60 @@ func (m *ModFile) Write(path string) error {
 	defer f.Close()
 
 	fmt.Fprintf(f, "holon %s\n", m.HolonPath)
+	if m.Stable {
+		fmt.Fprintln(f, "stable")
+	}
+	if m.Provenance {
+		fmt.Fprintln(f, "provenance")
+	}
+	if m.TrustOnFirstUse {
+		fmt.Fprintln(f, "tofu")
+	}
+	if m.Resolve != "" {
+		fmt.Fprintf(f, "resolve %s\n", m.Resolve)
+	}
+	if m.VendorDir != "" {
+		fmt.Fprintf(f, "vendor %s\n", m.VendorDir)
+	}
+	if m.Registry != "" {
+		fmt.Fprintf(f, "registry %s\n", m.Registry)
+	}
+	if len(m.Proxy) > 0 {
+		fmt.Fprintf(f, "proxy %s\n", strings.Join(m.Proxy, ","))
+	}
+	if m.Budget > 0 {
+		fmt.Fprintf(f, "budget %s\n", FormatSize(m.Budget))
+	}
+	if m.TotalBudget > 0 {
+		fmt.Fprintf(f, "budget total %s\n", FormatSize(m.TotalBudget))
+	}
+	for _, d := range m.Deprecated {
+		fmt.Fprintln(f, d)
+	}
 
-	if len(m.Require) > 0 {
+	// One require block per group, the default one first.
+	for _, group := range m.Groups() {
 		fmt.Fprintln(f)
-		fmt.Fprintln(f, "require (")
+		if group == DefaultGroup {
+			fmt.Fprintln(f, "require (")
+		} else {
+			fmt.Fprintf(f, "require %s (\n", group)
+		}
 		for _, r := range m.Require {
-			fmt.Fprintf(f, "    %s %s\n", r.Path, r.Version)
+			if !r.InGroup(group) {
+				continue
+			}
+			line := r.Path + " " + r.Version
+			if r.Alias != "" {
+				line += " as " + r.Alias
+			}
+			if r.Optional {
+				line += " optional"
+			}
+			if len(r.Meta) > 0 {
+				line += " // " + formatMeta(r.Meta)
+			}
+			fmt.Fprintf(f, "    %s\n", line)
 		}
 		fmt.Fprintln(f, ")")
 	}
@@ -121,7 +661,11 @@ func (m *ModFile) Write(path string) error {
 		fmt.Fprintln(f)
 		fmt.Fprintln(f, "replace (")
 		for _, r := range m.Replace {
-			fmt.Fprintf(f, "    %s => %s\n", r.Old, r.LocalPath)
+			line := r.Old + " => " + r.New
+			if len(r.Meta) > 0 {
+				line += " // " + formatMeta(r.Meta)
+			}
+			fmt.Fprintf(f, "    %s\n", line)
 		}
 		fmt.Fprintln(f, ")")
 	}
@@ -129,6 +673,26 @@ func (m *ModFile) Write(path string) error {
 	return nil
 }
 
+// Clone returns a deep copy of m.
+func (m *ModFile) Clone() *ModFile {
+	c := *m
+	c.Require = append([]Require(nil), m.Require...)
+	for i, r := range c.Require {
+		if r.Meta != nil {
+			c.Require[i].Meta = maps.Clone(r.Meta)
+		}
+	}
+	c.Proxy = slices.Clone(m.Proxy)
+	c.Deprecated = slices.Clone(m.Deprecated)
+	c.Replace = append([]Replace(nil), m.Replace...)
+	for i, r := range c.Replace {
+		if r.Meta != nil {
+			c.Replace[i].Meta = maps.Clone(r.Meta)
+		}
+	}
+	return &c
+}
+
 // AddRequire adds or updates a dependency. Returns true if it was added
 // (false if updated).
 func (m *ModFile) AddRequire(path, version string) bool {
@@ -142,6 +706,121 @@ func (m *ModFile) AddRequire(path, version string) bool {
 	return true
 }
 
+// Groups returns the groups of the dependencies of m: DefaultGroup first
+// if any dependency is in it, then the others sorted.
+func (m *ModFile) Groups() []string {
+	var groups []string
+	for _, r := range m.Require {
+		if r.Group != "" && !slices.Contains(groups, r.Group) {
+			groups = append(groups, r.Group)
+		}
+	}
+	sort.Strings(groups)
+	if slices.ContainsFunc(m.Require, func(r Require) bool { return r.Group == "" }) {
+		groups = append([]string{DefaultGroup}, groups...)
+	}
+	return groups
+}
+
+// OnlyGroup returns a copy of m requiring only the dependencies in group.
+func (m *ModFile) OnlyGroup(group string) *ModFile {
+	c := m.Clone()
+	c.Require = slices.DeleteFunc(c.Require, func(r Require) bool { return !r.InGroup(group) })
+	return c
+}
+
+// SetMeta replaces the annotations of the dependency at path. Keys and
+// values must not contain spaces or '='.
+func (m *ModFile) SetMeta(path string, meta map[string]string) error {
+	for k, v := range meta {
+		if k == "" || strings.ContainsAny(k, " \t=") || strings.ContainsAny(v, " \t=") {
+			return fmt.Errorf("invalid annotation %q=%q", k, v)
+		}
+	}
+	for i, r := range m.Require {
+		if r.Path == path {
+			m.Require[i].Meta = meta
+			return nil
+		}
+	}
+	return fmt.Errorf("dependency %q not found", path)
+}
+
+// parseMeta extracts key=value pairs from a require line comment. Words
+// that are not key=value pairs are ignored.
+func parseMeta(comment string) map[string]string {
+	var meta map[string]string
+	for _, word := range strings.Fields(comment) {
+		k, v, ok := strings.Cut(word, "=")
+		if !ok || k == "" {
+			continue
+		}
+		if meta == nil {
+			meta = make(map[string]string)
+		}
+		meta[k] = v
+	}
+	return meta
+}
+
+// formatMeta renders annotations as sorted key=value pairs.
+func formatMeta(meta map[string]string) string {
+	pairs := make([]string, 0, len(meta))
+	for _, k := range slices.Sorted(maps.Keys(meta)) {
+		pairs = append(pairs, k+"="+meta[k])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// RequireByName finds a dependency by path or by alias.
+func (m *ModFile) RequireByName(name string) (Require, bool) {
+	for _, r := range m.Require {
+		if r.Path == name || (r.Alias != "" && r.Alias == name) {
+			return r, true
+		}
+	}
+	return Require{}, false
+}
+
+// SetAlias gives the dependency at path a short name, or clears it when
+// alias is empty. It fails if the dependency is missing, the alias is
+// malformed, or another dependency already uses it.
+func (m *ModFile) SetAlias(path, alias string) error {
+	if alias != "" {
+		if err := ValidateAlias(alias); err != nil {
+			return err
+		}
+		if other, ok := m.RequireByName(alias); ok && other.Path != path {
+			return fmt.Errorf("alias %q already used by %s", alias, other.Path)
+		}
+	}
+	for i, r := range m.Require {
+		if r.Path == path {
+			m.Require[i].Alias = alias
+			return nil
+		}
+	}
+	return fmt.Errorf("dependency %q not found", path)
+}
+
+// ValidateAlias checks that an alias is a short name: letters, digits,
+// '-' and '_', starting with a letter. Aliases never contain '/' or '.',
+// so they cannot be mistaken for a dependency path.
+func ValidateAlias(alias string) error {
+	if alias == "" {
+		return fmt.Errorf("empty alias")
+	}
+	for i, c := range alias {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '-' || c == '_'):
+		default:
+			return fmt.Errorf("invalid alias %q", alias)
+		}
+	}
+	return nil
+}
+
 // RemoveRequire removes a dependency by path. Returns true if found.
 func (m *ModFile) RemoveRequire(path string) bool {
 	for i, r := range m.Require {
@@ -153,24 +832,59 @@ func (m *ModFile) RemoveRequire(path string) bool {
 	return false
 }
 
-// ResolvedPath returns the local path for a dependency if a replace
-// directive exists, otherwise empty string.
-func (m *ModFile) ResolvedPath(depPath string) string {
+// Replacement returns the replace directive matching depPath and what it
+// replaces it with. An exact directive wins over a wildcard one, and a
+// longer wildcard over a shorter one.
+func (m *ModFile) Replacement(depPath string) (Replace, string, bool) {
+	var best Replace
+	var target string
 	for _, r := range m.Replace {
+		t, ok := r.Match(depPath)
+		if !ok {
+			continue
+		}
 		if r.Old == depPath {
-			return r.LocalPath
+			return r, t, true
 		}
+		if len(r.Old) > len(best.Old) {
+			best, target = r, t
+		}
+	}
+	return best, target, best.Old != ""
+}
+
+// ResolvedPath returns the local path for a dependency if a replace
+// directive replaces it with a local directory, otherwise empty string.
+func (m *ModFile) ResolvedPath(depPath string) string {
+	if r, target, ok := m.Replacement(depPath); ok && r.Local() {
+		return target
 	}
 	return ""
 }
 
+// SourcePath returns the path a dependency is fetched from: the remote
+// path a replace directive replaces it with, otherwise depPath.
+func (m *ModFile) SourcePath(depPath string) string {
+	if r, target, ok := m.Replacement(depPath); ok && !r.Local() {
+		return target
+	}
+	return depPath
+}
+
 // --- holon.sum ---
 
 // SumEntry represents one line in holon.sum.
 type SumEntry struct {
 	Path    string // e.g. "github.com/org/dep"
 	Version string // e.g. "v1.2.0" or "v1.2.0/HOLON.md"
-	Hash    string // e.g. "h1:abc123..."
+	Hash    string // e.g. "h1:abc123...", prefixed by its algorithm
+}
+
+// SplitHash splits a holon.sum hash into the name of its algorithm and
+// its digest: "h1:abc" yields "h1" and "abc".
+func SplitHash(hash string) (algorithm, digest string, ok bool) {
+	algorithm, digest, ok = strings.Cut(hash, ":")
+	return algorithm, digest, ok && algorithm != "" && digest != ""
 }
 
 // SumFile represents a parsed holon.sum.
@@ -200,6 +914,9 @@ func ParseSum(path string) (*SumFile, error) {
 		if len(parts) != 3 {
 			return nil, fmt.Errorf("invalid holon.sum line: %q", line)
 		}
+		if _, _, ok := SplitHash(parts[2]); !ok {
+			return nil, fmt.Errorf("invalid holon.sum line: %q: hash has no algorithm prefix", line)
+		}
 		sum.Entries = append(sum.Entries, SumEntry{
 			Path:    parts[0],
 			Version: parts[1],
@@ -225,7 +942,10 @@ func (s *SumFile) Write(path string) error {
 		if s.Entries[i].Path != s.Entries[j].Path {
 			return s.Entries[i].Path < s.Entries[j].Path
 		}
-		return s.Entries[i].Version < s.Entries[j].Version
+		if s.Entries[i].Version != s.Entries[j].Version {
+			return s.Entries[i].Version < s.Entries[j].Version
+		}
+		return s.Entries[i].Hash < s.Entries[j].Hash
 	})
 
 	for _, e := range s.Entries {
@@ -234,11 +954,18 @@ func (s *SumFile) Write(path string) error {
 	return nil
 }
 
+// Clone returns a deep copy of s.
+func (s *SumFile) Clone() *SumFile {
+	return &SumFile{Entries: append([]SumEntry(nil), s.Entries...)}
+}
+
 // Set adds or updates an entry. If an entry with the same path+version
-// exists, it is replaced.
+// and hash algorithm exists, it is replaced; entries of other algorithms
+// are kept, so that a holon.sum can be read by tools knowing either.
 func (s *SumFile) Set(path, version, hash string) {
+	alg, _, _ := SplitHash(hash)
 	for i, e := range s.Entries {
-		if e.Path == path && e.Version == version {
+		if e.Path == path && e.Version == version && sameAlgorithm(e.Hash, alg) {
 			s.Entries[i].Hash = hash
 			return
 		}
@@ -246,7 +973,28 @@ func (s *SumFile) Set(path, version, hash string) {
 	s.Entries = append(s.Entries, SumEntry{Path: path, Version: version, Hash: hash})
 }
 
-// Lookup returns the hash for a given path+version, or empty string.
+func sameAlgorithm(hash, alg string) bool {
+	a, _, _ := SplitHash(hash)
+	return a == alg
+}
+
+// Retain keeps the entries for which keep returns true and returns the
+// removed ones.
+func (s *SumFile) Retain(keep func(SumEntry) bool) []SumEntry {
+	var kept, removed []SumEntry
+	for _, e := range s.Entries {
+		if keep(e) {
+			kept = append(kept, e)
+		} else {
+			removed = append(removed, e)
+		}
+	}
+	s.Entries = kept
+	return removed
+}
+
+// Lookup returns the first hash recorded for a given path+version, of
+// whichever algorithm, or empty string.
 func (s *SumFile) Lookup(path, version string) string {
 	for _, e := range s.Entries {
 		if e.Path == path && e.Version == version {
@@ -255,3 +1003,14 @@ func (s *SumFile) Lookup(path, version string) string {
 	}
 	return ""
 }
+
+// LookupAlgorithm returns the hash recorded for a given path+version with
+// the given algorithm (e.g. "h1"), or empty string.
+func (s *SumFile) LookupAlgorithm(path, version, algorithm string) string {
+	for _, e := range s.Entries {
+		if e.Path == path && e.Version == version && sameAlgorithm(e.Hash, algorithm) {
+			return e.Hash
+		}
+	}
+	return ""
+}
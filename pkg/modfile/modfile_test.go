@@ -1,9 +1,15 @@
 package modfile_test
 
 import (
+	"errors"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/organic-programming/rhizome-atlas/pkg/modfile"
 )
@@ -44,8 +50,8 @@ replace (
 	if len(mod.Replace) != 1 {
 		t.Fatalf("Replace len = %d, want 1", len(mod.Replace))
 	}
-	if mod.Replace[0].LocalPath != "../local-dep-a" {
-		t.Errorf("Replace[0].LocalPath = %q", mod.Replace[0].LocalPath)
+	if mod.Replace[0].New != "../local-dep-a" {
+		t.Errorf("Replace[0].New = %q", mod.Replace[0].New)
 	}
 
 	// Round-trip: write and re-parse
@@ -126,6 +132,21 @@ func TestSumRoundTrip(t *testing.T) {
 	}
 }
 
+func TestParseSumRequiresAlgorithm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holon.sum")
+	if err := os.WriteFile(path, []byte("dep/a v1.0.0 abc123\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := modfile.ParseSum(path); err == nil {
+		t.Error("ParseSum accepted a hash without algorithm")
+	}
+
+	alg, digest, ok := modfile.SplitHash("h2:abc")
+	if alg != "h2" || digest != "abc" || !ok {
+		t.Errorf("SplitHash(h2:abc) = %q, %q, %v", alg, digest, ok)
+	}
+}
+
 func TestParseSumMissing(t *testing.T) {
 	sum, err := modfile.ParseSum("/nonexistent/holon.sum")
 	if err != nil {
@@ -135,3 +156,769 @@ func TestParseSumMissing(t *testing.T) {
 		t.Error("missing file should return empty SumFile")
 	}
 }
+
+func TestAliases(t *testing.T) {
+	dir := t.TempDir()
+	modPath := filepath.Join(dir, "holon.mod")
+
+	content := `holon github.com/org/myholon
+
+require (
+    github.com/org/long-name v1.2.0 as ln
+    github.com/org/other v0.1.0
+)
+`
+	if err := os.WriteFile(modPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mod, err := modfile.Parse(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := mod.RequireByName("ln"); !ok || r.Path != "github.com/org/long-name" {
+		t.Errorf("RequireByName(ln) = %+v, %v", r, ok)
+	}
+	if err := mod.SetAlias("github.com/org/other", "ln"); err == nil {
+		t.Error("SetAlias should reject an alias already in use")
+	}
+	if err := mod.SetAlias("github.com/org/other", "a/b"); err == nil {
+		t.Error("SetAlias should reject a path-like alias")
+	}
+	if err := mod.SetAlias("github.com/org/other", "oth"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Round-trip keeps aliases
+	if err := mod.Write(modPath); err != nil {
+		t.Fatal(err)
+	}
+	mod2, err := modfile.Parse(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod2.Require[0].Alias != "ln" || mod2.Require[1].Alias != "oth" {
+		t.Errorf("round-trip aliases = %q, %q", mod2.Require[0].Alias, mod2.Require[1].Alias)
+	}
+
+	// Duplicate aliases are rejected at parse time
+	dup := "holon x\n\nrequire (\n    a/b v1.0.0 as x1\n    c/d v1.0.0 as x1\n)\n"
+	if err := os.WriteFile(modPath, []byte(dup), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := modfile.Parse(modPath); err == nil {
+		t.Error("expected error for duplicate alias")
+	}
+}
+
+func TestRequireMeta(t *testing.T) {
+	dir := t.TempDir()
+	modPath := filepath.Join(dir, "holon.mod")
+
+	content := `holon github.com/org/myholon
+
+require (
+    github.com/org/a v1.0.0 // scope=test owner=platform
+    github.com/org/b v1.0.0 as bee // pinned for now, owner=core
+    github.com/org/c v1.0.0
+)
+`
+	if err := os.WriteFile(modPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mod, err := modfile.Parse(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := mod.Require[0].Meta; m["scope"] != "test" || m["owner"] != "platform" {
+		t.Errorf("Require[0].Meta = %v", m)
+	}
+	if r := mod.Require[1]; r.Alias != "bee" || len(r.Meta) != 1 || r.Meta["owner"] != "core" {
+		t.Errorf("Require[1] = %+v", r)
+	}
+	if mod.Require[2].Meta != nil {
+		t.Errorf("Require[2].Meta = %v, want nil", mod.Require[2].Meta)
+	}
+	if !mod.Require[0].Matches(map[string]string{"scope": "test"}) || mod.Require[2].Matches(map[string]string{"scope": "test"}) {
+		t.Error("Matches mismatch")
+	}
+
+	// Round-trip keeps annotations; Clone does not share them
+	if err := mod.Write(modPath); err != nil {
+		t.Fatal(err)
+	}
+	mod2, err := modfile.Parse(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod2.Require[0].Meta["owner"] != "platform" {
+		t.Errorf("round-trip Meta = %v", mod2.Require[0].Meta)
+	}
+	clone := mod2.Clone()
+	clone.Require[0].Meta["owner"] = "changed"
+	if mod2.Require[0].Meta["owner"] != "platform" {
+		t.Error("Clone shares Meta with the original")
+	}
+}
+
+func TestUntil(t *testing.T) {
+	mod, err := modfile.ParseBytes([]byte(`holon github.com/org/myholon
+
+require (
+    github.com/org/a v1.0.0 // until=2025-06-01
+    github.com/org/b v1.0.0
+)
+
+replace (
+    github.com/org/b => ../b // until=2025-07-01 owner=core
+)
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	until, ok := mod.Require[0].Until()
+	if !ok || until.Format(time.DateOnly) != "2025-06-01" {
+		t.Errorf("Require[0].Until() = %v, %v", until, ok)
+	}
+	if _, ok := mod.Require[1].Until(); ok {
+		t.Error("Require[1] has an until date")
+	}
+	r := mod.Replace[0]
+	if r.New != "../b" || r.Meta["owner"] != "core" {
+		t.Errorf("Replace[0] = %+v", r)
+	}
+	until, ok = r.Until()
+	if !ok || !modfile.Expired(until, until.AddDate(0, 0, 1)) || modfile.Expired(until, until.Add(23*time.Hour)) {
+		t.Errorf("Replace[0] until %v, %v: expired the day after only", until, ok)
+	}
+
+	// Annotations of replaces round-trip.
+	path := filepath.Join(t.TempDir(), "holon.mod")
+	if err := mod.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	mod2, err := modfile.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mod2.Replace[0].Meta, r.Meta) {
+		t.Errorf("round-trip Meta = %v, want %v", mod2.Replace[0].Meta, r.Meta)
+	}
+
+	if _, err := modfile.ParseBytes([]byte("holon x\n\nreplace (\n    a => ../a // until=June\n)\n")); err == nil {
+		t.Error("invalid until date: no error")
+	}
+}
+
+func TestStableDirective(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "holon.mod")
+	content := "holon test/stable\nstable\nprovenance\ntofu\n\nrequire (\n    github.com/a/b v1.0.0\n)\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mod, err := modfile.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mod.Stable {
+		t.Fatal("Stable = false, want true")
+	}
+	if !mod.Provenance {
+		t.Fatal("Provenance = false, want true")
+	}
+	if !mod.TrustOnFirstUse {
+		t.Fatal("TrustOnFirstUse = false, want true")
+	}
+
+	if err := mod.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != content {
+		t.Errorf("round trip:\n%s\nwant:\n%s", data, content)
+	}
+}
+
+func TestResolveDirective(t *testing.T) {
+	mod, err := modfile.ParseBytes([]byte("holon test/resolve\nresolve highest\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod.Resolve != modfile.StrategyHighest {
+		t.Errorf("Resolve = %q, want %q", mod.Resolve, modfile.StrategyHighest)
+	}
+	if _, err := modfile.ParseBytes([]byte("holon test/resolve\nresolve newest\n")); err == nil {
+		t.Error("unknown strategy accepted")
+	}
+}
+
+func TestRequireGroups(t *testing.T) {
+	content := `holon test/groups
+
+require (
+    github.com/org/app-lib v1.0.0
+)
+
+require dev (
+    github.com/org/linter v0.3.0
+)
+
+require test (
+    github.com/org/fixtures v1.1.0 as fx
+)
+`
+	mod, err := modfile.ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := mod.Groups(), []string{"runtime", "dev", "test"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Groups() = %q, want %q", got, want)
+	}
+	var paths []string
+	for _, r := range mod.OnlyGroup("test").Require {
+		paths = append(paths, r.Path)
+	}
+	if want := []string{"github.com/org/fixtures"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("test group = %q, want %q", paths, want)
+	}
+	if rt := mod.OnlyGroup(modfile.DefaultGroup); len(rt.Require) != 1 || !rt.Require[0].InGroup(modfile.DefaultGroup) {
+		t.Errorf("runtime group = %v", rt.Require)
+	}
+	if len(mod.Require) != 3 {
+		t.Errorf("OnlyGroup modified the original: %v", mod.Require)
+	}
+
+	path := filepath.Join(t.TempDir(), "holon.mod")
+	if err := mod.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("written:\n%s\nwant:\n%s", data, content)
+	}
+
+	if _, err := modfile.ParseBytes([]byte("holon test/groups\n\nrequire a/b (\n)\n")); err == nil {
+		t.Error("invalid group accepted")
+	}
+}
+
+func TestOptionalRequire(t *testing.T) {
+	content := `holon test/optional
+
+require (
+    github.com/org/core v1.0.0
+    github.com/org/otel v0.4.0 as otel optional // provides=tracing,metrics
+    github.com/org/gpu v2.1.0 optional
+)
+`
+	mod, err := modfile.ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	core, otel, gpu := mod.Require[0], mod.Require[1], mod.Require[2]
+	if core.Optional || !otel.Optional || !gpu.Optional || otel.Alias != "otel" {
+		t.Fatalf("requires = %+v", mod.Require)
+	}
+	if got, want := otel.Provides(), []string{"tracing", "metrics"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Provides() = %q, want %q", got, want)
+	}
+	for _, tt := range []struct {
+		with            []string
+		core, otel, gpu bool
+	}{
+		{nil, true, false, false},
+		{[]string{"otel"}, true, true, false},
+		{[]string{"metrics"}, true, true, false},
+		{[]string{"github.com/org/gpu"}, true, false, true},
+	} {
+		if core.Wanted(tt.with) != tt.core || otel.Wanted(tt.with) != tt.otel || gpu.Wanted(tt.with) != tt.gpu {
+			t.Errorf("with %q: wanted core=%v otel=%v gpu=%v", tt.with, core.Wanted(tt.with), otel.Wanted(tt.with), gpu.Wanted(tt.with))
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "holon.mod")
+	if err := mod.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("written:\n%s\nwant:\n%s", data, content)
+	}
+}
+
+func TestWildcardReplace(t *testing.T) {
+	mod, err := modfile.ParseBytes([]byte(`holon test/replace
+
+replace (
+    github.com/oldorg/* => github.com/neworg/*
+    github.com/oldorg/kept => github.com/oldorg/kept
+    github.com/mono/* => ../monorepo/*
+    github.com/mono/tools/* => /opt/tools
+)
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		path, local, source string
+	}{
+		{"github.com/oldorg/a", "", "github.com/neworg/a"},
+		{"github.com/oldorg/a/b", "", "github.com/neworg/a/b"},
+		{"github.com/oldorg/kept", "", "github.com/oldorg/kept"},
+		{"github.com/oldorg", "", "github.com/oldorg"},
+		{"github.com/mono/x", filepath.Join("..", "monorepo", "x"), "github.com/mono/x"},
+		{"github.com/mono/tools/lint", "/opt/tools", "github.com/mono/tools/lint"},
+		{"github.com/other/x", "", "github.com/other/x"},
+	} {
+		if got := mod.ResolvedPath(tt.path); got != tt.local {
+			t.Errorf("ResolvedPath(%q) = %q, want %q", tt.path, got, tt.local)
+		}
+		if got := mod.SourcePath(tt.path); got != tt.source {
+			t.Errorf("SourcePath(%q) = %q, want %q", tt.path, got, tt.source)
+		}
+	}
+
+	for _, line := range []string{
+		"github.com/a/b => github.com/c/*",
+		"github.com/*/b => github.com/c/b",
+	} {
+		if _, err := modfile.ParseBytes([]byte("holon test/replace\n\nreplace (\n    " + line + "\n)\n")); err == nil {
+			t.Errorf("replace %q accepted", line)
+		}
+	}
+}
+
+func TestVendorDirective(t *testing.T) {
+	mod, err := modfile.ParseBytes([]byte("holon test/vendor\nvendor third_party/holons\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod.VendorDir != "third_party/holons" {
+		t.Errorf("VendorDir = %q, want third_party/holons", mod.VendorDir)
+	}
+	for _, dir := range []string{"/abs", "../outside", "a/../b", "."} {
+		if _, err := modfile.ParseBytes([]byte("holon test/vendor\nvendor " + dir + "\n")); err == nil {
+			t.Errorf("vendor %q accepted", dir)
+		}
+	}
+}
+
+func TestSourceDirectives(t *testing.T) {
+	content := "holon test/sources\nregistry https://holons.example.com/\nproxy https://mirror.example.com,file:///srv/holons,git@git.example.com:holons\n"
+	mod, err := modfile.ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod.Registry != "https://holons.example.com" {
+		t.Errorf("Registry = %q, want https://holons.example.com", mod.Registry)
+	}
+	if !slices.Equal(mod.Proxy, []string{"https://mirror.example.com", "file:///srv/holons", "git@git.example.com:holons"}) {
+		t.Errorf("Proxy = %q", mod.Proxy)
+	}
+
+	path := filepath.Join(t.TempDir(), "holon.mod")
+	if err := mod.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	again, err := modfile.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Registry != mod.Registry || !slices.Equal(again.Proxy, mod.Proxy) {
+		t.Errorf("round trip = %q %q, want %q %q", again.Registry, again.Proxy, mod.Registry, mod.Proxy)
+	}
+
+	for _, line := range []string{"registry holons.example.com", "proxy https://a.example.com,", "proxy https://a b", "proxy c:holons"} {
+		if _, err := modfile.ParseBytes([]byte("holon test/sources\n" + line + "\n")); err == nil {
+			t.Errorf("%q accepted", line)
+		}
+	}
+}
+
+func TestBudgetDirectives(t *testing.T) {
+	content := "holon test/budget\nbudget 2M\nbudget total 1536k\n\nrequire (\n" +
+		"    github.com/a/big v1.0.0 // budget=1G\n    github.com/b/small v1.0.0\n)\n\n" +
+		"replace (\n    github.com/b/small => github.com/fork/small\n)\n"
+	mod, err := modfile.ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod.Budget != 2<<20 || mod.TotalBudget != 1536<<10 {
+		t.Errorf("budgets = %d, %d", mod.Budget, mod.TotalBudget)
+	}
+	for path, want := range map[string]int64{
+		"github.com/a/big":      1 << 30,
+		"github.com/fork/small": 2 << 20,
+		"github.com/c/indirect": 2 << 20,
+	} {
+		if got := mod.BudgetOf(path); got != want {
+			t.Errorf("BudgetOf(%s) = %d, want %d", path, got, want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "holon.mod")
+	if err := mod.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "budget 2M\nbudget total 1536k\n") {
+		t.Errorf("written:\n%s", data)
+	}
+
+	for _, line := range []string{"budget 2X", "budget total", "budget -1"} {
+		if _, err := modfile.ParseBytes([]byte("holon test/budget\n" + line + "\n")); err == nil {
+			t.Errorf("%q accepted", line)
+		}
+	}
+	if _, err := modfile.ParseBytes([]byte("holon test/budget\n\nrequire (\n    github.com/a/x v1.0.0 // budget=big\n)\n")); err == nil {
+		t.Error("budget=big accepted")
+	}
+}
+
+func TestDeprecatedDirectives(t *testing.T) {
+	content := "holon test/old\n" +
+		"deprecated v1.2.0 => v1.2.1 // corrupts its cache on upgrade\n" +
+		"deprecated => github.com/org/new@v2.0.0 // superseded by new\n"
+	mod, err := modfile.ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for version, want := range map[string]modfile.Deprecation{
+		"v1.2.0": {Version: "v1.2.0", Successor: "v1.2.1", Message: "corrupts its cache on upgrade"},
+		"v1.3.0": {Successor: "github.com/org/new@v2.0.0", Message: "superseded by new"},
+	} {
+		if got, ok := mod.DeprecationOf(version); !ok || got != want {
+			t.Errorf("DeprecationOf(%s) = %+v, %v, want %+v", version, got, ok, want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "holon.mod")
+	if err := mod.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != content {
+		t.Errorf("written:\n%s", data)
+	}
+
+	if mod, err := modfile.ParseBytes([]byte("holon test/old\ndeprecated\n")); err != nil || len(mod.Deprecated) != 1 || mod.Deprecated[0] != (modfile.Deprecation{}) {
+		t.Errorf("bare deprecated = %+v, %v", mod, err)
+	}
+	for _, line := range []string{"deprecated v1.0.0 v1.1.0", "deprecated v1.0.0 =>", "deprecated => a b"} {
+		if _, err := modfile.ParseBytes([]byte("holon test/old\n" + line + "\n")); err == nil {
+			t.Errorf("%q accepted", line)
+		}
+	}
+}
+
+func TestLockRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holon.lock")
+	lock := &modfile.LockFile{
+		Strategy: modfile.StrategyMVS,
+		With:     []string{"github.com/c/z", "tracing"},
+		Selected: []modfile.Selection{
+			{Path: "github.com/a/x", Version: "v1.2.0", RequiredBy: []modfile.Requirement{
+				{Holon: "test/root", Version: "v1.0.0"},
+				{Holon: "github.com/b/y", Version: "v1.2.0"},
+			}},
+			{Path: "github.com/b/y", Version: "v0.3.0", RequiredBy: []modfile.Requirement{{Holon: "test/root", Version: "v0.3.0"}}},
+		},
+	}
+	if err := lock.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := modfile.ParseLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, lock) {
+		t.Errorf("round trip = %+v, want %+v", got, lock)
+	}
+	if sel, ok := got.Lookup("github.com/a/x"); !ok || sel.Version != "v1.2.0" {
+		t.Errorf("Lookup = %+v, %v", sel, ok)
+	}
+
+	// The lock matches holon.mod while the requirements it was resolved
+	// from, strategy and optional dependencies asked for are unchanged.
+	mod, err := modfile.ParseBytes([]byte("holon test/root\nrequire (\n    github.com/b/y v0.3.0\n    github.com/a/x v1.0.0\n    github.com/c/z v0.1.0 optional\n    github.com/d/w v1.0.0\n)\nreplace (\n    github.com/d/w => ../w\n)\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	with := []string{"tracing", "github.com/c/z", "tracing"}
+	if got.Matches(mod, modfile.StrategyMVS, with) {
+		t.Error("lock without the optional dependency asked for matches")
+	}
+	mod.Require = slices.DeleteFunc(mod.Require, func(r modfile.Require) bool { return r.Optional })
+	if !got.Matches(mod, modfile.StrategyMVS, with) {
+		t.Error("lock does not match the holon.mod it was resolved from")
+	}
+	if got.Matches(mod, modfile.StrategyHighest, with) || got.Matches(mod, modfile.StrategyMVS, nil) {
+		t.Error("lock matches another strategy or other optional dependencies")
+	}
+	mod.Require[1].Version = "v1.1.0"
+	if got.Matches(mod, modfile.StrategyMVS, with) {
+		t.Error("lock matches a changed requirement")
+	}
+
+	missing, err := modfile.ParseLock(filepath.Join(t.TempDir(), "holon.lock"))
+	if err != nil || len(missing.Selected) != 0 {
+		t.Errorf("missing lock = %+v, %v", missing, err)
+	}
+}
+
+func TestSumRetain(t *testing.T) {
+	sum := &modfile.SumFile{}
+	sum.Set("github.com/a/b", "v1.0.0", "h1:aaa")
+	sum.Set("github.com/a/b", "v1.1.0", "h1:bbb")
+	sum.Set("github.com/c/d", "v0.1.0", "h1:ccc")
+
+	removed := sum.Retain(func(e modfile.SumEntry) bool { return e.Version != "v1.0.0" })
+	if len(removed) != 1 || removed[0].Hash != "h1:aaa" {
+		t.Errorf("removed = %v, want the v1.0.0 entry", removed)
+	}
+	if len(sum.Entries) != 2 || sum.Lookup("github.com/a/b", "v1.0.0") != "" {
+		t.Errorf("entries = %v", sum.Entries)
+	}
+}
+
+func TestSumMixedAlgorithms(t *testing.T) {
+	sum := &modfile.SumFile{}
+	sum.Set("github.com/a/b", "v1.0.0", "h1:aaa")
+	sum.Set("github.com/a/b", "v1.0.0", "h2:bbb")
+	sum.Set("github.com/a/b", "v1.0.0", "h1:ccc")
+	if len(sum.Entries) != 2 {
+		t.Fatalf("entries = %v, want one per algorithm", sum.Entries)
+	}
+	if h := sum.LookupAlgorithm("github.com/a/b", "v1.0.0", "h1"); h != "h1:ccc" {
+		t.Errorf("h1 = %q", h)
+	}
+	if h := sum.LookupAlgorithm("github.com/a/b", "v1.0.0", "h2"); h != "h2:bbb" {
+		t.Errorf("h2 = %q", h)
+	}
+
+	// Only hashes of the same algorithm can conflict.
+	theirs := &modfile.SumFile{}
+	theirs.Set("github.com/a/b", "v1.0.0", "h2:bbb")
+	theirs.Set("github.com/a/b", "v1.0.0", "h3:ddd")
+	merged, conflicts := modfile.MergeSum(sum, theirs)
+	if len(conflicts) != 0 || len(merged.Entries) != 3 {
+		t.Errorf("merged = %v, conflicts = %v", merged, conflicts)
+	}
+}
+
+func TestMergeSum(t *testing.T) {
+	ours := &modfile.SumFile{}
+	ours.Set("github.com/a/b", "v1.0.0", "h1:aaa")
+	ours.Set("github.com/c/d", "v0.1.0", "h1:ccc")
+	theirs := &modfile.SumFile{}
+	theirs.Set("github.com/a/b", "v1.0.0", "h1:aaa")
+	theirs.Set("github.com/e/f", "v2.0.0", "h1:eee")
+
+	merged, conflicts := modfile.MergeSum(ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("conflicts = %v", conflicts)
+	}
+	if len(merged.Entries) != 3 || merged.Lookup("github.com/e/f", "v2.0.0") != "h1:eee" {
+		t.Errorf("merged = %v", merged.Entries)
+	}
+
+	theirs.Set("github.com/c/d", "v0.1.0", "h1:xxx")
+	if merged, conflicts = modfile.MergeSum(ours, theirs); merged != nil || len(conflicts) != 1 {
+		t.Fatalf("merged = %v, conflicts = %v; want one conflict", merged, conflicts)
+	}
+	if c := conflicts[0]; c.Path != "github.com/c/d" || c.Ours != "h1:ccc" || c.Theirs != "h1:xxx" {
+		t.Errorf("conflict = %+v", c)
+	}
+}
+
+func TestMergeMod(t *testing.T) {
+	base := &modfile.ModFile{HolonPath: "test/merge"}
+	base.AddRequire("github.com/a/kept", "v1.0.0")
+	base.AddRequire("github.com/a/dropped", "v1.0.0")
+
+	ours := base.Clone()
+	ours.AddRequire("github.com/a/kept", "v1.2.0")
+	ours.AddRequire("github.com/a/ours", "v0.1.0")
+	ours.RemoveRequire("github.com/a/dropped")
+
+	theirs := base.Clone()
+	theirs.AddRequire("github.com/a/kept", "v1.1.0")
+	theirs.AddRequire("github.com/a/theirs", "v0.2.0")
+	theirs.Replace = append(theirs.Replace, modfile.Replace{Old: "github.com/a/theirs", New: "../theirs"})
+
+	merged, err := modfile.MergeMod(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"github.com/a/kept":   "v1.2.0",
+		"github.com/a/ours":   "v0.1.0",
+		"github.com/a/theirs": "v0.2.0",
+	}
+	if len(merged.Require) != len(want) {
+		t.Fatalf("requires = %v", merged.Require)
+	}
+	for _, r := range merged.Require {
+		if want[r.Path] != r.Version {
+			t.Errorf("require %s %s, want %s", r.Path, r.Version, want[r.Path])
+		}
+	}
+	if len(merged.Replace) != 1 {
+		t.Errorf("replaces = %v", merged.Replace)
+	}
+
+	theirs.Replace[0].New = "../elsewhere"
+	ours.Replace = append(ours.Replace, modfile.Replace{Old: "github.com/a/theirs", New: "../ours"})
+	if _, err := modfile.MergeMod(base, ours, theirs); err == nil {
+		t.Error("conflicting replace targets merged without error")
+	}
+}
+
+func TestMergeModBudgets(t *testing.T) {
+	base := &modfile.ModFile{HolonPath: "test/merge", Budget: 2 << 20, TotalBudget: 4 << 20}
+
+	merged, err := modfile.MergeMod(base, base.Clone(), base.Clone())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Budget != 2<<20 || merged.TotalBudget != 4<<20 {
+		t.Errorf("unchanged budgets = %d, %d, want %d, %d", merged.Budget, merged.TotalBudget, 2<<20, 4<<20)
+	}
+
+	ours, theirs := base.Clone(), base.Clone()
+	ours.Budget = 3 << 20
+	theirs.TotalBudget = 0
+	if merged, err = modfile.MergeMod(base, ours, theirs); err != nil {
+		t.Fatal(err)
+	}
+	if merged.Budget != 3<<20 || merged.TotalBudget != 0 {
+		t.Errorf("budgets = %d, %d, want ours %d and theirs removed", merged.Budget, merged.TotalBudget, 3<<20)
+	}
+
+	theirs.Budget = 1 << 20
+	if _, err := modfile.MergeMod(base, ours, theirs); err == nil {
+		t.Error("budgets changed on both sides merged without error")
+	}
+}
+
+func TestMergeModDeprecated(t *testing.T) {
+	base := &modfile.ModFile{HolonPath: "test/merge", Deprecated: []modfile.Deprecation{
+		{Version: "v1.0.0", Successor: "v1.0.1", Message: "data loss"},
+		{Version: "v1.1.0", Message: "retracted"},
+	}}
+	ours, theirs := base.Clone(), base.Clone()
+	ours.Deprecated = append(ours.Deprecated, modfile.Deprecation{Message: "use github.com/org/next"})
+	theirs.Deprecated = append(theirs.Deprecated[1:], modfile.Deprecation{Version: "v1.2.0", Successor: "v1.2.1"})
+
+	merged, err := modfile.MergeMod(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range merged.Deprecated {
+		got = append(got, d.String())
+	}
+	if want := []string{"deprecated v1.1.0 // retracted", "deprecated // use github.com/org/next", "deprecated v1.2.0 => v1.2.1"}; !slices.Equal(got, want) {
+		t.Errorf("deprecated = %q, want %q", got, want)
+	}
+
+	ours.Deprecated[1].Message = "broken"
+	theirs.Deprecated[0].Message = "yanked"
+	if _, err := modfile.MergeMod(base, ours, theirs); err == nil {
+		t.Error("a version deprecated differently on both sides merged without error")
+	}
+}
+
+func TestMergeModFlags(t *testing.T) {
+	base := &modfile.ModFile{HolonPath: "test/merge", Stable: true, Provenance: true}
+	ours, theirs := base.Clone(), base.Clone()
+	ours.Stable = false
+	theirs.TrustOnFirstUse = true
+
+	merged, err := modfile.MergeMod(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Stable || !merged.Provenance || !merged.TrustOnFirstUse {
+		t.Errorf("stable, provenance, tofu = %v, %v, %v, want false (removed by ours), true, true (added by theirs)",
+			merged.Stable, merged.Provenance, merged.TrustOnFirstUse)
+	}
+}
+
+func TestMergeModRequireFields(t *testing.T) {
+	base := &modfile.ModFile{HolonPath: "test/merge"}
+	base.Require = []modfile.Require{
+		{Path: "github.com/a/alias", Version: "v1.0.0", Alias: "a"},
+		{Path: "github.com/a/group", Version: "v1.0.0"},
+		{Path: "github.com/a/optional", Version: "v1.0.0", Optional: true},
+		{Path: "github.com/a/meta", Version: "v1.0.0", Meta: map[string]string{"owner": "core", "scope": "test"}},
+	}
+	base.Replace = []modfile.Replace{{Old: "github.com/a/meta", New: "../meta", Meta: map[string]string{"until": "2025-06-01"}}}
+
+	// Each side edits its own fields; theirs wins where ours left base.
+	ours, theirs := base.Clone(), base.Clone()
+	ours.Require[3].Meta = map[string]string{"owner": "core", "scope": "test", "provides": "tracing"}
+	theirs.Require[0].Alias = "alpha"
+	theirs.Require[1].Group = "dev"
+	theirs.Require[2].Optional = false
+	theirs.Require[3].Meta = map[string]string{"owner": "platform"}
+	theirs.Replace[0].Meta = map[string]string{"until": "2025-12-31"}
+
+	merged, err := modfile.MergeMod(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPath := map[string]modfile.Require{}
+	for _, r := range merged.Require {
+		byPath[r.Path] = r
+	}
+	if r := byPath["github.com/a/alias"]; r.Alias != "alpha" {
+		t.Errorf("alias = %q, want theirs", r.Alias)
+	}
+	if r := byPath["github.com/a/group"]; r.Group != "dev" {
+		t.Errorf("group = %q, want theirs", r.Group)
+	}
+	if r := byPath["github.com/a/optional"]; r.Optional {
+		t.Error("optional kept, want removed by theirs")
+	}
+	if r, want := byPath["github.com/a/meta"], map[string]string{"owner": "platform", "provides": "tracing"}; !maps.Equal(r.Meta, want) {
+		t.Errorf("annotations = %v, want %v", r.Meta, want)
+	}
+	if want := map[string]string{"until": "2025-12-31"}; len(merged.Replace) != 1 || !maps.Equal(merged.Replace[0].Meta, want) {
+		t.Errorf("replaces = %v, want annotations %v", merged.Replace, want)
+	}
+
+	// Both sides changing a field differently conflict.
+	for _, tc := range []struct {
+		name         string
+		ours, theirs func(m *modfile.ModFile)
+	}{
+		{"alias", func(m *modfile.ModFile) { m.Require[0].Alias = "x" }, func(m *modfile.ModFile) { m.Require[0].Alias = "y" }},
+		{"group", func(m *modfile.ModFile) { m.Require[1].Group = "dev" }, func(m *modfile.ModFile) { m.Require[1].Group = "test" }},
+		{"annotation", func(m *modfile.ModFile) { m.Require[3].Meta["owner"] = "x" }, func(m *modfile.ModFile) { m.Require[3].Meta["owner"] = "y" }},
+		{"replace annotation", func(m *modfile.ModFile) { m.Replace[0].Meta["until"] = "2026-01-01" }, func(m *modfile.ModFile) { delete(m.Replace[0].Meta, "until") }},
+	} {
+		ours, theirs := base.Clone(), base.Clone()
+		tc.ours(ours)
+		tc.theirs(theirs)
+		if _, err := modfile.MergeMod(base, ours, theirs); err == nil {
+			t.Errorf("%s changed on both sides merged without error", tc.name)
+		}
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	content := "holon test/lines\n\nrequire (\n    github.com/a/b v1.0.0\n)\n\nreplace (\n    github.com/a/b => ../b\n)\n"
+	mod, err := modfile.ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod.Require[0].Line != 4 || mod.Replace[0].Line != 8 {
+		t.Errorf("lines = %d, %d, want 4, 8", mod.Require[0].Line, mod.Replace[0].Line)
+	}
+
+	_, err = modfile.ParseBytes([]byte("holon test/lines\nrequire (\n    github.com/a/b\n)\n"))
+	var perr *modfile.ParseError
+	if !errors.As(err, &perr) || perr.Line != 3 {
+		t.Errorf("err = %v, want a ParseError on line 3", err)
+	}
+}
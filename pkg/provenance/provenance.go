@@ -0,0 +1,189 @@
+// Package provenance reads and writes the provenance attestations of holon
+// releases: in-toto statements with a SLSA provenance v1 predicate, telling
+// which builder released the holon, from which source commit, by which
+// workflow. A release carries its statement in the message of its
+// annotated tag, between BeginMarker and EndMarker.
+package provenance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// StatementType is the type of in-toto v1 statements.
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PredicateType is the type of SLSA provenance v1 predicates.
+	PredicateType = "https://slsa.dev/provenance/v1"
+	// BuildType identifies the releases tagged by "atlas release".
+	BuildType = "https://github.com/organic-programming/rhizome-atlas/release/v1"
+
+	BeginMarker = "-----BEGIN HOLON PROVENANCE-----"
+	EndMarker   = "-----END HOLON PROVENANCE-----"
+)
+
+// Statement is an in-toto statement attesting to the provenance of its
+// subjects.
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Subject is an artifact the statement is about, identified by digests.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate is a SLSA provenance v1 predicate.
+type Predicate struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   map[string]any       `json:"externalParameters"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+type ResourceDescriptor struct {
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+type RunDetails struct {
+	Builder Builder `json:"builder"`
+}
+
+type Builder struct {
+	ID string `json:"id"`
+}
+
+// New returns the statement that builder released subject, e.g.
+// "example.com/dep@v1.2.0", from commit of the repository at source, by
+// workflow.
+func New(subject, source, commit, builder, workflow string) *Statement {
+	params := map[string]any{"source": source}
+	if workflow != "" {
+		params["workflow"] = workflow
+	}
+	return &Statement{
+		Type:          StatementType,
+		Subject:       []Subject{{Name: subject, Digest: map[string]string{"gitCommit": commit}}},
+		PredicateType: PredicateType,
+		Predicate: Predicate{
+			BuildDefinition: BuildDefinition{
+				BuildType:          BuildType,
+				ExternalParameters: params,
+				ResolvedDependencies: []ResourceDescriptor{
+					{URI: source, Digest: map[string]string{"gitCommit": commit}},
+				},
+			},
+			RunDetails: RunDetails{Builder: Builder{ID: builder}},
+		},
+	}
+}
+
+// Parse decodes a JSON statement and checks that it is SLSA provenance.
+func Parse(data []byte) (*Statement, error) {
+	var s Statement
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("decode statement: %w", err)
+	}
+	if s.Type != StatementType {
+		return nil, fmt.Errorf("statement type %q, want %q", s.Type, StatementType)
+	}
+	if s.PredicateType != PredicateType {
+		return nil, fmt.Errorf("predicate type %q, want %q", s.PredicateType, PredicateType)
+	}
+	if s.Predicate.RunDetails.Builder.ID == "" {
+		return nil, errors.New("statement names no builder")
+	}
+	return &s, nil
+}
+
+// Encode returns s between the markers, to append to a tag message.
+func Encode(s *Statement) (string, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return BeginMarker + "\n" + string(data) + "\n" + EndMarker + "\n", nil
+}
+
+// Extract returns the statement carried in a tag message, or nil if the
+// message carries none.
+func Extract(message string) (*Statement, error) {
+	_, rest, ok := strings.Cut(message, BeginMarker)
+	if !ok {
+		return nil, nil
+	}
+	body, _, ok := strings.Cut(rest, EndMarker)
+	if !ok {
+		return nil, errors.New("provenance block not terminated")
+	}
+	return Parse([]byte(body))
+}
+
+// Verify checks that s attests to the given source commit.
+func (s *Statement) Verify(commit string) error {
+	got := s.Commit()
+	if got == "" {
+		return errors.New("statement names no source commit")
+	}
+	if got != commit {
+		return fmt.Errorf("statement attests to commit %s, not %s", got, commit)
+	}
+	return nil
+}
+
+// Builder returns the ID of the builder that made the release.
+func (s *Statement) Builder() string { return s.Predicate.RunDetails.Builder.ID }
+
+// Commit returns the source commit: the git commit of the first resolved
+// dependency naming one, else of the first subject.
+func (s *Statement) Commit() string {
+	for _, d := range s.Predicate.BuildDefinition.ResolvedDependencies {
+		if c := d.Digest["gitCommit"]; c != "" {
+			return c
+		}
+	}
+	for _, sub := range s.Subject {
+		if c := sub.Digest["gitCommit"]; c != "" {
+			return c
+		}
+	}
+	return ""
+}
+
+// Source returns the URI of the source repository.
+func (s *Statement) Source() string {
+	for _, d := range s.Predicate.BuildDefinition.ResolvedDependencies {
+		if d.Digest["gitCommit"] != "" && d.URI != "" {
+			return d.URI
+		}
+	}
+	src, _ := s.Predicate.BuildDefinition.ExternalParameters["source"].(string)
+	return src
+}
+
+// Workflow returns the workflow that ran the release, as a string or, for
+// statements of the GitHub generator, its repository path and ref.
+func (s *Statement) Workflow() string {
+	switch w := s.Predicate.BuildDefinition.ExternalParameters["workflow"].(type) {
+	case string:
+		return w
+	case map[string]any:
+		p, _ := w["path"].(string)
+		if ref, _ := w["ref"].(string); ref != "" {
+			return p + "@" + ref
+		}
+		return p
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package provenance_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/provenance"
+)
+
+func TestEncodeExtract(t *testing.T) {
+	s := provenance.New("example.com/dep@v1.0.0", "git+https://example.com/dep@refs/tags/v1.0.0",
+		"0123abcd", "https://ci.example.com/runner", ".ci/release.yml")
+	block, err := provenance.Encode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := provenance.Extract("Release v1.0.0\n\n" + block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Builder() != "https://ci.example.com/runner" || got.Commit() != "0123abcd" ||
+		got.Workflow() != ".ci/release.yml" || got.Source() != "git+https://example.com/dep@refs/tags/v1.0.0" {
+		t.Errorf("extracted %+v", got)
+	}
+	if err := got.Verify("0123abcd"); err != nil {
+		t.Error(err)
+	}
+	if err := got.Verify("ffff0000"); err == nil {
+		t.Error("statement verified against another commit")
+	}
+
+	if s, err := provenance.Extract("Release v1.0.0\n"); s != nil || err != nil {
+		t.Errorf("message without provenance: %v, %v", s, err)
+	}
+	if _, err := provenance.Extract(strings.TrimSuffix(block, provenance.EndMarker+"\n")); err == nil {
+		t.Error("unterminated block accepted")
+	}
+}
+
+func TestParseGitHubWorkflow(t *testing.T) {
+	s, err := provenance.Parse([]byte(`{
+  "_type": "https://in-toto.io/Statement/v1",
+  "subject": [{"name": "dep.tar.gz", "digest": {"sha256": "ab"}}],
+  "predicateType": "https://slsa.dev/provenance/v1",
+  "predicate": {
+    "buildDefinition": {
+      "buildType": "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1",
+      "externalParameters": {"workflow": {"ref": "refs/tags/v1.0.0", "repository": "https://github.com/o/dep", "path": ".github/workflows/release.yml"}},
+      "resolvedDependencies": [{"uri": "git+https://github.com/o/dep@refs/tags/v1.0.0", "digest": {"gitCommit": "c0ffee"}}]
+    },
+    "runDetails": {"builder": {"id": "https://github.com/actions/runner"}}
+  }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Workflow() != ".github/workflows/release.yml@refs/tags/v1.0.0" || s.Commit() != "c0ffee" {
+		t.Errorf("workflow %q, commit %q", s.Workflow(), s.Commit())
+	}
+
+	if _, err := provenance.Parse([]byte(`{"_type": "https://in-toto.io/Statement/v1", "predicateType": "https://example.com/other"}`)); err == nil {
+		t.Error("foreign predicate accepted")
+	}
+}
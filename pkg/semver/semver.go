@@ -0,0 +1,139 @@
+// Package semver parses and orders the "vMAJOR.MINOR.PATCH[-prerelease]"
+// versions used to tag holons, and the pseudo-versions pinning commits.
+package semver
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pseudoTime is the layout of the commit time in a pseudo-version.
+const pseudoTime = "20060102150405"
+
+// PseudoVersion returns the pseudo-version pinning commit, made at t, as
+// Go writes those of untagged commits: v0.0.0-yyyymmddhhmmss-abcdef123456,
+// with the UTC time and the first 12 hex digits of the hash.
+func PseudoVersion(t time.Time, commit string) string {
+	return "v0.0.0-" + t.UTC().Format(pseudoTime) + "-" + commit[:min(len(commit), 12)]
+}
+
+// Pseudo returns the commit time and abbreviated hash a pseudo-version
+// names; ok is false for other versions. Besides those of PseudoVersion,
+// the vX.Y.Z-pre.0.yyyymmddhhmmss-abcdef123456 and
+// vX.Y.(Z+1)-0.yyyymmddhhmmss-abcdef123456 forms Go gives commits after a
+// tag are recognized.
+func Pseudo(v string) (t time.Time, commit string, ok bool) {
+	if _, _, _, ok := Parse(v); !ok {
+		return time.Time{}, "", false
+	}
+	pre := Prerelease(v)
+	i := strings.LastIndexByte(pre, '-')
+	if i < len(pseudoTime) || len(pre)-i-1 != 12 || strings.Trim(pre[i+1:], "0123456789abcdef") != "" {
+		return time.Time{}, "", false
+	}
+	stamp := pre[i-len(pseudoTime) : i]
+	if rest := pre[:i-len(pseudoTime)]; rest != "" && rest != "0." && !strings.HasSuffix(rest, ".0.") {
+		return time.Time{}, "", false
+	}
+	t, err := time.Parse(pseudoTime, stamp)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return t, pre[i+1:], true
+}
+
+// IsPseudo reports whether v is a pseudo-version (see Pseudo).
+func IsPseudo(v string) bool {
+	_, _, ok := Pseudo(v)
+	return ok
+}
+
+// Parse extracts major, minor, patch from "vM.N.P", ignoring any
+// "-prerelease" or "+build" suffix.
+func Parse(v string) (major, minor, patch int, ok bool) {
+	core, _, _ := strings.Cut(strings.TrimPrefix(v, "v"), "+")
+	core, _, _ = strings.Cut(core, "-")
+	parts := strings.SplitN(core, ".", 3)
+	if len(parts) != 3 {
+		return 0, 0, 0, false
+	}
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(parts[1])
+	patch, err3 := strconv.Atoi(parts[2])
+	return major, minor, patch, err1 == nil && err2 == nil && err3 == nil
+}
+
+// Prerelease returns the prerelease part of v: "beta.2" for v1.0.0-beta.2,
+// "" for a release.
+func Prerelease(v string) string {
+	v, _, _ = strings.Cut(v, "+")
+	_, pre, _ := strings.Cut(v, "-")
+	return pre
+}
+
+// Channel returns the channel of a prerelease version, the leading
+// letters of its prerelease: "beta" for v1.0.0-beta.2, "rc" for
+// v1.0.0-rc1. Releases have no channel.
+func Channel(v string) string {
+	pre := Prerelease(v)
+	end := strings.IndexFunc(pre, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z')
+	})
+	if end < 0 {
+		return pre
+	}
+	return pre[:end]
+}
+
+// Compare returns a negative, zero or positive number as a is
+// lower than, equal to or higher than b. A prerelease is lower than the
+// release of the same version.
+func Compare(a, b string) int {
+	ma, mia, pa, _ := Parse(a)
+	mb, mib, pb, _ := Parse(b)
+	if ma != mb {
+		return ma - mb
+	}
+	if mia != mib {
+		return mia - mib
+	}
+	if pa != pb {
+		return pa - pb
+	}
+	return comparePrerelease(Prerelease(a), Prerelease(b))
+}
+
+// comparePrerelease orders prerelease strings by semver precedence:
+// dot-separated identifiers compare numerically when both are numbers,
+// lexically otherwise, and the empty prerelease (a release) sorts last.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		na, errA := strconv.Atoi(as[i])
+		nb, errB := strconv.Atoi(bs[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				return na - nb
+			}
+		case errA == nil:
+			return -1 // numeric identifiers sort first
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return len(as) - len(bs)
+}
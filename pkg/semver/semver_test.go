@@ -0,0 +1,82 @@
+package semver_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/semver"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int // sign only
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v2.0.0", "v1.99.99", 1},
+		{"v1.0.0-rc1", "v1.0.0", -1},
+		{"v1.0.0-beta.2", "v1.0.0-beta.10", -1},
+		{"v1.0.0-beta", "v1.0.0-beta.1", -1},
+		{"v1.0.0-alpha.1", "v1.0.0-beta", -1},
+	}
+	for _, tt := range tests {
+		got := semver.Compare(tt.a, tt.b)
+		if sign(got) != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want sign %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestChannel(t *testing.T) {
+	for v, want := range map[string]string{
+		"v1.0.0":        "",
+		"v1.0.0-beta.2": "beta",
+		"v1.0.0-rc1":    "rc",
+		"v1.0.0+build":  "",
+	} {
+		if got := semver.Channel(v); got != want {
+			t.Errorf("Channel(%s) = %q, want %q", v, got, want)
+		}
+	}
+	if _, _, _, ok := semver.Parse("sha256:abc"); ok {
+		t.Error("Parse accepted a digest")
+	}
+}
+
+func TestPseudo(t *testing.T) {
+	at := time.Date(2024, 3, 5, 14, 7, 9, 0, time.FixedZone("CET", 3600))
+	v := semver.PseudoVersion(at, "0123456789abcdef0123456789abcdef01234567")
+	if v != "v0.0.0-20240305130709-0123456789ab" {
+		t.Fatalf("PseudoVersion = %s", v)
+	}
+	got, commit, ok := semver.Pseudo(v)
+	if !ok || !got.Equal(at) || commit != "0123456789ab" {
+		t.Errorf("Pseudo(%s) = %v, %q, %v", v, got, commit, ok)
+	}
+	for v, want := range map[string]bool{
+		"v1.2.4-0.20240305130709-0123456789ab":      true,
+		"v1.2.3-beta.0.20240305130709-0123456789ab": true,
+		"v1.2.3": false,
+		"v1.0.0-beta.20240305130709-0123456789ab": false,
+		"v0.0.0-20241305130709-0123456789ab":      false,
+		"v0.0.0-20240305130709-0123456789":        false,
+	} {
+		if got := semver.IsPseudo(v); got != want {
+			t.Errorf("IsPseudo(%s) = %v, want %v", v, got, want)
+		}
+	}
+	if semver.Compare("v0.0.0-20240305130709-0123456789ab", "v0.0.0-20240306000000-ffffffffffff") >= 0 {
+		t.Error("pseudo-versions do not order by time")
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
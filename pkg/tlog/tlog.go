@@ -0,0 +1,178 @@
+// Package tlog computes and checks the proofs of a transparency log: an
+// append-only Merkle tree of records, hashed as in RFC 6962. A client
+// remembering the root of the tree at some size can check that a record
+// is in the log, and that the log only grew since.
+package tlog
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// Hash is a node of the tree.
+type Hash [sha256.Size]byte
+
+// ParseHash converts a hash received as bytes.
+func ParseHash(b []byte) (Hash, error) {
+	var h Hash
+	if len(b) != len(h) {
+		return h, fmt.Errorf("hash of %d bytes, want %d", len(b), len(h))
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// RecordHash returns the leaf hash of a record.
+func RecordHash(data []byte) Hash {
+	return sha256.Sum256(append([]byte{0x00}, data...))
+}
+
+// NodeHash returns the hash of the node with the given children.
+func NodeHash(left, right Hash) Hash {
+	buf := make([]byte, 0, 1+2*len(left))
+	buf = append(append(append(buf, 0x01), left[:]...), right[:]...)
+	return sha256.Sum256(buf)
+}
+
+// TreeHash returns the root of the tree of the given leaf hashes.
+func TreeHash(leaves []Hash) Hash {
+	switch len(leaves) {
+	case 0:
+		return sha256.Sum256(nil)
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return NodeHash(TreeHash(leaves[:k]), TreeHash(leaves[k:]))
+}
+
+// split returns the largest power of two smaller than n, for n > 1.
+func split(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// ProveRecord returns the proof that the leaf at index is in the tree of
+// the given leaves.
+func ProveRecord(leaves []Hash, index int) ([]Hash, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("record %d not in a tree of size %d", index, len(leaves))
+	}
+	return recordPath(leaves, index), nil
+}
+
+func recordPath(leaves []Hash, m int) []Hash {
+	if len(leaves) == 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if m < k {
+		return append(recordPath(leaves[:k], m), TreeHash(leaves[k:]))
+	}
+	return append(recordPath(leaves[k:], m-k), TreeHash(leaves[:k]))
+}
+
+// ErrInvalidProof is returned when a proof does not hold.
+var ErrInvalidProof = errors.New("invalid proof")
+
+// CheckRecord checks that proof shows the leaf at index to be in the tree
+// of the given size and root.
+func CheckRecord(proof []Hash, size, index int64, root, leaf Hash) error {
+	if index < 0 || index >= size {
+		return ErrInvalidProof
+	}
+	fn, sn, r := index, size-1, leaf
+	for _, p := range proof {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			r = NodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn, sn = fn>>1, sn>>1
+			}
+		} else {
+			r = NodeHash(r, p)
+		}
+		fn, sn = fn>>1, sn>>1
+	}
+	if sn != 0 || r != root {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// ProveTree returns the proof that the tree of the first oldSize leaves
+// is a prefix of the tree of all of them.
+func ProveTree(leaves []Hash, oldSize int) ([]Hash, error) {
+	if oldSize < 0 || oldSize > len(leaves) {
+		return nil, fmt.Errorf("tree of size %d not a prefix of one of size %d", oldSize, len(leaves))
+	}
+	if oldSize == 0 || oldSize == len(leaves) {
+		return nil, nil
+	}
+	return subproof(leaves, oldSize, true), nil
+}
+
+func subproof(leaves []Hash, m int, whole bool) []Hash {
+	if m == len(leaves) {
+		if whole {
+			return nil
+		}
+		return []Hash{TreeHash(leaves)}
+	}
+	k := split(len(leaves))
+	if m <= k {
+		return append(subproof(leaves[:k], m, whole), TreeHash(leaves[k:]))
+	}
+	return append(subproof(leaves[k:], m-k, false), TreeHash(leaves[:k]))
+}
+
+// CheckTree checks that proof shows the tree of oldSize and oldRoot to be
+// a prefix of the tree of newSize and newRoot.
+func CheckTree(proof []Hash, newSize int64, newRoot Hash, oldSize int64, oldRoot Hash) error {
+	switch {
+	case oldSize < 0 || oldSize > newSize:
+		return ErrInvalidProof
+	case oldSize == newSize:
+		if len(proof) != 0 || oldRoot != newRoot {
+			return ErrInvalidProof
+		}
+		return nil
+	case oldSize == 0:
+		if len(proof) != 0 {
+			return ErrInvalidProof
+		}
+		return nil
+	case len(proof) == 0:
+		return ErrInvalidProof
+	}
+
+	if oldSize&(oldSize-1) == 0 {
+		proof = append([]Hash{oldRoot}, proof...)
+	}
+	fn, sn := oldSize-1, newSize-1
+	for fn&1 == 1 {
+		fn, sn = fn>>1, sn>>1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			fr, sr = NodeHash(c, fr), NodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn, sn = fn>>1, sn>>1
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+		fn, sn = fn>>1, sn>>1
+	}
+	if sn != 0 || fr != oldRoot || sr != newRoot {
+		return ErrInvalidProof
+	}
+	return nil
+}
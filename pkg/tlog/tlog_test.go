@@ -0,0 +1,60 @@
+package tlog_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/tlog"
+)
+
+func leaves(n int) []tlog.Hash {
+	var hs []tlog.Hash
+	for i := 0; i < n; i++ {
+		hs = append(hs, tlog.RecordHash(fmt.Appendf(nil, "record %d\n", i)))
+	}
+	return hs
+}
+
+func TestProveRecord(t *testing.T) {
+	for size := 1; size <= 17; size++ {
+		hs := leaves(size)
+		root := tlog.TreeHash(hs)
+		for i := range hs {
+			proof, err := tlog.ProveRecord(hs, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tlog.CheckRecord(proof, int64(size), int64(i), root, hs[i]); err != nil {
+				t.Errorf("size %d, record %d: %v", size, i, err)
+			}
+			if err := tlog.CheckRecord(proof, int64(size), int64(i), root, tlog.RecordHash([]byte("forged"))); err == nil {
+				t.Errorf("size %d, record %d: forged record accepted", size, i)
+			}
+		}
+	}
+}
+
+func TestProveTree(t *testing.T) {
+	all := leaves(17)
+	for newSize := 1; newSize <= len(all); newSize++ {
+		newRoot := tlog.TreeHash(all[:newSize])
+		for oldSize := 0; oldSize <= newSize; oldSize++ {
+			oldRoot := tlog.TreeHash(all[:oldSize])
+			proof, err := tlog.ProveTree(all[:newSize], oldSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tlog.CheckTree(proof, int64(newSize), newRoot, int64(oldSize), oldRoot); err != nil {
+				t.Errorf("%d → %d: %v", oldSize, newSize, err)
+			}
+
+			// A log rewriting its past cannot prove it grew from it.
+			if oldSize > 0 {
+				forked := append(leaves(oldSize-1), tlog.RecordHash([]byte("forged")))
+				if err := tlog.CheckTree(proof, int64(newSize), newRoot, int64(oldSize), tlog.TreeHash(forked)); err == nil {
+					t.Errorf("%d → %d: forked tree accepted", oldSize, newSize)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,504 @@
+// Package tuf signs and verifies the metadata of The Update Framework that
+// a registry or mirror publishes next to the repositories it serves:
+//
+//   - root.json, and <version>.root.json for every version of it, lists
+//     the keys of each role and how many of them must sign its metadata,
+//     its threshold;
+//   - targets.json is the index: every version served, with the commit it
+//     names;
+//   - snapshot.json pins the version and hash of targets.json;
+//   - timestamp.json, signed again often and expiring soon, pins those of
+//     snapshot.json.
+//
+// A client trusting a root received out of band follows its rotations and
+// checks the signatures, versions and expiry of every role (see Update):
+// a server compromised without the keys can neither serve content the
+// targets keys did not sign, roll the index back, nor freeze it past the
+// expiry of its timestamp.
+package tuf
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// The roles of a repository.
+const (
+	RoleRoot      = "root"
+	RoleTargets   = "targets"
+	RoleSnapshot  = "snapshot"
+	RoleTimestamp = "timestamp"
+)
+
+// Roles lists the roles of a repository, root first.
+var Roles = []string{RoleRoot, RoleTargets, RoleSnapshot, RoleTimestamp}
+
+// DefaultExpiry is how long the metadata of each role is valid once
+// signed by Publish.
+var DefaultExpiry = map[string]time.Duration{
+	RoleRoot:      365 * 24 * time.Hour,
+	RoleTargets:   90 * 24 * time.Hour,
+	RoleSnapshot:  7 * 24 * time.Hour,
+	RoleTimestamp: 24 * time.Hour,
+}
+
+// ErrNotFound is wrapped by a FetchFunc asked for metadata the repository
+// does not have.
+var ErrNotFound = errors.New("metadata not found")
+
+// Key is a public key of a role.
+type Key struct {
+	Type   string `json:"keytype"` // "ed25519"
+	Public string `json:"public"`  // hex
+}
+
+// KeyID returns the ID of an Ed25519 public key: the hex SHA-256 of it.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:])
+}
+
+// Role gives the keys of a role and how many of them must sign.
+type Role struct {
+	KeyIDs    []string `json:"keyids"`
+	Threshold int      `json:"threshold"`
+}
+
+// Root is the metadata of the root role.
+type Root struct {
+	Type    string          `json:"_type"`
+	Version int64           `json:"version"`
+	Expires time.Time       `json:"expires"`
+	Keys    map[string]Key  `json:"keys"`
+	Roles   map[string]Role `json:"roles"`
+}
+
+// Target is a version a repository serves.
+type Target struct {
+	// Commit is the hash of the commit the version's tag names.
+	Commit string `json:"commit"`
+	// Hash is the holon.sum hash of the content at Commit, if known.
+	Hash string `json:"hash,omitempty"`
+}
+
+// TargetName returns the name of path@version in Targets.
+func TargetName(path, version string) string {
+	return path + "@" + version
+}
+
+// Targets is the metadata of the targets role.
+type Targets struct {
+	Type    string            `json:"_type"`
+	Version int64             `json:"version"`
+	Expires time.Time         `json:"expires"`
+	Targets map[string]Target `json:"targets"`
+}
+
+// MetaFile pins a metadata file.
+type MetaFile struct {
+	Version int64  `json:"version"`
+	Length  int64  `json:"length"`
+	SHA256  string `json:"sha256"`
+}
+
+// Snapshot is the metadata of the snapshot role, pinning targets.json.
+type Snapshot struct {
+	Type    string              `json:"_type"`
+	Version int64               `json:"version"`
+	Expires time.Time           `json:"expires"`
+	Meta    map[string]MetaFile `json:"meta"`
+}
+
+// Timestamp is the metadata of the timestamp role, pinning
+// snapshot.json.
+type Timestamp struct {
+	Type    string              `json:"_type"`
+	Version int64               `json:"version"`
+	Expires time.Time           `json:"expires"`
+	Meta    map[string]MetaFile `json:"meta"`
+}
+
+// Signature is the signature of metadata by a key.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"` // hex
+}
+
+// signed is a metadata file: the metadata, and the signatures of its
+// exact bytes.
+type signed struct {
+	Signed     json.RawMessage `json:"signed"`
+	Signatures []Signature     `json:"signatures"`
+}
+
+// header is what the metadata of every role starts with.
+type header struct {
+	Type    string    `json:"_type"`
+	Version int64     `json:"version"`
+	Expires time.Time `json:"expires"`
+}
+
+// Sign returns meta as a metadata file signed by each of keys.
+func Sign(meta any, keys ...ed25519.PrivateKey) ([]byte, error) {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	file := signed{Signed: data}
+	for _, key := range keys {
+		file.Signatures = append(file.Signatures, Signature{
+			KeyID: KeyID(key.Public().(ed25519.PublicKey)),
+			Sig:   hex.EncodeToString(ed25519.Sign(key, data)),
+		})
+	}
+	out, err := json.Marshal(file)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// verify checks that data, a metadata file of role, is signed by at least
+// the threshold of the keys r gives role, and decodes it into meta.
+func (r *Root) verify(role string, data []byte, meta any) error {
+	var file signed
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%s: %w", role, err)
+	}
+	var h header
+	if err := json.Unmarshal(file.Signed, &h); err != nil {
+		return fmt.Errorf("%s: %w", role, err)
+	}
+	if h.Type != role {
+		return fmt.Errorf("%s: metadata of type %q", role, h.Type)
+	}
+	spec, ok := r.Roles[role]
+	if !ok || spec.Threshold < 1 {
+		return fmt.Errorf("%s: root %d gives the role no keys", role, r.Version)
+	}
+	valid := map[string]bool{}
+	for _, sig := range file.Signatures {
+		key, ok := r.Keys[sig.KeyID]
+		if !ok || !slices.Contains(spec.KeyIDs, sig.KeyID) || key.Type != "ed25519" {
+			continue
+		}
+		pub, err1 := hex.DecodeString(key.Public)
+		raw, err2 := hex.DecodeString(sig.Sig)
+		if err1 == nil && err2 == nil && len(pub) == ed25519.PublicKeySize && ed25519.Verify(pub, file.Signed, raw) {
+			valid[sig.KeyID] = true
+		}
+	}
+	if len(valid) < spec.Threshold {
+		return fmt.Errorf("%s: %d valid signatures of the keys of root %d, want %d", role, len(valid), r.Version, spec.Threshold)
+	}
+	return json.Unmarshal(file.Signed, meta)
+}
+
+// Peek decodes the metadata of a metadata file without verifying it, e.g.
+// that a repository published last.
+func Peek(data []byte, meta any) error {
+	var file signed
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	return json.Unmarshal(file.Signed, meta)
+}
+
+// pin returns how metadata file data is pinned at version.
+func pin(version int64, data []byte) MetaFile {
+	sum := sha256.Sum256(data)
+	return MetaFile{Version: version, Length: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// check reports whether data is what m pins.
+func (m MetaFile) check(name string, data []byte) error {
+	if got := pin(m.Version, data); got.Length != m.Length || got.SHA256 != m.SHA256 {
+		return fmt.Errorf("%s: %d bytes hashing to %s, want %d bytes hashing to %s", name, got.Length, got.SHA256, m.Length, m.SHA256)
+	}
+	return nil
+}
+
+// expired reports metadata of role expired at now.
+func expired(role string, version int64, expires, now time.Time) error {
+	if !now.Before(expires) {
+		return fmt.Errorf("%s %d expired at %s", role, version, expires.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// FetchFunc returns the metadata file of a repository called name, e.g.
+// "timestamp.json", or an error wrapping ErrNotFound if there is none.
+type FetchFunc func(name string) ([]byte, error)
+
+// Update brings up to date, through fetch, the metadata of a repository a
+// client trusts, kept in dir, and returns the targets it verified. trusted
+// is the root the client trusts initially, until dir holds a later one.
+// Update fails, keeping what dir holds, if metadata is not signed by the
+// keys the root gives its role, is older than what dir holds, does not
+// match what the role above pins, or has expired at now.
+func Update(dir string, trusted []byte, fetch FetchFunc, now time.Time) (*Targets, error) {
+	if data, err := os.ReadFile(filepath.Join(dir, "root.json")); err == nil {
+		trusted = data
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	// The trusted root is signed by its own keys.
+	var self, root Root
+	if err := Peek(trusted, &self); err != nil {
+		return nil, fmt.Errorf("trusted root: %w", err)
+	}
+	if err := self.verify(RoleRoot, trusted, &root); err != nil {
+		return nil, fmt.Errorf("trusted root: %w", err)
+	}
+
+	// Follow the rotations of the root, each signed by the keys of the
+	// root before it and its own.
+	first := root
+	for {
+		name := strconv.FormatInt(root.Version+1, 10) + ".root.json"
+		data, err := fetch(name)
+		if errors.Is(err, ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var next, self Root
+		if err := root.verify(RoleRoot, data, &next); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if err := next.verify(RoleRoot, data, &self); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if next.Version != root.Version+1 {
+			return nil, fmt.Errorf("%s: version %d", name, next.Version)
+		}
+		root = next
+		if err := writeTrusted(dir, "root.json", data); err != nil {
+			return nil, err
+		}
+	}
+	if err := expired(RoleRoot, root.Version, root.Expires, now); err != nil {
+		return nil, err
+	}
+	// Keys rotated away from a compromise also discard what they signed.
+	for _, role := range []string{RoleTimestamp, RoleSnapshot} {
+		if !slices.Equal(first.Roles[role].KeyIDs, root.Roles[role].KeyIDs) {
+			os.Remove(filepath.Join(dir, role+".json")) //nolint:errcheck
+		}
+	}
+
+	data, err := fetch("timestamp.json")
+	if err != nil {
+		return nil, err
+	}
+	var ts Timestamp
+	if err := root.verify(RoleTimestamp, data, &ts); err != nil {
+		return nil, err
+	}
+	var oldTS Timestamp
+	if readTrusted(dir, "timestamp.json", &oldTS) {
+		switch {
+		case ts.Version < oldTS.Version:
+			return nil, fmt.Errorf("timestamp: version %d, older than the version %d trusted", ts.Version, oldTS.Version)
+		case ts.Meta["snapshot.json"].Version < oldTS.Meta["snapshot.json"].Version:
+			return nil, fmt.Errorf("timestamp: snapshot version %d, older than the version %d trusted", ts.Meta["snapshot.json"].Version, oldTS.Meta["snapshot.json"].Version)
+		}
+	}
+	if err := expired(RoleTimestamp, ts.Version, ts.Expires, now); err != nil {
+		return nil, err
+	}
+	if err := writeTrusted(dir, "timestamp.json", data); err != nil {
+		return nil, err
+	}
+
+	snapPin, ok := ts.Meta["snapshot.json"]
+	if !ok {
+		return nil, errors.New("timestamp: snapshot.json not pinned")
+	}
+	if data, err = fetch("snapshot.json"); err != nil {
+		return nil, err
+	}
+	if err := snapPin.check("snapshot.json", data); err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := root.verify(RoleSnapshot, data, &snap); err != nil {
+		return nil, err
+	}
+	if snap.Version != snapPin.Version {
+		return nil, fmt.Errorf("snapshot: version %d, timestamp pins %d", snap.Version, snapPin.Version)
+	}
+	var oldSnap Snapshot
+	if readTrusted(dir, "snapshot.json", &oldSnap) && snap.Meta["targets.json"].Version < oldSnap.Meta["targets.json"].Version {
+		return nil, fmt.Errorf("snapshot: targets version %d, older than the version %d trusted", snap.Meta["targets.json"].Version, oldSnap.Meta["targets.json"].Version)
+	}
+	if err := expired(RoleSnapshot, snap.Version, snap.Expires, now); err != nil {
+		return nil, err
+	}
+	if err := writeTrusted(dir, "snapshot.json", data); err != nil {
+		return nil, err
+	}
+
+	targetsPin, ok := snap.Meta["targets.json"]
+	if !ok {
+		return nil, errors.New("snapshot: targets.json not pinned")
+	}
+	if data, err = fetch("targets.json"); err != nil {
+		return nil, err
+	}
+	if err := targetsPin.check("targets.json", data); err != nil {
+		return nil, err
+	}
+	var targets Targets
+	if err := root.verify(RoleTargets, data, &targets); err != nil {
+		return nil, err
+	}
+	if targets.Version != targetsPin.Version {
+		return nil, fmt.Errorf("targets: version %d, snapshot pins %d", targets.Version, targetsPin.Version)
+	}
+	if err := expired(RoleTargets, targets.Version, targets.Expires, now); err != nil {
+		return nil, err
+	}
+	if err := writeTrusted(dir, "targets.json", data); err != nil {
+		return nil, err
+	}
+	return &targets, nil
+}
+
+// readTrusted decodes the metadata of the file name in dir, if there is
+// one.
+func readTrusted(dir, name string, meta any) bool {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	return err == nil && Peek(data, meta) == nil
+}
+
+func writeTrusted(dir, name string, data []byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name), data, 0o644)
+}
+
+// Keys are the private keys a repository is signed with.
+type Keys struct {
+	// Roles holds the keys of each role.
+	Roles map[string][]ed25519.PrivateKey
+	// Thresholds holds how many keys of each role must sign; 1 if unset.
+	Thresholds map[string]int
+	// Retired holds keys of earlier roots. They only sign the next root,
+	// so that clients trusting them follow the rotation.
+	Retired []ed25519.PrivateKey
+}
+
+// root returns the root these keys make, at version.
+func (k Keys) root(version int64, expires time.Time) (*Root, error) {
+	root := &Root{Type: RoleRoot, Version: version, Expires: expires, Keys: map[string]Key{}, Roles: map[string]Role{}}
+	for _, role := range Roles {
+		keys := k.Roles[role]
+		threshold := max(k.Thresholds[role], 1)
+		if len(keys) < threshold {
+			return nil, fmt.Errorf("%s: %d keys for a threshold of %d", role, len(keys), threshold)
+		}
+		spec := Role{Threshold: threshold}
+		for _, key := range keys {
+			pub := key.Public().(ed25519.PublicKey)
+			id := KeyID(pub)
+			root.Keys[id] = Key{Type: "ed25519", Public: hex.EncodeToString(pub)}
+			spec.KeyIDs = append(spec.KeyIDs, id)
+		}
+		slices.Sort(spec.KeyIDs)
+		root.Roles[role] = spec
+	}
+	return root, nil
+}
+
+// Publish signs targets into the metadata of a repository, in dir: new
+// targets, snapshot and timestamp, each a version above the last, and a
+// new root when the keys or thresholds changed, or the root is past half
+// its lifetime. A new root is signed by the root keys and the retired
+// ones.
+func Publish(dir string, keys Keys, targets map[string]Target, now time.Time) error {
+	now = now.UTC().Truncate(time.Second)
+	expires := func(role string) time.Time { return now.Add(DefaultExpiry[role]) }
+
+	var current Root
+	hasRoot := readTrusted(dir, "root.json", &current)
+	root, err := keys.root(current.Version+1, expires(RoleRoot))
+	if err != nil {
+		return err
+	}
+	sameRole := func(a, b Role) bool { return a.Threshold == b.Threshold && slices.Equal(a.KeyIDs, b.KeyIDs) }
+	if !hasRoot || now.After(current.Expires.Add(-DefaultExpiry[RoleRoot]/2)) ||
+		!maps.Equal(current.Keys, root.Keys) || !maps.EqualFunc(current.Roles, root.Roles, sameRole) {
+		data, err := Sign(root, append(slices.Clone(keys.Roles[RoleRoot]), keys.Retired...)...)
+		if err != nil {
+			return err
+		}
+		if err := writeTrusted(dir, strconv.FormatInt(root.Version, 10)+".root.json", data); err != nil {
+			return err
+		}
+		if err := writeTrusted(dir, "root.json", data); err != nil {
+			return err
+		}
+	}
+
+	version := func(name string) int64 {
+		var h header
+		readTrusted(dir, name, &h)
+		return h.Version + 1
+	}
+	files := []struct {
+		name string
+		role string
+		meta func(pinned []byte) any
+	}{
+		{"targets.json", RoleTargets, func([]byte) any {
+			return &Targets{Type: RoleTargets, Version: version("targets.json"), Expires: expires(RoleTargets), Targets: targets}
+		}},
+		{"snapshot.json", RoleSnapshot, func(pinned []byte) any {
+			return &Snapshot{Type: RoleSnapshot, Version: version("snapshot.json"), Expires: expires(RoleSnapshot),
+				Meta: map[string]MetaFile{"targets.json": pin(version("targets.json")-1, pinned)}}
+		}},
+		{"timestamp.json", RoleTimestamp, func(pinned []byte) any {
+			return &Timestamp{Type: RoleTimestamp, Version: version("timestamp.json"), Expires: expires(RoleTimestamp),
+				Meta: map[string]MetaFile{"snapshot.json": pin(version("snapshot.json")-1, pinned)}}
+		}},
+	}
+	// Each file pins the one written before it, so they are written in
+	// order: a client reading meanwhile sees a timestamp pinning the old
+	// snapshot, which pins the old targets, or the new one, not a mix.
+	var last []byte
+	for _, f := range files {
+		data, err := Sign(f.meta(last), keys.Roles[f.role]...)
+		if err != nil {
+			return err
+		}
+		if err := writeTrusted(dir, f.name, data); err != nil {
+			return err
+		}
+		last = data
+	}
+	return nil
+}
+
+// ParseKey returns the Ed25519 key of a seed of 32 bytes in hex, as
+// `openssl rand -hex 32` prints.
+func ParseKey(data []byte) (ed25519.PrivateKey, error) {
+	seed, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("not an Ed25519 seed of %d bytes in hex", ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
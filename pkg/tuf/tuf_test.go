@@ -0,0 +1,243 @@
+package tuf_test
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/organic-programming/rhizome-atlas/pkg/tuf"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func key(name string) ed25519.PrivateKey {
+	seed := sha256.Sum256([]byte(name))
+	return ed25519.NewKeyFromSeed(seed[:])
+}
+
+// keys returns one key per role, named after it and suffix.
+func keys(suffix string) tuf.Keys {
+	k := tuf.Keys{Roles: map[string][]ed25519.PrivateKey{}}
+	for _, role := range tuf.Roles {
+		k.Roles[role] = []ed25519.PrivateKey{key(role + suffix)}
+	}
+	return k
+}
+
+// serve returns a FetchFunc reading the metadata in dir.
+func serve(dir string) tuf.FetchFunc {
+	return func(name string) ([]byte, error) {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s: %w", name, tuf.ErrNotFound)
+		}
+		return data, err
+	}
+}
+
+func publish(t *testing.T, dir string, k tuf.Keys, targets map[string]tuf.Target, at time.Time) {
+	t.Helper()
+	if err := tuf.Publish(dir, k, targets, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestUpdate(t *testing.T) {
+	repo, client := t.TempDir(), t.TempDir()
+	v1 := map[string]tuf.Target{tuf.TargetName("example.com/a", "v1.0.0"): {Commit: "c1"}}
+	publish(t, repo, keys(""), v1, now)
+	root := read(t, filepath.Join(repo, "root.json"))
+
+	targets, err := tuf.Update(client, root, serve(repo), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := targets.Targets["example.com/a@v1.0.0"].Commit; got != "c1" {
+		t.Fatalf("target = %q, want c1", got)
+	}
+
+	v2 := map[string]tuf.Target{"example.com/a@v1.0.0": {Commit: "c1"}, "example.com/a@v1.1.0": {Commit: "c2"}}
+	publish(t, repo, keys(""), v2, now.Add(time.Hour))
+	if targets, err = tuf.Update(client, root, serve(repo), now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(targets.Targets) != 2 {
+		t.Fatalf("targets = %v, want 2", targets.Targets)
+	}
+}
+
+func TestUpdateRejects(t *testing.T) {
+	targets := map[string]tuf.Target{"example.com/a@v1.0.0": {Commit: "c1"}}
+	for _, c := range []struct {
+		name   string
+		attack func(t *testing.T, repo string)
+		at     time.Time
+		want   string
+	}{
+		{"tampered targets", func(t *testing.T, repo string) {
+			path := filepath.Join(repo, "targets.json")
+			os.WriteFile(path, []byte(strings.Replace(string(read(t, path)), `"c1"`, `"c6"`, 1)), 0o644)
+		}, now, "targets.json: "},
+		{"targets signed by the snapshot key", func(t *testing.T, repo string) {
+			forged := sign(t, repo, &tuf.Targets{Type: tuf.RoleTargets, Version: 1, Expires: now.Add(time.Hour)}, key(tuf.RoleSnapshot))
+			forged = sign(t, repo, &tuf.Snapshot{Type: tuf.RoleSnapshot, Version: 2, Expires: now.Add(time.Hour),
+				Meta: map[string]tuf.MetaFile{"targets.json": pin(1, forged)}}, key(tuf.RoleSnapshot))
+			sign(t, repo, &tuf.Timestamp{Type: tuf.RoleTimestamp, Version: 2, Expires: now.Add(time.Hour),
+				Meta: map[string]tuf.MetaFile{"snapshot.json": pin(2, forged)}}, key(tuf.RoleTimestamp))
+		}, now, "targets: 0 valid signatures"},
+		{"expired timestamp", func(*testing.T, string) {}, now.Add(25 * time.Hour), "timestamp 1 expired"},
+		{"wrong role", func(t *testing.T, repo string) {
+			os.WriteFile(filepath.Join(repo, "timestamp.json"), read(t, filepath.Join(repo, "snapshot.json")), 0o644)
+		}, now, `metadata of type "snapshot"`},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			repo := t.TempDir()
+			publish(t, repo, keys(""), targets, now)
+			c.attack(t, repo)
+			_, err := tuf.Update(t.TempDir(), read(t, filepath.Join(repo, "root.json")), serve(repo), c.at)
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("Update = %v, want an error containing %q", err, c.want)
+			}
+		})
+	}
+}
+
+func TestUpdateRollback(t *testing.T) {
+	repo, client := t.TempDir(), t.TempDir()
+	publish(t, repo, keys(""), map[string]tuf.Target{"example.com/a@v1.0.0": {Commit: "c1"}}, now)
+	root := read(t, filepath.Join(repo, "root.json"))
+	old := map[string][]byte{}
+	for _, name := range []string{"timestamp.json", "snapshot.json", "targets.json"} {
+		old[name] = read(t, filepath.Join(repo, name))
+	}
+	publish(t, repo, keys(""), map[string]tuf.Target{"example.com/a@v1.0.0": {Commit: "c1"}, "example.com/a@v1.0.1": {Commit: "c2"}}, now)
+	if _, err := tuf.Update(client, root, serve(repo), now); err != nil {
+		t.Fatal(err)
+	}
+
+	// A server replaying older metadata, validly signed, is refused.
+	for name, data := range old {
+		os.WriteFile(filepath.Join(repo, name), data, 0o644)
+	}
+	if _, err := tuf.Update(client, root, serve(repo), now); err == nil || !strings.Contains(err.Error(), "older than the version 2 trusted") {
+		t.Fatalf("Update of older metadata = %v, want a rollback error", err)
+	}
+}
+
+func TestUpdateRotation(t *testing.T) {
+	repo := t.TempDir()
+	targets := map[string]tuf.Target{"example.com/a@v1.0.0": {Commit: "c1"}}
+	publish(t, repo, keys(""), targets, now)
+	root := read(t, filepath.Join(repo, "root.json"))
+
+	// Every key rotated, the old root key signing the new root: a client
+	// trusting the first root follows.
+	rotated := keys("-2")
+	rotated.Retired = []ed25519.PrivateKey{key(tuf.RoleRoot)}
+	publish(t, repo, rotated, targets, now)
+	client := t.TempDir()
+	if _, err := tuf.Update(client, root, serve(repo), now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "2.root.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A root not signed by the root it replaces is refused.
+	repo = t.TempDir()
+	publish(t, repo, keys(""), targets, now)
+	root = read(t, filepath.Join(repo, "root.json"))
+	publish(t, repo, keys("-2"), targets, now)
+	if _, err := tuf.Update(t.TempDir(), root, serve(repo), now); err == nil || !strings.Contains(err.Error(), "2.root.json: root: 0 valid signatures") {
+		t.Fatalf("Update through an unsigned rotation = %v, want a signature error", err)
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	repo := t.TempDir()
+	k := keys("")
+	k.Roles[tuf.RoleTargets] = []ed25519.PrivateKey{key("t1"), key("t2"), key("t3")}
+	k.Thresholds = map[string]int{tuf.RoleTargets: 2}
+	targets := map[string]tuf.Target{"example.com/a@v1.0.0": {Commit: "c1"}}
+	publish(t, repo, k, targets, now)
+	root := read(t, filepath.Join(repo, "root.json"))
+	if _, err := tuf.Update(t.TempDir(), root, serve(repo), now); err != nil {
+		t.Fatal(err)
+	}
+
+	// One of the targets keys stolen, with the online snapshot and
+	// timestamp keys, is not enough to sign the index.
+	forged := sign(t, repo, &tuf.Targets{Type: tuf.RoleTargets, Version: 2, Expires: now.Add(time.Hour),
+		Targets: map[string]tuf.Target{"example.com/a@v1.0.0": {Commit: "evil"}}}, key("t1"))
+	forged = sign(t, repo, &tuf.Snapshot{Type: tuf.RoleSnapshot, Version: 2, Expires: now.Add(time.Hour),
+		Meta: map[string]tuf.MetaFile{"targets.json": pin(2, forged)}}, key(tuf.RoleSnapshot))
+	sign(t, repo, &tuf.Timestamp{Type: tuf.RoleTimestamp, Version: 2, Expires: now.Add(time.Hour),
+		Meta: map[string]tuf.MetaFile{"snapshot.json": pin(2, forged)}}, key(tuf.RoleTimestamp))
+	_, err := tuf.Update(t.TempDir(), root, serve(repo), now)
+	if err == nil || !strings.Contains(err.Error(), "targets: 1 valid signatures of the keys of root 1, want 2") {
+		t.Fatalf("Update of targets signed by one key of 2 = %v, want a threshold error", err)
+	}
+
+	k.Thresholds[tuf.RoleTargets] = 4
+	if err := tuf.Publish(t.TempDir(), k, targets, now); err == nil {
+		t.Fatal("Publish with a threshold above the keys succeeded")
+	}
+}
+
+// sign signs meta, writes it to the file of its role in repo and
+// returns it.
+func sign(t *testing.T, repo string, meta any, keys ...ed25519.PrivateKey) []byte {
+	t.Helper()
+	data, err := tuf.Sign(meta, keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var name string
+	switch meta.(type) {
+	case *tuf.Targets:
+		name = "targets.json"
+	case *tuf.Snapshot:
+		name = "snapshot.json"
+	case *tuf.Timestamp:
+		name = "timestamp.json"
+	}
+	if err := os.WriteFile(filepath.Join(repo, name), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func pin(version int64, data []byte) tuf.MetaFile {
+	sum := sha256.Sum256(data)
+	return tuf.MetaFile{Version: version, Length: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}
+}
+
+func TestNotFound(t *testing.T) {
+	repo := t.TempDir()
+	_, err := tuf.Update(t.TempDir(), nil, serve(repo), now)
+	if err == nil {
+		t.Fatal("Update without a trusted root succeeded")
+	}
+	publish(t, repo, keys(""), nil, now)
+	root := read(t, filepath.Join(repo, "root.json"))
+	os.Remove(filepath.Join(repo, "timestamp.json"))
+	if _, err := tuf.Update(t.TempDir(), root, serve(repo), now); !errors.Is(err, tuf.ErrNotFound) {
+		t.Fatalf("Update without a timestamp = %v, want ErrNotFound", err)
+	}
+}